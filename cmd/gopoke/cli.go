@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os/signal"
+	"syscall"
+
+	"gopoke/internal/app"
+	"gopoke/internal/benchenv"
+	"gopoke/internal/desktop"
+	"gopoke/internal/project"
+)
+
+// runCommand runs the headless subcommand named by args[0]. It reports
+// false when args name none, so the app starts as usual.
+func runCommand(args []string, stdout io.Writer, stderr io.Writer) (bool, int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+	switch args[0] {
+	case "bench-env":
+		return true, benchEnv(args[1:], stdout, stderr)
+	case "event-schemas":
+		return true, eventSchemas(stdout, stderr)
+	default:
+		return false, 0
+	}
+}
+
+// benchEnv measures cold and warm runs against each detected toolchain.
+func benchEnv(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("bench-env", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gopoke bench-env [-toolchain name] [-warm-runs n] [-json]")
+		fmt.Fprintln(stderr, "Runs a standard snippet set cold and warm against each Go toolchain and compares startup, compile and first-feedback times.")
+		flags.PrintDefaults()
+	}
+	toolchainName := flags.String("toolchain", "", "measure only the toolchain with this name, such as go1.22.4")
+	warmRuns := flags.Int("warm-runs", benchenv.DefaultWarmRuns, "warm runs averaged per snippet")
+	asJSON := flags.Bool("json", false, "print measurements as JSON")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	toolchains, err := app.New().AvailableToolchains(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "bench-env: %v\n", err)
+		return 1
+	}
+	if *toolchainName != "" {
+		selected := make([]project.ToolchainInfo, 0, 1)
+		for _, toolchain := range toolchains {
+			if toolchain.Name == *toolchainName {
+				selected = append(selected, toolchain)
+			}
+		}
+		if len(selected) == 0 {
+			fmt.Fprintf(stderr, "bench-env: toolchain %q not found\n", *toolchainName)
+			return 1
+		}
+		toolchains = selected
+	}
+
+	report, err := benchenv.Run(ctx, benchenv.Options{Toolchains: toolchains, WarmRuns: *warmRuns, Progress: stderr})
+	if err != nil {
+		fmt.Fprintf(stderr, "bench-env: %v\n", err)
+		return 1
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = benchenv.WriteTable(stdout, report)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "bench-env: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// eventSchemas prints the JSON Schemas of the events the desktop bridge
+// emits, for checking the frontend against.
+func eventSchemas(stdout io.Writer, stderr io.Writer) int {
+	document, err := desktop.ExportEventSchemas()
+	if err != nil {
+		fmt.Fprintf(stderr, "event-schemas: %v\n", err)
+		return 1
+	}
+	if _, err := stdout.Write(document); err != nil {
+		fmt.Fprintf(stderr, "event-schemas: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -121,8 +121,10 @@ export default function SettingsPanel({ onClose, editorSettings, onEditorSetting
       const s = await getGlobalSettings();
       setSettings(s);
       setAdvancedDraft({
-        defaultTimeoutMS: s.defaultTimeoutMS || 30000,
-        maxOutputBytes: s.maxOutputBytes || 1048576,
+        defaultTimeoutMS: s.defaultTimeoutMS || 15000,
+        maxOutputBytes: s.maxOutputBytes || 131072,
+        defaultTimeoutSet: !!s.defaultTimeoutSet,
+        maxOutputSet: !!s.maxOutputSet,
         goPathOverride: s.goPathOverride || "",
         goModCacheOverride: s.goModCacheOverride || "",
       });
@@ -265,8 +267,10 @@ export default function SettingsPanel({ onClose, editorSettings, onEditorSetting
     try {
       const updated = {
         ...settings,
-        defaultTimeoutMS: Number(advancedDraft.defaultTimeoutMS) || 30000,
-        maxOutputBytes: Number(advancedDraft.maxOutputBytes) || 1048576,
+        defaultTimeoutMS: Number(advancedDraft.defaultTimeoutMS) || 15000,
+        maxOutputBytes: Number(advancedDraft.maxOutputBytes) || 131072,
+        defaultTimeoutSet: advancedDraft.defaultTimeoutSet,
+        maxOutputSet: advancedDraft.maxOutputSet,
         goPathOverride: advancedDraft.goPathOverride,
         goModCacheOverride: advancedDraft.goModCacheOverride,
       };
@@ -668,7 +672,7 @@ function AdvancedTab({ draft, onDraftChange, onSave, saving }) {
           max={300000}
           step={1000}
           value={draft.defaultTimeoutMS}
-          onChange={(e) => updateField("defaultTimeoutMS", e.target.value)}
+          onChange={(e) => onDraftChange({ ...draft, defaultTimeoutMS: e.target.value, defaultTimeoutSet: true })}
         />
       </div>
 
@@ -681,7 +685,7 @@ function AdvancedTab({ draft, onDraftChange, onSave, saving }) {
           max={10485760}
           step={1024}
           value={draft.maxOutputBytes}
-          onChange={(e) => updateField("maxOutputBytes", e.target.value)}
+          onChange={(e) => onDraftChange({ ...draft, maxOutputBytes: e.target.value, maxOutputSet: true })}
         />
       </div>
 
@@ -231,6 +231,7 @@ export namespace storage {
 	    key: string;
 	    value: string;
 	    masked: boolean;
+	    fromDotEnv?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new EnvVarRecord(source);
@@ -243,6 +244,7 @@ export namespace storage {
 	        this.key = source["key"];
 	        this.value = source["value"];
 	        this.masked = source["masked"];
+	        this.fromDotEnv = source["fromDotEnv"];
 	    }
 	}
 	export class HealthReport {
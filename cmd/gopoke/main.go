@@ -17,6 +17,12 @@ func main() {
 	if runner.RunWorkerModeIfEnabled() {
 		return
 	}
+	if handled, code := runCommand(os.Args[1:], os.Stdout, os.Stderr); handled {
+		os.Exit(code)
+	}
+	if err := app.LaunchPendingUpdate(); err != nil {
+		slog.Warn("staged update not installed", "error", err)
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
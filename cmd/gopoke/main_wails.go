@@ -6,6 +6,7 @@ import (
 	"context"
 	"embed"
 	"log/slog"
+	"os"
 
 	"gopoke/internal/app"
 	"gopoke/internal/desktop"
@@ -24,6 +25,12 @@ func main() {
 	if runner.RunWorkerModeIfEnabled() {
 		return
 	}
+	if handled, code := runCommand(os.Args[1:], os.Stdout, os.Stderr); handled {
+		os.Exit(code)
+	}
+	if err := app.LaunchPendingUpdate(); err != nil {
+		slog.Warn("staged update not installed", "error", err)
+	}
 
 	application := app.New()
 	bridge := desktop.NewWailsBridge(application)
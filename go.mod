@@ -5,6 +5,7 @@ go 1.25
 require (
 	github.com/gorilla/websocket v1.5.3
 	github.com/wailsapp/wails/v2 v2.11.0
+	golang.org/x/mod v0.23.0
 )
 
 require (
@@ -0,0 +1,11 @@
+package acceptance
+
+import (
+	"testing"
+
+	"gopoke/internal/testutil"
+)
+
+func TestMain(m *testing.M) {
+	testutil.Main(m)
+}
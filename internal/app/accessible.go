@@ -0,0 +1,124 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopoke/internal/diagnostics"
+	"gopoke/internal/execution"
+	"gopoke/internal/i18n"
+)
+
+// maxRecentResults bounds results kept for AccessibleRunResult lookups.
+const maxRecentResults = 32
+
+// ansiEscapePattern matches CSI sequences (colors, cursor movement) and OSC
+// sequences (titles, hyperlinks).
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// AccessibleRunResult renders a recent run result as linear plain text for
+// screen readers: status, problems in file order, stdout, then stderr.
+func (a *Application) AccessibleRunResult(ctx context.Context, runID string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("accessible run result context: %w", err)
+	}
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return "", fmt.Errorf("run id is required")
+	}
+
+	a.recentMu.Lock()
+	result, ok := a.recentResults[runID]
+	a.recentMu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("run result not found: %s", runID)
+	}
+	return formatAccessibleResult(result, a.localizer()), nil
+}
+
+// rememberResult keeps the latest results by run ID, evicting the oldest.
+func (a *Application) rememberResult(runID string, result execution.Result) {
+	a.recentMu.Lock()
+	defer a.recentMu.Unlock()
+	if a.recentResults == nil {
+		a.recentResults = make(map[string]execution.Result)
+	}
+	if _, exists := a.recentResults[runID]; !exists {
+		a.recentOrder = append(a.recentOrder, runID)
+	}
+	a.recentResults[runID] = result
+	for len(a.recentOrder) > maxRecentResults {
+		delete(a.recentResults, a.recentOrder[0])
+		a.recentOrder = a.recentOrder[1:]
+	}
+}
+
+// stripANSI removes terminal escape sequences from run output.
+func stripANSI(result *execution.Result) {
+	result.Stdout = ansiEscapePattern.ReplaceAllString(result.Stdout, "")
+	result.Stderr = ansiEscapePattern.ReplaceAllString(result.Stderr, "")
+}
+
+func formatAccessibleResult(result execution.Result, localizer *i18n.Localizer) string {
+	lines := make([]string, 0, 16)
+
+	switch {
+	case result.TimedOut:
+		lines = append(lines, localizer.T(i18n.MsgA11yStatusTimedOut, result.DurationMS))
+	case result.Canceled:
+		lines = append(lines, localizer.T(i18n.MsgA11yStatusCanceled, result.DurationMS))
+	case result.ExitCode == 0:
+		lines = append(lines, localizer.T(i18n.MsgA11yStatusSuccess, result.DurationMS))
+	default:
+		lines = append(lines, localizer.T(i18n.MsgA11yStatusFailed, result.ExitCode, result.DurationMS))
+	}
+
+	problems := slices.Clone(result.Diagnostics)
+	slices.SortStableFunc(problems, func(left, right execution.Diagnostic) int {
+		return cmp.Or(
+			cmp.Compare(left.File, right.File),
+			cmp.Compare(left.Line, right.Line),
+			cmp.Compare(left.Column, right.Column),
+			cmp.Compare(left.Kind, right.Kind),
+		)
+	})
+	if len(problems) > 0 {
+		summary := result.DiagnosticsSummary
+		if summary == "" {
+			summary = fmt.Sprint(len(problems))
+		}
+		lines = append(lines, localizer.T(i18n.MsgA11yProblems, summary))
+	}
+	for index, problem := range problems {
+		kind := localizer.T(i18n.MsgA11yKindCompile)
+		if problem.Kind == diagnostics.KindPanic {
+			kind = localizer.T(i18n.MsgA11yKindPanic)
+		}
+		lines = append(lines, localizer.T(
+			i18n.MsgA11yProblem,
+			index+1, len(problems), kind, problem.File, problem.Line, problem.Column, problem.Message,
+		))
+	}
+
+	lines = appendOutputSection(lines, localizer, result.Stdout, i18n.MsgA11yStdout, i18n.MsgA11yStdoutEmpty)
+	lines = appendOutputSection(lines, localizer, result.Stderr, i18n.MsgA11yStderr, i18n.MsgA11yStderrEmpty)
+	if result.StdoutTruncated || result.StderrTruncated {
+		lines = append(lines, localizer.T(i18n.MsgA11yTruncated))
+	}
+	lines = append(lines, localizer.T(i18n.MsgA11yEnd))
+	return strings.Join(lines, "\n")
+}
+
+func appendOutputSection(lines []string, localizer *i18n.Localizer, output string, headerKey string, emptyKey string) []string {
+	output = strings.TrimRight(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	if output == "" {
+		return append(lines, localizer.T(emptyKey))
+	}
+	outputLines := strings.Split(output, "\n")
+	lines = append(lines, localizer.Plural(headerKey, len(outputLines)))
+	return append(lines, outputLines...)
+}
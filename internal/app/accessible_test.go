@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/i18n"
+)
+
+func TestFormatAccessibleResultIsLinearAndOrdered(t *testing.T) {
+	t.Parallel()
+
+	result := execution.Result{
+		ExitCode:   1,
+		DurationMS: 42,
+		Stdout:     "first\r\nsecond\n",
+		Stderr:     "./main.go:9:2: missing return\n./main.go:3:5: undefined: x\n",
+		Diagnostics: []execution.Diagnostic{
+			{Kind: "compile", File: "./main.go", Line: 9, Column: 2, Message: "missing return"},
+			{Kind: "compile", File: "./main.go", Line: 3, Column: 5, Message: "undefined: x"},
+		},
+		DiagnosticsSummary: "2 compile errors",
+		StderrTruncated:    true,
+	}
+
+	got := formatAccessibleResult(result, i18n.New("en"))
+	want := strings.Join([]string{
+		"Run failed with exit code 1 after 42 milliseconds.",
+		"Problems: 2 compile errors.",
+		"Problem 1 of 2: compile error in ./main.go, line 3, column 5: undefined: x",
+		"Problem 2 of 2: compile error in ./main.go, line 9, column 2: missing return",
+		"Standard output, 2 lines:",
+		"first",
+		"second",
+		"Standard error, 2 lines:",
+		"./main.go:9:2: missing return",
+		"./main.go:3:5: undefined: x",
+		"Output was truncated.",
+		"End of run result.",
+	}, "\n")
+	if got != want {
+		t.Fatalf("formatAccessibleResult() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestStripANSIRemovesEscapeSequences(t *testing.T) {
+	t.Parallel()
+
+	result := execution.Result{
+		Stdout: "\x1b[1;32mok\x1b[0m done",
+		Stderr: "\x1b]0;title\x07warn\x1b[2K",
+	}
+	stripANSI(&result)
+	if got, want := result.Stdout, "ok done"; got != want {
+		t.Fatalf("Stdout = %q, want %q", got, want)
+	}
+	if got, want := result.Stderr, "warn"; got != want {
+		t.Fatalf("Stderr = %q, want %q", got, want)
+	}
+}
+
+func TestAccessibleRunResultUsesRecentResults(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	for i := 0; i <= maxRecentResults; i++ {
+		application.rememberResult(fmt.Sprintf("run-%d", i), execution.Result{DurationMS: int64(i)})
+	}
+
+	if _, err := application.AccessibleRunResult(context.Background(), "run-0"); err == nil {
+		t.Fatal("AccessibleRunResult(evicted) error = nil, want not found")
+	}
+	text, err := application.AccessibleRunResult(context.Background(), fmt.Sprintf("run-%d", maxRecentResults))
+	if err != nil {
+		t.Fatalf("AccessibleRunResult() error = %v", err)
+	}
+	if !strings.HasPrefix(text, fmt.Sprintf("Run succeeded in %d milliseconds.", maxRecentResults)) {
+		t.Fatalf("AccessibleRunResult() = %q", text)
+	}
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"gopoke/internal/storage"
+)
+
+// DefaultActivityWindow is the heatmap window when none is given.
+const DefaultActivityWindow = 30 * 24 * time.Hour
+
+// ActivityHeatmap shows where a project's runs and edits concentrated, per
+// package and day.
+type ActivityHeatmap struct {
+	// Days are the UTC dates of the window, as 2006-01-02, oldest first.
+	Days []string `json:"days"`
+	// Packages are ordered busiest first.
+	Packages []PackageActivity `json:"packages"`
+	// MaxDaily is the largest count of one package on one day, for scaling
+	// the heatmap's colors.
+	MaxDaily int `json:"maxDaily"`
+}
+
+// PackageActivity is the activity of one package. Edits count saves of the
+// package's files.
+type PackageActivity struct {
+	Package string `json:"package"`
+	Runs    int    `json:"runs"`
+	Edits   int    `json:"edits"`
+	// Daily holds runs plus edits for each of ActivityHeatmap.Days.
+	Daily []int `json:"daily"`
+	// Files are the package's edited files, most edited first.
+	Files []FileActivity `json:"files"`
+}
+
+// FileActivity counts the saves of one file.
+type FileActivity struct {
+	Path  string `json:"path"`
+	Edits int    `json:"edits"`
+}
+
+// ActivityHeatmap aggregates the project's runs and file saves over the
+// window ending today; zero means DefaultActivityWindow.
+func (a *Application) ActivityHeatmap(ctx context.Context, projectPath string, window time.Duration) (ActivityHeatmap, error) {
+	if err := ctx.Err(); err != nil {
+		return ActivityHeatmap{}, fmt.Errorf("activity heatmap context: %w", err)
+	}
+	if window == 0 {
+		window = DefaultActivityWindow
+	}
+	if window < 0 || window > storage.ActivityRetention {
+		return ActivityHeatmap{}, fmt.Errorf("activity window must be between 1 day and %d days", storage.ActivityRetention/(24*time.Hour))
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return ActivityHeatmap{}, err
+	}
+
+	today := a.clock().UTC().Truncate(24 * time.Hour)
+	dayCount := max(int(window/(24*time.Hour)), 1)
+	since := today.AddDate(0, 0, 1-dayCount)
+	records, err := a.store.ProjectActivity(ctx, record.ID, since)
+	if err != nil {
+		return ActivityHeatmap{}, fmt.Errorf("load project activity: %w", err)
+	}
+
+	heatmap := ActivityHeatmap{Days: make([]string, dayCount), Packages: []PackageActivity{}}
+	dayIndex := make(map[string]int, dayCount)
+	for index := range heatmap.Days {
+		day := since.AddDate(0, 0, index).Format("2006-01-02")
+		heatmap.Days[index] = day
+		dayIndex[day] = index
+	}
+	packageIndex := make(map[string]int)
+	fileEdits := make(map[string]map[string]int)
+	for _, activity := range records {
+		day, ok := dayIndex[activity.Day]
+		if !ok {
+			continue
+		}
+		pkg := activity.Target
+		if activity.Kind == storage.ActivityEdit {
+			pkg = filePackage(activity.Target)
+		}
+		index, ok := packageIndex[pkg]
+		if !ok {
+			index = len(heatmap.Packages)
+			packageIndex[pkg] = index
+			heatmap.Packages = append(heatmap.Packages, PackageActivity{Package: pkg, Daily: make([]int, dayCount)})
+			fileEdits[pkg] = make(map[string]int)
+		}
+		entry := &heatmap.Packages[index]
+		switch activity.Kind {
+		case storage.ActivityRun:
+			entry.Runs += activity.Count
+		case storage.ActivityEdit:
+			entry.Edits += activity.Count
+			fileEdits[pkg][activity.Target] += activity.Count
+		}
+		entry.Daily[day] += activity.Count
+	}
+
+	for index := range heatmap.Packages {
+		entry := &heatmap.Packages[index]
+		entry.Files = make([]FileActivity, 0, len(fileEdits[entry.Package]))
+		for file, edits := range fileEdits[entry.Package] {
+			entry.Files = append(entry.Files, FileActivity{Path: file, Edits: edits})
+		}
+		slices.SortFunc(entry.Files, func(x, y FileActivity) int {
+			if x.Edits != y.Edits {
+				return y.Edits - x.Edits
+			}
+			return strings.Compare(x.Path, y.Path)
+		})
+		heatmap.MaxDaily = max(heatmap.MaxDaily, slices.Max(entry.Daily))
+	}
+	slices.SortFunc(heatmap.Packages, func(x, y PackageActivity) int {
+		if total := (y.Runs + y.Edits) - (x.Runs + x.Edits); total != 0 {
+			return total
+		}
+		return strings.Compare(x.Package, y.Package)
+	})
+	return heatmap, nil
+}
+
+// recordRunActivity counts a project run against the package it ran in.
+// Failures are logged, never returned, so tracking cannot fail a run.
+func (a *Application) recordRunActivity(ctx context.Context, resolved resolvedRunRequest, at time.Time) {
+	if a.store == nil {
+		return
+	}
+	err := a.store.RecordActivity(context.WithoutCancel(ctx), resolved.projectID, storage.ActivityRun, activityPackage(resolved.packagePath), at)
+	if err != nil {
+		a.logger.Warn("record run activity failed", "projectID", resolved.projectID, "error", err)
+	}
+}
+
+// recordEditActivity counts a save of filePath against the known project
+// that contains it, the innermost one when projects nest. Files outside
+// every project are not tracked.
+func (a *Application) recordEditActivity(ctx context.Context, filePath string) {
+	if a.store == nil {
+		return
+	}
+	projects, err := a.store.RecentProjects(ctx, 0)
+	if err != nil {
+		a.logger.Warn("record edit activity failed", "path", filePath, "error", err)
+		return
+	}
+	var owner storage.ProjectRecord
+	relative := ""
+	for _, candidate := range projects {
+		rel, err := filepath.Rel(candidate.Path, filePath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+			continue
+		}
+		if len(candidate.Path) > len(owner.Path) {
+			owner = candidate
+			relative = filepath.ToSlash(rel)
+		}
+	}
+	if owner.ID == "" {
+		return
+	}
+	if err := a.store.RecordActivity(ctx, owner.ID, storage.ActivityEdit, relative, a.clock()); err != nil {
+		a.logger.Warn("record edit activity failed", "path", filePath, "error", err)
+	}
+}
+
+// activityPackage spells a run package the way run targets are listed,
+// such as "." or "./cmd/api".
+func activityPackage(pkg string) string {
+	cleaned := path.Clean(filepath.ToSlash(strings.TrimSpace(pkg)))
+	if cleaned == "." || cleaned == "" {
+		return "."
+	}
+	return "./" + cleaned
+}
+
+// filePackage is the package of a project-relative file.
+func filePackage(file string) string {
+	return activityPackage(path.Dir(file))
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+)
+
+func TestActivityHeatmapCountsRunsAndEdits(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	application.SetClock(func() time.Time { return now })
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	run := func(pkg string) {
+		t.Helper()
+		request := execution.RunRequest{ProjectPath: projectDir, PackagePath: pkg, Source: "package main\n\nfunc main() {}\n"}
+		if _, err := application.RunSnippet(context.Background(), request, nil, nil); err != nil {
+			t.Fatalf("RunSnippet(%q) error = %v", pkg, err)
+		}
+	}
+	save := func(file string) {
+		t.Helper()
+		if err := application.SaveGoFile(context.Background(), filepath.Join(projectDir, file), "package main\n\nfunc main() {}\n"); err != nil {
+			t.Fatalf("SaveGoFile(%q) error = %v", file, err)
+		}
+	}
+
+	run(".")
+	now = now.Add(24 * time.Hour)
+	run("./cmd/api")
+	run("./cmd/api")
+	save(filepath.Join("cmd", "api", "main.go"))
+	save("main.go")
+	save(filepath.Join("cmd", "api", "main.go"))
+
+	heatmap, err := application.ActivityHeatmap(context.Background(), projectDir, 2*24*time.Hour)
+	if err != nil {
+		t.Fatalf("ActivityHeatmap() error = %v", err)
+	}
+	if want := []string{"2026-03-10", "2026-03-11"}; !reflect.DeepEqual(heatmap.Days, want) {
+		t.Fatalf("Days = %v, want %v", heatmap.Days, want)
+	}
+	want := []PackageActivity{
+		{Package: "./cmd/api", Runs: 2, Edits: 2, Daily: []int{0, 4}, Files: []FileActivity{{Path: "cmd/api/main.go", Edits: 2}}},
+		{Package: ".", Runs: 1, Edits: 1, Daily: []int{1, 1}, Files: []FileActivity{{Path: "main.go", Edits: 1}}},
+	}
+	if !reflect.DeepEqual(heatmap.Packages, want) {
+		t.Fatalf("Packages = %+v, want %+v", heatmap.Packages, want)
+	}
+	if heatmap.MaxDaily != 4 {
+		t.Fatalf("MaxDaily = %d, want 4", heatmap.MaxDaily)
+	}
+
+	today, err := application.ActivityHeatmap(context.Background(), projectDir, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("ActivityHeatmap(1 day) error = %v", err)
+	}
+	if len(today.Packages) != 2 || today.Packages[1].Runs != 0 {
+		t.Fatalf("one-day Packages = %+v, want yesterday's run excluded", today.Packages)
+	}
+	if _, err := application.ActivityHeatmap(context.Background(), projectDir, 365*24*time.Hour); err == nil {
+		t.Fatal("ActivityHeatmap() beyond retention error = nil")
+	}
+}
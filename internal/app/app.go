@@ -6,25 +6,43 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"gopoke/internal/audit"
+	"gopoke/internal/benchsnippet"
 	"gopoke/internal/diagnostics"
+	"gopoke/internal/download"
 	"gopoke/internal/execution"
 	"gopoke/internal/formatting"
+	"gopoke/internal/fspath"
+	"gopoke/internal/i18n"
+	"gopoke/internal/idle"
 	"gopoke/internal/lsp"
+	"gopoke/internal/outputfold"
 	"gopoke/internal/playground"
+	"gopoke/internal/plugins"
 	"gopoke/internal/project"
 	"gopoke/internal/richoutput"
 	"gopoke/internal/runner"
+	"gopoke/internal/session"
 	"gopoke/internal/settings"
+	"gopoke/internal/share"
+	"gopoke/internal/snippethelper"
+	"gopoke/internal/snippetmeta"
+	"gopoke/internal/snippetparam"
 	"gopoke/internal/storage"
 	"gopoke/internal/telemetry"
+	"gopoke/internal/update"
+	"gopoke/internal/webhook"
 )
 
 // DefaultShutdownTimeout controls graceful shutdown time for the app.
@@ -46,26 +64,88 @@ const (
 
 // Application wires core dependencies for the GoPad process.
 type Application struct {
-	logger         *slog.Logger
-	store          *storage.Store
-	projects       *project.Service
-	workers        *runner.Manager
-	lspManager     *lsp.Manager
-	runMu          sync.Mutex
-	activeRuns     map[string]context.CancelFunc
-	telemetry      *telemetry.Recorder
-	startupMetrics telemetry.StartupEvent
-	scratchDir     string // temp dir for projectless runs and LSP
+	logger            *slog.Logger
+	store             *storage.Store
+	projects          *project.Service
+	workers           *runner.Manager
+	workerLogs        runner.LogHandler
+	lspManager        *lsp.Manager
+	runMu             sync.Mutex
+	activeRuns        map[string]context.CancelFunc
+	runPIDs           map[string]int // root process of each started active run
+	telemetry         *telemetry.Recorder
+	startupMetrics    telemetry.StartupEvent
+	scratchDir        string // temp dir for projectless runs and LSP
+	toolBinDir        string // managed tool install dir, searched after PATH
+	updates           *update.Updater
+	locale            atomic.Pointer[i18n.Localizer]
+	plainText         atomic.Bool
+	monitorNetwork    atomic.Bool
+	promptNetwork     atomic.Bool
+	lowPriorityRuns   atomic.Bool
+	networkMu         sync.Mutex
+	networkHandler    RunNetworkHandler
+	permissionHandler RunNetworkPermissionHandler
+	networkPrompts    map[string]*networkPrompt // pending permission prompt per run
+	networkDenied     map[string]bool           // runs stopped by a denied prompt
+	volumesMu         sync.Mutex
+	volumes           map[string]fspath.Volume // filesystem of each project path
+	recentMu          sync.Mutex
+	recentResults     map[string]execution.Result
+	recentOrder       []string
+	runEventsMu       sync.Mutex
+	runEvents         map[string]*runEventLog
+	runEventsOrder    []string
+	sessionDir        string
+	artifactsDir      string // per-project run artifacts, keyed by project ID
+	backupsDir        string // per-project file backups, keyed by project ID
+	sessionMu         sync.Mutex
+	session           *session.Recorder
+	now               func() time.Time // wall clock override; nil uses time.Now
+	backendMu         sync.RWMutex
+	backend           execution.Backend
+	runStages         execution.Pipeline // stages around every run; see runPipeline
+	runStagesOnce     sync.Once
+	auditLog          *audit.Log // nil disables auditing
+	snippetSyncDir    string     // snippet sync state and git caches
+	snippetSyncMu     sync.Mutex
+	shareMu           sync.Mutex
+	shareServer       *share.Server         // running read-only project share
+	webhooks          *webhook.Dispatcher   // nil disables run webhooks
+	plugins           *plugins.Host         // nil disables plugins
+	idle              *idle.Monitor         // nil disables idle pausing
+	power             powerWatch            // power source, for power saving
+	capabilities      capabilityState       // degraded-mode tracking
+	runCache          runCache              // results of cacheable runs
+	sumChecks         sumCheckCache         // go.sum verification per project
+	toolchainVersions toolchainVersionCache // go version per toolchain binary
+	snippetCache      snippetCache          // generated snippet file location and budget
 }
 
 type resolvedRunRequest struct {
 	projectID        string
 	projectPath      string
+	packagePath      string
 	source           string
 	workingDirectory string
 	toolchain        string
 	environment      map[string]string
 	timeout          time.Duration
+	limits           execution.RunLimits
+	teePath          string
+	outputEncoding   string
+	trusted          bool   // whether the project may run a worker
+	runGuard         string // run confirmation policy; empty means confirm
+	seed             *int64
+	frozenTime       time.Time
+	args             []string
+	highlights       []storage.HighlightRule // output highlight rules
+	shutdown         execution.Shutdown
+	shutdownGrace    time.Duration // zero keeps the backend default
+	cpuAffinity      []int
+	networkAllow     []string // hosts runs may reach without a prompt
+	files            []string // absolute package files built with the snippet
+	moduleOverlay    bool     // run against private copies of go.mod and go.sum
 }
 
 // New creates an application with default local dependencies.
@@ -79,54 +159,127 @@ func NewWithDataRoot(dataRoot string) *Application {
 		dataRoot = defaultDataRoot()
 	}
 	return &Application{
-		logger:    slog.Default(),
-		store:     storage.New(filepath.Join(dataRoot, "state")),
-		telemetry: telemetry.NewRecorder(),
+		logger:         slog.Default(),
+		store:          storage.New(filepath.Join(dataRoot, "state")),
+		telemetry:      telemetry.NewRecorder(),
+		toolBinDir:     download.NewManager(filepath.Join(dataRoot, "toolchain")).ToolBinDir(),
+		updates:        update.NewUpdater(filepath.Join(dataRoot, "updates")),
+		sessionDir:     filepath.Join(dataRoot, "sessions"),
+		artifactsDir:   filepath.Join(dataRoot, "artifacts"),
+		backupsDir:     filepath.Join(dataRoot, "backups"),
+		auditLog:       audit.New(filepath.Join(dataRoot, "audit", "audit.log")),
+		snippetSyncDir: filepath.Join(dataRoot, "snippet-sync"),
+		snippetCache:   snippetCache{root: filepath.Join(dataRoot, "snippet-cache")},
+		webhooks:       webhook.NewDispatcher(slog.Default()),
+		plugins:        plugins.NewHost(filepath.Join(dataRoot, "plugins"), update.CurrentVersion, slog.Default()),
+		idle:           idle.NewMonitor(slog.Default()),
 	}
 }
 
+// SetClock replaces the wall clock used for run timestamps and worker
+// lifecycle policy so harnesses can drive time deterministically. Call it
+// before Start.
+func (a *Application) SetClock(now func() time.Time) {
+	a.now = now
+}
+
+// clock returns the current UTC time from the configured clock.
+func (a *Application) clock() time.Time {
+	if a.now == nil {
+		return time.Now().UTC()
+	}
+	return a.now().UTC()
+}
+
 // Start boots storage and records startup metrics.
 func (a *Application) Start(ctx context.Context) error {
 	startedAt := time.Now()
-	if err := a.store.Bootstrap(ctx); err != nil {
-		return fmt.Errorf("bootstrap storage: %w", err)
-	}
+	// Unreadable storage degrades the application instead of failing it;
+	// see RequireCapabilities.
+	a.probeCapabilities(ctx, CapabilityStorage)
 
 	// Prepend configured tool paths to PATH so exec.LookPath finds them.
 	a.applyToolchainPaths(ctx)
 
+	// Reclaim scratch workspaces and run caches left by crashed sessions.
+	sweep := a.sweepOrphans(ctx, os.TempDir())
+
 	// Create scratch workspace for projectless mode
-	scratchDir := filepath.Join(os.TempDir(), fmt.Sprintf("gopoke-scratch-%d", os.Getpid()))
+	scratchDir := filepath.Join(os.TempDir(), scratchDirPrefix+strconv.Itoa(os.Getpid()))
 	if err := os.MkdirAll(scratchDir, 0o700); err != nil {
 		return fmt.Errorf("create scratch workspace: %w", err)
 	}
-	goModContent := "module gopoke-scratch\n\ngo 1.22\n"
+	// Scratch snippets can import "gopoke" for helpers such as gopoke.Dump.
+	if err := snippethelper.Install(scratchDir); err != nil {
+		return fmt.Errorf("install scratch helpers: %w", err)
+	}
+	goModContent := "module gopoke-scratch\n\ngo 1.22\n\n" + snippethelper.GoModDirectives("./"+snippethelper.DirName)
 	if err := os.WriteFile(filepath.Join(scratchDir, "go.mod"), []byte(goModContent), 0o644); err != nil {
 		return fmt.Errorf("write scratch go.mod: %w", err)
 	}
 	a.scratchDir = scratchDir
 
 	a.projects = project.NewService(a.store)
-	a.workers = runner.NewManager()
+	a.workers = runner.NewManager(runner.WithLogHandler(a.workerLogs), runner.WithClock(a.now))
 	a.lspManager = lsp.NewManager()
+	a.lspManager.SetTokenSource(a.editorTokenSource())
+	a.startPowerWatch()
+	if gs, err := a.store.GetSettings(ctx); err == nil {
+		a.applyRuntimeSettings(gs)
+	} else {
+		a.logger.Warn("load global settings for runtime policy", "error", err)
+	}
 	a.activeRuns = make(map[string]context.CancelFunc)
+	if a.plugins != nil {
+		if err := a.plugins.Start(ctx); err != nil {
+			a.logger.Warn("start plugins failed", "error", err)
+		}
+	}
+	a.probeCapabilities(ctx, CapabilityToolchain, CapabilityGopls)
+	a.startCapabilityWatch()
+	a.startIdleMonitor()
 	a.startupMetrics = a.telemetry.MarkStartupComplete(startedAt)
+	a.startupMetrics.SweptDirs = sweep.dirs
+	a.startupMetrics.ReclaimedBytes = sweep.reclaimedBytes
 	a.logger.Info(
 		"application started",
 		"storagePath", a.store.Path(),
 		"startupDurationMs", a.startupMetrics.Duration.Milliseconds(),
+		"sweptDirs", sweep.dirs,
+		"reclaimedBytes", sweep.reclaimedBytes,
 	)
 	return nil
 }
 
 // Stop shuts down workers and LSP, then releases resources.
 func (a *Application) Stop(ctx context.Context) error {
+	if a.idle != nil {
+		a.idle.Stop()
+	}
+	a.stopPowerWatch()
+	a.stopCapabilityWatch()
+	a.snippetCache.stop()
+	a.closeSessionRecording()
+	if err := a.StopProjectShare(ctx); err != nil {
+		a.logger.Warn("stop project share failed", "error", err)
+	}
+	if a.webhooks != nil {
+		if err := a.webhooks.Close(ctx); err != nil {
+			a.logger.Warn("flush run webhooks failed", "error", err)
+		}
+	}
+	if err := a.telemetry.Shutdown(ctx); err != nil {
+		a.logger.Warn("flush telemetry spans failed", "error", err)
+	}
 	if a.scratchDir != "" {
 		os.RemoveAll(a.scratchDir)
 	}
 	if a.lspManager != nil {
 		a.lspManager.Stop()
 	}
+	if a.plugins != nil {
+		a.plugins.Stop()
+	}
 	if a.workers != nil {
 		if err := a.workers.StopAll(ctx); err != nil {
 			return fmt.Errorf("stop worker manager: %w", err)
@@ -135,6 +288,12 @@ func (a *Application) Stop(ctx context.Context) error {
 	return nil
 }
 
+// StartupMetrics returns the timing of the last Start and the space its
+// sweep of orphaned directories reclaimed.
+func (a *Application) StartupMetrics() telemetry.StartupEvent {
+	return a.startupMetrics
+}
+
 // ScratchDir returns the path to the scratch workspace for projectless mode.
 func (a *Application) ScratchDir() string {
 	return a.scratchDir
@@ -162,6 +321,8 @@ func (a *Application) OpenProject(ctx context.Context, path string) (project.Ope
 	if err != nil {
 		return project.OpenProjectResult{}, fmt.Errorf("open project: %w", err)
 	}
+	a.rememberProjectVolume(result.Project.Path, result.Filesystem)
+	a.recordSessionEvent(session.Event{Kind: session.KindOpenProject, ProjectPath: resolvedPath})
 	return result, nil
 }
 
@@ -261,9 +422,14 @@ func (a *Application) SetProjectWorkingDirectory(ctx context.Context, projectPat
 	return updated, nil
 }
 
-// AvailableToolchains returns detected Go toolchains from PATH.
+// AvailableToolchains returns detected Go toolchains from PATH and the
+// managed tool directory, where gotip is installed.
 func (a *Application) AvailableToolchains(ctx context.Context) ([]project.ToolchainInfo, error) {
-	toolchains, err := project.DiscoverToolchains(ctx)
+	var extraDirs []string
+	if a.toolBinDir != "" {
+		extraDirs = append(extraDirs, a.toolBinDir)
+	}
+	toolchains, err := project.DiscoverToolchains(ctx, extraDirs...)
 	if err != nil {
 		return nil, fmt.Errorf("discover toolchains: %w", err)
 	}
@@ -278,7 +444,14 @@ func (a *Application) SetProjectToolchain(ctx context.Context, projectPath strin
 	}
 	resolvedToolchain, err := project.ResolveToolchainBinary(toolchain)
 	if err != nil {
-		return storage.ProjectRecord{}, fmt.Errorf("resolve selected toolchain: %w", err)
+		tipPath, found := "", false
+		if strings.TrimSpace(toolchain) == project.ToolchainTip {
+			tipPath, found = a.lookupTool("gotip")
+		}
+		if !found {
+			return storage.ProjectRecord{}, fmt.Errorf("resolve selected toolchain: %w", err)
+		}
+		resolvedToolchain = tipPath
 	}
 	updated, err := a.store.UpdateProjectToolchain(ctx, projectRecord.Path, resolvedToolchain)
 	if err != nil {
@@ -287,6 +460,30 @@ func (a *Application) SetProjectToolchain(ctx context.Context, projectPath strin
 	return updated, nil
 }
 
+// SetProjectRunLimits stores a project's timeout and output cap overrides.
+// Zero inherits the global setting; other values must be within bounds.
+func (a *Application) SetProjectRunLimits(ctx context.Context, projectPath string, timeoutMS int64, maxOutputBytes int64) (storage.ProjectRecord, error) {
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	if timeoutMS != 0 {
+		if err := settings.ValidateTimeoutMS(timeoutMS); err != nil {
+			return storage.ProjectRecord{}, err
+		}
+	}
+	if maxOutputBytes != 0 {
+		if err := settings.ValidateMaxOutputBytes(maxOutputBytes); err != nil {
+			return storage.ProjectRecord{}, err
+		}
+	}
+	updated, err := a.store.UpdateProjectRunLimits(ctx, projectRecord.Path, timeoutMS, maxOutputBytes)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project run limits: %w", err)
+	}
+	return updated, nil
+}
+
 // ProjectSnippets returns snippets for one project.
 func (a *Application) ProjectSnippets(ctx context.Context, projectPath string) ([]storage.SnippetRecord, error) {
 	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
@@ -300,14 +497,34 @@ func (a *Application) ProjectSnippets(ctx context.Context, projectPath string) (
 	return snippets, nil
 }
 
-// SaveProjectSnippet creates or updates one snippet in project scope.
+// SaveProjectSnippet creates or updates one snippet in project scope. An
+// empty name falls back to the snippet's //gopoke:name directive.
 func (a *Application) SaveProjectSnippet(ctx context.Context, projectPath string, snippetID string, name string, content string) (storage.SnippetRecord, error) {
 	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
 	if err != nil {
 		return storage.SnippetRecord{}, err
 	}
+	snippetID = strings.TrimSpace(snippetID)
+	var original []byte
+	if snippetID != "" {
+		existing, found, err := a.store.SnippetByID(ctx, snippetID)
+		if err != nil {
+			return storage.SnippetRecord{}, fmt.Errorf("load project snippet: %w", err)
+		}
+		if found {
+			original = []byte(existing.Content)
+		}
+	}
+	content = a.normalizeForSave(ctx, content, original)
+	meta, err := snippetmeta.Parse(content)
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	if strings.TrimSpace(name) == "" {
+		name = meta.Name
+	}
 	snippet, err := a.store.SaveSnippet(ctx, storage.SnippetRecord{
-		ID:        strings.TrimSpace(snippetID),
+		ID:        snippetID,
 		ProjectID: projectRecord.ID,
 		Name:      name,
 		Content:   content,
@@ -315,6 +532,12 @@ func (a *Application) SaveProjectSnippet(ctx context.Context, projectPath string
 	if err != nil {
 		return storage.SnippetRecord{}, fmt.Errorf("save project snippet: %w", err)
 	}
+	a.recordSessionEvent(session.Event{
+		Kind:        session.KindSaveSnippet,
+		ProjectPath: projectRecord.Path,
+		Name:        snippet.Name,
+		Content:     content,
+	})
 	return snippet, nil
 }
 
@@ -324,17 +547,20 @@ func (a *Application) DeleteProjectSnippet(ctx context.Context, projectPath stri
 	if err != nil {
 		return err
 	}
-	snippet, found, err := a.store.SnippetByID(ctx, snippetID)
-	if err != nil {
-		return fmt.Errorf("load project snippet: %w", err)
-	}
-	if !found {
+	// The ownership check and the delete share one update, so nothing can
+	// change the snippet in between.
+	err = a.store.Update(ctx, func(tx *storage.Tx) error {
+		snippet, found := tx.SnippetByID(snippetID)
+		if !found {
+			return nil
+		}
+		if snippet.ProjectID != projectRecord.ID {
+			return fmt.Errorf("snippet does not belong to selected project")
+		}
+		tx.DeleteSnippet(snippetID)
 		return nil
-	}
-	if snippet.ProjectID != projectRecord.ID {
-		return fmt.Errorf("snippet does not belong to selected project")
-	}
-	if err := a.store.DeleteSnippet(ctx, snippetID); err != nil {
+	})
+	if err != nil {
 		return fmt.Errorf("delete project snippet: %w", err)
 	}
 	return nil
@@ -358,15 +584,44 @@ func (a *Application) RunSnippet(
 	request execution.RunRequest,
 	onStdoutChunk execution.StdoutChunkHandler,
 	onStderrChunk execution.StderrChunkHandler,
+) (execution.Result, error) {
+	if request.Golden && strings.TrimSpace(request.SnippetID) == "" {
+		return execution.Result{}, fmt.Errorf("golden runs need a saved snippet")
+	}
+	request.RunID = strings.TrimSpace(request.RunID)
+	if request.RunID == "" {
+		request.RunID = generateRunID()
+	}
+	eventLog := a.beginRunEvents(request)
+	onStdoutChunk, onStderrChunk = eventLog.outputHandlers(onStdoutChunk, onStderrChunk)
+	ctx, span := a.telemetry.StartSpan(ctx, "run")
+	span.SetAttribute("gopoke.run_id", request.RunID)
+	result, err := a.runSnippet(ctx, request, onStdoutChunk, onStderrChunk)
+	if err == nil && !result.ConfirmationRequired {
+		a.checkGolden(ctx, request, &result)
+		span.SetAttribute("gopoke.run.status", runStatusFromResult(result))
+		span.SetAttribute("gopoke.run.exit_code", strconv.Itoa(result.ExitCode))
+	}
+	span.End(err)
+	eventLog.finish(result, err)
+	a.recordAudit(audit.ActionRun, strings.TrimSpace(request.ProjectPath), runAuditParams(request, result), err)
+	if err == nil && !result.ConfirmationRequired {
+		a.recordSessionRun(request, result)
+		a.notifyRunWebhooks(request, result)
+	}
+	return result, err
+}
+
+func (a *Application) runSnippet(
+	ctx context.Context,
+	request execution.RunRequest,
+	onStdoutChunk execution.StdoutChunkHandler,
+	onStderrChunk execution.StderrChunkHandler,
 ) (execution.Result, error) {
 	if err := ctx.Err(); err != nil {
 		return execution.Result{}, fmt.Errorf("run snippet context: %w", err)
 	}
-	runID := strings.TrimSpace(request.RunID)
-	if runID == "" {
-		runID = generateRunID()
-		request.RunID = runID
-	}
+	runID := request.RunID
 
 	runCtx, cancel := context.WithCancel(ctx)
 	if err := a.registerActiveRun(runID, cancel); err != nil {
@@ -377,20 +632,22 @@ func (a *Application) RunSnippet(
 		cancel()
 		a.unregisterActiveRun(runID)
 	}()
-	runStartedAt := time.Now().UTC()
+	runStartedAt := a.clock()
 
+	_, resolveSpan := a.telemetry.StartSpan(ctx, "run.resolve")
 	resolvedRequest, err := a.resolveRunRequest(runCtx, request)
+	resolveSpan.End(err)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
-			result := canceledRunResult(runStartedAt)
-			if recordErr := a.recordRunResult(ctx, runID, "", runStartedAt, result); recordErr != nil {
+			result := a.canceledRunResult(runStartedAt)
+			if recordErr := a.recordRunResult(ctx, runID, resolvedRunRequest{}, nil, runStartedAt, result); recordErr != nil {
 				a.logger.Warn("record run metadata failed", "runID", runID, "error", recordErr)
 			}
 			return result, nil
 		}
 		if errors.Is(err, context.DeadlineExceeded) {
-			result := timedOutRunResult(runStartedAt)
-			if recordErr := a.recordRunResult(ctx, runID, "", runStartedAt, result); recordErr != nil {
+			result := a.timedOutRunResult(runStartedAt)
+			if recordErr := a.recordRunResult(ctx, runID, resolvedRunRequest{}, nil, runStartedAt, result); recordErr != nil {
 				a.logger.Warn("record run metadata failed", "runID", runID, "error", recordErr)
 			}
 			return result, nil
@@ -398,18 +655,68 @@ func (a *Application) RunSnippet(
 		return execution.Result{}, fmt.Errorf("resolve run request: %w", err)
 	}
 
-	if a.workers != nil {
-		if _, err := a.workers.StartWorker(runCtx, resolvedRequest.projectPath); err != nil {
+	findings, held := a.guardRun(request, resolvedRequest)
+	if held {
+		return execution.Result{
+			GuardFindings:        findings,
+			ConfirmationRequired: true,
+			Limits:               resolvedRequest.limits,
+		}, nil
+	}
+	if !request.IgnoreModuleWarning {
+		if warning := a.checkModuleSums(runCtx, resolvedRequest); warning != nil {
+			return execution.Result{
+				GuardFindings:        findings,
+				ModuleWarning:        warning,
+				ConfirmationRequired: true,
+				Limits:               resolvedRequest.limits,
+			}, nil
+		}
+	}
+
+	runEnvironment := a.runEnvironment(runCtx, resolvedRequest)
+
+	benchmarkSource, err := prepareBenchmarkSnippet(&resolvedRequest)
+	if err != nil {
+		return execution.Result{}, err
+	}
+
+	cacheKey := ""
+	if snippetCacheable(request.Source) && resolvedRequest.teePath == "" {
+		cacheKey = a.runCacheKey(runCtx, request, resolvedRequest)
+		if cached, ok := a.runCache.get(cacheKey); ok && !request.RefreshCache {
+			cached.Cached = true
+			cached.GuardFindings = findings
+			// Rules may have changed since the result was cached.
+			a.highlightResult(&cached, resolvedRequest.highlights)
+			if onStdoutChunk != nil && cached.Stdout != "" {
+				onStdoutChunk(cached.Stdout)
+			}
+			if onStderrChunk != nil && cached.Stderr != "" {
+				onStderrChunk(cached.Stderr)
+			}
+			if err := a.recordRunResult(ctx, runID, resolvedRequest, runEnvironment, runStartedAt, cached); err != nil {
+				a.logger.Warn("record run metadata failed", "runID", runID, "error", err)
+			}
+			return cached, nil
+		}
+	}
+
+	if a.workers != nil && resolvedRequest.trusted {
+		_, workerSpan := a.telemetry.StartSpan(ctx, "run.worker")
+		_, err := a.workers.StartWorker(runCtx, resolvedRequest.projectPath)
+		workerSpan.End(err)
+		if err != nil {
 			if errors.Is(err, context.Canceled) {
-				result := canceledRunResult(runStartedAt)
-				if recordErr := a.recordRunResult(ctx, runID, resolvedRequest.projectID, runStartedAt, result); recordErr != nil {
+				result := a.canceledRunResult(runStartedAt)
+				if recordErr := a.recordRunResult(ctx, runID, resolvedRequest, runEnvironment, runStartedAt, result); recordErr != nil {
 					a.logger.Warn("record run metadata failed", "runID", runID, "error", recordErr)
 				}
 				return result, nil
 			}
 			if errors.Is(err, context.DeadlineExceeded) {
-				result := timedOutRunResult(runStartedAt)
-				if recordErr := a.recordRunResult(ctx, runID, resolvedRequest.projectID, runStartedAt, result); recordErr != nil {
+				result := a.timedOutRunResult(runStartedAt)
+				if recordErr := a.recordRunResult(ctx, runID, resolvedRequest, runEnvironment, runStartedAt, result); recordErr != nil {
 					a.logger.Warn("record run metadata failed", "runID", runID, "error", recordErr)
 				}
 				return result, nil
@@ -418,45 +725,137 @@ func (a *Application) RunSnippet(
 		}
 	}
 
-	result, err := execution.RunGoSnippetWithOptions(
-		runCtx,
+	var tee io.Writer
+	if resolvedRequest.teePath != "" {
+		if err := os.MkdirAll(filepath.Dir(resolvedRequest.teePath), 0o755); err != nil {
+			return execution.Result{}, fmt.Errorf("create tee directory: %w", err)
+		}
+		teeFile, err := os.Create(resolvedRequest.teePath)
+		if err != nil {
+			return execution.Result{}, fmt.Errorf("open tee file: %w", err)
+		}
+		defer teeFile.Close()
+		tee = teeFile
+	}
+
+	overlay, err := a.prepareModuleOverlay(runID, resolvedRequest)
+	if err != nil {
+		return execution.Result{}, err
+	}
+
+	backend := a.executionBackend()
+	if a.workers != nil && resolvedRequest.trusted && backend.Name() == execution.BackendGo {
+		// Trusted projects run in their worker, over the worker protocol.
+		backend = a.workers.Backend()
+	}
+
+	_, executeSpan := a.telemetry.StartSpan(ctx, "run.execute")
+	a.snippetCache.useMu.RLock()
+	result, err := a.runPipeline().Backend(backend).Run(
+		withRunScope(runCtx, runScope{runID: runID, resolved: resolvedRequest}),
 		resolvedRequest.projectPath,
 		resolvedRequest.source,
 		execution.RunOptions{
-			WorkingDirectory: resolvedRequest.workingDirectory,
-			Environment:      resolvedRequest.environment,
-			Toolchain:        resolvedRequest.toolchain,
-			Timeout:          resolvedRequest.timeout,
-			OnStdoutChunk:    onStdoutChunk,
-			OnStderrChunk:    onStderrChunk,
-			MaxStdoutBytes:   execution.DefaultMaxOutputBytes,
-			MaxStderrBytes:   execution.DefaultMaxOutputBytes,
+			WorkingDirectory:  resolvedRequest.workingDirectory,
+			Environment:       resolvedRequest.environment,
+			Toolchain:         resolvedRequest.toolchain,
+			Timeout:           resolvedRequest.timeout,
+			OnStdoutChunk:     onStdoutChunk,
+			OnStderrChunk:     onStderrChunk,
+			MaxStdoutBytes:    int(resolvedRequest.limits.MaxOutputBytes),
+			MaxStderrBytes:    int(resolvedRequest.limits.MaxOutputBytes),
+			Tee:               tee,
+			OutputEncoding:    resolvedRequest.outputEncoding,
+			TimeZone:          request.TimeZone,
+			Locale:            request.Locale,
+			Seed:              resolvedRequest.seed,
+			FrozenTime:        resolvedRequest.frozenTime,
+			ExportDeadline:    request.ExportDeadline,
+			Shutdown:          resolvedRequest.shutdown,
+			KillGracePeriod:   resolvedRequest.shutdownGrace,
+			CPUAffinity:       resolvedRequest.cpuAffinity,
+			LowPriority:       a.runLowPriority(request),
+			MaxDiskWriteBytes: resolvedRequest.limits.MaxDiskWriteBytes,
+			MaxProcesses:      int(resolvedRequest.limits.MaxProcesses),
+			MaxOpenFiles:      int(resolvedRequest.limits.MaxOpenFiles),
+			Args:              resolvedRequest.args,
+			Files:             resolvedRequest.files,
+			ModFile:           overlay.modFile(),
+			CacheDir:          a.snippetCache.dir(resolvedRequest.projectID),
+			OnStart: func(pid int) {
+				a.setActiveRunPID(runID, pid)
+			},
 		},
 	)
+	a.snippetCache.useMu.RUnlock()
+	executeSpan.End(err)
+	a.scheduleSnippetCacheEviction()
+	networkDenied := a.takeNetworkDenial(runID)
 	if err != nil {
+		overlay.discard()
 		if errors.Is(err, context.Canceled) {
-			result := canceledRunResult(runStartedAt)
-			if recordErr := a.recordRunResult(ctx, runID, resolvedRequest.projectID, runStartedAt, result); recordErr != nil {
+			result := a.canceledRunResult(runStartedAt)
+			result.Limits = resolvedRequest.limits
+			if recordErr := a.recordRunResult(ctx, runID, resolvedRequest, runEnvironment, runStartedAt, result); recordErr != nil {
 				a.logger.Warn("record run metadata failed", "runID", runID, "error", recordErr)
 			}
 			return result, nil
 		}
 		if errors.Is(err, context.DeadlineExceeded) {
-			result := timedOutRunResult(runStartedAt)
-			if recordErr := a.recordRunResult(ctx, runID, resolvedRequest.projectID, runStartedAt, result); recordErr != nil {
+			result := a.timedOutRunResult(runStartedAt)
+			result.Limits = resolvedRequest.limits
+			if recordErr := a.recordRunResult(ctx, runID, resolvedRequest, runEnvironment, runStartedAt, result); recordErr != nil {
 				a.logger.Warn("record run metadata failed", "runID", runID, "error", recordErr)
 			}
 			return result, nil
 		}
 		return execution.Result{}, fmt.Errorf("run snippet: %w", err)
 	}
-	result.Diagnostics = convertDiagnostics(diagnostics.ParseAll(result.Stderr))
-
-	cleanStdout, richBlocks := richoutput.Parse(result.Stdout)
-	result.CleanStdout = cleanStdout
-	result.RichBlocks = convertRichBlocks(richBlocks)
-
-	if err := a.recordRunResult(ctx, runID, resolvedRequest.projectID, runStartedAt, result); err != nil {
+	result.Limits = resolvedRequest.limits
+	result.TeeFile = resolvedRequest.teePath
+	result.NetworkDenied = networkDenied
+	result.GuardFindings = findings
+	result.ModuleOverlay = overlay.finish()
+	if result.TeeError != "" {
+		a.logger.Warn("tee run output failed", "runID", runID, "path", result.TeeFile, "error", result.TeeError)
+	}
+	plainText := a.plainText.Load()
+	if plainText {
+		stripANSI(&result)
+	}
+	a.localizeRunResult(&result)
+
+	if plainText {
+		// Plain-text mode skips rich blocks so output reads exactly as printed.
+		result.PlainText = true
+		result.CleanStdout = result.Stdout
+	} else if result.RawStdout != nil {
+		// Binary output carries no markers; show its bytes instead.
+		result.CleanStdout = result.Stdout
+		result.RichBlocks = convertRichBlocks([]richoutput.RichBlock{richoutput.Hexdump(result.RawStdout)})
+	} else {
+		cleanStdout, richBlocks := richoutput.Parse(result.Stdout)
+		result.CleanStdout = cleanStdout
+		result.RichBlocks = convertRichBlocks(richBlocks)
+	}
+	if !plainText {
+		// Plain-text mode shows every line as printed.
+		result.StdoutFolds = outputfold.Folds(result.CleanStdout)
+		result.StderrFolds = outputfold.Folds(result.Stderr)
+	}
+	if benchmarkSource != "" {
+		result.Benchmarks = benchsnippet.Annotate(benchmarkSource, benchsnippet.ParseResults(result.Stdout))
+	}
+	a.highlightResult(&result, resolvedRequest.highlights)
+
+	if cacheKey != "" && cacheableResult(result) {
+		a.runCache.put(cacheKey, resolvedRequest.projectID, result)
+	}
+
+	_, recordSpan := a.telemetry.StartSpan(ctx, "run.record")
+	err = a.recordRunResult(ctx, runID, resolvedRequest, runEnvironment, runStartedAt, result)
+	recordSpan.End(err)
+	if err != nil {
 		a.logger.Warn("record run metadata failed", "runID", runID, "error", err)
 	}
 	return result, nil
@@ -493,26 +892,81 @@ func (a *Application) resolveRunRequest(ctx context.Context, request execution.R
 	if strings.TrimSpace(request.Source) == "" {
 		return resolvedRunRequest{}, fmt.Errorf("snippet is required")
 	}
-	timeout := execution.DefaultTimeout
-	if request.TimeoutMS > 0 {
-		timeout = time.Duration(request.TimeoutMS) * time.Millisecond
+	if err := execution.ValidateTimeZone(request.TimeZone); err != nil {
+		return resolvedRunRequest{}, err
+	}
+	if err := execution.ValidateLocale(request.Locale); err != nil {
+		return resolvedRunRequest{}, err
+	}
+	frozenTime, err := execution.ParseFrozenTime(request.FrozenTime)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+	shutdown, shutdownGrace, err := resolveShutdown(request)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+	cpuAffinity, err := execution.NormalizeCPUAffinity(request.CPUAffinity)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+	params, err := snippetparam.Resolve(request.Source, request.Params)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+	meta, err := snippetmeta.Parse(request.Source)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+	for key := range request.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return resolvedRunRequest{}, fmt.Errorf("invalid run environment variable name %q", key)
+		}
 	}
-	// Projectless mode: use scratch workspace
+	// Projectless mode: use scratch workspace; the scratch module has no
+	// packages to target.
 	if strings.TrimSpace(request.ProjectPath) == "" {
 		if a.scratchDir == "" {
 			return resolvedRunRequest{}, fmt.Errorf("scratch workspace not initialized")
 		}
-		resolvedToolchain, err := project.ResolveToolchainBinary("go")
+		if len(request.Files) > 0 {
+			return resolvedRunRequest{}, fmt.Errorf("run files need a project")
+		}
+		limits, err := a.resolveRunLimits(ctx, request, meta, storage.ProjectRecord{})
+		if err != nil {
+			return resolvedRunRequest{}, err
+		}
+		resolvedToolchain, err := a.resolveToolchain("go")
 		if err != nil {
 			return resolvedRunRequest{}, fmt.Errorf("resolve default toolchain: %w", err)
 		}
+		teePath, err := a.resolveTeePath(a.scratchDir, "", request.TeeToFile)
+		if err != nil {
+			return resolvedRunRequest{}, err
+		}
+		environment := make(map[string]string)
+		if err := applyExperiments(environment, request.Experiments, nil); err != nil {
+			return resolvedRunRequest{}, err
+		}
+		maps.Copy(environment, meta.Env)
+		maps.Copy(environment, request.Env)
+		maps.Copy(environment, params.Environment)
 		return resolvedRunRequest{
 			projectPath:      a.scratchDir,
 			source:           request.Source,
 			workingDirectory: a.scratchDir,
 			toolchain:        resolvedToolchain,
-			environment:      make(map[string]string),
-			timeout:          timeout,
+			environment:      environment,
+			timeout:          time.Duration(limits.TimeoutMS) * time.Millisecond,
+			limits:           limits,
+			teePath:          teePath,
+			trusted:          true,
+			seed:             request.Seed,
+			frozenTime:       frozenTime,
+			args:             params.Args,
+			shutdown:         shutdown,
+			shutdownGrace:    shutdownGrace,
+			cpuAffinity:      cpuAffinity,
 		}, nil
 	}
 	absoluteProjectPath, err := resolveInputPath(request.ProjectPath)
@@ -533,6 +987,9 @@ func (a *Application) resolveRunRequest(ctx context.Context, request execution.R
 	}
 
 	selectedPackage := strings.TrimSpace(request.PackagePath)
+	if selectedPackage == "" {
+		selectedPackage = meta.Target
+	}
 	if selectedPackage == "" && foundProject {
 		selectedPackage = strings.TrimSpace(projectRecord.DefaultPkg)
 	}
@@ -548,6 +1005,9 @@ func (a *Application) resolveRunRequest(ctx context.Context, request execution.R
 		}
 		foundProject = true
 	}
+	// The record is the project's identity, so a path spelled with other
+	// case shares its worker and language server.
+	absoluteProjectPath = projectRecord.Path
 
 	workingDirectory, err := resolveWorkingDirectory(ctx, absoluteProjectPath, selectedPackage, projectRecord.WorkingDir)
 	if err != nil {
@@ -561,27 +1021,235 @@ func (a *Application) resolveRunRequest(ctx context.Context, request execution.R
 			return resolvedRunRequest{}, fmt.Errorf("load project env: %w", err)
 		}
 	}
+	files, err := resolveRunFiles(absoluteProjectPath, request.Files)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+	envMap, err = gopathEnvironment(ctx, absoluteProjectPath, envMap)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+	if err := applyExperiments(envMap, request.Experiments, projectRecord.Experiments); err != nil {
+		return resolvedRunRequest{}, err
+	}
+	maps.Copy(envMap, meta.Env)
+	maps.Copy(envMap, request.Env)
+	maps.Copy(envMap, params.Environment)
 
 	selectedToolchain := strings.TrimSpace(projectRecord.Toolchain)
 	if selectedToolchain == "" {
 		selectedToolchain = "go"
 	}
-	resolvedToolchain, err := project.ResolveToolchainBinary(selectedToolchain)
+	resolvedToolchain, err := a.resolveToolchain(selectedToolchain)
 	if err != nil {
 		return resolvedRunRequest{}, fmt.Errorf("resolve project toolchain: %w", err)
 	}
 
+	limits, err := a.resolveRunLimits(ctx, request, meta, projectRecord)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+	limits = a.adjustLimitsForFilesystem(absoluteProjectPath, limits)
+	teePath, err := a.resolveTeePath(absoluteProjectPath, projectRecord.ID, request.TeeToFile)
+	if err != nil {
+		return resolvedRunRequest{}, err
+	}
+
 	return resolvedRunRequest{
 		projectID:        projectRecord.ID,
 		projectPath:      absoluteProjectPath,
+		packagePath:      selectedPackage,
 		source:           request.Source,
 		workingDirectory: workingDirectory,
 		toolchain:        resolvedToolchain,
 		environment:      envMap,
-		timeout:          timeout,
+		timeout:          time.Duration(limits.TimeoutMS) * time.Millisecond,
+		limits:           limits,
+		teePath:          teePath,
+		outputEncoding:   projectRecord.OutputEncoding,
+		trusted:          projectRecord.Trusted(),
+		runGuard:         projectRecord.RunGuard,
+		seed:             request.Seed,
+		frozenTime:       frozenTime,
+		args:             params.Args,
+		highlights:       projectRecord.Highlights,
+		shutdown:         shutdown,
+		shutdownGrace:    shutdownGrace,
+		cpuAffinity:      cpuAffinity,
+		networkAllow:     projectRecord.NetworkAllow,
+		files:            files,
+		moduleOverlay:    projectRecord.ModuleOverlay,
 	}, nil
 }
 
+// resolveTeePath validates a tee target. Relative paths land in the project's
+// artifacts directory; absolute paths must stay inside the project or it.
+func (a *Application) resolveTeePath(projectPath string, projectID string, teeToFile string) (string, error) {
+	teeToFile = strings.TrimSpace(teeToFile)
+	if teeToFile == "" {
+		return "", nil
+	}
+	artifactsDir := projectDataDir(a.artifactsDir, projectID)
+	target := filepath.Clean(teeToFile)
+	if !filepath.IsAbs(target) {
+		base := artifactsDir
+		if base == "" {
+			base = projectPath
+		}
+		target = filepath.Join(base, target)
+	}
+
+	allowed := false
+	for _, root := range []string{projectPath, artifactsDir} {
+		if root != "" && pathWithin(root, target) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return "", fmt.Errorf("tee file must be inside the project or its artifacts directory")
+	}
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		return "", fmt.Errorf("tee file path is a directory")
+	}
+	return target, nil
+}
+
+// pathWithin reports whether target is strictly below root.
+func pathWithin(root string, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolveRunLimits picks the effective timeout and output cap. A request
+// timeout wins over the one meta, the snippet's parsed header, declares,
+// then the project override, then global settings.
+func (a *Application) resolveRunLimits(ctx context.Context, request execution.RunRequest, meta snippetmeta.Meta, projectRecord storage.ProjectRecord) (execution.RunLimits, error) {
+	limits := execution.RunLimits{
+		TimeoutMS:      execution.DefaultTimeout.Milliseconds(),
+		TimeoutSource:  execution.LimitSourceDefault,
+		MaxOutputBytes: execution.DefaultMaxOutputBytes,
+		OutputSource:   execution.LimitSourceDefault,
+	}
+	if gs, err := a.store.GetSettings(ctx); err == nil {
+		if gs.DefaultTimeoutSet {
+			limits.TimeoutMS = gs.DefaultTimeoutMS
+			limits.TimeoutSource = execution.LimitSourceGlobal
+		}
+		if gs.MaxOutputSet {
+			limits.MaxOutputBytes = gs.MaxOutputBytes
+			limits.OutputSource = execution.LimitSourceGlobal
+		}
+		if gs.MaxDiskWriteBytes > 0 {
+			limits.MaxDiskWriteBytes = gs.MaxDiskWriteBytes
+			limits.DiskWriteSource = execution.LimitSourceGlobal
+		}
+		if gs.MaxRunProcesses > 0 {
+			limits.MaxProcesses = gs.MaxRunProcesses
+			limits.ProcessSource = execution.LimitSourceGlobal
+		}
+		if gs.MaxRunOpenFiles > 0 {
+			limits.MaxOpenFiles = gs.MaxRunOpenFiles
+			limits.OpenFilesSource = execution.LimitSourceGlobal
+		}
+	} else {
+		a.logger.Warn("load global settings for run limits", "error", err)
+	}
+
+	if projectRecord.TimeoutMS > 0 {
+		limits.TimeoutMS = projectRecord.TimeoutMS
+		limits.TimeoutSource = execution.LimitSourceProject
+	}
+	if projectRecord.MaxOutputBytes > 0 {
+		limits.MaxOutputBytes = projectRecord.MaxOutputBytes
+		limits.OutputSource = execution.LimitSourceProject
+	}
+	if meta.TimeoutMS > 0 {
+		if err := settings.ValidateTimeoutMS(meta.TimeoutMS); err != nil {
+			return execution.RunLimits{}, fmt.Errorf("invalid snippet timeout: %w", err)
+		}
+		limits.TimeoutMS = meta.TimeoutMS
+		limits.TimeoutSource = execution.LimitSourceSnippet
+	}
+	if request.TimeoutMS != 0 {
+		if err := settings.ValidateTimeoutMS(request.TimeoutMS); err != nil {
+			return execution.RunLimits{}, fmt.Errorf("invalid run timeout: %w", err)
+		}
+		limits.TimeoutMS = request.TimeoutMS
+		limits.TimeoutSource = execution.LimitSourceRequest
+	}
+	if request.MaxDiskWriteBytes > 0 {
+		if err := settings.ValidateMaxDiskWriteBytes(request.MaxDiskWriteBytes); err != nil {
+			return execution.RunLimits{}, fmt.Errorf("invalid run disk quota: %w", err)
+		}
+		limits.MaxDiskWriteBytes = request.MaxDiskWriteBytes
+		limits.DiskWriteSource = execution.LimitSourceRequest
+	}
+	if request.MaxProcesses > 0 {
+		if err := settings.ValidateMaxRunProcesses(request.MaxProcesses); err != nil {
+			return execution.RunLimits{}, fmt.Errorf("invalid run process limit: %w", err)
+		}
+		limits.MaxProcesses = request.MaxProcesses
+		limits.ProcessSource = execution.LimitSourceRequest
+	}
+	if request.MaxOpenFiles > 0 {
+		if err := settings.ValidateMaxRunOpenFiles(request.MaxOpenFiles); err != nil {
+			return execution.RunLimits{}, fmt.Errorf("invalid run open file limit: %w", err)
+		}
+		limits.MaxOpenFiles = request.MaxOpenFiles
+		limits.OpenFilesSource = execution.LimitSourceRequest
+	}
+	return limits, nil
+}
+
+// resolveRunFiles checks the project-relative files a snippet is built
+// with: Go files of one package directory inside the project, none of
+// them a test or a declaration of main, which the snippet provides.
+func resolveRunFiles(projectPath string, files []string) ([]string, error) {
+	resolved := make([]string, 0, len(files))
+	for _, file := range files {
+		file = strings.TrimSpace(file)
+		target := filepath.Join(projectPath, filepath.FromSlash(file))
+		if filepath.IsAbs(file) || !pathWithin(projectPath, target) {
+			return nil, fmt.Errorf("run file %q must be a path inside the project", file)
+		}
+		if filepath.Ext(target) != ".go" || strings.HasSuffix(target, "_test.go") {
+			return nil, fmt.Errorf("run file %q must be a non-test Go file", file)
+		}
+		if len(resolved) > 0 && filepath.Dir(target) != filepath.Dir(resolved[0]) {
+			return nil, fmt.Errorf("run files must be in one package directory")
+		}
+		hasMain, err := project.DeclaresMain(target)
+		if err != nil {
+			return nil, fmt.Errorf("inspect run file %q: %w", file, err)
+		}
+		if hasMain {
+			return nil, fmt.Errorf("run file %q declares func main; the snippet provides main", file)
+		}
+		resolved = append(resolved, target)
+	}
+	return resolved, nil
+}
+
+// gopathEnvironment runs projects without a go.mod in GOPATH mode, so
+// their snippets import the project's packages by GOPATH import path.
+// Project variables win over the GOPATH defaults.
+func gopathEnvironment(ctx context.Context, projectPath string, environment map[string]string) (map[string]string, error) {
+	module, err := project.DetectModule(ctx, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("detect module: %w", err)
+	}
+	if module.HasModule {
+		return environment, nil
+	}
+	merged := module.GOPATH.Environment()
+	maps.Copy(merged, environment)
+	return merged, nil
+}
+
 func resolveWorkingDirectory(ctx context.Context, projectPath string, packagePath string, savedWorkingDirectory string) (string, error) {
 	if strings.TrimSpace(savedWorkingDirectory) != "" {
 		return resolveProjectWorkingDirectory(projectPath, savedWorkingDirectory)
@@ -618,6 +1286,7 @@ func (a *Application) registerActiveRun(runID string, cancel context.CancelFunc)
 func (a *Application) unregisterActiveRun(runID string) {
 	a.runMu.Lock()
 	defer a.runMu.Unlock()
+	delete(a.runPIDs, runID)
 	if a.activeRuns == nil {
 		return
 	}
@@ -627,22 +1296,26 @@ func (a *Application) unregisterActiveRun(runID string) {
 func (a *Application) recordRunResult(
 	ctx context.Context,
 	runID string,
-	projectID string,
+	resolved resolvedRunRequest,
+	environment *storage.RunEnvironment,
 	startedAt time.Time,
 	result execution.Result,
 ) error {
-	if projectID == "" {
+	a.rememberResult(runID, result)
+	if resolved.projectID == "" {
 		return nil
 	}
+	a.recordRunActivity(ctx, resolved, startedAt)
 
 	_, err := a.store.RecordRun(ctx, storage.RunRecord{
-		ID:         runID,
-		ProjectID:  projectID,
-		SnippetID:  "",
-		StartedAt:  startedAt,
-		DurationMS: result.DurationMS,
-		ExitCode:   result.ExitCode,
-		Status:     runStatusFromResult(result),
+		ID:          runID,
+		ProjectID:   resolved.projectID,
+		SnippetID:   "",
+		StartedAt:   startedAt,
+		DurationMS:  result.DurationMS,
+		ExitCode:    result.ExitCode,
+		Status:      runStatusFromResult(result),
+		Environment: environment,
 	})
 	if err != nil {
 		return fmt.Errorf("store run record: %w", err)
@@ -663,21 +1336,54 @@ func runStatusFromResult(result execution.Result) string {
 	}
 }
 
-func canceledRunResult(startedAt time.Time) execution.Result {
+func (a *Application) canceledRunResult(startedAt time.Time) execution.Result {
 	return execution.Result{
 		ExitCode:   -1,
-		DurationMS: time.Since(startedAt).Milliseconds(),
+		DurationMS: a.clock().Sub(startedAt).Milliseconds(),
 		Canceled:   true,
-		Stderr:     "execution canceled",
+		Stderr:     a.localizer().T(i18n.MsgRunCanceled),
 	}
 }
 
-func timedOutRunResult(startedAt time.Time) execution.Result {
+func (a *Application) timedOutRunResult(startedAt time.Time) execution.Result {
 	return execution.Result{
 		ExitCode:   -1,
-		DurationMS: time.Since(startedAt).Milliseconds(),
+		DurationMS: a.clock().Sub(startedAt).Milliseconds(),
 		TimedOut:   true,
-		Stderr:     "execution timed out",
+		Stderr:     a.localizer().T(i18n.MsgRunTimedOut),
+	}
+}
+
+// localizeRunResult translates placeholder messages and summarizes diagnostics.
+func (a *Application) localizeRunResult(result *execution.Result) {
+	localizer := a.localizer()
+	switch {
+	case result.TimedOut && result.Stderr == execution.MessageTimedOut:
+		result.Stderr = localizer.T(i18n.MsgRunTimedOut)
+	case result.TimedOut:
+		// Output written before the deadline would otherwise hide why the run stopped.
+		result.Stderr = strings.TrimRight(result.Stderr, "\n") + "\n" + localizer.T(i18n.MsgRunTimedOut)
+	case result.DiskQuotaExceeded && result.Stderr == execution.MessageDiskQuotaExceeded:
+		result.Stderr = localizer.T(i18n.MsgRunDiskQuota)
+	case result.DiskQuotaExceeded:
+		result.Stderr = strings.TrimRight(result.Stderr, "\n") + "\n" + localizer.T(i18n.MsgRunDiskQuota)
+	case result.ProcessLimitExceeded && result.Stderr == execution.MessageProcessLimitExceeded:
+		result.Stderr = localizer.T(i18n.MsgRunProcessLimit)
+	case result.ProcessLimitExceeded:
+		result.Stderr = strings.TrimRight(result.Stderr, "\n") + "\n" + localizer.T(i18n.MsgRunProcessLimit)
+	case result.NetworkDenied && result.Stderr == execution.MessageCanceled:
+		result.Stderr = localizer.T(i18n.MsgRunNetworkDenied)
+	case result.NetworkDenied:
+		result.Stderr = strings.TrimRight(result.Stderr, "\n") + "\n" + localizer.T(i18n.MsgRunNetworkDenied)
+	case result.Canceled && result.Stderr == execution.MessageCanceled:
+		result.Stderr = localizer.T(i18n.MsgRunCanceled)
+	}
+
+	parsed := diagnostics.Localize(diagnostics.ParseAll(result.Stderr), localizer)
+	parsed = diagnostics.AttachExplanations(parsed, localizer)
+	result.Diagnostics = convertDiagnostics(parsed)
+	if len(parsed) > 0 {
+		result.DiagnosticsSummary = diagnostics.Summary(parsed, localizer)
 	}
 }
 
@@ -687,13 +1393,22 @@ func convertDiagnostics(items []diagnostics.Diagnostic) []execution.Diagnostic {
 	}
 	converted := make([]execution.Diagnostic, 0, len(items))
 	for _, item := range items {
-		converted = append(converted, execution.Diagnostic{
+		diagnostic := execution.Diagnostic{
 			Kind:    item.Kind,
 			File:    item.File,
 			Line:    item.Line,
 			Column:  item.Column,
 			Message: item.Message,
-		})
+		}
+		if item.Explanation != nil {
+			diagnostic.Explanation = &execution.DiagnosticExplanation{
+				ID:     item.Explanation.ID,
+				Title:  item.Explanation.Title,
+				Detail: item.Explanation.Detail,
+				Fix:    item.Explanation.Fix,
+			}
+		}
+		converted = append(converted, diagnostic)
 	}
 	return converted
 }
@@ -717,11 +1432,14 @@ func (a *Application) StartProjectWorker(ctx context.Context, projectPath string
 	if a.workers == nil {
 		return runner.Worker{}, fmt.Errorf("worker manager not initialized")
 	}
-	resolvedProjectPath, err := resolveInputPath(projectPath)
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
 	if err != nil {
 		return runner.Worker{}, err
 	}
-	worker, err := a.workers.StartWorker(ctx, resolvedProjectPath)
+	if !projectRecord.Trusted() {
+		return runner.Worker{}, a.localizer().Error(i18n.MsgProjectNotTrusted)
+	}
+	worker, err := a.workers.StartWorker(ctx, projectRecord.Path)
 	if err != nil {
 		return runner.Worker{}, fmt.Errorf("start project worker: %w", err)
 	}
@@ -733,16 +1451,54 @@ func (a *Application) StopProjectWorker(ctx context.Context, projectPath string)
 	if a.workers == nil {
 		return fmt.Errorf("worker manager not initialized")
 	}
-	resolvedProjectPath, err := resolveInputPath(projectPath)
+	workerPath, err := a.workerProjectPath(ctx, projectPath)
 	if err != nil {
 		return err
 	}
-	if err := a.workers.StopWorker(ctx, resolvedProjectPath); err != nil {
+	if err := a.workers.StopWorker(ctx, workerPath); err != nil {
 		return fmt.Errorf("stop project worker: %w", err)
 	}
 	return nil
 }
 
+// ProjectWorkers lists running project workers with runtime metrics.
+func (a *Application) ProjectWorkers(ctx context.Context) ([]runner.Worker, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("project workers context: %w", err)
+	}
+	if a.workers == nil {
+		return nil, fmt.Errorf("worker manager not initialized")
+	}
+	return a.workers.List(), nil
+}
+
+// WorkerLogs returns up to limit recent log lines captured from a project's worker.
+func (a *Application) WorkerLogs(ctx context.Context, projectPath string, limit int) ([]runner.LogLine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("worker logs context: %w", err)
+	}
+	if a.workers == nil {
+		return nil, fmt.Errorf("worker manager not initialized")
+	}
+	workerPath, err := a.workerProjectPath(ctx, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := a.workers.Logs(workerPath, limit)
+	if err != nil {
+		return nil, fmt.Errorf("worker logs: %w", err)
+	}
+	return lines, nil
+}
+
+// SetWorkerLogHandler streams worker log lines to handler as they are captured.
+func (a *Application) SetWorkerLogHandler(handler runner.LogHandler) {
+	a.workerLogs = handler
+	if a.workers != nil {
+		a.workers.SetLogHandler(handler)
+	}
+}
+
 // StartLSP starts gopls for a project path.
 func (a *Application) StartLSP(ctx context.Context, projectPath string) error {
 	if a.lspManager == nil {
@@ -780,6 +1536,39 @@ func (a *Application) LSPWorkspaceInfo(ctx context.Context) lsp.WorkspaceInfo {
 	return a.lspManager.WorkspaceInfo()
 }
 
+// LSPModDocuments lists the active project's go.mod, go.sum and go.work
+// files with the language IDs gopls expects.
+func (a *Application) LSPModDocuments(ctx context.Context) []lsp.ModDocument {
+	if a.lspManager == nil {
+		return nil
+	}
+	return a.lspManager.ModDocuments()
+}
+
+// SetLSPAnalysisHandler streams gopls analysis state changes to handler.
+func (a *Application) SetLSPAnalysisHandler(handler lsp.AnalysisHandler) {
+	if a.lspManager == nil {
+		return
+	}
+	a.lspManager.SetAnalysisHandler(handler)
+}
+
+// SetLSPMemoryHandler streams gopls memory watchdog warnings to handler.
+func (a *Application) SetLSPMemoryHandler(handler lsp.MemoryHandler) {
+	if a.lspManager == nil {
+		return
+	}
+	a.lspManager.SetMemoryHandler(handler)
+}
+
+// LSPAnalysisState reports whether gopls diagnostics are current.
+func (a *Application) LSPAnalysisState(ctx context.Context) lsp.AnalysisEvent {
+	if a.lspManager == nil {
+		return lsp.AnalysisEvent{State: lsp.AnalysisClean}
+	}
+	return a.lspManager.AnalysisState()
+}
+
 // LSPStatus returns current LSP readiness.
 func (a *Application) LSPStatus(ctx context.Context) lsp.StatusResult {
 	if a.lspManager == nil {
@@ -790,7 +1579,7 @@ func (a *Application) LSPStatus(ctx context.Context) lsp.StatusResult {
 
 // OpenGoFile reads a single .go file and opens its parent directory as a project.
 func (a *Application) OpenGoFile(ctx context.Context, filePath string) (OpenGoFileResult, error) {
-	resolvedPath, err := resolveInputPath(filePath)
+	resolvedPath, err := resolveInputFilePath(filePath)
 	if err != nil {
 		return OpenGoFileResult{}, err
 	}
@@ -813,6 +1602,12 @@ func (a *Application) OpenGoFile(ctx context.Context, filePath string) (OpenGoFi
 	if err != nil {
 		return OpenGoFileResult{}, fmt.Errorf("open parent project: %w", err)
 	}
+	a.recordSessionEvent(session.Event{
+		Kind:        session.KindOpenFile,
+		ProjectPath: projectDir,
+		FilePath:    resolvedPath,
+		Content:     string(content),
+	})
 	return OpenGoFileResult{
 		Content:       string(content),
 		FilePath:      resolvedPath,
@@ -821,23 +1616,30 @@ func (a *Application) OpenGoFile(ctx context.Context, filePath string) (OpenGoFi
 }
 
 // SaveGoFile writes content back to a .go file on disk.
-func (a *Application) SaveGoFile(ctx context.Context, filePath string, content string) error {
+func (a *Application) SaveGoFile(ctx context.Context, filePath string, content string) (err error) {
+	defer func() {
+		a.recordAudit(audit.ActionSaveFile, "", map[string]string{"path": filePath, "bytes": strconv.Itoa(len(content))}, err)
+	}()
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("save file context: %w", err)
 	}
-	resolvedPath, err := resolveInputPath(filePath)
+	resolvedPath, err := resolveInputFilePath(filePath)
 	if err != nil {
 		return err
 	}
 	if !strings.HasSuffix(resolvedPath, ".go") {
 		return fmt.Errorf("file must have .go extension")
 	}
-	if _, err := os.Stat(resolvedPath); err != nil {
+	original, err := os.ReadFile(resolvedPath)
+	if err != nil {
 		return fmt.Errorf("file must already exist to save: %w", err)
 	}
+	content = a.normalizeForSave(ctx, content, original)
 	if err := os.WriteFile(resolvedPath, []byte(content), 0o644); err != nil {
 		return fmt.Errorf("write file: %w", err)
 	}
+	a.recordSessionEvent(session.Event{Kind: session.KindSaveFile, FilePath: resolvedPath, Content: content})
+	a.recordEditActivity(ctx, resolvedPath)
 	return nil
 }
 
@@ -857,16 +1659,6 @@ func (a *Application) PlaygroundImport(ctx context.Context, urlOrHash string) (s
 	return playground.Import(ctx, urlOrHash)
 }
 
-// ToolVersions holds detected versions for key tools.
-type ToolVersions struct {
-	GoVersion          string `json:"goVersion"`
-	GoPath             string `json:"goPath"`
-	GoplsVersion       string `json:"goplsVersion"`
-	GoplsPath          string `json:"goplsPath"`
-	StaticcheckVersion string `json:"staticcheckVersion"`
-	StaticcheckPath    string `json:"staticcheckPath"`
-}
-
 // GetGlobalSettings returns the current global settings.
 func (a *Application) GetGlobalSettings(ctx context.Context) (settings.GlobalSettings, error) {
 	if a.store == nil {
@@ -880,35 +1672,101 @@ func (a *Application) UpdateGlobalSettings(ctx context.Context, gs settings.Glob
 	if a.store == nil {
 		return settings.GlobalSettings{}, fmt.Errorf("storage service not initialized")
 	}
-	return a.store.UpdateSettings(ctx, gs)
+	if endpoint := strings.TrimSpace(gs.OTLPEndpoint); endpoint != "" {
+		if _, err := telemetry.NormalizeOTLPEndpoint(endpoint); err != nil {
+			return settings.GlobalSettings{}, err
+		}
+	}
+	if gs.PromptRunNetwork {
+		if err := networkPromptSupport(); err != nil {
+			return settings.GlobalSettings{}, fmt.Errorf("network permission prompts: %w", err)
+		}
+	}
+	updated, err := a.store.UpdateSettings(ctx, gs)
+	if err != nil {
+		return settings.GlobalSettings{}, err
+	}
+	a.applyRuntimeSettings(updated)
+	return updated, nil
+}
+
+// applyRuntimeSettings pushes settings that take effect without restart.
+func (a *Application) applyRuntimeSettings(gs settings.GlobalSettings) {
+	gs, _ = powerAdjusted(gs, a.power.current())
+	if a.workers != nil {
+		a.workers.SetPolicy(workerPolicy(gs))
+	}
+	if a.lspManager != nil {
+		a.lspManager.SetMemoryPolicy(goplsMemoryPolicy(gs))
+	}
+	a.locale.Store(i18n.New(gs.Locale))
+	a.plainText.Store(gs.PlainTextOutput)
+	a.monitorNetwork.Store(gs.MonitorRunNetwork)
+	a.promptNetwork.Store(gs.PromptRunNetwork)
+	a.lowPriorityRuns.Store(gs.LowPriorityRuns)
+	a.applyExecutionBackend(gs)
+	a.applyTelemetryExport(gs)
+	a.applyIdlePause(gs)
+	a.snippetCache.configure(gs)
 }
 
-// DetectToolVersions checks installed tool versions.
-func (a *Application) DetectToolVersions(ctx context.Context) ToolVersions {
-	result := ToolVersions{}
+// applyExecutionBackend selects the run backend from settings, letting
+// BackendEnv override it for demos and CI.
+func (a *Application) applyExecutionBackend(gs settings.GlobalSettings) {
+	name := gs.ExecutionBackend
+	if override := strings.TrimSpace(os.Getenv(execution.BackendEnv)); override != "" {
+		name = override
+	}
+	backend, err := execution.NewBackend(name)
+	if err != nil {
+		a.logger.Warn("select execution backend", "error", err)
+		backend = execution.GoBackend{}
+	}
+	a.backendMu.Lock()
+	a.backend = backend
+	a.backendMu.Unlock()
+}
 
-	if goPath, err := exec.LookPath("go"); err == nil {
-		result.GoPath = goPath
-		if out, err := exec.CommandContext(ctx, goPath, "version").Output(); err == nil {
-			result.GoVersion = strings.TrimSpace(string(out))
-		}
+// executionBackend returns the backend runs use.
+func (a *Application) executionBackend() execution.Backend {
+	a.backendMu.RLock()
+	defer a.backendMu.RUnlock()
+	if a.backend == nil {
+		return execution.GoBackend{}
 	}
+	return a.backend
+}
 
-	if goplsPath, err := exec.LookPath("gopls"); err == nil {
-		result.GoplsPath = goplsPath
-		if out, err := exec.CommandContext(ctx, goplsPath, "version").Output(); err == nil {
-			result.GoplsVersion = strings.TrimSpace(string(out))
-		}
+// resolveToolchain resolves a toolchain name to its binary. Backends other
+// than Go never invoke one, so the name passes through unresolved and runs
+// work on machines without Go.
+func (a *Application) resolveToolchain(name string) (string, error) {
+	if a.executionBackend().Name() != execution.BackendGo {
+		return name, nil
 	}
+	return project.ResolveToolchainBinary(name)
+}
 
-	if scPath, err := exec.LookPath("staticcheck"); err == nil {
-		result.StaticcheckPath = scPath
-		if out, err := exec.CommandContext(ctx, scPath, "-version").Output(); err == nil {
-			result.StaticcheckVersion = strings.TrimSpace(string(out))
-		}
+// localizer returns the active message localizer. A nil result renders English.
+func (a *Application) localizer() *i18n.Localizer {
+	return a.locale.Load()
+}
+
+// workerPolicy maps global settings onto the worker manager policy.
+func workerPolicy(gs settings.GlobalSettings) runner.Policy {
+	return runner.Policy{
+		MaxWorkers:             gs.WorkerMaxCount,
+		MaxLifetime:            time.Duration(gs.WorkerMaxLifetimeMS) * time.Millisecond,
+		RestartOnProjectChange: gs.WorkerRestartOnProjectChange,
 	}
+}
 
-	return result
+// goplsMemoryPolicy maps global settings onto the gopls memory watchdog.
+func goplsMemoryPolicy(gs settings.GlobalSettings) lsp.MemoryPolicy {
+	return lsp.MemoryPolicy{
+		LimitBytes:      gs.GoplsMemoryLimitMB << 20,
+		RestartDegraded: gs.GoplsDegradeOnMemoryLimit,
+	}
 }
 
 // applyToolchainPaths reads global settings and prepends configured tool
@@ -984,11 +1842,33 @@ func (a *Application) projectRecordByPath(ctx context.Context, projectPath strin
 		return storage.ProjectRecord{}, fmt.Errorf("load project context: %w", err)
 	}
 	if !found {
-		return storage.ProjectRecord{}, fmt.Errorf("project not found; open project first")
+		return storage.ProjectRecord{}, a.localizer().Error(i18n.MsgProjectNotFound)
 	}
 	return record, nil
 }
 
+// workerProjectPath resolves projectPath to the path its worker runs under:
+// the project record's path, as runs use, so a path spelled with other case
+// finds the same worker. A path without a record, such as the scratch
+// workspace, is used as resolved.
+func (a *Application) workerProjectPath(ctx context.Context, projectPath string) (string, error) {
+	resolvedProjectPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return "", err
+	}
+	if a.store == nil {
+		return resolvedProjectPath, nil
+	}
+	record, found, err := a.store.ProjectByPath(ctx, resolvedProjectPath)
+	if err != nil {
+		return "", fmt.Errorf("load project context: %w", err)
+	}
+	if !found {
+		return resolvedProjectPath, nil
+	}
+	return record.Path, nil
+}
+
 func resolveProjectWorkingDirectory(projectPath string, workingDirectory string) (string, error) {
 	resolved := strings.TrimSpace(workingDirectory)
 	if resolved == "" {
@@ -1033,7 +1913,28 @@ func generateRunID() string {
 	return "run_" + hex.EncodeToString(b)
 }
 
+// resolveInputPath expands ~ and returns the canonical absolute path, so a
+// project reached through a symlink maps to the same record, worker and
+// language server as its target.
 func resolveInputPath(path string) (string, error) {
+	absolutePath, err := expandInputPath(path)
+	if err != nil {
+		return "", err
+	}
+	return fspath.Canonical(absolutePath), nil
+}
+
+// resolveInputFilePath is resolveInputPath for files: a symlinked file keeps
+// its own name and directory.
+func resolveInputFilePath(path string) (string, error) {
+	absolutePath, err := expandInputPath(path)
+	if err != nil {
+		return "", err
+	}
+	return fspath.CanonicalParent(absolutePath), nil
+}
+
+func expandInputPath(path string) (string, error) {
 	trimmed := strings.TrimSpace(path)
 	if trimmed == "" {
 		return "", fmt.Errorf("project path is required")
@@ -12,7 +12,11 @@ import (
 	"time"
 
 	"gopoke/internal/execution"
+	"gopoke/internal/fspath"
 	"gopoke/internal/project"
+	"gopoke/internal/runner"
+	"gopoke/internal/settings"
+	"gopoke/internal/snippetmeta"
 	"gopoke/internal/storage"
 	"gopoke/internal/telemetry"
 	"gopoke/internal/testutil"
@@ -900,7 +904,7 @@ func TestApplicationRunSnippetTimeoutEnforced(t *testing.T) {
 		execution.RunRequest{
 			RunID:       "run_timeout_enforced",
 			ProjectPath: projectDir,
-			TimeoutMS:   50,
+			TimeoutMS:   settings.MinTimeoutMS,
 			Source: strings.Join([]string{
 				"package main",
 				"",
@@ -924,6 +928,9 @@ func TestApplicationRunSnippetTimeoutEnforced(t *testing.T) {
 	if !strings.Contains(result.Stderr, "timed out") {
 		t.Fatalf("stderr = %q, want timeout reason", result.Stderr)
 	}
+	if got, want := result.Limits.TimeoutSource, execution.LimitSourceRequest; got != want {
+		t.Fatalf("Limits.TimeoutSource = %q, want %q", got, want)
+	}
 
 	runs, err := application.store.ProjectRuns(context.Background(), openResult.Project.ID, 10)
 	if err != nil {
@@ -991,6 +998,259 @@ func TestApplicationRunSnippetOutputGuardrail(t *testing.T) {
 	}
 }
 
+func TestApplicationRunSnippetTeeToFile(t *testing.T) {
+	requireGoToolchain(t)
+
+	application := newTestApplication(t)
+	application.artifactsDir = t.TempDir()
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	openResult, err := application.OpenProject(context.Background(), projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	if _, err := application.SetProjectRunLimits(context.Background(), projectDir, 0, settings.MinOutputBytes); err != nil {
+		t.Fatalf("SetProjectRunLimits() error = %v", err)
+	}
+
+	runCtx, runCancel := testutil.TestRunContext(t)
+	defer runCancel()
+	result, runErr := application.RunSnippet(
+		runCtx,
+		execution.RunRequest{
+			ProjectPath: projectDir,
+			TeeToFile:   "logs/full.log",
+			Source: strings.Join([]string{
+				"package main",
+				"",
+				"import (",
+				"\t\"fmt\"",
+				"\t\"strings\"",
+				")",
+				"",
+				"func main() {",
+				"\tfmt.Print(strings.Repeat(\"x\", 8192))",
+				"}",
+				"",
+			}, "\n"),
+		},
+		nil,
+		nil,
+	)
+	if runErr != nil {
+		t.Fatalf("RunSnippet() error = %v", runErr)
+	}
+	wantPath := filepath.Join(application.artifactsDir, openResult.Project.ID, "logs", "full.log")
+	if got := result.TeeFile; got != wantPath {
+		t.Fatalf("TeeFile = %q, want %q", got, wantPath)
+	}
+	if !result.StdoutTruncated {
+		t.Fatal("StdoutTruncated = false, want true")
+	}
+	mirrored, err := os.ReadFile(wantPath)
+	if err != nil {
+		t.Fatalf("read tee file: %v", err)
+	}
+	if got, want := len(mirrored), 8192; got != want {
+		t.Fatalf("len(tee file) = %d, want %d", got, want)
+	}
+}
+
+func TestResolveTeePathStaysInsideAllowedRoots(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.artifactsDir = t.TempDir()
+	projectDir := t.TempDir()
+
+	inProject := filepath.Join(projectDir, "out", "run.log")
+	if got, err := application.resolveTeePath(projectDir, "prj_1", inProject); err != nil || got != inProject {
+		t.Fatalf("resolveTeePath(project file) = %q, %v; want %q", got, err, inProject)
+	}
+	for _, target := range []string{"../escape.log", filepath.Join(t.TempDir(), "other.log"), projectDir} {
+		if _, err := application.resolveTeePath(projectDir, "prj_1", target); err == nil {
+			t.Fatalf("resolveTeePath(%q) error = nil, want rejection", target)
+		}
+	}
+}
+
+func TestResolveRunLimitsPrecedenceAndBounds(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	limits, err := application.resolveRunLimits(ctx, execution.RunRequest{}, snippetmeta.Meta{}, storage.ProjectRecord{})
+	if err != nil {
+		t.Fatalf("resolveRunLimits(default) error = %v", err)
+	}
+	if limits.TimeoutMS != execution.DefaultTimeout.Milliseconds() || limits.MaxOutputBytes != execution.DefaultMaxOutputBytes {
+		t.Fatalf("default limits = %+v, want the execution defaults", limits)
+	}
+	if _, err := application.UpdateGlobalSettings(ctx, settings.GlobalSettings{DefaultTimeoutMS: 20000, MaxOutputBytes: 4096}); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+
+	limits, err = application.resolveRunLimits(ctx, execution.RunRequest{}, snippetmeta.Meta{}, storage.ProjectRecord{})
+	if err != nil {
+		t.Fatalf("resolveRunLimits(global) error = %v", err)
+	}
+	if limits.TimeoutMS != 20000 || limits.MaxOutputBytes != 4096 || limits.TimeoutSource != execution.LimitSourceGlobal {
+		t.Fatalf("global limits = %+v", limits)
+	}
+
+	record, err := application.SetProjectRunLimits(ctx, projectDir, 5000, 2048)
+	if err != nil {
+		t.Fatalf("SetProjectRunLimits() error = %v", err)
+	}
+	limits, err = application.resolveRunLimits(ctx, execution.RunRequest{TimeoutMS: 9000}, snippetmeta.Meta{}, record)
+	if err != nil {
+		t.Fatalf("resolveRunLimits(project) error = %v", err)
+	}
+	want := execution.RunLimits{
+		TimeoutMS:      9000,
+		TimeoutSource:  execution.LimitSourceRequest,
+		MaxOutputBytes: 2048,
+		OutputSource:   execution.LimitSourceProject,
+	}
+	if limits != want {
+		t.Fatalf("resolveRunLimits() = %+v, want %+v", limits, want)
+	}
+
+	// The snippet timeout comes from the metadata resolveRunRequest parsed,
+	// not from parsing the source again.
+	limits, err = application.resolveRunLimits(ctx, execution.RunRequest{Source: "package main\n"}, snippetmeta.Meta{TimeoutMS: 7000}, record)
+	if err != nil {
+		t.Fatalf("resolveRunLimits(snippet) error = %v", err)
+	}
+	if limits.TimeoutMS != 7000 || limits.TimeoutSource != execution.LimitSourceSnippet {
+		t.Fatalf("resolveRunLimits(snippet) = %+v, want the snippet's 7000ms", limits)
+	}
+	if _, err := application.resolveRunLimits(ctx, execution.RunRequest{}, snippetmeta.Meta{TimeoutMS: settings.MaxTimeoutMS + 1}, record); err == nil {
+		t.Fatal("resolveRunLimits(snippet timeout above max) error = nil, want error")
+	}
+
+	for _, timeoutMS := range []int64{settings.MaxTimeoutMS + 1, settings.MinTimeoutMS - 1, -1} {
+		if _, err := application.resolveRunLimits(ctx, execution.RunRequest{TimeoutMS: timeoutMS}, snippetmeta.Meta{}, record); err == nil {
+			t.Fatalf("resolveRunLimits(timeout %d) error = nil, want error", timeoutMS)
+		}
+	}
+
+	quota := settings.MinDiskWriteBytes * 2
+	limits, err = application.resolveRunLimits(ctx, execution.RunRequest{MaxDiskWriteBytes: quota}, snippetmeta.Meta{}, record)
+	if err != nil {
+		t.Fatalf("resolveRunLimits(disk quota) error = %v", err)
+	}
+	if limits.MaxDiskWriteBytes != quota || limits.DiskWriteSource != execution.LimitSourceRequest {
+		t.Fatalf("disk quota limits = %+v", limits)
+	}
+	if _, err := application.resolveRunLimits(ctx, execution.RunRequest{MaxDiskWriteBytes: 1}, snippetmeta.Meta{}, record); err == nil {
+		t.Fatal("resolveRunLimits(disk quota below min) error = nil, want error")
+	}
+	limits, err = application.resolveRunLimits(ctx, execution.RunRequest{MaxProcesses: 64, MaxOpenFiles: 512}, snippetmeta.Meta{}, record)
+	if err != nil {
+		t.Fatalf("resolveRunLimits(process limits) error = %v", err)
+	}
+	if limits.MaxProcesses != 64 || limits.ProcessSource != execution.LimitSourceRequest || limits.MaxOpenFiles != 512 || limits.OpenFilesSource != execution.LimitSourceRequest {
+		t.Fatalf("process limits = %+v", limits)
+	}
+	if _, err := application.resolveRunLimits(ctx, execution.RunRequest{MaxProcesses: 1}, snippetmeta.Meta{}, record); err == nil {
+		t.Fatal("resolveRunLimits(process limit below min) error = nil, want error")
+	}
+	if _, err := application.SetProjectRunLimits(ctx, projectDir, 10, 0); err == nil {
+		t.Fatal("SetProjectRunLimits(timeout below min) error = nil, want error")
+	}
+	if _, err := application.SetProjectRunLimits(ctx, projectDir, 0, settings.MaxOutputBytesCap+1); err == nil {
+		t.Fatal("SetProjectRunLimits(cap above max) error = nil, want error")
+	}
+}
+
+func TestUpdateGlobalSettingsAppliesWorkerPolicy(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.workers = runner.NewManager()
+
+	gs := settings.Defaults()
+	gs.WorkerMaxCount = 2
+	gs.WorkerMaxLifetimeMS = 60000
+	gs.WorkerRestartOnProjectChange = true
+	if _, err := application.UpdateGlobalSettings(context.Background(), gs); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+
+	policy := application.workers.Policy()
+	if got, want := policy.MaxWorkers, 2; got != want {
+		t.Fatalf("MaxWorkers = %d, want %d", got, want)
+	}
+	if got, want := policy.MaxLifetime, time.Minute; got != want {
+		t.Fatalf("MaxLifetime = %s, want %s", got, want)
+	}
+	if !policy.RestartOnProjectChange {
+		t.Fatal("RestartOnProjectChange = false, want true")
+	}
+}
+
+func TestWorkerProjectPathUsesProjectRecord(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.workers = runner.NewManager()
+	ctx := context.Background()
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	opened, err := application.OpenProject(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	if _, err := application.StartProjectWorker(ctx, projectDir); err == nil {
+		t.Fatal("StartProjectWorker(untrusted) error = nil, want error")
+	}
+
+	scratch := fspath.Canonical(t.TempDir())
+	if got, err := application.workerProjectPath(ctx, scratch); err != nil || got != scratch {
+		t.Fatalf("workerProjectPath(scratch) = %q, %v; want %q", got, err, scratch)
+	}
+	if !fspath.CaseInsensitive {
+		t.Skip("paths differing only in case are distinct on this platform")
+	}
+	respelled := strings.ToUpper(projectDir)
+	if got, err := application.workerProjectPath(ctx, respelled); err != nil || got != opened.Project.Path {
+		t.Fatalf("workerProjectPath(%q) = %q, %v; want record path %q", respelled, got, err, opened.Project.Path)
+	}
+}
+
+func TestUpdateGlobalSettingsLocalizesRunMessages(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	gs := settings.Defaults()
+	gs.Locale = "es"
+	if _, err := application.UpdateGlobalSettings(context.Background(), gs); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+
+	result := application.timedOutRunResult(time.Now())
+	if got, want := result.Stderr, "la ejecución superó el tiempo límite"; got != want {
+		t.Fatalf("timed out stderr = %q, want %q", got, want)
+	}
+
+	canceled := execution.Result{Canceled: true, Stderr: execution.MessageCanceled}
+	application.localizeRunResult(&canceled)
+	if got, want := canceled.Stderr, "ejecución cancelada"; got != want {
+		t.Fatalf("canceled stderr = %q, want %q", got, want)
+	}
+
+	_, err := application.projectRecordByPath(context.Background(), t.TempDir())
+	if err == nil || err.Error() != "proyecto no encontrado; abre el proyecto primero" {
+		t.Fatalf("projectRecordByPath() error = %v, want localized not found", err)
+	}
+}
+
 func newTestApplication(t *testing.T) *Application {
 	t.Helper()
 
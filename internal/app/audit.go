@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/execution"
+)
+
+// AuditLog returns audited file writes and external commands matching
+// filter, newest first.
+func (a *Application) AuditLog(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("audit log context: %w", err)
+	}
+	if a.auditLog == nil {
+		return nil, fmt.Errorf("audit log not initialized")
+	}
+	if strings.TrimSpace(filter.ProjectPath) != "" {
+		resolvedPath, err := resolveInputPath(filter.ProjectPath)
+		if err != nil {
+			return nil, err
+		}
+		filter.ProjectPath = resolvedPath
+	}
+	entries, err := a.auditLog.Query(filter)
+	if err != nil {
+		return nil, fmt.Errorf("query audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// recordAudit appends an entry for an action that has finished, with err
+// set when it failed. Project paths are stored canonical so filters match
+// however the path was spelled. Failures to write are logged, never
+// returned, so auditing cannot block the action itself.
+func (a *Application) recordAudit(action string, projectPath string, params map[string]string, err error) {
+	if a.auditLog == nil {
+		return
+	}
+	entry := audit.Entry{Time: a.clock(), Action: action, Params: params}
+	if strings.TrimSpace(projectPath) != "" {
+		if resolvedPath, resolveErr := resolveInputPath(projectPath); resolveErr == nil {
+			entry.ProjectPath = resolvedPath
+		} else {
+			entry.ProjectPath = projectPath
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if appendErr := a.auditLog.Append(entry); appendErr != nil {
+		a.logger.Warn("append audit entry failed", "action", action, "error", appendErr)
+	}
+}
+
+// runAuditParams describes a run without its source, which can be large;
+// the hash identifies it.
+func runAuditParams(request execution.RunRequest, result execution.Result) map[string]string {
+	sourceSum := sha256.Sum256([]byte(request.Source))
+	params := map[string]string{
+		"runId":        request.RunID,
+		"sourceSha256": hex.EncodeToString(sourceSum[:]),
+		"exitCode":     strconv.Itoa(result.ExitCode),
+	}
+	if request.PackagePath != "" {
+		params["packagePath"] = request.PackagePath
+	}
+	switch {
+	case result.ConfirmationRequired:
+		params["status"] = "held"
+	case result.TimedOut:
+		params["status"] = "timed_out"
+	case result.Canceled:
+		params["status"] = "canceled"
+	}
+	return params
+}
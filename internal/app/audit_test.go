@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/execution"
+)
+
+func TestAuditLogRecordsWritesAndRuns(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.auditLog = audit.New(filepath.Join(t.TempDir(), "audit.log"))
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	mainPath := filepath.Join(projectDir, "main.go")
+	if err := application.SaveGoFile(ctx, mainPath, "package main\n\nfunc main() {}\n"); err != nil {
+		t.Fatalf("SaveGoFile() error = %v", err)
+	}
+	if err := application.SaveGoFile(ctx, filepath.Join(projectDir, "missing.go"), "package main\n"); err == nil {
+		t.Fatal("SaveGoFile(missing) error = nil, want error")
+	}
+	if _, err := application.AddGoModReplace(ctx, projectDir, "example.com/dep", "", "../dep", ""); err != nil {
+		t.Fatalf("AddGoModReplace() error = %v", err)
+	}
+	if _, err := application.RunSnippet(ctx, execution.RunRequest{
+		RunID:       "run_audit",
+		ProjectPath: projectDir,
+		Source:      "package main\n\n//stdout: hi\nfunc main() {}\n",
+	}, nil, nil); err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+
+	entries, err := application.AuditLog(ctx, audit.Filter{})
+	if err != nil {
+		t.Fatalf("AuditLog() error = %v", err)
+	}
+	actions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	want := []string{audit.ActionRun, audit.ActionGoModReplace, audit.ActionSaveFile, audit.ActionSaveFile}
+	if len(actions) != len(want) {
+		t.Fatalf("audit actions = %v, want %v", actions, want)
+	}
+	for index := range want {
+		if actions[index] != want[index] {
+			t.Fatalf("audit actions = %v, want %v", actions, want)
+		}
+	}
+	if entries[0].Params["runId"] != "run_audit" || entries[0].ProjectPath != canonicalPath(t, projectDir) {
+		t.Fatalf("run entry = %+v", entries[0])
+	}
+	if entries[2].Error == "" || entries[3].Error != "" || entries[3].Params["path"] != mainPath {
+		t.Fatalf("save entries = %+v, %+v; want failed then successful save", entries[2], entries[3])
+	}
+
+	byProject, err := application.AuditLog(ctx, audit.Filter{ProjectPath: projectDir, Actions: []string{audit.ActionGoModReplace}})
+	if err != nil {
+		t.Fatalf("AuditLog(filter) error = %v", err)
+	}
+	if len(byProject) != 1 || byProject[0].Params["newPath"] != "../dep" {
+		t.Fatalf("AuditLog(filter) = %+v", byProject)
+	}
+}
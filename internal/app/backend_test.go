@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/settings"
+	"gopoke/internal/testutil"
+)
+
+func TestRunSnippetUsesFakeBackendFromSettings(t *testing.T) {
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	ctx := context.Background()
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	if _, err := application.UpdateGlobalSettings(ctx, settings.GlobalSettings{ExecutionBackend: settings.ExecutionBackendFake}); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+	if got := application.executionBackend().Name(); got != execution.BackendFake {
+		t.Fatalf("executionBackend().Name() = %q, want %q", got, execution.BackendFake)
+	}
+
+	runCtx, cancel := testutil.TestRunContext(t)
+	defer cancel()
+	result, err := application.RunSnippet(runCtx, execution.RunRequest{
+		ProjectPath: projectDir,
+		Source:      "package main\n\n//stdout: from the fake\n//stderr: ./main.go:6:2: declared and not used: x\n//exit: 1\nfunc main() {}\n",
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.Stdout != "from the fake\n" || result.ExitCode != 1 {
+		t.Fatalf("result = %+v, want scripted fake output", result)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Line != 6 {
+		t.Fatalf("Diagnostics = %+v, want the scripted compile error", result.Diagnostics)
+	}
+
+	t.Setenv(execution.BackendEnv, execution.BackendGo)
+	if _, err := application.UpdateGlobalSettings(ctx, settings.GlobalSettings{ExecutionBackend: settings.ExecutionBackendFake}); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+	if got := application.executionBackend().Name(); got != execution.BackendGo {
+		t.Fatalf("executionBackend().Name() with %s = %q, want %q", execution.BackendEnv, got, execution.BackendGo)
+	}
+}
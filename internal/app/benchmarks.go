@@ -0,0 +1,24 @@
+package app
+
+import (
+	"fmt"
+
+	"gopoke/internal/benchsnippet"
+)
+
+// prepareBenchmarkSnippet swaps a benchmark snippet for one with a main that
+// runs its benchmarks, so it runs and builds like any other snippet. It
+// returns the original source, for placing results, or "" when the snippet
+// is not a benchmark snippet.
+func prepareBenchmarkSnippet(resolved *resolvedRunRequest) (string, error) {
+	if !benchsnippet.IsBenchmarkSnippet(resolved.source) {
+		return "", nil
+	}
+	harness, err := benchsnippet.Harness(resolved.source)
+	if err != nil {
+		return "", fmt.Errorf("prepare benchmark snippet: %w", err)
+	}
+	original := resolved.source
+	resolved.source = harness
+	return original, nil
+}
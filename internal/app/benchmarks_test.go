@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestRunSnippetAnnotatesBenchmarks(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	source := "package main\n\nimport \"testing\"\n\n//stdout: BenchmarkSum 1000 12.5 ns/op 8 B/op 1 allocs/op\nfunc BenchmarkSum(b *testing.B) {\n\tfor i := 0; i < b.N; i++ {\n\t}\n}\n"
+	result, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if len(result.Benchmarks) != 1 {
+		t.Fatalf("Benchmarks = %+v, want one annotation", result.Benchmarks)
+	}
+	annotation := result.Benchmarks[0]
+	if annotation.Function != "BenchmarkSum" || annotation.Line != 6 || annotation.Text != "12.5 ns/op · 8 B/op · 1 allocs/op" {
+		t.Fatalf("annotation = %+v", annotation)
+	}
+
+	plain := "package main\n\n//stdout: BenchmarkSum 1000 12.5 ns/op\nfunc main() {}\n"
+	result, err = application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: plain}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.Benchmarks != nil {
+		t.Fatalf("Benchmarks = %+v, want none for a snippet with main", result.Benchmarks)
+	}
+}
@@ -0,0 +1,289 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/lsp"
+	"gopoke/internal/project"
+)
+
+// Capability names a subsystem the application can start without. Missing
+// capabilities put the application in degraded mode: calls that need them
+// fail with a CapabilityError while everything else keeps working.
+type Capability string
+
+const (
+	// CapabilityStorage is the state file holding projects, snippets,
+	// settings and run history.
+	CapabilityStorage Capability = "storage"
+	// CapabilityToolchain is the Go toolchain that builds and runs
+	// snippets.
+	CapabilityToolchain Capability = "toolchain"
+	// CapabilityGopls is gopls. Without it the editor falls back to the
+	// syntax-only language server.
+	CapabilityGopls Capability = "gopls"
+)
+
+// capabilityOrder is the probe order; storage comes first because it
+// supplies the toolchain paths.
+var capabilityOrder = []Capability{CapabilityStorage, CapabilityToolchain, CapabilityGopls}
+
+// capabilityRecheckInterval is how often unavailable capabilities are
+// probed again.
+const capabilityRecheckInterval = 30 * time.Second
+
+// ErrCapabilityUnavailable matches every CapabilityError.
+var ErrCapabilityUnavailable = errors.New("capability unavailable")
+
+// CapabilityError reports a call refused because a capability it needs is
+// unavailable.
+type CapabilityError struct {
+	Capability Capability
+	Reason     string
+}
+
+func (e *CapabilityError) Error() string {
+	return fmt.Sprintf("%s is unavailable: %s", e.Capability, e.Reason)
+}
+
+// Unwrap makes errors.Is match ErrCapabilityUnavailable.
+func (e *CapabilityError) Unwrap() error {
+	return ErrCapabilityUnavailable
+}
+
+// CapabilityStatus is the state of one capability.
+type CapabilityStatus struct {
+	Capability Capability `json:"capability"`
+	Available  bool       `json:"available"`
+	// Reason says why the capability is unavailable.
+	Reason string `json:"reason,omitempty"`
+	// Since is when the capability last changed state.
+	Since time.Time `json:"since"`
+}
+
+// CapabilityReport lists every capability. Degraded is set while any is
+// unavailable.
+type CapabilityReport struct {
+	Degraded     bool               `json:"degraded"`
+	Capabilities []CapabilityStatus `json:"capabilities"`
+}
+
+// CapabilityHandler receives the report whenever a capability changes.
+type CapabilityHandler func(report CapabilityReport)
+
+// capabilityState tracks capabilities. Until a capability is probed it
+// counts as available.
+type capabilityState struct {
+	mu       sync.Mutex
+	statuses map[Capability]CapabilityStatus
+	handler  CapabilityHandler
+	probe    func(ctx context.Context, capability Capability) error // test override
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// SetCapabilityHandler reports capability changes to handler. A nil
+// handler stops reporting.
+func (a *Application) SetCapabilityHandler(handler CapabilityHandler) {
+	a.capabilities.mu.Lock()
+	defer a.capabilities.mu.Unlock()
+	a.capabilities.handler = handler
+}
+
+// Capabilities reports which capabilities are available.
+func (a *Application) Capabilities(ctx context.Context) (CapabilityReport, error) {
+	if err := ctx.Err(); err != nil {
+		return CapabilityReport{}, fmt.Errorf("capabilities context: %w", err)
+	}
+	a.capabilities.mu.Lock()
+	defer a.capabilities.mu.Unlock()
+	return a.capabilityReportLocked(), nil
+}
+
+// RecheckCapabilities probes every capability now, re-enabling those whose
+// underlying problem has been fixed.
+func (a *Application) RecheckCapabilities(ctx context.Context) (CapabilityReport, error) {
+	if err := ctx.Err(); err != nil {
+		return CapabilityReport{}, fmt.Errorf("recheck capabilities context: %w", err)
+	}
+	a.probeCapabilities(ctx, capabilityOrder...)
+	return a.Capabilities(ctx)
+}
+
+// RequireCapabilities returns a CapabilityError for the first required
+// capability that is unavailable. An unavailable capability is probed once
+// more first, so a fix takes effect on the next call.
+func (a *Application) RequireCapabilities(ctx context.Context, required ...Capability) error {
+	var missing []Capability
+	a.capabilities.mu.Lock()
+	for _, capability := range required {
+		if status, ok := a.capabilities.statuses[capability]; ok && !status.Available {
+			missing = append(missing, capability)
+		}
+	}
+	a.capabilities.mu.Unlock()
+	if len(missing) == 0 {
+		return nil
+	}
+
+	a.probeCapabilities(ctx, missing...)
+	a.capabilities.mu.Lock()
+	defer a.capabilities.mu.Unlock()
+	for _, capability := range missing {
+		if status := a.capabilities.statuses[capability]; !status.Available {
+			return &CapabilityError{Capability: capability, Reason: status.Reason}
+		}
+	}
+	return nil
+}
+
+// probeCapabilities checks each capability, records what changed and
+// restores features that a recovered capability re-enables.
+func (a *Application) probeCapabilities(ctx context.Context, capabilities ...Capability) {
+	for _, capability := range capabilities {
+		err := a.probeCapability(ctx, capability)
+		now := a.clock().UTC()
+
+		a.capabilities.mu.Lock()
+		previous, probed := a.capabilities.statuses[capability]
+		status := CapabilityStatus{Capability: capability, Available: err == nil, Since: now}
+		if err != nil {
+			status.Reason = err.Error()
+		}
+		if probed && previous.Available == status.Available {
+			status.Since = previous.Since
+		}
+		if a.capabilities.statuses == nil {
+			a.capabilities.statuses = make(map[Capability]CapabilityStatus, len(capabilityOrder))
+		}
+		a.capabilities.statuses[capability] = status
+		changed := !probed && !status.Available || probed && previous.Available != status.Available
+		handler := a.capabilities.handler
+		report := a.capabilityReportLocked()
+		a.capabilities.mu.Unlock()
+
+		if !changed {
+			continue
+		}
+		if status.Available {
+			a.logger.Info("capability recovered", "capability", capability)
+			a.recoverCapability(ctx, capability)
+		} else {
+			a.logger.Warn("capability unavailable; running degraded", "capability", capability, "reason", status.Reason)
+		}
+		if handler != nil {
+			handler(report)
+		}
+	}
+}
+
+func (a *Application) probeCapability(ctx context.Context, capability Capability) error {
+	if a.capabilities.probe != nil {
+		return a.capabilities.probe(ctx, capability)
+	}
+	switch capability {
+	case CapabilityStorage:
+		// Bootstrap is idempotent; it also recreates a missing state file.
+		if err := a.store.Bootstrap(ctx); err != nil {
+			return fmt.Errorf("bootstrap storage: %w", err)
+		}
+	case CapabilityToolchain:
+		if a.executionBackend().Name() != execution.BackendGo {
+			return nil
+		}
+		if _, err := project.ResolveToolchainBinary("go"); err != nil {
+			return err
+		}
+	case CapabilityGopls:
+		if !lsp.GoplsAvailable() {
+			return errors.New("gopls not found in PATH; install it with go install golang.org/x/tools/gopls@latest")
+		}
+	}
+	return nil
+}
+
+// recoverCapability re-enables what a recovered capability provides.
+func (a *Application) recoverCapability(ctx context.Context, capability Capability) {
+	switch capability {
+	case CapabilityStorage:
+		a.applyToolchainPaths(ctx)
+		if gs, err := a.store.GetSettings(ctx); err == nil {
+			a.applyRuntimeSettings(gs)
+		}
+	case CapabilityGopls:
+		if a.lspManager == nil {
+			return
+		}
+		if _, err := a.lspManager.RestartIfLite(context.WithoutCancel(ctx)); err != nil {
+			a.logger.Warn("restart language server with gopls failed", "error", err)
+		}
+	}
+}
+
+func (a *Application) capabilityReportLocked() CapabilityReport {
+	report := CapabilityReport{Capabilities: make([]CapabilityStatus, 0, len(capabilityOrder))}
+	for _, capability := range capabilityOrder {
+		status, ok := a.capabilities.statuses[capability]
+		if !ok {
+			status = CapabilityStatus{Capability: capability, Available: true}
+		}
+		report.Degraded = report.Degraded || !status.Available
+		report.Capabilities = append(report.Capabilities, status)
+	}
+	return report
+}
+
+// startCapabilityWatch probes unavailable capabilities periodically so
+// features come back without a restart.
+func (a *Application) startCapabilityWatch() {
+	a.capabilities.mu.Lock()
+	defer a.capabilities.mu.Unlock()
+	if a.capabilities.stop != nil {
+		return
+	}
+	a.capabilities.stop = make(chan struct{})
+	a.capabilities.done = make(chan struct{})
+	go a.watchCapabilities(a.capabilities.stop, a.capabilities.done)
+}
+
+// stopCapabilityWatch stops the periodic probe.
+func (a *Application) stopCapabilityWatch() {
+	a.capabilities.mu.Lock()
+	stop, done := a.capabilities.stop, a.capabilities.done
+	a.capabilities.stop, a.capabilities.done = nil, nil
+	a.capabilities.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (a *Application) watchCapabilities(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(capabilityRecheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		a.capabilities.mu.Lock()
+		var unavailable []Capability
+		for _, capability := range capabilityOrder {
+			if status, ok := a.capabilities.statuses[capability]; ok && !status.Available {
+				unavailable = append(unavailable, capability)
+			}
+		}
+		a.capabilities.mu.Unlock()
+		if len(unavailable) > 0 {
+			a.probeCapabilities(context.Background(), unavailable...)
+		}
+	}
+}
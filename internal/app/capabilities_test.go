@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStartDegradesOnCorruptStorageAndRecovers(t *testing.T) {
+	t.Parallel()
+
+	dataRoot := t.TempDir()
+	statePath := filepath.Join(dataRoot, "state", "state.json")
+	if err := os.MkdirAll(filepath.Dir(statePath), 0o755); err != nil {
+		t.Fatalf("create state directory: %v", err)
+	}
+	if err := os.WriteFile(statePath, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("write corrupt state: %v", err)
+	}
+	application := NewWithDataRoot(dataRoot)
+	if err := application.Start(context.Background()); err != nil {
+		t.Fatalf("Start() with corrupt storage error = %v, want degraded start", err)
+	}
+	defer application.Stop(context.Background())
+	var changes atomic.Int32
+	application.SetCapabilityHandler(func(report CapabilityReport) { changes.Add(1) })
+
+	report, err := application.Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+	if !report.Degraded || report.Capabilities[0].Capability != CapabilityStorage || report.Capabilities[0].Available || report.Capabilities[0].Reason == "" {
+		t.Fatalf("Capabilities() = %+v, want storage unavailable", report)
+	}
+	err = application.RequireCapabilities(context.Background(), CapabilityStorage)
+	var capabilityErr *CapabilityError
+	if !errors.As(err, &capabilityErr) || capabilityErr.Capability != CapabilityStorage || !errors.Is(err, ErrCapabilityUnavailable) {
+		t.Fatalf("RequireCapabilities() error = %v, want storage capability error", err)
+	}
+
+	if err := os.Remove(statePath); err != nil {
+		t.Fatalf("remove corrupt state: %v", err)
+	}
+	if err := application.RequireCapabilities(context.Background(), CapabilityStorage); err != nil {
+		t.Fatalf("RequireCapabilities() after fix error = %v", err)
+	}
+	if changes.Load() != 1 {
+		t.Fatalf("capability handler calls = %d, want 1", changes.Load())
+	}
+	if _, err := application.RecentProjects(context.Background(), 10); err != nil {
+		t.Fatalf("RecentProjects() after recovery error = %v", err)
+	}
+}
+
+func TestRequireCapabilitiesReprobesUnavailable(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	var goplsInstalled atomic.Bool
+	application.capabilities.probe = func(ctx context.Context, capability Capability) error {
+		if capability == CapabilityGopls && !goplsInstalled.Load() {
+			return errors.New("gopls not found")
+		}
+		return nil
+	}
+	report, err := application.RecheckCapabilities(context.Background())
+	if err != nil {
+		t.Fatalf("RecheckCapabilities() error = %v", err)
+	}
+	if !report.Degraded || len(report.Capabilities) != 3 || report.Capabilities[2].Available {
+		t.Fatalf("RecheckCapabilities() = %+v, want gopls unavailable", report)
+	}
+	if err := application.RequireCapabilities(context.Background(), CapabilityStorage, CapabilityToolchain); err != nil {
+		t.Fatalf("RequireCapabilities(storage, toolchain) error = %v", err)
+	}
+	if err := application.RequireCapabilities(context.Background(), CapabilityGopls); !errors.Is(err, ErrCapabilityUnavailable) {
+		t.Fatalf("RequireCapabilities(gopls) error = %v", err)
+	}
+
+	goplsInstalled.Store(true)
+	if err := application.RequireCapabilities(context.Background(), CapabilityGopls); err != nil {
+		t.Fatalf("RequireCapabilities(gopls) after install error = %v", err)
+	}
+	if report, _ := application.Capabilities(context.Background()); report.Degraded {
+		t.Fatalf("Capabilities() after recovery = %+v", report)
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/diagnostics"
+	"gopoke/internal/execution"
+	"gopoke/internal/i18n"
+)
+
+// CheckSnippet builds the snippet of request, and vets it once it builds,
+// without running the program. It resolves the project, toolchain and
+// environment as a run would and reports compiler errors and vet findings
+// as diagnostics, giving fast feedback while gopls is unavailable.
+func (a *Application) CheckSnippet(ctx context.Context, request execution.RunRequest) (execution.CheckResult, error) {
+	if err := ctx.Err(); err != nil {
+		return execution.CheckResult{}, fmt.Errorf("check snippet context: %w", err)
+	}
+	resolved, err := a.resolveRunRequest(ctx, request)
+	if err != nil {
+		return execution.CheckResult{}, err
+	}
+	if _, err := prepareBenchmarkSnippet(&resolved); err != nil {
+		return execution.CheckResult{}, err
+	}
+	backend := a.executionBackend()
+	checker, ok := backend.(execution.Checker)
+	if !ok {
+		return execution.CheckResult{}, fmt.Errorf("execution backend %q cannot check snippets", backend.Name())
+	}
+	a.snippetCache.useMu.RLock()
+	result, err := checker.Check(ctx, resolved.projectPath, resolved.source, execution.CheckOptions{
+		WorkingDirectory: resolved.workingDirectory,
+		Environment:      resolved.environment,
+		Toolchain:        resolved.toolchain,
+		Timeout:          resolved.timeout,
+		Vet:              true,
+		CacheDir:         a.snippetCache.dir(resolved.projectID),
+	})
+	a.snippetCache.useMu.RUnlock()
+	a.scheduleSnippetCacheEviction()
+	if err != nil {
+		return execution.CheckResult{}, fmt.Errorf("check snippet: %w", err)
+	}
+	a.localizeCheckResult(&result)
+	return result, nil
+}
+
+// localizeCheckResult translates placeholder messages and summarizes the
+// diagnostics of the failed stage.
+func (a *Application) localizeCheckResult(result *execution.CheckResult) {
+	localizer := a.localizer()
+	switch {
+	case result.TimedOut && result.Stderr == execution.MessageTimedOut:
+		result.Stderr = localizer.T(i18n.MsgRunTimedOut)
+	case result.Canceled && result.Stderr == execution.MessageCanceled:
+		result.Stderr = localizer.T(i18n.MsgRunCanceled)
+	}
+	if result.OK || strings.TrimSpace(result.Stderr) == "" {
+		return
+	}
+
+	var parsed []diagnostics.Diagnostic
+	if result.Stage == execution.CheckStageVet {
+		parsed = diagnostics.ParseVetFindings(result.Stderr)
+	} else {
+		parsed = diagnostics.ParseCompileErrors(result.Stderr)
+	}
+	parsed = diagnostics.AttachExplanations(parsed, localizer)
+	result.Diagnostics = convertDiagnostics(parsed)
+	if len(parsed) > 0 {
+		result.DiagnosticsSummary = diagnostics.Summary(parsed, localizer)
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/diagnostics"
+	"gopoke/internal/execution"
+)
+
+func TestCheckSnippetReportsDiagnosticsWithoutRunning(t *testing.T) {
+	requireGoToolchain(t)
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	source := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\\n\", \"s\")\n\tpanic(\"ran\")\n}\n"
+	result, err := application.CheckSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source})
+	if err != nil {
+		t.Fatalf("CheckSnippet() error = %v", err)
+	}
+	if result.OK || result.Stage != execution.CheckStageVet {
+		t.Fatalf("result = %+v, want a failed vet stage", result)
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Kind != diagnostics.KindVet || result.Diagnostics[0].Line != 6 {
+		t.Fatalf("Diagnostics = %+v, want one vet finding on line 6", result.Diagnostics)
+	}
+	if result.DiagnosticsSummary != "1 vet finding" {
+		t.Fatalf("DiagnosticsSummary = %q, want %q", result.DiagnosticsSummary, "1 vet finding")
+	}
+
+	result, err = application.CheckSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: "package main\n\nfunc main() {\n\tx := 1\n}\n"})
+	if err != nil {
+		t.Fatalf("CheckSnippet() error = %v", err)
+	}
+	if result.Stage != execution.CheckStageBuild || len(result.Diagnostics) != 1 || result.Diagnostics[0].Kind != diagnostics.KindCompile {
+		t.Fatalf("result = %+v, want one compile error from the build stage", result)
+	}
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopoke/internal/execution"
+)
+
+// Command categories reported in Command.Category.
+const (
+	CommandCategoryRun       = "Run"
+	CommandCategoryEdit      = "Edit"
+	CommandCategoryShare     = "Share"
+	CommandCategoryProject   = "Project"
+	CommandCategoryToolchain = "Toolchain"
+	CommandCategoryPlugin    = "Plugin"
+)
+
+// Prefixes of command IDs that carry a parameter after the colon, such as
+// "project.openRecent:/home/me/proj". Plugin command IDs are
+// "plugin:<plugin>:<command>".
+const (
+	commandOpenRecentPrefix      = "project.openRecent:"
+	commandSwitchToolchainPrefix = "toolchain.switch:"
+	commandPluginPrefix          = "plugin:"
+)
+
+// recentCommandLimit caps the recent projects listed as commands.
+const recentCommandLimit = 10
+
+// Command is an action the command palette and key bindings can invoke
+// through ExecuteCommand.
+type Command struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+	// Detail is secondary text, such as a project or toolchain path.
+	Detail string `json:"detail,omitempty"`
+	// Keywords are extra words a palette search should match.
+	Keywords []string `json:"keywords,omitempty"`
+	// Args are the fields ExecuteCommand requires in its JSON arguments.
+	Args []string `json:"args,omitempty"`
+}
+
+// commandArgs holds every argument field a built-in command reads.
+type commandArgs struct {
+	ProjectPath string `json:"projectPath"`
+	Source      string `json:"source"`
+	RunID       string `json:"runId"`
+	Path        string `json:"path"`
+	URL         string `json:"url"`
+}
+
+type builtinCommand struct {
+	Command
+	run func(a *Application, ctx context.Context, args commandArgs, raw []byte) (any, error)
+}
+
+var builtinCommands = []builtinCommand{
+	{
+		Command: Command{ID: "run.snippet", Title: "Run Snippet", Category: CommandCategoryRun, Keywords: []string{"execute", "go run", "start"}, Args: []string{"projectPath", "source"}},
+		run: func(a *Application, ctx context.Context, _ commandArgs, raw []byte) (any, error) {
+			var request execution.RunRequest
+			if err := json.Unmarshal(raw, &request); err != nil {
+				return nil, fmt.Errorf("decode run request: %w", err)
+			}
+			return a.RunSnippet(ctx, request, nil, nil)
+		},
+	},
+	{
+		Command: Command{ID: "run.cancel", Title: "Stop Run", Category: CommandCategoryRun, Keywords: []string{"cancel", "kill", "abort"}, Args: []string{"runId"}},
+		run: func(a *Application, ctx context.Context, args commandArgs, _ []byte) (any, error) {
+			return nil, a.CancelRun(ctx, args.RunID)
+		},
+	},
+	{
+		Command: Command{ID: "run.clearCache", Title: "Clear Run Cache", Category: CommandCategoryRun, Keywords: []string{"cache", "reset"}, Args: []string{"projectPath"}},
+		run: func(a *Application, ctx context.Context, args commandArgs, _ []byte) (any, error) {
+			return a.ClearRunCache(ctx, args.ProjectPath)
+		},
+	},
+	{
+		Command: Command{ID: "edit.format", Title: "Format Snippet", Category: CommandCategoryEdit, Keywords: []string{"gofmt", "tidy", "indent"}, Args: []string{"source"}},
+		run: func(a *Application, ctx context.Context, args commandArgs, _ []byte) (any, error) {
+			return a.FormatSnippet(ctx, args.Source)
+		},
+	},
+	{
+		Command: Command{ID: "share.playground", Title: "Share to Go Playground", Category: CommandCategoryShare, Keywords: []string{"link", "upload", "play.golang"}, Args: []string{"source"}},
+		run: func(a *Application, ctx context.Context, args commandArgs, _ []byte) (any, error) {
+			return a.PlaygroundShare(ctx, args.Source)
+		},
+	},
+	{
+		Command: Command{ID: "share.import", Title: "Import from Go Playground", Category: CommandCategoryShare, Keywords: []string{"download", "open link"}, Args: []string{"url"}},
+		run: func(a *Application, ctx context.Context, args commandArgs, _ []byte) (any, error) {
+			return a.PlaygroundImport(ctx, args.URL)
+		},
+	},
+	{
+		Command: Command{ID: "project.open", Title: "Open Project", Category: CommandCategoryProject, Keywords: []string{"folder", "module"}, Args: []string{"path"}},
+		run: func(a *Application, ctx context.Context, args commandArgs, _ []byte) (any, error) {
+			return a.OpenProject(ctx, args.Path)
+		},
+	},
+	{
+		Command: Command{ID: "plugins.reload", Title: "Reload Plugins", Category: CommandCategoryPlugin, Keywords: []string{"extensions", "restart"}},
+		run: func(a *Application, ctx context.Context, _ commandArgs, _ []byte) (any, error) {
+			return a.ReloadPlugins(ctx)
+		},
+	},
+}
+
+// ListCommands returns every command the backend can execute: the built-in
+// actions, one per recent project, one per available toolchain and the
+// commands of running plugins.
+func (a *Application) ListCommands(ctx context.Context) ([]Command, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list commands context: %w", err)
+	}
+	commands := make([]Command, 0, len(builtinCommands))
+	for _, builtin := range builtinCommands {
+		commands = append(commands, builtin.Command)
+	}
+
+	if a.projects != nil {
+		recent, err := a.RecentProjects(ctx, recentCommandLimit)
+		if err != nil {
+			return nil, fmt.Errorf("list commands: %w", err)
+		}
+		for _, record := range recent {
+			commands = append(commands, Command{
+				ID:       commandOpenRecentPrefix + record.Path,
+				Title:    "Open Recent: " + filepath.Base(record.Path),
+				Category: CommandCategoryProject,
+				Detail:   record.Path,
+				Keywords: []string{"recent", "reopen"},
+			})
+		}
+	}
+
+	toolchains, err := a.AvailableToolchains(ctx)
+	if err != nil {
+		a.logger.Warn("list toolchain commands failed", "error", err)
+	}
+	for _, toolchain := range toolchains {
+		title := "Switch Toolchain: " + toolchain.Name
+		if toolchain.Version != "" {
+			title += " (" + toolchain.Version + ")"
+		}
+		commands = append(commands, Command{
+			ID:       commandSwitchToolchainPrefix + toolchain.Path,
+			Title:    title,
+			Category: CommandCategoryToolchain,
+			Detail:   toolchain.Path,
+			Keywords: []string{"go version", "sdk"},
+			Args:     []string{"projectPath"},
+		})
+	}
+
+	if a.plugins != nil {
+		for _, command := range a.plugins.Commands() {
+			commands = append(commands, Command{
+				ID:       commandPluginPrefix + command.Plugin + ":" + command.ID,
+				Title:    command.Title,
+				Category: CommandCategoryPlugin,
+				Detail:   command.Plugin,
+				Keywords: []string{command.Plugin},
+			})
+		}
+	}
+	return commands, nil
+}
+
+// ExecuteCommand runs the command with id, as listed by ListCommands.
+// args is a JSON object holding the command's Args; empty means none.
+// Plugin commands receive args unchanged.
+func (a *Application) ExecuteCommand(ctx context.Context, id string, args string) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("execute command context: %w", err)
+	}
+	id = strings.TrimSpace(id)
+	raw := []byte(strings.TrimSpace(args))
+	if len(raw) == 0 {
+		raw = []byte("{}")
+	}
+
+	if rest, ok := strings.CutPrefix(id, commandPluginPrefix); ok {
+		plugin, command, found := strings.Cut(rest, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid plugin command %q", id)
+		}
+		return a.RunPluginCommand(ctx, plugin, command, args)
+	}
+
+	var decoded commandArgs
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("command arguments must be a JSON object: %w", err)
+	}
+	if path, ok := strings.CutPrefix(id, commandOpenRecentPrefix); ok {
+		return a.OpenProject(ctx, path)
+	}
+	if toolchain, ok := strings.CutPrefix(id, commandSwitchToolchainPrefix); ok {
+		if strings.TrimSpace(decoded.ProjectPath) == "" {
+			return nil, fmt.Errorf("command %q requires projectPath", id)
+		}
+		return a.SetProjectToolchain(ctx, decoded.ProjectPath, toolchain)
+	}
+	for _, builtin := range builtinCommands {
+		if builtin.ID == id {
+			return builtin.run(a, ctx, decoded, raw)
+		}
+	}
+	return nil, fmt.Errorf("command %q not found", id)
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestListCommandsIncludesRecentProjects(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	commands, err := application.ListCommands(ctx)
+	if err != nil {
+		t.Fatalf("ListCommands() error = %v", err)
+	}
+	ids := make([]string, 0, len(commands))
+	for _, command := range commands {
+		if command.Title == "" || command.Category == "" {
+			t.Fatalf("command %+v lacks a title or category", command)
+		}
+		ids = append(ids, command.ID)
+	}
+	for _, want := range []string{"run.snippet", "edit.format", "share.playground", commandOpenRecentPrefix + projectDir} {
+		if !slices.Contains(ids, want) {
+			t.Fatalf("ListCommands() ids = %v, missing %q", ids, want)
+		}
+	}
+}
+
+func TestExecuteCommand(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	formatted, err := application.ExecuteCommand(ctx, "edit.format", `{"source":"package main\nfunc main(){}"}`)
+	if err != nil || formatted != "package main\n\nfunc main() {}\n" {
+		t.Fatalf("ExecuteCommand(edit.format) = %q, %v", formatted, err)
+	}
+
+	args, _ := json.Marshal(map[string]string{"projectPath": projectDir, "source": "package main\n\n//stdout: hi\nfunc main() {}\n"})
+	outcome, err := application.ExecuteCommand(ctx, "run.snippet", string(args))
+	if err != nil {
+		t.Fatalf("ExecuteCommand(run.snippet) error = %v", err)
+	}
+	if result, ok := outcome.(execution.Result); !ok || result.Stdout != "hi\n" {
+		t.Fatalf("ExecuteCommand(run.snippet) = %#v", outcome)
+	}
+
+	if _, err := application.ExecuteCommand(ctx, commandOpenRecentPrefix+projectDir, ""); err != nil {
+		t.Fatalf("ExecuteCommand(open recent) error = %v", err)
+	}
+	for _, id := range []string{"missing", "plugin:nocommand", commandSwitchToolchainPrefix + "go"} {
+		if _, err := application.ExecuteCommand(ctx, id, ""); err == nil {
+			t.Fatalf("ExecuteCommand(%q) error = nil", id)
+		}
+	}
+	if _, err := application.ExecuteCommand(ctx, "edit.format", "[1]"); err == nil {
+		t.Fatal("ExecuteCommand() with non-object args error = nil")
+	}
+}
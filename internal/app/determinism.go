@@ -0,0 +1,25 @@
+package app
+
+import (
+	"runtime"
+
+	"gopoke/internal/execution"
+)
+
+// RunDeterminismHelper returns helper code snippets can paste to read the
+// seed and frozen start time of a reproducible run.
+func (a *Application) RunDeterminismHelper() string {
+	return execution.DeterminismHelperSource
+}
+
+// RunDeadlineHelper returns helper code snippets can paste to derive a
+// context from the deadline a run exports.
+func (a *Application) RunDeadlineHelper() string {
+	return execution.DeadlineHelperSource
+}
+
+// AvailableCPUs returns how many CPUs a run can be pinned to; CPU indexes in
+// RunRequest.CPUAffinity range from zero to one less than this.
+func (a *Application) AvailableCPUs() int {
+	return runtime.NumCPU()
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/testutil"
+)
+
+func TestRunSnippetRejectsInvalidFrozenTime(t *testing.T) {
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	application.backend = &execution.FakeBackend{}
+
+	runCtx, cancel := testutil.TestRunContext(t)
+	defer cancel()
+	_, err := application.RunSnippet(runCtx, execution.RunRequest{
+		ProjectPath: projectDir,
+		Source:      "package main\nfunc main() {}\n",
+		FrozenTime:  "01/02/2025",
+	}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "frozen time") {
+		t.Fatalf("RunSnippet() error = %v, want frozen time error", err)
+	}
+	if !strings.Contains(application.RunDeterminismHelper(), execution.EnvSeed) {
+		t.Fatal("RunDeterminismHelper() does not read the seed variable")
+	}
+}
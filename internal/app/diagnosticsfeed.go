@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"gopoke/internal/diagnostics"
+	"gopoke/internal/execution"
+)
+
+// DocumentDiagnostics returns the unified diagnostics feed for one editor
+// document: live gopls diagnostics merged with the compile and panic
+// diagnostics of runID (optional), deduplicated and ranked by severity.
+func (a *Application) DocumentDiagnostics(ctx context.Context, documentURI string, runID string) ([]diagnostics.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("document diagnostics context: %w", err)
+	}
+	documentURI = strings.TrimSpace(documentURI)
+	documentPath, err := documentPathFromURI(documentURI)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]diagnostics.Entry, 0)
+	snippetDocument := false
+	if a.lspManager != nil {
+		snippetDocument = documentURI == a.lspManager.WorkspaceInfo().SnippetURI
+		for _, live := range a.lspManager.LiveDiagnostics(documentURI) {
+			// gopls labels type-checker errors "compiler"; attribute them to
+			// gopls so the feed shows which side reported each entry.
+			source := live.Source
+			if source == "" || source == "compiler" {
+				source = diagnostics.SourceGopls
+			}
+			entries = append(entries, diagnostics.Entry{
+				File:     documentPath,
+				Line:     live.Line,
+				Column:   live.Column,
+				Severity: live.Severity,
+				Code:     live.Code,
+				Message:  live.Message,
+				Sources:  []string{source},
+			})
+		}
+	}
+
+	if runID = strings.TrimSpace(runID); runID != "" {
+		a.recentMu.Lock()
+		result, ok := a.recentResults[runID]
+		a.recentMu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("run result not found: %s", runID)
+		}
+		for _, item := range result.Diagnostics {
+			if !runDiagnosticInDocument(item.File, documentPath, snippetDocument) {
+				continue
+			}
+			source := diagnostics.SourceCompiler
+			if item.Kind == diagnostics.KindPanic {
+				source = diagnostics.SourceRuntime
+			}
+			entries = append(entries, diagnostics.Entry{
+				File:        documentPath,
+				Line:        item.Line,
+				Column:      item.Column,
+				Severity:    diagnostics.SeverityForKind(item.Kind),
+				Kind:        item.Kind,
+				Message:     item.Message,
+				Sources:     []string{source},
+				Explanation: convertExplanation(item.Explanation),
+			})
+		}
+	}
+
+	return diagnostics.Merge(entries), nil
+}
+
+// runDiagnosticInDocument matches a run diagnostic's file to the document.
+// Runs compile the snippet editor's content as a generated cache file, so
+// those diagnostics belong to the snippet document.
+func runDiagnosticInDocument(file string, documentPath string, snippetDocument bool) bool {
+	if snippetDocument && execution.IsSnippetFile(file) {
+		return true
+	}
+	return filepath.IsAbs(file) && filepath.Clean(file) == documentPath
+}
+
+func documentPathFromURI(documentURI string) (string, error) {
+	if documentURI == "" {
+		return "", fmt.Errorf("document uri is required")
+	}
+	parsed, err := url.Parse(documentURI)
+	if err != nil || parsed.Scheme != "file" {
+		return "", fmt.Errorf("document uri must be a file uri: %s", documentURI)
+	}
+	return filepath.Clean(filepath.FromSlash(parsed.Path)), nil
+}
+
+func convertExplanation(explanation *execution.DiagnosticExplanation) *diagnostics.Explanation {
+	if explanation == nil {
+		return nil
+	}
+	return &diagnostics.Explanation{
+		ID:     explanation.ID,
+		Title:  explanation.Title,
+		Detail: explanation.Detail,
+		Fix:    explanation.Fix,
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/diagnostics"
+	"gopoke/internal/execution"
+)
+
+func TestDocumentDiagnosticsMergesRunDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	documentPath := filepath.Join(t.TempDir(), "main.go")
+	application.rememberResult("run-feed", execution.Result{
+		Diagnostics: []execution.Diagnostic{
+			{Kind: diagnostics.KindCompile, File: documentPath, Line: 3, Column: 2, Message: "declared and not used: x",
+				Explanation: &execution.DiagnosticExplanation{ID: "unusedVariable"}},
+			{Kind: diagnostics.KindCompile, File: documentPath, Line: 3, Column: 2, Message: "declared and not used: x"},
+			{Kind: diagnostics.KindPanic, File: "/elsewhere/other.go", Line: 9, Column: 1, Message: "boom"},
+		},
+	})
+
+	feed, err := application.DocumentDiagnostics(context.Background(), "file://"+filepath.ToSlash(documentPath), "run-feed")
+	if err != nil {
+		t.Fatalf("DocumentDiagnostics() error = %v", err)
+	}
+	if got, want := len(feed), 1; got != want {
+		t.Fatalf("len(feed) = %d, want %d: %+v", got, want, feed)
+	}
+	if feed[0].Explanation == nil || feed[0].Sources[0] != diagnostics.SourceCompiler {
+		t.Fatalf("feed[0] = %+v, want compiler entry with explanation", feed[0])
+	}
+
+	if _, err := application.DocumentDiagnostics(context.Background(), documentPath, ""); err == nil {
+		t.Fatal("DocumentDiagnostics(non-uri) error = nil, want error")
+	}
+	if _, err := application.DocumentDiagnostics(context.Background(), "file://"+documentPath, "missing"); err == nil {
+		t.Fatal("DocumentDiagnostics(unknown run) error = nil, want error")
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"gopoke/internal/lsp"
+	"gopoke/internal/snippetmeta"
+	"gopoke/internal/snippetparam"
+)
+
+// editorDirectives are the gopoke directives the editor explains on hover
+// and offers as completions.
+var editorDirectives = []lsp.Directive{
+	{
+		Name:  snippetmeta.DirectiveName,
+		Usage: snippetmeta.DirectiveName + " NAME",
+		Doc:   "Names the snippet. The name travels with the source through export, import and sharing.",
+	},
+	{
+		Name:  snippetmeta.DirectiveTimeout,
+		Usage: snippetmeta.DirectiveTimeout + " DURATION",
+		Doc:   "Stops the run after DURATION, such as `30s` or `2m`.",
+	},
+	{
+		Name:  snippetmeta.DirectiveEnv,
+		Usage: snippetmeta.DirectiveEnv + " KEY=VALUE",
+		Doc:   "Sets an environment variable for the run, over the project's value. May repeat.",
+	},
+	{
+		Name:  snippetmeta.DirectiveTarget,
+		Usage: snippetmeta.DirectiveTarget + " PACKAGE",
+		Doc:   "Runs the project package PACKAGE, such as `./cmd/api`, instead of the snippet's own package.",
+	},
+	{
+		Name:  snippetparam.Directive,
+		Usage: snippetparam.Directive + " NAME TYPE [default VALUE] [DESCRIPTION]",
+		Doc: "Declares a parameter the run form asks for. TYPE is `string`, `int`, `float`, `bool` or `duration`. " +
+			"The snippet reads it from `" + snippetparam.EnvPrefix + "NAME` or the `-NAME` flag.",
+	},
+	{
+		Name:  RunCacheDirective,
+		Usage: RunCacheDirective,
+		Doc:   "Marks the snippet as deterministic: an unchanged run is answered with the previous result instead of running again.",
+	},
+}
+
+// editorTokenSource returns the gopoke tokens merged into the editor's
+// gopls hover and completion results.
+func (a *Application) editorTokenSource() lsp.TokenSource {
+	return lsp.TokenSource{Directives: editorDirectives, EnvVars: a.editorEnvVars}
+}
+
+// editorEnvVars returns the variables a run of source in projectPath sets,
+// with the value that wins: parameter defaults over the snippet's
+// //gopoke:env directives over the project's variables. Source that does
+// not parse yet only loses its own contributions.
+func (a *Application) editorEnvVars(projectPath string, source string) []lsp.EnvVar {
+	var variables []lsp.EnvVar
+	index := make(map[string]int)
+	set := func(variable lsp.EnvVar) {
+		if position, ok := index[variable.Name]; ok {
+			variables[position] = variable
+			return
+		}
+		index[variable.Name] = len(variables)
+		variables = append(variables, variable)
+	}
+
+	ctx := context.Background()
+	if record, err := a.projectRecordByPath(ctx, projectPath); err == nil {
+		if envVars, err := a.store.ProjectEnvVars(ctx, record.ID); err == nil {
+			for _, envVar := range envVars {
+				set(lsp.EnvVar{Name: envVar.Key, Value: envVar.Value, Masked: envVar.Masked, Source: "project environment"})
+			}
+		}
+	}
+	if meta, err := snippetmeta.Parse(source); err == nil {
+		for _, key := range slices.Sorted(maps.Keys(meta.Env)) {
+			set(lsp.EnvVar{Name: key, Value: meta.Env[key], Source: "snippet's " + snippetmeta.DirectiveEnv + " directive"})
+		}
+	}
+	if params, err := snippetparam.Parse(source); err == nil {
+		for _, param := range params {
+			set(lsp.EnvVar{Name: param.EnvVar, Value: param.Default, Source: "snippet's " + snippetparam.Directive + " default"})
+		}
+	}
+	return variables
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/lsp"
+)
+
+func TestEditorEnvVarsFollowRunPrecedence(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	writeTestFile(t, filepath.Join(projectDir, "go.mod"), "module example.com/tokens\n\ngo 1.22\n")
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	for key, value := range map[string]string{"MODE": "project", "TOKEN": "secret"} {
+		if _, err := application.UpsertProjectEnvVar(ctx, projectDir, key, value, key == "TOKEN"); err != nil {
+			t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+		}
+	}
+
+	source := "//gopoke:env MODE=snippet\n//gopoke:param count int default 3\npackage main\n"
+	got := make(map[string]lsp.EnvVar)
+	for _, variable := range application.editorEnvVars(projectDir, source) {
+		got[variable.Name] = variable
+	}
+	if len(got) != 3 {
+		t.Fatalf("editorEnvVars() = %+v, want one entry per name", got)
+	}
+	if got["MODE"].Value != "snippet" || !got["TOKEN"].Masked || got["GOPOKE_PARAM_COUNT"].Value != "3" {
+		t.Fatalf("editorEnvVars() = %+v, want the values a run uses", got)
+	}
+
+	// A directive that does not parse yet keeps the project's variables.
+	variables := application.editorEnvVars(projectDir, "//gopoke:env =\n")
+	if len(variables) != 2 || variables[0] != (lsp.EnvVar{Name: "MODE", Value: "project", Source: "project environment"}) {
+		t.Fatalf("editorEnvVars(unparsed) = %+v, want the project's variables", variables)
+	}
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/storage"
+	"gopoke/internal/textenc"
+)
+
+// OutputEncodings lists the encodings run output can be decoded from.
+func (a *Application) OutputEncodings() []string {
+	return textenc.Names()
+}
+
+// SetProjectOutputEncoding sets the encoding a project's run output is
+// decoded from, such as a Windows code page. Auto or empty restores
+// detection.
+func (a *Application) SetProjectOutputEncoding(ctx context.Context, projectPath string, encoding string) (storage.ProjectRecord, error) {
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	normalized, err := textenc.Normalize(encoding)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	if normalized == textenc.Auto {
+		normalized = ""
+	}
+	updated, err := a.store.UpdateProjectOutputEncoding(ctx, projectRecord.Path, normalized)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project output encoding: %w", err)
+	}
+	return updated, nil
+}
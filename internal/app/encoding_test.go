@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/richoutput"
+	"gopoke/internal/testutil"
+	"gopoke/internal/textenc"
+)
+
+func TestRunOutputEncodingOverrideAndBinaryHexdump(t *testing.T) {
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	ctx := context.Background()
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	application.backend = &execution.FakeBackend{Responses: []execution.FakeResponse{
+		{Match: "codepage", Outputs: []execution.FakeOutput{{Stream: execution.StreamStdout, Text: "caf\x82\n"}}},
+		{Match: "binary", Outputs: []execution.FakeOutput{{Stream: execution.StreamStdout, Text: "\x7fELF\x02\x01\x01\x00\x00\x00"}}},
+	}}
+
+	if _, err := application.SetProjectOutputEncoding(ctx, projectDir, "ebcdic"); err == nil {
+		t.Fatal("SetProjectOutputEncoding(ebcdic) error = nil, want error")
+	}
+	record, err := application.SetProjectOutputEncoding(ctx, projectDir, "ibm437")
+	if err != nil {
+		t.Fatalf("SetProjectOutputEncoding() error = %v", err)
+	}
+	if record.OutputEncoding != textenc.CP437 {
+		t.Fatalf("OutputEncoding = %q, want %q", record.OutputEncoding, textenc.CP437)
+	}
+
+	runCtx, cancel := testutil.TestRunContext(t)
+	defer cancel()
+	result, err := application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: projectDir, Source: "package main // codepage\n"}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.Stdout != "café\n" || result.StdoutEncoding != textenc.CP437 {
+		t.Fatalf("result = %q as %q, want cp437 text", result.Stdout, result.StdoutEncoding)
+	}
+
+	if _, err := application.SetProjectOutputEncoding(ctx, projectDir, textenc.Auto); err != nil {
+		t.Fatalf("SetProjectOutputEncoding(auto) error = %v", err)
+	}
+	result, err = application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: projectDir, Source: "package main // binary\n"}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.StdoutEncoding != textenc.Binary {
+		t.Fatalf("StdoutEncoding = %q, want %q", result.StdoutEncoding, textenc.Binary)
+	}
+	if len(result.RichBlocks) != 1 || result.RichBlocks[0].Type != richoutput.TypeHexdump {
+		t.Fatalf("RichBlocks = %+v, want one hexdump block", result.RichBlocks)
+	}
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopoke/internal/execution"
+)
+
+// MaxEnvMatrixSets bounds the environment sets of one matrix run.
+const MaxEnvMatrixSets = 32
+
+// EnvMatrixCell is the run of the snippet with one environment set.
+type EnvMatrixCell struct {
+	Env map[string]string `json:"env"`
+	// Label names the set by the variables that vary across the matrix,
+	// such as "FEATURE_FLAG=on".
+	Label  string           `json:"label"`
+	Result execution.Result `json:"result"`
+	// Error is set when the run could not start; Result is then empty.
+	Error string `json:"error,omitempty"`
+	// Group indexes EnvMatrixResult.Groups.
+	Group int `json:"group"`
+}
+
+// EnvMatrixGroup is a set of cells whose runs ended the same way: same exit
+// code, timeout and stdout.
+type EnvMatrixGroup struct {
+	Cells    []int  `json:"cells"`
+	ExitCode int    `json:"exitCode"`
+	TimedOut bool   `json:"timedOut"`
+	Stdout   string `json:"stdout"`
+	Error    string `json:"error,omitempty"`
+}
+
+// EnvMatrixResult compares the runs of one snippet across environment sets.
+type EnvMatrixResult struct {
+	Cells []EnvMatrixCell `json:"cells"`
+	// VaryingKeys are the variables whose values differ between sets.
+	VaryingKeys []string         `json:"varyingKeys"`
+	Groups      []EnvMatrixGroup `json:"groups"`
+	// Consistent is true when every set produced the same outcome.
+	Consistent bool `json:"consistent"`
+	// Canceled is true when a canceled run stopped the matrix early; later
+	// sets have no cell.
+	Canceled bool `json:"canceled"`
+}
+
+// RunEnvMatrix runs request once per environment set, one after another,
+// and groups the runs by outcome. Each set is applied over the request's own
+// Env. Runs are numbered after request.RunID, when set, as RunID-1, RunID-2
+// and so on, so CancelRun can stop the current one, which ends the matrix.
+func (a *Application) RunEnvMatrix(ctx context.Context, request execution.RunRequest, envSets []map[string]string) (EnvMatrixResult, error) {
+	if err := ctx.Err(); err != nil {
+		return EnvMatrixResult{}, fmt.Errorf("run env matrix context: %w", err)
+	}
+	if len(envSets) == 0 {
+		return EnvMatrixResult{}, fmt.Errorf("at least one environment set is required")
+	}
+	if len(envSets) > MaxEnvMatrixSets {
+		return EnvMatrixResult{}, fmt.Errorf("at most %d environment sets can run in one matrix", MaxEnvMatrixSets)
+	}
+
+	varying := varyingEnvKeys(envSets)
+	baseRunID := strings.TrimSpace(request.RunID)
+	result := EnvMatrixResult{Cells: make([]EnvMatrixCell, 0, len(envSets)), VaryingKeys: varying, Groups: []EnvMatrixGroup{}}
+	for index, set := range envSets {
+		if err := ctx.Err(); err != nil {
+			return EnvMatrixResult{}, fmt.Errorf("run env matrix context: %w", err)
+		}
+		cellRequest := request
+		cellRequest.Env = maps.Clone(request.Env)
+		if cellRequest.Env == nil {
+			cellRequest.Env = make(map[string]string, len(set))
+		}
+		maps.Copy(cellRequest.Env, set)
+		cellRequest.RunID = ""
+		if baseRunID != "" {
+			cellRequest.RunID = baseRunID + "-" + strconv.Itoa(index+1)
+		}
+
+		cell := EnvMatrixCell{Env: maps.Clone(set), Label: envSetLabel(set, varying, index)}
+		if cell.Env == nil {
+			cell.Env = map[string]string{}
+		}
+		runResult, err := a.RunSnippet(ctx, cellRequest, nil, nil)
+		if err != nil {
+			cell.Error = err.Error()
+		} else {
+			cell.Result = runResult
+		}
+		cell.Group = result.addToGroup(index, cell)
+		result.Cells = append(result.Cells, cell)
+		if err == nil && runResult.Canceled {
+			result.Canceled = true
+			break
+		}
+	}
+	result.Consistent = len(result.Groups) == 1 && !result.Canceled
+	return result, nil
+}
+
+// addToGroup files cell under the group with its outcome, creating it if
+// needed, and returns the group's index.
+func (r *EnvMatrixResult) addToGroup(index int, cell EnvMatrixCell) int {
+	for position := range r.Groups {
+		group := &r.Groups[position]
+		if group.Error == cell.Error && group.ExitCode == cell.Result.ExitCode &&
+			group.TimedOut == cell.Result.TimedOut && group.Stdout == cell.Result.Stdout {
+			group.Cells = append(group.Cells, index)
+			return position
+		}
+	}
+	r.Groups = append(r.Groups, EnvMatrixGroup{
+		Cells:    []int{index},
+		ExitCode: cell.Result.ExitCode,
+		TimedOut: cell.Result.TimedOut,
+		Stdout:   cell.Result.Stdout,
+		Error:    cell.Error,
+	})
+	return len(r.Groups) - 1
+}
+
+// varyingEnvKeys returns the sorted keys whose value, or presence, differs
+// between sets.
+func varyingEnvKeys(envSets []map[string]string) []string {
+	keys := make(map[string]bool)
+	for _, set := range envSets {
+		for key := range set {
+			keys[key] = true
+		}
+	}
+	varying := make([]string, 0, len(keys))
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		first, firstOK := envSets[0][key]
+		for _, set := range envSets[1:] {
+			value, ok := set[key]
+			if ok != firstOK || value != first {
+				varying = append(varying, key)
+				break
+			}
+		}
+	}
+	return varying
+}
+
+// envSetLabel describes a set by its varying variables; a set that matches
+// the others on all of them is labeled by its position.
+func envSetLabel(set map[string]string, varying []string, index int) string {
+	parts := make([]string, 0, len(varying))
+	for _, key := range varying {
+		value, ok := set[key]
+		if !ok {
+			parts = append(parts, key+" unset")
+			continue
+		}
+		parts = append(parts, key+"="+value)
+	}
+	if len(parts) == 0 {
+		return "Set " + strconv.Itoa(index+1)
+	}
+	return strings.Join(parts, ", ")
+}
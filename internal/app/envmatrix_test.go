@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestRunEnvMatrixGroupsOutcomes(t *testing.T) {
+	requireGoToolchain(t)
+
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	snippet := strings.Join([]string{
+		"package main",
+		"",
+		"import (",
+		"\t\"fmt\"",
+		"\t\"os\"",
+		")",
+		"",
+		"func main() {",
+		"\tfmt.Print(os.Getenv(\"FEATURE_FLAG\"), \"|\", os.Getenv(\"REGION\"))",
+		"}",
+		"",
+	}, "\n")
+	result, err := application.RunEnvMatrix(context.Background(), execution.RunRequest{
+		ProjectPath: projectDir,
+		Source:      snippet,
+		Env:         map[string]string{"REGION": "eu"},
+	}, []map[string]string{
+		{"FEATURE_FLAG": "on"},
+		{"FEATURE_FLAG": "off"},
+		{"FEATURE_FLAG": "on", "REGION": "eu"},
+	})
+	if err != nil {
+		t.Fatalf("RunEnvMatrix() error = %v", err)
+	}
+
+	if want := []string{"FEATURE_FLAG", "REGION"}; !reflect.DeepEqual(result.VaryingKeys, want) {
+		t.Fatalf("VaryingKeys = %v, want %v", result.VaryingKeys, want)
+	}
+	if got := result.Cells[1].Label; got != "FEATURE_FLAG=off, REGION unset" {
+		t.Fatalf("Cells[1].Label = %q", got)
+	}
+	stdouts := make([]string, 0, len(result.Cells))
+	for _, cell := range result.Cells {
+		if cell.Error != "" {
+			t.Fatalf("cell %q error = %s", cell.Label, cell.Error)
+		}
+		stdouts = append(stdouts, cell.Result.Stdout)
+	}
+	if want := []string{"on|eu", "off|eu", "on|eu"}; !reflect.DeepEqual(stdouts, want) {
+		t.Fatalf("stdouts = %v, want %v", stdouts, want)
+	}
+	if result.Consistent || len(result.Groups) != 2 {
+		t.Fatalf("Consistent = %v, Groups = %+v, want two groups", result.Consistent, result.Groups)
+	}
+	if got := result.Groups[0].Cells; !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Fatalf("Groups[0].Cells = %v, want [0 2]", got)
+	}
+	if result.Cells[1].Group != 1 {
+		t.Fatalf("Cells[1].Group = %d, want 1", result.Cells[1].Group)
+	}
+}
+
+func TestRunEnvMatrixValidatesSets(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	request := execution.RunRequest{ProjectPath: projectDir, Source: "package main\n\n//stdout: same\nfunc main() {}\n"}
+
+	if _, err := application.RunEnvMatrix(context.Background(), request, nil); err == nil {
+		t.Fatal("RunEnvMatrix() with no sets error = nil")
+	}
+	tooMany := make([]map[string]string, MaxEnvMatrixSets+1)
+	if _, err := application.RunEnvMatrix(context.Background(), request, tooMany); err == nil {
+		t.Fatal("RunEnvMatrix() with too many sets error = nil")
+	}
+
+	result, err := application.RunEnvMatrix(context.Background(), request, []map[string]string{
+		{"MODE": "a"},
+		{"MODE": "b"},
+		{"BAD=KEY": "x"},
+	})
+	if err != nil {
+		t.Fatalf("RunEnvMatrix() error = %v", err)
+	}
+	if !strings.Contains(result.Cells[2].Error, "environment variable name") {
+		t.Fatalf("Cells[2].Error = %q, want invalid name", result.Cells[2].Error)
+	}
+	if len(result.Groups) != 2 || !reflect.DeepEqual(result.Groups[0].Cells, []int{0, 1}) || result.Consistent {
+		t.Fatalf("Groups = %+v, Consistent = %v", result.Groups, result.Consistent)
+	}
+}
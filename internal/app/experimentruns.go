@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopoke/internal/storage"
+)
+
+// maxExperimentOutputBytes bounds the output an experiment keeps of its
+// latest run.
+const maxExperimentOutputBytes = 4 * 1024
+
+// ExperimentSummary sums up the runs attached to an experiment.
+type ExperimentSummary struct {
+	Experiment storage.ExperimentRecord `json:"experiment"`
+	Runs       int                      `json:"runs"`
+	// Statuses counts the runs by status.
+	Statuses map[string]int `json:"statuses"`
+	// SuccessRate is the fraction of runs that succeeded; 0 without runs.
+	SuccessRate      float64 `json:"successRate"`
+	MinDurationMS    int64   `json:"minDurationMs"`
+	MedianDurationMS int64   `json:"medianDurationMs"`
+	MaxDurationMS    int64   `json:"maxDurationMs"`
+	// LatestRun is the attached run that started last, and LatestOutput the
+	// tail of its output when the run was attached while still recent.
+	LatestRun    *storage.RunRecord `json:"latestRun,omitempty"`
+	LatestOutput string             `json:"latestOutput,omitempty"`
+}
+
+// CreateExperiment starts an experiment in a project that runs can be
+// attached to with AssignRun.
+func (a *Application) CreateExperiment(ctx context.Context, projectPath string, name string, description string) (storage.ExperimentRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ExperimentRecord{}, fmt.Errorf("create experiment context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ExperimentRecord{}, err
+	}
+	experiment, err := a.store.CreateExperiment(ctx, storage.ExperimentRecord{
+		ProjectID:   record.ID,
+		Name:        name,
+		Description: description,
+	})
+	if err != nil {
+		return storage.ExperimentRecord{}, fmt.Errorf("create experiment: %w", err)
+	}
+	return experiment, nil
+}
+
+// AssignRun attaches a recorded run to an experiment. The tail of the
+// run's output is kept with the experiment while the result is still among
+// the recent ones; older runs are attached without output.
+func (a *Application) AssignRun(ctx context.Context, experimentID string, runID string) (storage.RunRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.RunRecord{}, fmt.Errorf("assign run context: %w", err)
+	}
+	if a.store == nil {
+		return storage.RunRecord{}, fmt.Errorf("storage service not initialized")
+	}
+	experimentID = strings.TrimSpace(experimentID)
+	runID = strings.TrimSpace(runID)
+
+	output := ""
+	a.recentMu.Lock()
+	result, ok := a.recentResults[runID]
+	a.recentMu.Unlock()
+	if ok {
+		stripANSI(&result)
+		output = result.Stdout
+		if strings.TrimSpace(output) == "" {
+			output = result.Stderr
+		}
+		output = outputTail(output, maxExperimentOutputBytes)
+	}
+
+	run, err := a.store.AssignRun(ctx, experimentID, runID, output)
+	if err != nil {
+		return storage.RunRecord{}, fmt.Errorf("assign run: %w", err)
+	}
+	return run, nil
+}
+
+// ExperimentSummaries sums up each experiment of a project, in creation
+// order.
+func (a *Application) ExperimentSummaries(ctx context.Context, projectPath string) ([]ExperimentSummary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("experiment summaries context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	experiments, err := a.store.ProjectExperiments(ctx, record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load experiments: %w", err)
+	}
+	summaries := make([]ExperimentSummary, 0, len(experiments))
+	for _, experiment := range experiments {
+		runs, err := a.store.ExperimentRuns(ctx, experiment.ID)
+		if err != nil {
+			return nil, fmt.Errorf("load experiment runs: %w", err)
+		}
+		summaries = append(summaries, summarizeExperiment(experiment, runs))
+	}
+	return summaries, nil
+}
+
+// summarizeExperiment sums up runs, which are sorted latest first.
+func summarizeExperiment(experiment storage.ExperimentRecord, runs []storage.RunRecord) ExperimentSummary {
+	summary := ExperimentSummary{
+		Experiment: experiment,
+		Runs:       len(runs),
+		Statuses:   make(map[string]int),
+	}
+	if len(runs) == 0 {
+		return summary
+	}
+	durations := make([]int64, 0, len(runs))
+	for _, run := range runs {
+		summary.Statuses[run.Status]++
+		durations = append(durations, run.DurationMS)
+	}
+	slices.Sort(durations)
+	summary.SuccessRate = float64(summary.Statuses[runStatusSuccess]) / float64(len(runs))
+	summary.MinDurationMS = durations[0]
+	summary.MedianDurationMS = durations[len(durations)/2]
+	summary.MaxDurationMS = durations[len(durations)-1]
+	latest := runs[0]
+	summary.LatestRun = &latest
+	if experiment.LatestRunID == latest.ID {
+		summary.LatestOutput = experiment.LatestOutput
+	}
+	return summary
+}
+
+// outputTail returns the last maxBytes of output, starting at a line when
+// one begins in it.
+func outputTail(output string, maxBytes int) string {
+	if len(output) <= maxBytes {
+		return output
+	}
+	tail := output[len(output)-maxBytes:]
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+	}
+	if newline := strings.IndexByte(tail, '\n'); newline >= 0 && newline < len(tail)-1 {
+		tail = tail[newline+1:]
+	}
+	return tail
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/storage"
+)
+
+func TestExperimentGroupsRunsIntoSummaries(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	opened, err := application.OpenProject(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	experiment, err := application.CreateExperiment(ctx, projectDir, " cache sizing ", "Which LRU size keeps hit rate above 90%?")
+	if err != nil {
+		t.Fatalf("CreateExperiment() error = %v", err)
+	}
+	if experiment.Name != "cache sizing" || experiment.ID == "" {
+		t.Fatalf("CreateExperiment() = %+v", experiment)
+	}
+	if _, err := application.CreateExperiment(ctx, projectDir, "Cache Sizing", ""); err == nil {
+		t.Fatal("CreateExperiment(duplicate name) error = nil")
+	}
+
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	runs := []storage.RunRecord{
+		{ID: "run_a", Status: runStatusSuccess, DurationMS: 300},
+		{ID: "run_b", Status: runStatusFailed, ExitCode: 1, DurationMS: 100},
+		{ID: "run_c", Status: runStatusSuccess, DurationMS: 200},
+		{ID: "run_d", Status: runStatusSuccess, DurationMS: 50},
+	}
+	for index, run := range runs {
+		run.ProjectID = opened.Project.ID
+		run.StartedAt = started.Add(time.Duration(index) * time.Minute)
+		if _, err := application.store.RecordRun(ctx, run); err != nil {
+			t.Fatalf("RecordRun(%s) error = %v", run.ID, err)
+		}
+	}
+	application.rememberResult("run_c", execution.Result{Stdout: strings.Repeat("x", maxExperimentOutputBytes) + "\nhit rate 93%\n"})
+
+	// run_b is attached after run_c but started before it, so run_c stays
+	// the latest run.
+	for _, runID := range []string{"run_a", "run_c", "run_b"} {
+		if _, err := application.AssignRun(ctx, experiment.ID, runID); err != nil {
+			t.Fatalf("AssignRun(%s) error = %v", runID, err)
+		}
+	}
+	if _, err := application.AssignRun(ctx, experiment.ID, "run_missing"); err == nil {
+		t.Fatal("AssignRun(missing run) error = nil")
+	}
+
+	summaries, err := application.ExperimentSummaries(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("ExperimentSummaries() error = %v", err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("ExperimentSummaries() = %d summaries, want 1", len(summaries))
+	}
+	summary := summaries[0]
+	if summary.Runs != 3 || summary.Statuses[runStatusSuccess] != 2 || summary.Statuses[runStatusFailed] != 1 {
+		t.Fatalf("summary counts = %d runs, statuses %v", summary.Runs, summary.Statuses)
+	}
+	if summary.SuccessRate < 0.66 || summary.SuccessRate > 0.67 {
+		t.Fatalf("SuccessRate = %v, want 2/3", summary.SuccessRate)
+	}
+	if summary.MinDurationMS != 100 || summary.MedianDurationMS != 200 || summary.MaxDurationMS != 300 {
+		t.Fatalf("durations = %d/%d/%d", summary.MinDurationMS, summary.MedianDurationMS, summary.MaxDurationMS)
+	}
+	if summary.LatestRun == nil || summary.LatestRun.ID != "run_c" || summary.LatestOutput != "hit rate 93%\n" {
+		t.Fatalf("latest = %+v, output %q", summary.LatestRun, summary.LatestOutput)
+	}
+
+	// A later run without a remembered result replaces the latest output.
+	if _, err := application.AssignRun(ctx, experiment.ID, "run_d"); err != nil {
+		t.Fatalf("AssignRun(run_d) error = %v", err)
+	}
+	summaries, err = application.ExperimentSummaries(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("ExperimentSummaries() error = %v", err)
+	}
+	if latest := summaries[0]; latest.LatestRun.ID != "run_d" || latest.LatestOutput != "" || latest.Runs != 4 {
+		t.Fatalf("after run_d: latest %s, output %q, %d runs", latest.LatestRun.ID, latest.LatestOutput, latest.Runs)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/project"
+	"gopoke/internal/storage"
+)
+
+// ToolchainExperiments reports which known GOEXPERIMENT values the project's
+// selected toolchain accepts. An empty projectPath checks the default go.
+func (a *Application) ToolchainExperiments(ctx context.Context, projectPath string) ([]project.ExperimentSupport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("toolchain experiments context: %w", err)
+	}
+	selectedToolchain := "go"
+	if strings.TrimSpace(projectPath) != "" {
+		projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		if toolchain := strings.TrimSpace(projectRecord.Toolchain); toolchain != "" {
+			selectedToolchain = toolchain
+		}
+	}
+	resolvedToolchain, err := project.ResolveToolchainBinary(selectedToolchain)
+	if err != nil {
+		return nil, fmt.Errorf("resolve project toolchain: %w", err)
+	}
+	support, err := project.DetectExperiments(ctx, resolvedToolchain)
+	if err != nil {
+		return nil, fmt.Errorf("detect experiments: %w", err)
+	}
+	return support, nil
+}
+
+// SetProjectExperiments stores the GOEXPERIMENT values enabled for a
+// project's runs, rejecting any the selected toolchain does not support.
+func (a *Application) SetProjectExperiments(ctx context.Context, projectPath string, experiments []string) (storage.ProjectRecord, error) {
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	normalized, err := project.NormalizeExperiments(experiments)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	if len(normalized) > 0 {
+		support, err := a.ToolchainExperiments(ctx, projectRecord.Path)
+		if err != nil {
+			return storage.ProjectRecord{}, err
+		}
+		for _, name := range normalized {
+			for _, candidate := range support {
+				if candidate.Name == name && !candidate.Supported {
+					return storage.ProjectRecord{}, fmt.Errorf("GOEXPERIMENT %q is not supported by the selected toolchain", name)
+				}
+			}
+		}
+	}
+	updated, err := a.store.UpdateProjectExperiments(ctx, projectRecord.Path, normalized)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project experiments: %w", err)
+	}
+	return updated, nil
+}
+
+// applyExperiments sets GOEXPERIMENT in environment from the run's selection,
+// falling back to the project's. A structured selection replaces any raw
+// GOEXPERIMENT env var.
+func applyExperiments(environment map[string]string, requested []string, projectExperiments []string) error {
+	selected := projectExperiments
+	if requested != nil {
+		selected = requested
+	}
+	normalized, err := project.NormalizeExperiments(selected)
+	if err != nil {
+		return err
+	}
+	if requested == nil && len(normalized) == 0 {
+		return nil
+	}
+	if len(normalized) == 0 {
+		delete(environment, "GOEXPERIMENT")
+		return nil
+	}
+	environment["GOEXPERIMENT"] = strings.Join(normalized, ",")
+	return nil
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestApplyExperimentsPrecedence(t *testing.T) {
+	t.Parallel()
+
+	environment := map[string]string{"GOEXPERIMENT": "raw"}
+	if err := applyExperiments(environment, nil, nil); err != nil {
+		t.Fatalf("applyExperiments(none) error = %v", err)
+	}
+	if got, want := environment["GOEXPERIMENT"], "raw"; got != want {
+		t.Fatalf("GOEXPERIMENT = %q, want %q", got, want)
+	}
+
+	if err := applyExperiments(environment, nil, []string{"rangefunc", "arenas"}); err != nil {
+		t.Fatalf("applyExperiments(project) error = %v", err)
+	}
+	if got, want := environment["GOEXPERIMENT"], "arenas,rangefunc"; got != want {
+		t.Fatalf("GOEXPERIMENT = %q, want %q", got, want)
+	}
+
+	if err := applyExperiments(environment, []string{}, []string{"arenas"}); err != nil {
+		t.Fatalf("applyExperiments(request none) error = %v", err)
+	}
+	if _, ok := environment["GOEXPERIMENT"]; ok {
+		t.Fatal("GOEXPERIMENT set after request disabled experiments")
+	}
+
+	if err := applyExperiments(environment, []string{"nosuchexperiment"}, nil); err == nil {
+		t.Fatal("applyExperiments(unknown) error = nil, want error")
+	}
+}
+
+func TestSetProjectExperimentsValidates(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	if _, err := application.SetProjectExperiments(ctx, projectDir, []string{"GOEXPERIMENT=arenas"}); err == nil {
+		t.Fatal("SetProjectExperiments(raw env) error = nil, want error")
+	}
+	record, err := application.SetProjectExperiments(ctx, projectDir, nil)
+	if err != nil {
+		t.Fatalf("SetProjectExperiments(nil) error = %v", err)
+	}
+	if len(record.Experiments) != 0 {
+		t.Fatalf("Experiments = %v, want none", record.Experiments)
+	}
+
+	if _, err := application.resolveRunRequest(ctx, execution.RunRequest{
+		ProjectPath: projectDir,
+		Source:      "package main\nfunc main() {}\n",
+		Experiments: []string{"bogus"},
+	}); err == nil {
+		t.Fatal("resolveRunRequest(unknown experiment) error = nil, want error")
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"gopoke/internal/execution"
+	"gopoke/internal/fspath"
+	"gopoke/internal/settings"
+)
+
+// slowFilesystemTimeoutFactor extends default and global run timeouts for
+// projects on network or slow filesystems, where go run spends longer
+// reading sources and the module cache.
+const slowFilesystemTimeoutFactor = 3
+
+// rememberProjectVolume caches the filesystem a project was opened from.
+func (a *Application) rememberProjectVolume(projectPath string, volume fspath.Volume) {
+	if volume.Kind == "" {
+		return
+	}
+	a.volumesMu.Lock()
+	defer a.volumesMu.Unlock()
+	if a.volumes == nil {
+		a.volumes = make(map[string]fspath.Volume)
+	}
+	a.volumes[projectPath] = volume
+}
+
+// projectVolume returns the cached filesystem of a project, inspecting it on
+// first use.
+func (a *Application) projectVolume(projectPath string) fspath.Volume {
+	a.volumesMu.Lock()
+	volume, ok := a.volumes[projectPath]
+	a.volumesMu.Unlock()
+	if ok {
+		return volume
+	}
+	volume = fspath.Inspect(projectPath)
+	a.rememberProjectVolume(projectPath, volume)
+	return volume
+}
+
+// adjustLimitsForFilesystem extends a default or global timeout for projects
+// on slow filesystems. Project and request timeouts are explicit choices and
+// are left alone.
+func (a *Application) adjustLimitsForFilesystem(projectPath string, limits execution.RunLimits) execution.RunLimits {
+	if limits.TimeoutSource != execution.LimitSourceDefault && limits.TimeoutSource != execution.LimitSourceGlobal {
+		return limits
+	}
+	volume := a.projectVolume(projectPath)
+	if !volume.Slow {
+		return limits
+	}
+	limits.TimeoutMS = min(limits.TimeoutMS*slowFilesystemTimeoutFactor, settings.MaxTimeoutMS)
+	limits.SlowFilesystem = true
+	a.logger.Info("extended run timeout for slow filesystem",
+		"projectPath", projectPath, "kind", volume.Kind, "probeMs", volume.ProbeMS, "timeoutMs", limits.TimeoutMS)
+	return limits
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/fspath"
+	"gopoke/internal/settings"
+)
+
+func TestOpenProjectThroughSymlinkUsesCanonicalRecord(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := canonicalPath(t, t.TempDir())
+	setupRunnableProject(t, projectDir)
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(projectDir, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	viaLink, err := application.OpenProject(ctx, link)
+	if err != nil {
+		t.Fatalf("OpenProject(link) error = %v", err)
+	}
+	direct, err := application.OpenProject(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject(target) error = %v", err)
+	}
+	if viaLink.Project.ID != direct.Project.ID || viaLink.Project.Path != projectDir {
+		t.Fatalf("project via link = %+v, direct = %+v", viaLink.Project, direct.Project)
+	}
+	if viaLink.Filesystem.Kind == "" {
+		t.Fatal("OpenProject().Filesystem.Kind is empty")
+	}
+	recent, err := application.RecentProjects(ctx, 10)
+	if err != nil {
+		t.Fatalf("RecentProjects() error = %v", err)
+	}
+	if len(recent) != 1 {
+		t.Fatalf("RecentProjects() = %+v, want one record", recent)
+	}
+}
+
+func TestSlowFilesystemExtendsDefaultTimeoutOnly(t *testing.T) {
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	application.rememberProjectVolume(projectDir, fspath.Volume{Kind: fspath.KindNetwork, Slow: true})
+
+	global := execution.RunLimits{TimeoutMS: 20000, TimeoutSource: execution.LimitSourceGlobal}
+	got := application.adjustLimitsForFilesystem(projectDir, global)
+	if got.TimeoutMS != 60000 || !got.SlowFilesystem {
+		t.Fatalf("adjusted global limits = %+v", got)
+	}
+
+	capped := execution.RunLimits{TimeoutMS: settings.MaxTimeoutMS, TimeoutSource: execution.LimitSourceGlobal}
+	if got := application.adjustLimitsForFilesystem(projectDir, capped); got.TimeoutMS != settings.MaxTimeoutMS {
+		t.Fatalf("adjusted capped limits = %+v", got)
+	}
+
+	explicit := execution.RunLimits{TimeoutMS: 5000, TimeoutSource: execution.LimitSourceProject}
+	if got := application.adjustLimitsForFilesystem(projectDir, explicit); got != explicit {
+		t.Fatalf("adjusted project limits = %+v, want unchanged", got)
+	}
+
+	localDir := t.TempDir()
+	application.rememberProjectVolume(localDir, fspath.Volume{Kind: fspath.KindLocal})
+	if got := application.adjustLimitsForFilesystem(localDir, global); got != global {
+		t.Fatalf("adjusted local limits = %+v, want unchanged", got)
+	}
+}
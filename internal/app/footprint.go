@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/execution"
+)
+
+// Footprint categories reported by ProjectFootprint.
+const (
+	FootprintRunCache   = "run_cache"
+	FootprintArtifacts  = "artifacts"
+	FootprintBackups    = "backups"
+	FootprintRunHistory = "run_history"
+	FootprintSavedData  = "saved_data"
+)
+
+// FootprintCategory is disk usage for one kind of project data.
+type FootprintCategory struct {
+	ID        string `json:"id"`
+	Path      string `json:"path,omitempty"`
+	Bytes     int64  `json:"bytes"`
+	Items     int    `json:"items"`
+	Cleanable bool   `json:"cleanable"`
+}
+
+// ProjectFootprint reports how much space gopoke uses on behalf of a project.
+type ProjectFootprint struct {
+	ProjectPath string              `json:"projectPath"`
+	TotalBytes  int64               `json:"totalBytes"`
+	Categories  []FootprintCategory `json:"categories"`
+}
+
+// ProjectFootprint measures the run cache, artifacts, backups and storage
+// records associated with a project.
+func (a *Application) ProjectFootprint(ctx context.Context, projectPath string) (ProjectFootprint, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectFootprint{}, fmt.Errorf("project footprint context: %w", err)
+	}
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return ProjectFootprint{}, err
+	}
+
+	categories := make([]FootprintCategory, 0, 5)
+	for _, category := range []FootprintCategory{
+		{ID: FootprintRunCache, Path: a.projectRunCacheDir(projectRecord)},
+		{ID: FootprintArtifacts, Path: projectDataDir(a.artifactsDir, projectRecord.ID)},
+		{ID: FootprintBackups, Path: projectDataDir(a.backupsDir, projectRecord.ID)},
+	} {
+		if category.Path == "" {
+			continue
+		}
+		bytes, files, err := directoryUsage(category.Path)
+		if err != nil {
+			return ProjectFootprint{}, fmt.Errorf("measure %s: %w", category.ID, err)
+		}
+		category.Bytes = bytes
+		category.Items = files
+		category.Cleanable = true
+		categories = append(categories, category)
+	}
+
+	usage, err := a.store.ProjectRecordUsage(ctx, projectRecord.ID)
+	if err != nil {
+		return ProjectFootprint{}, fmt.Errorf("measure project records: %w", err)
+	}
+	categories = append(categories,
+		FootprintCategory{ID: FootprintRunHistory, Path: a.store.Path(), Bytes: usage.RunBytes, Items: usage.Runs, Cleanable: true},
+		// Snippets and env vars are user data; they are reported but never bulk-deleted.
+		FootprintCategory{ID: FootprintSavedData, Path: a.store.Path(), Bytes: usage.SavedBytes, Items: usage.Snippets + usage.EnvVars},
+	)
+
+	footprint := ProjectFootprint{ProjectPath: projectRecord.Path, Categories: categories}
+	for _, category := range categories {
+		footprint.TotalBytes += category.Bytes
+	}
+	return footprint, nil
+}
+
+// CleanProjectFootprint reclaims space for one footprint category and returns
+// the updated report.
+func (a *Application) CleanProjectFootprint(ctx context.Context, projectPath string, category string) (_ ProjectFootprint, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionCleanFootprint, projectPath, map[string]string{"category": category}, err)
+	}()
+	if err := ctx.Err(); err != nil {
+		return ProjectFootprint{}, fmt.Errorf("clean project footprint context: %w", err)
+	}
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return ProjectFootprint{}, err
+	}
+
+	switch strings.TrimSpace(category) {
+	case FootprintRunCache:
+		if !a.snippetCache.useMu.TryLock() {
+			return ProjectFootprint{}, fmt.Errorf("cannot clear run cache while a run is active")
+		}
+		defer a.snippetCache.useMu.Unlock()
+		for _, dir := range []string{filepath.Join(projectRecord.Path, execution.RunCacheDirName), projectDataDir(a.snippetCache.root, projectRecord.ID)} {
+			if err := removeProjectDataDir(dir); err != nil {
+				return ProjectFootprint{}, fmt.Errorf("clear run cache: %w", err)
+			}
+		}
+	case FootprintArtifacts:
+		if err := removeProjectDataDir(projectDataDir(a.artifactsDir, projectRecord.ID)); err != nil {
+			return ProjectFootprint{}, fmt.Errorf("clear artifacts: %w", err)
+		}
+	case FootprintBackups:
+		if err := removeProjectDataDir(projectDataDir(a.backupsDir, projectRecord.ID)); err != nil {
+			return ProjectFootprint{}, fmt.Errorf("clear backups: %w", err)
+		}
+	case FootprintRunHistory:
+		if _, err := a.store.DeleteProjectRuns(ctx, projectRecord.ID); err != nil {
+			return ProjectFootprint{}, fmt.Errorf("clear run history: %w", err)
+		}
+	case FootprintSavedData:
+		return ProjectFootprint{}, fmt.Errorf("saved snippets and environment variables must be deleted individually")
+	default:
+		return ProjectFootprint{}, fmt.Errorf("unknown footprint category %q", category)
+	}
+
+	a.logger.Info("project footprint cleaned", "project", projectRecord.Path, "category", category)
+	return a.ProjectFootprint(ctx, projectRecord.Path)
+}
+
+// projectDataDir returns the per-project subdirectory of an app data root.
+func projectDataDir(root string, projectID string) string {
+	if strings.TrimSpace(root) == "" || strings.TrimSpace(projectID) == "" {
+		return ""
+	}
+	return filepath.Join(root, projectID)
+}
+
+func (a *Application) hasActiveRuns() bool {
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+	return len(a.activeRuns) > 0
+}
+
+func removeProjectDataDir(path string) error {
+	if path == "" {
+		return nil
+	}
+	return os.RemoveAll(path)
+}
+
+// directoryUsage sums regular file sizes under root. A missing root is empty.
+func directoryUsage(root string) (int64, int, error) {
+	var total int64
+	files := 0
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		total += info.Size()
+		files++
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return total, files, nil
+}
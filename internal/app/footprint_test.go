@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/storage"
+)
+
+func TestProjectFootprintReportsAndCleansCategories(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.artifactsDir = t.TempDir()
+	application.backupsDir = t.TempDir()
+	projectRoot := t.TempDir()
+	setupRunnableProject(t, projectRoot)
+
+	opened, err := application.OpenProject(ctx, projectRoot)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	projectID := opened.Project.ID
+	writeTestFile(t, filepath.Join(projectRoot, execution.RunCacheDirName, "snippet-a.go"), "package main\n")
+	writeTestFile(t, filepath.Join(application.artifactsDir, projectID, "stdout.log"), "0123456789")
+	if _, err := application.store.RecordRun(ctx, storage.RunRecord{ProjectID: projectID, Status: runStatusSuccess, StartedAt: time.Now()}); err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	if _, err := application.SaveProjectSnippet(ctx, projectRoot, "", "keep", "package main"); err != nil {
+		t.Fatalf("SaveProjectSnippet() error = %v", err)
+	}
+
+	footprint, err := application.ProjectFootprint(ctx, projectRoot)
+	if err != nil {
+		t.Fatalf("ProjectFootprint() error = %v", err)
+	}
+	byID := footprintByID(footprint)
+	if got, want := byID[FootprintRunCache].Bytes, int64(len("package main\n")); got != want {
+		t.Fatalf("run cache bytes = %d, want %d", got, want)
+	}
+	if got, want := byID[FootprintArtifacts].Bytes, int64(10); got != want {
+		t.Fatalf("artifacts bytes = %d, want %d", got, want)
+	}
+	if got, want := byID[FootprintBackups].Items, 0; got != want {
+		t.Fatalf("backups items = %d, want %d", got, want)
+	}
+	if byID[FootprintRunHistory].Items != 1 || byID[FootprintSavedData].Items != 1 {
+		t.Fatalf("record categories = %+v / %+v", byID[FootprintRunHistory], byID[FootprintSavedData])
+	}
+
+	for _, category := range []string{FootprintRunCache, FootprintArtifacts, FootprintRunHistory} {
+		footprint, err = application.CleanProjectFootprint(ctx, projectRoot, category)
+		if err != nil {
+			t.Fatalf("CleanProjectFootprint(%s) error = %v", category, err)
+		}
+	}
+	byID = footprintByID(footprint)
+	if footprint.TotalBytes != byID[FootprintSavedData].Bytes {
+		t.Fatalf("TotalBytes = %d, want only saved data %d", footprint.TotalBytes, byID[FootprintSavedData].Bytes)
+	}
+	if _, err := os.Stat(filepath.Join(projectRoot, "main.go")); err != nil {
+		t.Fatalf("project source removed: %v", err)
+	}
+	if _, err := application.CleanProjectFootprint(ctx, projectRoot, FootprintSavedData); err == nil {
+		t.Fatal("CleanProjectFootprint(saved_data) error = nil, want refusal")
+	}
+	if _, err := application.CleanProjectFootprint(ctx, projectRoot, "everything"); err == nil {
+		t.Fatal("CleanProjectFootprint(unknown) error = nil, want error")
+	}
+}
+
+func footprintByID(footprint ProjectFootprint) map[string]FootprintCategory {
+	byID := make(map[string]FootprintCategory, len(footprint.Categories))
+	for _, category := range footprint.Categories {
+		byID[category.ID] = category
+	}
+	return byID
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/storage"
+)
+
+// ForgetProject removes a project from gopoke: its record, snippets, run
+// history, env vars, activity and experiments go in one storage update, so
+// a failure leaves all of them in place. Its worker is stopped and its
+// artifacts, backups and snippet sync state are deleted afterwards. The
+// project's files are not touched.
+func (a *Application) ForgetProject(ctx context.Context, projectPath string) (_ storage.ForgottenProject, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionForgetProject, projectPath, nil, err)
+	}()
+	if err := ctx.Err(); err != nil {
+		return storage.ForgottenProject{}, fmt.Errorf("forget project context: %w", err)
+	}
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ForgottenProject{}, err
+	}
+	if a.hasActiveRuns() {
+		return storage.ForgottenProject{}, fmt.Errorf("cannot forget a project while a run is active")
+	}
+
+	var forgotten storage.ForgottenProject
+	err = a.store.Update(ctx, func(tx *storage.Tx) error {
+		var err error
+		forgotten, err = tx.ForgetProject(projectRecord.ID)
+		return err
+	})
+	if err != nil {
+		return storage.ForgottenProject{}, fmt.Errorf("forget project: %w", err)
+	}
+
+	if a.workers != nil && a.workers.IsRunning(projectRecord.Path) {
+		if err := a.workers.StopWorker(ctx, projectRecord.Path); err != nil {
+			a.logger.Warn("stop forgotten project worker failed", "projectPath", projectRecord.Path, "error", err)
+		}
+	}
+	for _, dir := range []string{
+		projectDataDir(a.artifactsDir, projectRecord.ID),
+		projectDataDir(a.backupsDir, projectRecord.ID),
+		projectDataDir(a.snippetCache.root, projectRecord.ID),
+	} {
+		if err := removeProjectDataDir(dir); err != nil {
+			a.logger.Warn("remove forgotten project data failed", "path", dir, "error", err)
+		}
+	}
+	if a.snippetSyncDir != "" {
+		if err := os.Remove(a.snippetSyncStatePath(projectRecord.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			a.logger.Warn("remove forgotten project snippet sync state failed", "projectPath", projectRecord.Path, "error", err)
+		}
+	}
+	a.logger.Info("forgot project", "projectPath", projectRecord.Path, "snippets", forgotten.Snippets, "runs", forgotten.Runs)
+	return forgotten, nil
+}
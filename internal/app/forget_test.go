@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestForgetProjectRemovesStoredRecordsAndData(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.artifactsDir = t.TempDir()
+
+	projectPath := t.TempDir()
+	writeTestFile(t, filepath.Join(projectPath, "go.mod"), "module example.com/forget\n\ngo 1.25\n")
+	if _, err := application.OpenProject(ctx, projectPath); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	snippet, err := application.SaveProjectSnippet(ctx, projectPath, "", "main", "package main\n")
+	if err != nil {
+		t.Fatalf("SaveProjectSnippet() error = %v", err)
+	}
+	record, err := application.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		t.Fatalf("projectRecordByPath() error = %v", err)
+	}
+	artifacts := projectDataDir(application.artifactsDir, record.ID)
+	writeTestFile(t, filepath.Join(artifacts, "out.txt"), "artifact")
+
+	if err := application.registerActiveRun("run-1", func() {}); err != nil {
+		t.Fatalf("registerActiveRun() error = %v", err)
+	}
+	if _, err := application.ForgetProject(ctx, projectPath); err == nil {
+		t.Fatal("ForgetProject() during a run error = nil")
+	}
+	application.unregisterActiveRun("run-1")
+
+	forgotten, err := application.ForgetProject(ctx, projectPath)
+	if err != nil {
+		t.Fatalf("ForgetProject() error = %v", err)
+	}
+	if forgotten.Project.ID != record.ID || forgotten.Snippets != 1 {
+		t.Fatalf("ForgetProject() = %+v, want project %s with 1 snippet", forgotten, record.ID)
+	}
+	if _, found, _ := application.store.ProjectByPath(ctx, projectPath); found {
+		t.Fatal("project still stored after ForgetProject")
+	}
+	if _, found, _ := application.store.SnippetByID(ctx, snippet.ID); found {
+		t.Fatal("snippet still stored after ForgetProject")
+	}
+	if _, err := os.Stat(artifacts); !os.IsNotExist(err) {
+		t.Fatalf("artifacts after ForgetProject: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "go.mod")); err != nil {
+		t.Fatalf("project files after ForgetProject: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/golden"
+	"gopoke/internal/storage"
+)
+
+// checkGolden compares a finished golden run with its snippet's snapshot.
+// The first such run records the snapshot; a differing one is kept as the
+// received output until it is approved or rejected. Runs that were canceled
+// or timed out are not compared.
+func (a *Application) checkGolden(ctx context.Context, request execution.RunRequest, result *execution.Result) {
+	if !request.Golden || result.Canceled || result.TimedOut {
+		return
+	}
+	snippet, err := a.goldenSnippet(ctx, request.ProjectPath, request.SnippetID)
+	if err != nil {
+		a.logger.Warn("golden check failed", "runID", request.RunID, "snippetID", request.SnippetID, "error", err)
+		return
+	}
+	output := &storage.GoldenOutput{Stdout: result.Stdout, ExitCode: result.ExitCode, RecordedAt: a.clock()}
+	if snippet.Golden == nil {
+		if _, err := a.store.UpdateSnippetGolden(ctx, snippet.ID, output, nil); err != nil {
+			a.logger.Warn("record golden output failed", "runID", request.RunID, "snippetID", snippet.ID, "error", err)
+			return
+		}
+		result.Golden = &golden.Check{Status: golden.StatusRecorded, GoldenExitCode: output.ExitCode}
+		return
+	}
+
+	check := golden.Compare(snippet.Golden.Stdout, snippet.Golden.ExitCode, output.Stdout, output.ExitCode)
+	var received *storage.GoldenOutput
+	if check.Status == golden.StatusChanged {
+		received = output
+	}
+	if received != nil || snippet.Received != nil {
+		if _, err := a.store.UpdateSnippetGolden(ctx, snippet.ID, snippet.Golden, received); err != nil {
+			a.logger.Warn("record received output failed", "runID", request.RunID, "snippetID", snippet.ID, "error", err)
+		}
+	}
+	result.Golden = &check
+}
+
+// ApproveGoldenOutput makes a snippet's received output its golden
+// snapshot.
+func (a *Application) ApproveGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("approve golden output context: %w", err)
+	}
+	snippet, err := a.goldenSnippet(ctx, projectPath, snippetID)
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	if snippet.Received == nil {
+		return storage.SnippetRecord{}, fmt.Errorf("snippet %q has no output awaiting approval", snippet.Name)
+	}
+	updated, err := a.store.UpdateSnippetGolden(ctx, snippet.ID, snippet.Received, nil)
+	if err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("approve golden output: %w", err)
+	}
+	return updated, nil
+}
+
+// RejectGoldenOutput discards a snippet's received output, keeping its
+// golden snapshot.
+func (a *Application) RejectGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("reject golden output context: %w", err)
+	}
+	snippet, err := a.goldenSnippet(ctx, projectPath, snippetID)
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	if snippet.Received == nil {
+		return snippet, nil
+	}
+	updated, err := a.store.UpdateSnippetGolden(ctx, snippet.ID, snippet.Golden, nil)
+	if err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("reject golden output: %w", err)
+	}
+	return updated, nil
+}
+
+// ResetGoldenOutput forgets a snippet's golden snapshot, so its next golden
+// run records a new one.
+func (a *Application) ResetGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("reset golden output context: %w", err)
+	}
+	snippet, err := a.goldenSnippet(ctx, projectPath, snippetID)
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	updated, err := a.store.UpdateSnippetGolden(ctx, snippet.ID, nil, nil)
+	if err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("reset golden output: %w", err)
+	}
+	return updated, nil
+}
+
+// goldenSnippet loads a saved snippet of the project at projectPath.
+func (a *Application) goldenSnippet(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	snippetID = strings.TrimSpace(snippetID)
+	if snippetID == "" {
+		return storage.SnippetRecord{}, fmt.Errorf("snippet ID is required")
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	snippet, found, err := a.store.SnippetByID(ctx, snippetID)
+	if err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("load snippet: %w", err)
+	}
+	if !found || snippet.ProjectID != record.ID {
+		return storage.SnippetRecord{}, fmt.Errorf("snippet not found")
+	}
+	return snippet, nil
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/golden"
+)
+
+func TestGoldenRunsRecordCompareAndApprove(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	original := "package main\n\n//stdout: total 3\nfunc main() {}\n"
+	snippet, err := application.SaveProjectSnippet(context.Background(), projectDir, "", "Totals", original)
+	if err != nil {
+		t.Fatalf("SaveProjectSnippet() error = %v", err)
+	}
+	run := func(source string) execution.Result {
+		t.Helper()
+		result, err := application.RunSnippet(context.Background(), execution.RunRequest{
+			ProjectPath: projectDir,
+			Source:      source,
+			SnippetID:   snippet.ID,
+			Golden:      true,
+		}, nil, nil)
+		if err != nil {
+			t.Fatalf("RunSnippet() error = %v", err)
+		}
+		if result.Golden == nil {
+			t.Fatal("RunSnippet() Golden = nil")
+		}
+		return result
+	}
+
+	if got := run(original).Golden.Status; got != golden.StatusRecorded {
+		t.Fatalf("first run status = %q, want recorded", got)
+	}
+	if got := run(original).Golden.Status; got != golden.StatusMatched {
+		t.Fatalf("second run status = %q, want matched", got)
+	}
+
+	changed := "package main\n\n//stdout: total 4\nfunc main() {}\n"
+	check := run(changed).Golden
+	if check.Status != golden.StatusChanged || len(check.Diff) != 2 || check.Diff[0].Text != "total 3" || check.Diff[1].Op != golden.OpAdded {
+		t.Fatalf("changed run check = %+v", check)
+	}
+
+	rejected, err := application.RejectGoldenOutput(context.Background(), projectDir, snippet.ID)
+	if err != nil {
+		t.Fatalf("RejectGoldenOutput() error = %v", err)
+	}
+	if rejected.Received != nil || rejected.Golden.Stdout != "total 3\n" {
+		t.Fatalf("after reject golden = %+v, received = %+v", rejected.Golden, rejected.Received)
+	}
+	if _, err := application.ApproveGoldenOutput(context.Background(), projectDir, snippet.ID); err == nil {
+		t.Fatal("ApproveGoldenOutput() with nothing received error = nil")
+	}
+
+	run(changed)
+	approved, err := application.ApproveGoldenOutput(context.Background(), projectDir, snippet.ID)
+	if err != nil {
+		t.Fatalf("ApproveGoldenOutput() error = %v", err)
+	}
+	if approved.Received != nil || approved.Golden.Stdout != "total 4\n" {
+		t.Fatalf("after approve golden = %+v, received = %+v", approved.Golden, approved.Received)
+	}
+	if got := run(changed).Golden.Status; got != golden.StatusMatched {
+		t.Fatalf("run after approve status = %q, want matched", got)
+	}
+
+	if _, err := application.RunSnippet(context.Background(), execution.RunRequest{ProjectPath: projectDir, Source: original, Golden: true}, nil, nil); err == nil {
+		t.Fatal("RunSnippet() golden without snippet error = nil")
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestRunSnippetInGOPATHProject(t *testing.T) {
+	t.Parallel()
+	requireGoToolchain(t)
+
+	application := newTestApplication(t)
+	gopath := t.TempDir()
+	projectDir := filepath.Join(gopath, "src", "example.com", "legacy")
+	writeTestFile(t, filepath.Join(projectDir, "greet", "greet.go"), "package greet\n\nfunc Hello() string { return \"hello from GOPATH\" }\n")
+	writeTestFile(t, filepath.Join(projectDir, "main.go"), "package main\n\nfunc main() {}\n")
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	result, err := application.RunSnippet(context.Background(), execution.RunRequest{
+		ProjectPath: projectDir,
+		Source:      "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/legacy/greet\"\n)\n\nfunc main() { fmt.Println(greet.Hello()) }\n",
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.ExitCode != 0 || strings.TrimSpace(result.Stdout) != "hello from GOPATH" {
+		t.Fatalf("RunSnippet() exit = %d, stdout = %q, stderr = %q", result.ExitCode, result.Stdout, result.Stderr)
+	}
+}
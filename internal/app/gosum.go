@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+)
+
+// sumCheckTimeout bounds go.sum verification so a slow module proxy delays
+// a run by at most this long.
+const sumCheckTimeout = 30 * time.Second
+
+// sumCheckFiles are the module files whose contents decide whether an
+// earlier go.sum verification still holds.
+var sumCheckFiles = []string{"go.mod", "go.sum", "go.work", "go.work.sum"}
+
+// sumCheckCache remembers the go.sum verification of each project until its
+// module files change.
+type sumCheckCache struct {
+	mu      sync.Mutex
+	entries map[string]sumCheckEntry
+}
+
+type sumCheckEntry struct {
+	digest  string
+	warning *project.SumWarning
+}
+
+func (c *sumCheckCache) get(projectID string, digest string) (*project.SumWarning, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[projectID]
+	if !ok || entry.digest != digest {
+		return nil, false
+	}
+	return entry.warning, true
+}
+
+func (c *sumCheckCache) put(projectID string, digest string, warning *project.SumWarning) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]sumCheckEntry)
+	}
+	c.entries[projectID] = sumCheckEntry{digest: digest, warning: warning}
+}
+
+func (c *sumCheckCache) forget(projectID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, projectID)
+}
+
+// checkModuleSums verifies go.sum before a project run and returns a warning
+// when the build would stop on a checksum problem. It runs again only after
+// the module files change. Scratch runs, backends other than Go and failed
+// checks never hold a run back.
+func (a *Application) checkModuleSums(ctx context.Context, resolved resolvedRunRequest) *project.SumWarning {
+	if resolved.projectID == "" || a.executionBackend().Name() != execution.BackendGo {
+		return nil
+	}
+	digest, ok := moduleFilesDigest(resolved.projectPath)
+	if !ok {
+		return nil
+	}
+	if warning, ok := a.sumChecks.get(resolved.projectID, digest); ok {
+		return warning
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, sumCheckTimeout)
+	defer cancel()
+	warning, err := project.VerifySums(checkCtx, resolved.toolchain, resolved.projectPath, resolved.environment)
+	if err != nil {
+		a.logger.Warn("verify go.sum failed", "project", resolved.projectPath, "error", err)
+		return nil
+	}
+	a.sumChecks.put(resolved.projectID, digest, warning)
+	return warning
+}
+
+// moduleFilesDigest hashes the module files of projectPath. It reports false
+// when the project has no go.mod.
+func moduleFilesDigest(projectPath string) (string, bool) {
+	hash := sha256.New()
+	for _, name := range sumCheckFiles {
+		data, err := os.ReadFile(filepath.Join(projectPath, name))
+		if err != nil {
+			if name == "go.mod" {
+				return "", false
+			}
+			continue
+		}
+		fmt.Fprintf(hash, "%s %d\n", name, len(data))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), true
+}
+
+// TidyProjectModules runs go mod tidy in a project with its toolchain and
+// environment, the remediation offered with a run's ModuleWarning, and
+// returns the command output.
+func (a *Application) TidyProjectModules(ctx context.Context, projectPath string) (_ string, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionGoModTidy, projectPath, nil, err)
+	}()
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("tidy project modules context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return "", err
+	}
+	toolchainName := record.Toolchain
+	if toolchainName == "" {
+		toolchainName = "go"
+	}
+	toolchain, err := project.ResolveToolchainBinary(toolchainName)
+	if err != nil {
+		return "", fmt.Errorf("resolve project toolchain: %w", err)
+	}
+	environment, err := a.store.ProjectEnvMap(ctx, record.ID)
+	if err != nil {
+		return "", fmt.Errorf("load project env: %w", err)
+	}
+	output, err := project.TidyModules(ctx, toolchain, record.Path, environment)
+	a.sumChecks.forget(record.ID)
+	return output, err
+}
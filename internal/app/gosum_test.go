@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+)
+
+func TestRunSnippetHoldsRunOnGoSumProblems(t *testing.T) {
+	requireGoToolchain(t)
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	writeTestFile(t, filepath.Join(projectDir, "go.mod"), "module example.com/sums\n\ngo 1.22\n\nrequire example.com/nope v1.0.0\n")
+	writeTestFile(t, filepath.Join(projectDir, "main.go"), "package main\n\nimport _ \"example.com/nope\"\n\nfunc main() {}\n")
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	for key, value := range map[string]string{"GOPROXY": "off", "GOFLAGS": "", "GOWORK": "off"} {
+		if _, err := application.UpsertProjectEnvVar(ctx, projectDir, key, value, false); err != nil {
+			t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+		}
+	}
+
+	source := "package main\n\nfunc main() {}\n"
+	result, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if !result.ConfirmationRequired || result.ModuleWarning == nil || result.ModuleWarning.Problems[0].Kind != project.SumMissingEntry {
+		t.Fatalf("result = %+v, want the run held on a missing go.sum entry", result)
+	}
+
+	result, err = application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source, IgnoreModuleWarning: true}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet(ignore) error = %v", err)
+	}
+	if result.ConfirmationRequired || result.ModuleWarning != nil || result.ExitCode != 0 {
+		t.Fatalf("result = %+v, want the snippet to run", result)
+	}
+
+	// With the import gone the module files change and verification passes.
+	writeTestFile(t, filepath.Join(projectDir, "main.go"), "package main\n\nfunc main() {}\n")
+	if _, err := application.TidyProjectModules(ctx, projectDir); err != nil {
+		t.Fatalf("TidyProjectModules() error = %v", err)
+	}
+	result, err = application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() after tidy error = %v", err)
+	}
+	if result.ConfirmationRequired || result.ExitCode != 0 {
+		t.Fatalf("result after tidy = %+v, want a normal run", result)
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/highlight"
+	"gopoke/internal/storage"
+)
+
+// SetProjectHighlights replaces the rules that highlight a project's run
+// output. Rules without an ID get one.
+func (a *Application) SetProjectHighlights(ctx context.Context, projectPath string, rules []storage.HighlightRule) (storage.ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project highlights context: %w", err)
+	}
+	normalized, err := highlight.Normalize(rules)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	updated, err := a.store.UpdateProjectHighlights(ctx, record.Path, normalized)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project highlights: %w", err)
+	}
+	return updated, nil
+}
+
+// OutputHighlighter returns the compiled highlight rules of a project for
+// annotating streamed output. Scratch runs and projects without rules get a
+// nil highlighter, which marks nothing.
+func (a *Application) OutputHighlighter(ctx context.Context, projectPath string) (*highlight.Highlighter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("output highlighter context: %w", err)
+	}
+	if strings.TrimSpace(projectPath) == "" {
+		return nil, nil
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	return highlight.Compile(record.Highlights)
+}
+
+// highlightResult marks a finished run's output by the project's rules.
+// Plain-text results stay unmarked so they read exactly as printed.
+func (a *Application) highlightResult(result *execution.Result, rules []storage.HighlightRule) {
+	result.StdoutHighlights = nil
+	result.StderrHighlights = nil
+	if result.PlainText || len(rules) == 0 {
+		return
+	}
+	highlighter, err := highlight.Compile(rules)
+	if err != nil {
+		a.logger.Warn("compile output highlights failed", "error", err)
+		return
+	}
+	result.StdoutHighlights = highlighter.Spans(result.CleanStdout)
+	result.StderrHighlights = highlighter.Spans(result.Stderr)
+}
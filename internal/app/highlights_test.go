@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/storage"
+)
+
+func TestRunSnippetHighlightsOutputByProjectRules(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	if _, err := application.SetProjectHighlights(ctx, projectDir, []storage.HighlightRule{{Pattern: `(`}}); err == nil {
+		t.Fatal("SetProjectHighlights(invalid pattern) error = nil, want an error")
+	}
+	record, err := application.SetProjectHighlights(ctx, projectDir, []storage.HighlightRule{
+		{Pattern: `req-[0-9]+`, Color: "blue", Label: "request"},
+		{Pattern: `^ERROR`, Color: "red", Collapse: true},
+	})
+	if err != nil {
+		t.Fatalf("SetProjectHighlights() error = %v", err)
+	}
+	if len(record.Highlights) != 2 || record.Highlights[0].ID == "" {
+		t.Fatalf("Highlights = %+v, want two rules with IDs", record.Highlights)
+	}
+	highlighter, err := application.OutputHighlighter(ctx, projectDir)
+	if err != nil || highlighter == nil {
+		t.Fatalf("OutputHighlighter() = %v, %v, want a highlighter", highlighter, err)
+	}
+
+	source := "package main\n\n//stdout: handled req-42\n//stderr: ERROR boom\nfunc main() {}\n"
+	result, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if len(result.StdoutHighlights) != 1 {
+		t.Fatalf("StdoutHighlights = %+v, want one span", result.StdoutHighlights)
+	}
+	span := result.StdoutHighlights[0]
+	if got := result.CleanStdout[span.Start:span.End]; got != "req-42" || span.Label != "request" {
+		t.Fatalf("stdout span %+v covers %q, want the request ID", span, got)
+	}
+	if len(result.StderrHighlights) != 1 || !result.StderrHighlights[0].Collapse {
+		t.Fatalf("StderrHighlights = %+v, want one collapsed line", result.StderrHighlights)
+	}
+
+	if _, err := application.SetProjectHighlights(ctx, projectDir, nil); err != nil {
+		t.Fatalf("SetProjectHighlights(nil) error = %v", err)
+	}
+	result, err = application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.StdoutHighlights != nil || result.StderrHighlights != nil {
+		t.Fatalf("result = %+v, want no highlights once rules are cleared", result)
+	}
+}
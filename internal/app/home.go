@@ -0,0 +1,179 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gopoke/internal/storage"
+	"gopoke/internal/update"
+)
+
+// homeRecentLimit is how many recent projects the home screen lists.
+const homeRecentLimit = 10
+
+// Home screen notification kinds.
+const (
+	HomeNotificationUpdate     = "update"
+	HomeNotificationCapability = "capability"
+)
+
+// HomeScreenData is everything the home screen shows, gathered in one call.
+type HomeScreenData struct {
+	Recent []storage.ProjectRecord `json:"recent"`
+	Pinned []storage.ProjectRecord `json:"pinned"`
+	// LastSession is the most recently opened project; nil before any
+	// project was opened.
+	LastSession   *HomeLastSession   `json:"lastSession,omitempty"`
+	Notifications []HomeNotification `json:"notifications"`
+	Environment   EnvironmentSummary `json:"environment"`
+	// Warnings lists parts that could not be loaded; the rest of the data
+	// is still returned.
+	Warnings   []string `json:"warnings,omitempty"`
+	DurationMS int64    `json:"durationMs"`
+}
+
+// HomeLastSession is the project to offer resuming, with its latest run.
+type HomeLastSession struct {
+	Project storage.ProjectRecord `json:"project"`
+	LastRun *storage.RunRecord    `json:"lastRun,omitempty"`
+}
+
+// HomeNotification is one item waiting for the user's attention.
+type HomeNotification struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// EnvironmentSummary condenses tool detection and capabilities into what the
+// home screen needs to flag a broken setup.
+type EnvironmentSummary struct {
+	GoVersion string `json:"goVersion"`
+	// Ready is set when every known tool is installed at a supported
+	// version and no capability is degraded.
+	Ready    bool     `json:"ready"`
+	Missing  []string `json:"missing,omitempty"`
+	Outdated []string `json:"outdated,omitempty"`
+	Degraded bool     `json:"degraded"`
+}
+
+// HomeScreenData loads the home screen. Stored projects, the staged update
+// and tool detection, which runs each tool, are read concurrently, so the
+// call costs the slowest of them. A part that fails is reported as a
+// warning and left empty.
+func (a *Application) HomeScreenData(ctx context.Context) (HomeScreenData, error) {
+	if err := ctx.Err(); err != nil {
+		return HomeScreenData{}, fmt.Errorf("home screen data context: %w", err)
+	}
+	started := a.clock()
+
+	home := HomeScreenData{
+		Recent:        []storage.ProjectRecord{},
+		Pinned:        []storage.ProjectRecord{},
+		Notifications: []HomeNotification{},
+	}
+	var projectsErr, updateErr error
+	var staged *update.StagedUpdate
+	var tools ToolVersions
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		projectsErr = a.loadHomeProjects(ctx, &home)
+	})
+	wg.Go(func() {
+		if a.updates != nil {
+			staged, updateErr = a.PendingUpdate(ctx)
+		}
+	})
+	wg.Go(func() {
+		tools = a.DetectToolVersions(ctx)
+	})
+	wg.Wait()
+
+	if projectsErr != nil {
+		a.logger.Warn("home screen data: load projects", "error", projectsErr)
+		home.Warnings = append(home.Warnings, fmt.Sprintf("projects: %v", projectsErr))
+	}
+	if updateErr != nil {
+		a.logger.Warn("home screen data: pending update", "error", updateErr)
+		home.Warnings = append(home.Warnings, fmt.Sprintf("update: %v", updateErr))
+	} else if staged != nil {
+		home.Notifications = append(home.Notifications, HomeNotification{
+			Kind:    HomeNotificationUpdate,
+			Message: fmt.Sprintf("gopoke %s is ready to install on restart", staged.Version),
+		})
+	}
+
+	capabilities, err := a.Capabilities(ctx)
+	if err != nil {
+		home.Warnings = append(home.Warnings, fmt.Sprintf("capabilities: %v", err))
+	}
+	for _, status := range capabilities.Capabilities {
+		if !status.Available {
+			home.Notifications = append(home.Notifications, HomeNotification{
+				Kind:    HomeNotificationCapability,
+				Message: fmt.Sprintf("%s unavailable: %s", status.Capability, status.Reason),
+			})
+		}
+	}
+
+	home.Environment = EnvironmentSummary{GoVersion: tools.GoVersion, Degraded: capabilities.Degraded}
+	for _, tool := range tools.Tools {
+		switch {
+		case !tool.Installed:
+			home.Environment.Missing = append(home.Environment.Missing, tool.Name)
+		case !tool.Supported:
+			home.Environment.Outdated = append(home.Environment.Outdated, tool.Name)
+		}
+	}
+	home.Environment.Ready = len(home.Environment.Missing) == 0 && len(home.Environment.Outdated) == 0 && !capabilities.Degraded
+
+	home.DurationMS = a.clock().Sub(started).Milliseconds()
+	return home, nil
+}
+
+// loadHomeProjects fills the recent, pinned and last session parts of home
+// from one read of the stored projects.
+func (a *Application) loadHomeProjects(ctx context.Context, home *HomeScreenData) error {
+	projects, err := a.RecentProjects(ctx, 0)
+	if err != nil {
+		return err
+	}
+	for i, record := range projects {
+		if i < homeRecentLimit {
+			home.Recent = append(home.Recent, record)
+		}
+		if record.Pinned {
+			home.Pinned = append(home.Pinned, record)
+		}
+	}
+	if len(projects) == 0 {
+		return nil
+	}
+
+	last := &HomeLastSession{Project: projects[0]}
+	runs, err := a.store.ProjectRuns(ctx, last.Project.ID, 1)
+	if err != nil {
+		return fmt.Errorf("last session runs: %w", err)
+	}
+	if len(runs) > 0 {
+		last.LastRun = &runs[0]
+	}
+	home.LastSession = last
+	return nil
+}
+
+// SetProjectPinned pins or unpins a project on the home screen.
+func (a *Application) SetProjectPinned(ctx context.Context, projectPath string, pinned bool) (storage.ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project pinned context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	updated, err := a.store.UpdateProjectPinned(ctx, record.Path, pinned)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project pinned: %w", err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/storage"
+)
+
+func TestHomeScreenDataGathersProjectsSessionAndNotifications(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	application := newTestApplication(t)
+
+	older, err := application.store.RecordProjectOpen(ctx, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen(older) error = %v", err)
+	}
+	latest, err := application.store.RecordProjectOpen(ctx, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen(latest) error = %v", err)
+	}
+	if _, err := application.SetProjectPinned(ctx, older.Path, true); err != nil {
+		t.Fatalf("SetProjectPinned() error = %v", err)
+	}
+	run, err := application.store.RecordRun(ctx, storage.RunRecord{ProjectID: latest.ID, Status: "success"})
+	if err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	application.capabilities.statuses = map[Capability]CapabilityStatus{
+		CapabilityGopls: {Capability: CapabilityGopls, Reason: "gopls not found"},
+	}
+
+	home, err := application.HomeScreenData(ctx)
+	if err != nil {
+		t.Fatalf("HomeScreenData() error = %v", err)
+	}
+	if len(home.Warnings) != 0 {
+		t.Fatalf("Warnings = %v, want none", home.Warnings)
+	}
+	if len(home.Recent) != 2 || home.Recent[0].ID != latest.ID {
+		t.Fatalf("Recent = %+v, want latest project first", home.Recent)
+	}
+	if len(home.Pinned) != 1 || home.Pinned[0].ID != older.ID {
+		t.Fatalf("Pinned = %+v, want only the pinned project", home.Pinned)
+	}
+	if home.LastSession == nil || home.LastSession.Project.ID != latest.ID ||
+		home.LastSession.LastRun == nil || home.LastSession.LastRun.ID != run.ID {
+		t.Fatalf("LastSession = %+v, want latest project with its run", home.LastSession)
+	}
+	if len(home.Notifications) != 1 || home.Notifications[0].Kind != HomeNotificationCapability {
+		t.Fatalf("Notifications = %+v, want the unavailable capability", home.Notifications)
+	}
+	if !home.Environment.Degraded || home.Environment.Ready {
+		t.Fatalf("Environment = %+v, want degraded and not ready", home.Environment)
+	}
+}
+
+func TestHomeScreenDataWithoutProjects(t *testing.T) {
+	t.Parallel()
+	application := newTestApplication(t)
+
+	home, err := application.HomeScreenData(context.Background())
+	if err != nil {
+		t.Fatalf("HomeScreenData() error = %v", err)
+	}
+	if home.LastSession != nil || len(home.Recent) != 0 || len(home.Pinned) != 0 {
+		t.Fatalf("home = %+v, want no projects or session", home)
+	}
+}
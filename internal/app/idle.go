@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gopoke/internal/idle"
+	"gopoke/internal/settings"
+)
+
+// startIdleMonitor registers the subsystems paused while the user is idle
+// and starts watching for inactivity. Editor traffic to gopls counts as
+// interaction, like calls through the bridge.
+func (a *Application) startIdleMonitor() {
+	if a.idle == nil {
+		return
+	}
+	if a.lspManager != nil {
+		a.idle.Register("gopls memory watchdog", a.lspManager)
+		a.lspManager.SetActivityHandler(a.idle.Touch)
+	}
+	a.idle.Register("telemetry export", a.telemetry)
+	a.idle.Register("power source polling", &a.power.gate)
+	a.idle.Start()
+}
+
+// applyIdlePause sets how long without interaction pauses background work.
+func (a *Application) applyIdlePause(gs settings.GlobalSettings) {
+	if a.idle == nil {
+		return
+	}
+	a.idle.SetTimeout(time.Duration(gs.IdlePauseMinutes) * time.Minute)
+}
+
+// NoteActivity records a user interaction, resuming paused background work
+// before it returns.
+func (a *Application) NoteActivity() {
+	if a.idle != nil {
+		a.idle.Touch()
+	}
+}
+
+// IdleStatus reports whether background work is paused for inactivity.
+func (a *Application) IdleStatus(ctx context.Context) (idle.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return idle.Status{}, fmt.Errorf("idle status context: %w", err)
+	}
+	if a.idle == nil {
+		return idle.Status{Subsystems: []string{}}, nil
+	}
+	return a.idle.Status(), nil
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopoke/internal/idle"
+	"gopoke/internal/settings"
+)
+
+func TestIdleMonitorPausesTelemetryUntilActivity(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.idle = idle.NewMonitor(application.logger)
+	application.startIdleMonitor()
+	defer application.idle.Stop()
+
+	gs := settings.Defaults()
+	gs.IdlePauseMinutes = settings.IdlePauseNever
+	application.applyIdlePause(gs)
+	if status, _ := application.IdleStatus(context.Background()); status.Timeout != 0 {
+		t.Fatalf("Timeout = %v with pausing off, want 0", status.Timeout)
+	}
+
+	application.idle.SetTimeout(20 * time.Millisecond)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, err := application.IdleStatus(context.Background())
+		if err != nil {
+			t.Fatalf("IdleStatus() error = %v", err)
+		}
+		if status.Idle {
+			if !reflect.DeepEqual(status.Subsystems, []string{"telemetry export", "power source polling"}) {
+				t.Fatalf("Subsystems = %v", status.Subsystems)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("application did not go idle")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	application.NoteActivity()
+	if status, _ := application.IdleStatus(context.Background()); status.Idle {
+		t.Fatal("IdleStatus().Idle = true after NoteActivity")
+	}
+}
@@ -0,0 +1,287 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+)
+
+// maskedEnvValue stands in for masked project variables in reports.
+const maskedEnvValue = "********"
+
+// IsolationRun is one side of an isolation check.
+type IsolationRun struct {
+	Result execution.Result `json:"result"`
+	// Error is set when the run could not start; Result is then empty.
+	Error string `json:"error,omitempty"`
+}
+
+// IsolationEnvDiff is a variable gopoke sets differently for the two runs.
+// Variables both runs inherit from gopoke's own environment are not
+// compared.
+type IsolationEnvDiff struct {
+	Key       string `json:"key"`
+	Project   string `json:"project,omitempty"`
+	Scratch   string `json:"scratch,omitempty"`
+	InProject bool   `json:"inProject"`
+	InScratch bool   `json:"inScratch"`
+}
+
+// IsolationModule is the module an import resolves to in one context, as
+// its go.mod selects it. The zero value means no module provides it.
+type IsolationModule struct {
+	Path    string `json:"path,omitempty"`
+	Version string `json:"version,omitempty"`
+	// Replace is the replacement, a directory or path@version, if any.
+	Replace string `json:"replace,omitempty"`
+	// Main is set when the import is a package of the context's own
+	// module.
+	Main bool `json:"main,omitempty"`
+}
+
+// IsolationImport is a snippet import that resolves differently in the
+// project and in scratch mode.
+type IsolationImport struct {
+	ImportPath string          `json:"importPath"`
+	Project    IsolationModule `json:"project"`
+	Scratch    IsolationModule `json:"scratch"`
+}
+
+// IsolationReport compares a snippet run in its project with the same
+// snippet run in scratch mode.
+type IsolationReport struct {
+	Project IsolationRun       `json:"project"`
+	Scratch IsolationRun       `json:"scratch"`
+	Env     []IsolationEnvDiff `json:"env"`
+	Modules []IsolationImport  `json:"modules"`
+	// Differences describes how the toolchains and outcomes differ: start
+	// errors, exit codes, timeouts and stdout. Stderr is not compared, since
+	// compiler and panic output name paths that differ between the two.
+	Differences []string `json:"differences"`
+	// Same is true when neither the outcomes, the environment nor the
+	// imports' modules differ.
+	Same bool `json:"same"`
+	// Canceled is true when a canceled run stopped the check; outcomes are
+	// then not compared.
+	Canceled bool `json:"canceled"`
+}
+
+// RunIsolationCheck runs request in its project and then in scratch mode,
+// one after another, and reports how the environment gopoke sets, the
+// modules the snippet's imports resolve to and the outcomes differ. Both
+// runs skip the run cache and golden snapshots. The runs are numbered after
+// request.RunID, when set, as RunID-project and RunID-scratch, so CancelRun
+// can stop the check.
+func (a *Application) RunIsolationCheck(ctx context.Context, request execution.RunRequest) (IsolationReport, error) {
+	if err := ctx.Err(); err != nil {
+		return IsolationReport{}, fmt.Errorf("run isolation check context: %w", err)
+	}
+	if strings.TrimSpace(request.ProjectPath) == "" {
+		return IsolationReport{}, fmt.Errorf("project path is required")
+	}
+
+	request.RefreshCache = true
+	request.Golden = false
+	scratchRequest := request
+	scratchRequest.ProjectPath = ""
+	scratchRequest.PackagePath = ""
+	scratchRequest.Files = nil
+	scratchRequest.TeeToFile = ""
+	baseRunID := strings.TrimSpace(request.RunID)
+	if baseRunID != "" {
+		request.RunID = baseRunID + "-project"
+		scratchRequest.RunID = baseRunID + "-scratch"
+	}
+
+	projectResolved, err := a.resolveRunRequest(ctx, request)
+	if err != nil {
+		return IsolationReport{}, err
+	}
+	scratchResolved, err := a.resolveRunRequest(ctx, scratchRequest)
+	if err != nil {
+		return IsolationReport{}, err
+	}
+	env, err := a.isolationEnvDiffs(ctx, projectResolved, scratchResolved)
+	if err != nil {
+		return IsolationReport{}, err
+	}
+	report := IsolationReport{
+		Env:         env,
+		Modules:     a.isolationImports(ctx, request.Source, projectResolved.projectPath, scratchResolved.projectPath),
+		Differences: []string{},
+	}
+
+	report.Project = a.isolationRun(ctx, request)
+	if report.Project.Result.Canceled {
+		report.Canceled = true
+		return report, nil
+	}
+	report.Scratch = a.isolationRun(ctx, scratchRequest)
+	if report.Scratch.Result.Canceled {
+		report.Canceled = true
+		return report, nil
+	}
+	report.Differences = isolationDifferences(report.Project, report.Scratch)
+	if projectResolved.toolchain != scratchResolved.toolchain {
+		report.Differences = append(report.Differences, fmt.Sprintf("toolchain %s in the project, %s in scratch mode", projectResolved.toolchain, scratchResolved.toolchain))
+	}
+	report.Same = len(report.Differences) == 0 && len(report.Env) == 0 && len(report.Modules) == 0
+	return report, nil
+}
+
+func (a *Application) isolationRun(ctx context.Context, request execution.RunRequest) IsolationRun {
+	result, err := a.RunSnippet(ctx, request, nil, nil)
+	if err != nil {
+		return IsolationRun{Error: err.Error()}
+	}
+	return IsolationRun{Result: result}
+}
+
+// isolationEnvDiffs compares the variables gopoke sets for each run. Masked
+// project variables are reported without their value.
+func (a *Application) isolationEnvDiffs(ctx context.Context, projectResolved resolvedRunRequest, scratchResolved resolvedRunRequest) ([]IsolationEnvDiff, error) {
+	masked := make(map[string]bool)
+	envVars, err := a.store.ProjectEnvVars(ctx, projectResolved.projectID)
+	if err != nil {
+		return nil, fmt.Errorf("load project environment: %w", err)
+	}
+	for _, envVar := range envVars {
+		if envVar.Masked {
+			masked[envVar.Key] = true
+		}
+	}
+
+	union := make(map[string]string, len(projectResolved.environment)+len(scratchResolved.environment))
+	maps.Copy(union, projectResolved.environment)
+	maps.Copy(union, scratchResolved.environment)
+	keys := slices.Sorted(maps.Keys(union))
+	diffs := []IsolationEnvDiff{}
+	for _, key := range keys {
+		projectValue, inProject := projectResolved.environment[key]
+		scratchValue, inScratch := scratchResolved.environment[key]
+		if inProject == inScratch && projectValue == scratchValue {
+			continue
+		}
+		if masked[key] {
+			if inProject {
+				projectValue = maskedEnvValue
+			}
+			if inScratch {
+				scratchValue = maskedEnvValue
+			}
+		}
+		diffs = append(diffs, IsolationEnvDiff{Key: key, Project: projectValue, Scratch: scratchValue, InProject: inProject, InScratch: inScratch})
+	}
+	return diffs, nil
+}
+
+// isolationImports returns the snippet's imports whose module differs
+// between the go.mod files in projectPath and scratchPath. Standard library
+// packages, which no module provides, are left out; so is everything when
+// the snippet or either go.mod does not parse.
+func (a *Application) isolationImports(ctx context.Context, source string, projectPath string, scratchPath string) []IsolationImport {
+	imports := []IsolationImport{}
+	file, err := parser.ParseFile(token.NewFileSet(), "snippet.go", source, parser.ImportsOnly)
+	if err != nil {
+		return imports
+	}
+	projectMod, err := project.ParseGoMod(ctx, projectPath)
+	if err != nil {
+		a.logger.Warn("read project go.mod for isolation check", "error", err)
+		return imports
+	}
+	scratchMod, err := project.ParseGoMod(ctx, scratchPath)
+	if err != nil {
+		a.logger.Warn("read scratch go.mod for isolation check", "error", err)
+		return imports
+	}
+
+	seen := make(map[string]bool)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || seen[importPath] {
+			continue
+		}
+		seen[importPath] = true
+		inProject := importModule(importPath, projectMod)
+		inScratch := importModule(importPath, scratchMod)
+		if inProject == inScratch {
+			continue
+		}
+		imports = append(imports, IsolationImport{ImportPath: importPath, Project: inProject, Scratch: inScratch})
+	}
+	slices.SortFunc(imports, func(left, right IsolationImport) int {
+		return strings.Compare(left.ImportPath, right.ImportPath)
+	})
+	return imports
+}
+
+// importModule returns the module of goMod that provides importPath: its
+// own module or the requirement with the longest matching path.
+func importModule(importPath string, goMod project.GoMod) IsolationModule {
+	within := func(modulePath string) bool {
+		return modulePath != "" && (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/"))
+	}
+	if within(goMod.ModulePath) {
+		return IsolationModule{Path: goMod.ModulePath, Main: true}
+	}
+	var module IsolationModule
+	for _, require := range goMod.Requires {
+		if within(require.Path) && len(require.Path) > len(module.Path) {
+			module = IsolationModule{Path: require.Path, Version: require.Version}
+		}
+	}
+	if module.Path == "" {
+		return module
+	}
+	for _, replace := range goMod.Replaces {
+		if replace.OldPath != module.Path || (replace.OldVersion != "" && replace.OldVersion != module.Version) {
+			continue
+		}
+		module.Replace = replace.NewPath
+		if replace.NewVersion != "" {
+			module.Replace += "@" + replace.NewVersion
+		}
+	}
+	return module
+}
+
+// isolationDifferences describes how the outcomes of the two runs differ.
+func isolationDifferences(projectRun IsolationRun, scratchRun IsolationRun) []string {
+	differences := []string{}
+	if projectRun.Error != "" || scratchRun.Error != "" {
+		if projectRun.Error != "" {
+			differences = append(differences, "the project run could not start: "+projectRun.Error)
+		}
+		if scratchRun.Error != "" {
+			differences = append(differences, "the scratch run could not start: "+scratchRun.Error)
+		}
+		if projectRun.Error == scratchRun.Error {
+			return []string{}
+		}
+		return differences
+	}
+	projectResult, scratchResult := projectRun.Result, scratchRun.Result
+	if projectResult.ExitCode != scratchResult.ExitCode {
+		differences = append(differences, fmt.Sprintf("exit code %d in the project, %d in scratch mode", projectResult.ExitCode, scratchResult.ExitCode))
+	}
+	if projectResult.TimedOut != scratchResult.TimedOut {
+		if projectResult.TimedOut {
+			differences = append(differences, "only the project run timed out")
+		} else {
+			differences = append(differences, "only the scratch run timed out")
+		}
+	}
+	if projectResult.Stdout != scratchResult.Stdout {
+		differences = append(differences, "stdout differs")
+	}
+	return differences
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+)
+
+func TestRunIsolationCheckReportsProjectDifferences(t *testing.T) {
+	requireGoToolchain(t)
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.scratchDir = t.TempDir()
+	writeTestFile(t, filepath.Join(application.scratchDir, "go.mod"), "module gopoke-scratch\n\ngo 1.22\n")
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	if _, err := application.UpsertProjectEnvVar(ctx, projectDir, "MODE", "project", false); err != nil {
+		t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+	}
+	if _, err := application.UpsertProjectEnvVar(ctx, projectDir, "TOKEN", "secret", true); err != nil {
+		t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+	}
+
+	source := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() {\n\tfmt.Print(os.Getenv(\"MODE\"))\n}\n"
+	report, err := application.RunIsolationCheck(ctx, execution.RunRequest{RunID: "run_isolation", ProjectPath: projectDir, Source: source})
+	if err != nil {
+		t.Fatalf("RunIsolationCheck() error = %v", err)
+	}
+	if report.Project.Error != "" || report.Scratch.Error != "" {
+		t.Fatalf("run errors = %q, %q", report.Project.Error, report.Scratch.Error)
+	}
+	if report.Project.Result.Stdout != "project" || report.Scratch.Result.Stdout != "" {
+		t.Fatalf("stdout = %q, %q", report.Project.Result.Stdout, report.Scratch.Result.Stdout)
+	}
+	wantEnv := []IsolationEnvDiff{
+		{Key: "MODE", Project: "project", InProject: true},
+		{Key: "TOKEN", Project: maskedEnvValue, InProject: true},
+	}
+	if !reflect.DeepEqual(report.Env, wantEnv) {
+		t.Fatalf("Env = %+v, want %+v", report.Env, wantEnv)
+	}
+	if want := []string{"stdout differs"}; !reflect.DeepEqual(report.Differences, want) || report.Same || len(report.Modules) != 0 {
+		t.Fatalf("report = %+v, want only stdout to differ", report)
+	}
+
+	if _, err := application.RunIsolationCheck(ctx, execution.RunRequest{Source: source}); err == nil {
+		t.Fatal("RunIsolationCheck() without a project error = nil")
+	}
+}
+
+func TestImportModuleResolvesRequiresAndReplaces(t *testing.T) {
+	goMod := project.GoMod{
+		ModulePath: "example.com/app",
+		Requires: []project.GoModRequire{
+			{Path: "example.com/lib", Version: "v1.2.0"},
+			{Path: "example.com/lib/v2", Version: "v2.0.1"},
+		},
+		Replaces: []project.GoModReplace{{OldPath: "example.com/lib/v2", NewPath: "../lib"}},
+	}
+	tests := map[string]IsolationModule{
+		"example.com/app/internal/db": {Path: "example.com/app", Main: true},
+		"example.com/lib/errs":        {Path: "example.com/lib", Version: "v1.2.0"},
+		"example.com/lib/v2/errs":     {Path: "example.com/lib/v2", Version: "v2.0.1", Replace: "../lib"},
+		"example.com/library":         {},
+		"fmt":                         {},
+	}
+	for importPath, want := range tests {
+		if got := importModule(importPath, goMod); got != want {
+			t.Errorf("importModule(%q) = %+v, want %+v", importPath, got, want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"context"
+
+	"gopoke/internal/settings"
+	"gopoke/internal/textnorm"
+)
+
+// normalizeForSave applies the line ending, byte order mark and final
+// newline settings to content replacing original, so saves keep the
+// original's convention unless settings force one.
+func (a *Application) normalizeForSave(ctx context.Context, content string, original []byte) string {
+	gs, err := a.store.GetSettings(ctx)
+	if err != nil {
+		a.logger.Warn("load settings for save normalization", "error", err)
+		gs = settings.Defaults()
+	}
+	return textnorm.Apply(content, textnorm.Detect(original), textnorm.Options{
+		LineEnding:         gs.LineEndings,
+		StripBOM:           gs.StripBOM,
+		EnsureFinalNewline: gs.EnsureFinalNewline,
+	})
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/settings"
+	"gopoke/internal/textnorm"
+)
+
+func TestSaveGoFileKeepsOriginalConventionUnlessConfigured(t *testing.T) {
+	application := newTestApplication(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "main.go")
+	writeTestFile(t, path, "\uFEFFpackage main\r\n\r\nfunc main() {}\r\n")
+
+	if err := application.SaveGoFile(ctx, path, "package main\n\nfunc main() { println() }\n"); err != nil {
+		t.Fatalf("SaveGoFile() error = %v", err)
+	}
+	if got, want := readTestFile(t, path), "\uFEFFpackage main\r\n\r\nfunc main() { println() }\r\n"; got != want {
+		t.Fatalf("saved = %q, want %q", got, want)
+	}
+
+	if _, err := application.UpdateGlobalSettings(ctx, settings.GlobalSettings{
+		LineEndings:        textnorm.LineEndingLF,
+		StripBOM:           true,
+		EnsureFinalNewline: true,
+	}); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+	if err := application.SaveGoFile(ctx, path, "package main\r\n\r\nfunc main() {}"); err != nil {
+		t.Fatalf("SaveGoFile() error = %v", err)
+	}
+	if got, want := readTestFile(t, path), "package main\n\nfunc main() {}\n"; got != want {
+		t.Fatalf("saved = %q, want %q", got, want)
+	}
+}
+
+func TestSaveProjectSnippetKeepsExistingLineEndings(t *testing.T) {
+	application := newTestApplication(t)
+	ctx := context.Background()
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	created, err := application.SaveProjectSnippet(ctx, projectDir, "", "crlf", "package main\r\n")
+	if err != nil {
+		t.Fatalf("SaveProjectSnippet(create) error = %v", err)
+	}
+	updated, err := application.SaveProjectSnippet(ctx, projectDir, created.ID, "crlf", "package main\n\nfunc main() {}\n")
+	if err != nil {
+		t.Fatalf("SaveProjectSnippet(update) error = %v", err)
+	}
+	if want := "package main\r\n\r\nfunc main() {}\r\n"; updated.Content != want {
+		t.Fatalf("Content = %q, want %q", updated.Content, want)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%q) error = %v", path, err)
+	}
+	return string(raw)
+}
@@ -0,0 +1,342 @@
+package app
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+	"gopoke/internal/storage"
+)
+
+const (
+	// moduleOverlaysDirName holds kept module overlays inside a project's
+	// artifacts directory, one subdirectory per run.
+	moduleOverlaysDirName = ".module-overlays"
+	// maxModuleOverlays is how many unapplied overlays a project keeps;
+	// older ones are removed when a new run starts.
+	maxModuleOverlays = 20
+	// moduleOverlayBaseSuffix marks the copy of a module file as it was
+	// when the run started.
+	moduleOverlayBaseSuffix = ".base"
+)
+
+// moduleOverlayFiles are the module files a run gets private copies of.
+var moduleOverlayFiles = []string{"go.mod", "go.sum"}
+
+// moduleOverlay is one run's private copy of its project's go.mod and
+// go.sum. Next to each copy is a base copy of the original, used to tell
+// what the run changed and whether the project changed since.
+type moduleOverlay struct {
+	dir     string // empty when the run uses the project's files
+	skipped string
+}
+
+// SetProjectModuleOverlay turns a project's module overlay mode on or off.
+func (a *Application) SetProjectModuleOverlay(ctx context.Context, projectPath string, enabled bool) (storage.ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project module overlay context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	updated, err := a.store.UpdateProjectModuleOverlay(ctx, record.Path, enabled)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project module overlay: %w", err)
+	}
+	return updated, nil
+}
+
+// prepareModuleOverlay copies the project's module files for a run in
+// module overlay mode. It returns nil when the run needs no overlay: the
+// mode is off, the run is not a go run, or the project has no go.mod.
+func (a *Application) prepareModuleOverlay(runID string, resolved resolvedRunRequest) (*moduleOverlay, error) {
+	if !resolved.moduleOverlay || resolved.projectID == "" || a.executionBackend().Name() != execution.BackendGo {
+		return nil, nil
+	}
+	if _, err := os.Stat(filepath.Join(resolved.projectPath, "go.mod")); err != nil {
+		return nil, nil
+	}
+	if reason := moduleOverlaySkipReason(resolved); reason != "" {
+		return &moduleOverlay{skipped: reason}, nil
+	}
+	root := moduleOverlaysRoot(a.artifactsDir, resolved.projectID)
+	if root == "" {
+		return nil, fmt.Errorf("module overlay: artifacts directory not configured")
+	}
+	a.pruneModuleOverlays(root)
+
+	dir := filepath.Join(root, runID)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create module overlay: %w", err)
+	}
+	for _, name := range moduleOverlayFiles {
+		data, err := os.ReadFile(filepath.Join(resolved.projectPath, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, name), data, 0o600)
+		}
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, name+moduleOverlayBaseSuffix), data, 0o600)
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("copy %s to module overlay: %w", name, err)
+		}
+	}
+	return &moduleOverlay{dir: dir}, nil
+}
+
+// moduleOverlaySkipReason says why a run cannot use a private go.mod: the
+// go command refuses -modfile in workspace mode, and a working directory in
+// a nested module builds against that module's go.mod instead.
+func moduleOverlaySkipReason(resolved resolvedRunRequest) string {
+	goWork, ok := resolved.environment["GOWORK"]
+	if !ok {
+		goWork = os.Getenv("GOWORK")
+	}
+	switch {
+	case goWork == "off":
+	case goWork != "":
+		return "GOWORK selects a workspace"
+	default:
+		for dir := resolved.workingDirectory; ; dir = filepath.Dir(dir) {
+			if _, err := os.Stat(filepath.Join(dir, "go.work")); err == nil {
+				return "the run is in a go.work workspace"
+			}
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	for dir := resolved.workingDirectory; dir != resolved.projectPath; dir = filepath.Dir(dir) {
+		if filepath.Dir(dir) == dir {
+			break
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "the working directory is in a nested module"
+		}
+	}
+	return ""
+}
+
+// modFile returns the go.mod copy the run uses, or empty when it uses the
+// project's.
+func (o *moduleOverlay) modFile() string {
+	if o == nil || o.dir == "" {
+		return ""
+	}
+	return filepath.Join(o.dir, "go.mod")
+}
+
+// finish reports what the run changed in its copies. An overlay with no
+// changes is removed; one with changes is kept until ApplyRunModuleChanges
+// or DiscardModuleOverlay removes it.
+func (o *moduleOverlay) finish() *execution.ModuleOverlay {
+	if o == nil {
+		return nil
+	}
+	if o.dir == "" {
+		return &execution.ModuleOverlay{Skipped: o.skipped}
+	}
+	report := &execution.ModuleOverlay{
+		GoModChanged: moduleOverlayChanged(o.dir, "go.mod"),
+		GoSumChanged: moduleOverlayChanged(o.dir, "go.sum"),
+	}
+	if !report.GoModChanged && !report.GoSumChanged {
+		os.RemoveAll(o.dir)
+		return nil
+	}
+	return report
+}
+
+// discard removes the overlay of a run that produced no result.
+func (o *moduleOverlay) discard() {
+	if o != nil && o.dir != "" {
+		os.RemoveAll(o.dir)
+	}
+}
+
+// moduleOverlayChanged reports whether the run's copy of name differs from
+// its base copy. A file the run created has no base copy.
+func moduleOverlayChanged(dir string, name string) bool {
+	current, currentErr := os.ReadFile(filepath.Join(dir, name))
+	base, baseErr := os.ReadFile(filepath.Join(dir, name+moduleOverlayBaseSuffix))
+	if currentErr != nil || baseErr != nil {
+		return (currentErr == nil) != (baseErr == nil)
+	}
+	return !bytes.Equal(current, base)
+}
+
+// DiscardModuleOverlay removes the module files a module overlay run
+// changed without applying them.
+func (a *Application) DiscardModuleOverlay(ctx context.Context, projectPath string, runID string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("discard module overlay context: %w", err)
+	}
+	_, dir, err := a.moduleOverlayDir(ctx, projectPath, runID)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("discard module overlay: %w", err)
+	}
+	return nil
+}
+
+// moduleOverlayDir finds the kept overlay of a project's run.
+func (a *Application) moduleOverlayDir(ctx context.Context, projectPath string, runID string) (storage.ProjectRecord, string, error) {
+	runID = strings.TrimSpace(runID)
+	if runID == "" || runID != filepath.Base(runID) || runID == "." || runID == ".." {
+		return storage.ProjectRecord{}, "", fmt.Errorf("invalid run ID %q", runID)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, "", err
+	}
+	dir := filepath.Join(moduleOverlaysRoot(a.artifactsDir, record.ID), runID)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return storage.ProjectRecord{}, "", fmt.Errorf("no module overlay for run %s", runID)
+	}
+	return record, dir, nil
+}
+
+// moduleOverlaysRoot returns where a project's module overlays are kept.
+func moduleOverlaysRoot(artifactsDir string, projectID string) string {
+	dir := projectDataDir(artifactsDir, projectID)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, moduleOverlaysDirName)
+}
+
+// pruneModuleOverlays removes the oldest kept overlays so at most
+// maxModuleOverlays-1 remain before a new one is created.
+func (a *Application) pruneModuleOverlays(root string) {
+	entries, err := os.ReadDir(root)
+	if err != nil || len(entries) < maxModuleOverlays {
+		return
+	}
+	type overlayEntry struct {
+		path    string
+		modTime int64
+	}
+	overlays := make([]overlayEntry, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		overlays = append(overlays, overlayEntry{path: filepath.Join(root, entry.Name()), modTime: info.ModTime().UnixNano()})
+	}
+	slices.SortFunc(overlays, func(left, right overlayEntry) int {
+		return cmp.Compare(left.modTime, right.modTime)
+	})
+	for len(overlays) >= maxModuleOverlays {
+		if err := os.RemoveAll(overlays[0].path); err != nil {
+			a.logger.Warn("prune module overlay", "path", overlays[0].path, "error", err)
+		}
+		overlays = overlays[1:]
+	}
+}
+
+// PreviewRunModuleChanges lists the requirement and go.sum changes a
+// module overlay run made, as ApplyRunModuleChanges would apply them.
+func (a *Application) PreviewRunModuleChanges(ctx context.Context, runID string) (project.ModuleChanges, error) {
+	if err := ctx.Err(); err != nil {
+		return project.ModuleChanges{}, fmt.Errorf("preview run module changes context: %w", err)
+	}
+	_, _, changes, err := a.runModuleChanges(ctx, runID)
+	if err != nil {
+		return project.ModuleChanges{}, err
+	}
+	return changes, nil
+}
+
+// ApplyRunModuleChanges turns what a successful module overlay run did to
+// its go.mod and go.sum into real dependency changes. Rather than taking
+// the run's files as they are, it applies only the run's requirement
+// changes and new go.sum lines on top of the project's current files, so
+// edits made since the run are kept. The overlay is removed once applied.
+func (a *Application) ApplyRunModuleChanges(ctx context.Context, runID string) (_ project.ModuleChanges, err error) {
+	projectPath := ""
+	defer func() {
+		a.recordAudit(audit.ActionApplyModChanges, projectPath, map[string]string{"runId": runID}, err)
+	}()
+	if err := ctx.Err(); err != nil {
+		return project.ModuleChanges{}, fmt.Errorf("apply run module changes context: %w", err)
+	}
+	record, dir, changes, err := a.runModuleChanges(ctx, runID)
+	if err != nil {
+		return project.ModuleChanges{}, err
+	}
+	projectPath = record.Path
+	if !changes.Empty() {
+		if _, err := project.ApplyModuleChanges(ctx, record.Path, changes); err != nil {
+			return project.ModuleChanges{}, fmt.Errorf("apply run module changes: %w", err)
+		}
+		a.sumChecks.forget(record.ID)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		a.logger.Warn("remove applied module overlay", "path", dir, "error", err)
+	}
+	return changes, nil
+}
+
+// runModuleChanges diffs the kept overlay of a successful run against the
+// module files the run started from.
+func (a *Application) runModuleChanges(ctx context.Context, runID string) (storage.ProjectRecord, string, project.ModuleChanges, error) {
+	runID = strings.TrimSpace(runID)
+	run, found, err := a.store.RunByID(ctx, runID)
+	if err != nil {
+		return storage.ProjectRecord{}, "", project.ModuleChanges{}, fmt.Errorf("load run: %w", err)
+	}
+	if !found {
+		return storage.ProjectRecord{}, "", project.ModuleChanges{}, fmt.Errorf("run %s not found", runID)
+	}
+	if run.Status != runStatusSuccess {
+		return storage.ProjectRecord{}, "", project.ModuleChanges{}, fmt.Errorf("run %s did not succeed", runID)
+	}
+	record, found, err := a.store.ProjectByID(ctx, run.ProjectID)
+	if err != nil {
+		return storage.ProjectRecord{}, "", project.ModuleChanges{}, fmt.Errorf("load run project: %w", err)
+	}
+	if !found {
+		return storage.ProjectRecord{}, "", project.ModuleChanges{}, fmt.Errorf("project of run %s not found", runID)
+	}
+	record, dir, err := a.moduleOverlayDir(ctx, record.Path, runID)
+	if err != nil {
+		return storage.ProjectRecord{}, "", project.ModuleChanges{}, err
+	}
+
+	read := func(name string) ([]byte, error) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) && strings.HasPrefix(name, "go.sum") {
+			return nil, nil
+		}
+		return data, err
+	}
+	var files [4][]byte
+	for i, name := range []string{"go.mod" + moduleOverlayBaseSuffix, "go.mod", "go.sum" + moduleOverlayBaseSuffix, "go.sum"} {
+		if files[i], err = read(name); err != nil {
+			return storage.ProjectRecord{}, "", project.ModuleChanges{}, fmt.Errorf("read module overlay %s: %w", name, err)
+		}
+	}
+	changes, err := project.DiffModuleFiles(files[0], files[1], files[2], files[3])
+	if err != nil {
+		return storage.ProjectRecord{}, "", project.ModuleChanges{}, fmt.Errorf("diff module overlay: %w", err)
+	}
+	return record, dir, changes, nil
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+)
+
+// goModEditSnippet runs go mod edit with flag from inside the snippet,
+// standing in for a run that changes the module files.
+func goModEditSnippet(flag string) string {
+	return "package main\n\nimport (\n\t\"os\"\n\t\"os/exec\"\n)\n\nfunc main() {\n" +
+		"\tcmd := exec.Command(\"go\", \"mod\", \"edit\", \"" + flag + "\")\n" +
+		"\tcmd.Stderr = os.Stderr\n\tif err := cmd.Run(); err != nil {\n\t\tos.Exit(1)\n\t}\n}\n"
+}
+
+// newModuleOverlayProject opens a project with module overlay mode on and
+// an offline go environment, and returns its go.mod path.
+func newModuleOverlayProject(t *testing.T, application *Application) (string, string) {
+	t.Helper()
+	ctx := context.Background()
+	application.artifactsDir = t.TempDir()
+	projectDir := t.TempDir()
+	goModPath := filepath.Join(projectDir, "go.mod")
+	writeTestFile(t, goModPath, "module example.com/overlay\n\ngo 1.22\n")
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	for key, value := range map[string]string{"GOPROXY": "off", "GOFLAGS": "", "GOWORK": "off"} {
+		if _, err := application.UpsertProjectEnvVar(ctx, projectDir, key, value, false); err != nil {
+			t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+		}
+	}
+	if _, err := application.SetProjectModuleOverlay(ctx, projectDir, true); err != nil {
+		t.Fatalf("SetProjectModuleOverlay() error = %v", err)
+	}
+	return projectDir, goModPath
+}
+
+// runModuleOverlaySnippet runs goModEditSnippet(flag) and checks it
+// succeeded.
+func runModuleOverlaySnippet(t *testing.T, application *Application, projectDir string, runID string, flag string) execution.Result {
+	t.Helper()
+	request := execution.RunRequest{RunID: runID, ProjectPath: projectDir, Source: goModEditSnippet(flag)}
+	result, err := application.RunSnippet(context.Background(), request, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet(%s) error = %v", runID, err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("RunSnippet(%s) = %+v, want success", runID, result)
+	}
+	return result
+}
+
+func TestModuleOverlayKeepsRunChangesOutOfProject(t *testing.T) {
+	requireGoToolchain(t)
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir, goModPath := newModuleOverlayProject(t, application)
+
+	result := runModuleOverlaySnippet(t, application, projectDir, "run_overlay_keep", "-go=1.23")
+	if result.ModuleOverlay == nil || !result.ModuleOverlay.GoModChanged || result.ModuleOverlay.GoSumChanged {
+		t.Fatalf("ModuleOverlay = %+v, want only go.mod changed", result.ModuleOverlay)
+	}
+	if strings.Contains(readTestFile(t, goModPath), "go 1.23") {
+		t.Fatal("run changed the project's go.mod")
+	}
+
+	runModuleOverlaySnippet(t, application, projectDir, "run_overlay_discard", "-require=example.com/dep@v1.0.0")
+	if err := application.DiscardModuleOverlay(ctx, projectDir, "run_overlay_discard"); err != nil {
+		t.Fatalf("DiscardModuleOverlay() error = %v", err)
+	}
+	if _, err := application.ApplyRunModuleChanges(ctx, "run_overlay_discard"); err == nil {
+		t.Fatal("ApplyRunModuleChanges() after discard error = nil, want the overlay gone")
+	}
+	if strings.Contains(readTestFile(t, goModPath), "example.com/dep") {
+		t.Fatal("discarded changes reached the project's go.mod")
+	}
+	if err := application.DiscardModuleOverlay(ctx, projectDir, "../run_overlay_keep"); err == nil {
+		t.Fatal("DiscardModuleOverlay() accepted a run ID with a path")
+	}
+}
+
+func TestApplyRunModuleChangesKeepsLaterProjectEdits(t *testing.T) {
+	requireGoToolchain(t)
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir, goModPath := newModuleOverlayProject(t, application)
+
+	runModuleOverlaySnippet(t, application, projectDir, "run_overlay_require", "-require=example.com/dep@v1.0.0")
+	preview, err := application.PreviewRunModuleChanges(ctx, "run_overlay_require")
+	if err != nil {
+		t.Fatalf("PreviewRunModuleChanges() error = %v", err)
+	}
+	want := []project.RequireChange{{Path: "example.com/dep", Kind: project.RequireAdded, To: "v1.0.0"}}
+	if !slices.Equal(preview.Requires, want) || len(preview.SumLines) != 0 {
+		t.Fatalf("preview = %+v, want %+v", preview, want)
+	}
+
+	// An edit made after the run must survive applying it.
+	writeTestFile(t, goModPath, "module example.com/overlay\n\ngo 1.23\n")
+	applied, err := application.ApplyRunModuleChanges(ctx, "run_overlay_require")
+	if err != nil {
+		t.Fatalf("ApplyRunModuleChanges() error = %v", err)
+	}
+	if !slices.Equal(applied.Requires, want) {
+		t.Fatalf("applied = %+v, want %+v", applied.Requires, want)
+	}
+	goMod := readTestFile(t, goModPath)
+	if !strings.Contains(goMod, "go 1.23") || !strings.Contains(goMod, "require example.com/dep v1.0.0") {
+		t.Fatalf("go.mod = %q, want the later edit and the run's requirement", goMod)
+	}
+	if _, err := application.PreviewRunModuleChanges(ctx, "run_overlay_require"); err == nil {
+		t.Fatal("PreviewRunModuleChanges() after apply error = nil, want the overlay gone")
+	}
+	if _, err := application.ApplyRunModuleChanges(ctx, "run_missing"); err == nil {
+		t.Fatal("ApplyRunModuleChanges(unknown run) error = nil")
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/project"
+)
+
+// ParseGoMod returns the structured require, replace and exclude directives
+// of a project's go.mod.
+func (a *Application) ParseGoMod(ctx context.Context, projectPath string) (project.GoMod, error) {
+	resolvedPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return project.GoMod{}, err
+	}
+	parsed, err := project.ParseGoMod(ctx, resolvedPath)
+	if err != nil {
+		return project.GoMod{}, fmt.Errorf("parse go.mod: %w", err)
+	}
+	return parsed, nil
+}
+
+// AddGoModReplace adds or updates a replace directive in a project's go.mod.
+func (a *Application) AddGoModReplace(ctx context.Context, projectPath string, oldPath string, oldVersion string, newPath string, newVersion string) (_ project.GoMod, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionGoModReplace, projectPath, map[string]string{
+			"oldPath": oldPath, "oldVersion": oldVersion, "newPath": newPath, "newVersion": newVersion,
+		}, err)
+	}()
+	resolvedPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return project.GoMod{}, err
+	}
+	updated, err := project.AddReplace(ctx, resolvedPath, oldPath, oldVersion, newPath, newVersion)
+	if err != nil {
+		return project.GoMod{}, fmt.Errorf("add go.mod replace: %w", err)
+	}
+	return updated, nil
+}
+
+// DropGoModRequire removes a require directive from a project's go.mod.
+func (a *Application) DropGoModRequire(ctx context.Context, projectPath string, modulePath string) (_ project.GoMod, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionGoModDropRequire, projectPath, map[string]string{"modulePath": modulePath}, err)
+	}()
+	resolvedPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return project.GoMod{}, err
+	}
+	updated, err := project.DropRequire(ctx, resolvedPath, modulePath)
+	if err != nil {
+		return project.GoMod{}, fmt.Errorf("drop go.mod require: %w", err)
+	}
+	return updated, nil
+}
+
+// ParseGoWork returns the go version and use directives of a project's go.work.
+func (a *Application) ParseGoWork(ctx context.Context, projectPath string) (project.GoWork, error) {
+	resolvedPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return project.GoWork{}, err
+	}
+	parsed, err := project.ParseGoWork(ctx, resolvedPath)
+	if err != nil {
+		return project.GoWork{}, fmt.Errorf("parse go.work: %w", err)
+	}
+	return parsed, nil
+}
+
+// CreateGoWork writes a new go.work in the project using the given module
+// directories.
+func (a *Application) CreateGoWork(ctx context.Context, projectPath string, moduleDirs []string) (_ project.GoWork, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionGoWorkCreate, projectPath, map[string]string{"moduleDirs": strings.Join(moduleDirs, ",")}, err)
+	}()
+	resolvedPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return project.GoWork{}, err
+	}
+	created, err := project.CreateGoWork(ctx, resolvedPath, moduleDirs)
+	if err != nil {
+		return project.GoWork{}, fmt.Errorf("create go.work: %w", err)
+	}
+	return created, nil
+}
+
+// AddWorkModule adds a module directory to a project's go.work.
+func (a *Application) AddWorkModule(ctx context.Context, projectPath string, moduleDir string) (_ project.GoWork, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionGoWorkAddModule, projectPath, map[string]string{"moduleDir": moduleDir}, err)
+	}()
+	resolvedPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return project.GoWork{}, err
+	}
+	updated, err := project.AddWorkModule(ctx, resolvedPath, moduleDir)
+	if err != nil {
+		return project.GoWork{}, fmt.Errorf("add go.work module: %w", err)
+	}
+	return updated, nil
+}
+
+// DropWorkModule removes a module directory from a project's go.work.
+func (a *Application) DropWorkModule(ctx context.Context, projectPath string, moduleDir string) (_ project.GoWork, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionGoWorkDropModule, projectPath, map[string]string{"moduleDir": moduleDir}, err)
+	}()
+	resolvedPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return project.GoWork{}, err
+	}
+	updated, err := project.DropWorkModule(ctx, resolvedPath, moduleDir)
+	if err != nil {
+		return project.GoWork{}, fmt.Errorf("drop go.work module: %w", err)
+	}
+	return updated, nil
+}
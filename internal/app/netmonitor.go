@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+
+	"gopoke/internal/netmon"
+	"gopoke/internal/procmem"
+)
+
+// RunNetworkEvent reports a remote endpoint a running snippet connected to.
+type RunNetworkEvent struct {
+	RunID      string            `json:"runId"`
+	Connection netmon.Connection `json:"connection"`
+}
+
+// RunNetworkHandler receives run network events.
+type RunNetworkHandler func(event RunNetworkEvent)
+
+// SetRunNetworkHandler streams the connections of running snippets to
+// handler while the MonitorRunNetwork setting is on. A nil handler disables
+// streaming.
+func (a *Application) SetRunNetworkHandler(handler RunNetworkHandler) {
+	a.networkMu.Lock()
+	defer a.networkMu.Unlock()
+	a.networkHandler = handler
+}
+
+// startRunNetworkMonitor watches the process tree rooted at pid until ctx
+// ends. It does nothing unless monitoring is enabled and a handler is set.
+func (a *Application) startRunNetworkMonitor(ctx context.Context, runID string, pid int) {
+	if !a.monitorNetwork.Load() {
+		return
+	}
+	a.networkMu.Lock()
+	handler := a.networkHandler
+	a.networkMu.Unlock()
+	if handler == nil {
+		return
+	}
+
+	go func() {
+		err := netmon.Watch(ctx, runProcessIDs(pid), netmon.DefaultInterval, func(connection netmon.Connection) {
+			a.logger.Info("run network connection",
+				"runID", runID, "pid", connection.PID, "protocol", connection.Protocol, "remote", connection.RemoteAddress)
+			handler(RunNetworkEvent{RunID: runID, Connection: connection})
+		})
+		if err != nil {
+			a.logger.Warn("run network monitor unavailable", "runID", runID, "error", err)
+		}
+	}()
+}
+
+// runProcessIDs returns a function listing the pids of the process tree
+// rooted at pid, or just pid where trees cannot be listed.
+func runProcessIDs(pid int) func() []int {
+	return func() []int {
+		tree, err := procmem.ProcessTree(pid)
+		if err != nil {
+			return []int{pid}
+		}
+		pids := make([]int, 0, len(tree))
+		for _, process := range tree {
+			pids = append(pids, process.PID)
+		}
+		return pids
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/netmon"
+	"gopoke/internal/settings"
+	"gopoke/internal/testutil"
+)
+
+func TestRunNetworkMonitorReportsSnippetConnections(t *testing.T) {
+	requireGoToolchain(t)
+	if _, err := netmon.Connections([]int{1}); errors.Is(err, netmon.ErrUnsupported) {
+		t.Skip(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.UpdateGlobalSettings(context.Background(), settings.GlobalSettings{MonitorRunNetwork: true}); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+	events := make(chan RunNetworkEvent, 8)
+	application.SetRunNetworkHandler(func(event RunNetworkEvent) {
+		events <- event
+	})
+
+	source := fmt.Sprintf(`package main
+
+import (
+	"net"
+	"time"
+)
+
+func main() {
+	conn, err := net.Dial("tcp", %q)
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+	time.Sleep(2 * time.Second)
+}
+`, listener.Addr().String())
+
+	runCtx, cancel := testutil.TestRunContext(t)
+	defer cancel()
+	if _, err := application.RunSnippet(runCtx, execution.RunRequest{RunID: "run-net", ProjectPath: projectDir, Source: source}, nil, nil); err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.RunID == "run-net" && event.Connection.RemoteAddress == listener.Addr().String() {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("no network event for %s", listener.Addr())
+		}
+	}
+}
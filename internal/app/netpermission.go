@@ -0,0 +1,282 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/netmon"
+	"gopoke/internal/storage"
+)
+
+// Decisions for a run network permission prompt.
+const (
+	// NetworkAllowOnce lets the run reach the host until it ends.
+	NetworkAllowOnce = "allowOnce"
+	// NetworkAllowProject adds the host to the project's allow list.
+	NetworkAllowProject = "allowProject"
+	// NetworkDeny stops the run.
+	NetworkDeny = "deny"
+)
+
+// networkPermissionInterval is how often prompting runs are sampled for new
+// connections; shorter than netmon.DefaultInterval so a run does little
+// before it is paused.
+const networkPermissionInterval = 100 * time.Millisecond
+
+// RunNetworkPermissionRequest asks whether a paused run may keep talking to
+// Host. The run stays paused until RespondRunNetworkPermission; its timeout
+// keeps counting meanwhile.
+type RunNetworkPermissionRequest struct {
+	RunID string `json:"runId"`
+	Host  string `json:"host"`
+	// CanAllowProject is false for scratch runs, which have no project to
+	// remember the decision in.
+	CanAllowProject bool              `json:"canAllowProject"`
+	Connection      netmon.Connection `json:"connection"`
+}
+
+// RunNetworkPermissionHandler receives run network permission requests.
+type RunNetworkPermissionHandler func(request RunNetworkPermissionRequest)
+
+// networkPrompt is a permission request waiting for the user.
+type networkPrompt struct {
+	request     RunNetworkPermissionRequest
+	projectPath string
+	decision    chan string
+}
+
+// SetRunNetworkPermissionHandler sends permission requests of runs that
+// connect to unapproved hosts to handler while the PromptRunNetwork setting
+// is on. A nil handler disables prompting.
+func (a *Application) SetRunNetworkPermissionHandler(handler RunNetworkPermissionHandler) {
+	a.networkMu.Lock()
+	defer a.networkMu.Unlock()
+	a.permissionHandler = handler
+}
+
+// RespondRunNetworkPermission answers the pending permission prompt of a run
+// with NetworkAllowOnce, NetworkAllowProject or NetworkDeny.
+func (a *Application) RespondRunNetworkPermission(ctx context.Context, runID string, decision string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("respond run network permission context: %w", err)
+	}
+	runID = strings.TrimSpace(runID)
+	switch decision {
+	case NetworkAllowOnce, NetworkAllowProject, NetworkDeny:
+	default:
+		return fmt.Errorf("unsupported network permission decision %q", decision)
+	}
+
+	a.networkMu.Lock()
+	prompt, ok := a.networkPrompts[runID]
+	a.networkMu.Unlock()
+	if !ok {
+		return fmt.Errorf("run %q has no pending network permission request", runID)
+	}
+	if decision == NetworkAllowProject {
+		if !prompt.request.CanAllowProject {
+			return fmt.Errorf("run %q has no project to allow %s for", runID, prompt.request.Host)
+		}
+		if _, err := a.allowProjectHost(ctx, prompt.projectPath, prompt.request.Host); err != nil {
+			return err
+		}
+	}
+
+	a.networkMu.Lock()
+	current, ok := a.networkPrompts[runID]
+	if ok && current == prompt {
+		delete(a.networkPrompts, runID)
+	}
+	a.networkMu.Unlock()
+	if !ok || current != prompt {
+		return fmt.Errorf("run %q network permission request was already answered", runID)
+	}
+	prompt.decision <- decision
+	return nil
+}
+
+// SetProjectNetworkAllow replaces the hosts a project's runs may connect to
+// without a permission prompt.
+func (a *Application) SetProjectNetworkAllow(ctx context.Context, projectPath string, hosts []string) (storage.ProjectRecord, error) {
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	normalized := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || slices.Contains(normalized, host) {
+			continue
+		}
+		normalized = append(normalized, host)
+	}
+	updated, err := a.store.UpdateProjectNetworkAllow(ctx, projectRecord.Path, normalized)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project network allow list: %w", err)
+	}
+	return updated, nil
+}
+
+func (a *Application) allowProjectHost(ctx context.Context, projectPath string, host string) (storage.ProjectRecord, error) {
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	return a.SetProjectNetworkAllow(ctx, projectRecord.Path, append(slices.Clone(projectRecord.NetworkAllow), host))
+}
+
+// takeNetworkDenial reports whether runID was stopped by a denied prompt and
+// forgets it.
+func (a *Application) takeNetworkDenial(runID string) bool {
+	a.networkMu.Lock()
+	defer a.networkMu.Unlock()
+	denied := a.networkDenied[runID]
+	delete(a.networkDenied, runID)
+	return denied
+}
+
+// runNetworkGate is the permission state of one prompting run.
+type runNetworkGate struct {
+	runID       string
+	projectPath string // empty for scratch runs
+	pid         int
+	allowed     map[string]bool
+	handler     RunNetworkPermissionHandler
+}
+
+// networkPromptSupport returns why this platform cannot run network
+// permission prompts, which need socket listing to see connections and
+// process suspension to pause the run, or nil when it can.
+func networkPromptSupport() error {
+	if !netmon.Supported {
+		return netmon.ErrUnsupported
+	}
+	if !execution.CanSuspendRuns {
+		return fmt.Errorf("pausing runs is not supported on %s", runtime.GOOS)
+	}
+	return nil
+}
+
+// networkPromptHandler returns the handler runs prompt through, or nil when
+// prompting is off.
+func (a *Application) networkPromptHandler() RunNetworkPermissionHandler {
+	if !a.promptNetwork.Load() {
+		return nil
+	}
+	a.networkMu.Lock()
+	defer a.networkMu.Unlock()
+	return a.permissionHandler
+}
+
+// startRunNetworkPermissions watches the process tree rooted at pid until
+// ctx ends and pauses the run at each connection to a new host outside the
+// project's allow list until the user decides. Loopback hosts never prompt.
+// This is detection after the fact, not a firewall: a connection is seen
+// only once it is open, up to networkPermissionInterval later, so the run
+// may already have exchanged data with the host when it is paused. It does
+// nothing unless prompting is enabled and a handler is set; sandboxStage
+// refuses runs where prompting cannot work.
+func (a *Application) startRunNetworkPermissions(ctx context.Context, runID string, resolved resolvedRunRequest, pid int) {
+	handler := a.networkPromptHandler()
+	if handler == nil {
+		return
+	}
+
+	gate := &runNetworkGate{runID: runID, pid: pid, allowed: make(map[string]bool), handler: handler}
+	if resolved.projectID != "" {
+		gate.projectPath = resolved.projectPath
+	}
+	for _, host := range resolved.networkAllow {
+		gate.allowed[host] = true
+	}
+	go func() {
+		err := netmon.Watch(ctx, runProcessIDs(pid), networkPermissionInterval, func(connection netmon.Connection) {
+			a.checkRunConnection(ctx, gate, connection)
+		})
+		if err != nil {
+			a.logger.Warn("run network permissions unavailable", "runID", runID, "error", err)
+		}
+	}()
+}
+
+// checkRunConnection prompts for connection unless its host is allowed and
+// blocks until the user answers or ctx ends.
+func (a *Application) checkRunConnection(ctx context.Context, gate *runNetworkGate, connection netmon.Connection) {
+	host := remoteHost(connection.RemoteAddress)
+	if gate.allowed[host] {
+		return
+	}
+	if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsUnspecified()) {
+		return
+	}
+
+	if err := execution.SuspendRun(gate.pid); err != nil {
+		a.logger.Warn("pause run for network permission", "runID", gate.runID, "error", err)
+	}
+	prompt := &networkPrompt{
+		request: RunNetworkPermissionRequest{
+			RunID:           gate.runID,
+			Host:            host,
+			CanAllowProject: gate.projectPath != "",
+			Connection:      connection,
+		},
+		projectPath: gate.projectPath,
+		decision:    make(chan string, 1),
+	}
+	a.networkMu.Lock()
+	if a.networkPrompts == nil {
+		a.networkPrompts = make(map[string]*networkPrompt)
+	}
+	a.networkPrompts[gate.runID] = prompt
+	a.networkMu.Unlock()
+	a.logger.Info("run network permission requested", "runID", gate.runID, "remote", connection.RemoteAddress)
+	gate.handler(prompt.request)
+
+	var decision string
+	select {
+	case decision = <-prompt.decision:
+	case <-ctx.Done():
+		a.networkMu.Lock()
+		delete(a.networkPrompts, gate.runID)
+		a.networkMu.Unlock()
+		// The run ended or timed out while paused; resume it so it can
+		// handle its shutdown signal instead of waiting to be killed.
+		if err := execution.ResumeRun(gate.pid); err != nil {
+			a.logger.Warn("resume run after network prompt ended", "runID", gate.runID, "error", err)
+		}
+		return
+	}
+	a.logger.Info("run network permission decided", "runID", gate.runID, "host", host, "decision", decision)
+	if decision == NetworkDeny {
+		a.networkMu.Lock()
+		if a.networkDenied == nil {
+			a.networkDenied = make(map[string]bool)
+		}
+		a.networkDenied[gate.runID] = true
+		a.networkMu.Unlock()
+		if err := a.CancelRun(context.Background(), gate.runID); err != nil {
+			a.logger.Warn("stop run after denied network permission", "runID", gate.runID, "error", err)
+		}
+	} else {
+		gate.allowed[host] = true
+	}
+	// A denied run is resumed too, so it receives its shutdown signal.
+	if err := execution.ResumeRun(gate.pid); err != nil {
+		a.logger.Warn("resume run after network permission", "runID", gate.runID, "error", err)
+	}
+}
+
+// remoteHost strips the port from a netmon remote address.
+func remoteHost(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return strings.ToLower(address)
+	}
+	return strings.ToLower(host)
+}
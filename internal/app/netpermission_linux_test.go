@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"gopoke/internal/netmon"
+)
+
+func TestCheckRunConnectionResumesRunCanceledWhilePrompting(t *testing.T) {
+	t.Parallel()
+
+	command := exec.Command("sleep", "30")
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := command.Start(); err != nil {
+		t.Skipf("start sleep: %v", err)
+	}
+	defer func() {
+		_ = command.Process.Kill()
+		_ = command.Wait()
+	}()
+	pid := command.Process.Pid
+
+	application := newTestApplication(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	gate := &runNetworkGate{
+		runID:   "run-3",
+		pid:     pid,
+		allowed: map[string]bool{},
+		handler: func(RunNetworkPermissionRequest) {
+			waitForRunState(t, pid, "T")
+			cancel()
+		},
+	}
+	application.checkRunConnection(ctx, gate, netmon.Connection{Protocol: netmon.ProtocolTCP, RemoteAddress: "203.0.113.5:443"})
+
+	waitForRunState(t, pid, "S")
+	if err := application.RespondRunNetworkPermission(context.Background(), "run-3", NetworkAllowOnce); err == nil {
+		t.Fatal("RespondRunNetworkPermission() after cancel error = nil, want the prompt gone")
+	}
+}
+
+// waitForRunState polls /proc until pid is in state.
+func waitForRunState(t *testing.T, pid int, state string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+		if err != nil {
+			t.Fatalf("read process stat: %v", err)
+		}
+		// The state follows the parenthesized command name.
+		fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+		if len(fields) > 0 && fields[0] == state {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("process %d state = %v, want %s", pid, fields, state)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
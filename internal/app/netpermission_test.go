@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"gopoke/internal/netmon"
+	"gopoke/internal/settings"
+)
+
+func TestCheckRunConnectionAllowsHostForProject(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	ctx := context.Background()
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	var requests []RunNetworkPermissionRequest
+	gate := &runNetworkGate{
+		runID:       "run-1",
+		projectPath: projectDir,
+		allowed:     map[string]bool{},
+		handler: func(request RunNetworkPermissionRequest) {
+			requests = append(requests, request)
+			if err := application.RespondRunNetworkPermission(ctx, request.RunID, NetworkAllowProject); err != nil {
+				t.Errorf("RespondRunNetworkPermission() error = %v", err)
+			}
+		},
+	}
+	connection := netmon.Connection{Protocol: netmon.ProtocolTCP, RemoteAddress: "203.0.113.5:443"}
+	application.checkRunConnection(ctx, gate, connection)
+	application.checkRunConnection(ctx, gate, netmon.Connection{Protocol: netmon.ProtocolTCP, RemoteAddress: "203.0.113.5:80"})
+	application.checkRunConnection(ctx, gate, netmon.Connection{Protocol: netmon.ProtocolTCP, RemoteAddress: "127.0.0.1:8080"})
+
+	if len(requests) != 1 || requests[0].Host != "203.0.113.5" || !requests[0].CanAllowProject {
+		t.Fatalf("requests = %+v, want one prompt for 203.0.113.5", requests)
+	}
+	record, err := application.projectRecordByPath(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("projectRecordByPath() error = %v", err)
+	}
+	if !slices.Equal(record.NetworkAllow, []string{"203.0.113.5"}) {
+		t.Fatalf("NetworkAllow = %v", record.NetworkAllow)
+	}
+}
+
+func TestCheckRunConnectionDenyStopsRun(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	ctx := context.Background()
+	canceled := false
+	application.runMu.Lock()
+	application.activeRuns = map[string]context.CancelFunc{"run-2": func() { canceled = true }}
+	application.runMu.Unlock()
+
+	gate := &runNetworkGate{
+		runID:   "run-2",
+		allowed: map[string]bool{},
+		handler: func(request RunNetworkPermissionRequest) {
+			if err := application.RespondRunNetworkPermission(ctx, request.RunID, NetworkAllowProject); err == nil {
+				t.Error("RespondRunNetworkPermission(allowProject) for scratch run error = nil")
+			}
+			if err := application.RespondRunNetworkPermission(ctx, request.RunID, NetworkDeny); err != nil {
+				t.Errorf("RespondRunNetworkPermission(deny) error = %v", err)
+			}
+		},
+	}
+	application.checkRunConnection(ctx, gate, netmon.Connection{Protocol: netmon.ProtocolTCP, RemoteAddress: "[2001:db8::1]:443"})
+
+	if !canceled {
+		t.Fatal("denied run was not canceled")
+	}
+	if !application.takeNetworkDenial("run-2") {
+		t.Fatal("takeNetworkDenial() = false, want true")
+	}
+	if err := application.RespondRunNetworkPermission(ctx, "run-2", NetworkAllowOnce); err == nil {
+		t.Fatal("RespondRunNetworkPermission() without a pending prompt error = nil")
+	}
+}
+
+func TestUpdateGlobalSettingsRefusesUnsupportedNetworkPrompts(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	gs := settings.Defaults()
+	gs.PromptRunNetwork = true
+	_, err := application.UpdateGlobalSettings(context.Background(), gs)
+	if supportErr := networkPromptSupport(); (supportErr == nil) != (err == nil) {
+		t.Fatalf("UpdateGlobalSettings() error = %v with platform support error %v", err, supportErr)
+	}
+}
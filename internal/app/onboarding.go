@@ -0,0 +1,38 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/project"
+)
+
+// AnalyzeProject suggests run targets, environment variables and tasks for
+// an open project. OpenProject includes the same analysis the first time a
+// project is opened; this runs it again on demand.
+func (a *Application) AnalyzeProject(ctx context.Context, projectPath string) (project.Onboarding, error) {
+	if err := ctx.Err(); err != nil {
+		return project.Onboarding{}, fmt.Errorf("analyze project context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return project.Onboarding{}, err
+	}
+	targets, err := project.DiscoverRunTargets(ctx, record.Path)
+	if err != nil {
+		return project.Onboarding{}, fmt.Errorf("discover run targets: %w", err)
+	}
+	envVars, err := a.store.ProjectEnvVars(ctx, record.ID)
+	if err != nil {
+		return project.Onboarding{}, fmt.Errorf("load project env vars: %w", err)
+	}
+	keys := make([]string, 0, len(envVars))
+	for _, variable := range envVars {
+		keys = append(keys, variable.Key)
+	}
+	onboarding, err := project.AnalyzeOnboarding(ctx, record.Path, targets, keys)
+	if err != nil {
+		return project.Onboarding{}, fmt.Errorf("analyze project: %w", err)
+	}
+	return onboarding, nil
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/lite"
+)
+
+// outlineFileName labels the snippet when it is parsed for its outline and
+// folding ranges.
+const outlineFileName = "snippet.go"
+
+// DocumentSymbols returns the function, type, constant and variable outline
+// of snippet source. The outline comes from the parser, so it follows
+// unsaved edits and works whether or not gopls is installed.
+func (a *Application) DocumentSymbols(ctx context.Context, source string) ([]lite.Symbol, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("document symbols context: %w", err)
+	}
+	return lite.Symbols(outlineFileName, []byte(source)), nil
+}
+
+// DocumentSymbolPath returns the symbols enclosing a zero-based line and
+// UTF-16 character of source, outermost first, for the editor breadcrumbs.
+func (a *Application) DocumentSymbolPath(ctx context.Context, source string, line int, character int) ([]lite.Symbol, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("document symbol path context: %w", err)
+	}
+	if line < 0 || character < 0 {
+		return nil, fmt.Errorf("position must not be negative")
+	}
+	symbols := lite.Symbols(outlineFileName, []byte(source))
+	return lite.SymbolPath(symbols, lite.Position{Line: line, Character: character}), nil
+}
+
+// FoldingRanges returns the foldable regions of snippet source, with IDs that
+// survive edits elsewhere in the buffer, so folding works without gopls.
+func (a *Application) FoldingRanges(ctx context.Context, source string) ([]lite.FoldingRange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("folding ranges context: %w", err)
+	}
+	return lite.FoldingRanges(outlineFileName, []byte(source)), nil
+}
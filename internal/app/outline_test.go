@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/lite"
+)
+
+func TestDocumentSymbolsOutlineSnippet(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	source := "package main\n\ntype Point struct {\n\tX, Y int\n}\n\nfunc (p Point) Sum() int {\n\treturn p.X + p.Y\n}\n\nfunc main() {\n"
+
+	symbols, err := application.DocumentSymbols(ctx, source)
+	if err != nil {
+		t.Fatalf("DocumentSymbols() error = %v", err)
+	}
+	if len(symbols) != 3 || symbols[0].Name != "Point" || len(symbols[0].Children) != 2 {
+		t.Fatalf("symbols = %+v, want Point with two fields, Sum and main", symbols)
+	}
+	if sum := symbols[1]; sum.Name != "(Point).Sum" || sum.Kind != lite.SymbolMethod || sum.Detail != "func() int" {
+		t.Fatalf("Sum symbol = %+v, want a method with its signature", sum)
+	}
+
+	path, err := application.DocumentSymbolPath(ctx, source, 3, 2)
+	if err != nil {
+		t.Fatalf("DocumentSymbolPath() error = %v", err)
+	}
+	if len(path) != 2 || path[0].Name != "Point" || path[1].Name != "X" {
+		t.Fatalf("path = %+v, want Point > X", path)
+	}
+	if path, _ := application.DocumentSymbolPath(ctx, source, 0, 0); len(path) != 0 {
+		t.Fatalf("path at package clause = %+v, want none", path)
+	}
+}
+
+func TestFoldingRangesForSnippet(t *testing.T) {
+	application := newTestApplication(t)
+	source := "package main\n\nimport (\n\t\"fmt\"\n)\n\n//region demo\nfunc main() {\n\tfmt.Println()\n}\n//endregion\n"
+
+	ranges, err := application.FoldingRanges(context.Background(), source)
+	if err != nil {
+		t.Fatalf("FoldingRanges() error = %v", err)
+	}
+	if len(ranges) != 3 || ranges[0].Kind != lite.FoldImports || ranges[1].ID != "region:demo" || ranges[2].ID != "func:main" {
+		t.Fatalf("ranges = %+v, want imports, the demo region and main", ranges)
+	}
+}
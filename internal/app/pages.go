@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/storage"
+)
+
+// RecentProjectsPage returns one page of recently opened projects matching
+// the request's filter. Pass the previous page's NextCursor to continue.
+func (a *Application) RecentProjectsPage(ctx context.Context, page storage.PageRequest) (storage.ProjectPage, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectPage{}, fmt.Errorf("recent projects page context: %w", err)
+	}
+	if a.projects == nil {
+		return storage.ProjectPage{}, fmt.Errorf("project service not initialized")
+	}
+	records, err := a.projects.RecentPage(ctx, page)
+	if err != nil {
+		return storage.ProjectPage{}, fmt.Errorf("recent projects: %w", err)
+	}
+	return records, nil
+}
+
+// ProjectSnippetsPage returns one page of a project's snippets matching the
+// request's filter.
+func (a *Application) ProjectSnippetsPage(ctx context.Context, projectPath string, page storage.PageRequest) (storage.SnippetPage, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.SnippetPage{}, fmt.Errorf("project snippets page context: %w", err)
+	}
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.SnippetPage{}, err
+	}
+	snippets, err := a.store.ProjectSnippetsPage(ctx, projectRecord.ID, page)
+	if err != nil {
+		return storage.SnippetPage{}, fmt.Errorf("load project snippets: %w", err)
+	}
+	return snippets, nil
+}
+
+// ProjectRunsPage returns one page of a project's run history matching the
+// request's filter, latest run first.
+func (a *Application) ProjectRunsPage(ctx context.Context, projectPath string, page storage.PageRequest) (storage.RunPage, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.RunPage{}, fmt.Errorf("project runs page context: %w", err)
+	}
+	projectRecord, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.RunPage{}, err
+	}
+	runs, err := a.store.ProjectRunsPage(ctx, projectRecord.ID, page)
+	if err != nil {
+		return storage.RunPage{}, fmt.Errorf("load project runs: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/plugins"
+)
+
+// Plugins describes the installed plugins and whether each is running.
+func (a *Application) Plugins(ctx context.Context) ([]plugins.Info, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list plugins context: %w", err)
+	}
+	if a.plugins == nil {
+		return []plugins.Info{}, nil
+	}
+	return a.plugins.Plugins(), nil
+}
+
+// ReloadPlugins stops every plugin, discovers the plugin directory again and
+// starts what it finds.
+func (a *Application) ReloadPlugins(ctx context.Context) ([]plugins.Info, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("reload plugins context: %w", err)
+	}
+	if a.plugins == nil {
+		return nil, fmt.Errorf("plugins not initialized")
+	}
+	if err := a.plugins.Start(ctx); err != nil {
+		return nil, fmt.Errorf("reload plugins: %w", err)
+	}
+	return a.plugins.Plugins(), nil
+}
+
+// RestartPlugin restarts one plugin, for example after it crashed.
+func (a *Application) RestartPlugin(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("restart plugin context: %w", err)
+	}
+	if a.plugins == nil {
+		return fmt.Errorf("plugins not initialized")
+	}
+	if err := a.plugins.Restart(ctx, strings.TrimSpace(name)); err != nil {
+		return fmt.Errorf("restart plugin: %w", err)
+	}
+	return nil
+}
+
+// ApprovePlugin grants a plugin the permissions its manifest requests and
+// starts it. Plugins are not started until the user approves them.
+func (a *Application) ApprovePlugin(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("approve plugin context: %w", err)
+	}
+	if a.plugins == nil {
+		return fmt.Errorf("plugins not initialized")
+	}
+	if err := a.plugins.Approve(ctx, strings.TrimSpace(name)); err != nil {
+		return fmt.Errorf("approve plugin: %w", err)
+	}
+	return nil
+}
+
+// RunPluginCommand runs a command a plugin registered and returns its
+// output. args is passed to the plugin as JSON; empty means none.
+func (a *Application) RunPluginCommand(ctx context.Context, plugin string, command string, args string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("run plugin command context: %w", err)
+	}
+	if a.plugins == nil {
+		return "", fmt.Errorf("plugins not initialized")
+	}
+	var raw json.RawMessage
+	if trimmed := strings.TrimSpace(args); trimmed != "" {
+		if !json.Valid([]byte(trimmed)) {
+			return "", fmt.Errorf("plugin command arguments must be JSON")
+		}
+		raw = json.RawMessage(trimmed)
+	}
+	output, err := a.plugins.RunCommand(ctx, strings.TrimSpace(plugin), strings.TrimSpace(command), raw)
+	if err != nil {
+		return "", fmt.Errorf("run plugin command: %w", err)
+	}
+	return output, nil
+}
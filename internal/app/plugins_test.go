@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/plugins"
+)
+
+func TestPluginsReportInvalidManifests(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	if _, err := application.RunPluginCommand(ctx, "tools", "format", ""); err == nil {
+		t.Fatal("RunPluginCommand() without a plugin host error = nil")
+	}
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "broken"), 0o755); err != nil {
+		t.Fatalf("create plugin dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "broken", plugins.ManifestFile), []byte(`{"name":"broken"}`), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	application.plugins = plugins.NewHost(root, "test", nil)
+	defer application.plugins.Stop()
+
+	infos, err := application.ReloadPlugins(ctx)
+	if err != nil {
+		t.Fatalf("ReloadPlugins() error = %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "broken" || infos[0].State != plugins.StateFailed || infos[0].Error == "" {
+		t.Fatalf("ReloadPlugins() = %+v, want broken plugin failed", infos)
+	}
+	if _, err := application.RunPluginCommand(ctx, "broken", "format", "{"); err == nil {
+		t.Fatal("RunPluginCommand() with invalid JSON args error = nil")
+	}
+	if _, err := application.RunPluginCommand(ctx, "broken", "format", `{"tab":4}`); err == nil {
+		t.Fatal("RunPluginCommand() on an invalid plugin error = nil")
+	}
+}
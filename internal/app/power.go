@@ -0,0 +1,173 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gopoke/internal/idle"
+	"gopoke/internal/power"
+	"gopoke/internal/settings"
+)
+
+// powerPollInterval is how often the power source is read.
+const powerPollInterval = time.Minute
+
+// powerWatch tracks the machine's power source. The zero value reads
+// power.Detect and is not polling.
+type powerWatch struct {
+	mu     sync.Mutex
+	state  power.State
+	detect func() power.State // test override; nil uses power.Detect
+	gate   idle.Gate          // pauses polling while the user is idle
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func (w *powerWatch) read() power.State {
+	if w.detect != nil {
+		return w.detect()
+	}
+	return power.Detect()
+}
+
+func (w *powerWatch) current() power.State {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state.Source == "" {
+		return power.State{Source: power.SourceUnknown, Percent: -1}
+	}
+	return w.state
+}
+
+// PowerStatus describes the power source and what power saving changed.
+type PowerStatus struct {
+	power.State
+	// Mode is the PowerSaving setting.
+	Mode string `json:"mode"`
+	// Saving reports whether background work is currently reduced.
+	Saving bool `json:"saving"`
+	// Adjustments describe each reduced setting, empty when not saving.
+	Adjustments []string `json:"adjustments"`
+}
+
+// startPowerWatch reads the power source and polls it for changes, applying
+// settings again whenever it switches between AC and battery.
+func (a *Application) startPowerWatch() {
+	a.power.mu.Lock()
+	defer a.power.mu.Unlock()
+	if a.power.stop != nil {
+		return
+	}
+	a.power.state = a.power.read()
+	a.power.stop = make(chan struct{})
+	a.power.done = make(chan struct{})
+	go a.watchPower(a.power.stop, a.power.done)
+}
+
+// stopPowerWatch stops polling the power source.
+func (a *Application) stopPowerWatch() {
+	a.power.mu.Lock()
+	stop, done := a.power.stop, a.power.done
+	a.power.stop, a.power.done = nil, nil
+	a.power.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (a *Application) watchPower(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(powerPollInterval)
+	defer ticker.Stop()
+	for {
+		if resumed := a.power.gate.Paused(); resumed != nil {
+			select {
+			case <-stop:
+				return
+			case <-resumed:
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		a.refreshPowerState(context.Background())
+	}
+}
+
+// refreshPowerState reads the power source and, when it switched, applies
+// settings again so power saving follows it.
+func (a *Application) refreshPowerState(ctx context.Context) {
+	state := a.power.read()
+	a.power.mu.Lock()
+	previous := a.power.state
+	a.power.state = state
+	a.power.mu.Unlock()
+	if state.Source == previous.Source {
+		return
+	}
+	a.logger.Info("power source changed", "source", state.Source, "percent", state.Percent)
+	gs, err := a.store.GetSettings(ctx)
+	if err != nil {
+		a.logger.Warn("load global settings for power saving", "error", err)
+		return
+	}
+	a.applyRuntimeSettings(gs)
+}
+
+// powerSaving reports whether settings ask for reduced background work in
+// the given power state.
+func powerSaving(gs settings.GlobalSettings, state power.State) bool {
+	switch gs.PowerSaving {
+	case settings.PowerSavingAlways:
+		return true
+	case settings.PowerSavingNever:
+		return false
+	default:
+		return state.OnBattery()
+	}
+}
+
+// powerAdjusted reduces the expensive parts of gs while power saving: the
+// worker pool shrinks to BatteryMaxWorkers and idle pausing starts sooner.
+// It also returns a description of each change.
+func powerAdjusted(gs settings.GlobalSettings, state power.State) (settings.GlobalSettings, []string) {
+	adjustments := []string{}
+	if !powerSaving(gs, state) {
+		return gs, adjustments
+	}
+	if limit := max(gs.BatteryMaxWorkers, 1); gs.WorkerMaxCount > limit {
+		gs.WorkerMaxCount = limit
+		adjustments = append(adjustments, fmt.Sprintf("worker pool limited to %d", limit))
+	}
+	if gs.IdlePauseMinutes != settings.IdlePauseNever && gs.IdlePauseMinutes > settings.BatteryIdlePauseMinutes {
+		gs.IdlePauseMinutes = settings.BatteryIdlePauseMinutes
+		adjustments = append(adjustments, fmt.Sprintf("background work pauses after %d minutes idle", settings.BatteryIdlePauseMinutes))
+	}
+	return gs, adjustments
+}
+
+// PowerStatus reports the power source and whether background work is
+// reduced for it.
+func (a *Application) PowerStatus(ctx context.Context) (PowerStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return PowerStatus{}, fmt.Errorf("power status context: %w", err)
+	}
+	gs, err := a.store.GetSettings(ctx)
+	if err != nil {
+		return PowerStatus{}, fmt.Errorf("load global settings: %w", err)
+	}
+	state := a.power.current()
+	_, adjustments := powerAdjusted(gs, state)
+	return PowerStatus{
+		State:       state,
+		Mode:        gs.PowerSaving,
+		Saving:      powerSaving(gs, state),
+		Adjustments: adjustments,
+	}, nil
+}
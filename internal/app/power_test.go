@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/power"
+	"gopoke/internal/settings"
+)
+
+func TestPowerSavingFollowsBatteryAndOverrides(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	state := power.State{Source: power.SourceAC, Percent: 90}
+	application.power.detect = func() power.State { return state }
+	application.refreshPowerState(context.Background())
+
+	status, err := application.PowerStatus(context.Background())
+	if err != nil {
+		t.Fatalf("PowerStatus() error = %v", err)
+	}
+	if status.Source != power.SourceAC || status.Mode != settings.PowerSavingAuto || status.Saving || len(status.Adjustments) != 0 {
+		t.Fatalf("PowerStatus() on AC = %+v", status)
+	}
+
+	state = power.State{Source: power.SourceBattery, Percent: 40}
+	application.refreshPowerState(context.Background())
+	status, err = application.PowerStatus(context.Background())
+	if err != nil {
+		t.Fatalf("PowerStatus() error = %v", err)
+	}
+	if !status.Saving || status.Percent != 40 || len(status.Adjustments) != 2 {
+		t.Fatalf("PowerStatus() on battery = %+v", status)
+	}
+
+	gs := settings.Defaults()
+	adjusted, _ := powerAdjusted(gs, state)
+	if adjusted.WorkerMaxCount != settings.DefaultBatteryMaxWorkers || adjusted.IdlePauseMinutes != settings.BatteryIdlePauseMinutes {
+		t.Fatalf("powerAdjusted() on battery = workers %d, idle %d", adjusted.WorkerMaxCount, adjusted.IdlePauseMinutes)
+	}
+	gs.IdlePauseMinutes = settings.IdlePauseNever
+	if adjusted, _ := powerAdjusted(gs, state); adjusted.IdlePauseMinutes != settings.IdlePauseNever {
+		t.Fatalf("powerAdjusted() overrode idle pause never: %d", adjusted.IdlePauseMinutes)
+	}
+
+	gs.PowerSaving = settings.PowerSavingNever
+	if adjusted, changes := powerAdjusted(gs, state); adjusted.WorkerMaxCount != gs.WorkerMaxCount || len(changes) != 0 {
+		t.Fatalf("powerAdjusted() with power saving never = %+v, %v", adjusted, changes)
+	}
+	gs.PowerSaving = settings.PowerSavingAlways
+	if adjusted, _ := powerAdjusted(gs, power.State{Source: power.SourceAC}); adjusted.WorkerMaxCount != settings.DefaultBatteryMaxWorkers {
+		t.Fatalf("powerAdjusted() with power saving always on AC = workers %d", adjusted.WorkerMaxCount)
+	}
+}
@@ -0,0 +1,12 @@
+package app
+
+import "gopoke/internal/execution"
+
+// runLowPriority reports whether a run starts at reduced priority: the
+// request decides when it says so, the LowPriorityRuns setting otherwise.
+func (a *Application) runLowPriority(request execution.RunRequest) bool {
+	if request.LowPriority != nil {
+		return *request.LowPriority
+	}
+	return a.lowPriorityRuns.Load()
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestRunLowPriorityFollowsSettingUnlessRequested(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+
+	if application.runLowPriority(execution.RunRequest{}) {
+		t.Fatal("runLowPriority() = true with the setting off")
+	}
+	gs, err := application.GetGlobalSettings(ctx)
+	if err != nil {
+		t.Fatalf("GetGlobalSettings() error = %v", err)
+	}
+	gs.LowPriorityRuns = true
+	if _, err := application.UpdateGlobalSettings(ctx, gs); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+	if !application.runLowPriority(execution.RunRequest{}) {
+		t.Fatal("runLowPriority() = false with the setting on")
+	}
+	normal := false
+	if application.runLowPriority(execution.RunRequest{LowPriority: &normal}) {
+		t.Fatal("runLowPriority() = true for a run that opted out")
+	}
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/procmem"
+)
+
+// ActiveRunProcesses lists the process tree an active run has started, root
+// first. The tree is empty until the run's process starts and for backends
+// that start none.
+func (a *Application) ActiveRunProcesses(ctx context.Context, runID string) ([]procmem.Process, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("active run processes context: %w", err)
+	}
+	pid, err := a.activeRunPID(runID)
+	if err != nil {
+		return nil, err
+	}
+	if pid == 0 {
+		return []procmem.Process{}, nil
+	}
+	tree, err := procmem.ProcessTree(pid)
+	if err != nil {
+		return nil, fmt.Errorf("inspect run processes: %w", err)
+	}
+	return tree, nil
+}
+
+// KillRunProcess kills one process of an active run's tree. Killing the root
+// ends the run the same way the process exiting would.
+func (a *Application) KillRunProcess(ctx context.Context, runID string, pid int) (err error) {
+	defer func() {
+		a.recordAudit(audit.ActionKillProcess, "", map[string]string{"runId": runID, "pid": strconv.Itoa(pid)}, err)
+	}()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("kill run process context: %w", err)
+	}
+	tree, err := a.ActiveRunProcesses(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(tree, func(process procmem.Process) bool { return process.PID == pid }) {
+		return fmt.Errorf("process %d does not belong to run %q", pid, runID)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("find process %d: %w", pid, err)
+	}
+	if err := process.Kill(); err != nil {
+		return fmt.Errorf("kill process %d: %w", pid, err)
+	}
+	a.logger.Info("killed run process", "runID", runID, "pid", pid)
+	return nil
+}
+
+func (a *Application) setActiveRunPID(runID string, pid int) {
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+	if a.runPIDs == nil {
+		a.runPIDs = make(map[string]int)
+	}
+	a.runPIDs[runID] = pid
+}
+
+// activeRunPID returns the root pid of an active run, or zero when it has
+// not started a process.
+func (a *Application) activeRunPID(runID string) (int, error) {
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return 0, fmt.Errorf("run id is required")
+	}
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+	if _, ok := a.activeRuns[runID]; !ok {
+		return 0, fmt.Errorf("run %q is not active", runID)
+	}
+	return a.runPIDs[runID], nil
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/testutil"
+)
+
+func TestActiveRunProcessesListsAndKillsRunChildren(t *testing.T) {
+	requireGoToolchain(t)
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("process listing unsupported")
+	}
+
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+
+	runCtx, cancel := testutil.TestRunContext(t)
+	defer cancel()
+	ready := make(chan struct{})
+	var readyOnce bool
+	done := make(chan execution.Result, 1)
+	go func() {
+		result, err := application.RunSnippet(runCtx, execution.RunRequest{
+			RunID:       "run-tree",
+			ProjectPath: projectDir,
+			Source:      "package main\n\nimport (\n\t\"fmt\"\n\t\"time\"\n)\n\nfunc main() {\n\tfmt.Println(\"ready\")\n\ttime.Sleep(time.Hour)\n}\n",
+		}, func(chunk string) {
+			if !readyOnce && strings.Contains(chunk, "ready") {
+				readyOnce = true
+				close(ready)
+			}
+		}, nil)
+		if err != nil {
+			t.Errorf("RunSnippet() error = %v", err)
+		}
+		done <- result
+	}()
+
+	select {
+	case <-ready:
+	case <-runCtx.Done():
+		t.Fatal("snippet did not start")
+	}
+
+	tree, err := application.ActiveRunProcesses(context.Background(), "run-tree")
+	if err != nil {
+		t.Fatalf("ActiveRunProcesses() error = %v", err)
+	}
+	if len(tree) < 2 {
+		t.Fatalf("tree = %+v, want go run and the snippet binary", tree)
+	}
+	if err := application.KillRunProcess(context.Background(), "run-tree", os.Getpid()); err == nil {
+		t.Fatal("KillRunProcess(foreign pid) error = nil, want error")
+	}
+
+	snippet := tree[len(tree)-1]
+	if err := application.KillRunProcess(context.Background(), "run-tree", snippet.PID); err != nil {
+		t.Fatalf("KillRunProcess() error = %v", err)
+	}
+	result := <-done
+	if result.ExitCode == 0 || result.Canceled {
+		t.Fatalf("result = %+v, want the run to fail after its child was killed", result)
+	}
+
+	if _, err := application.ActiveRunProcesses(context.Background(), "run-tree"); err == nil {
+		t.Fatal("ActiveRunProcesses(finished run) error = nil, want error")
+	}
+}
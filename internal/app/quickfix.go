@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/diagnostics"
+	"gopoke/internal/execution"
+	"gopoke/internal/formatting"
+)
+
+// ApplyQuickFix applies the deterministic fix for a run diagnostic (unused
+// import, unused variable or missing return) to source and returns the
+// patched, formatted source. It works without gopls attached to the buffer.
+func (a *Application) ApplyQuickFix(ctx context.Context, source string, diagnostic execution.Diagnostic) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("apply quick fix context: %w", err)
+	}
+	if diagnostic.Kind != diagnostics.KindCompile {
+		return "", fmt.Errorf("apply quick fix: %w", formatting.ErrNoQuickFix)
+	}
+	kind, args := diagnostics.Classify(diagnostic.Message)
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+	patched, err := formatting.ApplyQuickFix(source, kind, diagnostic.Line, diagnostic.Column, name)
+	if err != nil {
+		return "", fmt.Errorf("apply quick fix: %w", err)
+	}
+	return patched, nil
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/testutil"
+)
+
+func TestApplyQuickFixResolvesRunDiagnostics(t *testing.T) {
+	requireGoToolchain(t)
+
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	source := "package main\n\nimport \"os\"\n\nfunc main() {\n\tx := 1\n\tprintln(\"fixed\")\n}\n"
+	for attempt := 0; attempt < 3; attempt++ {
+		runCtx, runCancel := testutil.TestRunContext(t)
+		result, err := application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil)
+		runCancel()
+		if err != nil {
+			t.Fatalf("RunSnippet() error = %v", err)
+		}
+		if len(result.Diagnostics) == 0 {
+			if result.ExitCode != 0 || result.Stderr != "fixed\n" {
+				t.Fatalf("run after fixes = %+v, want success", result)
+			}
+			return
+		}
+		source, err = application.ApplyQuickFix(context.Background(), source, result.Diagnostics[0])
+		if err != nil {
+			t.Fatalf("ApplyQuickFix(%q) error = %v", result.Diagnostics[0].Message, err)
+		}
+	}
+	t.Fatalf("diagnostics remain after quick fixes:\n%s", source)
+}
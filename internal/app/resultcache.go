@@ -0,0 +1,289 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+)
+
+// RunCacheDirective marks a snippet as deterministic: an unchanged run may
+// be answered with the previous result instead of running again.
+const RunCacheDirective = "//gopoke:cacheable"
+
+// maxCachedResults bounds the run cache; the least recently used entry is
+// evicted first.
+const maxCachedResults = 64
+
+// runCache holds results of cacheable runs in memory, keyed by everything
+// that decides their outcome.
+type runCache struct {
+	mu      sync.Mutex
+	entries map[string]runCacheEntry
+	order   []string // least recently used first
+}
+
+type runCacheEntry struct {
+	projectID string // empty for scratch runs
+	result    execution.Result
+}
+
+func (c *runCache) get(key string) (execution.Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return execution.Result{}, false
+	}
+	c.touchLocked(key)
+	return entry.result, true
+}
+
+func (c *runCache) put(key string, projectID string, result execution.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]runCacheEntry)
+	}
+	c.entries[key] = runCacheEntry{projectID: projectID, result: result}
+	c.touchLocked(key)
+	for len(c.order) > maxCachedResults {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+func (c *runCache) touchLocked(key string) {
+	c.order = slices.DeleteFunc(c.order, func(existing string) bool { return existing == key })
+	c.order = append(c.order, key)
+}
+
+// clear drops entries of projectID, or every entry when it is empty, and
+// reports how many were dropped.
+func (c *runCache) clear(projectID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for key, entry := range c.entries {
+		if projectID == "" || entry.projectID == projectID {
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	c.order = slices.DeleteFunc(c.order, func(key string) bool {
+		_, kept := c.entries[key]
+		return !kept
+	})
+	return removed
+}
+
+// ClearRunCache forgets cached run results of a project, or of every
+// project when projectPath is empty, and reports how many were dropped.
+func (a *Application) ClearRunCache(ctx context.Context, projectPath string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("clear run cache context: %w", err)
+	}
+	if strings.TrimSpace(projectPath) == "" {
+		return a.runCache.clear(""), nil
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return 0, err
+	}
+	return a.runCache.clear(record.ID), nil
+}
+
+// snippetCacheable reports whether source carries RunCacheDirective on a
+// line of its own.
+func snippetCacheable(source string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(source))
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == RunCacheDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheableResult reports whether a run's result may be replayed. Only
+// clean, complete runs are kept.
+func cacheableResult(result execution.Result) bool {
+	return result.ExitCode == 0 && !result.TimedOut && !result.Canceled && !result.ConfirmationRequired && result.TeeError == "" &&
+		result.ModuleOverlay == nil
+}
+
+// runCacheKey hashes everything that decides a run's outcome: the source,
+// toolchain, backend, environment, arguments, package files, the sources
+// of the on-disk packages they import, run overrides, CPU set, limits and
+// the project's module files.
+func (a *Application) runCacheKey(ctx context.Context, request execution.RunRequest, resolved resolvedRunRequest) string {
+	hash := sha256.New()
+	field := func(name string, value string) {
+		fmt.Fprintf(hash, "%s=%d:%s\n", name, len(value), value)
+	}
+	field("source", resolved.source)
+	field("backend", a.executionBackend().Name())
+	field("toolchain", resolved.toolchain)
+	field("project", resolved.projectPath)
+	field("workdir", resolved.workingDirectory)
+	for _, name := range slices.Sorted(maps.Keys(resolved.environment)) {
+		field("env."+name, resolved.environment[name])
+	}
+	for _, arg := range resolved.args {
+		field("arg", arg)
+	}
+	for _, file := range resolved.files {
+		contents, _ := os.ReadFile(file)
+		field("file."+file, string(contents))
+	}
+	for _, file := range importedPackageFiles(ctx, resolved.projectPath, resolved.source, resolved.files) {
+		contents, _ := os.ReadFile(file)
+		field("import."+file, string(contents))
+	}
+	field("timeZone", request.TimeZone)
+	field("locale", request.Locale)
+	if resolved.seed != nil {
+		field("seed", strconv.FormatInt(*resolved.seed, 10))
+	}
+	if !resolved.frozenTime.IsZero() {
+		field("frozenTime", resolved.frozenTime.String())
+	}
+	for _, cpu := range resolved.cpuAffinity {
+		field("cpu", strconv.Itoa(cpu))
+	}
+	field("timeout", resolved.timeout.String())
+	field("maxOutput", strconv.FormatInt(resolved.limits.MaxOutputBytes, 10))
+	field("maxDiskWrite", strconv.FormatInt(resolved.limits.MaxDiskWriteBytes, 10))
+	field("maxProcesses", strconv.FormatInt(resolved.limits.MaxProcesses, 10))
+	field("maxOpenFiles", strconv.FormatInt(resolved.limits.MaxOpenFiles, 10))
+	field("encoding", resolved.outputEncoding)
+	field("plainText", strconv.FormatBool(a.plainText.Load()))
+	for _, name := range []string{"go.mod", "go.sum", "go.work"} {
+		contents, err := os.ReadFile(filepath.Join(resolved.projectPath, name))
+		if err == nil {
+			field(name, string(contents))
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// importedPackageFiles lists, sorted, the files of every package the
+// snippet and its package files import, directly or through one another,
+// that lives on disk: in the project's module, a go.work module or a local
+// replacement. Test files are left out; module cache packages are covered
+// by go.sum.
+func importedPackageFiles(ctx context.Context, projectPath string, source string, packageFiles []string) []string {
+	roots := localModuleRoots(ctx, projectPath)
+	if len(roots) == 0 {
+		return nil
+	}
+
+	fileSet := token.NewFileSet()
+	var pending []string
+	addImports := func(name string, src any) {
+		file, err := parser.ParseFile(fileSet, name, src, parser.ImportsOnly)
+		if err != nil {
+			return
+		}
+		for _, spec := range file.Imports {
+			if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+				pending = append(pending, importPath)
+			}
+		}
+	}
+	addImports("snippet.go", source)
+	for _, file := range packageFiles {
+		addImports(file, nil)
+	}
+
+	var files []string
+	visited := make(map[string]bool)
+	for len(pending) > 0 {
+		importPath := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		dir, ok := localPackageDir(importPath, roots)
+		if !ok || visited[dir] {
+			continue
+		}
+		visited[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.Type().IsRegular() || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			files = append(files, path)
+			if strings.HasSuffix(name, ".go") {
+				addImports(path, nil)
+			}
+		}
+	}
+	slices.Sort(files)
+	return files
+}
+
+// localModuleRoots maps the module paths whose sources are on disk for a
+// project to their directories.
+func localModuleRoots(ctx context.Context, projectPath string) map[string]string {
+	roots := make(map[string]string)
+	goMod, err := project.ParseGoMod(ctx, projectPath)
+	if err == nil {
+		if goMod.ModulePath != "" {
+			roots[goMod.ModulePath] = projectPath
+		}
+		for _, replace := range goMod.Replaces {
+			if replace.NewVersion == "" {
+				roots[replace.OldPath] = resolveModuleDir(projectPath, replace.NewPath)
+			}
+		}
+	}
+	if goWork, err := project.ParseGoWork(ctx, projectPath); err == nil {
+		for _, use := range goWork.Uses {
+			dir := resolveModuleDir(projectPath, use.Path)
+			if useMod, err := project.ParseGoMod(ctx, dir); err == nil && useMod.ModulePath != "" {
+				roots[useMod.ModulePath] = dir
+			}
+		}
+	}
+	return roots
+}
+
+func resolveModuleDir(projectPath string, dir string) string {
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(projectPath, filepath.FromSlash(dir))
+}
+
+// localPackageDir returns the directory of importPath within the module in
+// roots with the longest matching path.
+func localPackageDir(importPath string, roots map[string]string) (string, bool) {
+	best := ""
+	for modulePath := range roots {
+		if (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")) && len(modulePath) > len(best) {
+			best = modulePath
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	rel := strings.TrimPrefix(strings.TrimPrefix(importPath, best), "/")
+	return filepath.Join(roots[best], filepath.FromSlash(rel)), true
+}
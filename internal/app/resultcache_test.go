@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+// countingBackend is a fake backend that counts the snippets it runs.
+type countingBackend struct {
+	execution.FakeBackend
+	runs int
+}
+
+func (b *countingBackend) Run(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+	b.runs++
+	return b.FakeBackend.Run(ctx, projectPath, snippet, options)
+}
+
+func TestRunCacheReplaysDeterministicRuns(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	backend := &countingBackend{}
+	application.backend = backend
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	cacheable := "package main\n\n//gopoke:cacheable\n//stdout: 42\nfunc main() {}\n"
+	run := func(request execution.RunRequest) (execution.Result, string) {
+		t.Helper()
+		request.ProjectPath = projectDir
+		streamed := ""
+		result, err := application.RunSnippet(ctx, request, func(chunk string) { streamed += chunk }, nil)
+		if err != nil {
+			t.Fatalf("RunSnippet() error = %v", err)
+		}
+		return result, streamed
+	}
+
+	first, _ := run(execution.RunRequest{Source: cacheable})
+	second, streamed := run(execution.RunRequest{Source: cacheable})
+	if backend.runs != 1 || first.Cached || !second.Cached {
+		t.Fatalf("runs = %d, cached = %t/%t; want one run and a replay", backend.runs, first.Cached, second.Cached)
+	}
+	if second.Stdout != first.Stdout || streamed != first.Stdout {
+		t.Fatalf("replay stdout = %q streamed %q, want %q", second.Stdout, streamed, first.Stdout)
+	}
+
+	// A different environment, a refresh or an unmarked snippet runs again.
+	if _, err := application.UpsertProjectEnvVar(ctx, projectDir, "MODE", "fast", false); err != nil {
+		t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+	}
+	if result, _ := run(execution.RunRequest{Source: cacheable}); result.Cached {
+		t.Fatal("run after env change was cached")
+	}
+	if result, _ := run(execution.RunRequest{Source: cacheable, RefreshCache: true}); result.Cached {
+		t.Fatal("refreshed run was cached")
+	}
+	plain := "package main\n\n//stdout: 42\nfunc main() {}\n"
+	run(execution.RunRequest{Source: plain})
+	if result, _ := run(execution.RunRequest{Source: plain}); result.Cached {
+		t.Fatal("unmarked snippet was cached")
+	}
+	if backend.runs != 5 {
+		t.Fatalf("runs = %d, want 5", backend.runs)
+	}
+
+	removed, err := application.ClearRunCache(ctx, filepath.Clean(projectDir))
+	if err != nil || removed != 2 {
+		t.Fatalf("ClearRunCache() = %d, %v; want two entries", removed, err)
+	}
+	if result, _ := run(execution.RunRequest{Source: cacheable}); result.Cached {
+		t.Fatal("run after clearing the cache was cached")
+	}
+}
+
+func TestRunCacheSkipsFailedRuns(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	backend := &countingBackend{}
+	application.backend = backend
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	source := "package main\n\n//gopoke:cacheable\n//exit: 1\nfunc main() {}\n"
+	for range 2 {
+		result, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil)
+		if err != nil || result.Cached {
+			t.Fatalf("RunSnippet() = cached %t, %v; want a fresh failed run", result.Cached, err)
+		}
+	}
+	if backend.runs != 2 {
+		t.Fatalf("runs = %d, want 2", backend.runs)
+	}
+}
+
+func TestRunCacheKeyCoversImportedProjectPackages(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	backend := &countingBackend{}
+	application.backend = backend
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	writeTestFile(t, filepath.Join(projectDir, "greet", "greet.go"), "package greet\n\nimport \"example.com/gopoketest/greet/words\"\n\nfunc Hello() string { return words.Hi }\n")
+	writeTestFile(t, filepath.Join(projectDir, "greet", "words", "words.go"), "package words\n\nconst Hi = \"hi\"\n")
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	source := "package main\n\nimport \"example.com/gopoketest/greet\"\n\n//gopoke:cacheable\nfunc main() { println(greet.Hello()) }\n"
+	run := func() execution.Result {
+		t.Helper()
+		result, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil)
+		if err != nil {
+			t.Fatalf("RunSnippet() error = %v", err)
+		}
+		return result
+	}
+
+	run()
+	if !run().Cached {
+		t.Fatal("unchanged run was not cached")
+	}
+	// Editing a package the snippet reaches only transitively invalidates it.
+	writeTestFile(t, filepath.Join(projectDir, "greet", "words", "words.go"), "package words\n\nconst Hi = \"hello\"\n")
+	if run().Cached {
+		t.Fatal("run after editing an imported project package was cached")
+	}
+	// Test files do not change what the snippet builds.
+	writeTestFile(t, filepath.Join(projectDir, "greet", "greet_test.go"), "package greet\n")
+	if !run().Cached {
+		t.Fatal("run after adding a test file was not cached")
+	}
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"gopoke/internal/project"
+	"gopoke/internal/storage"
+)
+
+// runEnvSettings are variables that configure the go command or runtime
+// rather than carry data; run snapshots keep their values verbatim.
+var runEnvSettings = []string{
+	"CGO_ENABLED", "GOAMD64", "GOARCH", "GOARM", "GOARM64", "GODEBUG",
+	"GOEXPERIMENT", "GOFLAGS", "GOGC", "GOMAXPROCS", "GOMEMLIMIT", "GOOS",
+	"GOTOOLCHAIN",
+}
+
+// toolchainVersionCache remembers the go version of each toolchain binary
+// until the binary is replaced.
+type toolchainVersionCache struct {
+	mu      sync.Mutex
+	entries map[string]toolchainVersionEntry
+}
+
+type toolchainVersionEntry struct {
+	modTime time.Time
+	size    int64
+	version string
+}
+
+func (c *toolchainVersionCache) lookup(ctx context.Context, binaryPath string) string {
+	info, err := os.Stat(binaryPath)
+	if err != nil {
+		return project.ToolchainVersion(ctx, binaryPath)
+	}
+	c.mu.Lock()
+	entry, ok := c.entries[binaryPath]
+	c.mu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.version
+	}
+	version := project.ToolchainVersion(ctx, binaryPath)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]toolchainVersionEntry)
+	}
+	c.entries[binaryPath] = toolchainVersionEntry{modTime: info.ModTime(), size: info.Size(), version: version}
+	return version
+}
+
+// runEnvironment snapshots the environment of a project run for its history
+// record. Variable values are redacted as described on storage.RunEnvVar;
+// when the project's masked flags cannot be read every variable counts as
+// masked. Scratch runs keep no history and get no snapshot.
+func (a *Application) runEnvironment(ctx context.Context, resolved resolvedRunRequest) *storage.RunEnvironment {
+	if resolved.projectID == "" {
+		return nil
+	}
+	masked := make(map[string]bool)
+	maskAll := false
+	records, err := a.store.ProjectEnvVars(ctx, resolved.projectID)
+	if err != nil {
+		a.logger.Warn("load project env for run snapshot failed", "projectID", resolved.projectID, "error", err)
+		maskAll = true
+	}
+	for _, record := range records {
+		masked[record.Key] = record.Masked
+	}
+
+	variables := make([]storage.RunEnvVar, 0, len(resolved.environment))
+	for key, value := range resolved.environment {
+		variable := storage.RunEnvVar{Key: key}
+		switch {
+		case maskAll || masked[key]:
+			variable.Masked = true
+		case slices.Contains(runEnvSettings, key):
+			variable.Value = value
+		default:
+			variable.Fingerprint = envFingerprint(value)
+		}
+		variables = append(variables, variable)
+	}
+	slices.SortFunc(variables, func(a storage.RunEnvVar, b storage.RunEnvVar) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if value := resolved.environment["GOOS"]; value != "" {
+		goos = value
+	}
+	if value := resolved.environment["GOARCH"]; value != "" {
+		goarch = value
+	}
+	return &storage.RunEnvironment{
+		ToolchainVersion: a.toolchainVersions.lookup(ctx, resolved.toolchain),
+		GOOS:             goos,
+		GOARCH:           goarch,
+		WorkingDirectory: resolved.workingDirectory,
+		Variables:        variables,
+	}
+}
+
+// envFingerprint is a short digest of a variable value; equal fingerprints
+// mean the value did not change between runs.
+func envFingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:6])
+}
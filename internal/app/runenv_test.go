@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/storage"
+)
+
+func TestRunSnippetRecordsRedactedEnvironment(t *testing.T) {
+	requireGoToolchain(t)
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	opened, err := application.OpenProject(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	for _, variable := range []storage.EnvVarRecord{
+		{Key: "API_TOKEN", Value: "s3cret", Masked: true},
+		{Key: "GREETING", Value: "hello"},
+		{Key: "GOFLAGS", Value: "-trimpath"},
+	} {
+		if _, err := application.UpsertProjectEnvVar(ctx, projectDir, variable.Key, variable.Value, variable.Masked); err != nil {
+			t.Fatalf("UpsertProjectEnvVar(%s) error = %v", variable.Key, err)
+		}
+	}
+
+	request := execution.RunRequest{ProjectPath: projectDir, Source: "package main\n\nfunc main() {}\n"}
+	if _, err := application.RunSnippet(ctx, request, nil, nil); err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if _, err := application.UpsertProjectEnvVar(ctx, projectDir, "GREETING", "goodbye", false); err != nil {
+		t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+	}
+	if _, err := application.RunSnippet(ctx, request, nil, nil); err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+
+	runs, err := application.store.ProjectRuns(ctx, opened.Project.ID, 10)
+	if err != nil {
+		t.Fatalf("ProjectRuns() error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].Environment == nil || runs[1].Environment == nil {
+		t.Fatalf("runs = %+v, want two runs with environment snapshots", runs)
+	}
+	snapshot := runs[0].Environment
+	if !strings.HasPrefix(snapshot.ToolchainVersion, "go version") {
+		t.Fatalf("ToolchainVersion = %q, want go version output", snapshot.ToolchainVersion)
+	}
+	if snapshot.GOOS != runtime.GOOS || snapshot.GOARCH != runtime.GOARCH || snapshot.WorkingDirectory == "" {
+		t.Fatalf("snapshot = %+v, want host platform and a working directory", snapshot)
+	}
+	variables := make(map[string]storage.RunEnvVar)
+	for _, variable := range snapshot.Variables {
+		variables[variable.Key] = variable
+	}
+	if token := variables["API_TOKEN"]; !token.Masked || token.Value != "" || token.Fingerprint != "" {
+		t.Fatalf("API_TOKEN = %+v, want masked with nothing kept", token)
+	}
+	if flags := variables["GOFLAGS"]; flags.Value != "-trimpath" {
+		t.Fatalf("GOFLAGS = %+v, want its value kept", flags)
+	}
+	greeting := variables["GREETING"]
+	if greeting.Value != "" || greeting.Fingerprint == "" {
+		t.Fatalf("GREETING = %+v, want only a fingerprint", greeting)
+	}
+	for _, variable := range runs[1].Environment.Variables {
+		if variable.Key == "GREETING" && variable.Fingerprint == greeting.Fingerprint {
+			t.Fatal("GREETING fingerprint did not change with its value")
+		}
+	}
+
+	view, err := shareSource{app: application, project: opened.Project}.View(ctx)
+	if err != nil {
+		t.Fatalf("View() error = %v", err)
+	}
+	for _, run := range view.Runs {
+		if run.Environment != nil {
+			t.Fatalf("shared run %s carries an environment snapshot", run.ID)
+		}
+	}
+}
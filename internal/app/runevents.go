@@ -0,0 +1,172 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/execution"
+)
+
+// Run event types written by ExportRunEvents.
+const (
+	RunEventStarted  = "started"
+	RunEventStdout   = "stdout"
+	RunEventStderr   = "stderr"
+	RunEventFinished = "finished"
+	RunEventFailed   = "failed"
+)
+
+// RunEvent is one timestamped lifecycle or output event of a run.
+type RunEvent struct {
+	Seq         int       `json:"seq"`
+	Time        time.Time `json:"time"`
+	RunID       string    `json:"runId"`
+	Type        string    `json:"type"`
+	ProjectPath string    `json:"projectPath,omitempty"`
+	Data        string    `json:"data,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	ExitCode    *int      `json:"exitCode,omitempty"`
+	DurationMS  int64     `json:"durationMs,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// runEventLog collects events for one run; output handlers call it from the
+// runner's goroutines.
+type runEventLog struct {
+	mu     sync.Mutex
+	runID  string
+	events []RunEvent
+}
+
+func (l *runEventLog) add(event RunEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	event.Seq = len(l.events) + 1
+	event.RunID = l.runID
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	l.events = append(l.events, event)
+}
+
+func (l *runEventLog) snapshot() []RunEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]RunEvent(nil), l.events...)
+}
+
+// ExportRunEvents writes a recent run's events to destPath as newline-delimited
+// JSON and returns the number of events written.
+func (a *Application) ExportRunEvents(ctx context.Context, runID string, destPath string) (_ int, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionExportRunEvents, "", map[string]string{"runId": runID, "path": destPath}, err)
+	}()
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("export run events context: %w", err)
+	}
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return 0, fmt.Errorf("run id is required")
+	}
+	resolvedPath, err := resolveInputFilePath(destPath)
+	if err != nil {
+		return 0, err
+	}
+
+	a.runEventsMu.Lock()
+	eventLog, ok := a.runEvents[runID]
+	a.runEventsMu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("run events not found: %s", runID)
+	}
+	events := eventLog.snapshot()
+
+	if err := os.MkdirAll(filepath.Dir(resolvedPath), 0o755); err != nil {
+		return 0, fmt.Errorf("create export directory: %w", err)
+	}
+	file, err := os.Create(resolvedPath)
+	if err != nil {
+		return 0, fmt.Errorf("create run events file: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			file.Close()
+			return 0, fmt.Errorf("encode run event: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return 0, fmt.Errorf("write run events: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("close run events file: %w", err)
+	}
+	return len(events), nil
+}
+
+// beginRunEvents starts an event log for the request's run, evicting the
+// oldest logs beyond maxRecentResults.
+func (a *Application) beginRunEvents(request execution.RunRequest) *runEventLog {
+	eventLog := &runEventLog{runID: request.RunID}
+	eventLog.add(RunEvent{Type: RunEventStarted, ProjectPath: strings.TrimSpace(request.ProjectPath)})
+
+	a.runEventsMu.Lock()
+	defer a.runEventsMu.Unlock()
+	if a.runEvents == nil {
+		a.runEvents = make(map[string]*runEventLog)
+	}
+	if _, exists := a.runEvents[request.RunID]; !exists {
+		a.runEventsOrder = append(a.runEventsOrder, request.RunID)
+	}
+	a.runEvents[request.RunID] = eventLog
+	for len(a.runEventsOrder) > maxRecentResults {
+		delete(a.runEvents, a.runEventsOrder[0])
+		a.runEventsOrder = a.runEventsOrder[1:]
+	}
+	return eventLog
+}
+
+// finish records the terminal event for a run.
+func (l *runEventLog) finish(result execution.Result, err error) {
+	if err != nil {
+		l.add(RunEvent{Type: RunEventFailed, Error: err.Error()})
+		return
+	}
+	exitCode := result.ExitCode
+	l.add(RunEvent{
+		Type:       RunEventFinished,
+		Status:     runStatusFromResult(result),
+		ExitCode:   &exitCode,
+		DurationMS: result.DurationMS,
+	})
+}
+
+// outputHandlers wraps stream handlers so every chunk is also logged.
+func (l *runEventLog) outputHandlers(
+	onStdoutChunk execution.StdoutChunkHandler,
+	onStderrChunk execution.StderrChunkHandler,
+) (execution.StdoutChunkHandler, execution.StderrChunkHandler) {
+	stdout := func(chunk string) {
+		l.add(RunEvent{Type: RunEventStdout, Data: chunk})
+		if onStdoutChunk != nil {
+			onStdoutChunk(chunk)
+		}
+	}
+	stderr := func(chunk string) {
+		l.add(RunEvent{Type: RunEventStderr, Data: chunk})
+		if onStderrChunk != nil {
+			onStderrChunk(chunk)
+		}
+	}
+	return stdout, stderr
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestExportRunEventsWritesNDJSON(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	eventLog := application.beginRunEvents(execution.RunRequest{RunID: "run-1", ProjectPath: "/work/demo"})
+	forwarded := ""
+	onStdout, onStderr := eventLog.outputHandlers(func(chunk string) { forwarded += chunk }, nil)
+	onStdout("hello ")
+	onStderr("warn")
+	onStdout("world")
+	eventLog.finish(execution.Result{ExitCode: 0, DurationMS: 7}, nil)
+	if got, want := forwarded, "hello world"; got != want {
+		t.Fatalf("forwarded stdout = %q, want %q", got, want)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "exports", "run-1.ndjson")
+	count, err := application.ExportRunEvents(context.Background(), "run-1", destPath)
+	if err != nil {
+		t.Fatalf("ExportRunEvents() error = %v", err)
+	}
+	if got, want := count, 5; got != want {
+		t.Fatalf("ExportRunEvents() count = %d, want %d", got, want)
+	}
+
+	file, err := os.Open(destPath)
+	if err != nil {
+		t.Fatalf("open export: %v", err)
+	}
+	defer file.Close()
+	types := make([]string, 0, count)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event RunEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("decode line %q: %v", scanner.Text(), err)
+		}
+		if event.RunID != "run-1" || event.Time.IsZero() || event.Seq != len(types)+1 {
+			t.Fatalf("event = %+v, want run-1 with time and seq %d", event, len(types)+1)
+		}
+		types = append(types, event.Type)
+	}
+	want := []string{RunEventStarted, RunEventStdout, RunEventStderr, RunEventStdout, RunEventFinished}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("event types = %v, want %v", types, want)
+		}
+	}
+
+	if _, err := application.ExportRunEvents(context.Background(), "missing", destPath); err == nil {
+		t.Fatal("ExportRunEvents(missing) error = nil, want not found")
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestRunSnippetWithPackageFiles(t *testing.T) {
+	t.Parallel()
+	requireGoToolchain(t)
+
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	writeTestFile(t, filepath.Join(projectDir, "cmd", "api", "routes.go"), "package main\n\nfunc routes() []string { return []string{\"/health\", \"/users\"} }\n")
+	opened, err := application.OpenProject(context.Background(), projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	var files []string
+	for _, target := range opened.Targets {
+		if target.Package == "./cmd/api" {
+			files = target.Files
+		}
+	}
+	if len(files) != 1 || files[0] != "cmd/api/routes.go" {
+		t.Fatalf("./cmd/api Files = %v", files)
+	}
+
+	request := execution.RunRequest{
+		ProjectPath: projectDir,
+		PackagePath: "./cmd/api",
+		Files:       files,
+		Source:      "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(routes()) }\n",
+	}
+	result, err := application.RunSnippet(context.Background(), request, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.ExitCode != 0 || strings.TrimSpace(result.Stdout) != "[/health /users]" {
+		t.Fatalf("RunSnippet() exit = %d, stdout = %q, stderr = %q", result.ExitCode, result.Stdout, result.Stderr)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(projectDir, "cmd", "api", "gopoke_snippet_*.go"))
+	if len(leftovers) != 0 {
+		t.Fatalf("snippet files left in the package: %v", leftovers)
+	}
+
+	for _, bad := range [][]string{{"cmd/api/main.go"}, {"../outside.go"}, {"cmd/api/routes.go", "main.go"}} {
+		request.Files = bad
+		if _, err := application.RunSnippet(context.Background(), request, nil, nil); err == nil {
+			t.Fatalf("RunSnippet() with files %v error = nil", bad)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "cmd", "api", "routes.go")); err != nil {
+		t.Fatalf("package file disturbed: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/runguard"
+	"gopoke/internal/storage"
+)
+
+// SetProjectRunGuard sets how a project handles snippets the pre-run scan
+// flags as destructive: confirm, warn or off.
+func (a *Application) SetProjectRunGuard(ctx context.Context, projectPath string, policy string) (storage.ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project run guard context: %w", err)
+	}
+	normalized, err := runguard.NormalizePolicy(policy)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	updated, err := a.store.UpdateProjectRunGuard(ctx, record.Path, normalized)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project run guard: %w", err)
+	}
+	return updated, nil
+}
+
+// guardRun scans the snippet under the project's policy. It returns the
+// findings and whether the run must wait for confirmation.
+func (a *Application) guardRun(request execution.RunRequest, resolved resolvedRunRequest) ([]runguard.Finding, bool) {
+	policy, err := runguard.NormalizePolicy(resolved.runGuard)
+	if err != nil {
+		a.logger.Warn("invalid run guard policy; confirming", "projectPath", resolved.projectPath, "error", err)
+		policy = runguard.PolicyConfirm
+	}
+	if policy == runguard.PolicyOff {
+		return nil, false
+	}
+	findings := runguard.Scan(resolved.source, resolved.projectPath)
+	if len(findings) == 0 {
+		return nil, false
+	}
+	return findings, policy == runguard.PolicyConfirm && !request.ConfirmDestructive
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/runguard"
+)
+
+const destructiveSnippet = `package main
+
+import "os"
+
+//stdout: cleaned
+func main() {
+	os.RemoveAll("/")
+}
+`
+
+func TestRunGuardHoldsDestructiveSnippetUntilConfirmed(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	opened, err := application.OpenProject(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	held, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: destructiveSnippet}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet(held) error = %v", err)
+	}
+	if !held.ConfirmationRequired || len(held.GuardFindings) != 1 || held.Stdout != "" {
+		t.Fatalf("held result = %+v, want confirmation without running", held)
+	}
+	if held.GuardFindings[0].Rule != runguard.RuleRemoveAll {
+		t.Fatalf("finding = %+v, want %s", held.GuardFindings[0], runguard.RuleRemoveAll)
+	}
+	runs, err := application.store.ProjectRuns(ctx, opened.Project.ID, 10)
+	if err != nil {
+		t.Fatalf("ProjectRuns() error = %v", err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("run history = %+v, want held run unrecorded", runs)
+	}
+
+	confirmed, err := application.RunSnippet(ctx, execution.RunRequest{
+		ProjectPath:        projectDir,
+		Source:             destructiveSnippet,
+		ConfirmDestructive: true,
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet(confirmed) error = %v", err)
+	}
+	if confirmed.ConfirmationRequired || confirmed.Stdout != "cleaned\n" || len(confirmed.GuardFindings) != 1 {
+		t.Fatalf("confirmed result = %+v, want run with findings", confirmed)
+	}
+
+	if _, err := application.SetProjectRunGuard(ctx, projectDir, runguard.PolicyOff); err != nil {
+		t.Fatalf("SetProjectRunGuard(off) error = %v", err)
+	}
+	unguarded, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: destructiveSnippet}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet(off) error = %v", err)
+	}
+	if unguarded.ConfirmationRequired || len(unguarded.GuardFindings) != 0 {
+		t.Fatalf("unguarded result = %+v, want no scan", unguarded)
+	}
+	if _, err := application.SetProjectRunGuard(ctx, projectDir, "block"); err == nil {
+		t.Fatal("SetProjectRunGuard(invalid) error = nil, want error")
+	}
+}
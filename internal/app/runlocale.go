@@ -0,0 +1,13 @@
+package app
+
+import "gopoke/internal/execution"
+
+// RunTimeZones lists the time zones a run can be pinned to.
+func (a *Application) RunTimeZones() []execution.TimeZoneOption {
+	return execution.TimeZones()
+}
+
+// RunLocales lists the locales a run can be pinned to.
+func (a *Application) RunLocales() []execution.LocaleOption {
+	return execution.Locales()
+}
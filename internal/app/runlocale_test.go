@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/testutil"
+)
+
+func TestRunSnippetValidatesTimeZoneAndLocale(t *testing.T) {
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	application.backend = &execution.FakeBackend{}
+
+	runCtx, cancel := testutil.TestRunContext(t)
+	defer cancel()
+	source := "package main\n//stdout: ok\nfunc main() {}\n"
+
+	_, err := application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: projectDir, Source: source, TimeZone: "Mars/Olympus_Mons"}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "time zone") {
+		t.Fatalf("RunSnippet(bad time zone) error = %v, want time zone error", err)
+	}
+	_, err = application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: projectDir, Source: source, Locale: "de-DE"}, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "locale") {
+		t.Fatalf("RunSnippet(bad locale) error = %v, want locale error", err)
+	}
+
+	result, err := application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: projectDir, Source: source, TimeZone: "UTC", Locale: "C"}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d, want 0", result.ExitCode)
+	}
+	if len(application.RunTimeZones()) == 0 || len(application.RunLocales()) == 0 {
+		t.Fatal("picker lists are empty")
+	}
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/plugins"
+)
+
+// Built-in run stage names, registered in this order ahead of any added
+// with UseRunStage.
+const (
+	// RunStageLogging logs each run's outcome.
+	RunStageLogging = "logging"
+	// RunStageSandbox watches a run's network use: monitoring and
+	// permission prompts, as the settings enable them.
+	RunStageSandbox = "sandbox"
+	// RunStagePlugins passes run output through plugins and tells them
+	// how each run ended.
+	RunStagePlugins = "plugins"
+)
+
+// runScope is what run stages know about the run they wrap beyond its
+// options.
+type runScope struct {
+	runID    string
+	resolved resolvedRunRequest
+}
+
+type runScopeKey struct{}
+
+func withRunScope(ctx context.Context, scope runScope) context.Context {
+	return context.WithValue(ctx, runScopeKey{}, scope)
+}
+
+// runScopeFrom returns the scope of the run ctx belongs to. Runs started
+// outside runSnippet have none.
+func runScopeFrom(ctx context.Context) (runScope, bool) {
+	scope, ok := ctx.Value(runScopeKey{}).(runScope)
+	return scope, ok
+}
+
+// UseRunStage adds an execution stage that wraps every later run, inside
+// the built-in stages and those added before it.
+func (a *Application) UseRunStage(name string, middleware execution.Middleware) error {
+	return a.runPipeline().Use(name, middleware)
+}
+
+// RemoveRunStage removes a run stage, built-in or added, and reports whether
+// it was registered.
+func (a *Application) RemoveRunStage(name string) bool {
+	return a.runPipeline().Remove(name)
+}
+
+// RunStages lists the registered run stages, outermost first.
+func (a *Application) RunStages() []string {
+	return a.runPipeline().Stages()
+}
+
+// runPipeline returns the run stages, registering the built-in ones on
+// first use.
+func (a *Application) runPipeline() *execution.Pipeline {
+	a.runStagesOnce.Do(func() {
+		_ = a.runStages.Use(RunStageLogging, a.loggingStage)
+		_ = a.runStages.Use(RunStageSandbox, a.sandboxStage)
+		_ = a.runStages.Use(RunStagePlugins, a.pluginsStage)
+	})
+	return &a.runStages
+}
+
+// loggingStage logs how each run ended.
+func (a *Application) loggingStage(next execution.RunFunc) execution.RunFunc {
+	return func(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+		scope, _ := runScopeFrom(ctx)
+		result, err := next(ctx, projectPath, snippet, options)
+		if err != nil {
+			a.logger.Warn("run failed", "runID", scope.runID, "error", err)
+			return result, err
+		}
+		a.logger.Info("run finished",
+			"runID", scope.runID, "exitCode", result.ExitCode, "durationMS", result.DurationMS,
+			"timedOut", result.TimedOut, "canceled", result.Canceled)
+		return result, nil
+	}
+}
+
+// sandboxStage starts network monitoring and permission prompts once the
+// run's process starts. With prompting on where runs cannot be paused for
+// it, the run is refused rather than left to connect unchecked.
+func (a *Application) sandboxStage(next execution.RunFunc) execution.RunFunc {
+	return func(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+		scope, ok := runScopeFrom(ctx)
+		if !ok {
+			return next(ctx, projectPath, snippet, options)
+		}
+		if a.networkPromptHandler() != nil {
+			if err := networkPromptSupport(); err != nil {
+				return execution.Result{}, fmt.Errorf("network permission prompts: %w", err)
+			}
+		}
+		options = execution.WithStartHook(options, func(pid int) {
+			a.startRunNetworkMonitor(ctx, scope.runID, pid)
+			a.startRunNetworkPermissions(ctx, scope.runID, scope.resolved, pid)
+		})
+		return next(ctx, projectPath, snippet, options)
+	}
+}
+
+// pluginsStage lets plugins with the transformOutput permission rewrite a
+// run's stdout, then sends the result to those that receive run results.
+// Output decoded from another encoding is left alone.
+func (a *Application) pluginsStage(next execution.RunFunc) execution.RunFunc {
+	return func(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+		result, err := next(ctx, projectPath, snippet, options)
+		if err != nil || a.plugins == nil {
+			return result, err
+		}
+		scope, _ := runScopeFrom(ctx)
+		if result.RawStdout == nil {
+			result.Stdout = a.plugins.TransformOutput(context.WithoutCancel(ctx), scope.runID, result.Stdout)
+		}
+		a.plugins.NotifyRunResult(plugins.RunResult{
+			RunID:       scope.runID,
+			ProjectPath: projectPath,
+			ExitCode:    result.ExitCode,
+			DurationMS:  result.DurationMS,
+			Stdout:      result.Stdout,
+			Stderr:      result.Stderr,
+			TimedOut:    result.TimedOut,
+			Canceled:    result.Canceled,
+		})
+		return result, nil
+	}
+}
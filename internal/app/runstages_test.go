@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestUseRunStageWrapsRuns(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	ctx := context.Background()
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	var seenRunID string
+	err := application.UseRunStage("redact", func(next execution.RunFunc) execution.RunFunc {
+		return func(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+			scope, _ := runScopeFrom(ctx)
+			seenRunID = scope.runID
+			result, err := next(ctx, projectPath, snippet, options)
+			result.Stdout = "[redacted]\n"
+			return result, err
+		}
+	})
+	if err != nil {
+		t.Fatalf("UseRunStage() error = %v", err)
+	}
+	if got := application.RunStages(); !reflect.DeepEqual(got, []string{RunStageLogging, RunStageSandbox, RunStagePlugins, "redact"}) {
+		t.Fatalf("RunStages() = %v", got)
+	}
+
+	result, err := application.RunSnippet(ctx, execution.RunRequest{
+		RunID:       "run-stage",
+		ProjectPath: projectDir,
+		Source:      "package main\n\nfunc main() {}\n//stdout: secret\n",
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if result.Stdout != "[redacted]\n" || seenRunID != "run-stage" {
+		t.Fatalf("Stdout = %q, stage saw run %q", result.Stdout, seenRunID)
+	}
+
+	if !application.RemoveRunStage("redact") {
+		t.Fatal("RemoveRunStage(redact) = false")
+	}
+	result, err = application.RunSnippet(ctx, execution.RunRequest{
+		RunID:        "run-plain",
+		ProjectPath:  projectDir,
+		Source:       "package main\n\nfunc main() {}\n//stdout: secret\n",
+		RefreshCache: true,
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() after removal error = %v", err)
+	}
+	if result.Stdout != "secret\n" {
+		t.Fatalf("Stdout after removal = %q", result.Stdout)
+	}
+}
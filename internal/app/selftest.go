@@ -0,0 +1,270 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/procmem"
+)
+
+const (
+	// DefaultSelfTestIterations is used when SelfTest is asked for none.
+	DefaultSelfTestIterations = 10
+	// MaxSelfTestIterations bounds a single self-test.
+	MaxSelfTestIterations = 200
+
+	selfTestRunTimeoutMS = 20000
+	selfTestCancelDelay  = 250 * time.Millisecond
+	selfTestCancelWait   = 3 * time.Second
+	selfTestSettleWait   = 2 * time.Second
+	selfTestPollInterval = 50 * time.Millisecond
+)
+
+// Leak tolerances absorb runtime and background activity that is unrelated
+// to runs, such as timers and LSP traffic.
+const (
+	goroutineLeakTolerance = 5
+	fileLeakTolerance      = 4
+)
+
+// Self-test resources checked for leaks.
+const (
+	SelfTestGoroutines      = "goroutines"
+	SelfTestProcesses       = "processes"
+	SelfTestFileDescriptors = "file_descriptors"
+)
+
+// selfTestSnippet runs until canceled. The sleep directive keeps it running
+// under the fake backend too.
+const selfTestSnippet = `package main
+
+import "time"
+
+//sleep: 3600
+func main() {
+	for {
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+`
+
+// SelfTestFailure describes one iteration that did not cancel cleanly.
+type SelfTestFailure struct {
+	Iteration int    `json:"iteration"`
+	RunID     string `json:"runId"`
+	Reason    string `json:"reason"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// SelfTestLeakCheck compares a resource count before and after the runs.
+type SelfTestLeakCheck struct {
+	Resource string `json:"resource"`
+	// Supported is false when the platform cannot count the resource.
+	Supported bool `json:"supported"`
+	Before    int  `json:"before"`
+	After     int  `json:"after"`
+	Leaked    bool `json:"leaked"`
+}
+
+// SelfTestReport summarizes a run/cancel self-test.
+type SelfTestReport struct {
+	Iterations  int                 `json:"iterations"`
+	Succeeded   int                 `json:"succeeded"`
+	Reliability float64             `json:"reliability"`
+	Backend     string              `json:"backend"`
+	DurationMS  int64               `json:"durationMs"`
+	Failures    []SelfTestFailure   `json:"failures"`
+	Leaks       []SelfTestLeakCheck `json:"leaks"`
+	// Passed is set when every iteration canceled cleanly and nothing leaked.
+	Passed bool `json:"passed"`
+}
+
+type selfTestOutcome struct {
+	result execution.Result
+	err    error
+}
+
+// SelfTest starts and cancels a long-running snippet in the scratch
+// workspace iterations times, then checks that goroutines, child processes
+// and file descriptors return to their starting counts. Runs made by the
+// self-test are kept out of run history and the session recording.
+func (a *Application) SelfTest(ctx context.Context, iterations int) (SelfTestReport, error) {
+	if err := ctx.Err(); err != nil {
+		return SelfTestReport{}, fmt.Errorf("self test context: %w", err)
+	}
+	if a.scratchDir == "" {
+		return SelfTestReport{}, fmt.Errorf("scratch workspace not initialized")
+	}
+	if iterations <= 0 {
+		iterations = DefaultSelfTestIterations
+	}
+	if iterations > MaxSelfTestIterations {
+		return SelfTestReport{}, fmt.Errorf("self test iterations must be at most %d", MaxSelfTestIterations)
+	}
+
+	startedAt := time.Now()
+	workerWasRunning := a.workers != nil && a.workers.IsRunning(a.scratchDir)
+	before := sampleSelfTestResources()
+
+	report := SelfTestReport{
+		Iterations: iterations,
+		Backend:    a.executionBackend().Name(),
+		Failures:   make([]SelfTestFailure, 0),
+	}
+	for iteration := 1; iteration <= iterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return SelfTestReport{}, fmt.Errorf("self test context: %w", err)
+		}
+		runID := fmt.Sprintf("%s_selftest_%03d", generateRunID(), iteration)
+		if failure, ok := a.selfTestIteration(ctx, runID); !ok {
+			failure.Iteration = iteration
+			report.Failures = append(report.Failures, failure)
+			continue
+		}
+		report.Succeeded++
+	}
+
+	// The self-test started the scratch worker; stop it so it is not
+	// reported as a leaked process.
+	if a.workers != nil && !workerWasRunning {
+		if err := a.workers.StopWorker(ctx, a.scratchDir); err != nil {
+			a.logger.Warn("stop self test worker failed", "error", err)
+		}
+	}
+
+	report.Leaks = settleSelfTestResources(ctx, before)
+	report.Reliability = float64(report.Succeeded) / float64(iterations)
+	report.DurationMS = time.Since(startedAt).Milliseconds()
+	report.Passed = len(report.Failures) == 0
+	for _, check := range report.Leaks {
+		if check.Leaked {
+			report.Passed = false
+		}
+	}
+	a.logger.Info("self test finished",
+		"iterations", iterations, "succeeded", report.Succeeded, "passed", report.Passed, "durationMs", report.DurationMS)
+	return report, nil
+}
+
+// selfTestIteration starts one run, cancels it and waits for the canceled
+// result.
+func (a *Application) selfTestIteration(ctx context.Context, runID string) (SelfTestFailure, bool) {
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+
+	outcomes := make(chan selfTestOutcome, 1)
+	go func() {
+		// runSnippet bypasses run events and session recording; the run has
+		// no project so nothing reaches run history.
+		result, err := a.runSnippet(runCtx, execution.RunRequest{
+			RunID:     runID,
+			Source:    selfTestSnippet,
+			TimeoutMS: selfTestRunTimeoutMS,
+		}, nil, nil)
+		outcomes <- selfTestOutcome{result: result, err: err}
+	}()
+
+	select {
+	case outcome := <-outcomes:
+		return selfTestOutcomeFailure(runID, outcome)
+	case <-time.After(selfTestCancelDelay):
+	case <-ctx.Done():
+	}
+
+	if err := a.CancelRun(context.Background(), runID); err != nil {
+		return SelfTestFailure{RunID: runID, Reason: "cancel_error", Detail: err.Error()}, false
+	}
+	select {
+	case outcome := <-outcomes:
+		return selfTestOutcomeFailure(runID, outcome)
+	case <-time.After(selfTestCancelWait):
+		cancelRun()
+		<-outcomes
+		return SelfTestFailure{
+			RunID:  runID,
+			Reason: "cancel_timeout",
+			Detail: fmt.Sprintf("run did not stop within %s", selfTestCancelWait),
+		}, false
+	}
+}
+
+// selfTestOutcomeFailure accepts only a canceled result.
+func selfTestOutcomeFailure(runID string, outcome selfTestOutcome) (SelfTestFailure, bool) {
+	if outcome.err != nil {
+		return SelfTestFailure{RunID: runID, Reason: "run_error", Detail: outcome.err.Error()}, false
+	}
+	if !outcome.result.Canceled {
+		return SelfTestFailure{
+			RunID:  runID,
+			Reason: "not_canceled",
+			Detail: fmt.Sprintf("timedOut=%t exitCode=%d stderr=%q",
+				outcome.result.TimedOut, outcome.result.ExitCode, tailString(outcome.result.Stderr, 160)),
+		}, false
+	}
+	return SelfTestFailure{}, true
+}
+
+type resourceSample struct {
+	resource string
+	count    int
+	ok       bool
+}
+
+func sampleSelfTestResources() []resourceSample {
+	processes, processesOK := procmem.ChildProcesses(os.Getpid())
+	files, filesOK := procmem.OpenFiles()
+	return []resourceSample{
+		{resource: SelfTestGoroutines, count: runtime.NumGoroutine(), ok: true},
+		{resource: SelfTestProcesses, count: processes, ok: processesOK},
+		{resource: SelfTestFileDescriptors, count: files, ok: filesOK},
+	}
+}
+
+// settleSelfTestResources samples resources until they are back within
+// tolerance of before or the settle window ends, and reports the last
+// sample.
+func settleSelfTestResources(ctx context.Context, before []resourceSample) []SelfTestLeakCheck {
+	deadline := time.Now().Add(selfTestSettleWait)
+	for {
+		after := sampleSelfTestResources()
+		checks := make([]SelfTestLeakCheck, 0, len(after))
+		leaked := false
+		for index, sample := range after {
+			check := SelfTestLeakCheck{Resource: sample.resource}
+			if sample.ok && before[index].ok {
+				check.Supported = true
+				check.Before = before[index].count
+				check.After = sample.count
+				check.Leaked = check.After-check.Before > leakTolerance(sample.resource)
+			}
+			leaked = leaked || check.Leaked
+			checks = append(checks, check)
+		}
+		if !leaked || time.Now().After(deadline) || ctx.Err() != nil {
+			return checks
+		}
+		time.Sleep(selfTestPollInterval)
+	}
+}
+
+func leakTolerance(resource string) int {
+	switch resource {
+	case SelfTestGoroutines:
+		return goroutineLeakTolerance
+	case SelfTestFileDescriptors:
+		return fileLeakTolerance
+	default:
+		return 0
+	}
+}
+
+func tailString(value string, max int) string {
+	if max <= 0 || len(value) <= max {
+		return value
+	}
+	return value[len(value)-max:]
+}
@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/settings"
+)
+
+func TestSelfTestCancelsRunsWithoutLeaks(t *testing.T) {
+	application := newTestApplication(t)
+	application.scratchDir = t.TempDir()
+	ctx := context.Background()
+	if _, err := application.UpdateGlobalSettings(ctx, settings.GlobalSettings{ExecutionBackend: settings.ExecutionBackendFake}); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+
+	report, err := application.SelfTest(ctx, 3)
+	if err != nil {
+		t.Fatalf("SelfTest() error = %v", err)
+	}
+	if report.Iterations != 3 || report.Succeeded != 3 || report.Reliability != 1 {
+		t.Fatalf("report = %+v, want 3/3 canceled runs", report)
+	}
+	if report.Backend != execution.BackendFake {
+		t.Fatalf("Backend = %q, want %q", report.Backend, execution.BackendFake)
+	}
+	if len(report.Leaks) != 3 {
+		t.Fatalf("Leaks = %+v, want goroutine, process and file checks", report.Leaks)
+	}
+	for _, check := range report.Leaks {
+		if check.Leaked {
+			t.Fatalf("leak reported: %+v", check)
+		}
+	}
+	if !report.Passed {
+		t.Fatalf("Passed = false, report = %+v", report)
+	}
+
+	if _, err := application.SelfTest(ctx, MaxSelfTestIterations+1); err == nil {
+		t.Fatal("SelfTest() with too many iterations error = nil, want error")
+	}
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/session"
+)
+
+// SessionRecording describes the active session recorder.
+type SessionRecording struct {
+	Active bool   `json:"active"`
+	Path   string `json:"path"`
+}
+
+// ReplaySessionResult is a recorded session rebuilt as playback steps.
+type ReplaySessionResult struct {
+	Header session.Header `json:"header"`
+	Steps  []session.Step `json:"steps"`
+}
+
+// StartSessionRecording begins capturing opens, saves and runs into a new
+// session file. Recording is opt-in and stays on until stopped.
+func (a *Application) StartSessionRecording(ctx context.Context, name string) (SessionRecording, error) {
+	if err := ctx.Err(); err != nil {
+		return SessionRecording{}, fmt.Errorf("start session recording context: %w", err)
+	}
+	if strings.TrimSpace(a.sessionDir) == "" {
+		return SessionRecording{}, fmt.Errorf("session directory not initialized")
+	}
+
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	if a.session != nil {
+		return SessionRecording{}, fmt.Errorf("session recording already active: %s", a.session.Path())
+	}
+	recorder, err := session.Start(a.sessionDir, name)
+	if err != nil {
+		return SessionRecording{}, fmt.Errorf("start session recording: %w", err)
+	}
+	a.session = recorder
+	a.logger.Info("session recording started", "path", recorder.Path())
+	return SessionRecording{Active: true, Path: recorder.Path()}, nil
+}
+
+// StopSessionRecording closes the active session file and returns its path.
+func (a *Application) StopSessionRecording(ctx context.Context) (SessionRecording, error) {
+	if err := ctx.Err(); err != nil {
+		return SessionRecording{}, fmt.Errorf("stop session recording context: %w", err)
+	}
+
+	a.sessionMu.Lock()
+	recorder := a.session
+	a.session = nil
+	a.sessionMu.Unlock()
+	if recorder == nil {
+		return SessionRecording{}, nil
+	}
+	if err := recorder.Close(); err != nil {
+		return SessionRecording{}, fmt.Errorf("stop session recording: %w", err)
+	}
+	return SessionRecording{Path: recorder.Path()}, nil
+}
+
+// ReplaySession loads a session file and returns its step-by-step playback.
+// With rerun set, recorded runs are executed again and compared.
+func (a *Application) ReplaySession(ctx context.Context, path string, rerun bool) (ReplaySessionResult, error) {
+	if err := ctx.Err(); err != nil {
+		return ReplaySessionResult{}, fmt.Errorf("replay session context: %w", err)
+	}
+	resolvedPath, err := resolveInputFilePath(path)
+	if err != nil {
+		return ReplaySessionResult{}, err
+	}
+	header, events, err := session.Load(resolvedPath)
+	if err != nil {
+		return ReplaySessionResult{}, fmt.Errorf("load session: %w", err)
+	}
+
+	steps := session.Replay(events)
+	if rerun {
+		for index := range steps {
+			step := &steps[index]
+			if step.Event.Kind != session.KindRun {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return ReplaySessionResult{}, fmt.Errorf("replay session context: %w", err)
+			}
+			replayed, err := a.RunSnippet(ctx, execution.RunRequest{
+				ProjectPath: step.Event.ProjectPath,
+				Source:      step.Source,
+			}, nil, nil)
+			if err != nil {
+				return ReplaySessionResult{}, fmt.Errorf("replay step %d: %w", step.Index, err)
+			}
+			step.Replayed = &replayed
+			if step.Event.Result != nil {
+				matches := session.SameOutcome(*step.Event.Result, replayed)
+				step.Matches = &matches
+			}
+		}
+	}
+	return ReplaySessionResult{Header: header, Steps: steps}, nil
+}
+
+// recordSessionEvent appends to the active session, if any. Recording
+// failures are logged rather than failing the user's action.
+func (a *Application) recordSessionEvent(event session.Event) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+	if a.session == nil {
+		return
+	}
+	if err := a.session.Record(event); err != nil {
+		a.logger.Warn("record session event failed", "kind", event.Kind, "error", err)
+	}
+}
+
+func (a *Application) recordSessionRun(request execution.RunRequest, result execution.Result) {
+	a.recordSessionEvent(session.Event{
+		Kind:        session.KindRun,
+		ProjectPath: strings.TrimSpace(request.ProjectPath),
+		Content:     request.Source,
+		Result:      &result,
+	})
+}
+
+func (a *Application) closeSessionRecording() {
+	a.sessionMu.Lock()
+	recorder := a.session
+	a.session = nil
+	a.sessionMu.Unlock()
+	if recorder == nil {
+		return
+	}
+	if err := recorder.Close(); err != nil {
+		a.logger.Warn("close session recording failed", "error", err)
+	}
+}
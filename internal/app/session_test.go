@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/session"
+)
+
+func TestSessionRecordingReplaysActions(t *testing.T) {
+	t.Parallel()
+
+	application := newTestApplication(t)
+	application.sessionDir = t.TempDir()
+	projectRoot := t.TempDir()
+	setupRunnableProject(t, projectRoot)
+	ctx := context.Background()
+
+	if _, err := application.OpenProject(ctx, projectRoot); err != nil {
+		t.Fatalf("OpenProject() before recording error = %v", err)
+	}
+	recording, err := application.StartSessionRecording(ctx, "repro")
+	if err != nil {
+		t.Fatalf("StartSessionRecording() error = %v", err)
+	}
+	if _, err := application.StartSessionRecording(ctx, "again"); err == nil {
+		t.Fatal("StartSessionRecording() while active error = nil, want error")
+	}
+
+	mainPath := filepath.Join(projectRoot, "main.go")
+	if _, err := application.OpenGoFile(ctx, mainPath); err != nil {
+		t.Fatalf("OpenGoFile() error = %v", err)
+	}
+	edited := "package main\n\nfunc main() { println(1) }\n"
+	if err := application.SaveGoFile(ctx, mainPath, edited); err != nil {
+		t.Fatalf("SaveGoFile() error = %v", err)
+	}
+	stopped, err := application.StopSessionRecording(ctx)
+	if err != nil {
+		t.Fatalf("StopSessionRecording() error = %v", err)
+	}
+	if stopped.Path != recording.Path || stopped.Active {
+		t.Fatalf("StopSessionRecording() = %+v, want inactive %q", stopped, recording.Path)
+	}
+	if err := application.SaveGoFile(ctx, mainPath, edited); err != nil {
+		t.Fatalf("SaveGoFile() after stop error = %v", err)
+	}
+
+	replay, err := application.ReplaySession(ctx, recording.Path, false)
+	if err != nil {
+		t.Fatalf("ReplaySession() error = %v", err)
+	}
+	kinds := make([]string, 0, len(replay.Steps))
+	for _, step := range replay.Steps {
+		kinds = append(kinds, step.Event.Kind)
+	}
+	want := []string{session.KindOpenProject, session.KindOpenFile, session.KindSaveFile}
+	if len(kinds) != len(want) {
+		t.Fatalf("step kinds = %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("step kinds = %v, want %v", kinds, want)
+		}
+	}
+	if got := replay.Steps[2].Source; got != edited {
+		t.Fatalf("final step source = %q, want %q", got, edited)
+	}
+	if got, want := replay.Header.Name, "repro"; got != want {
+		t.Fatalf("header name = %q, want %q", got, want)
+	}
+}
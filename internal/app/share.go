@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"gopoke/internal/share"
+	"gopoke/internal/storage"
+)
+
+// shareRunLimit is how many recent runs a project share lists.
+const shareRunLimit = 50
+
+// StartProjectShare serves a read-only, token-protected view of a
+// project's snippets and runs on address, replacing any running share.
+// An empty address listens on every interface with a free port.
+func (a *Application) StartProjectShare(ctx context.Context, projectPath string, address string) (share.Info, error) {
+	if err := ctx.Err(); err != nil {
+		return share.Info{}, fmt.Errorf("start project share context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return share.Info{}, err
+	}
+
+	a.shareMu.Lock()
+	defer a.shareMu.Unlock()
+	if err := a.stopShareLocked(ctx); err != nil {
+		return share.Info{}, err
+	}
+	server, err := share.Start(address, record.Path, shareSource{app: a, project: record}, a.logger)
+	if err != nil {
+		return share.Info{}, fmt.Errorf("start project share: %w", err)
+	}
+	a.shareServer = server
+	info := server.Info()
+	a.logger.Info("project share started", "projectPath", record.Path, "address", info.Address)
+	return info, nil
+}
+
+// StopProjectShare stops the running share, if any.
+func (a *Application) StopProjectShare(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("stop project share context: %w", err)
+	}
+	a.shareMu.Lock()
+	defer a.shareMu.Unlock()
+	return a.stopShareLocked(ctx)
+}
+
+// ProjectShare describes the running share; Active is false when none is.
+func (a *Application) ProjectShare() share.Info {
+	a.shareMu.Lock()
+	defer a.shareMu.Unlock()
+	if a.shareServer == nil {
+		return share.Info{}
+	}
+	return a.shareServer.Info()
+}
+
+func (a *Application) stopShareLocked(ctx context.Context) error {
+	if a.shareServer == nil {
+		return nil
+	}
+	server := a.shareServer
+	a.shareServer = nil
+	if err := server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("stop project share: %w", err)
+	}
+	return nil
+}
+
+// shareSource exposes one project to a share. Environment variables are
+// left out; run output is limited to the project's own recent runs.
+type shareSource struct {
+	app     *Application
+	project storage.ProjectRecord
+}
+
+func (s shareSource) View(ctx context.Context) (share.View, error) {
+	snippets, err := s.app.store.ProjectSnippets(ctx, s.project.ID)
+	if err != nil {
+		return share.View{}, err
+	}
+	runs, err := s.app.store.ProjectRuns(ctx, s.project.ID, shareRunLimit)
+	if err != nil {
+		return share.View{}, err
+	}
+	for i := range runs {
+		// Snapshots name every variable the run saw.
+		runs[i].Environment = nil
+	}
+	return share.View{
+		ProjectPath: s.project.Path,
+		ProjectName: filepath.Base(s.project.Path),
+		Snippets:    snippets,
+		Runs:        runs,
+		GeneratedAt: s.app.clock(),
+	}, nil
+}
+
+func (s shareSource) RunOutput(ctx context.Context, runID string) (share.RunOutput, bool, error) {
+	runs, err := s.app.store.ProjectRuns(ctx, s.project.ID, shareRunLimit)
+	if err != nil {
+		return share.RunOutput{}, false, err
+	}
+	if !slices.ContainsFunc(runs, func(run storage.RunRecord) bool { return run.ID == runID }) {
+		return share.RunOutput{}, false, nil
+	}
+	s.app.recentMu.Lock()
+	result, ok := s.app.recentResults[runID]
+	s.app.recentMu.Unlock()
+	if !ok {
+		return share.RunOutput{}, false, nil
+	}
+	return share.RunOutput{
+		RunID:      runID,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		ExitCode:   result.ExitCode,
+		DurationMS: result.DurationMS,
+	}, true, nil
+}
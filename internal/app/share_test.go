@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/share"
+	"gopoke/internal/testutil"
+)
+
+func TestProjectShareExposesOnlyItsProject(t *testing.T) {
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	ctx := context.Background()
+	shared, other := t.TempDir(), t.TempDir()
+	for _, projectDir := range []string{shared, other} {
+		setupRunnableProject(t, projectDir)
+		if _, err := application.OpenProject(ctx, projectDir); err != nil {
+			t.Fatalf("OpenProject() error = %v", err)
+		}
+	}
+
+	runCtx, cancel := testutil.TestRunContext(t)
+	defer cancel()
+	source := "package main\n//stdout: shared output\nfunc main() {}\n"
+	if _, err := application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: shared, Source: source, RunID: "run_shared"}, nil, nil); err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if _, err := application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: other, Source: source, RunID: "run_other"}, nil, nil); err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+
+	if application.ProjectShare().Active {
+		t.Fatal("ProjectShare().Active = true before starting")
+	}
+	info, err := application.StartProjectShare(ctx, shared, "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("StartProjectShare() error = %v", err)
+	}
+	t.Cleanup(func() { application.StopProjectShare(context.Background()) })
+
+	get := func(path string) *http.Response {
+		request, _ := http.NewRequest(http.MethodGet, "http://"+info.Address+path, nil)
+		request.Header.Set("Authorization", "Bearer "+info.Token)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		t.Cleanup(func() { response.Body.Close() })
+		return response
+	}
+
+	var view share.View
+	if err := json.NewDecoder(get("/api/view").Body).Decode(&view); err != nil {
+		t.Fatalf("decode view: %v", err)
+	}
+	if len(view.Runs) != 1 || view.Runs[0].ID != "run_shared" {
+		t.Fatalf("view runs = %+v, want only run_shared", view.Runs)
+	}
+	if response := get("/api/runs/run_shared"); response.StatusCode != http.StatusOK {
+		t.Fatalf("shared run status = %d, want 200", response.StatusCode)
+	}
+	if response := get("/api/runs/run_other"); response.StatusCode != http.StatusNotFound {
+		t.Fatalf("other project's run status = %d, want 404", response.StatusCode)
+	}
+
+	if err := application.StopProjectShare(ctx); err != nil {
+		t.Fatalf("StopProjectShare() error = %v", err)
+	}
+	if application.ProjectShare().Active {
+		t.Fatal("ProjectShare().Active = true after stopping")
+	}
+}
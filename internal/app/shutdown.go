@@ -0,0 +1,22 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"gopoke/internal/execution"
+)
+
+// resolveShutdown validates how a run asks to be stopped and how long it
+// may take before it is killed. A zero grace keeps the backend default.
+func resolveShutdown(request execution.RunRequest) (execution.Shutdown, time.Duration, error) {
+	shutdown, err := execution.NormalizeShutdown(request.ShutdownSignal, request.ShutdownURL)
+	if err != nil {
+		return execution.Shutdown{}, 0, err
+	}
+	grace := time.Duration(request.ShutdownGraceMS) * time.Millisecond
+	if grace < 0 || grace > execution.MaxShutdownGrace {
+		return execution.Shutdown{}, 0, fmt.Errorf("shutdown grace must be between 0 and %s", execution.MaxShutdownGrace)
+	}
+	return shutdown, grace, nil
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestRunSnippetRejectsInvalidShutdown(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	source := "package main\n\nfunc main() {}\n"
+	for _, request := range []execution.RunRequest{
+		{ProjectPath: projectDir, Source: source, ShutdownSignal: execution.ShutdownHTTP, ShutdownURL: "http://example.com/stop"},
+		{ProjectPath: projectDir, Source: source, ShutdownSignal: execution.ShutdownTerminate, ShutdownGraceMS: -1},
+		{ProjectPath: projectDir, Source: source, ShutdownGraceMS: execution.MaxShutdownGrace.Milliseconds() + 1},
+	} {
+		if _, err := application.RunSnippet(ctx, request, nil, nil); err == nil {
+			t.Fatalf("RunSnippet(%+v) error = nil, want error", request)
+		}
+	}
+	if _, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source, ShutdownSignal: "terminate", ShutdownGraceMS: 2000}, nil, nil); err != nil {
+		t.Fatalf("RunSnippet(terminate) error = %v", err)
+	}
+}
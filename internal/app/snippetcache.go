@@ -0,0 +1,238 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/execution"
+	"gopoke/internal/settings"
+	"gopoke/internal/storage"
+)
+
+// snippetCacheInUse is how recently a generated snippet file must have been
+// used for eviction to keep it over budget: a run may still be compiling it.
+const snippetCacheInUse = time.Minute
+
+// snippetCacheEvictDelay is how long after the last run or check the
+// budget is enforced, so a burst of runs pays for one scan.
+const snippetCacheEvictDelay = 5 * time.Second
+
+// SnippetCacheDir is one run cache directory holding generated snippet
+// files.
+type SnippetCacheDir struct {
+	// ProjectPath is empty for the scratch workspace.
+	ProjectPath string    `json:"projectPath,omitempty"`
+	Path        string    `json:"path"`
+	Location    string    `json:"location"`
+	Bytes       int64     `json:"bytes"`
+	Files       int       `json:"files"`
+	LastUsedAt  time.Time `json:"lastUsedAt,omitzero"`
+}
+
+// CacheStats reports where generated snippet files live and how much of
+// the size budget they use.
+type CacheStats struct {
+	// Location is the settings.SnippetCacheProject or
+	// settings.SnippetCacheData location new files are written to.
+	Location   string            `json:"location"`
+	MaxBytes   int64             `json:"maxBytes"`
+	TotalBytes int64             `json:"totalBytes"`
+	Files      int               `json:"files"`
+	Dirs       []SnippetCacheDir `json:"dirs"`
+}
+
+// snippetCache holds where runs write generated snippet files and the size
+// budget shared by every project's files.
+type snippetCache struct {
+	mu         sync.Mutex
+	root       string // per-project directories for the data location
+	location   string
+	maxBytes   int64
+	evictTimer *time.Timer
+	evictMu    sync.Mutex
+	// useMu is read-held by runs and checks while they write snippet files
+	// and held by ClearCache while it removes them.
+	useMu sync.RWMutex
+}
+
+func (c *snippetCache) configure(gs settings.GlobalSettings) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.location = gs.SnippetCacheLocation
+	c.maxBytes = gs.SnippetCacheMaxMB << 20
+}
+
+func (c *snippetCache) policy() (string, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.location != settings.SnippetCacheData || c.root == "" {
+		return settings.SnippetCacheProject, c.maxBytes
+	}
+	return c.location, c.maxBytes
+}
+
+// dir returns the run cache directory for a project's runs and checks, or
+// "" for execution.RunCacheDirName inside the project.
+func (c *snippetCache) dir(projectID string) string {
+	if location, _ := c.policy(); location != settings.SnippetCacheData {
+		return ""
+	}
+	return projectDataDir(c.root, projectID)
+}
+
+// stop cancels a pending budget enforcement.
+func (c *snippetCache) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.evictTimer != nil {
+		c.evictTimer.Stop()
+	}
+}
+
+// projectRunCacheDir returns the run cache directory new runs of a project
+// write to.
+func (a *Application) projectRunCacheDir(projectRecord storage.ProjectRecord) string {
+	if dir := a.snippetCache.dir(projectRecord.ID); dir != "" {
+		return dir
+	}
+	return filepath.Join(projectRecord.Path, execution.RunCacheDirName)
+}
+
+// snippetCacheDirs lists the run cache directories of every known project
+// in both locations, and of the scratch workspace, whether or not they
+// exist yet.
+func (a *Application) snippetCacheDirs(ctx context.Context) ([]SnippetCacheDir, error) {
+	projects, err := a.store.RecentProjects(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	var dirs []SnippetCacheDir
+	if a.scratchDir != "" {
+		dirs = append(dirs, SnippetCacheDir{Path: filepath.Join(a.scratchDir, execution.RunCacheDirName), Location: settings.SnippetCacheProject})
+	}
+	for _, projectRecord := range projects {
+		dirs = append(dirs, SnippetCacheDir{
+			ProjectPath: projectRecord.Path,
+			Path:        filepath.Join(projectRecord.Path, execution.RunCacheDirName),
+			Location:    settings.SnippetCacheProject,
+		})
+		if dataDir := projectDataDir(a.snippetCache.root, projectRecord.ID); dataDir != "" {
+			dirs = append(dirs, SnippetCacheDir{ProjectPath: projectRecord.Path, Path: dataDir, Location: settings.SnippetCacheData})
+		}
+	}
+	return dirs, nil
+}
+
+// CacheStats measures the generated snippet files of every project against
+// the cache size budget. Directories without files are left out.
+func (a *Application) CacheStats(ctx context.Context) (CacheStats, error) {
+	if err := ctx.Err(); err != nil {
+		return CacheStats{}, fmt.Errorf("cache stats context: %w", err)
+	}
+	dirs, err := a.snippetCacheDirs(ctx)
+	if err != nil {
+		return CacheStats{}, err
+	}
+	location, maxBytes := a.snippetCache.policy()
+	stats := CacheStats{Location: location, MaxBytes: maxBytes, Dirs: []SnippetCacheDir{}}
+	for _, dir := range dirs {
+		files, err := execution.SnippetCacheFiles(dir.Path)
+		if err != nil {
+			return CacheStats{}, err
+		}
+		for _, file := range files {
+			dir.Bytes += file.Bytes
+			dir.Files++
+			if file.UsedAt.After(dir.LastUsedAt) {
+				dir.LastUsedAt = file.UsedAt.UTC()
+			}
+		}
+		if dir.Files == 0 {
+			continue
+		}
+		stats.TotalBytes += dir.Bytes
+		stats.Files += dir.Files
+		stats.Dirs = append(stats.Dirs, dir)
+	}
+	return stats, nil
+}
+
+// ClearCache removes the generated snippet files of every project in both
+// locations and returns the emptied report. It refuses while a run is
+// active.
+func (a *Application) ClearCache(ctx context.Context) (_ CacheStats, err error) {
+	defer func() {
+		a.recordAudit(audit.ActionClearCache, "", nil, err)
+	}()
+	if err := ctx.Err(); err != nil {
+		return CacheStats{}, fmt.Errorf("clear cache context: %w", err)
+	}
+	// Runs and checks hold useMu while they use the cache, so none can
+	// start between this check and the removal.
+	if !a.snippetCache.useMu.TryLock() {
+		return CacheStats{}, fmt.Errorf("cannot clear the snippet cache while a run is active")
+	}
+	defer a.snippetCache.useMu.Unlock()
+	dirs, err := a.snippetCacheDirs(ctx)
+	if err != nil {
+		return CacheStats{}, err
+	}
+	a.snippetCache.evictMu.Lock()
+	defer a.snippetCache.evictMu.Unlock()
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir.Path); err != nil {
+			return CacheStats{}, fmt.Errorf("clear snippet cache: %w", err)
+		}
+	}
+	a.logger.Info("snippet cache cleared", "dirs", len(dirs))
+	return a.CacheStats(ctx)
+}
+
+// scheduleSnippetCacheEviction enforces the cache budget in the background
+// once runs and checks have paused for snippetCacheEvictDelay, keeping the
+// scan of every project's cache off the caller's path.
+func (a *Application) scheduleSnippetCacheEviction() {
+	c := &a.snippetCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.evictTimer != nil {
+		c.evictTimer.Reset(snippetCacheEvictDelay)
+		return
+	}
+	c.evictTimer = time.AfterFunc(snippetCacheEvictDelay, func() {
+		a.enforceSnippetCacheBudget(context.Background())
+	})
+}
+
+// enforceSnippetCacheBudget evicts the least recently used generated
+// snippet files across projects once their total passes the budget.
+func (a *Application) enforceSnippetCacheBudget(ctx context.Context) {
+	_, maxBytes := a.snippetCache.policy()
+	if maxBytes <= 0 || a.store == nil {
+		return
+	}
+	a.snippetCache.evictMu.Lock()
+	defer a.snippetCache.evictMu.Unlock()
+	dirs, err := a.snippetCacheDirs(ctx)
+	if err != nil {
+		a.logger.Warn("list snippet caches for eviction", "error", err)
+		return
+	}
+	var files []execution.SnippetCacheFile
+	for _, dir := range dirs {
+		dirFiles, err := execution.SnippetCacheFiles(dir.Path)
+		if err != nil {
+			a.logger.Warn("list snippet cache for eviction", "path", dir.Path, "error", err)
+			continue
+		}
+		files = append(files, dirFiles...)
+	}
+	if removed := execution.EvictSnippetCache(files, maxBytes, time.Now().Add(-snippetCacheInUse)); len(removed) > 0 {
+		a.logger.Info("snippet cache evicted", "files", len(removed), "maxBytes", maxBytes)
+	}
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/settings"
+)
+
+func TestSnippetCacheInDataRoot(t *testing.T) {
+	requireGoToolchain(t)
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.snippetCache.root = t.TempDir()
+	gs := settings.Defaults()
+	gs.SnippetCacheLocation = settings.SnippetCacheData
+	application.snippetCache.configure(gs)
+
+	projectRoot := t.TempDir()
+	setupRunnableProject(t, projectRoot)
+	opened, err := application.OpenProject(ctx, projectRoot)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	request := execution.RunRequest{ProjectPath: projectRoot, Source: "package main\n\nfunc main() {}\n"}
+	if result, err := application.RunSnippet(ctx, request, nil, nil); err != nil || result.ExitCode != 0 {
+		t.Fatalf("RunSnippet() = %+v, %v; want success", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(projectRoot, execution.RunCacheDirName)); !os.IsNotExist(err) {
+		t.Fatalf("project run cache exists (err = %v), want the snippet kept out of the project", err)
+	}
+
+	stats, err := application.CacheStats(ctx)
+	if err != nil {
+		t.Fatalf("CacheStats() error = %v", err)
+	}
+	if stats.Location != settings.SnippetCacheData || stats.Files != 1 || len(stats.Dirs) != 1 {
+		t.Fatalf("CacheStats() = %+v, want one file in the data root", stats)
+	}
+	if dir := stats.Dirs[0]; dir.Path != filepath.Join(application.snippetCache.root, opened.Project.ID) || dir.ProjectPath != opened.Project.Path {
+		t.Fatalf("cache dir = %+v", dir)
+	}
+}
+
+func TestSnippetCacheBudgetEvictsAcrossProjects(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.snippetCache.root = t.TempDir()
+	application.snippetCache.configure(settings.Defaults())
+
+	now := time.Now()
+	var paths []string
+	for _, file := range []struct {
+		name string
+		age  time.Duration
+	}{{"snippet-aa.go", 3 * time.Hour}, {"snippet-bb.go", time.Hour}} {
+		projectRoot := t.TempDir()
+		setupRunnableProject(t, projectRoot)
+		if _, err := application.OpenProject(ctx, projectRoot); err != nil {
+			t.Fatalf("OpenProject() error = %v", err)
+		}
+		path := filepath.Join(projectRoot, execution.RunCacheDirName, file.name)
+		writeTestFile(t, path, "package main\n")
+		if err := os.Chtimes(path, now.Add(-file.age), now.Add(-file.age)); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	application.enforceSnippetCacheBudget(ctx)
+	if stats, err := application.CacheStats(ctx); err != nil || stats.Files != 2 {
+		t.Fatalf("CacheStats() = %+v, %v; want both files within the default budget", stats, err)
+	}
+
+	application.snippetCache.maxBytes = int64(len("package main\n"))
+	application.enforceSnippetCacheBudget(ctx)
+	if _, err := os.Stat(paths[0]); !os.IsNotExist(err) {
+		t.Fatalf("least recently used file still exists (err = %v)", err)
+	}
+	if _, err := os.Stat(paths[1]); err != nil {
+		t.Fatalf("recently used file was evicted: %v", err)
+	}
+
+	// A run using the cache blocks clearing it.
+	application.snippetCache.useMu.RLock()
+	_, err := application.ClearCache(ctx)
+	application.snippetCache.useMu.RUnlock()
+	if err == nil {
+		t.Fatal("ClearCache() during a run error = nil")
+	}
+
+	stats, err := application.ClearCache(ctx)
+	if err != nil {
+		t.Fatalf("ClearCache() error = %v", err)
+	}
+	if stats.Files != 0 || stats.TotalBytes != 0 {
+		t.Fatalf("ClearCache() = %+v, want an empty cache", stats)
+	}
+	if _, err := os.Stat(paths[1]); !os.IsNotExist(err) {
+		t.Fatalf("cleared file still exists (err = %v)", err)
+	}
+}
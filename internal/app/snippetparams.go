@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/snippetmeta"
+	"gopoke/internal/snippetparam"
+)
+
+// SnippetParams returns the parameters source declares with //gopoke:param
+// comments, so the UI can prompt for their values before a run.
+func (a *Application) SnippetParams(ctx context.Context, source string) ([]snippetparam.Param, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("snippet params context: %w", err)
+	}
+	params, err := snippetparam.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse snippet params: %w", err)
+	}
+	return params, nil
+}
+
+// SnippetMeta returns the run configuration source declares with
+// //gopoke:name, timeout, env and target comments.
+func (a *Application) SnippetMeta(ctx context.Context, source string) (snippetmeta.Meta, error) {
+	if err := ctx.Err(); err != nil {
+		return snippetmeta.Meta{}, fmt.Errorf("snippet meta context: %w", err)
+	}
+	meta, err := snippetmeta.Parse(source)
+	if err != nil {
+		return snippetmeta.Meta{}, fmt.Errorf("parse snippet meta: %w", err)
+	}
+	return meta, nil
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/testutil"
+)
+
+func TestRunSnippetInjectsParams(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	ctx := context.Background()
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	source := `package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+//gopoke:param who string default "world"
+//gopoke:param times int
+func main() {
+	times := flag.Int("times", 0, "")
+	flag.String("who", "", "")
+	flag.Parse()
+	fmt.Println(os.Getenv("GOPOKE_PARAM_WHO"), *times)
+}
+`
+	params, err := application.SnippetParams(ctx, source)
+	if err != nil {
+		t.Fatalf("SnippetParams() error = %v", err)
+	}
+	if len(params) != 2 || params[0].Name != "who" || params[1].Name != "times" {
+		t.Fatalf("SnippetParams() = %+v, want who and times", params)
+	}
+
+	runCtx, cancel := testutil.TestRunContext(t)
+	defer cancel()
+	if _, err := application.RunSnippet(runCtx, execution.RunRequest{ProjectPath: projectDir, Source: source}, nil, nil); err == nil {
+		t.Fatal("RunSnippet() without required param error = nil, want error")
+	}
+	result, err := application.RunSnippet(runCtx, execution.RunRequest{
+		ProjectPath: projectDir,
+		Source:      source,
+		Params:      map[string]string{"times": "4"},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if got, want := strings.TrimSpace(result.Stdout), "world 4"; got != want {
+		t.Fatalf("Stdout = %q, want %q (stderr %s)", got, want, result.Stderr)
+	}
+}
+
+func TestSnippetMetaConfiguresRunsAndSaves(t *testing.T) {
+	requireGoToolchain(t)
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	ctx := context.Background()
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	source := "//gopoke:name API probe\n//gopoke:timeout 45s\n//gopoke:env MODE=probe\n//gopoke:target ./cmd/api\npackage main\n\nfunc main() {}\n"
+
+	resolved, err := application.resolveRunRequest(ctx, execution.RunRequest{ProjectPath: projectDir, Source: source})
+	if err != nil {
+		t.Fatalf("resolveRunRequest() error = %v", err)
+	}
+	if resolved.environment["MODE"] != "probe" {
+		t.Fatalf("environment = %v, want MODE=probe", resolved.environment)
+	}
+	if resolved.limits.TimeoutMS != 45000 || resolved.limits.TimeoutSource != execution.LimitSourceSnippet {
+		t.Fatalf("limits = %+v, want the snippet's 45s timeout", resolved.limits)
+	}
+	if want := filepath.Join(projectDir, "cmd", "api"); resolved.workingDirectory != want {
+		t.Fatalf("workingDirectory = %q, want %q", resolved.workingDirectory, want)
+	}
+
+	// Request settings win over the snippet's.
+	resolved, err = application.resolveRunRequest(ctx, execution.RunRequest{ProjectPath: projectDir, PackagePath: ".", Source: source, TimeoutMS: 5000})
+	if err != nil {
+		t.Fatalf("resolveRunRequest(overrides) error = %v", err)
+	}
+	if resolved.workingDirectory != projectDir || resolved.limits.TimeoutSource != execution.LimitSourceRequest {
+		t.Fatalf("resolved = %q, %+v; want request package and timeout", resolved.workingDirectory, resolved.limits)
+	}
+
+	saved, err := application.SaveProjectSnippet(ctx, projectDir, "", "", source)
+	if err != nil || saved.Name != "API probe" {
+		t.Fatalf("SaveProjectSnippet(unnamed) = %+v, %v; want the declared name", saved, err)
+	}
+	if _, err := application.SaveProjectSnippet(ctx, projectDir, "", "bad", "//gopoke:timeout never\npackage main\n"); err == nil {
+		t.Fatal("SaveProjectSnippet(invalid directive) error = nil, want error")
+	}
+}
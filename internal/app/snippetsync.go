@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopoke/internal/audit"
+	"gopoke/internal/snipsync"
+	"gopoke/internal/storage"
+)
+
+// SetProjectSnippetSync points a project's snippets at a remote library.
+// A config without provider and URL turns sync off. Changing the library
+// forgets the previous sync state, so the next sync merges from scratch.
+func (a *Application) SetProjectSnippetSync(ctx context.Context, projectPath string, config storage.SnippetSyncConfig) (storage.ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project snippet sync context: %w", err)
+	}
+	var normalized *storage.SnippetSyncConfig
+	if strings.TrimSpace(config.Provider) != "" || strings.TrimSpace(config.URL) != "" {
+		checked, err := snipsync.NormalizeConfig(config)
+		if err != nil {
+			return storage.ProjectRecord{}, err
+		}
+		normalized = &checked
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	updated, err := a.store.UpdateProjectSnippetSync(ctx, record.Path, normalized)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project snippet sync: %w", err)
+	}
+	if !sameSnippetLibrary(record.SnippetSync, normalized) {
+		if err := os.Remove(a.snippetSyncStatePath(record.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			a.logger.Warn("reset snippet sync state failed", "projectPath", record.Path, "error", err)
+		}
+	}
+	return updated, nil
+}
+
+// SyncSnippets pulls the project's remote library, merges it with the
+// local snippets and pushes the result. When both sides edited a snippet
+// the newer edit wins and the other is kept as a conflict copy.
+func (a *Application) SyncSnippets(ctx context.Context, projectPath string) (report snipsync.Report, err error) {
+	if err := ctx.Err(); err != nil {
+		return snipsync.Report{}, fmt.Errorf("sync snippets context: %w", err)
+	}
+	if a.snippetSyncDir == "" {
+		return snipsync.Report{}, fmt.Errorf("snippet sync not initialized")
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return snipsync.Report{}, err
+	}
+	if record.SnippetSync == nil {
+		return snipsync.Report{}, fmt.Errorf("snippet sync is not configured for this project")
+	}
+	defer func() {
+		a.recordAudit(audit.ActionSyncSnippets, record.Path, map[string]string{
+			"provider": record.SnippetSync.Provider,
+			"url":      record.SnippetSync.URL,
+			"pulled":   strconv.Itoa(report.Pulled),
+			"pushed":   strconv.Itoa(report.Pushed),
+		}, err)
+	}()
+
+	provider, err := snipsync.NewProvider(*record.SnippetSync, a.snippetSyncDir, record.Path)
+	if err != nil {
+		return snipsync.Report{}, err
+	}
+	// Syncs share git caches and state files; run one at a time.
+	a.snippetSyncMu.Lock()
+	defer a.snippetSyncMu.Unlock()
+
+	statePath := a.snippetSyncStatePath(record.ID)
+	state, err := snipsync.LoadState(statePath)
+	if err != nil {
+		return snipsync.Report{}, err
+	}
+	report, state, err = snipsync.Sync(ctx, provider, projectSnippets{store: a.store, projectID: record.ID}, state)
+	if err != nil {
+		return snipsync.Report{}, fmt.Errorf("sync snippets: %w", err)
+	}
+	if err := snipsync.SaveState(statePath, state); err != nil {
+		return snipsync.Report{}, err
+	}
+	return report, nil
+}
+
+func (a *Application) snippetSyncStatePath(projectID string) string {
+	return filepath.Join(a.snippetSyncDir, "state", projectID+".json")
+}
+
+func sameSnippetLibrary(left *storage.SnippetSyncConfig, right *storage.SnippetSyncConfig) bool {
+	if left == nil || right == nil {
+		return left == right
+	}
+	return left.Provider == right.Provider && left.URL == right.URL && left.Branch == right.Branch && left.Path == right.Path
+}
+
+// projectSnippets adapts the store to snipsync.Local for one project.
+type projectSnippets struct {
+	store     *storage.Store
+	projectID string
+}
+
+func (p projectSnippets) Snippets(ctx context.Context) ([]storage.SnippetRecord, error) {
+	return p.store.ProjectSnippets(ctx, p.projectID)
+}
+
+func (p projectSnippets) Apply(ctx context.Context, upserts []storage.SnippetRecord, deleteIDs []string) ([]storage.SnippetRecord, error) {
+	return p.store.ApplySnippetSync(ctx, p.projectID, upserts, deleteIDs)
+}
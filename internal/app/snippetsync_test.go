@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopoke/internal/storage"
+)
+
+func TestSyncSnippetsSharesLibraryBetweenProjects(t *testing.T) {
+	var mu sync.Mutex
+	var body []byte
+	version := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		etag := fmt.Sprintf(`"%d"`, version)
+		switch r.Method {
+		case http.MethodGet:
+			if body == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", etag)
+			w.Write(body)
+		case http.MethodPut:
+			if match := r.Header.Get("If-Match"); match != "" && match != etag {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			body, _ = io.ReadAll(r.Body)
+			version++
+			w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	application := newTestApplication(t)
+	application.snippetSyncDir = t.TempDir()
+	ctx := context.Background()
+	config := storage.SnippetSyncConfig{Provider: "webdav", URL: server.URL + "/team.json"}
+	projects := make([]string, 2)
+	for index := range projects {
+		projects[index] = t.TempDir()
+		setupRunnableProject(t, projects[index])
+		if _, err := application.OpenProject(ctx, projects[index]); err != nil {
+			t.Fatalf("OpenProject() error = %v", err)
+		}
+	}
+
+	if _, err := application.SyncSnippets(ctx, projects[0]); err == nil {
+		t.Fatal("SyncSnippets() without config error = nil, want error")
+	}
+	if _, err := application.SetProjectSnippetSync(ctx, projects[0], storage.SnippetSyncConfig{Provider: "ftp", URL: "x"}); err == nil {
+		t.Fatal("SetProjectSnippetSync(ftp) error = nil, want error")
+	}
+	for _, projectPath := range projects {
+		if _, err := application.SetProjectSnippetSync(ctx, projectPath, config); err != nil {
+			t.Fatalf("SetProjectSnippetSync() error = %v", err)
+		}
+	}
+
+	if _, err := application.SaveProjectSnippet(ctx, projects[0], "", "probe", "package main // probe"); err != nil {
+		t.Fatalf("SaveProjectSnippet() error = %v", err)
+	}
+	report, err := application.SyncSnippets(ctx, projects[0])
+	if err != nil {
+		t.Fatalf("SyncSnippets(first) error = %v", err)
+	}
+	if report.Pushed != 1 {
+		t.Fatalf("first report = %+v, want one pushed", report)
+	}
+	report, err = application.SyncSnippets(ctx, projects[1])
+	if err != nil {
+		t.Fatalf("SyncSnippets(second) error = %v", err)
+	}
+	if report.Pulled != 1 {
+		t.Fatalf("second report = %+v, want one pulled", report)
+	}
+
+	// Both projects hold their own copy of the shared snippet.
+	first, _ := application.ProjectSnippets(ctx, projects[0])
+	second, _ := application.ProjectSnippets(ctx, projects[1])
+	if len(first) != 1 || len(second) != 1 || second[0].Content != "package main // probe" || first[0].ID == second[0].ID {
+		t.Fatalf("snippets = %+v and %+v, want separate copies of probe", first, second)
+	}
+
+	// Deleting in one project removes the snippet from the other.
+	if err := application.DeleteProjectSnippet(ctx, projects[1], second[0].ID); err != nil {
+		t.Fatalf("DeleteProjectSnippet() error = %v", err)
+	}
+	if _, err := application.SyncSnippets(ctx, projects[1]); err != nil {
+		t.Fatalf("SyncSnippets(delete) error = %v", err)
+	}
+	report, err = application.SyncSnippets(ctx, projects[0])
+	if err != nil {
+		t.Fatalf("SyncSnippets(receive delete) error = %v", err)
+	}
+	if first, _ := application.ProjectSnippets(ctx, projects[0]); report.DeletedLocal != 1 || len(first) != 0 {
+		t.Fatalf("report = %+v snippets = %+v, want the snippet deleted", report, first)
+	}
+}
+
+func TestSyncSnippetsWithRepoDirectory(t *testing.T) {
+	application := newTestApplication(t)
+	application.snippetSyncDir = t.TempDir()
+	ctx := context.Background()
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	if _, err := application.SetProjectSnippetSync(ctx, projectDir, storage.SnippetSyncConfig{Provider: "repo"}); err != nil {
+		t.Fatalf("SetProjectSnippetSync(repo) error = %v", err)
+	}
+	if _, err := application.SaveProjectSnippet(ctx, projectDir, "", "probe", "package main // probe\n"); err != nil {
+		t.Fatalf("SaveProjectSnippet() error = %v", err)
+	}
+	if report, err := application.SyncSnippets(ctx, projectDir); err != nil || report.Pushed != 1 {
+		t.Fatalf("SyncSnippets(first) = %+v, %v; want one pushed", report, err)
+	}
+
+	// A git pull brings a teammate's edit.
+	snippetFile := filepath.Join(projectDir, ".gopoke", "snippets", "probe.go")
+	raw, err := os.ReadFile(snippetFile)
+	if err != nil {
+		t.Fatalf("read snippet file: %v", err)
+	}
+	edited := strings.Replace(string(raw), "// probe", "// probe v2", 1)
+	if err := os.WriteFile(snippetFile, []byte(edited), 0o644); err != nil {
+		t.Fatalf("write snippet file: %v", err)
+	}
+	if report, err := application.SyncSnippets(ctx, projectDir); err != nil || report.Pulled != 1 {
+		t.Fatalf("SyncSnippets(second) = %+v, %v; want one pulled", report, err)
+	}
+	snippets, err := application.ProjectSnippets(ctx, projectDir)
+	if err != nil || len(snippets) != 1 || snippets[0].Content != "package main // probe v2\n" {
+		t.Fatalf("ProjectSnippets() = %+v, %v; want the edited snippet", snippets, err)
+	}
+}
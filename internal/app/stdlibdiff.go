@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/project"
+	"gopoke/internal/stdlibdiff"
+)
+
+// StdlibDiff compares a standard library symbol between two Go versions,
+// such as "strings.Cut" between "go1.21" and "go1.22.3": API lines added or
+// removed and GODEBUG behavior notes in between. Versions may also name an
+// installed toolchain such as "go" or "tip". An older version that is not
+// installed is derived from a newer installation's API history.
+func (a *Application) StdlibDiff(ctx context.Context, symbol string, fromVersion string, toVersion string) (stdlibdiff.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return stdlibdiff.Result{}, fmt.Errorf("stdlib diff context: %w", err)
+	}
+	if strings.TrimSpace(symbol) == "" {
+		return stdlibdiff.Result{}, fmt.Errorf("symbol is required")
+	}
+	toolchains, err := a.AvailableToolchains(ctx)
+	if err != nil {
+		return stdlibdiff.Result{}, err
+	}
+
+	from, fromInstalled, err := loadStdlibRelease(ctx, toolchains, fromVersion)
+	if err != nil {
+		return stdlibdiff.Result{}, err
+	}
+	to, toInstalled, err := loadStdlibRelease(ctx, toolchains, toVersion)
+	if err != nil {
+		return stdlibdiff.Result{}, err
+	}
+	switch {
+	case fromInstalled && toInstalled:
+	case toInstalled && from.Minor <= to.Minor:
+		from = to.AsOf(from.Minor)
+	case fromInstalled && to.Minor <= from.Minor:
+		to = from.AsOf(to.Minor)
+	default:
+		missing := to
+		if toInstalled {
+			missing = from
+		}
+		return stdlibdiff.Result{}, fmt.Errorf("%s is not installed; install it or compare against an older version", missing.Version)
+	}
+
+	result, err := stdlibdiff.Diff(symbol, from, to)
+	if err != nil {
+		return stdlibdiff.Result{}, fmt.Errorf("stdlib diff: %w", err)
+	}
+	return result, nil
+}
+
+// loadStdlibRelease loads the standard library of the installed toolchain
+// matching version. When none matches it returns only the version, with
+// installed false.
+func loadStdlibRelease(ctx context.Context, toolchains []project.ToolchainInfo, version string) (stdlibdiff.Release, bool, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return stdlibdiff.Release{}, false, fmt.Errorf("version is required")
+	}
+	toolchain, found := stdlibToolchain(toolchains, version)
+	if !found {
+		minor, err := stdlibdiff.ParseVersion(version)
+		if err != nil {
+			return stdlibdiff.Release{}, false, err
+		}
+		release := stdlibdiff.Release{Version: "go" + strings.TrimPrefix(version, "go"), Minor: minor}
+		return release, false, nil
+	}
+	goroot, err := project.ToolchainGOROOT(ctx, toolchain.Path)
+	if err != nil {
+		return stdlibdiff.Release{}, false, err
+	}
+	release, err := stdlibdiff.Load(goroot)
+	if err != nil {
+		return stdlibdiff.Release{}, false, fmt.Errorf("load standard library of %s: %w", toolchain.Name, err)
+	}
+	return release, true, nil
+}
+
+// stdlibToolchain finds the toolchain named version, else one whose release
+// matches it: "1.22" matches any go1.22 patch, "1.22.3" only that patch.
+func stdlibToolchain(toolchains []project.ToolchainInfo, version string) (project.ToolchainInfo, bool) {
+	for _, toolchain := range toolchains {
+		if toolchain.Name == version {
+			return toolchain, true
+		}
+	}
+	wanted := strings.TrimPrefix(version, "go")
+	minor, err := stdlibdiff.ParseVersion(wanted)
+	if err != nil {
+		return project.ToolchainInfo{}, false
+	}
+	exactPatch := strings.Count(wanted, ".") == 2
+	for _, toolchain := range toolchains {
+		if toolchain.DevVersion {
+			continue
+		}
+		installed := parseToolVersion(toolchain.Version)
+		if installed == "" {
+			continue
+		}
+		if exactPatch {
+			if compareToolVersions(installed, wanted) == 0 {
+				return toolchain, true
+			}
+			continue
+		}
+		if installedMinor, err := stdlibdiff.ParseVersion(installed); err == nil && installedMinor == minor {
+			return toolchain, true
+		}
+	}
+	return project.ToolchainInfo{}, false
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStdlibDiffAgainstInstalledToolchain(t *testing.T) {
+	t.Parallel()
+	requireGoToolchain(t)
+
+	application := newTestApplication(t)
+	result, err := application.StdlibDiff(context.Background(), "strings.Cut", "1.17", "go")
+	if err != nil {
+		t.Fatalf("StdlibDiff() error = %v", err)
+	}
+	if !result.Changed || len(result.Added) != 1 || result.Added[0].Since != "go1.18" || result.From != "go1.17" {
+		t.Fatalf("StdlibDiff(strings.Cut) = %+v", result)
+	}
+
+	if _, err := application.StdlibDiff(context.Background(), "strings.Cut", "go", "1.999"); err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Fatalf("StdlibDiff(uninstalled) error = %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/procmem"
+)
+
+const (
+	// scratchDirPrefix names each process's scratch workspace in the temp
+	// directory, followed by its pid.
+	scratchDirPrefix = "gopoke-scratch-"
+	// orphanStaleAfter is how long a scratch or run cache directory must go
+	// unmodified before the startup sweep treats it as abandoned.
+	orphanStaleAfter = 7 * 24 * time.Hour
+)
+
+// orphanSweep is what the startup sweep removed.
+type orphanSweep struct {
+	dirs           int
+	reclaimedBytes int64
+}
+
+// sweepOrphans removes scratch workspaces in tempDir and project run caches
+// left behind by crashed sessions. A scratch workspace is orphaned once its
+// process is gone, or, where liveness cannot be checked or the pid may have
+// been reused, once nothing in it changed for orphanStaleAfter. Run caches
+// are shared by every gopoke process running the project, so only stale
+// ones are removed.
+func (a *Application) sweepOrphans(ctx context.Context, tempDir string) orphanSweep {
+	now := time.Now()
+	var sweep orphanSweep
+	remove := func(dir string) {
+		bytes, _, err := directoryUsage(dir)
+		if err != nil {
+			a.logger.Warn("measure orphaned directory", "path", dir, "error", err)
+			return
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			a.logger.Warn("remove orphaned directory", "path", dir, "error", err)
+			return
+		}
+		sweep.dirs++
+		sweep.reclaimedBytes += bytes
+	}
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		a.logger.Warn("list temp directory for orphaned scratch workspaces", "error", err)
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), scratchDirPrefix))
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), scratchDirPrefix) || err != nil || pid == os.Getpid() {
+			continue
+		}
+		dir := filepath.Join(tempDir, entry.Name())
+		if running, known := procmem.Running(pid); (known && !running) || staleSince(dir, now) {
+			remove(dir)
+		}
+	}
+
+	projects, err := a.store.RecentProjects(ctx, 0)
+	if err != nil {
+		a.logger.Warn("list projects for orphaned run caches", "error", err)
+		return sweep
+	}
+	for _, projectRecord := range projects {
+		for _, dir := range []string{filepath.Join(projectRecord.Path, execution.RunCacheDirName), projectDataDir(a.snippetCache.root, projectRecord.ID)} {
+			if info, err := os.Stat(dir); err == nil && info.IsDir() && staleSince(dir, now) {
+				remove(dir)
+			}
+		}
+	}
+	return sweep
+}
+
+// staleSince reports whether nothing under dir was modified within
+// orphanStaleAfter of now.
+func staleSince(dir string, now time.Time) bool {
+	cutoff := now.Add(-orphanStaleAfter)
+	stale := true
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// A directory that cannot be read is left alone.
+			if path == dir {
+				stale = false
+			}
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().After(cutoff) {
+			stale = false
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return stale
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/procmem"
+)
+
+// ageTree sets the modification time of everything under root to age ago.
+func ageTree(t *testing.T, root string, age time.Duration) {
+	t.Helper()
+	old := time.Now().Add(-age)
+	err := filepath.WalkDir(root, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Chtimes(path, old, old)
+	})
+	if err != nil {
+		t.Fatalf("age %s: %v", root, err)
+	}
+}
+
+func TestSweepOrphansRemovesAbandonedDirectories(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	application := newTestApplication(t)
+	tempDir := t.TempDir()
+
+	scratch := func(pid int) string {
+		dir := filepath.Join(tempDir, scratchDirPrefix+strconv.Itoa(pid))
+		writeTestFile(t, filepath.Join(dir, "go.mod"), "module gopoke-scratch\n")
+		return dir
+	}
+	own := scratch(os.Getpid())
+	ageTree(t, own, 30*24*time.Hour)
+	live := scratch(os.Getppid())
+	abandoned := scratch(os.Getppid() + 1_000_000)
+	ageTree(t, abandoned, 30*24*time.Hour)
+	dead := scratch(99_999_999)
+	unrelated := filepath.Join(tempDir, "gopoke-scratch-notes")
+	writeTestFile(t, filepath.Join(unrelated, "notes.txt"), "keep")
+
+	runCache := func(name string) string {
+		projectPath := filepath.Join(t.TempDir(), name)
+		dir := filepath.Join(projectPath, execution.RunCacheDirName)
+		writeTestFile(t, filepath.Join(dir, "snippet_main.go"), "package main\n")
+		if _, err := application.store.RecordProjectOpen(ctx, projectPath, ""); err != nil {
+			t.Fatalf("RecordProjectOpen(%s) error = %v", name, err)
+		}
+		return dir
+	}
+	staleCache := runCache("stale")
+	ageTree(t, staleCache, 30*24*time.Hour)
+	freshCache := runCache("fresh")
+
+	sweep := application.sweepOrphans(ctx, tempDir)
+
+	removed := []string{abandoned, staleCache}
+	kept := []string{own, live, unrelated, freshCache}
+	if _, known := procmem.Running(os.Getpid()); known {
+		removed = append(removed, dead)
+	} else {
+		kept = append(kept, dead)
+	}
+	for _, dir := range removed {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after sweep: %v", dir, err)
+		}
+	}
+	for _, dir := range kept {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("%s removed by sweep: %v", dir, err)
+		}
+	}
+	if sweep.dirs != len(removed) || sweep.reclaimedBytes <= 0 {
+		t.Fatalf("sweep = %+v, want %d dirs and reclaimed bytes", sweep, len(removed))
+	}
+}
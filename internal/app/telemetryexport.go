@@ -0,0 +1,34 @@
+package app
+
+import (
+	"strings"
+
+	"gopoke/internal/lsp"
+	"gopoke/internal/settings"
+)
+
+// applyTelemetryExport starts, moves or stops span export to match the
+// OTLP endpoint setting. LSP requests are only timed while export is on.
+func (a *Application) applyTelemetryExport(gs settings.GlobalSettings) {
+	endpoint := strings.TrimSpace(gs.OTLPEndpoint)
+	if err := a.telemetry.ConfigureExport(endpoint, a.logger); err != nil {
+		a.logger.Warn("configure telemetry export", "error", err)
+		endpoint = ""
+	}
+	if a.lspManager == nil {
+		return
+	}
+	if endpoint == "" {
+		a.lspManager.SetRequestHandler(nil)
+		return
+	}
+	a.lspManager.SetRequestHandler(a.recordLSPRequest)
+}
+
+// recordLSPRequest exports one editor request answered by gopls.
+func (a *Application) recordLSPRequest(event lsp.RequestEvent) {
+	a.telemetry.RecordSpan("lsp "+event.Method, event.StartedAt, event.StartedAt.Add(event.Duration), map[string]string{
+		"rpc.system": "jsonrpc",
+		"rpc.method": event.Method,
+	}, event.Error)
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/settings"
+)
+
+func TestRunSpansExportToCollector(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	names := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []struct {
+						Name string `json:"name"`
+					} `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode export: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, resource := range request.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				for _, span := range scope.Spans {
+					names = append(names, span.Name)
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	if _, err := application.UpdateGlobalSettings(ctx, settings.GlobalSettings{OTLPEndpoint: "localhost:4318"}); err == nil {
+		t.Fatal("UpdateGlobalSettings(bad endpoint) error = nil, want error")
+	}
+	if _, err := application.UpdateGlobalSettings(ctx, settings.GlobalSettings{OTLPEndpoint: server.URL}); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+	if _, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, Source: "package main\n\nfunc main() {}\n"}, nil, nil); err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	if err := application.telemetry.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	for _, want := range []string{"run", "run.resolve", "run.execute", "run.record"} {
+		if !slices.Contains(names, want) {
+			t.Fatalf("exported spans = %v, want %q", names, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/project"
+	"gopoke/internal/storage"
+)
+
+// SetProjectTestCache sets whether a project's test runs replay cached go
+// test results or always rerun.
+func (a *Application) SetProjectTestCache(ctx context.Context, projectPath string, mode string) (storage.ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project test cache context: %w", err)
+	}
+	normalized, err := project.NormalizeTestCacheMode(mode)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	updated, err := a.store.UpdateProjectTestCache(ctx, record.Path, normalized)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project test cache: %w", err)
+	}
+	return updated, nil
+}
+
+// ClearTestCache expires cached go test results for a project with its
+// toolchain and environment, and returns the go command's output.
+func (a *Application) ClearTestCache(ctx context.Context, projectPath string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("clear test cache context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return "", err
+	}
+	toolchainName := record.Toolchain
+	if toolchainName == "" {
+		toolchainName = "go"
+	}
+	toolchain, err := project.ResolveToolchainBinary(toolchainName)
+	if err != nil {
+		return "", fmt.Errorf("resolve project toolchain: %w", err)
+	}
+	environment, err := a.store.ProjectEnvMap(ctx, record.ID)
+	if err != nil {
+		return "", fmt.Errorf("load project env: %w", err)
+	}
+	output, err := project.ClearTestCache(ctx, toolchain, record.Path, environment)
+	if err != nil {
+		return output, fmt.Errorf("clear test cache: %w", err)
+	}
+	return output, nil
+}
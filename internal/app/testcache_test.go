@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"gopoke/internal/project"
+)
+
+func TestSetProjectTestCache(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	updated, err := application.SetProjectTestCache(ctx, projectDir, " Use ")
+	if err != nil {
+		t.Fatalf("SetProjectTestCache(use) error = %v", err)
+	}
+	if updated.TestCache != project.TestCacheUse {
+		t.Fatalf("TestCache = %q, want %q", updated.TestCache, project.TestCacheUse)
+	}
+	updated, err = application.SetProjectTestCache(ctx, projectDir, "")
+	if err != nil {
+		t.Fatalf("SetProjectTestCache(default) error = %v", err)
+	}
+	if updated.TestCache != project.TestCacheForce {
+		t.Fatalf("TestCache = %q, want %q", updated.TestCache, project.TestCacheForce)
+	}
+	if _, err := application.SetProjectTestCache(ctx, projectDir, "never"); err == nil {
+		t.Fatal("SetProjectTestCache(invalid) error = nil, want error")
+	}
+}
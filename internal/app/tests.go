@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+	"gopoke/internal/snippetmeta"
+	"gopoke/internal/storage"
+)
+
+// TestRequest selects tests of a project package to run.
+type TestRequest struct {
+	RunID       string `json:"runId"`
+	ProjectPath string `json:"projectPath"`
+	// PackagePath is relative to the project, such as "./internal/store";
+	// empty tests the project root package.
+	PackagePath string `json:"packagePath"`
+	// TestName runs one test, benchmark, fuzz target or example; empty
+	// runs every test of the package.
+	TestName string `json:"testName,omitempty"`
+	Verbose  bool   `json:"verbose,omitempty"`
+	// TimeoutMS overrides the project's run timeout.
+	TimeoutMS int64 `json:"timeoutMs,omitempty"`
+}
+
+// testEnvironment returns the toolchain binary and environment a project's
+// go test commands use, as its snippet runs would.
+func (a *Application) testEnvironment(ctx context.Context, record storage.ProjectRecord) (string, map[string]string, error) {
+	toolchainName := strings.TrimSpace(record.Toolchain)
+	if toolchainName == "" {
+		toolchainName = "go"
+	}
+	toolchain, err := project.ResolveToolchainBinary(toolchainName)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolve project toolchain: %w", err)
+	}
+	environment, err := a.store.ProjectEnvMap(ctx, record.ID)
+	if err != nil {
+		return "", nil, fmt.Errorf("load project env: %w", err)
+	}
+	environment, err = gopathEnvironment(ctx, record.Path, environment)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := applyExperiments(environment, nil, record.Experiments); err != nil {
+		return "", nil, err
+	}
+	return toolchain, environment, nil
+}
+
+// ListTests lists the tests, benchmarks, fuzz targets and examples of a
+// project package, with go test -list. packagePath may be a pattern such as
+// "./..." to list several packages.
+func (a *Application) ListTests(ctx context.Context, projectPath string, packagePath string) ([]project.TestFunc, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("list tests context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	toolchain, environment, err := a.testEnvironment(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := project.ListTests(ctx, toolchain, record.Path, packagePath, environment)
+	if err != nil {
+		return nil, fmt.Errorf("list tests: %w", err)
+	}
+	return tests, nil
+}
+
+// RunTest runs the tests of a project package with go test, streaming
+// output to the handlers. It is an active run like a snippet run, so
+// CancelRun stops it, and its record joins the project's run history.
+func (a *Application) RunTest(
+	ctx context.Context,
+	request TestRequest,
+	onStdoutChunk execution.StdoutChunkHandler,
+	onStderrChunk execution.StderrChunkHandler,
+) (execution.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return execution.Result{}, fmt.Errorf("run test context: %w", err)
+	}
+	record, err := a.projectRecordByPath(ctx, request.ProjectPath)
+	if err != nil {
+		return execution.Result{}, err
+	}
+	toolchain, environment, err := a.testEnvironment(ctx, record)
+	if err != nil {
+		return execution.Result{}, err
+	}
+	limits, err := a.resolveRunLimits(ctx, execution.RunRequest{TimeoutMS: request.TimeoutMS}, snippetmeta.Meta{}, record)
+	if err != nil {
+		return execution.Result{}, err
+	}
+	limits = a.adjustLimitsForFilesystem(record.Path, limits)
+	packagePath := strings.TrimSpace(request.PackagePath)
+	if packagePath == "" {
+		packagePath = "."
+	}
+
+	runID := strings.TrimSpace(request.RunID)
+	if runID == "" {
+		runID = generateRunID()
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	if err := a.registerActiveRun(runID, cancel); err != nil {
+		cancel()
+		return execution.Result{}, fmt.Errorf("register active run: %w", err)
+	}
+	defer func() {
+		cancel()
+		a.unregisterActiveRun(runID)
+	}()
+	startedAt := a.clock()
+
+	result, err := execution.RunGoTest(runCtx, record.Path, execution.TestRun{
+		Package:   packagePath,
+		Name:      request.TestName,
+		Verbose:   request.Verbose,
+		CacheMode: record.TestCache,
+	}, execution.RunOptions{
+		Toolchain:         toolchain,
+		Environment:       environment,
+		Timeout:           time.Duration(limits.TimeoutMS) * time.Millisecond,
+		OnStdoutChunk:     onStdoutChunk,
+		OnStderrChunk:     onStderrChunk,
+		MaxStdoutBytes:    int(limits.MaxOutputBytes),
+		MaxStderrBytes:    int(limits.MaxOutputBytes),
+		OutputEncoding:    record.OutputEncoding,
+		MaxDiskWriteBytes: limits.MaxDiskWriteBytes,
+		MaxProcesses:      int(limits.MaxProcesses),
+		MaxOpenFiles:      int(limits.MaxOpenFiles),
+		OnStart: func(pid int) {
+			a.setActiveRunPID(runID, pid)
+		},
+	})
+	if err != nil {
+		return execution.Result{}, fmt.Errorf("run test: %w", err)
+	}
+	result.Limits = limits
+	resolved := resolvedRunRequest{projectID: record.ID, projectPath: record.Path, packagePath: packagePath}
+	if err := a.recordRunResult(ctx, runID, resolved, nil, startedAt, result); err != nil {
+		a.logger.Warn("record test run failed", "runID", runID, "error", err)
+	}
+	return result, nil
+}
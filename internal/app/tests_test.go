@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopoke/internal/project"
+)
+
+func TestListAndRunProjectTests(t *testing.T) {
+	requireGoToolchain(t)
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	writeTestFile(t, filepath.Join(projectDir, "store", "store.go"), "package store\n\nfunc Get() string { return \"value\" }\n")
+	writeTestFile(t, filepath.Join(projectDir, "store", "store_test.go"), "package store\n\nimport \"testing\"\n\n"+
+		"func TestGet(t *testing.T) {\n\tif Get() != \"value\" {\n\t\tt.Fatal(\"wrong value\")\n\t}\n}\n\n"+
+		"func TestMissing(t *testing.T) {\n\tt.Fatal(\"not found\")\n}\n")
+	opened, err := application.OpenProject(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	for key, value := range map[string]string{"GOPROXY": "off", "GOFLAGS": "", "GOWORK": "off"} {
+		if _, err := application.UpsertProjectEnvVar(ctx, projectDir, key, value, false); err != nil {
+			t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+		}
+	}
+
+	tests, err := application.ListTests(ctx, projectDir, "./store")
+	if err != nil {
+		t.Fatalf("ListTests() error = %v", err)
+	}
+	if len(tests) != 2 || tests[0].Name != "TestGet" || tests[0].Kind != project.TestKindTest || tests[0].Package != "example.com/gopoketest/store" {
+		t.Fatalf("ListTests() = %+v", tests)
+	}
+
+	var streamed strings.Builder
+	result, err := application.RunTest(ctx, TestRequest{RunID: "run_test_get", ProjectPath: projectDir, PackagePath: "./store", TestName: "TestGet", Verbose: true}, func(chunk string) {
+		streamed.WriteString(chunk)
+	}, nil)
+	if err != nil {
+		t.Fatalf("RunTest(TestGet) error = %v", err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(streamed.String(), "--- PASS: TestGet") {
+		t.Fatalf("RunTest(TestGet) = exit %d, streamed %q", result.ExitCode, streamed.String())
+	}
+
+	result, err = application.RunTest(ctx, TestRequest{ProjectPath: projectDir, PackagePath: "./store"}, nil, nil)
+	if err != nil {
+		t.Fatalf("RunTest(package) error = %v", err)
+	}
+	if result.ExitCode == 0 || !strings.Contains(result.Stdout, "--- FAIL: TestMissing") {
+		t.Fatalf("RunTest(package) = exit %d, stdout %q", result.ExitCode, result.Stdout)
+	}
+
+	runs, err := application.store.ProjectRuns(ctx, opened.Project.ID, 0)
+	if err != nil {
+		t.Fatalf("ProjectRuns() error = %v", err)
+	}
+	if len(runs) != 2 || runs[1].ID != "run_test_get" || runs[1].Status != runStatusSuccess || runs[0].Status != runStatusFailed {
+		t.Fatalf("ProjectRuns() = %+v, want both test runs recorded", runs)
+	}
+}
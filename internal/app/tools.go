@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"gopoke/internal/i18n"
+)
+
+// Tool action values tell the UI which one-click fix applies to a tool.
+const (
+	ToolActionInstall = "install"
+	ToolActionUpgrade = "upgrade"
+)
+
+// ToolVersions holds detected versions for key tools.
+type ToolVersions struct {
+	GoVersion          string       `json:"goVersion"`
+	GoPath             string       `json:"goPath"`
+	GoplsVersion       string       `json:"goplsVersion"`
+	GoplsPath          string       `json:"goplsPath"`
+	StaticcheckVersion string       `json:"staticcheckVersion"`
+	StaticcheckPath    string       `json:"staticcheckPath"`
+	Tools              []ToolStatus `json:"tools"`
+}
+
+// ToolStatus reports one tool's detected version against the minimum gopoke supports.
+type ToolStatus struct {
+	Name           string `json:"name"`
+	Path           string `json:"path"`
+	RawVersion     string `json:"rawVersion"`
+	Version        string `json:"version"`
+	MinimumVersion string `json:"minimumVersion"`
+	Installed      bool   `json:"installed"`
+	Supported      bool   `json:"supported"`
+	Installable    bool   `json:"installable"`
+	Action         string `json:"action"`
+	Hint           string `json:"hint"`
+}
+
+type toolSpec struct {
+	name        string
+	versionArgs []string
+	minimum     string
+	installable bool
+	manualHint  string // i18n key shown when the tool must be installed by hand
+}
+
+// knownTools lists detected tools in display order. Installable tools can be
+// installed or upgraded through the download manager.
+var knownTools = []toolSpec{
+	{name: "go", versionArgs: []string{"version"}, minimum: "1.22.0", installable: true},
+	{name: "gopls", versionArgs: []string{"version"}, minimum: "0.14.0", installable: true},
+	{name: "dlv", versionArgs: []string{"version"}, minimum: "1.22.0", installable: true},
+	{name: "golangci-lint", versionArgs: []string{"--version"}, minimum: "1.55.0", installable: true},
+	{name: "staticcheck", versionArgs: []string{"-version"}, installable: true},
+	{name: "git", versionArgs: []string{"--version"}, minimum: "2.25.0", manualHint: i18n.MsgToolGitManual},
+}
+
+var toolVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// DetectToolVersions checks installed tool versions against minimum supported versions.
+func (a *Application) DetectToolVersions(ctx context.Context) ToolVersions {
+	result := ToolVersions{Tools: make([]ToolStatus, 0, len(knownTools))}
+
+	for _, spec := range knownTools {
+		path, found := a.lookupTool(spec.name)
+		output := ""
+		if found {
+			if out, err := exec.CommandContext(ctx, path, spec.versionArgs...).Output(); err == nil {
+				output = strings.TrimSpace(string(out))
+			}
+		}
+		status := evaluateTool(spec, path, found, output, a.localizer())
+		result.Tools = append(result.Tools, status)
+
+		switch spec.name {
+		case "go":
+			result.GoPath, result.GoVersion = status.Path, status.RawVersion
+		case "gopls":
+			result.GoplsPath, result.GoplsVersion = status.Path, status.RawVersion
+		case "staticcheck":
+			result.StaticcheckPath, result.StaticcheckVersion = status.Path, status.RawVersion
+		}
+	}
+
+	return result
+}
+
+// lookupTool finds a tool on PATH, falling back to the managed tool directory.
+func (a *Application) lookupTool(name string) (string, bool) {
+	if path, err := exec.LookPath(name); err == nil {
+		return path, true
+	}
+	if a.toolBinDir == "" {
+		return "", false
+	}
+	binary := name
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	candidate := filepath.Join(a.toolBinDir, binary)
+	if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+		return candidate, true
+	}
+	return "", false
+}
+
+func evaluateTool(spec toolSpec, path string, found bool, output string, localizer *i18n.Localizer) ToolStatus {
+	status := ToolStatus{
+		Name:           spec.name,
+		MinimumVersion: spec.minimum,
+		Installable:    spec.installable,
+	}
+	if !found {
+		status.Hint = localizer.T(i18n.MsgToolNotFound, spec.name)
+		if spec.installable {
+			status.Action = ToolActionInstall
+		} else if spec.manualHint != "" {
+			status.Hint += "; " + localizer.T(spec.manualHint)
+		}
+		return status
+	}
+
+	status.Path = path
+	status.Installed = true
+	status.RawVersion = output
+	status.Version = parseToolVersion(output)
+	status.Supported = true
+	if spec.minimum == "" || status.Version == "" {
+		return status
+	}
+	if compareToolVersions(status.Version, spec.minimum) >= 0 {
+		return status
+	}
+
+	status.Supported = false
+	status.Hint = localizer.T(i18n.MsgToolOutdated, spec.name, status.Version, spec.minimum)
+	if spec.installable {
+		status.Action = ToolActionUpgrade
+	} else if spec.manualHint != "" {
+		status.Hint += "; " + localizer.T(spec.manualHint)
+	}
+	return status
+}
+
+// parseToolVersion extracts the first dotted version number from tool output,
+// e.g. "go version go1.22.3 linux/amd64" -> "1.22.3".
+func parseToolVersion(output string) string {
+	match := toolVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return ""
+	}
+	patch := match[3]
+	if patch == "" {
+		patch = "0"
+	}
+	return match[1] + "." + match[2] + "." + patch
+}
+
+// compareToolVersions compares two major.minor.patch strings.
+func compareToolVersions(left string, right string) int {
+	leftParts := strings.Split(left, ".")
+	rightParts := strings.Split(right, ".")
+	for i := 0; i < 3; i++ {
+		leftValue := versionPart(leftParts, i)
+		rightValue := versionPart(rightParts, i)
+		if leftValue != rightValue {
+			if leftValue < rightValue {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionPart(parts []string, index int) int {
+	if index >= len(parts) {
+		return 0
+	}
+	value, err := strconv.Atoi(parts[index])
+	if err != nil {
+		return 0
+	}
+	return value
+}
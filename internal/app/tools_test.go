@@ -0,0 +1,123 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gopoke/internal/i18n"
+)
+
+func TestParseToolVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{output: "go version go1.22.3 linux/amd64", want: "1.22.3"},
+		{output: "golang.org/x/tools/gopls v0.15.3\n    golang.org/x/tools/gopls@v0.15.3", want: "0.15.3"},
+		{output: "Delve Debugger\nVersion: 1.22.1\nBuild: $Id$", want: "1.22.1"},
+		{output: "golangci-lint has version 1.57.2 built with go1.22.1", want: "1.57.2"},
+		{output: "git version 2.43.0", want: "2.43.0"},
+		{output: "go version go1.23 darwin/arm64", want: "1.23.0"},
+		{output: "devel build", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseToolVersion(tt.output); got != tt.want {
+			t.Fatalf("parseToolVersion(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestCompareToolVersions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		left  string
+		right string
+		want  int
+	}{
+		{left: "1.22.0", right: "1.22.0", want: 0},
+		{left: "1.21.9", right: "1.22.0", want: -1},
+		{left: "1.10.0", right: "1.9.5", want: 1},
+		{left: "0.14.1", right: "0.14.0", want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := compareToolVersions(tt.left, tt.right); got != tt.want {
+			t.Fatalf("compareToolVersions(%q, %q) = %d, want %d", tt.left, tt.right, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateToolStatus(t *testing.T) {
+	t.Parallel()
+
+	gopls := toolSpec{name: "gopls", minimum: "0.14.0", installable: true}
+	git := toolSpec{name: "git", minimum: "2.25.0", manualHint: i18n.MsgToolGitManual}
+
+	missing := evaluateTool(gopls, "", false, "", nil)
+	if missing.Installed || missing.Action != ToolActionInstall {
+		t.Fatalf("missing gopls = %+v, want install action", missing)
+	}
+
+	outdated := evaluateTool(gopls, "/bin/gopls", true, "golang.org/x/tools/gopls v0.11.0", nil)
+	if outdated.Supported || outdated.Action != ToolActionUpgrade {
+		t.Fatalf("outdated gopls = %+v, want upgrade action", outdated)
+	}
+	if outdated.Hint == "" {
+		t.Fatal("outdated gopls hint is empty")
+	}
+
+	current := evaluateTool(gopls, "/bin/gopls", true, "golang.org/x/tools/gopls v0.16.2", nil)
+	if !current.Supported || current.Action != "" || current.Version != "0.16.2" {
+		t.Fatalf("current gopls = %+v, want supported without action", current)
+	}
+
+	oldGit := evaluateTool(git, "/usr/bin/git", true, "git version 2.17.1", i18n.New("es"))
+	if oldGit.Supported || oldGit.Action != "" {
+		t.Fatalf("old git = %+v, want unsupported without install action", oldGit)
+	}
+	if want := "git 2.17.1 es anterior a la versión mínima compatible 2.25.0; instala git con el gestor de paquetes del sistema"; oldGit.Hint != want {
+		t.Fatalf("old git hint = %q, want %q", oldGit.Hint, want)
+	}
+}
+
+func TestLookupToolFallsBackToManagedBinDir(t *testing.T) {
+	t.Parallel()
+
+	binDir := t.TempDir()
+	name := "gopoke-fake-tool"
+	binary := name
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	if err := os.WriteFile(filepath.Join(binDir, binary), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write fake tool: %v", err)
+	}
+
+	application := &Application{toolBinDir: binDir}
+	path, found := application.lookupTool(name)
+	if !found {
+		t.Fatal("lookupTool() found = false, want true")
+	}
+	if got, want := path, filepath.Join(binDir, binary); got != want {
+		t.Fatalf("lookupTool() = %q, want %q", got, want)
+	}
+	if _, found := application.lookupTool("gopoke-missing-tool"); found {
+		t.Fatal("lookupTool(missing) found = true, want false")
+	}
+}
+
+func TestNewWithDataRootKeepsManagedToolsInDataRoot(t *testing.T) {
+	t.Parallel()
+
+	dataRoot := t.TempDir()
+	application := NewWithDataRoot(dataRoot)
+	if got, want := application.toolBinDir, filepath.Join(dataRoot, "toolchain", "bin"); got != want {
+		t.Fatalf("toolBinDir = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"gopoke/internal/i18n"
+	"gopoke/internal/project"
+)
+
+// SetProjectTrust records the user's trust decision for a project and
+// reopens it, so trusting loads its .env file at once. Restricting a
+// project stops its worker and drops the variables it loaded from .env.
+func (a *Application) SetProjectTrust(ctx context.Context, projectPath string, trust string) (project.OpenProjectResult, error) {
+	if err := ctx.Err(); err != nil {
+		return project.OpenProjectResult{}, fmt.Errorf("set project trust context: %w", err)
+	}
+	if a.projects == nil {
+		return project.OpenProjectResult{}, fmt.Errorf("project service not initialized")
+	}
+	resolvedProjectPath, err := resolveInputPath(projectPath)
+	if err != nil {
+		return project.OpenProjectResult{}, err
+	}
+	record, err := a.projects.SetTrust(ctx, resolvedProjectPath, trust)
+	if err != nil {
+		return project.OpenProjectResult{}, fmt.Errorf("set project trust: %w", err)
+	}
+	if !record.Trusted() && a.workers != nil && a.workers.IsRunning(record.Path) {
+		if err := a.workers.StopWorker(ctx, record.Path); err != nil {
+			return project.OpenProjectResult{}, fmt.Errorf("stop untrusted project worker: %w", err)
+		}
+	}
+	result, err := a.projects.Open(ctx, record.Path)
+	if err != nil {
+		return project.OpenProjectResult{}, fmt.Errorf("reopen project: %w", err)
+	}
+	a.logger.Info("project trust updated", "projectPath", record.Path, "trust", trust)
+	return result, nil
+}
+
+// requireTrustedProject fails unless projectPath belongs to a trusted
+// project.
+func (a *Application) requireTrustedProject(ctx context.Context, projectPath string) error {
+	if a.store == nil {
+		return fmt.Errorf("storage service not initialized")
+	}
+	record, found, err := a.store.ProjectByPath(ctx, projectPath)
+	if err != nil {
+		return fmt.Errorf("load project context: %w", err)
+	}
+	if !found || !record.Trusted() {
+		return a.localizer().Error(i18n.MsgProjectNotTrusted)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gopoke/internal/storage"
+)
+
+func TestProjectTrustGatesDotEnvAndWorkers(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	writeTestFile(t, filepath.Join(projectDir, ".env"), "API_TOKEN=abc\n")
+
+	opened, err := application.OpenProject(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	if opened.Project.Trust != storage.TrustUnknown || len(opened.EnvVars) != 0 {
+		t.Fatalf("new project = %+v, env = %+v; want unknown trust and no .env", opened.Project, opened.EnvVars)
+	}
+	if err := application.requireTrustedProject(ctx, projectDir); err == nil {
+		t.Fatal("requireTrustedProject(unknown) error = nil, want error")
+	}
+
+	trusted, err := application.SetProjectTrust(ctx, projectDir, storage.TrustTrusted)
+	if err != nil {
+		t.Fatalf("SetProjectTrust(trusted) error = %v", err)
+	}
+	if len(trusted.EnvVars) != 1 || trusted.EnvVars[0].Key != "API_TOKEN" {
+		t.Fatalf("trusted env = %+v, want .env loaded", trusted.EnvVars)
+	}
+	if err := application.requireTrustedProject(ctx, projectDir); err != nil {
+		t.Fatalf("requireTrustedProject(trusted) error = %v", err)
+	}
+
+	if _, err := application.UpsertProjectEnvVar(ctx, projectDir, "LOG_LEVEL", "debug", false); err != nil {
+		t.Fatalf("UpsertProjectEnvVar() error = %v", err)
+	}
+
+	restricted, err := application.SetProjectTrust(ctx, projectDir, storage.TrustRestricted)
+	if err != nil {
+		t.Fatalf("SetProjectTrust(restricted) error = %v", err)
+	}
+	if len(restricted.EnvVars) != 1 || restricted.EnvVars[0].Key != "LOG_LEVEL" {
+		t.Fatalf("restricted env = %+v, want .env variables dropped and user variables kept", restricted.EnvVars)
+	}
+	if err := application.requireTrustedProject(ctx, projectDir); err == nil {
+		t.Fatal("requireTrustedProject(restricted) error = nil, want error")
+	}
+	if _, err := application.SetProjectTrust(ctx, projectDir, "maybe"); err == nil {
+		t.Fatal("SetProjectTrust(invalid) error = nil, want error")
+	}
+}
+
+func TestLegacyProjectRecordsStayTrusted(t *testing.T) {
+	if !(storage.ProjectRecord{}).Trusted() {
+		t.Fatal("legacy record without trust is not trusted")
+	}
+	if (storage.ProjectRecord{Trust: storage.TrustUnknown}).Trusted() {
+		t.Fatal("unknown record is trusted")
+	}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"gopoke/internal/download"
+	"gopoke/internal/update"
+)
+
+// CheckForUpdate queries the release feed on the configured update channel.
+func (a *Application) CheckForUpdate(ctx context.Context) (update.CheckResult, error) {
+	if err := ctx.Err(); err != nil {
+		return update.CheckResult{}, fmt.Errorf("check update context: %w", err)
+	}
+	if a.updates == nil {
+		return update.CheckResult{}, fmt.Errorf("updater not initialized")
+	}
+	gs, err := a.GetGlobalSettings(ctx)
+	if err != nil {
+		return update.CheckResult{}, fmt.Errorf("load settings: %w", err)
+	}
+	result, err := a.updates.Check(ctx, gs.UpdateChannel)
+	if err != nil {
+		return update.CheckResult{}, fmt.Errorf("check for update: %w", err)
+	}
+	return result, nil
+}
+
+// DownloadUpdate downloads and verifies the newest release on the configured
+// channel and stages it; LaunchPendingUpdate installs it when gopoke next
+// starts.
+func (a *Application) DownloadUpdate(ctx context.Context, onProgress download.OnProgress) (update.StagedUpdate, error) {
+	check, err := a.CheckForUpdate(ctx)
+	if err != nil {
+		return update.StagedUpdate{}, err
+	}
+	staged, err := a.updates.Stage(ctx, check, onProgress)
+	if err != nil {
+		return update.StagedUpdate{}, fmt.Errorf("stage update: %w", err)
+	}
+	return staged, nil
+}
+
+// PendingUpdate returns the staged update, or nil when none is waiting.
+func (a *Application) PendingUpdate(ctx context.Context) (*update.StagedUpdate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("pending update context: %w", err)
+	}
+	if a.updates == nil {
+		return nil, fmt.Errorf("updater not initialized")
+	}
+	staged, ok, err := a.updates.Pending()
+	if err != nil {
+		return nil, fmt.Errorf("pending update: %w", err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &staged, nil
+}
+
+// LaunchPendingUpdate installs an update staged by an earlier session and
+// restarts gopoke on it. Call it in main before New, so the executable is
+// never replaced while an application is running. It returns only when no
+// update was installed or installing failed, and the current build keeps
+// running.
+func LaunchPendingUpdate() error {
+	return update.NewUpdater(filepath.Join(defaultDataRoot(), "updates")).Launch()
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/storage"
+	"gopoke/internal/webhook"
+)
+
+// SetProjectWebhooks replaces the webhooks that receive a project's run
+// results. Hooks without an ID get one.
+func (a *Application) SetProjectWebhooks(ctx context.Context, projectPath string, hooks []storage.Webhook) (storage.ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project webhooks context: %w", err)
+	}
+	normalized, err := webhook.Normalize(hooks)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	updated, err := a.store.UpdateProjectWebhooks(ctx, record.Path, normalized)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project webhooks: %w", err)
+	}
+	return updated, nil
+}
+
+// TestProjectWebhook posts a sample successful run to one of the project's
+// webhooks and waits for the delivery, retries included.
+func (a *Application) TestProjectWebhook(ctx context.Context, projectPath string, hookID string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("test project webhook context: %w", err)
+	}
+	if a.webhooks == nil {
+		return fmt.Errorf("run webhooks not initialized")
+	}
+	record, err := a.projectRecordByPath(ctx, projectPath)
+	if err != nil {
+		return err
+	}
+	for _, hook := range record.Webhooks {
+		if hook.ID != strings.TrimSpace(hookID) {
+			continue
+		}
+		target, err := webhookTarget(hook)
+		if err != nil {
+			return err
+		}
+		payload := webhook.NewPayload("test", record.Path, filepath.Base(record.Path), "", "webhook test", runStatusSuccess, 0, 0, "hello from gopoke\n", "", a.clock())
+		return a.webhooks.Deliver(ctx, target, payload)
+	}
+	return fmt.Errorf("webhook %q not found", hookID)
+}
+
+// notifyRunWebhooks sends a finished project run to the project's hooks
+// that want its status. Deliveries run in the background.
+func (a *Application) notifyRunWebhooks(request execution.RunRequest, result execution.Result) {
+	if a.webhooks == nil || strings.TrimSpace(request.ProjectPath) == "" {
+		return
+	}
+	ctx := context.Background()
+	record, err := a.projectRecordByPath(ctx, request.ProjectPath)
+	if err != nil || len(record.Webhooks) == 0 {
+		return
+	}
+	status := runStatusFromResult(result)
+	snippetName := ""
+	if request.SnippetID != "" {
+		if snippet, ok, err := a.store.SnippetByID(ctx, request.SnippetID); err == nil && ok && snippet.ProjectID == record.ID {
+			snippetName = snippet.Name
+		}
+	}
+	payload := webhook.NewPayload(request.RunID, record.Path, filepath.Base(record.Path), request.SnippetID, snippetName,
+		status, result.ExitCode, result.DurationMS, result.Stdout, result.Stderr, a.clock())
+	for _, hook := range record.Webhooks {
+		if !webhook.Matches(hook, status) {
+			continue
+		}
+		target, err := webhookTarget(hook)
+		if err != nil {
+			a.logger.Warn("skip run webhook", "projectPath", record.Path, "webhook", hook.ID, "error", err)
+			continue
+		}
+		a.webhooks.Send(target, payload)
+	}
+}
+
+// webhookTarget resolves a hook's signing secret. A hook naming an unset
+// secret variable is not sent, as receivers would reject it unsigned.
+func webhookTarget(hook storage.Webhook) (webhook.Target, error) {
+	target := webhook.Target{URL: hook.URL}
+	if hook.SecretEnv != "" {
+		target.Secret = os.Getenv(hook.SecretEnv)
+		if target.Secret == "" {
+			return webhook.Target{}, fmt.Errorf("webhook secret variable %s is not set", hook.SecretEnv)
+		}
+	}
+	return target, nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/storage"
+	"gopoke/internal/webhook"
+)
+
+func TestRunWebhooksFilterByStatus(t *testing.T) {
+	ctx := context.Background()
+	application := newTestApplication(t)
+	application.backend = &execution.FakeBackend{}
+	application.webhooks = webhook.NewDispatcher(nil, webhook.WithRetry(2, time.Millisecond))
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(ctx, projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	received := make(map[string][]webhook.Payload)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhook.Payload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], payload)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	if _, err := application.SetProjectWebhooks(ctx, projectDir, []storage.Webhook{{URL: server.URL, Statuses: []string{"bogus"}}}); err == nil {
+		t.Fatal("SetProjectWebhooks(unknown status) error = nil, want error")
+	}
+	record, err := application.SetProjectWebhooks(ctx, projectDir, []storage.Webhook{
+		{URL: server.URL + "/all"},
+		{URL: server.URL + "/failures", Statuses: []string{runStatusFailed}},
+	})
+	if err != nil {
+		t.Fatalf("SetProjectWebhooks() error = %v", err)
+	}
+	if len(record.Webhooks) != 2 || record.Webhooks[0].ID == "" {
+		t.Fatalf("Webhooks = %+v, want two hooks with IDs", record.Webhooks)
+	}
+	snippet, err := application.SaveProjectSnippet(ctx, projectDir, "", "probe", "package main\n")
+	if err != nil {
+		t.Fatalf("SaveProjectSnippet() error = %v", err)
+	}
+
+	for _, source := range []string{
+		"package main\n\n//stdout: ok\nfunc main() {}\n",
+		"package main\n\n//exit: 3\nfunc main() {}\n",
+	} {
+		if _, err := application.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectDir, SnippetID: snippet.ID, Source: source}, nil, nil); err != nil {
+			t.Fatalf("RunSnippet() error = %v", err)
+		}
+	}
+	if err := application.webhooks.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if got := received["/all"]; len(got) != 2 {
+		t.Fatalf("/all received %d payloads, want 2", len(got))
+	}
+	failures := received["/failures"]
+	if len(failures) != 1 {
+		t.Fatalf("/failures received %d payloads, want 1", len(failures))
+	}
+	if failures[0].Status != runStatusFailed || failures[0].ExitCode != 3 || failures[0].SnippetName != "probe" {
+		t.Fatalf("failure payload = %+v, want exit 3 of probe", failures[0])
+	}
+}
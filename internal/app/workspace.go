@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gopoke/internal/lsp"
+	"gopoke/internal/project"
+	"gopoke/internal/storage"
+)
+
+// WorkspaceRestore is everything the frontend needs to reopen a project,
+// gathered in one call.
+type WorkspaceRestore struct {
+	// Project holds the module, run targets and environment variables.
+	Project  project.OpenProjectResult `json:"project"`
+	Snippets []storage.SnippetRecord   `json:"snippets"`
+	// LSP is the language server workspace; LSPPort is 0 when it did not
+	// start.
+	LSP     lsp.WorkspaceInfo `json:"lsp"`
+	LSPPort int               `json:"lspPort"`
+	// Warnings lists parts that failed without failing the restore, such
+	// as the language server.
+	Warnings   []string `json:"warnings,omitempty"`
+	DurationMS int64    `json:"durationMs"`
+}
+
+// RestoreWorkspace reopens a project for the frontend at startup. Opening
+// the project, which lists packages with go list, runs alongside starting
+// the language server, so a large project's cold open costs the slower of
+// the two rather than their sum. A language server that fails to start
+// is reported as a warning; the editor falls back as it does for StartLSP.
+func (a *Application) RestoreWorkspace(ctx context.Context, projectPath string) (WorkspaceRestore, error) {
+	if err := ctx.Err(); err != nil {
+		return WorkspaceRestore{}, fmt.Errorf("restore workspace context: %w", err)
+	}
+	started := a.clock()
+
+	var restore WorkspaceRestore
+	var openErr, lspErr error
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		restore.Project, openErr = a.OpenProject(ctx, projectPath)
+	})
+	wg.Go(func() {
+		// The language server outlives this call.
+		lspErr = a.StartLSP(context.WithoutCancel(ctx), projectPath)
+	})
+	wg.Wait()
+	if openErr != nil {
+		return WorkspaceRestore{}, fmt.Errorf("restore workspace: %w", openErr)
+	}
+
+	if lspErr != nil {
+		a.logger.Warn("restore workspace: start language server", "error", lspErr)
+		restore.Warnings = append(restore.Warnings, fmt.Sprintf("language server: %v", lspErr))
+	} else {
+		restore.LSP = a.LSPWorkspaceInfo(ctx)
+		restore.LSPPort = a.LSPWebSocketPort(ctx)
+	}
+
+	snippets, err := a.store.ProjectSnippets(ctx, restore.Project.Project.ID)
+	if err != nil {
+		return WorkspaceRestore{}, fmt.Errorf("restore workspace: load project snippets: %w", err)
+	}
+	restore.Snippets = snippets
+	restore.DurationMS = a.clock().Sub(started).Milliseconds()
+	return restore, nil
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRestoreWorkspaceReturnsProjectAndSnippets(t *testing.T) {
+	t.Parallel()
+	requireGoToolchain(t)
+
+	application := newTestApplication(t)
+	projectDir := t.TempDir()
+	setupRunnableProject(t, projectDir)
+	if _, err := application.OpenProject(context.Background(), projectDir); err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	saved, err := application.SaveProjectSnippet(context.Background(), projectDir, "", "hello", "package main\n\nfunc main() {}\n")
+	if err != nil {
+		t.Fatalf("SaveProjectSnippet() error = %v", err)
+	}
+
+	restore, err := application.RestoreWorkspace(context.Background(), projectDir)
+	if err != nil {
+		t.Fatalf("RestoreWorkspace() error = %v", err)
+	}
+	if !restore.Project.Module.HasModule || len(restore.Project.Targets) != 2 {
+		t.Fatalf("Project = %+v, want the module and both run targets", restore.Project)
+	}
+	if len(restore.Snippets) != 1 || restore.Snippets[0].ID != saved.ID {
+		t.Fatalf("Snippets = %+v, want the saved snippet", restore.Snippets)
+	}
+	// The test application has no language server manager, so that part
+	// degrades to a warning instead of failing the restore.
+	if restore.LSPPort != 0 || len(restore.Warnings) != 1 || !strings.HasPrefix(restore.Warnings[0], "language server:") {
+		t.Fatalf("LSPPort = %d, Warnings = %v", restore.LSPPort, restore.Warnings)
+	}
+
+	if _, err := application.RestoreWorkspace(context.Background(), projectDir+"-missing"); err == nil {
+		t.Fatal("RestoreWorkspace(missing) error = nil")
+	}
+}
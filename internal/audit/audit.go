@@ -0,0 +1,184 @@
+// Package audit keeps an append-only JSON Lines log of file writes and
+// external commands made on the user's behalf, for review in regulated or
+// shared environments.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Actions recorded in the log.
+const (
+	ActionSaveFile         = "save_file"
+	ActionGoModReplace     = "gomod_replace"
+	ActionGoModDropRequire = "gomod_drop_require"
+	ActionGoModTidy        = "gomod_tidy"
+	ActionGoWorkCreate     = "gowork_create"
+	ActionGoWorkAddModule  = "gowork_add_module"
+	ActionGoWorkDropModule = "gowork_drop_module"
+	ActionRun              = "run"
+	ActionKillProcess      = "kill_process"
+	ActionCleanFootprint   = "clean_footprint"
+	ActionExportRunEvents  = "export_run_events"
+	ActionSyncSnippets     = "sync_snippets"
+	ActionForgetProject    = "forget_project"
+	ActionApplyModChanges  = "apply_module_changes"
+	ActionClearCache       = "clear_cache"
+)
+
+// DefaultQueryLimit caps Query results when the filter sets no limit.
+const DefaultQueryLimit = 500
+
+// maxLineBytes bounds one log line when reading it back.
+const maxLineBytes = 1024 * 1024
+
+// Entry is one audited action. Error is set when the action failed.
+type Entry struct {
+	Time        time.Time         `json:"time"`
+	Action      string            `json:"action"`
+	ProjectPath string            `json:"projectPath,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// Filter selects entries. Zero fields match everything.
+type Filter struct {
+	Actions     []string  `json:"actions,omitempty"`
+	ProjectPath string    `json:"projectPath,omitempty"`
+	Since       time.Time `json:"since,omitempty"`
+	Until       time.Time `json:"until,omitempty"`
+	// Limit caps the result; zero means DefaultQueryLimit.
+	Limit int `json:"limit,omitempty"`
+}
+
+func (f Filter) matches(entry Entry) bool {
+	if len(f.Actions) > 0 && !slices.Contains(f.Actions, entry.Action) {
+		return false
+	}
+	if f.ProjectPath != "" && entry.ProjectPath != f.ProjectPath {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Log appends entries to one file. Entries are never rewritten or removed.
+// It is safe for concurrent use.
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// New returns a log stored at path. The file is created on first append.
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the log file location.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes entry as one line. A zero Time is set to now.
+func (l *Log) Append(entry Entry) error {
+	if entry.Action == "" {
+		return fmt.Errorf("audit action is required")
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Time = entry.Time.UTC()
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("create audit directory: %w", err)
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	if tornTail(file) {
+		// Finish a line cut short by a crash so this entry starts clean.
+		line = append([]byte{'\n'}, line...)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("append audit entry: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close audit log: %w", err)
+	}
+	return nil
+}
+
+// tornTail reports whether the file ends without a newline.
+func tornTail(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return false
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return false
+	}
+	return last[0] != '\n'
+}
+
+// Query returns matching entries, newest first. Lines that do not decode,
+// such as a line cut short by a crash, are skipped.
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultQueryLimit
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	file, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	defer file.Close()
+
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	slices.Reverse(entries)
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndQuery(t *testing.T) {
+	t.Parallel()
+
+	log := New(filepath.Join(t.TempDir(), "audit", "audit.log"))
+	start := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: start, Action: ActionSaveFile, ProjectPath: "/p", Params: map[string]string{"path": "/p/main.go"}},
+		{Time: start.Add(time.Minute), Action: ActionRun, ProjectPath: "/p", Params: map[string]string{"runId": "run_1"}},
+		{Time: start.Add(2 * time.Minute), Action: ActionRun, ProjectPath: "/q", Error: "boom"},
+	}
+	for _, entry := range entries {
+		if err := log.Append(entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	all, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(all) != 3 || all[0].ProjectPath != "/q" || all[2].Action != ActionSaveFile {
+		t.Fatalf("Query(all) = %+v, want newest first", all)
+	}
+
+	runs, err := log.Query(Filter{Actions: []string{ActionRun}, ProjectPath: "/p"})
+	if err != nil {
+		t.Fatalf("Query(runs) error = %v", err)
+	}
+	if len(runs) != 1 || runs[0].Params["runId"] != "run_1" {
+		t.Fatalf("Query(runs) = %+v", runs)
+	}
+
+	windowed, err := log.Query(Filter{Since: start.Add(30 * time.Second), Until: start.Add(90 * time.Second)})
+	if err != nil {
+		t.Fatalf("Query(window) error = %v", err)
+	}
+	if len(windowed) != 1 || windowed[0].Action != ActionRun {
+		t.Fatalf("Query(window) = %+v", windowed)
+	}
+
+	limited, err := log.Query(Filter{Limit: 2})
+	if err != nil {
+		t.Fatalf("Query(limit) error = %v", err)
+	}
+	if len(limited) != 2 {
+		t.Fatalf("Query(limit) = %d entries, want 2", len(limited))
+	}
+}
+
+func TestQuerySkipsTornLinesAndMissingFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log := New(path)
+	if entries, err := log.Query(Filter{}); err != nil || len(entries) != 0 {
+		t.Fatalf("Query(missing) = %+v, %v; want empty", entries, err)
+	}
+	if err := log.Append(Entry{Action: ActionSaveFile}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	file.WriteString(`{"time":"2025-`)
+	file.Close()
+	if err := log.Append(Entry{Action: ActionKillProcess}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	entries, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Action != ActionKillProcess || entries[1].Action != ActionSaveFile {
+		t.Fatalf("Query() = %+v, want intact entries only", entries)
+	}
+	if err := log.Append(Entry{}); err == nil {
+		t.Fatal("Append(no action) error = nil, want error")
+	}
+}
@@ -0,0 +1,265 @@
+// Package benchenv measures how quickly this machine turns a snippet into
+// feedback. It runs a standard snippet set against each Go toolchain, once
+// cold with an empty build cache and then warm with the cache primed, the
+// same measurements the NFR benchmarks take, and renders a comparison.
+package benchenv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+)
+
+// Run modes.
+const (
+	ModeCold = "cold"
+	ModeWarm = "warm"
+)
+
+// DefaultWarmRuns is how many warm runs are averaged per snippet.
+const DefaultWarmRuns = 3
+
+// runTimeout bounds one run; cold runs compile the standard library.
+const runTimeout = 5 * time.Minute
+
+// Snippet is a program to measure. It must print as soon as main starts,
+// so first output marks the end of compilation.
+type Snippet struct {
+	Name   string
+	Source string
+}
+
+// StandardSnippets returns the snippet set bench-env measures.
+func StandardSnippets() []Snippet {
+	return []Snippet{
+		{Name: "hello", Source: "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"},
+		{Name: "json", Source: "package main\n\nimport (\n\t\"encoding/json\"\n\t\"os\"\n)\n\nfunc main() {\n\tjson.NewEncoder(os.Stdout).Encode(map[string]int{\"answer\": 42})\n}\n"},
+		{Name: "goroutines", Source: "package main\n\nimport (\n\t\"fmt\"\n\t\"sync\"\n)\n\nfunc main() {\n\tfmt.Println(\"start\")\n\tvar wg sync.WaitGroup\n\tfor i := 0; i < 4; i++ {\n\t\twg.Add(1)\n\t\tgo func(i int) {\n\t\t\tdefer wg.Done()\n\t\t\tfmt.Println(i)\n\t\t}(i)\n\t}\n\twg.Wait()\n}\n"},
+	}
+}
+
+// Options configures a benchmark.
+type Options struct {
+	// Toolchains to measure; each needs a Path.
+	Toolchains []project.ToolchainInfo
+	// Snippets defaults to StandardSnippets.
+	Snippets []Snippet
+	// WarmRuns defaults to DefaultWarmRuns.
+	WarmRuns int
+	// Progress, when set, receives a line per run.
+	Progress io.Writer
+}
+
+// Measurement is one snippet in one mode; warm values are means.
+type Measurement struct {
+	Toolchain string `json:"toolchain"`
+	Version   string `json:"version"`
+	Snippet   string `json:"snippet"`
+	Mode      string `json:"mode"`
+	Runs      int    `json:"runs"`
+	// StartupMS is the time until the go command was running.
+	StartupMS float64 `json:"startupMs"`
+	// CompileMS is from process start to first output: building and
+	// starting the program.
+	CompileMS float64 `json:"compileMs"`
+	// FirstFeedbackMS is from the run request to first output.
+	FirstFeedbackMS float64 `json:"firstFeedbackMs"`
+	TotalMS         float64 `json:"totalMs"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// Report holds every measurement in run order.
+type Report struct {
+	Measurements []Measurement `json:"measurements"`
+}
+
+// Run measures each snippet cold and warm against each toolchain. Every
+// snippet starts from its own empty build cache so cold numbers do not
+// depend on the order snippets run in.
+func Run(ctx context.Context, options Options) (Report, error) {
+	if len(options.Toolchains) == 0 {
+		return Report{}, fmt.Errorf("no Go toolchains found")
+	}
+	snippets := options.Snippets
+	if len(snippets) == 0 {
+		snippets = StandardSnippets()
+	}
+	warmRuns := options.WarmRuns
+	if warmRuns <= 0 {
+		warmRuns = DefaultWarmRuns
+	}
+	root, err := os.MkdirTemp("", "gopoke-bench-env-")
+	if err != nil {
+		return Report{}, fmt.Errorf("create bench workspace: %w", err)
+	}
+	defer os.RemoveAll(root)
+
+	report := Report{Measurements: make([]Measurement, 0, len(options.Toolchains)*len(snippets)*2)}
+	for toolchainIndex, toolchain := range options.Toolchains {
+		for snippetIndex, snippet := range snippets {
+			dir := filepath.Join(root, fmt.Sprintf("%d-%d", toolchainIndex, snippetIndex))
+			if err := os.MkdirAll(filepath.Join(dir, "cache"), 0o755); err != nil {
+				return Report{}, fmt.Errorf("create bench workspace: %w", err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module gopoke-bench-env\n\ngo 1.21\n"), 0o644); err != nil {
+				return Report{}, fmt.Errorf("write bench go.mod: %w", err)
+			}
+			environment := map[string]string{
+				"GOCACHE":     filepath.Join(dir, "cache"),
+				"GOTOOLCHAIN": "local",
+				"GOFLAGS":     "",
+			}
+			for _, mode := range []string{ModeCold, ModeWarm} {
+				runs := 1
+				if mode == ModeWarm {
+					runs = warmRuns
+				}
+				measurement := Measurement{Toolchain: toolchain.Name, Version: shortVersion(toolchain.Version), Snippet: snippet.Name, Mode: mode}
+				for range runs {
+					if err := ctx.Err(); err != nil {
+						return Report{}, fmt.Errorf("bench-env context: %w", err)
+					}
+					sample, err := measure(ctx, toolchain.Path, dir, snippet.Source, environment)
+					if err != nil {
+						measurement.Error = err.Error()
+						break
+					}
+					measurement.Runs++
+					measurement.StartupMS += sample.StartupMS
+					measurement.CompileMS += sample.CompileMS
+					measurement.FirstFeedbackMS += sample.FirstFeedbackMS
+					measurement.TotalMS += sample.TotalMS
+				}
+				if measurement.Runs > 0 {
+					count := float64(measurement.Runs)
+					measurement.StartupMS /= count
+					measurement.CompileMS /= count
+					measurement.FirstFeedbackMS /= count
+					measurement.TotalMS /= count
+				}
+				if options.Progress != nil {
+					fmt.Fprintf(options.Progress, "%s %s %s: %.0f ms to first feedback\n", toolchain.Name, snippet.Name, mode, measurement.FirstFeedbackMS)
+				}
+				report.Measurements = append(report.Measurements, measurement)
+			}
+		}
+	}
+	return report, nil
+}
+
+// measure runs source once and times its phases.
+func measure(ctx context.Context, toolchain string, dir string, source string, environment map[string]string) (Measurement, error) {
+	var mu sync.Mutex
+	var startedAt, firstOutputAt time.Time
+	onOutput := func(chunk string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if chunk != "" && firstOutputAt.IsZero() {
+			firstOutputAt = time.Now()
+		}
+	}
+	triggeredAt := time.Now()
+	result, err := execution.RunGoSnippetWithOptions(ctx, dir, source, execution.RunOptions{
+		WorkingDirectory: dir,
+		Environment:      environment,
+		Toolchain:        toolchain,
+		Timeout:          runTimeout,
+		OnStdoutChunk:    onOutput,
+		OnStderrChunk:    onOutput,
+		OnStart: func(int) {
+			mu.Lock()
+			defer mu.Unlock()
+			startedAt = time.Now()
+		},
+	})
+	completedAt := time.Now()
+	if err != nil {
+		return Measurement{}, err
+	}
+	if result.ExitCode != 0 {
+		return Measurement{}, fmt.Errorf("exit code %d: %s", result.ExitCode, firstLine(result.Stderr))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if startedAt.IsZero() {
+		startedAt = triggeredAt
+	}
+	if firstOutputAt.IsZero() {
+		firstOutputAt = completedAt
+	}
+	return Measurement{
+		StartupMS:       milliseconds(startedAt.Sub(triggeredAt)),
+		CompileMS:       milliseconds(firstOutputAt.Sub(startedAt)),
+		FirstFeedbackMS: milliseconds(firstOutputAt.Sub(triggeredAt)),
+		TotalMS:         milliseconds(completedAt.Sub(triggeredAt)),
+	}, nil
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return duration.Seconds() * 1000
+}
+
+// shortVersion turns "go version go1.22.4 linux/amd64" into "go1.22.4".
+func shortVersion(version string) string {
+	if fields := strings.Fields(version); len(fields) >= 3 && fields[0] == "go" && fields[1] == "version" {
+		return fields[2]
+	}
+	return version
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
+
+// WriteTable renders the report as a table followed by the warm speedup of
+// each toolchain.
+func WriteTable(w io.Writer, report Report) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TOOLCHAIN\tVERSION\tSNIPPET\tMODE\tSTARTUP\tCOMPILE\tFIRST FEEDBACK\tTOTAL")
+	type totals struct{ cold, warm float64 }
+	byToolchain := make(map[string]*totals)
+	order := make([]string, 0)
+	for _, m := range report.Measurements {
+		if m.Error != "" {
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\terror: %s\n", m.Toolchain, m.Version, m.Snippet, m.Mode, m.Error)
+			continue
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%.0f ms\t%.0f ms\t%.0f ms\t%.0f ms\n",
+			m.Toolchain, m.Version, m.Snippet, m.Mode, m.StartupMS, m.CompileMS, m.FirstFeedbackMS, m.TotalMS)
+		sums, ok := byToolchain[m.Toolchain]
+		if !ok {
+			sums = &totals{}
+			byToolchain[m.Toolchain] = sums
+			order = append(order, m.Toolchain)
+		}
+		if m.Mode == ModeCold {
+			sums.cold += m.FirstFeedbackMS
+		} else {
+			sums.warm += m.FirstFeedbackMS
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("write bench table: %w", err)
+	}
+	for _, name := range order {
+		sums := byToolchain[name]
+		if sums.cold == 0 || sums.warm == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: warm runs give first feedback %.1fx faster than cold runs\n", name, sums.cold/sums.warm); err != nil {
+			return fmt.Errorf("write bench summary: %w", err)
+		}
+	}
+	return nil
+}
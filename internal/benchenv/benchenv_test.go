@@ -0,0 +1,70 @@
+package benchenv
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopoke/internal/project"
+	"gopoke/internal/testharness"
+)
+
+func TestRunMeasuresColdAndWarm(t *testing.T) {
+	t.Parallel()
+
+	binDir := t.TempDir()
+	if err := testharness.WriteSyntheticToolchain(binDir); err != nil {
+		t.Skipf("synthetic toolchain unavailable: %v", err)
+	}
+	toolchain := project.ToolchainInfo{Name: "go", Path: filepath.Join(binDir, "go"), Version: testharness.SyntheticGoVersion}
+
+	var progress bytes.Buffer
+	report, err := Run(context.Background(), Options{
+		Toolchains: []project.ToolchainInfo{toolchain},
+		Snippets: []Snippet{
+			{Name: "ok", Source: "package main\n\n//stdout: ready\nfunc main() {}\n"},
+			{Name: "broken", Source: "package main\n\n//stderr: undefined: x\n//exit: 1\nfunc main() {}\n"},
+		},
+		WarmRuns: 2,
+		Progress: &progress,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(report.Measurements) != 4 {
+		t.Fatalf("measurements = %+v, want cold and warm for two snippets", report.Measurements)
+	}
+	cold, warm := report.Measurements[0], report.Measurements[1]
+	if cold.Mode != ModeCold || cold.Runs != 1 || warm.Mode != ModeWarm || warm.Runs != 2 {
+		t.Fatalf("ok measurements = %+v / %+v, want one cold and two warm runs", cold, warm)
+	}
+	if cold.FirstFeedbackMS <= 0 || cold.FirstFeedbackMS > cold.TotalMS || cold.Error != "" {
+		t.Fatalf("cold = %+v, want first feedback within the run", cold)
+	}
+	if broken := report.Measurements[2]; !strings.Contains(broken.Error, "undefined: x") || broken.Runs != 0 {
+		t.Fatalf("broken = %+v, want the compile error", broken)
+	}
+	if strings.Count(progress.String(), "\n") != 4 {
+		t.Fatalf("progress = %q, want a line per measurement", progress.String())
+	}
+
+	var table bytes.Buffer
+	if err := WriteTable(&table, report); err != nil {
+		t.Fatalf("WriteTable() error = %v", err)
+	}
+	for _, want := range []string{"FIRST FEEDBACK", testharness.SyntheticGoVersion, "error: exit code 1: undefined: x", "warm runs give first feedback"} {
+		if !strings.Contains(table.String(), want) {
+			t.Fatalf("table missing %q:\n%s", want, table.String())
+		}
+	}
+}
+
+func TestRunRequiresToolchain(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Run(context.Background(), Options{}); err == nil {
+		t.Fatal("Run() error = nil, want error without toolchains")
+	}
+}
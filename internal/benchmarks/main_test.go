@@ -0,0 +1,11 @@
+package benchmarks
+
+import (
+	"testing"
+
+	"gopoke/internal/testutil"
+)
+
+func TestMain(m *testing.M) {
+	testutil.Main(m)
+}
@@ -0,0 +1,207 @@
+// Package benchsnippet runs benchmark snippets and maps their results back
+// to the source. A snippet with Benchmark functions and no main gets a
+// generated main that runs each one with testing.Benchmark and prints
+// go test style result lines; those lines become inline annotations on the
+// benchmark functions.
+package benchsnippet
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Function is a benchmark function in a snippet. Line is one-based.
+type Function struct {
+	Name string
+	Line int
+}
+
+// Result is one parsed benchmark result line. Metrics absent from the line
+// are -1.
+type Result struct {
+	Name        string  `json:"name"`
+	Iterations  int64   `json:"iterations"`
+	NsPerOp     float64 `json:"nsPerOp"`
+	BytesPerOp  int64   `json:"bytesPerOp"`
+	AllocsPerOp int64   `json:"allocsPerOp"`
+}
+
+// Annotation places a benchmark result on the line of its function. Text is
+// a short badge such as "1234 ns/op · 16 B/op · 1 allocs/op".
+type Annotation struct {
+	Result
+	Function string `json:"function"`
+	Line     int    `json:"line"`
+	Text     string `json:"text"`
+}
+
+// resultLine matches go test benchmark output, with an optional -N
+// GOMAXPROCS suffix on the name.
+var resultLine = regexp.MustCompile(`^(Benchmark\S*?)(?:-\d+)?\s+(\d+)\s+(.+)$`)
+
+// Functions returns the benchmark functions of src: top-level functions
+// named Benchmark* taking a single *testing.B.
+func Functions(src string) []Function {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "snippet.go", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	functions := make([]Function, 0)
+	for _, decl := range file.Decls {
+		function, ok := decl.(*ast.FuncDecl)
+		if !ok || function.Recv != nil || !isBenchmark(function) {
+			continue
+		}
+		functions = append(functions, Function{Name: function.Name.Name, Line: fset.Position(function.Pos()).Line})
+	}
+	return functions
+}
+
+func isBenchmark(function *ast.FuncDecl) bool {
+	name := function.Name.Name
+	if !strings.HasPrefix(name, "Benchmark") {
+		return false
+	}
+	// As in go test, BenchmarkFoo counts but Benchmarkfoo does not.
+	if rest := name[len("Benchmark"):]; rest != "" && rest[0] >= 'a' && rest[0] <= 'z' {
+		return false
+	}
+	params := function.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	selector, ok := star.X.(*ast.SelectorExpr)
+	return ok && selector.Sel.Name == "B"
+}
+
+// IsBenchmarkSnippet reports whether src is a main package with benchmark
+// functions and no main function of its own.
+func IsBenchmarkSnippet(src string) bool {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "snippet.go", src, parser.SkipObjectResolution)
+	if err != nil || file.Name.Name != "main" {
+		return false
+	}
+	for _, decl := range file.Decls {
+		if function, ok := decl.(*ast.FuncDecl); ok && function.Recv == nil && function.Name.Name == "main" {
+			return false
+		}
+	}
+	return len(Functions(src)) > 0
+}
+
+// Harness returns src with a main that runs its benchmarks in source order.
+// Imports go on the package clause line and main at the end, so compile
+// errors keep the snippet's line numbers.
+func Harness(src string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "snippet.go", src, parser.PackageClauseOnly)
+	if err != nil {
+		return "", fmt.Errorf("parse benchmark snippet: %w", err)
+	}
+	functions := Functions(src)
+	if len(functions) == 0 {
+		return "", fmt.Errorf("snippet has no benchmark functions")
+	}
+	clauseEnd := fset.Position(file.Name.End()).Offset
+
+	var harness strings.Builder
+	harness.WriteString(src[:clauseEnd])
+	harness.WriteString(`; import (gopokeos "os"; gopoketesting "testing")`)
+	harness.WriteString(src[clauseEnd:])
+	if !strings.HasSuffix(src, "\n") {
+		harness.WriteString("\n")
+	}
+	harness.WriteString("\nfunc main() {\n")
+	harness.WriteString("\tgopoketesting.Init()\n")
+	for _, function := range functions {
+		fmt.Fprintf(&harness, "\t{\n\t\tresult := gopoketesting.Benchmark(%s)\n", function.Name)
+		fmt.Fprintf(&harness, "\t\tgopokeos.Stdout.WriteString(%q + \"\\t\" + result.String() + \"\\t\" + result.MemString() + \"\\n\")\n\t}\n", function.Name)
+	}
+	harness.WriteString("}\n")
+	return harness.String(), nil
+}
+
+// ParseResults extracts benchmark result lines from output.
+func ParseResults(output string) []Result {
+	results := make([]Result, 0)
+	for _, line := range strings.Split(output, "\n") {
+		match := resultLine.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		iterations, err := strconv.ParseInt(match[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		result := Result{Name: match[1], Iterations: iterations, NsPerOp: -1, BytesPerOp: -1, AllocsPerOp: -1}
+		fields := strings.Fields(match[3])
+		for i := 0; i+1 < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			switch fields[i+1] {
+			case "ns/op":
+				result.NsPerOp = value
+			case "B/op":
+				result.BytesPerOp = int64(value)
+			case "allocs/op":
+				result.AllocsPerOp = int64(value)
+			}
+		}
+		if result.NsPerOp >= 0 {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// Annotate maps results to the benchmark functions of src. Sub-benchmarks
+// annotate the function that runs them.
+func Annotate(src string, results []Result) []Annotation {
+	lines := make(map[string]int)
+	for _, function := range Functions(src) {
+		lines[function.Name] = function.Line
+	}
+	annotations := make([]Annotation, 0, len(results))
+	for _, result := range results {
+		function, _, _ := strings.Cut(result.Name, "/")
+		line, ok := lines[function]
+		if !ok {
+			continue
+		}
+		annotations = append(annotations, Annotation{Result: result, Function: function, Line: line, Text: badge(result)})
+	}
+	return annotations
+}
+
+func badge(result Result) string {
+	parts := []string{formatNs(result.NsPerOp) + " ns/op"}
+	if result.BytesPerOp >= 0 {
+		parts = append(parts, strconv.FormatInt(result.BytesPerOp, 10)+" B/op")
+	}
+	if result.AllocsPerOp >= 0 {
+		parts = append(parts, strconv.FormatInt(result.AllocsPerOp, 10)+" allocs/op")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// formatNs keeps fractional nanoseconds only for fast operations, as go
+// test does.
+func formatNs(ns float64) string {
+	if ns >= 100 {
+		return strconv.FormatFloat(ns, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(ns, 'f', -1, 64)
+}
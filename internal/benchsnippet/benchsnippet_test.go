@@ -0,0 +1,92 @@
+package benchsnippet
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const benchmarkSnippet = `package main
+
+import "testing"
+
+func BenchmarkSum(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = i + i
+	}
+}
+
+func Benchmarkhelper(b *testing.B) {}
+
+func BenchmarkParse(b *testing.B) {
+	b.Run("small", func(b *testing.B) {})
+}
+`
+
+func TestFunctionsAndDetection(t *testing.T) {
+	want := []Function{{Name: "BenchmarkSum", Line: 5}, {Name: "BenchmarkParse", Line: 14}}
+	if got := Functions(benchmarkSnippet); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Functions() = %+v, want %+v", got, want)
+	}
+	if !IsBenchmarkSnippet(benchmarkSnippet) {
+		t.Fatal("IsBenchmarkSnippet() = false, want true")
+	}
+	withMain := benchmarkSnippet + "\nfunc main() {}\n"
+	if IsBenchmarkSnippet(withMain) {
+		t.Fatal("IsBenchmarkSnippet(with main) = true, want false")
+	}
+	if IsBenchmarkSnippet("package main\n\nfunc main() {}\n") {
+		t.Fatal("IsBenchmarkSnippet(plain) = true, want false")
+	}
+}
+
+func TestHarnessKeepsLineNumbers(t *testing.T) {
+	harness, err := Harness(benchmarkSnippet)
+	if err != nil {
+		t.Fatalf("Harness() error = %v", err)
+	}
+	originalLines := strings.Split(benchmarkSnippet, "\n")
+	harnessLines := strings.Split(harness, "\n")
+	for i := 1; i < len(originalLines)-1; i++ {
+		if harnessLines[i] != originalLines[i] {
+			t.Fatalf("line %d = %q, want %q", i+1, harnessLines[i], originalLines[i])
+		}
+	}
+	if !strings.Contains(harness, "gopoketesting.Benchmark(BenchmarkSum)") || !strings.Contains(harness, "gopoketesting.Benchmark(BenchmarkParse)") {
+		t.Fatalf("harness does not run both benchmarks:\n%s", harness)
+	}
+	if strings.Contains(harness, "Benchmark(Benchmarkhelper)") {
+		t.Fatalf("harness runs a function go test would skip:\n%s", harness)
+	}
+}
+
+func TestParseResultsAndAnnotate(t *testing.T) {
+	output := strings.Join([]string{
+		"goos: linux",
+		"BenchmarkSum-8   \t1000000000\t         0.2513 ns/op\t       0 B/op\t       0 allocs/op",
+		"BenchmarkParse/small-8\t  52341\t     22891 ns/op",
+		"BenchmarkGone\t10\t5 ns/op",
+		"PASS",
+	}, "\n")
+	results := ParseResults(output)
+	want := []Result{
+		{Name: "BenchmarkSum", Iterations: 1000000000, NsPerOp: 0.2513, BytesPerOp: 0, AllocsPerOp: 0},
+		{Name: "BenchmarkParse/small", Iterations: 52341, NsPerOp: 22891, BytesPerOp: -1, AllocsPerOp: -1},
+		{Name: "BenchmarkGone", Iterations: 10, NsPerOp: 5, BytesPerOp: -1, AllocsPerOp: -1},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Fatalf("ParseResults() = %+v, want %+v", results, want)
+	}
+
+	annotations := Annotate(benchmarkSnippet, results)
+	if len(annotations) != 2 {
+		t.Fatalf("Annotate() = %+v, want two annotations", annotations)
+	}
+	if got := annotations[0]; got.Line != 5 || got.Text != "0.2513 ns/op · 0 B/op · 0 allocs/op" {
+		t.Fatalf("annotations[0] = %+v", got)
+	}
+	if got := annotations[1]; got.Function != "BenchmarkParse" || got.Line != 14 || got.Text != "22891 ns/op" {
+		t.Fatalf("annotations[1] = %+v", got)
+	}
+}
@@ -0,0 +1,122 @@
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gopoke/internal/app"
+	"gopoke/internal/download"
+	"gopoke/internal/eventschema"
+	"gopoke/internal/lsp"
+	"gopoke/internal/runner"
+)
+
+// DefaultEventNamespace prefixes the names of the bridge's own events, as
+// in "gopoke:run:stdout-chunk".
+const DefaultEventNamespace = "gopoke"
+
+// ToolchainCompleteEvent reports a finished toolchain, tool or update
+// download. Version is set for gopoke updates.
+type ToolchainCompleteEvent struct {
+	Tool    string `json:"tool"`
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+}
+
+// ToolchainErrorEvent reports a failed toolchain, tool or update download.
+// Canceled is set when the download was aborted with CancelDownload.
+type ToolchainErrorEvent struct {
+	Tool     string `json:"tool"`
+	Message  string `json:"message"`
+	Canceled bool   `json:"canceled,omitempty"`
+}
+
+// eventSchemas holds the payload schema of every event the bridge emits,
+// under the default event names.
+var eventSchemas = newEventSchemas()
+
+func newEventSchemas() *eventschema.Registry {
+	registry := eventschema.NewRegistry()
+	registry.Register(runStdoutChunkEventName, RunStdoutChunkEvent{})
+	registry.Register(runStderrChunkEventName, RunStderrChunkEvent{})
+	registry.Register(toolchainProgressEventName, download.Progress{})
+	registry.Register(toolchainCompleteEventName, ToolchainCompleteEvent{})
+	registry.Register(toolchainErrorEventName, ToolchainErrorEvent{})
+	registry.Register(workerLogEventName, runner.LogLine{})
+	registry.Register(lspAnalysisEventName, lsp.AnalysisEvent{})
+	registry.Register(lspMemoryEventName, lsp.MemoryWarning{})
+	registry.Register(runNetworkEventName, app.RunNetworkEvent{})
+	registry.Register(runNetworkPermissionEventName, app.RunNetworkPermissionRequest{})
+	registry.Register(capabilitiesEventName, app.CapabilityReport{})
+	return registry
+}
+
+// BridgeOption configures a WailsBridge.
+type BridgeOption func(b *WailsBridge)
+
+// WithEventNamespace emits the bridge's own events under namespace, as in
+// "acme:run:stdout-chunk", so several frontends can share one runtime. The
+// toolchain download events keep their global names. An empty namespace
+// keeps the default.
+func WithEventNamespace(namespace string) BridgeOption {
+	return func(b *WailsBridge) {
+		namespace = strings.Trim(strings.TrimSpace(namespace), ":")
+		if namespace != "" {
+			b.eventNamespace = namespace
+		}
+	}
+}
+
+// WithStrictEvents validates every payload against its event schema before
+// emitting it and panics on a mismatch or an unregistered event. Tests use
+// it so a payload change that would break the frontend fails the build.
+func WithStrictEvents() BridgeOption {
+	return func(b *WailsBridge) {
+		b.strictEvents = true
+	}
+}
+
+// eventName returns the emitted name of a default event name, with the
+// bridge's namespace in place of the default one.
+func (b *WailsBridge) eventName(name string) string {
+	if b.eventNamespace == "" || b.eventNamespace == DefaultEventNamespace {
+		return name
+	}
+	if rest, ok := strings.CutPrefix(name, DefaultEventNamespace+":"); ok {
+		return b.eventNamespace + ":" + rest
+	}
+	return name
+}
+
+// emit sends an event to the frontend under its namespaced name.
+func (b *WailsBridge) emit(ctx context.Context, name string, payload any) {
+	if b.strictEvents {
+		if err := eventSchemas.Validate(name, payload); err != nil {
+			panic(fmt.Sprintf("strict events: %v", err))
+		}
+	}
+	b.emitEvent(ctx, b.eventName(name), payload)
+}
+
+// eventNames returns the emitted names of every event, sorted by default
+// name.
+func (b *WailsBridge) eventNames() []string {
+	names := eventSchemas.Names()
+	for i, name := range names {
+		names[i] = b.eventName(name)
+	}
+	return names
+}
+
+// EventSchemas returns the JSON Schema of every event payload, keyed by the
+// name the event is emitted under.
+func (b *WailsBridge) EventSchemas() eventschema.Document {
+	return eventSchemas.Export(b.eventName)
+}
+
+// ExportEventSchemas returns the event schema document for the default
+// namespace, formatted for writing to a file.
+func ExportEventSchemas() ([]byte, error) {
+	return eventSchemas.Export(nil).MarshalIndent()
+}
@@ -0,0 +1,95 @@
+package desktop
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+
+	"gopoke/internal/app"
+	"gopoke/internal/update"
+)
+
+const (
+	// BridgeAPIVersion is the newest bridge API this build serves. Bump it
+	// when a method is removed or its parameters or result change
+	// incompatibly; added methods, events and features need no bump because
+	// clients find them in the manifest.
+	BridgeAPIVersion = 1
+	// MinBridgeAPIVersion is the oldest client API version this build still
+	// serves.
+	MinBridgeAPIVersion = 1
+)
+
+// bridgeFeatures are the optional features a client can check before
+// offering them, with the capabilities each needs. A feature reads false
+// while one of its capabilities is unavailable.
+var bridgeFeatures = map[string][]app.Capability{
+	"projects":       {app.CapabilityStorage},
+	"snippetLibrary": {app.CapabilityStorage},
+	"runs":           {app.CapabilityStorage, app.CapabilityToolchain},
+	"envMatrix":      {app.CapabilityStorage, app.CapabilityToolchain},
+	"experiments":    {app.CapabilityStorage},
+	"projectWorkers": {app.CapabilityStorage, app.CapabilityToolchain},
+	"stdlibDiff":     {app.CapabilityStorage, app.CapabilityToolchain},
+	"testExplorer":   {app.CapabilityStorage, app.CapabilityToolchain},
+	"gopls":          {app.CapabilityGopls},
+	"powerStatus":    {},
+	"idleStatus":     {},
+}
+
+// bridgeLifecycleMethods are called by Wails itself, not by clients.
+var bridgeLifecycleMethods = []string{"Startup", "Shutdown"}
+
+// bridgeMethods lists the bridge's client methods once; the method set is
+// fixed at build time.
+var bridgeMethods = sync.OnceValue(func() []string {
+	bridgeType := reflect.TypeFor[*WailsBridge]()
+	methods := make([]string, 0, bridgeType.NumMethod())
+	for i := range bridgeType.NumMethod() {
+		if name := bridgeType.Method(i).Name; !slices.Contains(bridgeLifecycleMethods, name) {
+			methods = append(methods, name)
+		}
+	}
+	return methods
+})
+
+// BridgeManifest describes what this backend serves, so a client built
+// against an older or newer backend can hide what is missing instead of
+// calling a binding that does not exist. It embeds the capability report,
+// so clients reading only degraded mode keep working.
+type BridgeManifest struct {
+	APIVersion    int    `json:"apiVersion"`
+	MinAPIVersion int    `json:"minApiVersion"`
+	AppVersion    string `json:"appVersion"`
+	// Methods are the bridge methods, sorted by name.
+	Methods []string `json:"methods"`
+	// Events are the emitted event names; EventSchemas describes their
+	// payloads.
+	Events []string `json:"events"`
+	// Features maps each optional feature to whether it is usable now.
+	Features map[string]bool `json:"features"`
+	app.CapabilityReport
+}
+
+func newBridgeManifest(report app.CapabilityReport, events []string) BridgeManifest {
+	available := make(map[app.Capability]bool, len(report.Capabilities))
+	for _, status := range report.Capabilities {
+		available[status.Capability] = status.Available
+	}
+	features := make(map[string]bool, len(bridgeFeatures))
+	for feature, required := range bridgeFeatures {
+		features[feature] = !slices.ContainsFunc(required, func(capability app.Capability) bool {
+			usable, known := available[capability]
+			return known && !usable
+		})
+	}
+	return BridgeManifest{
+		APIVersion:       BridgeAPIVersion,
+		MinAPIVersion:    MinBridgeAPIVersion,
+		AppVersion:       update.CurrentVersion,
+		Methods:          slices.Clone(bridgeMethods()),
+		Events:           events,
+		Features:         features,
+		CapabilityReport: report,
+	}
+}
@@ -4,21 +4,36 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"gopoke/internal/app"
+	"gopoke/internal/audit"
+	"gopoke/internal/diagnostics"
 	"gopoke/internal/download"
 	"gopoke/internal/execution"
+	"gopoke/internal/highlight"
+	"gopoke/internal/i18n"
+	"gopoke/internal/idle"
+	"gopoke/internal/lite"
 	"gopoke/internal/lsp"
 	"gopoke/internal/playground"
+	"gopoke/internal/plugins"
+	"gopoke/internal/procmem"
 	"gopoke/internal/project"
+	"gopoke/internal/richoutput"
 	"gopoke/internal/runner"
 	"gopoke/internal/settings"
+	"gopoke/internal/share"
+	"gopoke/internal/snippetmeta"
+	"gopoke/internal/snippetparam"
+	"gopoke/internal/snipsync"
+	"gopoke/internal/stdlibdiff"
 	"gopoke/internal/storage"
+	"gopoke/internal/update"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -31,17 +46,30 @@ const runStderrChunkEventName = "gopoke:run:stderr-chunk"
 const toolchainProgressEventName = "toolchain:download:progress"
 const toolchainCompleteEventName = "toolchain:download:complete"
 const toolchainErrorEventName = "toolchain:download:error"
+const workerLogEventName = "gopoke:worker:log"
+const lspAnalysisEventName = "gopoke:lsp:analysis"
+const lspMemoryEventName = "gopoke:lsp:memory"
+const runNetworkEventName = "gopoke:run:network"
+const runNetworkPermissionEventName = "gopoke:run:network-permission"
+const capabilitiesEventName = "gopoke:capabilities"
 
 // RunStdoutChunkEvent contains streamed stdout payload for one run.
+// Highlights mark the lines this chunk completes, at offsets into the
+// run's whole stdout. Progress holds the progress bars the chunk updates.
 type RunStdoutChunkEvent struct {
-	RunID string `json:"runId"`
-	Chunk string `json:"chunk"`
+	RunID      string                `json:"runId"`
+	Chunk      string                `json:"chunk"`
+	Highlights []highlight.Span      `json:"highlights,omitempty"`
+	Progress   []richoutput.Progress `json:"progress,omitempty"`
 }
 
 // RunStderrChunkEvent contains streamed stderr payload for one run.
+// Highlights mark the lines this chunk completes, at offsets into the
+// run's whole stderr.
 type RunStderrChunkEvent struct {
-	RunID string `json:"runId"`
-	Chunk string `json:"chunk"`
+	RunID      string           `json:"runId"`
+	Chunk      string           `json:"chunk"`
+	Highlights []highlight.Span `json:"highlights,omitempty"`
 }
 
 // ApplicationService captures app methods used by Wails bindings.
@@ -50,33 +78,130 @@ type ApplicationService interface {
 	Stop(ctx context.Context) error
 	Health(ctx context.Context) (storage.HealthReport, error)
 	OpenProject(ctx context.Context, path string) (project.OpenProjectResult, error)
+	RestoreWorkspace(ctx context.Context, projectPath string) (app.WorkspaceRestore, error)
+	HomeScreenData(ctx context.Context) (app.HomeScreenData, error)
+	SetProjectPinned(ctx context.Context, projectPath string, pinned bool) (storage.ProjectRecord, error)
 	RecentProjects(ctx context.Context, limit int) ([]storage.ProjectRecord, error)
+	RecentProjectsPage(ctx context.Context, page storage.PageRequest) (storage.ProjectPage, error)
+	ForgetProject(ctx context.Context, projectPath string) (storage.ForgottenProject, error)
 	DiscoverRunTargets(ctx context.Context, path string) ([]project.RunTarget, error)
+	ParseGoMod(ctx context.Context, projectPath string) (project.GoMod, error)
+	AddGoModReplace(ctx context.Context, projectPath string, oldPath string, oldVersion string, newPath string, newVersion string) (project.GoMod, error)
+	DropGoModRequire(ctx context.Context, projectPath string, modulePath string) (project.GoMod, error)
+	TidyProjectModules(ctx context.Context, projectPath string) (string, error)
+	ParseGoWork(ctx context.Context, projectPath string) (project.GoWork, error)
+	CreateGoWork(ctx context.Context, projectPath string, moduleDirs []string) (project.GoWork, error)
+	AddWorkModule(ctx context.Context, projectPath string, moduleDir string) (project.GoWork, error)
+	DropWorkModule(ctx context.Context, projectPath string, moduleDir string) (project.GoWork, error)
 	SetProjectDefaultPackage(ctx context.Context, projectPath string, packagePath string) (storage.ProjectRecord, error)
+	SetProjectTrust(ctx context.Context, projectPath string, trust string) (project.OpenProjectResult, error)
+	SetProjectRunGuard(ctx context.Context, projectPath string, policy string) (storage.ProjectRecord, error)
+	SetProjectTestCache(ctx context.Context, projectPath string, mode string) (storage.ProjectRecord, error)
+	ClearTestCache(ctx context.Context, projectPath string) (string, error)
+	SetProjectModuleOverlay(ctx context.Context, projectPath string, enabled bool) (storage.ProjectRecord, error)
+	DiscardModuleOverlay(ctx context.Context, projectPath string, runID string) error
+	PreviewRunModuleChanges(ctx context.Context, runID string) (project.ModuleChanges, error)
+	ApplyRunModuleChanges(ctx context.Context, runID string) (project.ModuleChanges, error)
+	AuditLog(ctx context.Context, filter audit.Filter) ([]audit.Entry, error)
 	ProjectEnvVars(ctx context.Context, projectPath string) ([]storage.EnvVarRecord, error)
 	UpsertProjectEnvVar(ctx context.Context, projectPath string, key string, value string, masked bool) (storage.EnvVarRecord, error)
 	DeleteProjectEnvVar(ctx context.Context, projectPath string, key string) error
 	SetProjectWorkingDirectory(ctx context.Context, projectPath string, workingDirectory string) (storage.ProjectRecord, error)
 	AvailableToolchains(ctx context.Context) ([]project.ToolchainInfo, error)
+	StdlibDiff(ctx context.Context, symbol string, fromVersion string, toVersion string) (stdlibdiff.Result, error)
 	SetProjectToolchain(ctx context.Context, projectPath string, toolchain string) (storage.ProjectRecord, error)
+	SetProjectRunLimits(ctx context.Context, projectPath string, timeoutMS int64, maxOutputBytes int64) (storage.ProjectRecord, error)
+	ToolchainExperiments(ctx context.Context, projectPath string) ([]project.ExperimentSupport, error)
+	SetProjectExperiments(ctx context.Context, projectPath string, experiments []string) (storage.ProjectRecord, error)
+	OutputEncodings() []string
+	RunTimeZones() []execution.TimeZoneOption
+	RunLocales() []execution.LocaleOption
+	RunDeterminismHelper() string
+	RunDeadlineHelper() string
+	AvailableCPUs() int
+	SetProjectOutputEncoding(ctx context.Context, projectPath string, encoding string) (storage.ProjectRecord, error)
 	ProjectSnippets(ctx context.Context, projectPath string) ([]storage.SnippetRecord, error)
+	ProjectSnippetsPage(ctx context.Context, projectPath string, page storage.PageRequest) (storage.SnippetPage, error)
+	ProjectRunsPage(ctx context.Context, projectPath string, page storage.PageRequest) (storage.RunPage, error)
 	SaveProjectSnippet(ctx context.Context, projectPath string, snippetID string, name string, content string) (storage.SnippetRecord, error)
 	DeleteProjectSnippet(ctx context.Context, projectPath string, snippetID string) error
+	ApproveGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error)
+	RejectGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error)
+	ResetGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error)
+	SetProjectSnippetSync(ctx context.Context, projectPath string, config storage.SnippetSyncConfig) (storage.ProjectRecord, error)
+	SyncSnippets(ctx context.Context, projectPath string) (snipsync.Report, error)
+	StartProjectShare(ctx context.Context, projectPath string, address string) (share.Info, error)
+	StopProjectShare(ctx context.Context) error
+	ProjectShare() share.Info
+	SetProjectWebhooks(ctx context.Context, projectPath string, hooks []storage.Webhook) (storage.ProjectRecord, error)
+	TestProjectWebhook(ctx context.Context, projectPath string, hookID string) error
+	SetProjectHighlights(ctx context.Context, projectPath string, rules []storage.HighlightRule) (storage.ProjectRecord, error)
+	OutputHighlighter(ctx context.Context, projectPath string) (*highlight.Highlighter, error)
+	CreateExperiment(ctx context.Context, projectPath string, name string, description string) (storage.ExperimentRecord, error)
+	AssignRun(ctx context.Context, experimentID string, runID string) (storage.RunRecord, error)
+	ExperimentSummaries(ctx context.Context, projectPath string) ([]app.ExperimentSummary, error)
 	FormatSnippet(ctx context.Context, source string) (string, error)
+	SnippetParams(ctx context.Context, source string) ([]snippetparam.Param, error)
+	SnippetMeta(ctx context.Context, source string) (snippetmeta.Meta, error)
+	ListTests(ctx context.Context, projectPath string, packagePath string) ([]project.TestFunc, error)
+	RunTest(
+		ctx context.Context,
+		request app.TestRequest,
+		onStdoutChunk execution.StdoutChunkHandler,
+		onStderrChunk execution.StderrChunkHandler,
+	) (execution.Result, error)
 	RunSnippet(
 		ctx context.Context,
 		request execution.RunRequest,
 		onStdoutChunk execution.StdoutChunkHandler,
 		onStderrChunk execution.StderrChunkHandler,
 	) (execution.Result, error)
+	RunEnvMatrix(ctx context.Context, request execution.RunRequest, envSets []map[string]string) (app.EnvMatrixResult, error)
+	RunIsolationCheck(ctx context.Context, request execution.RunRequest) (app.IsolationReport, error)
 	CancelRun(ctx context.Context, runID string) error
+	ClearRunCache(ctx context.Context, projectPath string) (int, error)
+	CheckSnippet(ctx context.Context, request execution.RunRequest) (execution.CheckResult, error)
+	ActiveRunProcesses(ctx context.Context, runID string) ([]procmem.Process, error)
+	KillRunProcess(ctx context.Context, runID string, pid int) error
+	RespondRunNetworkPermission(ctx context.Context, runID string, decision string) error
+	SetProjectNetworkAllow(ctx context.Context, projectPath string, hosts []string) (storage.ProjectRecord, error)
+	Plugins(ctx context.Context) ([]plugins.Info, error)
+	ReloadPlugins(ctx context.Context) ([]plugins.Info, error)
+	RestartPlugin(ctx context.Context, name string) error
+	ApprovePlugin(ctx context.Context, name string) error
+	RunPluginCommand(ctx context.Context, plugin string, command string, args string) (string, error)
+	ListCommands(ctx context.Context) ([]app.Command, error)
+	AnalyzeProject(ctx context.Context, projectPath string) (project.Onboarding, error)
+	ActivityHeatmap(ctx context.Context, projectPath string, window time.Duration) (app.ActivityHeatmap, error)
+	NoteActivity()
+	IdleStatus(ctx context.Context) (idle.Status, error)
+	PowerStatus(ctx context.Context) (app.PowerStatus, error)
+	Capabilities(ctx context.Context) (app.CapabilityReport, error)
+	RecheckCapabilities(ctx context.Context) (app.CapabilityReport, error)
+	RequireCapabilities(ctx context.Context, required ...app.Capability) error
+	SetCapabilityHandler(handler app.CapabilityHandler)
+	ExecuteCommand(ctx context.Context, id string, args string) (any, error)
 	StartProjectWorker(ctx context.Context, projectPath string) (runner.Worker, error)
 	StopProjectWorker(ctx context.Context, projectPath string) error
+	ProjectWorkers(ctx context.Context) ([]runner.Worker, error)
+	WorkerLogs(ctx context.Context, projectPath string, limit int) ([]runner.LogLine, error)
+	SetWorkerLogHandler(handler runner.LogHandler)
 	StartLSP(ctx context.Context, projectPath string) error
 	StopLSP(ctx context.Context) error
 	LSPWebSocketPort(ctx context.Context) int
 	LSPWorkspaceInfo(ctx context.Context) lsp.WorkspaceInfo
 	LSPStatus(ctx context.Context) lsp.StatusResult
+	SetLSPAnalysisHandler(handler lsp.AnalysisHandler)
+	SetLSPMemoryHandler(handler lsp.MemoryHandler)
+	SetRunNetworkHandler(handler app.RunNetworkHandler)
+	SetRunNetworkPermissionHandler(handler app.RunNetworkPermissionHandler)
+	LSPAnalysisState(ctx context.Context) lsp.AnalysisEvent
+	LSPModDocuments(ctx context.Context) []lsp.ModDocument
+	DocumentDiagnostics(ctx context.Context, documentURI string, runID string) ([]diagnostics.Entry, error)
+	ApplyQuickFix(ctx context.Context, source string, diagnostic execution.Diagnostic) (string, error)
+	DocumentSymbols(ctx context.Context, source string) ([]lite.Symbol, error)
+	DocumentSymbolPath(ctx context.Context, source string, line int, character int) ([]lite.Symbol, error)
+	FoldingRanges(ctx context.Context, source string) ([]lite.FoldingRange, error)
 	OpenGoFile(ctx context.Context, filePath string) (app.OpenGoFileResult, error)
 	SaveGoFile(ctx context.Context, filePath string, content string) error
 	PlaygroundShare(ctx context.Context, source string) (playground.ShareResult, error)
@@ -84,6 +209,19 @@ type ApplicationService interface {
 	GetGlobalSettings(ctx context.Context) (settings.GlobalSettings, error)
 	UpdateGlobalSettings(ctx context.Context, gs settings.GlobalSettings) (settings.GlobalSettings, error)
 	DetectToolVersions(ctx context.Context) app.ToolVersions
+	AccessibleRunResult(ctx context.Context, runID string) (string, error)
+	ExportRunEvents(ctx context.Context, runID string, destPath string) (int, error)
+	ProjectFootprint(ctx context.Context, projectPath string) (app.ProjectFootprint, error)
+	CleanProjectFootprint(ctx context.Context, projectPath string, category string) (app.ProjectFootprint, error)
+	CacheStats(ctx context.Context) (app.CacheStats, error)
+	ClearCache(ctx context.Context) (app.CacheStats, error)
+	SelfTest(ctx context.Context, iterations int) (app.SelfTestReport, error)
+	StartSessionRecording(ctx context.Context, name string) (app.SessionRecording, error)
+	StopSessionRecording(ctx context.Context) (app.SessionRecording, error)
+	ReplaySession(ctx context.Context, path string, rerun bool) (app.ReplaySessionResult, error)
+	CheckForUpdate(ctx context.Context) (update.CheckResult, error)
+	DownloadUpdate(ctx context.Context, onProgress download.OnProgress) (update.StagedUpdate, error)
+	PendingUpdate(ctx context.Context) (*update.StagedUpdate, error)
 	ScratchDir() string
 }
 
@@ -101,18 +239,25 @@ type WailsBridge struct {
 	openDirectoryDialog func(ctx context.Context) (string, error)
 	openFileDialog      func(ctx context.Context) (string, error)
 	emitEvent           func(ctx context.Context, eventName string, payload interface{})
+	eventNamespace      string
+	strictEvents        bool
 }
 
 // NewWailsBridge creates a binding bridge for a running app service.
-func NewWailsBridge(app ApplicationService) *WailsBridge {
-	return &WailsBridge{
+func NewWailsBridge(app ApplicationService, options ...BridgeOption) *WailsBridge {
+	bridge := &WailsBridge{
 		app:                 app,
 		downloads:           download.NewManager(download.DefaultBaseDir()),
 		ctx:                 context.Background(),
 		openDirectoryDialog: defaultOpenDirectoryDialog,
 		openFileDialog:      defaultOpenFileDialog,
 		emitEvent:           defaultEmitEvent,
+		eventNamespace:      DefaultEventNamespace,
 	}
+	for _, option := range options {
+		option(bridge)
+	}
+	return bridge
 }
 
 // Startup is called by Wails at app startup.
@@ -123,6 +268,25 @@ func (b *WailsBridge) Startup(ctx context.Context) {
 	b.startupErr = b.app.Start(ctx)
 	b.mu.Unlock()
 
+	b.app.SetWorkerLogHandler(func(line runner.LogLine) {
+		b.emit(ctx, workerLogEventName, line)
+	})
+	b.app.SetLSPAnalysisHandler(func(event lsp.AnalysisEvent) {
+		b.emit(ctx, lspAnalysisEventName, event)
+	})
+	b.app.SetLSPMemoryHandler(func(warning lsp.MemoryWarning) {
+		b.emit(ctx, lspMemoryEventName, warning)
+	})
+	b.app.SetRunNetworkHandler(func(event app.RunNetworkEvent) {
+		b.emit(ctx, runNetworkEventName, event)
+	})
+	b.app.SetRunNetworkPermissionHandler(func(request app.RunNetworkPermissionRequest) {
+		b.emit(ctx, runNetworkPermissionEventName, request)
+	})
+	b.app.SetCapabilityHandler(func(report app.CapabilityReport) {
+		b.emit(ctx, capabilitiesEventName, report)
+	})
+
 	// Start LSP against scratch workspace for immediate completions.
 	// Synchronous so the port is available when the frontend mounts.
 	scratchDir := b.app.ScratchDir()
@@ -188,372 +352,1483 @@ func (b *WailsBridge) OpenProject(path string) (project.OpenProjectResult, error
 	return result, nil
 }
 
-// RecentProjects returns recently opened projects for the home screen.
-func (b *WailsBridge) RecentProjects(limit int) ([]storage.ProjectRecord, error) {
+// RestoreWorkspace reopens a project at startup in one call: project,
+// run targets, environment, snippets and the language server.
+func (b *WailsBridge) RestoreWorkspace(projectPath string) (app.WorkspaceRestore, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return nil, err
+		return app.WorkspaceRestore{}, err
 	}
-	records, err := b.app.RecentProjects(ctx, limit)
+	restore, err := b.app.RestoreWorkspace(ctx, projectPath)
 	if err != nil {
-		return nil, fmt.Errorf("recent projects: %w", err)
+		return app.WorkspaceRestore{}, fmt.Errorf("restore workspace: %w", err)
 	}
-	return records, nil
+	return restore, nil
 }
 
-// DiscoverRunTargets loads runnable package targets for a project.
-func (b *WailsBridge) DiscoverRunTargets(path string) ([]project.RunTarget, error) {
+// HomeScreenData loads everything the home screen shows in one call.
+func (b *WailsBridge) HomeScreenData() (app.HomeScreenData, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return nil, err
+		return app.HomeScreenData{}, err
 	}
-	targets, err := b.app.DiscoverRunTargets(ctx, path)
+	home, err := b.app.HomeScreenData(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("discover run targets: %w", err)
+		return app.HomeScreenData{}, fmt.Errorf("home screen data: %w", err)
 	}
-	return targets, nil
+	return home, nil
 }
 
-// SetProjectDefaultPackage persists the selected default package for a project.
-func (b *WailsBridge) SetProjectDefaultPackage(projectPath string, packagePath string) (storage.ProjectRecord, error) {
+// SetProjectPinned pins or unpins a project on the home screen.
+func (b *WailsBridge) SetProjectPinned(projectPath string, pinned bool) (storage.ProjectRecord, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
 		return storage.ProjectRecord{}, err
 	}
-	record, err := b.app.SetProjectDefaultPackage(ctx, projectPath, packagePath)
+	record, err := b.app.SetProjectPinned(ctx, projectPath, pinned)
 	if err != nil {
-		return storage.ProjectRecord{}, fmt.Errorf("set project default package: %w", err)
+		return storage.ProjectRecord{}, fmt.Errorf("set project pinned: %w", err)
 	}
 	return record, nil
 }
 
-// ProjectEnvVars returns project environment variables.
-func (b *WailsBridge) ProjectEnvVars(projectPath string) ([]storage.EnvVarRecord, error) {
+// RecentProjects returns recently opened projects for the home screen.
+func (b *WailsBridge) RecentProjects(limit int) ([]storage.ProjectRecord, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
 		return nil, err
 	}
-	vars, err := b.app.ProjectEnvVars(ctx, projectPath)
+	records, err := b.app.RecentProjects(ctx, limit)
 	if err != nil {
-		return nil, fmt.Errorf("project env vars: %w", err)
+		return nil, fmt.Errorf("recent projects: %w", err)
 	}
-	return vars, nil
+	return records, nil
 }
 
-// UpsertProjectEnvVar creates or updates one project environment variable.
-func (b *WailsBridge) UpsertProjectEnvVar(projectPath string, key string, value string, masked bool) (storage.EnvVarRecord, error) {
+// RecentProjectsPage returns one page of recently opened projects; pass
+// the previous page's nextCursor to continue.
+func (b *WailsBridge) RecentProjectsPage(page storage.PageRequest) (storage.ProjectPage, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return storage.EnvVarRecord{}, err
+		return storage.ProjectPage{}, err
 	}
-	record, err := b.app.UpsertProjectEnvVar(ctx, projectPath, key, value, masked)
+	records, err := b.app.RecentProjectsPage(ctx, page)
 	if err != nil {
-		return storage.EnvVarRecord{}, fmt.Errorf("upsert project env var: %w", err)
+		return storage.ProjectPage{}, fmt.Errorf("recent projects page: %w", err)
 	}
-	return record, nil
+	return records, nil
 }
 
-// DeleteProjectEnvVar removes one project environment variable.
-func (b *WailsBridge) DeleteProjectEnvVar(projectPath string, key string) error {
+// ForgetProject removes a project and everything gopoke stored for it,
+// leaving the project's files alone.
+func (b *WailsBridge) ForgetProject(projectPath string) (storage.ForgottenProject, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return err
+		return storage.ForgottenProject{}, err
 	}
-	if err := b.app.DeleteProjectEnvVar(ctx, projectPath, key); err != nil {
-		return fmt.Errorf("delete project env var: %w", err)
+	forgotten, err := b.app.ForgetProject(ctx, projectPath)
+	if err != nil {
+		return storage.ForgottenProject{}, fmt.Errorf("forget project: %w", err)
 	}
-	return nil
+	return forgotten, nil
 }
 
-// SetProjectWorkingDirectory persists project working directory.
-func (b *WailsBridge) SetProjectWorkingDirectory(projectPath string, workingDirectory string) (storage.ProjectRecord, error) {
+// DiscoverRunTargets loads runnable package targets for a project.
+func (b *WailsBridge) DiscoverRunTargets(path string) ([]project.RunTarget, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return storage.ProjectRecord{}, err
+		return nil, err
 	}
-	record, err := b.app.SetProjectWorkingDirectory(ctx, projectPath, workingDirectory)
+	targets, err := b.app.DiscoverRunTargets(ctx, path)
 	if err != nil {
-		return storage.ProjectRecord{}, fmt.Errorf("set project working directory: %w", err)
+		return nil, fmt.Errorf("discover run targets: %w", err)
 	}
-	return record, nil
+	return targets, nil
 }
 
-// AvailableToolchains returns detected Go toolchains from PATH.
-func (b *WailsBridge) AvailableToolchains() ([]project.ToolchainInfo, error) {
+// ParseGoMod returns structured go.mod directives for the go.mod editor.
+func (b *WailsBridge) ParseGoMod(projectPath string) (project.GoMod, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return nil, err
+		return project.GoMod{}, err
 	}
-	toolchains, err := b.app.AvailableToolchains(ctx)
+	parsed, err := b.app.ParseGoMod(ctx, projectPath)
 	if err != nil {
-		return nil, fmt.Errorf("available toolchains: %w", err)
+		return project.GoMod{}, fmt.Errorf("parse go.mod: %w", err)
 	}
-	return toolchains, nil
+	return parsed, nil
 }
 
-// SetProjectToolchain persists selected Go toolchain for a project.
-func (b *WailsBridge) SetProjectToolchain(projectPath string, toolchain string) (storage.ProjectRecord, error) {
+// AddGoModReplace adds or updates a go.mod replace directive. Leave
+// newVersion empty to replace with a local directory.
+func (b *WailsBridge) AddGoModReplace(projectPath string, oldPath string, oldVersion string, newPath string, newVersion string) (project.GoMod, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return storage.ProjectRecord{}, err
+		return project.GoMod{}, err
 	}
-	record, err := b.app.SetProjectToolchain(ctx, projectPath, toolchain)
+	updated, err := b.app.AddGoModReplace(ctx, projectPath, oldPath, oldVersion, newPath, newVersion)
 	if err != nil {
-		return storage.ProjectRecord{}, fmt.Errorf("set project toolchain: %w", err)
+		return project.GoMod{}, fmt.Errorf("add go.mod replace: %w", err)
 	}
-	return record, nil
+	return updated, nil
 }
 
-// ProjectSnippets returns snippets for a project.
-func (b *WailsBridge) ProjectSnippets(projectPath string) ([]storage.SnippetRecord, error) {
+// DropGoModRequire removes a go.mod require directive.
+func (b *WailsBridge) DropGoModRequire(projectPath string, modulePath string) (project.GoMod, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return nil, err
+		return project.GoMod{}, err
 	}
-	snippets, err := b.app.ProjectSnippets(ctx, projectPath)
+	updated, err := b.app.DropGoModRequire(ctx, projectPath, modulePath)
 	if err != nil {
-		return nil, fmt.Errorf("project snippets: %w", err)
+		return project.GoMod{}, fmt.Errorf("drop go.mod require: %w", err)
 	}
-	return snippets, nil
+	return updated, nil
 }
 
-// SaveProjectSnippet creates or updates a project snippet.
-func (b *WailsBridge) SaveProjectSnippet(projectPath string, snippetID string, name string, content string) (storage.SnippetRecord, error) {
+// TidyProjectModules runs go mod tidy in the project, resolving a run's
+// go.sum warning.
+func (b *WailsBridge) TidyProjectModules(projectPath string) (string, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return storage.SnippetRecord{}, err
+		return "", err
 	}
-	snippet, err := b.app.SaveProjectSnippet(ctx, projectPath, snippetID, name, content)
+	output, err := b.app.TidyProjectModules(ctx, projectPath)
 	if err != nil {
-		return storage.SnippetRecord{}, fmt.Errorf("save project snippet: %w", err)
+		return output, fmt.Errorf("tidy project modules: %w", err)
 	}
-	return snippet, nil
+	return output, nil
 }
 
-// DeleteProjectSnippet removes one snippet from a project.
-func (b *WailsBridge) DeleteProjectSnippet(projectPath string, snippetID string) error {
+// ParseGoWork returns the use directives of the project's go.work.
+func (b *WailsBridge) ParseGoWork(projectPath string) (project.GoWork, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return err
+		return project.GoWork{}, err
 	}
-	if err := b.app.DeleteProjectSnippet(ctx, projectPath, snippetID); err != nil {
-		return fmt.Errorf("delete project snippet: %w", err)
+	parsed, err := b.app.ParseGoWork(ctx, projectPath)
+	if err != nil {
+		return project.GoWork{}, fmt.Errorf("parse go.work: %w", err)
 	}
-	return nil
+	return parsed, nil
 }
 
-// FormatSnippet runs gofmt formatting over snippet source.
-func (b *WailsBridge) FormatSnippet(source string) (string, error) {
+// CreateGoWork creates go.work for a multi-module experiment.
+func (b *WailsBridge) CreateGoWork(projectPath string, moduleDirs []string) (project.GoWork, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return "", err
+		return project.GoWork{}, err
 	}
-	formatted, err := b.app.FormatSnippet(ctx, source)
+	created, err := b.app.CreateGoWork(ctx, projectPath, moduleDirs)
 	if err != nil {
-		return "", fmt.Errorf("format snippet: %w", err)
+		return project.GoWork{}, fmt.Errorf("create go.work: %w", err)
 	}
-	return formatted, nil
+	return created, nil
 }
 
-// RunSnippet executes snippet source against a project context.
-func (b *WailsBridge) RunSnippet(request execution.RunRequest) (execution.Result, error) {
+// AddWorkModule adds a module directory to go.work.
+func (b *WailsBridge) AddWorkModule(projectPath string, moduleDir string) (project.GoWork, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return execution.Result{}, err
-	}
-
-	runID := strings.TrimSpace(request.RunID)
-	if runID == "" {
-		runID = generateBridgeRunID()
-	}
-	request.RunID = runID
-
-	if NativeToolbarUpdater != nil {
-		NativeToolbarUpdater(true)
-		defer NativeToolbarUpdater(false)
+		return project.GoWork{}, err
 	}
-
-	result, err := b.app.RunSnippet(
-		ctx,
-		request,
-		func(chunk string) {
-			if chunk == "" {
-				return
-			}
-			b.emitEvent(ctx, runStdoutChunkEventName, RunStdoutChunkEvent{
-				RunID: runID,
-				Chunk: chunk,
-			})
-		},
-		func(chunk string) {
-			if chunk == "" {
-				return
-			}
-			b.emitEvent(ctx, runStderrChunkEventName, RunStderrChunkEvent{
-				RunID: runID,
-				Chunk: chunk,
-			})
-		},
-	)
+	updated, err := b.app.AddWorkModule(ctx, projectPath, moduleDir)
 	if err != nil {
-		return execution.Result{}, fmt.Errorf("run snippet: %w", err)
+		return project.GoWork{}, fmt.Errorf("add go.work module: %w", err)
 	}
-	return result, nil
+	return updated, nil
 }
 
-// CancelRun requests cancellation for an active run.
-func (b *WailsBridge) CancelRun(runID string) error {
+// DropWorkModule removes a module directory from go.work.
+func (b *WailsBridge) DropWorkModule(projectPath string, moduleDir string) (project.GoWork, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return err
+		return project.GoWork{}, err
 	}
-	if err := b.app.CancelRun(ctx, runID); err != nil {
-		return fmt.Errorf("cancel run: %w", err)
+	updated, err := b.app.DropWorkModule(ctx, projectPath, moduleDir)
+	if err != nil {
+		return project.GoWork{}, fmt.Errorf("drop go.work module: %w", err)
 	}
-	return nil
+	return updated, nil
 }
 
-// StartProjectWorker ensures a long-lived worker process exists for a project.
-func (b *WailsBridge) StartProjectWorker(projectPath string) (runner.Worker, error) {
+// SetProjectDefaultPackage persists the selected default package for a project.
+func (b *WailsBridge) SetProjectDefaultPackage(projectPath string, packagePath string) (storage.ProjectRecord, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return runner.Worker{}, err
+		return storage.ProjectRecord{}, err
 	}
-	worker, err := b.app.StartProjectWorker(ctx, projectPath)
+	record, err := b.app.SetProjectDefaultPackage(ctx, projectPath, packagePath)
 	if err != nil {
-		return runner.Worker{}, fmt.Errorf("start project worker: %w", err)
+		return storage.ProjectRecord{}, fmt.Errorf("set project default package: %w", err)
 	}
-	return worker, nil
+	return record, nil
 }
 
-// StopProjectWorker stops a worker process for a project.
-func (b *WailsBridge) StopProjectWorker(projectPath string) error {
+// SetProjectTrust records whether the user trusts a project.
+func (b *WailsBridge) SetProjectTrust(projectPath string, trust string) (project.OpenProjectResult, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return err
+		return project.OpenProjectResult{}, err
 	}
-	if err := b.app.StopProjectWorker(ctx, projectPath); err != nil {
-		return fmt.Errorf("stop project worker: %w", err)
+	result, err := b.app.SetProjectTrust(ctx, projectPath, trust)
+	if err != nil {
+		return project.OpenProjectResult{}, fmt.Errorf("set project trust: %w", err)
 	}
-	return nil
+	return result, nil
 }
 
-// ChooseProjectDirectory opens a native directory picker and returns the selected path.
-func (b *WailsBridge) ChooseProjectDirectory() (string, error) {
+// SetProjectRunGuard sets a project's run confirmation policy.
+func (b *WailsBridge) SetProjectRunGuard(projectPath string, policy string) (storage.ProjectRecord, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return "", err
+		return storage.ProjectRecord{}, err
 	}
-	path, err := b.openDirectoryDialog(ctx)
+	record, err := b.app.SetProjectRunGuard(ctx, projectPath, policy)
 	if err != nil {
-		return "", fmt.Errorf("choose project directory: %w", err)
+		return storage.ProjectRecord{}, fmt.Errorf("set project run guard: %w", err)
 	}
-	return path, nil
+	return record, nil
 }
 
-// LSPWebSocketPort returns the WebSocket proxy port for monaco-languageclient.
-func (b *WailsBridge) LSPWebSocketPort() (int, error) {
+// SetProjectTestCache sets whether a project's test runs replay cached go
+// test results.
+func (b *WailsBridge) SetProjectTestCache(projectPath string, mode string) (storage.ProjectRecord, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return 0, err
+		return storage.ProjectRecord{}, err
 	}
-	return b.app.LSPWebSocketPort(ctx), nil
+	record, err := b.app.SetProjectTestCache(ctx, projectPath, mode)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project test cache: %w", err)
+	}
+	return record, nil
 }
 
-// LSPWorkspaceInfo returns workspace details for the frontend LSP client.
-func (b *WailsBridge) LSPWorkspaceInfo() (lsp.WorkspaceInfo, error) {
+// SetProjectModuleOverlay turns on or off running a project's snippets
+// against private copies of its go.mod and go.sum.
+func (b *WailsBridge) SetProjectModuleOverlay(projectPath string, enabled bool) (storage.ProjectRecord, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return lsp.WorkspaceInfo{}, err
+		return storage.ProjectRecord{}, err
 	}
-	return b.app.LSPWorkspaceInfo(ctx), nil
+	record, err := b.app.SetProjectModuleOverlay(ctx, projectPath, enabled)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project module overlay: %w", err)
+	}
+	return record, nil
 }
 
-// LSPStatus returns LSP readiness status.
-func (b *WailsBridge) LSPStatus() (lsp.StatusResult, error) {
+// DiscardModuleOverlay drops the module file changes of a module overlay
+// run.
+func (b *WailsBridge) DiscardModuleOverlay(projectPath string, runID string) error {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return lsp.StatusResult{}, err
+		return err
 	}
-	return b.app.LSPStatus(ctx), nil
+	if err := b.app.DiscardModuleOverlay(ctx, projectPath, runID); err != nil {
+		return fmt.Errorf("discard module overlay: %w", err)
+	}
+	return nil
 }
 
-// ChooseGoFile opens a native file picker filtered to .go files.
-func (b *WailsBridge) ChooseGoFile() (string, error) {
+// PreviewRunModuleChanges lists the requirement and go.sum changes a module
+// overlay run made.
+func (b *WailsBridge) PreviewRunModuleChanges(runID string) (project.ModuleChanges, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return "", err
+		return project.ModuleChanges{}, err
 	}
-	path, err := b.openFileDialog(ctx)
+	changes, err := b.app.PreviewRunModuleChanges(ctx, runID)
 	if err != nil {
-		return "", fmt.Errorf("choose go file: %w", err)
+		return project.ModuleChanges{}, fmt.Errorf("preview run module changes: %w", err)
 	}
-	return path, nil
+	return changes, nil
 }
 
-// OpenGoFile reads a .go file and opens its parent directory as project context.
-func (b *WailsBridge) OpenGoFile(filePath string) (app.OpenGoFileResult, error) {
+// ApplyRunModuleChanges applies a module overlay run's requirement and
+// go.sum changes to its project.
+func (b *WailsBridge) ApplyRunModuleChanges(runID string) (project.ModuleChanges, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return app.OpenGoFileResult{}, err
+		return project.ModuleChanges{}, err
 	}
-	result, err := b.app.OpenGoFile(ctx, filePath)
+	changes, err := b.app.ApplyRunModuleChanges(ctx, runID)
 	if err != nil {
-		return app.OpenGoFileResult{}, fmt.Errorf("open go file: %w", err)
-	}
-
-	// Start LSP synchronously for the parent directory.
-	if lspErr := b.app.StartLSP(context.Background(), result.ProjectResult.Project.Path); lspErr != nil {
-		fmt.Printf("gopls start (file): %v\n", lspErr)
+		return project.ModuleChanges{}, fmt.Errorf("apply run module changes: %w", err)
 	}
-
-	return result, nil
+	return changes, nil
 }
 
-// SaveGoFile writes content back to a .go file on disk.
-func (b *WailsBridge) SaveGoFile(filePath string, content string) error {
+// ClearTestCache expires cached go test results for a project.
+func (b *WailsBridge) ClearTestCache(projectPath string) (string, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return err
+		return "", err
 	}
-	if err := b.app.SaveGoFile(ctx, filePath, content); err != nil {
-		return fmt.Errorf("save go file: %w", err)
+	output, err := b.app.ClearTestCache(ctx, projectPath)
+	if err != nil {
+		return output, fmt.Errorf("clear test cache: %w", err)
 	}
-	return nil
+	return output, nil
 }
 
-// GetGlobalSettings returns the current global settings.
-func (b *WailsBridge) GetGlobalSettings() (settings.GlobalSettings, error) {
+// AuditLog returns audited file writes and external commands.
+func (b *WailsBridge) AuditLog(filter audit.Filter) ([]audit.Entry, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return settings.GlobalSettings{}, err
+		return nil, err
 	}
-	return b.app.GetGlobalSettings(ctx)
+	entries, err := b.app.AuditLog(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("audit log: %w", err)
+	}
+	return entries, nil
 }
 
-// UpdateGlobalSettings saves new global settings.
-func (b *WailsBridge) UpdateGlobalSettings(gs settings.GlobalSettings) (settings.GlobalSettings, error) {
+// ProjectEnvVars returns project environment variables.
+func (b *WailsBridge) ProjectEnvVars(projectPath string) ([]storage.EnvVarRecord, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return settings.GlobalSettings{}, err
+		return nil, err
 	}
-	return b.app.UpdateGlobalSettings(ctx, gs)
+	vars, err := b.app.ProjectEnvVars(ctx, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("project env vars: %w", err)
+	}
+	return vars, nil
 }
 
-// DetectToolVersions returns detected versions for go, gopls, staticcheck.
-func (b *WailsBridge) DetectToolVersions() (app.ToolVersions, error) {
+// UpsertProjectEnvVar creates or updates one project environment variable.
+func (b *WailsBridge) UpsertProjectEnvVar(projectPath string, key string, value string, masked bool) (storage.EnvVarRecord, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return app.ToolVersions{}, err
+		return storage.EnvVarRecord{}, err
 	}
-	return b.app.DetectToolVersions(ctx), nil
+	record, err := b.app.UpsertProjectEnvVar(ctx, projectPath, key, value, masked)
+	if err != nil {
+		return storage.EnvVarRecord{}, fmt.Errorf("upsert project env var: %w", err)
+	}
+	return record, nil
 }
 
-// ListGoVersions fetches available Go SDK versions from go.dev.
-func (b *WailsBridge) ListGoVersions() ([]download.GoVersion, error) {
+// DeleteProjectEnvVar removes one project environment variable.
+func (b *WailsBridge) DeleteProjectEnvVar(projectPath string, key string) error {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err := b.app.DeleteProjectEnvVar(ctx, projectPath, key); err != nil {
+		return fmt.Errorf("delete project env var: %w", err)
+	}
+	return nil
+}
+
+// SetProjectWorkingDirectory persists project working directory.
+func (b *WailsBridge) SetProjectWorkingDirectory(projectPath string, workingDirectory string) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectWorkingDirectory(ctx, projectPath, workingDirectory)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project working directory: %w", err)
+	}
+	return record, nil
+}
+
+// AvailableToolchains returns detected Go toolchains from PATH.
+func (b *WailsBridge) AvailableToolchains() ([]project.ToolchainInfo, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	toolchains, err := b.app.AvailableToolchains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("available toolchains: %w", err)
+	}
+	return toolchains, nil
+}
+
+// StdlibDiff compares a standard library symbol between two Go versions.
+func (b *WailsBridge) StdlibDiff(symbol string, fromVersion string, toVersion string) (stdlibdiff.Result, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return stdlibdiff.Result{}, err
+	}
+	result, err := b.app.StdlibDiff(ctx, symbol, fromVersion, toVersion)
+	if err != nil {
+		return stdlibdiff.Result{}, fmt.Errorf("stdlib diff: %w", err)
+	}
+	return result, nil
+}
+
+// SetProjectToolchain persists selected Go toolchain for a project.
+func (b *WailsBridge) SetProjectToolchain(projectPath string, toolchain string) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectToolchain(ctx, projectPath, toolchain)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project toolchain: %w", err)
+	}
+	return record, nil
+}
+
+// SetProjectRunLimits persists a project's timeout and output cap overrides.
+// Zero values inherit the global settings.
+func (b *WailsBridge) SetProjectRunLimits(projectPath string, timeoutMS int64, maxOutputBytes int64) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectRunLimits(ctx, projectPath, timeoutMS, maxOutputBytes)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project run limits: %w", err)
+	}
+	return record, nil
+}
+
+// ToolchainExperiments lists known GOEXPERIMENT options and whether the
+// project's toolchain supports each.
+func (b *WailsBridge) ToolchainExperiments(projectPath string) ([]project.ExperimentSupport, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	support, err := b.app.ToolchainExperiments(ctx, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("toolchain experiments: %w", err)
+	}
+	return support, nil
+}
+
+// SetProjectExperiments persists the GOEXPERIMENT values enabled for a project.
+func (b *WailsBridge) SetProjectExperiments(projectPath string, experiments []string) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectExperiments(ctx, projectPath, experiments)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project experiments: %w", err)
+	}
+	return record, nil
+}
+
+// OutputEncodings lists the encodings run output can be decoded from.
+func (b *WailsBridge) OutputEncodings() []string {
+	return b.app.OutputEncodings()
+}
+
+// RunTimeZones lists the time zones a run can be pinned to.
+func (b *WailsBridge) RunTimeZones() []execution.TimeZoneOption {
+	return b.app.RunTimeZones()
+}
+
+// RunLocales lists the locales a run can be pinned to.
+func (b *WailsBridge) RunLocales() []execution.LocaleOption {
+	return b.app.RunLocales()
+}
+
+// RunDeterminismHelper returns helper code for reproducible runs.
+func (b *WailsBridge) RunDeterminismHelper() string {
+	return b.app.RunDeterminismHelper()
+}
+
+// RunDeadlineHelper returns helper code for runs that export their deadline.
+func (b *WailsBridge) RunDeadlineHelper() string {
+	return b.app.RunDeadlineHelper()
+}
+
+// AvailableCPUs returns how many CPUs a run can be pinned to.
+func (b *WailsBridge) AvailableCPUs() int {
+	return b.app.AvailableCPUs()
+}
+
+// SetProjectOutputEncoding persists the encoding a project's run output is
+// decoded from; "auto" restores detection.
+func (b *WailsBridge) SetProjectOutputEncoding(projectPath string, encoding string) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectOutputEncoding(ctx, projectPath, encoding)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project output encoding: %w", err)
+	}
+	return record, nil
+}
+
+// ProjectSnippets returns snippets for a project.
+func (b *WailsBridge) ProjectSnippets(projectPath string) ([]storage.SnippetRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	snippets, err := b.app.ProjectSnippets(ctx, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("project snippets: %w", err)
+	}
+	return snippets, nil
+}
+
+// ProjectSnippetsPage returns one page of a project's snippets.
+func (b *WailsBridge) ProjectSnippetsPage(projectPath string, page storage.PageRequest) (storage.SnippetPage, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.SnippetPage{}, err
+	}
+	snippets, err := b.app.ProjectSnippetsPage(ctx, projectPath, page)
+	if err != nil {
+		return storage.SnippetPage{}, fmt.Errorf("project snippets page: %w", err)
+	}
+	return snippets, nil
+}
+
+// ProjectRunsPage returns one page of a project's run history.
+func (b *WailsBridge) ProjectRunsPage(projectPath string, page storage.PageRequest) (storage.RunPage, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.RunPage{}, err
+	}
+	runs, err := b.app.ProjectRunsPage(ctx, projectPath, page)
+	if err != nil {
+		return storage.RunPage{}, fmt.Errorf("project runs page: %w", err)
+	}
+	return runs, nil
+}
+
+// SaveProjectSnippet creates or updates a project snippet.
+func (b *WailsBridge) SaveProjectSnippet(projectPath string, snippetID string, name string, content string) (storage.SnippetRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	snippet, err := b.app.SaveProjectSnippet(ctx, projectPath, snippetID, name, content)
+	if err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("save project snippet: %w", err)
+	}
+	return snippet, nil
+}
+
+// DeleteProjectSnippet removes one snippet from a project.
+func (b *WailsBridge) DeleteProjectSnippet(projectPath string, snippetID string) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.DeleteProjectSnippet(ctx, projectPath, snippetID); err != nil {
+		return fmt.Errorf("delete project snippet: %w", err)
+	}
+	return nil
+}
+
+// ApproveGoldenOutput makes a snippet's received output its golden
+// snapshot.
+func (b *WailsBridge) ApproveGoldenOutput(projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	snippet, err := b.app.ApproveGoldenOutput(ctx, projectPath, snippetID)
+	if err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("approve golden output: %w", err)
+	}
+	return snippet, nil
+}
+
+// RejectGoldenOutput discards a snippet's received output.
+func (b *WailsBridge) RejectGoldenOutput(projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	snippet, err := b.app.RejectGoldenOutput(ctx, projectPath, snippetID)
+	if err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("reject golden output: %w", err)
+	}
+	return snippet, nil
+}
+
+// ResetGoldenOutput forgets a snippet's golden snapshot.
+func (b *WailsBridge) ResetGoldenOutput(projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.SnippetRecord{}, err
+	}
+	snippet, err := b.app.ResetGoldenOutput(ctx, projectPath, snippetID)
+	if err != nil {
+		return storage.SnippetRecord{}, fmt.Errorf("reset golden output: %w", err)
+	}
+	return snippet, nil
+}
+
+// SetProjectSnippetSync sets the remote library a project's snippets sync
+// with; an empty provider and URL turn sync off.
+func (b *WailsBridge) SetProjectSnippetSync(projectPath string, config storage.SnippetSyncConfig) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectSnippetSync(ctx, projectPath, config)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project snippet sync: %w", err)
+	}
+	return record, nil
+}
+
+// SyncSnippets merges a project's snippets with its remote library.
+func (b *WailsBridge) SyncSnippets(projectPath string) (snipsync.Report, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return snipsync.Report{}, err
+	}
+	report, err := b.app.SyncSnippets(ctx, projectPath)
+	if err != nil {
+		return snipsync.Report{}, fmt.Errorf("sync snippets: %w", err)
+	}
+	return report, nil
+}
+
+// StartProjectShare serves a read-only view of a project to the LAN.
+func (b *WailsBridge) StartProjectShare(projectPath string, address string) (share.Info, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return share.Info{}, err
+	}
+	info, err := b.app.StartProjectShare(ctx, projectPath, address)
+	if err != nil {
+		return share.Info{}, fmt.Errorf("start project share: %w", err)
+	}
+	return info, nil
+}
+
+// StopProjectShare stops the running project share.
+func (b *WailsBridge) StopProjectShare() error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.StopProjectShare(ctx); err != nil {
+		return fmt.Errorf("stop project share: %w", err)
+	}
+	return nil
+}
+
+// ProjectShare describes the running project share.
+func (b *WailsBridge) ProjectShare() share.Info {
+	return b.app.ProjectShare()
+}
+
+// SetProjectWebhooks replaces the webhooks that receive a project's run
+// results.
+func (b *WailsBridge) SetProjectWebhooks(projectPath string, hooks []storage.Webhook) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectWebhooks(ctx, projectPath, hooks)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project webhooks: %w", err)
+	}
+	return record, nil
+}
+
+// SetProjectHighlights replaces the rules that highlight a project's run
+// output.
+func (b *WailsBridge) SetProjectHighlights(projectPath string, rules []storage.HighlightRule) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectHighlights(ctx, projectPath, rules)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project highlights: %w", err)
+	}
+	return record, nil
+}
+
+// CreateExperiment starts an experiment that a project's runs can be
+// attached to.
+func (b *WailsBridge) CreateExperiment(projectPath string, name string, description string) (storage.ExperimentRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ExperimentRecord{}, err
+	}
+	experiment, err := b.app.CreateExperiment(ctx, projectPath, name, description)
+	if err != nil {
+		return storage.ExperimentRecord{}, fmt.Errorf("create experiment: %w", err)
+	}
+	return experiment, nil
+}
+
+// AssignRun attaches a recorded run to an experiment.
+func (b *WailsBridge) AssignRun(experimentID string, runID string) (storage.RunRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.RunRecord{}, err
+	}
+	run, err := b.app.AssignRun(ctx, experimentID, runID)
+	if err != nil {
+		return storage.RunRecord{}, fmt.Errorf("assign run: %w", err)
+	}
+	return run, nil
+}
+
+// ExperimentSummaries sums up the runs of each experiment of a project.
+func (b *WailsBridge) ExperimentSummaries(projectPath string) ([]app.ExperimentSummary, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := b.app.ExperimentSummaries(ctx, projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("experiment summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// TestProjectWebhook posts a sample run result to one project webhook.
+func (b *WailsBridge) TestProjectWebhook(projectPath string, hookID string) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.TestProjectWebhook(ctx, projectPath, hookID); err != nil {
+		return fmt.Errorf("test project webhook: %w", err)
+	}
+	return nil
+}
+
+// FormatSnippet runs gofmt formatting over snippet source.
+func (b *WailsBridge) FormatSnippet(source string) (string, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return "", err
+	}
+	formatted, err := b.app.FormatSnippet(ctx, source)
+	if err != nil {
+		return "", fmt.Errorf("format snippet: %w", err)
+	}
+	return formatted, nil
+}
+
+// SnippetParams returns the parameters a snippet declares.
+func (b *WailsBridge) SnippetParams(source string) ([]snippetparam.Param, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	params, err := b.app.SnippetParams(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("snippet params: %w", err)
+	}
+	return params, nil
+}
+
+// SnippetMeta returns the run configuration a snippet declares.
+func (b *WailsBridge) SnippetMeta(source string) (snippetmeta.Meta, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return snippetmeta.Meta{}, err
+	}
+	meta, err := b.app.SnippetMeta(ctx, source)
+	if err != nil {
+		return snippetmeta.Meta{}, fmt.Errorf("snippet meta: %w", err)
+	}
+	return meta, nil
+}
+
+// RunSnippet executes snippet source against a project context.
+func (b *WailsBridge) RunSnippet(request execution.RunRequest) (execution.Result, error) {
+	ctx, err := b.capabilityContext(app.CapabilityStorage, app.CapabilityToolchain)
+	if err != nil {
+		return execution.Result{}, err
+	}
+
+	runID := strings.TrimSpace(request.RunID)
+	if runID == "" {
+		runID = generateBridgeRunID()
+	}
+	request.RunID = runID
+
+	if NativeToolbarUpdater != nil {
+		NativeToolbarUpdater(true)
+		defer NativeToolbarUpdater(false)
+	}
+
+	// Highlighting is cosmetic; a project that cannot be loaded fails the
+	// run itself with a clearer error.
+	highlighter, _ := b.app.OutputHighlighter(ctx, request.ProjectPath)
+	stdoutHighlights := highlighter.Stream()
+	stderrHighlights := highlighter.Stream()
+	var progress richoutput.ProgressStream
+
+	result, err := b.app.RunSnippet(
+		ctx,
+		request,
+		func(chunk string) {
+			if chunk == "" {
+				return
+			}
+			b.emit(ctx, runStdoutChunkEventName, RunStdoutChunkEvent{
+				RunID:      runID,
+				Chunk:      chunk,
+				Highlights: stdoutHighlights.Feed(chunk),
+				Progress:   progress.Feed(chunk),
+			})
+		},
+		func(chunk string) {
+			if chunk == "" {
+				return
+			}
+			b.emit(ctx, runStderrChunkEventName, RunStderrChunkEvent{
+				RunID:      runID,
+				Chunk:      chunk,
+				Highlights: stderrHighlights.Feed(chunk),
+			})
+		},
+	)
+	if err != nil {
+		return execution.Result{}, fmt.Errorf("run snippet: %w", err)
+	}
+	return result, nil
+}
+
+// ListTests lists the tests, benchmarks, fuzz targets and examples of a
+// project package.
+func (b *WailsBridge) ListTests(projectPath string, packagePath string) ([]project.TestFunc, error) {
+	ctx, err := b.capabilityContext(app.CapabilityStorage, app.CapabilityToolchain)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := b.app.ListTests(ctx, projectPath, packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("list tests: %w", err)
+	}
+	return tests, nil
+}
+
+// RunTest runs tests of a project package with go test, streaming output
+// as run chunk events under the request's run ID.
+func (b *WailsBridge) RunTest(request app.TestRequest) (execution.Result, error) {
+	ctx, err := b.capabilityContext(app.CapabilityStorage, app.CapabilityToolchain)
+	if err != nil {
+		return execution.Result{}, err
+	}
+
+	runID := strings.TrimSpace(request.RunID)
+	if runID == "" {
+		runID = generateBridgeRunID()
+	}
+	request.RunID = runID
+
+	highlighter, _ := b.app.OutputHighlighter(ctx, request.ProjectPath)
+	stdoutHighlights := highlighter.Stream()
+	stderrHighlights := highlighter.Stream()
+
+	result, err := b.app.RunTest(
+		ctx,
+		request,
+		func(chunk string) {
+			if chunk == "" {
+				return
+			}
+			b.emit(ctx, runStdoutChunkEventName, RunStdoutChunkEvent{
+				RunID:      runID,
+				Chunk:      chunk,
+				Highlights: stdoutHighlights.Feed(chunk),
+			})
+		},
+		func(chunk string) {
+			if chunk == "" {
+				return
+			}
+			b.emit(ctx, runStderrChunkEventName, RunStderrChunkEvent{
+				RunID:      runID,
+				Chunk:      chunk,
+				Highlights: stderrHighlights.Feed(chunk),
+			})
+		},
+	)
+	if err != nil {
+		return execution.Result{}, fmt.Errorf("run test: %w", err)
+	}
+	return result, nil
+}
+
+// ProgressHelper returns Go source a snippet can paste in to report progress
+// with //gopoke:progress lines.
+func (b *WailsBridge) ProgressHelper() string {
+	return richoutput.ProgressHelper
+}
+
+// MeasureHelper returns Go source a snippet can paste in to measure a block
+// with //gopoke:measure lines when it cannot import the gopoke helper.
+func (b *WailsBridge) MeasureHelper() string {
+	return richoutput.MeasureHelper
+}
+
+// RunEnvMatrix runs a snippet once per environment set and compares the
+// outcomes. Cancelling RunID-<n> stops the matrix at set n.
+func (b *WailsBridge) RunEnvMatrix(request execution.RunRequest, envSets []map[string]string) (app.EnvMatrixResult, error) {
+	ctx, err := b.capabilityContext(app.CapabilityStorage, app.CapabilityToolchain)
+	if err != nil {
+		return app.EnvMatrixResult{}, err
+	}
+	if strings.TrimSpace(request.RunID) == "" {
+		request.RunID = generateBridgeRunID()
+	}
+	result, err := b.app.RunEnvMatrix(ctx, request, envSets)
+	if err != nil {
+		return app.EnvMatrixResult{}, fmt.Errorf("run env matrix: %w", err)
+	}
+	return result, nil
+}
+
+// RunIsolationCheck runs a project snippet in the project and in scratch
+// mode and reports the differences. Cancelling RunID-project or
+// RunID-scratch stops the check.
+func (b *WailsBridge) RunIsolationCheck(request execution.RunRequest) (app.IsolationReport, error) {
+	ctx, err := b.capabilityContext(app.CapabilityStorage, app.CapabilityToolchain)
+	if err != nil {
+		return app.IsolationReport{}, err
+	}
+	if strings.TrimSpace(request.RunID) == "" {
+		request.RunID = generateBridgeRunID()
+	}
+	report, err := b.app.RunIsolationCheck(ctx, request)
+	if err != nil {
+		return app.IsolationReport{}, fmt.Errorf("run isolation check: %w", err)
+	}
+	return report, nil
+}
+
+// CancelRun requests cancellation for an active run.
+func (b *WailsBridge) CancelRun(runID string) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.CancelRun(ctx, runID); err != nil {
+		return fmt.Errorf("cancel run: %w", err)
+	}
+	return nil
+}
+
+// ClearRunCache forgets cached results of a project's cacheable snippets,
+// or of every project when projectPath is empty.
+func (b *WailsBridge) ClearRunCache(projectPath string) (int, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return 0, err
+	}
+	removed, err := b.app.ClearRunCache(ctx, projectPath)
+	if err != nil {
+		return 0, fmt.Errorf("clear run cache: %w", err)
+	}
+	return removed, nil
+}
+
+// CheckSnippet builds and vets snippet source without running it.
+func (b *WailsBridge) CheckSnippet(request execution.RunRequest) (execution.CheckResult, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return execution.CheckResult{}, err
+	}
+	result, err := b.app.CheckSnippet(ctx, request)
+	if err != nil {
+		return execution.CheckResult{}, fmt.Errorf("check snippet: %w", err)
+	}
+	return result, nil
+}
+
+// ActiveRunProcesses lists the process tree started by an active run.
+func (b *WailsBridge) ActiveRunProcesses(runID string) ([]procmem.Process, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	processes, err := b.app.ActiveRunProcesses(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("active run processes: %w", err)
+	}
+	return processes, nil
+}
+
+// KillRunProcess kills one process from an active run's process tree.
+func (b *WailsBridge) KillRunProcess(runID string, pid int) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.KillRunProcess(ctx, runID, pid); err != nil {
+		return fmt.Errorf("kill run process: %w", err)
+	}
+	return nil
+}
+
+// RespondRunNetworkPermission answers a paused run's network permission
+// prompt with "allowOnce", "allowProject" or "deny".
+func (b *WailsBridge) RespondRunNetworkPermission(runID string, decision string) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.RespondRunNetworkPermission(ctx, runID, decision); err != nil {
+		return fmt.Errorf("respond run network permission: %w", err)
+	}
+	return nil
+}
+
+// SetProjectNetworkAllow replaces the hosts a project's runs may reach
+// without a permission prompt.
+func (b *WailsBridge) SetProjectNetworkAllow(projectPath string, hosts []string) (storage.ProjectRecord, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return storage.ProjectRecord{}, err
+	}
+	record, err := b.app.SetProjectNetworkAllow(ctx, projectPath, hosts)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project network allow list: %w", err)
+	}
+	return record, nil
+}
+
+// Plugins lists the installed plugins with their state and commands.
+func (b *WailsBridge) Plugins() ([]plugins.Info, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := b.app.Plugins(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list plugins: %w", err)
+	}
+	return infos, nil
+}
+
+// ReloadPlugins restarts the plugin host, picking up added or removed plugins.
+func (b *WailsBridge) ReloadPlugins() ([]plugins.Info, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	infos, err := b.app.ReloadPlugins(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reload plugins: %w", err)
+	}
+	return infos, nil
+}
+
+// RestartPlugin restarts one plugin.
+func (b *WailsBridge) RestartPlugin(name string) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.RestartPlugin(ctx, name); err != nil {
+		return fmt.Errorf("restart plugin: %w", err)
+	}
+	return nil
+}
+
+// ApprovePlugin grants a plugin its requested permissions and starts it.
+func (b *WailsBridge) ApprovePlugin(name string) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.ApprovePlugin(ctx, name); err != nil {
+		return fmt.Errorf("approve plugin: %w", err)
+	}
+	return nil
+}
+
+// RunPluginCommand runs a plugin command with JSON arguments and returns its
+// output.
+func (b *WailsBridge) RunPluginCommand(plugin string, command string, args string) (string, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return "", err
+	}
+	output, err := b.app.RunPluginCommand(ctx, plugin, command, args)
+	if err != nil {
+		return "", fmt.Errorf("run plugin command: %w", err)
+	}
+	return output, nil
+}
+
+// AnalyzeProject suggests run targets, env vars and tasks for a project.
+func (b *WailsBridge) AnalyzeProject(projectPath string) (project.Onboarding, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return project.Onboarding{}, err
+	}
+	onboarding, err := b.app.AnalyzeProject(ctx, projectPath)
+	if err != nil {
+		return project.Onboarding{}, fmt.Errorf("analyze project: %w", err)
+	}
+	return onboarding, nil
+}
+
+// ActivityHeatmap shows which packages a project's runs and edits targeted
+// over the last days; zero days uses the default window.
+func (b *WailsBridge) ActivityHeatmap(projectPath string, days int) (app.ActivityHeatmap, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.ActivityHeatmap{}, err
+	}
+	heatmap, err := b.app.ActivityHeatmap(ctx, projectPath, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		return app.ActivityHeatmap{}, fmt.Errorf("activity heatmap: %w", err)
+	}
+	return heatmap, nil
+}
+
+// IdleStatus reports whether background work is paused for inactivity.
+func (b *WailsBridge) IdleStatus() (idle.Status, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return idle.Status{}, err
+	}
+	status, err := b.app.IdleStatus(ctx)
+	if err != nil {
+		return idle.Status{}, fmt.Errorf("idle status: %w", err)
+	}
+	return status, nil
+}
+
+// Capabilities returns the bridge manifest: API version, methods, events
+// and feature flags, with which capabilities are available so the frontend
+// can show degraded mode.
+func (b *WailsBridge) Capabilities() (BridgeManifest, error) {
+	ctx, err := b.capabilityContext()
+	if err != nil {
+		return BridgeManifest{}, err
+	}
+	report, err := b.app.Capabilities(ctx)
+	if err != nil {
+		return BridgeManifest{}, fmt.Errorf("capabilities: %w", err)
+	}
+	return newBridgeManifest(report, b.eventNames()), nil
+}
+
+// RecheckCapabilities probes every capability now, re-enabling fixed ones,
+// and returns the updated manifest.
+func (b *WailsBridge) RecheckCapabilities() (BridgeManifest, error) {
+	ctx, err := b.capabilityContext()
+	if err != nil {
+		return BridgeManifest{}, err
+	}
+	report, err := b.app.RecheckCapabilities(ctx)
+	if err != nil {
+		return BridgeManifest{}, fmt.Errorf("recheck capabilities: %w", err)
+	}
+	return newBridgeManifest(report, b.eventNames()), nil
+}
+
+// PowerStatus reports the power source and whether background work is
+// reduced for it.
+func (b *WailsBridge) PowerStatus() (app.PowerStatus, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.PowerStatus{}, err
+	}
+	status, err := b.app.PowerStatus(ctx)
+	if err != nil {
+		return app.PowerStatus{}, fmt.Errorf("power status: %w", err)
+	}
+	return status, nil
+}
+
+// ListCommands returns the command registry behind the command palette and
+// key bindings.
+func (b *WailsBridge) ListCommands() ([]app.Command, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	commands, err := b.app.ListCommands(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list commands: %w", err)
+	}
+	return commands, nil
+}
+
+// ExecuteCommand runs a registry command with JSON arguments and returns
+// what the underlying action returns.
+func (b *WailsBridge) ExecuteCommand(id string, args string) (any, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	result, err := b.app.ExecuteCommand(ctx, id, args)
+	if err != nil {
+		return nil, fmt.Errorf("execute command: %w", err)
+	}
+	return result, nil
+}
+
+// StartProjectWorker ensures a long-lived worker process exists for a project.
+func (b *WailsBridge) StartProjectWorker(projectPath string) (runner.Worker, error) {
+	ctx, err := b.capabilityContext(app.CapabilityStorage, app.CapabilityToolchain)
+	if err != nil {
+		return runner.Worker{}, err
+	}
+	worker, err := b.app.StartProjectWorker(ctx, projectPath)
+	if err != nil {
+		return runner.Worker{}, fmt.Errorf("start project worker: %w", err)
+	}
+	return worker, nil
+}
+
+// StopProjectWorker stops a worker process for a project.
+func (b *WailsBridge) StopProjectWorker(projectPath string) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.StopProjectWorker(ctx, projectPath); err != nil {
+		return fmt.Errorf("stop project worker: %w", err)
+	}
+	return nil
+}
+
+// ProjectWorkers lists running project workers with PID, uptime, restarts, and memory.
+func (b *WailsBridge) ProjectWorkers() ([]runner.Worker, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	workers, err := b.app.ProjectWorkers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("project workers: %w", err)
+	}
+	return workers, nil
+}
+
+// WorkerLogs returns recent captured stdout/stderr lines for a project worker.
+func (b *WailsBridge) WorkerLogs(projectPath string, limit int) ([]runner.LogLine, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	lines, err := b.app.WorkerLogs(ctx, projectPath, limit)
+	if err != nil {
+		return nil, fmt.Errorf("worker logs: %w", err)
+	}
+	return lines, nil
+}
+
+// ChooseProjectDirectory opens a native directory picker and returns the selected path.
+func (b *WailsBridge) ChooseProjectDirectory() (string, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return "", err
+	}
+	path, err := b.openDirectoryDialog(ctx)
+	if err != nil {
+		return "", fmt.Errorf("choose project directory: %w", err)
+	}
+	return path, nil
+}
+
+// LSPWebSocketPort returns the WebSocket proxy port for monaco-languageclient.
+func (b *WailsBridge) LSPWebSocketPort() (int, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return 0, err
+	}
+	return b.app.LSPWebSocketPort(ctx), nil
+}
+
+// LSPWorkspaceInfo returns workspace details for the frontend LSP client.
+func (b *WailsBridge) LSPWorkspaceInfo() (lsp.WorkspaceInfo, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return lsp.WorkspaceInfo{}, err
+	}
+	return b.app.LSPWorkspaceInfo(ctx), nil
+}
+
+// LSPModDocuments returns module and workspace files the editor can open
+// through the language server.
+func (b *WailsBridge) LSPModDocuments() ([]lsp.ModDocument, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	return b.app.LSPModDocuments(ctx), nil
+}
+
+// DocumentDiagnostics returns the deduplicated gopls and run diagnostics for
+// one document, most severe first. runID may be empty.
+func (b *WailsBridge) DocumentDiagnostics(documentURI string, runID string) ([]diagnostics.Entry, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	feed, err := b.app.DocumentDiagnostics(ctx, documentURI, runID)
+	if err != nil {
+		return nil, fmt.Errorf("document diagnostics: %w", err)
+	}
+	return feed, nil
+}
+
+// ApplyQuickFix returns source with the deterministic fix for a run
+// diagnostic applied.
+func (b *WailsBridge) ApplyQuickFix(source string, diagnostic execution.Diagnostic) (string, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return "", err
+	}
+	patched, err := b.app.ApplyQuickFix(ctx, source, diagnostic)
+	if err != nil {
+		return "", fmt.Errorf("apply quick fix: %w", err)
+	}
+	return patched, nil
+}
+
+// DocumentSymbols returns the outline of snippet source for the outline
+// sidebar.
+func (b *WailsBridge) DocumentSymbols(source string) ([]lite.Symbol, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	symbols, err := b.app.DocumentSymbols(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("document symbols: %w", err)
+	}
+	return symbols, nil
+}
+
+// DocumentSymbolPath returns the symbols enclosing a cursor position for the
+// editor breadcrumbs.
+func (b *WailsBridge) DocumentSymbolPath(source string, line int, character int) ([]lite.Symbol, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	path, err := b.app.DocumentSymbolPath(ctx, source, line, character)
+	if err != nil {
+		return nil, fmt.Errorf("document symbol path: %w", err)
+	}
+	return path, nil
+}
+
+// FoldingRanges returns the foldable regions of snippet source.
+func (b *WailsBridge) FoldingRanges(source string) ([]lite.FoldingRange, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	ranges, err := b.app.FoldingRanges(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("folding ranges: %w", err)
+	}
+	return ranges, nil
+}
+
+// LSPStatus returns LSP readiness status.
+func (b *WailsBridge) LSPStatus() (lsp.StatusResult, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return lsp.StatusResult{}, err
+	}
+	return b.app.LSPStatus(ctx), nil
+}
+
+// LSPAnalysisState returns whether gopls diagnostics are current. Changes are
+// also streamed as analysis events.
+func (b *WailsBridge) LSPAnalysisState() (lsp.AnalysisEvent, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return lsp.AnalysisEvent{}, err
+	}
+	return b.app.LSPAnalysisState(ctx), nil
+}
+
+// ChooseGoFile opens a native file picker filtered to .go files.
+func (b *WailsBridge) ChooseGoFile() (string, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return "", err
+	}
+	path, err := b.openFileDialog(ctx)
+	if err != nil {
+		return "", fmt.Errorf("choose go file: %w", err)
+	}
+	return path, nil
+}
+
+// OpenGoFile reads a .go file and opens its parent directory as project context.
+func (b *WailsBridge) OpenGoFile(filePath string) (app.OpenGoFileResult, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.OpenGoFileResult{}, err
+	}
+	result, err := b.app.OpenGoFile(ctx, filePath)
+	if err != nil {
+		return app.OpenGoFileResult{}, fmt.Errorf("open go file: %w", err)
+	}
+
+	// Start LSP synchronously for the parent directory.
+	if lspErr := b.app.StartLSP(context.Background(), result.ProjectResult.Project.Path); lspErr != nil {
+		fmt.Printf("gopls start (file): %v\n", lspErr)
+	}
+
+	return result, nil
+}
+
+// SaveGoFile writes content back to a .go file on disk.
+func (b *WailsBridge) SaveGoFile(filePath string, content string) error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
+	if err := b.app.SaveGoFile(ctx, filePath, content); err != nil {
+		return fmt.Errorf("save go file: %w", err)
+	}
+	return nil
+}
+
+// GetGlobalSettings returns the current global settings.
+func (b *WailsBridge) GetGlobalSettings() (settings.GlobalSettings, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return settings.GlobalSettings{}, err
+	}
+	return b.app.GetGlobalSettings(ctx)
+}
+
+// UpdateGlobalSettings saves new global settings.
+func (b *WailsBridge) UpdateGlobalSettings(gs settings.GlobalSettings) (settings.GlobalSettings, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return settings.GlobalSettings{}, err
+	}
+	return b.app.UpdateGlobalSettings(ctx, gs)
+}
+
+// DetectToolVersions returns detected tool versions with minimum-version checks
+// and install/upgrade actions.
+func (b *WailsBridge) DetectToolVersions() (app.ToolVersions, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.ToolVersions{}, err
+	}
+	return b.app.DetectToolVersions(ctx), nil
+}
+
+// ListGoVersions fetches available Go SDK versions from go.dev.
+func (b *WailsBridge) ListGoVersions() ([]download.GoVersion, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
 	}
 	return download.ListGoVersions(ctx)
 }
@@ -565,28 +1840,26 @@ func (b *WailsBridge) DownloadGoSDK(version string) error {
 		return err
 	}
 
-	go func() {
-		dlErr := b.downloads.DownloadGoSDK(ctx, version, func(p download.Progress) {
-			b.emitEvent(ctx, toolchainProgressEventName, p)
-		})
-		if dlErr != nil {
-			b.emitEvent(ctx, toolchainErrorEventName, map[string]string{
-				"tool":    "go",
-				"message": dlErr.Error(),
-			})
-			return
-		}
-		b.emitEvent(ctx, toolchainCompleteEventName, map[string]string{
-			"tool": "go",
-			"path": b.downloads.GoBinPath(),
-		})
-	}()
-
+	b.installToolAsync(ctx, "go", b.downloads.GoBinPath(), func(onProgress download.OnProgress) error {
+		return b.downloads.DownloadGoSDK(ctx, version, onProgress)
+	})
 	return nil
 }
 
 // DownloadGopls triggers gopls installation with progress events.
 func (b *WailsBridge) DownloadGopls() error {
+	return b.InstallTool("gopls")
+}
+
+// DownloadStaticcheck triggers staticcheck installation with progress events.
+func (b *WailsBridge) DownloadStaticcheck() error {
+	return b.InstallTool("staticcheck")
+}
+
+// InstallTool installs or upgrades a tool reported by DetectToolVersions,
+// emitting toolchain download events. "go" installs the latest stable SDK and
+// "gotip" builds the development toolchain selectable as "tip".
+func (b *WailsBridge) InstallTool(tool string) error {
 	ctx, err := b.requestContext()
 	if err != nil {
 		return err
@@ -595,56 +1868,285 @@ func (b *WailsBridge) DownloadGopls() error {
 	gs, _ := b.app.GetGlobalSettings(ctx)
 	goPath := gs.GoPath
 
+	var install func(onProgress download.OnProgress) error
+	switch tool {
+	case "go":
+		b.installToolAsync(ctx, tool, b.downloads.GoBinPath(), func(onProgress download.OnProgress) error {
+			version, err := latestStableGoVersion(ctx)
+			if err != nil {
+				return err
+			}
+			return b.downloads.DownloadGoSDK(ctx, version, onProgress)
+		})
+		return nil
+	case "gopls":
+		install = func(onProgress download.OnProgress) error {
+			return b.downloads.InstallGopls(ctx, goPath, onProgress)
+		}
+	case "staticcheck":
+		install = func(onProgress download.OnProgress) error {
+			return b.downloads.InstallStaticcheck(ctx, goPath, onProgress)
+		}
+	case "dlv":
+		install = func(onProgress download.OnProgress) error {
+			return b.downloads.InstallDelve(ctx, goPath, onProgress)
+		}
+	case "golangci-lint":
+		install = func(onProgress download.OnProgress) error {
+			return b.downloads.InstallGolangciLint(ctx, goPath, onProgress)
+		}
+	case "gotip":
+		install = func(onProgress download.OnProgress) error {
+			return b.downloads.InstallGotip(ctx, goPath, onProgress)
+		}
+	default:
+		return fmt.Errorf("install tool: unsupported tool %q", tool)
+	}
+
+	b.installToolAsync(ctx, tool, b.downloads.ToolBinPath(tool), install)
+	return nil
+}
+
+// CancelDownload aborts a running toolchain or tool download started by
+// DownloadGoSDK or InstallTool; its error event is marked canceled. It
+// reports whether a download of tool was running.
+func (b *WailsBridge) CancelDownload(tool string) (bool, error) {
+	if _, err := b.requestContext(); err != nil {
+		return false, err
+	}
+	return b.downloads.CancelDownload(tool), nil
+}
+
+// ActiveDownloads returns the latest progress of every running toolchain or
+// tool download, so a reopened settings view can resume showing them.
+func (b *WailsBridge) ActiveDownloads() ([]download.Progress, error) {
+	if _, err := b.requestContext(); err != nil {
+		return nil, err
+	}
+	return b.downloads.Active(), nil
+}
+
+// installToolAsync runs install in the background and reports progress,
+// completion, and failure through toolchain download events.
+func (b *WailsBridge) installToolAsync(ctx context.Context, tool string, path string, install func(onProgress download.OnProgress) error) {
 	go func() {
-		dlErr := b.downloads.InstallGopls(ctx, goPath, func(p download.Progress) {
-			b.emitEvent(ctx, toolchainProgressEventName, p)
+		dlErr := install(func(p download.Progress) {
+			b.emit(ctx, toolchainProgressEventName, p)
 		})
 		if dlErr != nil {
-			b.emitEvent(ctx, toolchainErrorEventName, map[string]string{
-				"tool":    "gopls",
-				"message": dlErr.Error(),
+			b.emit(ctx, toolchainErrorEventName, ToolchainErrorEvent{
+				Tool:     tool,
+				Message:  dlErr.Error(),
+				Canceled: errors.Is(dlErr, context.Canceled),
 			})
 			return
 		}
-		b.emitEvent(ctx, toolchainCompleteEventName, map[string]string{
-			"tool": "gopls",
-			"path": filepath.Join(b.downloads.ToolBinDir(), "gopls"),
-		})
+		b.emit(ctx, toolchainCompleteEventName, ToolchainCompleteEvent{Tool: tool, Path: path})
 	}()
+}
 
-	return nil
+func latestStableGoVersion(ctx context.Context) (string, error) {
+	versions, err := download.ListGoVersions(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, version := range versions {
+		if version.Stable {
+			return version.Version, nil
+		}
+	}
+	return "", fmt.Errorf("no stable go release found")
 }
 
-// DownloadStaticcheck triggers staticcheck installation with progress events.
-func (b *WailsBridge) DownloadStaticcheck() error {
+// AccessibleRunResult returns a completed run's result as linear plain text
+// suitable for screen readers.
+func (b *WailsBridge) AccessibleRunResult(runID string) (string, error) {
 	ctx, err := b.requestContext()
 	if err != nil {
-		return err
+		return "", err
+	}
+	text, err := b.app.AccessibleRunResult(ctx, runID)
+	if err != nil {
+		return "", fmt.Errorf("accessible run result: %w", err)
+	}
+	return text, nil
+}
+
+// ProjectFootprint reports disk usage of a project's caches, artifacts,
+// backups and stored records.
+func (b *WailsBridge) ProjectFootprint(projectPath string) (app.ProjectFootprint, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.ProjectFootprint{}, err
 	}
+	footprint, err := b.app.ProjectFootprint(ctx, projectPath)
+	if err != nil {
+		return app.ProjectFootprint{}, fmt.Errorf("project footprint: %w", err)
+	}
+	return footprint, nil
+}
 
-	gs, _ := b.app.GetGlobalSettings(ctx)
-	goPath := gs.GoPath
+// CleanProjectFootprint clears one footprint category and returns the new report.
+func (b *WailsBridge) CleanProjectFootprint(projectPath string, category string) (app.ProjectFootprint, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.ProjectFootprint{}, err
+	}
+	footprint, err := b.app.CleanProjectFootprint(ctx, projectPath, category)
+	if err != nil {
+		return app.ProjectFootprint{}, fmt.Errorf("clean project footprint: %w", err)
+	}
+	return footprint, nil
+}
+
+// CacheStats reports the generated snippet files of every project against
+// the snippet cache size budget.
+func (b *WailsBridge) CacheStats() (app.CacheStats, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.CacheStats{}, err
+	}
+	stats, err := b.app.CacheStats(ctx)
+	if err != nil {
+		return app.CacheStats{}, fmt.Errorf("cache stats: %w", err)
+	}
+	return stats, nil
+}
+
+// ClearCache removes every project's generated snippet files.
+func (b *WailsBridge) ClearCache() (app.CacheStats, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.CacheStats{}, err
+	}
+	stats, err := b.app.ClearCache(ctx)
+	if err != nil {
+		return app.CacheStats{}, fmt.Errorf("clear cache: %w", err)
+	}
+	return stats, nil
+}
+
+// SelfTest runs and cancels snippets in a loop and reports reliability and
+// leaked goroutines, processes or file descriptors.
+func (b *WailsBridge) SelfTest(iterations int) (app.SelfTestReport, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.SelfTestReport{}, err
+	}
+	report, err := b.app.SelfTest(ctx, iterations)
+	if err != nil {
+		return app.SelfTestReport{}, fmt.Errorf("self test: %w", err)
+	}
+	return report, nil
+}
+
+// StartSessionRecording begins recording opens, saves and runs to a session file.
+func (b *WailsBridge) StartSessionRecording(name string) (app.SessionRecording, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.SessionRecording{}, err
+	}
+	recording, err := b.app.StartSessionRecording(ctx, name)
+	if err != nil {
+		return app.SessionRecording{}, fmt.Errorf("start session recording: %w", err)
+	}
+	return recording, nil
+}
+
+// StopSessionRecording ends the active session recording.
+func (b *WailsBridge) StopSessionRecording() (app.SessionRecording, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.SessionRecording{}, err
+	}
+	recording, err := b.app.StopSessionRecording(ctx)
+	if err != nil {
+		return app.SessionRecording{}, fmt.Errorf("stop session recording: %w", err)
+	}
+	return recording, nil
+}
+
+// ReplaySession returns step-by-step playback of a recorded session,
+// optionally re-running its snippets.
+func (b *WailsBridge) ReplaySession(path string, rerun bool) (app.ReplaySessionResult, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return app.ReplaySessionResult{}, err
+	}
+	result, err := b.app.ReplaySession(ctx, path, rerun)
+	if err != nil {
+		return app.ReplaySessionResult{}, fmt.Errorf("replay session: %w", err)
+	}
+	return result, nil
+}
+
+// ExportRunEvents writes a recent run's lifecycle and output events to
+// destPath as newline-delimited JSON.
+func (b *WailsBridge) ExportRunEvents(runID string, destPath string) (int, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return 0, err
+	}
+	count, err := b.app.ExportRunEvents(ctx, runID, destPath)
+	if err != nil {
+		return 0, fmt.Errorf("export run events: %w", err)
+	}
+	return count, nil
+}
+
+// SupportedLocales returns locale codes with backend message catalogs.
+func (b *WailsBridge) SupportedLocales() []string {
+	return i18n.Locales()
+}
+
+// CheckForUpdate reports whether a newer gopoke release is available.
+func (b *WailsBridge) CheckForUpdate() (update.CheckResult, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return update.CheckResult{}, err
+	}
+	result, err := b.app.CheckForUpdate(ctx)
+	if err != nil {
+		return update.CheckResult{}, fmt.Errorf("check for update: %w", err)
+	}
+	return result, nil
+}
+
+// DownloadUpdate downloads and stages the latest gopoke release in the
+// background, reporting progress through toolchain download events.
+func (b *WailsBridge) DownloadUpdate() error {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return err
+	}
 
 	go func() {
-		dlErr := b.downloads.InstallStaticcheck(ctx, goPath, func(p download.Progress) {
-			b.emitEvent(ctx, toolchainProgressEventName, p)
+		staged, dlErr := b.app.DownloadUpdate(ctx, func(p download.Progress) {
+			b.emit(ctx, toolchainProgressEventName, p)
 		})
 		if dlErr != nil {
-			b.emitEvent(ctx, toolchainErrorEventName, map[string]string{
-				"tool":    "staticcheck",
-				"message": dlErr.Error(),
-			})
+			b.emit(ctx, toolchainErrorEventName, ToolchainErrorEvent{Tool: "gopoke", Message: dlErr.Error()})
 			return
 		}
-		b.emitEvent(ctx, toolchainCompleteEventName, map[string]string{
-			"tool": "staticcheck",
-			"path": filepath.Join(b.downloads.ToolBinDir(), "staticcheck"),
-		})
+		b.emit(ctx, toolchainCompleteEventName, ToolchainCompleteEvent{Tool: "gopoke", Path: staged.Path, Version: staged.Version})
 	}()
 
 	return nil
 }
 
+// PendingUpdate returns the staged update awaiting install, or nil.
+func (b *WailsBridge) PendingUpdate() (*update.StagedUpdate, error) {
+	ctx, err := b.requestContext()
+	if err != nil {
+		return nil, err
+	}
+	staged, err := b.app.PendingUpdate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pending update: %w", err)
+	}
+	return staged, nil
+}
+
 // BrowseForBinary opens a native file picker for selecting a binary.
 func (b *WailsBridge) BrowseForBinary(title string) (string, error) {
 	ctx, err := b.requestContext()
@@ -679,6 +2181,12 @@ func (b *WailsBridge) PlaygroundImport(urlOrHash string) (string, error) {
 }
 
 func (b *WailsBridge) requestContext() (context.Context, error) {
+	return b.capabilityContext(app.CapabilityStorage)
+}
+
+// capabilityContext is requestContext for calls that need capabilities
+// other than storage. Calls that report degraded mode itself require none.
+func (b *WailsBridge) capabilityContext(required ...app.Capability) (context.Context, error) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 	if !b.started {
@@ -687,6 +2195,12 @@ func (b *WailsBridge) requestContext() (context.Context, error) {
 	if b.startupErr != nil {
 		return nil, fmt.Errorf("wails bridge startup failed: %w", b.startupErr)
 	}
+	// Every call is an interaction, so background work paused while the
+	// user was away resumes before the call runs.
+	b.app.NoteActivity()
+	if err := b.app.RequireCapabilities(b.ctx, required...); err != nil {
+		return nil, err
+	}
 	return b.ctx, nil
 }
 
@@ -2,24 +2,47 @@ package desktop
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"gopoke/internal/app"
+	"gopoke/internal/audit"
+	"gopoke/internal/diagnostics"
+	"gopoke/internal/download"
 	"gopoke/internal/execution"
+	"gopoke/internal/highlight"
+	"gopoke/internal/idle"
+	"gopoke/internal/lite"
 	"gopoke/internal/lsp"
 	"gopoke/internal/playground"
+	"gopoke/internal/plugins"
+	"gopoke/internal/procmem"
 	"gopoke/internal/project"
+	"gopoke/internal/richoutput"
 	"gopoke/internal/runner"
+	"gopoke/internal/session"
 	"gopoke/internal/settings"
+	"gopoke/internal/share"
+	"gopoke/internal/snippetmeta"
+	"gopoke/internal/snippetparam"
+	"gopoke/internal/snipsync"
+	"gopoke/internal/stdlibdiff"
 	"gopoke/internal/storage"
+	"gopoke/internal/update"
 )
 
 type fakeApplication struct {
 	startErr error
+	// unavailable capabilities fail RequireCapabilities.
+	unavailable map[app.Capability]string
 
 	healthResp          storage.HealthReport
 	healthErr           error
@@ -58,6 +81,25 @@ type fakeApplication struct {
 	startWorkerResp     runner.Worker
 	startWorkerErr      error
 	stopWorkerErr       error
+	workersResp         []runner.Worker
+	workersErr          error
+	workerLogsResp      []runner.LogLine
+	workerLogHandler    runner.LogHandler
+	analysisHandler     lsp.AnalysisHandler
+	memoryHandler       lsp.MemoryHandler
+	networkHandler      app.RunNetworkHandler
+	permissionHandler   app.RunNetworkPermissionHandler
+	updateCheckResp     update.CheckResult
+	stagedUpdate        update.StagedUpdate
+	updateErr           error
+	accessibleResp      string
+	footprintResp       app.ProjectFootprint
+	footprintErr        error
+	cleanedCategory     string
+	sessionRecording    app.SessionRecording
+	replayResp          app.ReplaySessionResult
+	replayPath          string
+	replayRerun         bool
 	lspStatus           lsp.StatusResult
 	lspWSPort           int
 	lspWorkspaceInfo    lsp.WorkspaceInfo
@@ -84,18 +126,114 @@ func (f *fakeApplication) OpenProject(ctx context.Context, path string) (project
 	return f.openResp, f.openErr
 }
 
+func (f *fakeApplication) RestoreWorkspace(ctx context.Context, projectPath string) (app.WorkspaceRestore, error) {
+	return app.WorkspaceRestore{Project: f.openResp}, f.openErr
+}
+
+func (f *fakeApplication) HomeScreenData(ctx context.Context) (app.HomeScreenData, error) {
+	return app.HomeScreenData{Recent: f.recentResp}, f.recentErr
+}
+
+func (f *fakeApplication) SetProjectPinned(ctx context.Context, projectPath string, pinned bool) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{Path: projectPath, Pinned: pinned}, nil
+}
+
 func (f *fakeApplication) RecentProjects(ctx context.Context, limit int) ([]storage.ProjectRecord, error) {
 	return f.recentResp, f.recentErr
 }
 
+func (f *fakeApplication) RecentProjectsPage(ctx context.Context, page storage.PageRequest) (storage.ProjectPage, error) {
+	return storage.ProjectPage{}, nil
+}
+
+func (f *fakeApplication) ProjectSnippetsPage(ctx context.Context, projectPath string, page storage.PageRequest) (storage.SnippetPage, error) {
+	return storage.SnippetPage{}, nil
+}
+
+func (f *fakeApplication) ProjectRunsPage(ctx context.Context, projectPath string, page storage.PageRequest) (storage.RunPage, error) {
+	return storage.RunPage{}, nil
+}
+
+func (f *fakeApplication) ForgetProject(ctx context.Context, projectPath string) (storage.ForgottenProject, error) {
+	return storage.ForgottenProject{}, nil
+}
+
 func (f *fakeApplication) DiscoverRunTargets(ctx context.Context, path string) ([]project.RunTarget, error) {
 	return f.discoverTargetsResp, f.discoverTargetsErr
 }
 
+func (f *fakeApplication) ParseGoMod(ctx context.Context, projectPath string) (project.GoMod, error) {
+	return project.GoMod{}, nil
+}
+
+func (f *fakeApplication) AddGoModReplace(ctx context.Context, projectPath string, oldPath string, oldVersion string, newPath string, newVersion string) (project.GoMod, error) {
+	return project.GoMod{}, nil
+}
+
+func (f *fakeApplication) DropGoModRequire(ctx context.Context, projectPath string, modulePath string) (project.GoMod, error) {
+	return project.GoMod{}, nil
+}
+
+func (f *fakeApplication) TidyProjectModules(ctx context.Context, projectPath string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeApplication) ParseGoWork(ctx context.Context, projectPath string) (project.GoWork, error) {
+	return project.GoWork{}, nil
+}
+
+func (f *fakeApplication) CreateGoWork(ctx context.Context, projectPath string, moduleDirs []string) (project.GoWork, error) {
+	return project.GoWork{}, nil
+}
+
+func (f *fakeApplication) AddWorkModule(ctx context.Context, projectPath string, moduleDir string) (project.GoWork, error) {
+	return project.GoWork{}, nil
+}
+
+func (f *fakeApplication) DropWorkModule(ctx context.Context, projectPath string, moduleDir string) (project.GoWork, error) {
+	return project.GoWork{}, nil
+}
+
 func (f *fakeApplication) SetProjectDefaultPackage(ctx context.Context, projectPath string, packagePath string) (storage.ProjectRecord, error) {
 	return f.setDefaultResp, f.setDefaultErr
 }
 
+func (f *fakeApplication) SetProjectTrust(ctx context.Context, projectPath string, trust string) (project.OpenProjectResult, error) {
+	return project.OpenProjectResult{}, nil
+}
+
+func (f *fakeApplication) SetProjectRunGuard(ctx context.Context, projectPath string, policy string) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{}, nil
+}
+
+func (f *fakeApplication) SetProjectTestCache(ctx context.Context, projectPath string, mode string) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{}, nil
+}
+
+func (f *fakeApplication) ClearTestCache(ctx context.Context, projectPath string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeApplication) SetProjectModuleOverlay(ctx context.Context, projectPath string, enabled bool) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{Path: projectPath, ModuleOverlay: enabled}, nil
+}
+
+func (f *fakeApplication) DiscardModuleOverlay(ctx context.Context, projectPath string, runID string) error {
+	return nil
+}
+
+func (f *fakeApplication) PreviewRunModuleChanges(ctx context.Context, runID string) (project.ModuleChanges, error) {
+	return project.ModuleChanges{}, nil
+}
+
+func (f *fakeApplication) ApplyRunModuleChanges(ctx context.Context, runID string) (project.ModuleChanges, error) {
+	return project.ModuleChanges{}, nil
+}
+
+func (f *fakeApplication) AuditLog(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	return nil, nil
+}
+
 func (f *fakeApplication) ProjectEnvVars(ctx context.Context, projectPath string) ([]storage.EnvVarRecord, error) {
 	return f.projectEnvVarsResp, f.projectEnvVarsErr
 }
@@ -116,6 +254,53 @@ func (f *fakeApplication) AvailableToolchains(ctx context.Context) ([]project.To
 	return f.toolchainsResp, f.toolchainsErr
 }
 
+func (f *fakeApplication) StdlibDiff(ctx context.Context, symbol string, fromVersion string, toVersion string) (stdlibdiff.Result, error) {
+	return stdlibdiff.Result{Symbol: symbol, From: fromVersion, To: toVersion}, nil
+}
+
+func (f *fakeApplication) SetProjectRunLimits(ctx context.Context, projectPath string, timeoutMS int64, maxOutputBytes int64) (storage.ProjectRecord, error) {
+	record := f.setToolchainResp
+	record.TimeoutMS = timeoutMS
+	record.MaxOutputBytes = maxOutputBytes
+	return record, f.setToolchainErr
+}
+
+func (f *fakeApplication) ToolchainExperiments(ctx context.Context, projectPath string) ([]project.ExperimentSupport, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) SetProjectExperiments(ctx context.Context, projectPath string, experiments []string) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{}, nil
+}
+
+func (f *fakeApplication) OutputEncodings() []string {
+	return nil
+}
+
+func (f *fakeApplication) RunTimeZones() []execution.TimeZoneOption {
+	return nil
+}
+
+func (f *fakeApplication) RunLocales() []execution.LocaleOption {
+	return nil
+}
+
+func (f *fakeApplication) RunDeterminismHelper() string {
+	return ""
+}
+
+func (f *fakeApplication) RunDeadlineHelper() string {
+	return ""
+}
+
+func (f *fakeApplication) AvailableCPUs() int {
+	return 1
+}
+
+func (f *fakeApplication) SetProjectOutputEncoding(ctx context.Context, projectPath string, encoding string) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{OutputEncoding: encoding}, nil
+}
+
 func (f *fakeApplication) SetProjectToolchain(ctx context.Context, projectPath string, toolchain string) (storage.ProjectRecord, error) {
 	return f.setToolchainResp, f.setToolchainErr
 }
@@ -128,10 +313,99 @@ func (f *fakeApplication) SaveProjectSnippet(ctx context.Context, projectPath st
 	return f.saveSnippetResp, f.saveSnippetErr
 }
 
+func (f *fakeApplication) ApproveGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	return storage.SnippetRecord{}, nil
+}
+
+func (f *fakeApplication) RejectGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	return storage.SnippetRecord{}, nil
+}
+
+func (f *fakeApplication) ResetGoldenOutput(ctx context.Context, projectPath string, snippetID string) (storage.SnippetRecord, error) {
+	return storage.SnippetRecord{}, nil
+}
+
 func (f *fakeApplication) DeleteProjectSnippet(ctx context.Context, projectPath string, snippetID string) error {
 	return f.deleteSnippetErr
 }
 
+func (f *fakeApplication) SnippetParams(ctx context.Context, source string) ([]snippetparam.Param, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) RunEnvMatrix(ctx context.Context, request execution.RunRequest, envSets []map[string]string) (app.EnvMatrixResult, error) {
+	return app.EnvMatrixResult{}, nil
+}
+
+func (f *fakeApplication) RunIsolationCheck(ctx context.Context, request execution.RunRequest) (app.IsolationReport, error) {
+	return app.IsolationReport{}, nil
+}
+
+func (f *fakeApplication) SnippetMeta(ctx context.Context, source string) (snippetmeta.Meta, error) {
+	return snippetmeta.Meta{}, nil
+}
+
+func (f *fakeApplication) SetProjectSnippetSync(ctx context.Context, projectPath string, config storage.SnippetSyncConfig) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{}, nil
+}
+
+func (f *fakeApplication) SyncSnippets(ctx context.Context, projectPath string) (snipsync.Report, error) {
+	return snipsync.Report{}, nil
+}
+
+func (f *fakeApplication) StartProjectShare(ctx context.Context, projectPath string, address string) (share.Info, error) {
+	return share.Info{}, nil
+}
+
+func (f *fakeApplication) StopProjectShare(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeApplication) ProjectShare() share.Info {
+	return share.Info{}
+}
+
+func (f *fakeApplication) SetProjectWebhooks(ctx context.Context, projectPath string, hooks []storage.Webhook) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{}, nil
+}
+
+func (f *fakeApplication) TestProjectWebhook(ctx context.Context, projectPath string, hookID string) error {
+	return nil
+}
+
+func (f *fakeApplication) SetProjectHighlights(ctx context.Context, projectPath string, rules []storage.HighlightRule) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{Path: projectPath, Highlights: rules}, nil
+}
+
+func (f *fakeApplication) CreateExperiment(ctx context.Context, projectPath string, name string, description string) (storage.ExperimentRecord, error) {
+	return storage.ExperimentRecord{ID: "exp_1", Name: name, Description: description}, nil
+}
+
+func (f *fakeApplication) AssignRun(ctx context.Context, experimentID string, runID string) (storage.RunRecord, error) {
+	return storage.RunRecord{ID: runID, ExperimentID: experimentID}, nil
+}
+
+func (f *fakeApplication) ExperimentSummaries(ctx context.Context, projectPath string) ([]app.ExperimentSummary, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) ListTests(ctx context.Context, projectPath string, packagePath string) ([]project.TestFunc, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) RunTest(
+	ctx context.Context,
+	request app.TestRequest,
+	onStdoutChunk execution.StdoutChunkHandler,
+	onStderrChunk execution.StderrChunkHandler,
+) (execution.Result, error) {
+	return execution.Result{}, nil
+}
+
+func (f *fakeApplication) OutputHighlighter(ctx context.Context, projectPath string) (*highlight.Highlighter, error) {
+	return nil, nil
+}
+
 func (f *fakeApplication) FormatSnippet(ctx context.Context, source string) (string, error) {
 	return f.formatResp, f.formatErr
 }
@@ -160,6 +434,22 @@ func (f *fakeApplication) CancelRun(ctx context.Context, runID string) error {
 	return f.cancelRunErr
 }
 
+func (f *fakeApplication) ClearRunCache(ctx context.Context, projectPath string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeApplication) CheckSnippet(ctx context.Context, request execution.RunRequest) (execution.CheckResult, error) {
+	return execution.CheckResult{}, nil
+}
+
+func (f *fakeApplication) ActiveRunProcesses(ctx context.Context, runID string) ([]procmem.Process, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) KillRunProcess(ctx context.Context, runID string, pid int) error {
+	return nil
+}
+
 func (f *fakeApplication) StartProjectWorker(ctx context.Context, projectPath string) (runner.Worker, error) {
 	return f.startWorkerResp, f.startWorkerErr
 }
@@ -168,6 +458,18 @@ func (f *fakeApplication) StopProjectWorker(ctx context.Context, projectPath str
 	return f.stopWorkerErr
 }
 
+func (f *fakeApplication) ProjectWorkers(ctx context.Context) ([]runner.Worker, error) {
+	return f.workersResp, f.workersErr
+}
+
+func (f *fakeApplication) WorkerLogs(ctx context.Context, projectPath string, limit int) ([]runner.LogLine, error) {
+	return f.workerLogsResp, nil
+}
+
+func (f *fakeApplication) SetWorkerLogHandler(handler runner.LogHandler) {
+	f.workerLogHandler = handler
+}
+
 func (f *fakeApplication) StartLSP(ctx context.Context, projectPath string) error {
 	return nil
 }
@@ -184,10 +486,131 @@ func (f *fakeApplication) LSPWorkspaceInfo(ctx context.Context) lsp.WorkspaceInf
 	return f.lspWorkspaceInfo
 }
 
+func (f *fakeApplication) LSPModDocuments(ctx context.Context) []lsp.ModDocument {
+	return nil
+}
+
+func (f *fakeApplication) DocumentDiagnostics(ctx context.Context, documentURI string, runID string) ([]diagnostics.Entry, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) ApplyQuickFix(ctx context.Context, source string, diagnostic execution.Diagnostic) (string, error) {
+	return source, nil
+}
+
+func (f *fakeApplication) DocumentSymbols(ctx context.Context, source string) ([]lite.Symbol, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) DocumentSymbolPath(ctx context.Context, source string, line int, character int) ([]lite.Symbol, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) FoldingRanges(ctx context.Context, source string) ([]lite.FoldingRange, error) {
+	return nil, nil
+}
+
 func (f *fakeApplication) LSPStatus(ctx context.Context) lsp.StatusResult {
 	return f.lspStatus
 }
 
+func (f *fakeApplication) SetLSPAnalysisHandler(handler lsp.AnalysisHandler) {
+	f.analysisHandler = handler
+}
+
+func (f *fakeApplication) SetLSPMemoryHandler(handler lsp.MemoryHandler) {
+	f.memoryHandler = handler
+}
+
+func (f *fakeApplication) SetRunNetworkHandler(handler app.RunNetworkHandler) {
+	f.networkHandler = handler
+}
+
+func (f *fakeApplication) SetRunNetworkPermissionHandler(handler app.RunNetworkPermissionHandler) {
+	f.permissionHandler = handler
+}
+
+func (f *fakeApplication) RespondRunNetworkPermission(ctx context.Context, runID string, decision string) error {
+	return nil
+}
+
+func (f *fakeApplication) SetProjectNetworkAllow(ctx context.Context, projectPath string, hosts []string) (storage.ProjectRecord, error) {
+	return storage.ProjectRecord{Path: projectPath, NetworkAllow: hosts}, nil
+}
+
+func (f *fakeApplication) Plugins(ctx context.Context) ([]plugins.Info, error) {
+	return []plugins.Info{}, nil
+}
+
+func (f *fakeApplication) ReloadPlugins(ctx context.Context) ([]plugins.Info, error) {
+	return []plugins.Info{}, nil
+}
+
+func (f *fakeApplication) RestartPlugin(ctx context.Context, name string) error {
+	return nil
+}
+
+func (f *fakeApplication) ApprovePlugin(ctx context.Context, name string) error {
+	return nil
+}
+
+func (f *fakeApplication) RunPluginCommand(ctx context.Context, plugin string, command string, args string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeApplication) ActivityHeatmap(ctx context.Context, projectPath string, window time.Duration) (app.ActivityHeatmap, error) {
+	return app.ActivityHeatmap{}, nil
+}
+
+func (f *fakeApplication) NoteActivity() {}
+
+func (f *fakeApplication) IdleStatus(ctx context.Context) (idle.Status, error) {
+	return idle.Status{}, nil
+}
+
+func (f *fakeApplication) PowerStatus(ctx context.Context) (app.PowerStatus, error) {
+	return app.PowerStatus{}, nil
+}
+
+func (f *fakeApplication) Capabilities(ctx context.Context) (app.CapabilityReport, error) {
+	report := app.CapabilityReport{Degraded: len(f.unavailable) > 0}
+	for capability, reason := range f.unavailable {
+		report.Capabilities = append(report.Capabilities, app.CapabilityStatus{Capability: capability, Reason: reason})
+	}
+	return report, nil
+}
+
+func (f *fakeApplication) RecheckCapabilities(ctx context.Context) (app.CapabilityReport, error) {
+	return f.Capabilities(ctx)
+}
+
+func (f *fakeApplication) RequireCapabilities(ctx context.Context, required ...app.Capability) error {
+	for _, capability := range required {
+		if reason, ok := f.unavailable[capability]; ok {
+			return &app.CapabilityError{Capability: capability, Reason: reason}
+		}
+	}
+	return nil
+}
+
+func (f *fakeApplication) SetCapabilityHandler(handler app.CapabilityHandler) {}
+
+func (f *fakeApplication) AnalyzeProject(ctx context.Context, projectPath string) (project.Onboarding, error) {
+	return project.Onboarding{}, nil
+}
+
+func (f *fakeApplication) ListCommands(ctx context.Context) ([]app.Command, error) {
+	return []app.Command{}, nil
+}
+
+func (f *fakeApplication) ExecuteCommand(ctx context.Context, id string, args string) (any, error) {
+	return nil, nil
+}
+
+func (f *fakeApplication) LSPAnalysisState(ctx context.Context) lsp.AnalysisEvent {
+	return lsp.AnalysisEvent{State: lsp.AnalysisClean}
+}
+
 func (f *fakeApplication) PlaygroundShare(ctx context.Context, source string) (playground.ShareResult, error) {
 	return playground.ShareResult{URL: "https://go.dev/play/p/test", Hash: "test"}, nil
 }
@@ -218,6 +641,65 @@ func (f *fakeApplication) DetectToolVersions(ctx context.Context) app.ToolVersio
 	return app.ToolVersions{}
 }
 
+func (f *fakeApplication) AccessibleRunResult(ctx context.Context, runID string) (string, error) {
+	return f.accessibleResp, nil
+}
+
+func (f *fakeApplication) ProjectFootprint(ctx context.Context, projectPath string) (app.ProjectFootprint, error) {
+	return f.footprintResp, f.footprintErr
+}
+
+func (f *fakeApplication) CleanProjectFootprint(ctx context.Context, projectPath string, category string) (app.ProjectFootprint, error) {
+	f.cleanedCategory = category
+	return f.footprintResp, f.footprintErr
+}
+
+func (f *fakeApplication) CacheStats(ctx context.Context) (app.CacheStats, error) {
+	return app.CacheStats{}, nil
+}
+
+func (f *fakeApplication) ClearCache(ctx context.Context) (app.CacheStats, error) {
+	return app.CacheStats{}, nil
+}
+
+func (f *fakeApplication) SelfTest(ctx context.Context, iterations int) (app.SelfTestReport, error) {
+	return app.SelfTestReport{Iterations: iterations}, nil
+}
+
+func (f *fakeApplication) ExportRunEvents(ctx context.Context, runID string, destPath string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeApplication) StartSessionRecording(ctx context.Context, name string) (app.SessionRecording, error) {
+	return f.sessionRecording, nil
+}
+
+func (f *fakeApplication) StopSessionRecording(ctx context.Context) (app.SessionRecording, error) {
+	return app.SessionRecording{Path: f.sessionRecording.Path}, nil
+}
+
+func (f *fakeApplication) ReplaySession(ctx context.Context, path string, rerun bool) (app.ReplaySessionResult, error) {
+	f.replayPath = path
+	f.replayRerun = rerun
+	return f.replayResp, nil
+}
+
+func (f *fakeApplication) CheckForUpdate(ctx context.Context) (update.CheckResult, error) {
+	return f.updateCheckResp, f.updateErr
+}
+
+func (f *fakeApplication) DownloadUpdate(ctx context.Context, onProgress download.OnProgress) (update.StagedUpdate, error) {
+	if f.updateErr != nil {
+		return update.StagedUpdate{}, f.updateErr
+	}
+	onProgress(download.Progress{Tool: "gopoke", Stage: "downloading", Percent: 50})
+	return f.stagedUpdate, nil
+}
+
+func (f *fakeApplication) PendingUpdate(ctx context.Context) (*update.StagedUpdate, error) {
+	return nil, f.updateErr
+}
+
 func (f *fakeApplication) ScratchDir() string { return "" }
 
 func TestWailsBridgeRequiresStartup(t *testing.T) {
@@ -243,6 +725,56 @@ func TestWailsBridgeStartupError(t *testing.T) {
 	}
 }
 
+func TestWailsBridgeGatesCallsOnCapabilities(t *testing.T) {
+	t.Parallel()
+
+	bridge := NewWailsBridge(&fakeApplication{unavailable: map[app.Capability]string{app.CapabilityToolchain: "go not found"}})
+	bridge.Startup(context.Background())
+
+	if _, err := bridge.RunSnippet(execution.RunRequest{ProjectPath: "/tmp/project", Source: "package main"}); !errors.Is(err, app.ErrCapabilityUnavailable) {
+		t.Fatalf("RunSnippet() error = %v, want capability error", err)
+	}
+	if _, err := bridge.RecentProjects(10); err != nil {
+		t.Fatalf("RecentProjects() error = %v, want storage calls to keep working", err)
+	}
+	report, err := bridge.Capabilities()
+	if err != nil || !report.Degraded {
+		t.Fatalf("Capabilities() = %+v, %v", report, err)
+	}
+}
+
+func TestWailsBridgeCapabilitiesManifest(t *testing.T) {
+	t.Parallel()
+
+	bridge := NewWailsBridge(&fakeApplication{unavailable: map[app.Capability]string{app.CapabilityToolchain: "go not found"}})
+	bridge.Startup(context.Background())
+
+	manifest, err := bridge.Capabilities()
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+	if manifest.APIVersion != BridgeAPIVersion || manifest.MinAPIVersion > manifest.APIVersion || manifest.AppVersion == "" {
+		t.Fatalf("manifest versions = %d/%d/%q", manifest.APIVersion, manifest.MinAPIVersion, manifest.AppVersion)
+	}
+	if !slices.Contains(manifest.Methods, "RunSnippet") || !slices.Contains(manifest.Methods, "Capabilities") || slices.Contains(manifest.Methods, "Startup") {
+		t.Fatalf("Methods = %v", manifest.Methods)
+	}
+	if !slices.IsSorted(manifest.Methods) || !slices.Contains(manifest.Events, capabilitiesEventName) {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	if manifest.Features["runs"] || !manifest.Features["projects"] || !manifest.Degraded {
+		t.Fatalf("Features = %v, Degraded = %v; want runs off while the toolchain is missing", manifest.Features, manifest.Degraded)
+	}
+
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("marshal manifest: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"degraded":true`) {
+		t.Fatalf("manifest JSON = %s, want the capability report fields inline", encoded)
+	}
+}
+
 func TestWailsBridgeForwardsMethods(t *testing.T) {
 	t.Parallel()
 
@@ -462,7 +994,7 @@ func TestWailsBridgeRunSnippet(t *testing.T) {
 		},
 		runStdoutChunks: []string{"ok\n", "streamed\n"},
 		runStderrChunks: []string{"warn-1\n", "warn-2\n"},
-	})
+	}, WithStrictEvents())
 	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {
 		switch eventName {
 		case runStdoutChunkEventName:
@@ -517,6 +1049,37 @@ func TestWailsBridgeRunSnippet(t *testing.T) {
 	}
 }
 
+func TestWailsBridgeRunSnippetStreamsProgress(t *testing.T) {
+	t.Parallel()
+
+	emitted := make([]RunStdoutChunkEvent, 0)
+	bridge := NewWailsBridge(&fakeApplication{
+		runStdoutChunks: []string{"//gopoke:progress 1/4 \"index\"\n//gopoke:pro", "gress 4/4 \"index\"\nok\n"},
+	}, WithStrictEvents())
+	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {
+		if event, ok := payload.(RunStdoutChunkEvent); ok {
+			emitted = append(emitted, event)
+		}
+	}
+	bridge.Startup(context.Background())
+
+	if _, err := bridge.RunSnippet(execution.RunRequest{RunID: "run_progress", Source: "package main\nfunc main(){}\n"}); err != nil {
+		t.Fatalf("RunSnippet() error = %v", err)
+	}
+	want := [][]richoutput.Progress{
+		{{Label: "index", Current: 1, Total: 4}},
+		{{Label: "index", Current: 4, Total: 4, Done: true}},
+	}
+	if len(emitted) != len(want) {
+		t.Fatalf("len(emitted) = %d, want %d", len(emitted), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(emitted[i].Progress, want[i]) {
+			t.Fatalf("emitted[%d].Progress = %+v, want %+v", i, emitted[i].Progress, want[i])
+		}
+	}
+}
+
 func TestWailsBridgeProjectWorkerLifecycle(t *testing.T) {
 	t.Parallel()
 
@@ -541,6 +1104,270 @@ func TestWailsBridgeProjectWorkerLifecycle(t *testing.T) {
 	}
 }
 
+func TestWailsBridgeProjectWorkers(t *testing.T) {
+	t.Parallel()
+
+	bridge := NewWailsBridge(&fakeApplication{
+		workersResp: []runner.Worker{
+			{ProjectPath: "/tmp/project", PID: 1234, Running: true, RestartCount: 2},
+		},
+	})
+	bridge.Startup(context.Background())
+
+	workers, err := bridge.ProjectWorkers()
+	if err != nil {
+		t.Fatalf("ProjectWorkers() error = %v", err)
+	}
+	if got, want := len(workers), 1; got != want {
+		t.Fatalf("len(workers) = %d, want %d", got, want)
+	}
+	if got, want := workers[0].RestartCount, 2; got != want {
+		t.Fatalf("workers[0].RestartCount = %d, want %d", got, want)
+	}
+}
+
+func TestWailsBridgeInstallToolRejectsUnknownTool(t *testing.T) {
+	t.Parallel()
+
+	bridge := NewWailsBridge(&fakeApplication{})
+	bridge.Startup(context.Background())
+
+	err := bridge.InstallTool("emacs")
+	if err == nil || !strings.Contains(err.Error(), "unsupported tool") {
+		t.Fatalf("InstallTool() error = %v, want unsupported tool", err)
+	}
+}
+
+func TestWailsBridgeDownloadUpdateEmitsProgress(t *testing.T) {
+	t.Parallel()
+
+	bridge := NewWailsBridge(&fakeApplication{
+		stagedUpdate: update.StagedUpdate{Version: "v1.2.0", Path: "/tmp/updates/gopoke-1.2.0"},
+	}, WithStrictEvents())
+	events := make(chan string, 4)
+	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {
+		events <- eventName
+	}
+	bridge.Startup(context.Background())
+
+	if err := bridge.DownloadUpdate(); err != nil {
+		t.Fatalf("DownloadUpdate() error = %v", err)
+	}
+	for _, want := range []string{toolchainProgressEventName, toolchainCompleteEventName} {
+		select {
+		case got := <-events:
+			if got != want {
+				t.Fatalf("event = %q, want %q", got, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
+
+func TestWailsBridgeAccessibleRunResult(t *testing.T) {
+	t.Parallel()
+
+	bridge := NewWailsBridge(&fakeApplication{accessibleResp: "Run succeeded in 5 milliseconds."})
+	bridge.Startup(context.Background())
+
+	text, err := bridge.AccessibleRunResult("run-1")
+	if err != nil {
+		t.Fatalf("AccessibleRunResult() error = %v", err)
+	}
+	if got, want := text, "Run succeeded in 5 milliseconds."; got != want {
+		t.Fatalf("AccessibleRunResult() = %q, want %q", got, want)
+	}
+}
+
+func TestWailsBridgeReplaySession(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeApplication{
+		replayResp: app.ReplaySessionResult{Steps: []session.Step{{Index: 1, Description: "Opened project /tmp/demo"}}},
+	}
+	bridge := NewWailsBridge(fake)
+	bridge.Startup(context.Background())
+
+	result, err := bridge.ReplaySession("/tmp/demo.gopoke-session.jsonl", true)
+	if err != nil {
+		t.Fatalf("ReplaySession() error = %v", err)
+	}
+	if got, want := len(result.Steps), 1; got != want {
+		t.Fatalf("len(Steps) = %d, want %d", got, want)
+	}
+	if fake.replayPath != "/tmp/demo.gopoke-session.jsonl" || !fake.replayRerun {
+		t.Fatalf("ReplaySession forwarded path=%q rerun=%v", fake.replayPath, fake.replayRerun)
+	}
+}
+
+func TestWailsBridgeWorkerLogs(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeApplication{
+		workerLogsResp: []runner.LogLine{
+			{ProjectPath: "/tmp/project", Stream: runner.LogStreamStderr, Line: "boom"},
+		},
+	}
+	bridge := NewWailsBridge(fake, WithStrictEvents())
+	emitted := make([]runner.LogLine, 0)
+	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {
+		if eventName != workerLogEventName {
+			return
+		}
+		line, ok := payload.(runner.LogLine)
+		if !ok {
+			t.Fatalf("payload type = %T, want runner.LogLine", payload)
+		}
+		emitted = append(emitted, line)
+	}
+	bridge.Startup(context.Background())
+
+	lines, err := bridge.WorkerLogs("/tmp/project", 10)
+	if err != nil {
+		t.Fatalf("WorkerLogs() error = %v", err)
+	}
+	if got, want := len(lines), 1; got != want {
+		t.Fatalf("len(lines) = %d, want %d", got, want)
+	}
+
+	if fake.workerLogHandler == nil {
+		t.Fatal("worker log handler not installed at startup")
+	}
+	fake.workerLogHandler(runner.LogLine{ProjectPath: "/tmp/project", Line: "live"})
+	if got, want := len(emitted), 1; got != want {
+		t.Fatalf("len(emitted) = %d, want %d", got, want)
+	}
+	if got, want := emitted[0].Line, "live"; got != want {
+		t.Fatalf("emitted[0].Line = %q, want %q", got, want)
+	}
+}
+
+func TestWailsBridgeStreamsLSPAnalysisState(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeApplication{}
+	bridge := NewWailsBridge(fake, WithStrictEvents())
+	var emitted []lsp.AnalysisEvent
+	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {
+		if event, ok := payload.(lsp.AnalysisEvent); ok && eventName == lspAnalysisEventName {
+			emitted = append(emitted, event)
+		}
+	}
+	bridge.Startup(context.Background())
+
+	if fake.analysisHandler == nil {
+		t.Fatal("analysis handler not installed at startup")
+	}
+	fake.analysisHandler(lsp.AnalysisEvent{State: lsp.AnalysisAnalyzing})
+	if len(emitted) != 1 || emitted[0].State != lsp.AnalysisAnalyzing {
+		t.Fatalf("emitted = %+v, want one analyzing event", emitted)
+	}
+
+	if fake.memoryHandler == nil {
+		t.Fatal("memory handler not installed at startup")
+	}
+
+	state, err := bridge.LSPAnalysisState()
+	if err != nil {
+		t.Fatalf("LSPAnalysisState() error = %v", err)
+	}
+	if state.State != lsp.AnalysisClean {
+		t.Fatalf("LSPAnalysisState() = %+v, want clean", state)
+	}
+}
+
+func TestWailsBridgeStreamsRunNetworkEvents(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeApplication{}
+	bridge := NewWailsBridge(fake, WithStrictEvents())
+	var emitted []app.RunNetworkEvent
+	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {
+		if event, ok := payload.(app.RunNetworkEvent); ok && eventName == runNetworkEventName {
+			emitted = append(emitted, event)
+		}
+	}
+	bridge.Startup(context.Background())
+
+	if fake.networkHandler == nil {
+		t.Fatal("run network handler not installed at startup")
+	}
+	fake.networkHandler(app.RunNetworkEvent{RunID: "run-1"})
+	if len(emitted) != 1 || emitted[0].RunID != "run-1" {
+		t.Fatalf("emitted = %+v, want one event for run-1", emitted)
+	}
+	var prompted []app.RunNetworkPermissionRequest
+	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {
+		if request, ok := payload.(app.RunNetworkPermissionRequest); ok && eventName == runNetworkPermissionEventName {
+			prompted = append(prompted, request)
+		}
+	}
+	if fake.permissionHandler == nil {
+		t.Fatal("run network permission handler not installed at startup")
+	}
+	fake.permissionHandler(app.RunNetworkPermissionRequest{RunID: "run-1", Host: "203.0.113.5"})
+	if len(prompted) != 1 || prompted[0].Host != "203.0.113.5" {
+		t.Fatalf("prompted = %+v, want one request for 203.0.113.5", prompted)
+	}
+}
+
+func TestEventSchemasMatchCommittedContract(t *testing.T) {
+	t.Parallel()
+
+	got, err := ExportEventSchemas()
+	if err != nil {
+		t.Fatalf("ExportEventSchemas() error = %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join("testdata", "event_schemas.json"))
+	if err != nil {
+		t.Fatalf("read committed schemas: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatal("event payloads changed; update the frontend, then regenerate testdata/event_schemas.json with: go run ./cmd/gopoke event-schemas")
+	}
+}
+
+func TestWailsBridgeEventNamespace(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeApplication{}
+	bridge := NewWailsBridge(fake, WithEventNamespace("acme"), WithStrictEvents())
+	var names []string
+	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {
+		names = append(names, eventName)
+	}
+	bridge.Startup(context.Background())
+
+	fake.analysisHandler(lsp.AnalysisEvent{State: lsp.AnalysisClean})
+	if len(names) != 1 || names[0] != "acme:lsp:analysis" {
+		t.Fatalf("emitted names = %v, want acme:lsp:analysis", names)
+	}
+	if _, ok := bridge.EventSchemas().Events["acme:run:stdout-chunk"]; !ok {
+		t.Fatal("EventSchemas() lacks the namespaced stdout event")
+	}
+	manifest, err := bridge.Capabilities()
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+	if !slices.Contains(manifest.Events, "acme:capabilities") || !slices.Contains(manifest.Events, toolchainProgressEventName) {
+		t.Fatalf("manifest events = %v, want namespaced bridge events and global toolchain events", manifest.Events)
+	}
+}
+
+func TestWailsBridgeStrictEventsRejectsUnregisteredEvents(t *testing.T) {
+	t.Parallel()
+
+	bridge := NewWailsBridge(&fakeApplication{}, WithStrictEvents())
+	bridge.emitEvent = func(ctx context.Context, eventName string, payload interface{}) {}
+	defer func() {
+		if recovered := recover(); recovered == nil {
+			t.Fatal("emit of an unregistered event did not panic in strict mode")
+		}
+	}()
+	bridge.emit(context.Background(), "gopoke:unknown", struct{}{})
+}
+
 func TestWailsBridgeLSPWebSocketPort(t *testing.T) {
 	t.Parallel()
 
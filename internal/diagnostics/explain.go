@@ -0,0 +1,105 @@
+package diagnostics
+
+import (
+	"regexp"
+
+	"gopoke/internal/i18n"
+)
+
+// Explanation is a curated description of a compiler error and how to fix
+// it, shown as an expandable learning aid next to the diagnostic.
+type Explanation struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix"`
+}
+
+// explainer maps one compiler message pattern to catalog entries
+// "explain.<id>.title", ".detail" and ".fix". Non-empty submatches are passed
+// in order as arguments to the detail message.
+type explainer struct {
+	id      string
+	pattern *regexp.Regexp
+}
+
+var explainers = []explainer{
+	{id: "unusedVariable", pattern: regexp.MustCompile(`^(?:declared and not used: (\S+)|(\S+) declared (?:and|but) not used)$`)},
+	{id: "unusedImport", pattern: regexp.MustCompile(`^"([^"]+)" imported (?:as \S+ )?and not used$`)},
+	// Interface failures are usually reported inside a "cannot use" message,
+	// so they are matched before the generic type mismatch.
+	{id: "missingMethod", pattern: regexp.MustCompile(`does not implement (\S+) \(missing method (\w+)\)$`)},
+	{id: "pointerReceiver", pattern: regexp.MustCompile(`does not implement (\S+) \(method (\w+) has pointer receiver\)$`)},
+	{id: "typeMismatch", pattern: regexp.MustCompile(`^cannot use (.+?)(?: \(.+\))? as (.+?) value in (.+)$`)},
+	{id: "undefinedName", pattern: regexp.MustCompile(`^undefined: (\S+)$`)},
+	{id: "noFieldOrMethod", pattern: regexp.MustCompile(`^\S+ undefined \(type (.+?) has no field or method (\w+)(?:, but does have .+)?\)$`)},
+	{id: "missingReturn", pattern: regexp.MustCompile(`^missing return$`)},
+	{id: "redeclared", pattern: regexp.MustCompile(`^(\S+) redeclared in this block$`)},
+	{id: "noNewVariables", pattern: regexp.MustCompile(`^no new variables on left side of :=$`)},
+	{id: "assignmentMismatch", pattern: regexp.MustCompile(`^assignment mismatch: (.+)$`)},
+	{id: "tooManyArguments", pattern: regexp.MustCompile(`^too many arguments in call to (.+)$`)},
+	{id: "notEnoughArguments", pattern: regexp.MustCompile(`^not enough arguments in call to (.+)$`)},
+	{id: "mismatchedTypes", pattern: regexp.MustCompile(`^invalid operation: .+ \(mismatched types (.+?) and (.+?)\)$`)},
+	{id: "callNonFunction", pattern: regexp.MustCompile(`^invalid operation: cannot call non-function (\S+)`)},
+	{id: "unusedValue", pattern: regexp.MustCompile(`^(.+?) \(.+\) is not used$`)},
+	{id: "syntaxError", pattern: regexp.MustCompile(`^syntax error: (.+)$`)},
+	{id: "nonBooleanCondition", pattern: regexp.MustCompile(`^non-boolean condition in (if|for) statement$`)},
+	{id: "importCycle", pattern: regexp.MustCompile(`^import cycle not allowed`)},
+	{id: "cannotAssign", pattern: regexp.MustCompile(`^cannot assign to (.+?)(?: \(.+\))?$`)},
+	{id: "breakOutsideLoop", pattern: regexp.MustCompile(`^break is not in a loop, switch, or select$`)},
+	{id: "notAType", pattern: regexp.MustCompile(`^(\S+) is not a type$`)},
+	{id: "nonNameDefine", pattern: regexp.MustCompile(`^non-name (.+) on left side of :=$`)},
+	{id: "indexOutOfRange", pattern: regexp.MustCompile(`^invalid argument: index (\S+) out of bounds \[0:(\d+)\]$`)},
+	{id: "cannotRange", pattern: regexp.MustCompile(`^cannot range over (.+)$`)},
+}
+
+// Classify returns the explainer ID matching a compiler message and its
+// non-empty submatches (such as the variable or import path), or "" when no
+// pattern matches.
+func Classify(message string) (string, []string) {
+	for _, candidate := range explainers {
+		matches := candidate.pattern.FindStringSubmatch(message)
+		if matches == nil {
+			continue
+		}
+		args := make([]string, 0, len(matches)-1)
+		for _, match := range matches[1:] {
+			if match != "" {
+				args = append(args, match)
+			}
+		}
+		return candidate.id, args
+	}
+	return "", nil
+}
+
+// Explain returns the curated explanation for a compiler message, or nil when
+// no pattern matches.
+func Explain(message string, localizer *i18n.Localizer) *Explanation {
+	id, matches := Classify(message)
+	if id == "" {
+		return nil
+	}
+	args := make([]any, 0, len(matches))
+	for _, match := range matches {
+		args = append(args, match)
+	}
+	key := "explain." + id
+	return &Explanation{
+		ID:     id,
+		Title:  localizer.T(key + ".title"),
+		Detail: localizer.T(key+".detail", args...),
+		Fix:    localizer.T(key + ".fix"),
+	}
+}
+
+// AttachExplanations sets Explanation on compile diagnostics with a known
+// message pattern.
+func AttachExplanations(items []Diagnostic, localizer *i18n.Localizer) []Diagnostic {
+	for i := range items {
+		if items[i].Kind == KindCompile {
+			items[i].Explanation = Explain(items[i].Message, localizer)
+		}
+	}
+	return items
+}
@@ -0,0 +1,100 @@
+package diagnostics
+
+import (
+	"strings"
+	"testing"
+
+	"gopoke/internal/i18n"
+)
+
+func TestExplainMatchesCompilerMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		message string
+		wantID  string
+		detail  string
+	}{
+		{message: "declared and not used: count", wantID: "unusedVariable", detail: "count"},
+		{message: "count declared but not used", wantID: "unusedVariable", detail: "count"},
+		{message: `"strings" imported and not used`, wantID: "unusedImport", detail: "strings"},
+		{message: `"math/rand" imported as mrand and not used`, wantID: "unusedImport", detail: "math/rand"},
+		{message: `cannot use "abc" (untyped string constant) as int value in argument to sum`, wantID: "typeMismatch", detail: `"abc" cannot be used as int in argument to sum`},
+		{message: "undefined: missingValue", wantID: "undefinedName", detail: "missingValue"},
+		{message: "u.Nmae undefined (type User has no field or method Nmae, but does have field Name)", wantID: "noFieldOrMethod", detail: "User has no field or method named Nmae"},
+		{message: "missing return", wantID: "missingReturn"},
+		{message: "x redeclared in this block", wantID: "redeclared", detail: "x"},
+		{message: "no new variables on left side of :=", wantID: "noNewVariables"},
+		{message: "assignment mismatch: 1 variable but strconv.Atoi returns 2 values", wantID: "assignmentMismatch", detail: "strconv.Atoi returns 2 values"},
+		{message: "too many arguments in call to handler", wantID: "tooManyArguments", detail: "handler"},
+		{message: "not enough arguments in call to handler", wantID: "notEnoughArguments", detail: "handler"},
+		{message: "invalid operation: a + b (mismatched types int and float64)", wantID: "mismatchedTypes", detail: "int and a float64"},
+		{message: "invalid operation: cannot call non-function total (variable of type int)", wantID: "callNonFunction", detail: "total"},
+		{message: "Circle does not implement Shape (missing method Area)", wantID: "missingMethod", detail: "Shape because it lacks the method Area"},
+		{message: "cannot use c (variable of type Counter) as Incrementer value in assignment: Counter does not implement Incrementer (method Inc has pointer receiver)", wantID: "pointerReceiver", detail: "Incrementer"},
+		{message: "Counter does not implement Incrementer (method Inc has pointer receiver)", wantID: "pointerReceiver", detail: "Inc is declared on the pointer type"},
+		{message: "x + 1 (value of type int) is not used", wantID: "unusedValue", detail: "x + 1"},
+		{message: "syntax error: unexpected newline, expected comma or )", wantID: "syntaxError", detail: "unexpected newline"},
+		{message: "non-boolean condition in if statement", wantID: "nonBooleanCondition", detail: "if"},
+		{message: "import cycle not allowed", wantID: "importCycle"},
+		{message: "cannot assign to s[0] (neither addressable nor a map index expression)", wantID: "cannotAssign", detail: "s[0]"},
+		{message: "break is not in a loop, switch, or select", wantID: "breakOutsideLoop"},
+		{message: "count is not a type", wantID: "notAType", detail: "count"},
+		{message: "non-name p.x on left side of :=", wantID: "nonNameDefine", detail: "p.x"},
+		{message: "invalid argument: index 5 out of bounds [0:3]", wantID: "indexOutOfRange", detail: "Index 5 is out of bounds for a length of 3"},
+		{message: "cannot range over p (variable of type *Point)", wantID: "cannotRange", detail: "p (variable of type *Point)"},
+	}
+	english := i18n.New("en")
+	for _, tt := range tests {
+		explanation := Explain(tt.message, english)
+		if explanation == nil {
+			t.Errorf("Explain(%q) = nil, want %s", tt.message, tt.wantID)
+			continue
+		}
+		if explanation.ID != tt.wantID {
+			t.Errorf("Explain(%q).ID = %q, want %q", tt.message, explanation.ID, tt.wantID)
+		}
+		if !strings.Contains(explanation.Detail, tt.detail) {
+			t.Errorf("Explain(%q).Detail = %q, want it to contain %q", tt.message, explanation.Detail, tt.detail)
+		}
+		if strings.Contains(explanation.Detail, "%!") || explanation.Fix == "" || explanation.Title == "" {
+			t.Errorf("Explain(%q) = %+v, want fully formatted text", tt.message, explanation)
+		}
+	}
+	if got := Explain("some brand new compiler complaint", english); got != nil {
+		t.Fatalf("Explain(unknown) = %+v, want nil", got)
+	}
+}
+
+func TestExplainersHaveCatalogEntries(t *testing.T) {
+	t.Parallel()
+
+	english := i18n.New("en")
+	for _, candidate := range explainers {
+		for _, suffix := range []string{".title", ".detail", ".fix"} {
+			key := "explain." + candidate.id + suffix
+			if got := english.T(key); got == key {
+				t.Errorf("catalog is missing %q", key)
+			}
+		}
+	}
+}
+
+func TestAttachExplanationsLocalizesCompileDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	items := AttachExplanations([]Diagnostic{
+		{Kind: KindCompile, Message: "missing return"},
+		{Kind: KindPanic, Message: "missing return"},
+	}, i18n.New("es"))
+
+	if items[0].Explanation == nil {
+		t.Fatal("items[0].Explanation = nil, want explanation")
+	}
+	if got, want := items[0].Explanation.Title, "Falta return"; got != want {
+		t.Fatalf("items[0].Explanation.Title = %q, want %q", got, want)
+	}
+	if items[1].Explanation != nil {
+		t.Fatalf("items[1].Explanation = %+v, want nil for panic frames", items[1].Explanation)
+	}
+}
@@ -0,0 +1,133 @@
+package diagnostics
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Severity levels use LSP DiagnosticSeverity numbering; lower is more severe.
+const (
+	SeverityError       = 1
+	SeverityWarning     = 2
+	SeverityInformation = 3
+	SeverityHint        = 4
+)
+
+// Sources that feed the unified diagnostics list.
+const (
+	SourceGopls    = "gopls"
+	SourceCompiler = "compiler"
+	SourceRuntime  = "runtime"
+	// SourceSyntax reports parse errors from the syntax-only language server
+	// used when gopls is missing.
+	SourceSyntax = "syntax"
+)
+
+// messageSimilarityThreshold is the minimum word overlap (Jaccard index) for
+// two messages on the same line to count as the same issue.
+const messageSimilarityThreshold = 0.6
+
+// Entry is one diagnostic in a document's unified feed. Merged entries list
+// every source that reported them.
+type Entry struct {
+	File        string       `json:"file"`
+	Line        int          `json:"line"`
+	Column      int          `json:"column"`
+	Severity    int          `json:"severity"`
+	Kind        string       `json:"kind,omitempty"`
+	Code        string       `json:"code,omitempty"`
+	Message     string       `json:"message"`
+	Sources     []string     `json:"sources"`
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// SeverityForKind maps a run diagnostic kind onto a severity. Compile errors
+// and panics both stop the program, so both rank as errors.
+func SeverityForKind(kind string) int {
+	switch kind {
+	case KindCompile, KindPanic:
+		return SeverityError
+	default:
+		return SeverityWarning
+	}
+}
+
+// Merge folds entries that share a file and line and describe the same issue,
+// keeping the most severe rating, then orders the feed by severity and position.
+func Merge(entries []Entry) []Entry {
+	merged := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Severity <= 0 {
+			entry.Severity = SeverityError
+		}
+		entry.Sources = slices.Clone(entry.Sources)
+		index := slices.IndexFunc(merged, func(existing Entry) bool {
+			return existing.File == entry.File && existing.Line == entry.Line && similarMessages(existing.Message, entry.Message)
+		})
+		if index < 0 {
+			merged = append(merged, entry)
+			continue
+		}
+		merged[index] = mergeEntry(merged[index], entry)
+	}
+
+	slices.SortStableFunc(merged, func(a, b Entry) int {
+		return cmp.Or(
+			cmp.Compare(a.Severity, b.Severity),
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+		)
+	})
+	return merged
+}
+
+func mergeEntry(existing Entry, duplicate Entry) Entry {
+	existing.Severity = min(existing.Severity, duplicate.Severity)
+	if existing.Column <= 0 {
+		existing.Column = duplicate.Column
+	}
+	if existing.Kind == "" {
+		existing.Kind = duplicate.Kind
+	}
+	if existing.Code == "" {
+		existing.Code = duplicate.Code
+	}
+	if existing.Explanation == nil {
+		existing.Explanation = duplicate.Explanation
+	}
+	for _, source := range duplicate.Sources {
+		if !slices.Contains(existing.Sources, source) {
+			existing.Sources = append(existing.Sources, source)
+		}
+	}
+	return existing
+}
+
+// similarMessages compares messages after normalizing case, quoting and
+// spacing, accepting containment or a high word overlap.
+func similarMessages(a string, b string) bool {
+	a, b = normalizeMessage(a), normalizeMessage(b)
+	if a == b || (a != "" && b != "" && (strings.Contains(a, b) || strings.Contains(b, a))) {
+		return true
+	}
+	wordsA, wordsB := strings.Fields(a), strings.Fields(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return false
+	}
+	shared := 0
+	for _, word := range wordsA {
+		if slices.Contains(wordsB, word) {
+			shared++
+		}
+	}
+	union := len(wordsA) + len(wordsB) - shared
+	return float64(shared)/float64(union) >= messageSimilarityThreshold
+}
+
+func normalizeMessage(message string) string {
+	message = strings.ToLower(message)
+	message = strings.NewReplacer("\"", "", "`", "", "'", "").Replace(message)
+	return strings.Join(strings.Fields(message), " ")
+}
@@ -0,0 +1,59 @@
+package diagnostics
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeDeduplicatesAcrossSources(t *testing.T) {
+	t.Parallel()
+
+	explanation := &Explanation{ID: "unusedVariable"}
+	merged := Merge([]Entry{
+		{File: "main.go", Line: 9, Column: 2, Severity: SeverityHint, Message: "unusedfunc: function helper is unused", Sources: []string{SourceGopls}},
+		{File: "main.go", Line: 4, Column: 2, Severity: SeverityWarning, Message: "declared and not used: x", Sources: []string{SourceGopls}},
+		{File: "main.go", Line: 4, Column: 2, Severity: SeverityError, Kind: KindCompile, Message: "declared and not used: x", Sources: []string{SourceCompiler}, Explanation: explanation},
+		{File: "main.go", Line: 6, Severity: SeverityError, Message: `cannot use "a" (untyped string constant) as int value in assignment`, Sources: []string{SourceCompiler}},
+		{File: "main.go", Line: 6, Column: 6, Severity: SeverityError, Message: "cannot use `a` (untyped string constant) as int value in assignment", Sources: []string{SourceGopls}},
+		{File: "main.go", Line: 6, Column: 10, Severity: SeverityError, Message: "undefined: y", Sources: []string{SourceGopls}},
+	})
+
+	if got, want := len(merged), 4; got != want {
+		t.Fatalf("len(Merge()) = %d, want %d: %+v", got, want, merged)
+	}
+	first := merged[0]
+	if first.Line != 4 || first.Severity != SeverityError || first.Explanation != explanation {
+		t.Fatalf("merged[0] = %+v, want line 4 error with explanation", first)
+	}
+	if want := []string{SourceGopls, SourceCompiler}; !slices.Equal(first.Sources, want) {
+		t.Fatalf("merged[0].Sources = %v, want %v", first.Sources, want)
+	}
+	if got, want := merged[1].Column, 6; got != want {
+		t.Fatalf("merged[1].Column = %d, want %d", got, want)
+	}
+	if got, want := merged[2].Message, "undefined: y"; got != want {
+		t.Fatalf("merged[2].Message = %q, want %q", got, want)
+	}
+	if got, want := merged[3].Severity, SeverityHint; got != want {
+		t.Fatalf("merged[3].Severity = %d, want %d", got, want)
+	}
+}
+
+func TestSimilarMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "undefined: x", b: "Undefined:  x", want: true},
+		{a: "missing return", b: "missing return at end of function", want: true},
+		{a: "undefined: x", b: "undefined: y", want: false},
+		{a: "too many arguments in call to f", b: "not enough arguments in call to f", want: false},
+	}
+	for _, tt := range tests {
+		if got := similarMessages(tt.a, tt.b); got != tt.want {
+			t.Errorf("similarMessages(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
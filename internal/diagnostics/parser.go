@@ -12,6 +12,10 @@ const (
 	KindCompile = "compile"
 	// KindPanic indicates a runtime panic diagnostic.
 	KindPanic = "panic"
+	// KindVet indicates a go vet finding.
+	KindVet = "vet"
+	// DefaultPanicMessage is used for panic frames without a panic: line.
+	DefaultPanicMessage = "runtime panic"
 )
 
 var (
@@ -27,6 +31,8 @@ type Diagnostic struct {
 	Column  int
 	Message string
 	Raw     string
+	// Explanation is set by AttachExplanations for recognized compile errors.
+	Explanation *Explanation
 }
 
 // ParseCompileErrors extracts compile diagnostics from stderr output.
@@ -59,6 +65,16 @@ func ParseCompileErrors(stderr string) []Diagnostic {
 	return diagnostics
 }
 
+// ParseVetFindings extracts go vet findings from its stderr output. Vet
+// reports in the compiler's file:line:column format.
+func ParseVetFindings(stderr string) []Diagnostic {
+	findings := ParseCompileErrors(stderr)
+	for i := range findings {
+		findings[i].Kind = KindVet
+	}
+	return findings
+}
+
 // ParseRuntimePanics extracts runtime panic stack frame diagnostics.
 func ParseRuntimePanics(stderr string) []Diagnostic {
 	diagnostics := make([]Diagnostic, 0)
@@ -86,7 +102,7 @@ func ParseRuntimePanics(stderr string) []Diagnostic {
 				columnNumber = parsedColumn
 			}
 		}
-		message := "runtime panic"
+		message := DefaultPanicMessage
 		if pendingMessage != "" {
 			message = pendingMessage
 		}
@@ -0,0 +1,51 @@
+package diagnostics
+
+import (
+	"strings"
+
+	"gopoke/internal/i18n"
+)
+
+// Summary returns a short localized description such as
+// "2 compile errors, 1 panic frame".
+func Summary(items []Diagnostic, localizer *i18n.Localizer) string {
+	compileCount := 0
+	panicCount := 0
+	vetCount := 0
+	for _, item := range items {
+		switch item.Kind {
+		case KindCompile:
+			compileCount++
+		case KindPanic:
+			panicCount++
+		case KindVet:
+			vetCount++
+		}
+	}
+
+	parts := make([]string, 0, 3)
+	if compileCount > 0 {
+		parts = append(parts, localizer.Plural(i18n.MsgDiagnosticsCompile, compileCount))
+	}
+	if panicCount > 0 {
+		parts = append(parts, localizer.Plural(i18n.MsgDiagnosticsPanicCount, panicCount))
+	}
+	if vetCount > 0 {
+		parts = append(parts, localizer.Plural(i18n.MsgDiagnosticsVet, vetCount))
+	}
+	if len(parts) == 0 {
+		return localizer.T(i18n.MsgDiagnosticsNone)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Localize replaces parser-generated default messages with localized text.
+// Compiler and panic messages copied from Go output are left untouched.
+func Localize(items []Diagnostic, localizer *i18n.Localizer) []Diagnostic {
+	for i := range items {
+		if items[i].Kind == KindPanic && items[i].Message == DefaultPanicMessage {
+			items[i].Message = localizer.T(i18n.MsgDiagnosticsPanic)
+		}
+	}
+	return items
+}
@@ -0,0 +1,43 @@
+package diagnostics
+
+import (
+	"testing"
+
+	"gopoke/internal/i18n"
+)
+
+func TestSummaryCountsDiagnosticsByKind(t *testing.T) {
+	t.Parallel()
+
+	items := []Diagnostic{
+		{Kind: KindCompile, Message: "undefined: x"},
+		{Kind: KindCompile, Message: "missing return"},
+		{Kind: KindPanic, Message: DefaultPanicMessage},
+	}
+
+	if got, want := Summary(items, i18n.New("en")), "2 compile errors, 1 panic frame"; got != want {
+		t.Fatalf("Summary(en) = %q, want %q", got, want)
+	}
+	if got, want := Summary(items, i18n.New("es")), "2 errores de compilación, 1 marco de pánico"; got != want {
+		t.Fatalf("Summary(es) = %q, want %q", got, want)
+	}
+	if got, want := Summary(nil, i18n.New("en")), "no problems found"; got != want {
+		t.Fatalf("Summary(nil) = %q, want %q", got, want)
+	}
+}
+
+func TestLocalizeReplacesDefaultPanicMessage(t *testing.T) {
+	t.Parallel()
+
+	items := Localize([]Diagnostic{
+		{Kind: KindPanic, Message: DefaultPanicMessage},
+		{Kind: KindPanic, Message: "index out of range"},
+	}, i18n.New("de"))
+
+	if got, want := items[0].Message, "Laufzeit-Panic"; got != want {
+		t.Fatalf("items[0].Message = %q, want %q", got, want)
+	}
+	if got, want := items[1].Message, "index out of range"; got != want {
+		t.Fatalf("items[1].Message = %q, want %q", got, want)
+	}
+}
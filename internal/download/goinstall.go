@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
@@ -20,6 +21,68 @@ func InstallStaticcheckBinary(ctx context.Context, goPath string, targetBinDir s
 	return goInstallTool(ctx, goPath, targetBinDir, "staticcheck", "honnef.co/go/tools/cmd/staticcheck@latest", onProgress)
 }
 
+// InstallDelveBinary installs the dlv debugger using go install.
+func InstallDelveBinary(ctx context.Context, goPath string, targetBinDir string, onProgress OnProgress) error {
+	return goInstallTool(ctx, goPath, targetBinDir, "dlv", "github.com/go-delve/delve/cmd/dlv@latest", onProgress)
+}
+
+// InstallGolangciLintBinary installs golangci-lint using go install.
+func InstallGolangciLintBinary(ctx context.Context, goPath string, targetBinDir string, onProgress OnProgress) error {
+	return goInstallTool(ctx, goPath, targetBinDir, "golangci-lint", "github.com/golangci/golangci-lint/v2/cmd/golangci-lint@latest", onProgress)
+}
+
+// InstallGotipBinary installs the gotip wrapper and runs "gotip download",
+// which builds the development toolchain under $HOME/sdk/gotip.
+func InstallGotipBinary(ctx context.Context, goPath string, targetBinDir string, onProgress OnProgress) error {
+	if err := goInstallTool(ctx, goPath, targetBinDir, "gotip", "golang.org/dl/gotip@latest", onProgress); err != nil {
+		return err
+	}
+	gotipBin := filepath.Join(targetBinDir, "gotip")
+	if runtime.GOOS == "windows" {
+		gotipBin += ".exe"
+	}
+
+	if onProgress != nil {
+		onProgress(Progress{
+			Tool:    "gotip",
+			Stage:   StageBuilding,
+			Message: "Downloading and building Go tip...",
+		})
+	}
+	cmd := exec.CommandContext(ctx, gotipBin, "download")
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("stdout pipe: %w", err)
+	}
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start gotip download: %w", err)
+	}
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		if onProgress != nil {
+			onProgress(Progress{
+				Tool:    "gotip",
+				Stage:   StageBuilding,
+				Message: scanner.Text(),
+			})
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("gotip download: %w", err)
+	}
+
+	if onProgress != nil {
+		onProgress(Progress{
+			Tool:    "gotip",
+			Stage:   StageComplete,
+			Percent: 100,
+			Message: "Go tip installed successfully",
+		})
+	}
+	return nil
+}
+
 func goInstallTool(ctx context.Context, goPath string, targetBinDir string, toolName string, pkg string, onProgress OnProgress) error {
 	goBin := goPath
 	if goBin == "" {
@@ -33,7 +96,7 @@ func goInstallTool(ctx context.Context, goPath string, targetBinDir string, tool
 	if onProgress != nil {
 		onProgress(Progress{
 			Tool:    toolName,
-			Stage:   "installing",
+			Stage:   StageInstalling,
 			Message: fmt.Sprintf("Installing %s...", toolName),
 		})
 	}
@@ -63,7 +126,7 @@ func goInstallTool(ctx context.Context, goPath string, targetBinDir string, tool
 		if onProgress != nil {
 			onProgress(Progress{
 				Tool:    toolName,
-				Stage:   "installing",
+				Stage:   StageInstalling,
 				Message: line,
 			})
 		}
@@ -76,7 +139,7 @@ func goInstallTool(ctx context.Context, goPath string, targetBinDir string, tool
 	if onProgress != nil {
 		onProgress(Progress{
 			Tool:    toolName,
-			Stage:   "complete",
+			Stage:   StageComplete,
 			Percent: 100,
 			Message: fmt.Sprintf("%s installed successfully", toolName),
 		})
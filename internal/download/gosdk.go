@@ -4,6 +4,8 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 )
 
@@ -37,7 +40,7 @@ type goFile struct {
 
 // ListGoVersions fetches available Go SDK versions from go.dev.
 func ListGoVersions(ctx context.Context) ([]GoVersion, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://go.dev/dl/?mode=json", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, goDownloadURL+"?mode=json", nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -67,7 +70,29 @@ func ListGoVersions(ctx context.Context) ([]GoVersion, error) {
 	return versions, nil
 }
 
-// DownloadGoSDK downloads and extracts a Go SDK to targetDir.
+// goDownloadURL serves Go SDK archives and their SHA-256 checksums.
+var goDownloadURL = "https://go.dev/dl/"
+
+// sdkStages are the stages of a Go SDK download, in order.
+var sdkStages = []string{StageDownloading, StageVerifying, StageExtracting, StageRegistering}
+
+// reportSDK sends p as progress of the Go SDK download, numbering its stage.
+func reportSDK(onProgress OnProgress, p Progress) {
+	if onProgress == nil {
+		return
+	}
+	p.Tool = "go"
+	if index := slices.Index(sdkStages, p.Stage); index >= 0 {
+		p.Step = index + 1
+		p.Steps = len(sdkStages)
+	}
+	onProgress(p)
+}
+
+// DownloadGoSDK downloads a Go SDK, verifies it against its published
+// checksum and installs it as targetDir/go. The archive is extracted next to
+// the installed SDK and swapped in once complete, so a canceled or failed
+// download leaves the previous SDK in place.
 func DownloadGoSDK(ctx context.Context, version string, targetDir string, onProgress OnProgress) error {
 	goos := runtime.GOOS
 	goarch := runtime.GOARCH
@@ -75,16 +100,21 @@ func DownloadGoSDK(ctx context.Context, version string, targetDir string, onProg
 	if goos == "windows" {
 		ext = "zip"
 	}
+	if ext != "tar.gz" {
+		return fmt.Errorf("zip extraction not yet implemented")
+	}
 
 	filename := fmt.Sprintf("%s.%s-%s.%s", version, goos, goarch, ext)
-	url := fmt.Sprintf("https://go.dev/dl/%s", filename)
+	url := goDownloadURL + filename
 
-	if onProgress != nil {
-		onProgress(Progress{
-			Tool:    "go",
-			Stage:   "downloading",
-			Message: fmt.Sprintf("Downloading %s...", filename),
-		})
+	reportSDK(onProgress, Progress{
+		Stage:   StageDownloading,
+		Message: fmt.Sprintf("Downloading %s...", filename),
+	})
+
+	checksum, err := fetchChecksum(ctx, url+".sha256")
+	if err != nil {
+		return err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -112,7 +142,8 @@ func DownloadGoSDK(ctx context.Context, version string, targetDir string, onProg
 	tmpPath := tmpFile.Name()
 	defer os.Remove(tmpPath)
 
-	// Download with progress
+	// Download with progress, hashing as we go
+	hash := sha256.New()
 	var received int64
 	buf := make([]byte, 32*1024)
 	for {
@@ -126,17 +157,15 @@ func DownloadGoSDK(ctx context.Context, version string, targetDir string, onProg
 				tmpFile.Close()
 				return fmt.Errorf("write temp file: %w", writeErr)
 			}
+			hash.Write(buf[:n])
 			received += int64(n)
-			if onProgress != nil {
-				onProgress(Progress{
-					Tool:          "go",
-					Stage:         "downloading",
-					BytesReceived: received,
-					BytesTotal:    totalBytes,
-					Percent:       calcPercent(received, totalBytes),
-					Message:       fmt.Sprintf("Downloading %s...", filename),
-				})
-			}
+			reportSDK(onProgress, Progress{
+				Stage:         StageDownloading,
+				BytesReceived: received,
+				BytesTotal:    totalBytes,
+				Percent:       calcPercent(received, totalBytes),
+				Message:       fmt.Sprintf("Downloading %s...", filename),
+			})
 		}
 		if readErr == io.EOF {
 			break
@@ -148,42 +177,137 @@ func DownloadGoSDK(ctx context.Context, version string, targetDir string, onProg
 	}
 	tmpFile.Close()
 
-	if onProgress != nil {
-		onProgress(Progress{
-			Tool:    "go",
-			Stage:   "extracting",
-			Percent: 100,
-			Message: "Extracting Go SDK...",
-		})
+	reportSDK(onProgress, Progress{
+		Stage:   StageVerifying,
+		Message: "Verifying Go SDK checksum...",
+	})
+	if got := hex.EncodeToString(hash.Sum(nil)); got != checksum {
+		return fmt.Errorf("go sdk checksum mismatch: got %s, want %s", got, checksum)
 	}
-
-	// Remove existing go dir if present
-	goDir := filepath.Join(targetDir, "go")
-	os.RemoveAll(goDir)
+	reportSDK(onProgress, Progress{
+		Stage:   StageVerifying,
+		Percent: 100,
+		Message: "Verified Go SDK checksum",
+	})
 
 	if err := os.MkdirAll(targetDir, 0o755); err != nil {
 		return fmt.Errorf("create target dir: %w", err)
 	}
+	stagingDir, err := os.MkdirTemp(targetDir, ".go-extract-*")
+	if err != nil {
+		return fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
 
-	if ext == "tar.gz" {
-		if err := extractTarGz(tmpPath, targetDir); err != nil {
-			return fmt.Errorf("extract tar.gz: %w", err)
+	reportSDK(onProgress, Progress{
+		Stage:   StageExtracting,
+		Message: "Extracting Go SDK...",
+	})
+	lastPercent := -1
+	err = extractTarGz(ctx, tmpPath, stagingDir, func(name string, consumed int64, total int64) {
+		// One update per whole percent keeps an SDK's thousands of files
+		// from flooding the frontend.
+		percent := calcPercent(consumed, total)
+		if int(percent) == lastPercent {
+			return
 		}
-	} else {
-		return fmt.Errorf("zip extraction not yet implemented")
+		lastPercent = int(percent)
+		reportSDK(onProgress, Progress{
+			Stage:         StageExtracting,
+			BytesReceived: consumed,
+			BytesTotal:    total,
+			Percent:       percent,
+			File:          name,
+			Message:       "Extracting Go SDK...",
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("extract tar.gz: %w", err)
+	}
+
+	reportSDK(onProgress, Progress{
+		Stage:   StageRegistering,
+		Message: "Registering Go SDK...",
+	})
+	bin := "go"
+	if goos == "windows" {
+		bin = "go.exe"
+	}
+	if _, err := os.Stat(filepath.Join(stagingDir, "go", "bin", bin)); err != nil {
+		return fmt.Errorf("go sdk archive has no go binary: %w", err)
+	}
+	goDir := filepath.Join(targetDir, "go")
+	if err := os.RemoveAll(goDir); err != nil {
+		return fmt.Errorf("remove previous go sdk: %w", err)
+	}
+	if err := os.Rename(filepath.Join(stagingDir, "go"), goDir); err != nil {
+		return fmt.Errorf("install go sdk: %w", err)
 	}
 
+	reportSDK(onProgress, Progress{
+		Stage:   StageComplete,
+		Percent: 100,
+		Message: fmt.Sprintf("Go SDK %s installed successfully", version),
+	})
 	return nil
 }
 
-func extractTarGz(archivePath string, destDir string) error {
+// fetchChecksum returns the hex SHA-256 published for a download.
+func fetchChecksum(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("create checksum request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch go sdk checksum: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("checksum download returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", fmt.Errorf("read go sdk checksum: %w", err)
+	}
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("go sdk checksum is empty")
+	}
+	checksum := strings.ToLower(fields[0])
+	if decoded, err := hex.DecodeString(checksum); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid go sdk checksum %q", fields[0])
+	}
+	return checksum, nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// extractTarGz extracts archivePath into destDir, calling onEntry after each
+// entry with how much of the compressed archive has been read.
+func extractTarGz(ctx context.Context, archivePath string, destDir string, onEntry func(name string, consumed int64, total int64)) error {
 	f, err := os.Open(archivePath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	counter := &countingReader{reader: f}
 
-	gz, err := gzip.NewReader(f)
+	gz, err := gzip.NewReader(counter)
 	if err != nil {
 		return err
 	}
@@ -191,6 +315,9 @@ func extractTarGz(archivePath string, destDir string) error {
 
 	tr := tar.NewReader(gz)
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		header, err := tr.Next()
 		if err == io.EOF {
 			break
@@ -226,6 +353,9 @@ func extractTarGz(archivePath string, destDir string) error {
 			}
 			out.Close()
 		}
+		if onEntry != nil {
+			onEntry(header.Name, counter.count, info.Size())
+		}
 	}
 	return nil
 }
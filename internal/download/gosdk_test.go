@@ -0,0 +1,174 @@
+package download
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func testSDKArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		content := files[name]
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("write header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("write content: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("close tar: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// serveSDK serves archive as go1.99.0 for this platform under goDownloadURL
+// with the given checksum.
+func serveSDK(t *testing.T, archive []byte, checksum string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("zip extraction not yet implemented")
+	}
+	name := "/go1.99.0." + runtime.GOOS + "-" + runtime.GOARCH + ".tar.gz"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case name:
+			w.Write(archive)
+		case name + ".sha256":
+			w.Write([]byte(checksum + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	previous := goDownloadURL
+	goDownloadURL = server.URL + "/"
+	t.Cleanup(func() { goDownloadURL = previous })
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// These tests replace goDownloadURL, so they do not run in parallel.
+
+func TestDownloadGoSDKReportsStages(t *testing.T) {
+	archive := testSDKArchive(t, map[string]string{
+		"go/VERSION":    "go1.99.0\n",
+		"go/bin/go":     "#!/bin/sh\n",
+		"go/src/go.mod": "module std\n",
+	})
+	serveSDK(t, archive, sha256Hex(archive))
+
+	targetDir := t.TempDir()
+	var stages []string
+	var files []string
+	err := DownloadGoSDK(context.Background(), "go1.99.0", targetDir, func(p Progress) {
+		if len(stages) == 0 || stages[len(stages)-1] != p.Stage {
+			stages = append(stages, p.Stage)
+		}
+		if p.File != "" {
+			files = append(files, p.File)
+		}
+		if p.Stage != StageComplete && (p.Steps != 4 || p.Step != slices.Index(sdkStages, p.Stage)+1) {
+			t.Errorf("progress %+v has step %d of %d", p.Stage, p.Step, p.Steps)
+		}
+	})
+	if err != nil {
+		t.Fatalf("DownloadGoSDK() error = %v", err)
+	}
+	want := []string{StageDownloading, StageVerifying, StageExtracting, StageRegistering, StageComplete}
+	if !slices.Equal(stages, want) {
+		t.Fatalf("stages = %v, want %v", stages, want)
+	}
+	if len(files) == 0 {
+		t.Fatal("extracting progress named no files")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "go", "bin", "go")); err != nil {
+		t.Fatalf("installed go binary: %v", err)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(targetDir, ".go-extract-*"))
+	if len(leftovers) != 0 {
+		t.Fatalf("staging dirs left behind: %v", leftovers)
+	}
+}
+
+func TestDownloadGoSDKRejectsChecksumMismatch(t *testing.T) {
+	archive := testSDKArchive(t, map[string]string{"go/bin/go": "#!/bin/sh\n"})
+	serveSDK(t, archive, sha256Hex([]byte("something else")))
+
+	targetDir := t.TempDir()
+	if err := DownloadGoSDK(context.Background(), "go1.99.0", targetDir, nil); err == nil {
+		t.Fatal("DownloadGoSDK() error = nil for a checksum mismatch")
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, "go")); !os.IsNotExist(err) {
+		t.Fatalf("go dir after checksum mismatch: %v", err)
+	}
+}
+
+func TestDownloadGoSDKCancelKeepsPreviousSDK(t *testing.T) {
+	archive := testSDKArchive(t, map[string]string{
+		"go/VERSION": "go1.99.0\n",
+		"go/bin/go":  "new\n",
+	})
+	serveSDK(t, archive, sha256Hex(archive))
+
+	m := NewManager(t.TempDir())
+	previous := filepath.Join(m.GoSDKDir(), "bin", "go")
+	if err := os.MkdirAll(filepath.Dir(previous), 0o755); err != nil {
+		t.Fatalf("create previous sdk: %v", err)
+	}
+	if err := os.WriteFile(previous, []byte("old\n"), 0o755); err != nil {
+		t.Fatalf("write previous sdk: %v", err)
+	}
+
+	var active []Progress
+	err := m.DownloadGoSDK(context.Background(), "go1.99.0", func(p Progress) {
+		if p.Stage == StageExtracting && active == nil {
+			active = m.Active()
+			if !m.CancelDownload("go") {
+				t.Error("CancelDownload(go) = false during a download")
+			}
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("DownloadGoSDK() error = %v, want context.Canceled", err)
+	}
+	if len(active) != 1 || active[0].Tool != "go" || active[0].Stage != StageExtracting {
+		t.Fatalf("Active() during download = %+v", active)
+	}
+	if got := m.Active(); len(got) != 0 {
+		t.Fatalf("Active() after download = %+v", got)
+	}
+	if m.CancelDownload("go") {
+		t.Fatal("CancelDownload(go) = true with no download running")
+	}
+	content, err := os.ReadFile(previous)
+	if err != nil || string(content) != "old\n" {
+		t.Fatalf("previous sdk = %q, %v; want it kept", content, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"sync"
 )
 
@@ -14,6 +15,9 @@ type Manager struct {
 	mu          sync.Mutex
 	baseDir     string
 	downloading map[string]context.CancelFunc
+	// progress holds the latest progress of each running download, so a
+	// reopened settings view can resume showing it.
+	progress map[string]Progress
 }
 
 // NewManager creates a download manager with the given base directory.
@@ -21,6 +25,7 @@ func NewManager(baseDir string) *Manager {
 	return &Manager{
 		baseDir:     baseDir,
 		downloading: make(map[string]context.CancelFunc),
+		progress:    make(map[string]Progress),
 	}
 }
 
@@ -57,54 +62,83 @@ func (m *Manager) DownloadGoSDK(ctx context.Context, version string, onProgress
 	defer cancel()
 	defer m.finishDownload("go")
 
-	return DownloadGoSDK(dlCtx, version, m.baseDir, onProgress)
+	return DownloadGoSDK(dlCtx, version, m.baseDir, m.trackProgress("go", onProgress))
 }
 
 // InstallGopls installs gopls using the configured (or managed) Go binary.
 func (m *Manager) InstallGopls(ctx context.Context, goPath string, onProgress OnProgress) error {
-	dlCtx, cancel, err := m.startDownload(ctx, "gopls")
-	if err != nil {
-		return err
-	}
-	defer cancel()
-	defer m.finishDownload("gopls")
-
-	effectiveGo := goPath
-	if effectiveGo == "" {
-		if _, err := os.Stat(m.GoBinPath()); err == nil {
-			effectiveGo = m.GoBinPath()
-		}
-	}
-
-	return InstallGoplsBinary(dlCtx, effectiveGo, m.ToolBinDir(), onProgress)
+	return m.installGoTool(ctx, "gopls", goPath, InstallGoplsBinary, onProgress)
 }
 
 // InstallStaticcheck installs staticcheck using the configured (or managed) Go binary.
 func (m *Manager) InstallStaticcheck(ctx context.Context, goPath string, onProgress OnProgress) error {
-	dlCtx, cancel, err := m.startDownload(ctx, "staticcheck")
-	if err != nil {
-		return err
-	}
-	defer cancel()
-	defer m.finishDownload("staticcheck")
+	return m.installGoTool(ctx, "staticcheck", goPath, InstallStaticcheckBinary, onProgress)
+}
 
-	effectiveGo := goPath
-	if effectiveGo == "" {
-		if _, err := os.Stat(m.GoBinPath()); err == nil {
-			effectiveGo = m.GoBinPath()
-		}
-	}
+// InstallDelve installs dlv using the configured (or managed) Go binary.
+func (m *Manager) InstallDelve(ctx context.Context, goPath string, onProgress OnProgress) error {
+	return m.installGoTool(ctx, "dlv", goPath, InstallDelveBinary, onProgress)
+}
+
+// InstallGolangciLint installs golangci-lint using the configured (or managed) Go binary.
+func (m *Manager) InstallGolangciLint(ctx context.Context, goPath string, onProgress OnProgress) error {
+	return m.installGoTool(ctx, "golangci-lint", goPath, InstallGolangciLintBinary, onProgress)
+}
+
+// InstallGotip installs gotip and builds the development Go toolchain.
+func (m *Manager) InstallGotip(ctx context.Context, goPath string, onProgress OnProgress) error {
+	return m.installGoTool(ctx, "gotip", goPath, InstallGotipBinary, onProgress)
+}
 
-	return InstallStaticcheckBinary(dlCtx, effectiveGo, m.ToolBinDir(), onProgress)
+// ToolBinPath returns the expected path of an installed tool binary.
+func (m *Manager) ToolBinPath(tool string) string {
+	if runtime.GOOS == "windows" {
+		tool += ".exe"
+	}
+	return filepath.Join(m.ToolBinDir(), tool)
 }
 
-// CancelDownload cancels an in-progress download/install.
-func (m *Manager) CancelDownload(tool string) {
+// CancelDownload cancels an in-progress download/install and reports
+// whether one was running.
+func (m *Manager) CancelDownload(tool string) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	if cancel, ok := m.downloading[tool]; ok {
+	cancel, ok := m.downloading[tool]
+	if ok {
 		cancel()
 	}
+	return ok
+}
+
+// Active returns the latest progress of every running download, sorted by
+// tool. A download that has not reported yet is listed with its tool only.
+func (m *Manager) Active() []Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	active := make([]Progress, 0, len(m.downloading))
+	for tool := range m.downloading {
+		progress, ok := m.progress[tool]
+		if !ok {
+			progress = Progress{Tool: tool}
+		}
+		active = append(active, progress)
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Tool < active[j].Tool })
+	return active
+}
+
+// trackProgress records each update for Active before passing it on.
+func (m *Manager) trackProgress(tool string, onProgress OnProgress) OnProgress {
+	return func(p Progress) {
+		m.mu.Lock()
+		if _, running := m.downloading[tool]; running {
+			m.progress[tool] = p
+		}
+		m.mu.Unlock()
+		if onProgress != nil {
+			onProgress(p)
+		}
+	}
 }
 
 // DefaultBaseDir returns the platform-appropriate toolchain directory.
@@ -128,8 +162,31 @@ func (m *Manager) startDownload(ctx context.Context, tool string) (context.Conte
 	return dlCtx, cancel, nil
 }
 
+type goToolInstaller func(ctx context.Context, goPath string, targetBinDir string, onProgress OnProgress) error
+
+// installGoTool reserves a download slot for tool and runs install with the
+// configured Go binary, falling back to the managed SDK when none is set.
+func (m *Manager) installGoTool(ctx context.Context, tool string, goPath string, install goToolInstaller, onProgress OnProgress) error {
+	dlCtx, cancel, err := m.startDownload(ctx, tool)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	defer m.finishDownload(tool)
+
+	effectiveGo := goPath
+	if effectiveGo == "" {
+		if _, err := os.Stat(m.GoBinPath()); err == nil {
+			effectiveGo = m.GoBinPath()
+		}
+	}
+
+	return install(dlCtx, effectiveGo, m.ToolBinDir(), m.trackProgress(tool, onProgress))
+}
+
 func (m *Manager) finishDownload(tool string) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	delete(m.downloading, tool)
+	delete(m.progress, tool)
 }
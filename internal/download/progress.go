@@ -1,5 +1,18 @@
 package download
 
+// Progress stages. A Go SDK download goes through downloading, verifying,
+// extracting and registering; go install tools report installing, and gotip
+// building. Every successful install ends with StageComplete.
+const (
+	StageDownloading = "downloading"
+	StageVerifying   = "verifying"
+	StageExtracting  = "extracting"
+	StageRegistering = "registering"
+	StageInstalling  = "installing"
+	StageBuilding    = "building"
+	StageComplete    = "complete"
+)
+
 // Progress reports download/install progress.
 type Progress struct {
 	Tool          string  `json:"tool"`
@@ -8,6 +21,13 @@ type Progress struct {
 	BytesTotal    int64   `json:"bytesTotal"`
 	Percent       float64 `json:"percent"`
 	Message       string  `json:"message"`
+	// Step is the 1-based position of Stage among the Steps stages of an
+	// install with known stages; both are zero otherwise. Percent is the
+	// progress within the stage.
+	Step  int `json:"step,omitempty"`
+	Steps int `json:"steps,omitempty"`
+	// File is the archive entry being extracted.
+	File string `json:"file,omitempty"`
 }
 
 // OnProgress is a callback for progress updates.
@@ -0,0 +1,94 @@
+package eventschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// ErrUnregistered is returned when validating an event with no schema.
+var ErrUnregistered = errors.New("event has no registered schema")
+
+// Registry holds the payload schema of each event name.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]*Schema
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string]*Schema)}
+}
+
+// Register records the payload schema of event name from an example
+// payload, usually the zero value of the payload type.
+func (r *Registry) Register(name string, payload any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[name] = For(reflect.TypeOf(payload))
+}
+
+// Names returns the registered event names, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.schemas))
+	for name := range r.schemas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Schema returns the payload schema of event name.
+func (r *Registry) Schema(name string) (*Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schema, ok := r.schemas[name]
+	return schema, ok
+}
+
+// Validate checks a payload against the schema of event name.
+func (r *Registry) Validate(name string, payload any) error {
+	schema, ok := r.Schema(name)
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrUnregistered)
+	}
+	if err := Validate(schema, payload); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// Document is the exported form of a registry: one schema per event.
+type Document struct {
+	Schema string             `json:"$schema"`
+	Events map[string]*Schema `json:"events"`
+}
+
+// Export returns the registry as a document, with event names passed
+// through rename so a client sees the names it will receive.
+func (r *Registry) Export(rename func(name string) string) Document {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	document := Document{Schema: Dialect, Events: make(map[string]*Schema, len(r.schemas))}
+	for name, schema := range r.schemas {
+		if rename != nil {
+			name = rename(name)
+		}
+		document.Events[name] = schema
+	}
+	return document
+}
+
+// MarshalIndent encodes the document for writing to a file.
+func (d Document) MarshalIndent() ([]byte, error) {
+	encoded, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode event schemas: %w", err)
+	}
+	return append(encoded, '\n'), nil
+}
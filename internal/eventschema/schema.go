@@ -0,0 +1,253 @@
+// Package eventschema derives JSON Schemas from the Go types of event
+// payloads and validates payloads against them, so the shape of every event
+// the backend emits is explicit and a change to it shows up in tests.
+package eventschema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Dialect is the JSON Schema version the schemas follow.
+const Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema that Go payload types need.
+type Schema struct {
+	// Types lists the allowed JSON types; it is written as "type", a single
+	// string when there is one. No types allows any value.
+	Types      []string           `json:"-"`
+	Format     string             `json:"format,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	// Closed rejects properties that are not listed; it is written as
+	// "additionalProperties": false.
+	Closed bool    `json:"-"`
+	Items  *Schema `json:"items,omitempty"`
+	// Values is the schema of every property of a map, written as
+	// "additionalProperties".
+	Values *Schema `json:"-"`
+}
+
+// MarshalJSON writes the schema with its JSON Schema keywords.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	encoded := struct {
+		Type                 any `json:"type,omitempty"`
+		AdditionalProperties any `json:"additionalProperties,omitempty"`
+		*plain
+	}{plain: (*plain)(s)}
+	switch len(s.Types) {
+	case 0:
+	case 1:
+		encoded.Type = s.Types[0]
+	default:
+		encoded.Type = s.Types
+	}
+	if s.Values != nil {
+		encoded.AdditionalProperties = s.Values
+	} else if s.Closed {
+		encoded.AdditionalProperties = false
+	}
+	return json.Marshal(encoded)
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// For returns the schema of the JSON encoding/json produces for values of
+// type t. Recursive types are cut off with an unconstrained schema where
+// they recur.
+func For(t reflect.Type) *Schema {
+	return schemaFor(t, nil)
+}
+
+func schemaFor(t reflect.Type, visiting []reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Types: []string{"string"}, Format: "date-time"}
+	case t == durationType:
+		return &Schema{Types: []string{"integer"}}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)):
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)):
+		return &Schema{Types: []string{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Types: []string{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Types: []string{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Types: []string{"number"}}
+	case reflect.String:
+		return &Schema{Types: []string{"string"}}
+	case reflect.Pointer:
+		return nullable(schemaFor(t.Elem(), visiting))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Types: []string{"string", "null"}}
+		}
+		return nullable(&Schema{Types: []string{"array"}, Items: schemaFor(t.Elem(), visiting)})
+	case reflect.Array:
+		return &Schema{Types: []string{"array"}, Items: schemaFor(t.Elem(), visiting)}
+	case reflect.Map:
+		return nullable(&Schema{Types: []string{"object"}, Values: schemaFor(t.Elem(), visiting)})
+	case reflect.Struct:
+		if slices.Contains(visiting, t) {
+			return &Schema{}
+		}
+		schema := &Schema{Types: []string{"object"}, Properties: make(map[string]*Schema), Closed: true}
+		addFields(schema, t, append(visiting, t))
+		return schema
+	}
+	// Interfaces and anything else encoding/json accepts hold any value.
+	return &Schema{}
+}
+
+// addFields adds the properties of struct t, promoting the fields of
+// untagged embedded structs as encoding/json does.
+func addFields(schema *Schema, t reflect.Type, visiting []reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			embedded := fieldType
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addFields(schema, embedded, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := schemaFor(fieldType, visiting)
+		if hasOption(options, "string") {
+			property = &Schema{Types: []string{"string"}}
+		}
+		schema.Properties[name] = property
+		if !hasOption(options, "omitempty") && !hasOption(options, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	slices.Sort(schema.Required)
+}
+
+func hasOption(options string, option string) bool {
+	return slices.Contains(strings.Split(options, ","), option)
+}
+
+func nullable(schema *Schema) *Schema {
+	if len(schema.Types) > 0 && !slices.Contains(schema.Types, "null") {
+		schema.Types = append(schema.Types, "null")
+	}
+	return schema
+}
+
+// Validate checks the JSON encoding of value against schema. Errors name
+// the path of the first mismatch, such as "$.highlights[0].start".
+func Validate(schema *Schema, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded any
+	if err := decoder.Decode(&decoded); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	return validate(schema, decoded, "$")
+}
+
+func validate(schema *Schema, value any, path string) error {
+	if len(schema.Types) > 0 && !slices.Contains(schema.Types, jsonType(value, schema.Types)) {
+		return fmt.Errorf("%s: got %s, want %s", path, jsonType(value, nil), strings.Join(schema.Types, " or "))
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := value[name]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, name)
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			switch {
+			case ok:
+			case schema.Values != nil:
+				property = schema.Values
+			case schema.Closed:
+				return fmt.Errorf("%s: unexpected property %q", path, name)
+			default:
+				continue
+			}
+			if err := validate(property, value[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if schema.Items == nil {
+			return nil
+		}
+		for i, item := range value {
+			if err := validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON type of a decoded value. A whole number counts
+// as an integer when the schema allows integers.
+func jsonType(value any, allowed []string) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if !strings.ContainsAny(value.String(), ".eE") && (allowed == nil || slices.Contains(allowed, "integer")) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
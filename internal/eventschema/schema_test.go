@@ -0,0 +1,103 @@
+package eventschema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testSpan struct {
+	Start int    `json:"start"`
+	Label string `json:"label,omitempty"`
+}
+
+type testEvent struct {
+	testBase
+	Chunk   string            `json:"chunk"`
+	Spans   []testSpan        `json:"spans,omitempty"`
+	At      time.Time         `json:"at"`
+	Labels  map[string]string `json:"labels"`
+	Parent  *testEvent        `json:"parent,omitempty"`
+	Ignored string            `json:"-"`
+	private int
+}
+
+func TestForDescribesJSONEncoding(t *testing.T) {
+	t.Parallel()
+
+	schema := For(reflect.TypeFor[testEvent]())
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("marshal schema: %v", err)
+	}
+	for _, want := range []string{
+		`"additionalProperties":false`,
+		`"required":["at","chunk","id","labels"]`,
+		`"at":{"type":"string","format":"date-time"}`,
+		`"labels":{"type":["object","null"],"additionalProperties":{"type":"string"}}`,
+		`"spans":{"type":["array","null"],"items":`,
+		`"parent":{}`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("schema = %s, want it to contain %s", encoded, want)
+		}
+	}
+	if strings.Contains(string(encoded), "Ignored") || strings.Contains(string(encoded), "private") {
+		t.Errorf("schema = %s, want skipped fields left out", encoded)
+	}
+}
+
+func TestValidateReportsFirstMismatch(t *testing.T) {
+	t.Parallel()
+
+	schema := For(reflect.TypeFor[testEvent]())
+	valid := testEvent{testBase: testBase{ID: "run_1"}, Chunk: "ok", Spans: []testSpan{{Start: 2}}}
+	if err := Validate(schema, valid); err != nil {
+		t.Fatalf("Validate(valid) error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload any
+		want    string
+	}{
+		{name: "wrong type", payload: map[string]any{"id": 1, "chunk": "", "at": "", "labels": nil}, want: "$.id: got integer, want string"},
+		{name: "missing property", payload: map[string]any{"id": "", "at": "", "labels": nil}, want: `missing required property "chunk"`},
+		{name: "unexpected property", payload: map[string]any{"id": "", "chunk": "", "at": "", "labels": nil, "extra": true}, want: `unexpected property "extra"`},
+		{name: "nested item", payload: map[string]any{"id": "", "chunk": "", "at": "", "labels": nil, "spans": []any{map[string]any{"start": 1.5}}}, want: "$.spans[0].start: got number, want integer"},
+		{name: "map value", payload: map[string]any{"id": "", "chunk": "", "at": "", "labels": map[string]any{"a": 1}}, want: "$.labels.a"},
+	}
+	for _, test := range tests {
+		err := Validate(schema, test.payload)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: Validate() error = %v, want %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestRegistryValidatesByEventName(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry()
+	registry.Register("app:span", testSpan{})
+	if err := registry.Validate("app:span", testSpan{Start: 1}); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := registry.Validate("app:span", map[string]string{"start": "1"}); err == nil {
+		t.Fatal("Validate(mismatch) error = nil")
+	}
+	if err := registry.Validate("app:other", testSpan{}); !errors.Is(err, ErrUnregistered) {
+		t.Fatalf("Validate(unregistered) error = %v", err)
+	}
+	document := registry.Export(func(name string) string { return strings.Replace(name, "app:", "acme:", 1) })
+	if _, ok := document.Events["acme:span"]; !ok || document.Schema != Dialect {
+		t.Fatalf("Export() = %+v", document)
+	}
+}
@@ -0,0 +1,55 @@
+package execution
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NormalizeCPUAffinity validates CPU indexes for RunOptions.CPUAffinity and
+// returns them deduplicated and sorted.
+func NormalizeCPUAffinity(cpus []int) ([]int, error) {
+	if len(cpus) == 0 {
+		return nil, nil
+	}
+	available := runtime.NumCPU()
+	normalized := make([]int, 0, len(cpus))
+	for _, cpu := range cpus {
+		if cpu < 0 || cpu >= available {
+			return nil, fmt.Errorf("CPU %d is out of range; this machine has CPUs 0-%d", cpu, available-1)
+		}
+		if !slices.Contains(normalized, cpu) {
+			normalized = append(normalized, cpu)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// formatCPUList renders cpus as a comma-separated list.
+func formatCPUList(cpus []int) string {
+	parts := make([]string, len(cpus))
+	for i, cpu := range cpus {
+		parts[i] = strconv.Itoa(cpu)
+	}
+	return strings.Join(parts, ",")
+}
+
+// affinityEnvironment sets GOMAXPROCS to the size of the CPU set unless
+// environment chooses its own value. The input map is not modified.
+func affinityEnvironment(environment map[string]string, cpus []int) map[string]string {
+	if len(cpus) == 0 {
+		return environment
+	}
+	if _, ok := environment["GOMAXPROCS"]; ok {
+		return environment
+	}
+	merged := make(map[string]string, len(environment)+1)
+	for key, value := range environment {
+		merged[key] = value
+	}
+	merged["GOMAXPROCS"] = strconv.Itoa(len(cpus))
+	return merged
+}
@@ -0,0 +1,20 @@
+//go:build linux
+
+package execution
+
+import "os/exec"
+
+// pinnedCommand returns the program and arguments that run name with args
+// on cpus. The go command runs under taskset, so the build and the snippet
+// it starts inherit the CPU set. Without taskset the command is unchanged
+// and pinned is false.
+func pinnedCommand(name string, args []string, cpus []int) (string, []string, bool) {
+	if len(cpus) == 0 {
+		return name, args, false
+	}
+	taskset, err := exec.LookPath("taskset")
+	if err != nil {
+		return name, args, false
+	}
+	return taskset, append([]string{"-c", formatCPUList(cpus), name}, args...), true
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package execution
+
+// pinnedCommand leaves the command unchanged: CPU pinning is only supported
+// on Linux, and elsewhere a CPU set only limits GOMAXPROCS.
+func pinnedCommand(name string, args []string, cpus []int) (string, []string, bool) {
+	return name, args, false
+}
@@ -0,0 +1,67 @@
+package execution
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCPUAffinity(t *testing.T) {
+	t.Parallel()
+
+	got, err := NormalizeCPUAffinity([]int{0, 0})
+	if err != nil || !reflect.DeepEqual(got, []int{0}) {
+		t.Fatalf("NormalizeCPUAffinity([0 0]) = %v, %v; want [0]", got, err)
+	}
+	if got, err := NormalizeCPUAffinity(nil); err != nil || got != nil {
+		t.Fatalf("NormalizeCPUAffinity(nil) = %v, %v", got, err)
+	}
+	for _, cpus := range [][]int{{-1}, {runtime.NumCPU()}} {
+		if _, err := NormalizeCPUAffinity(cpus); err == nil {
+			t.Fatalf("NormalizeCPUAffinity(%v) error = nil, want error", cpus)
+		}
+	}
+}
+
+func TestAffinityEnvironment(t *testing.T) {
+	t.Parallel()
+
+	base := map[string]string{"FOO": "bar"}
+	if got := affinityEnvironment(base, []int{0, 2}); got["GOMAXPROCS"] != "2" || got["FOO"] != "bar" {
+		t.Fatalf("affinityEnvironment() = %v, want GOMAXPROCS=2", got)
+	}
+	if len(base) != 1 {
+		t.Fatalf("input environment modified: %v", base)
+	}
+	explicit := map[string]string{"GOMAXPROCS": "8"}
+	if got := affinityEnvironment(explicit, []int{0}); got["GOMAXPROCS"] != "8" {
+		t.Fatalf("affinityEnvironment() overrode GOMAXPROCS: %v", got)
+	}
+}
+
+func TestRunGoSnippetWithOptionsPinsCPUs(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	snippet := "package main\n\nimport (\n\t\"fmt\"\n\t\"runtime\"\n)\n\nfunc main() {\n\tfmt.Println(runtime.GOMAXPROCS(0))\n}\n"
+	result, err := RunGoSnippetWithOptions(context.Background(), t.TempDir(), snippet, RunOptions{CPUAffinity: []int{0}})
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d, stderr = %s", result.ExitCode, result.Stderr)
+	}
+	if got := strings.TrimSpace(result.Stdout); got != "1" {
+		t.Fatalf("GOMAXPROCS = %s, want 1", got)
+	}
+	_, tasksetErr := exec.LookPath("taskset")
+	if pinned := runtime.GOOS == "linux" && tasksetErr == nil; pinned != reflect.DeepEqual(result.CPUAffinity, []int{0}) {
+		t.Fatalf("CPUAffinity = %v, want pinned %v", result.CPUAffinity, pinned)
+	}
+}
@@ -0,0 +1,50 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Execution backend names accepted in settings and BackendEnv.
+const (
+	BackendGo   = "go"
+	BackendFake = "fake"
+)
+
+// BackendEnv selects the execution backend, overriding settings.
+const BackendEnv = "GOPOKE_EXECUTION_BACKEND"
+
+// Backend executes snippets for the application.
+type Backend interface {
+	// Name is the backend's settings name.
+	Name() string
+	// Run executes one snippet in projectPath.
+	Run(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error)
+}
+
+// GoBackend runs snippets with the selected Go toolchain.
+type GoBackend struct{}
+
+// Name implements Backend.
+func (GoBackend) Name() string {
+	return BackendGo
+}
+
+// Run implements Backend with RunGoSnippetWithOptions.
+func (GoBackend) Run(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error) {
+	return RunGoSnippetWithOptions(ctx, projectPath, snippet, options)
+}
+
+// NewBackend returns the backend registered under name. An empty name
+// selects the Go backend.
+func NewBackend(name string) (Backend, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", BackendGo:
+		return GoBackend{}, nil
+	case BackendFake:
+		return &FakeBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown execution backend %q", name)
+	}
+}
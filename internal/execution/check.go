@@ -0,0 +1,204 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CheckDirName is the directory under a run cache holding snippet files
+// written for checks.
+const CheckDirName = "check"
+
+// Check stages, in the order they run.
+const (
+	CheckStageBuild = "build"
+	CheckStageVet   = "vet"
+)
+
+// CheckOptions configures a compile-only check.
+type CheckOptions struct {
+	WorkingDirectory string
+	Environment      map[string]string
+	Toolchain        string
+	Timeout          time.Duration
+	// Vet runs go vet once the snippet builds.
+	Vet bool
+	// CacheDir is the run cache directory whose CheckDirName holds the
+	// generated snippet file. Empty uses RunCacheDirName inside the project.
+	CacheDir string
+}
+
+// CheckResult is the outcome of compiling a snippet without running it.
+type CheckResult struct {
+	// OK is set when every stage passed.
+	OK bool `json:"OK"`
+	// Stage is the last stage that ran.
+	Stage              string       `json:"Stage"`
+	Stderr             string       `json:"Stderr"`
+	DurationMS         int64        `json:"DurationMS"`
+	TimedOut           bool         `json:"TimedOut"`
+	Canceled           bool         `json:"Canceled"`
+	Diagnostics        []Diagnostic `json:"Diagnostics"`
+	DiagnosticsSummary string       `json:"DiagnosticsSummary,omitempty"`
+}
+
+// Checker is implemented by backends that can check a snippet without
+// running it.
+type Checker interface {
+	Check(ctx context.Context, projectPath string, snippet string, options CheckOptions) (CheckResult, error)
+}
+
+// Check implements Checker with CheckGoSnippet.
+func (GoBackend) Check(ctx context.Context, projectPath string, snippet string, options CheckOptions) (CheckResult, error) {
+	return CheckGoSnippet(ctx, projectPath, snippet, options)
+}
+
+// Check implements Checker. A snippet scripted to exit non-zero fails its
+// build stage with the scripted stderr; delays are ignored.
+func (b *FakeBackend) Check(ctx context.Context, projectPath string, snippet string, options CheckOptions) (CheckResult, error) {
+	if err := ctx.Err(); err != nil {
+		return CheckResult{}, fmt.Errorf("check snippet context: %w", err)
+	}
+	if strings.TrimSpace(snippet) == "" {
+		return CheckResult{}, fmt.Errorf("snippet is required")
+	}
+	response, err := b.respond(snippet)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	result := CheckResult{OK: true, Stage: CheckStageBuild}
+	if options.Vet {
+		result.Stage = CheckStageVet
+	}
+	if response.ExitCode == 0 {
+		return result, nil
+	}
+	var stderr strings.Builder
+	for _, output := range response.Outputs {
+		if output.Stream == StreamStderr {
+			stderr.WriteString(output.Text)
+		}
+	}
+	return CheckResult{Stage: CheckStageBuild, Stderr: stderr.String()}, nil
+}
+
+// CheckGoSnippet builds snippet in projectPath, discarding the binary, and
+// vets it when the build succeeds and options ask for it. The program never
+// runs.
+func CheckGoSnippet(ctx context.Context, projectPath string, snippet string, options CheckOptions) (CheckResult, error) {
+	if err := ctx.Err(); err != nil {
+		return CheckResult{}, fmt.Errorf("check snippet context: %w", err)
+	}
+	if strings.TrimSpace(projectPath) == "" {
+		return CheckResult{}, fmt.Errorf("project path is required")
+	}
+	if strings.TrimSpace(snippet) == "" {
+		return CheckResult{}, fmt.Errorf("snippet is required")
+	}
+
+	absoluteProjectPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("resolve project path: %w", err)
+	}
+	workingDirectory := strings.TrimSpace(options.WorkingDirectory)
+	if workingDirectory == "" {
+		workingDirectory = absoluteProjectPath
+	} else if !filepath.IsAbs(workingDirectory) {
+		workingDirectory = filepath.Join(absoluteProjectPath, workingDirectory)
+	}
+	info, err := os.Stat(workingDirectory)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("inspect working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return CheckResult{}, fmt.Errorf("working directory must be a directory")
+	}
+
+	checkDir := filepath.Join(runCacheDir(absoluteProjectPath, options.CacheDir), CheckDirName)
+	if err := os.MkdirAll(checkDir, 0o700); err != nil {
+		return CheckResult{}, fmt.Errorf("create check dir: %w", err)
+	}
+	filePath, err := stableSnippetFilePath(checkDir, snippet)
+	if err != nil {
+		return CheckResult{}, fmt.Errorf("resolve snippet check path: %w", err)
+	}
+	if err := os.WriteFile(filePath, []byte(snippet), 0o600); err != nil {
+		return CheckResult{}, fmt.Errorf("write snippet file: %w", err)
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	toolchain := strings.TrimSpace(options.Toolchain)
+	if toolchain == "" {
+		toolchain = "go"
+	}
+	environment := mergeEnvironment(os.Environ(), options.Environment)
+
+	startedAt := time.Now()
+	stages := [][]string{{CheckStageBuild, "-o", os.DevNull, filePath}}
+	if options.Vet {
+		stages = append(stages, []string{CheckStageVet, filePath})
+	}
+	result := CheckResult{OK: true}
+	for _, args := range stages {
+		result.Stage = args[0]
+		stderr, err := runCheckStage(checkCtx, toolchain, args, workingDirectory, environment)
+		result.DurationMS = time.Since(startedAt).Milliseconds()
+		if err == nil {
+			continue
+		}
+		result.OK = false
+		result.Stderr = stderr
+		switch {
+		case errors.Is(checkCtx.Err(), context.DeadlineExceeded):
+			result.TimedOut = true
+			if strings.TrimSpace(result.Stderr) == "" {
+				result.Stderr = MessageTimedOut
+			}
+			return result, nil
+		case errors.Is(checkCtx.Err(), context.Canceled):
+			result.Canceled = true
+			if strings.TrimSpace(result.Stderr) == "" {
+				result.Stderr = MessageCanceled
+			}
+			return result, nil
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return CheckResult{}, fmt.Errorf("%s snippet: %w", args[0], err)
+		}
+		return result, nil
+	}
+	return result, nil
+}
+
+// runCheckStage runs one go subcommand and returns its stderr.
+func runCheckStage(ctx context.Context, toolchain string, args []string, dir string, environment []string) (string, error) {
+	command := exec.Command(toolchain, args...)
+	command.Dir = dir
+	command.Env = environment
+	configureCommandForLifecycle(command)
+	stderr := newLimitedCaptureWriter(resolveMaxBytes(0), "", nil)
+	command.Stderr = stderr
+	if err := command.Start(); err != nil {
+		return "", err
+	}
+	waitCh := make(chan error, 1)
+	go func() {
+		waitCh <- command.Wait()
+	}()
+	err := waitForCommandExit(ctx, command, waitCh, Shutdown{}, resolveKillGracePeriod(0))
+	stderr.Flush()
+	return stderr.Decoded().Text, err
+}
@@ -0,0 +1,50 @@
+package execution
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckGoSnippet(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte("module example.com/check\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	cases := []struct {
+		name      string
+		snippet   string
+		wantOK    bool
+		wantStage string
+		wantError string
+	}{
+		{"clean", "package main\nimport \"os\"\nfunc main(){os.Exit(3)}\n", true, CheckStageVet, ""},
+		{"compile error", "package main\nfunc main(){ var unused int }\n", false, CheckStageBuild, "declared and not used"},
+		{"vet finding", "package main\nimport \"fmt\"\nfunc main(){fmt.Printf(\"%d\\n\", \"s\")}\n", false, CheckStageVet, "wrong type"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := CheckGoSnippet(context.Background(), projectDir, tc.snippet, CheckOptions{Vet: true})
+			if err != nil {
+				t.Fatalf("CheckGoSnippet() error = %v", err)
+			}
+			if result.OK != tc.wantOK || result.Stage != tc.wantStage {
+				t.Fatalf("result = %+v, want OK %t at stage %s", result, tc.wantOK, tc.wantStage)
+			}
+			if !strings.Contains(result.Stderr, tc.wantError) {
+				t.Fatalf("Stderr = %q, want %q", result.Stderr, tc.wantError)
+			}
+		})
+	}
+}
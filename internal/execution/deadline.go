@@ -0,0 +1,42 @@
+package execution
+
+import (
+	"strconv"
+	"time"
+)
+
+// EnvDeadline holds the moment a run times out, as Unix seconds with a
+// millisecond fraction. Runs set it only when asked to export their
+// deadline; snippets read it through DeadlineHelperSource or gopoke.Context.
+const EnvDeadline = "GOPOKE_DEADLINE_UNIX"
+
+// DeadlineHelperSource is helper code for snippets run with an exported
+// deadline. It needs the context, os, strconv and time imports. Without a
+// deadline the context never expires on its own.
+const DeadlineHelperSource = `// runContext returns a context that ends shortly before gopoke times the
+// run out, so the snippet can stop cleanly instead of being interrupted.
+func runContext() (context.Context, context.CancelFunc) {
+	seconds, err := strconv.ParseFloat(os.Getenv("` + EnvDeadline + `"), 64)
+	if err != nil {
+		return context.WithCancel(context.Background())
+	}
+	deadline := time.UnixMilli(int64(seconds * 1000)).Add(-250 * time.Millisecond)
+	return context.WithDeadline(context.Background(), deadline)
+}
+`
+
+// FormatDeadline renders deadline as an EnvDeadline value.
+func FormatDeadline(deadline time.Time) string {
+	return strconv.FormatFloat(float64(deadline.UnixMilli())/1000, 'f', 3, 64)
+}
+
+// deadlineEnvironment returns environment with EnvDeadline set to deadline.
+// The input map is not modified.
+func deadlineEnvironment(environment map[string]string, deadline time.Time) map[string]string {
+	merged := make(map[string]string, len(environment)+1)
+	for key, value := range environment {
+		merged[key] = value
+	}
+	merged[EnvDeadline] = FormatDeadline(deadline)
+	return merged
+}
@@ -0,0 +1,63 @@
+package execution
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDeadline(t *testing.T) {
+	t.Parallel()
+
+	deadline := time.Date(2025, time.March, 1, 12, 0, 0, 250_900_000, time.UTC)
+	if got, want := FormatDeadline(deadline), "1740830400.250"; got != want {
+		t.Fatalf("FormatDeadline() = %q, want %q", got, want)
+	}
+	base := map[string]string{"FOO": "bar"}
+	if got := deadlineEnvironment(base, deadline); got[EnvDeadline] != "1740830400.250" || got["FOO"] != "bar" {
+		t.Fatalf("deadlineEnvironment() = %v", got)
+	}
+	if len(base) != 1 {
+		t.Fatalf("input environment modified: %v", base)
+	}
+}
+
+func TestRunGoSnippetWithOptionsExportsDeadline(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	projectDir := t.TempDir()
+	snippet := "package main\n\nimport (\n\t\"context\"\n\t\"fmt\"\n\t\"os\"\n\t\"strconv\"\n\t\"time\"\n)\n\n" +
+		DeadlineHelperSource +
+		"\nfunc main() {\n\tctx, cancel := runContext()\n\tdefer cancel()\n\tdeadline, ok := ctx.Deadline()\n\tif !ok {\n\t\tfmt.Println(\"none\")\n\t\treturn\n\t}\n\tfmt.Println(time.Until(deadline).Milliseconds())\n}\n"
+	timeout := 30 * time.Second
+
+	result, err := RunGoSnippetWithOptions(context.Background(), projectDir, snippet, RunOptions{Timeout: timeout, ExportDeadline: true})
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("ExitCode = %d, stderr = %s", result.ExitCode, result.Stderr)
+	}
+	remaining, err := strconv.ParseInt(strings.TrimSpace(result.Stdout), 10, 64)
+	if err != nil {
+		t.Fatalf("Stdout = %q, want remaining milliseconds", result.Stdout)
+	}
+	if remaining <= 0 || remaining >= timeout.Milliseconds() {
+		t.Fatalf("remaining = %dms, want within the %s timeout", remaining, timeout)
+	}
+
+	result, err = RunGoSnippetWithOptions(context.Background(), projectDir, snippet, RunOptions{Timeout: timeout})
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	if got := strings.TrimSpace(result.Stdout); got != "none" {
+		t.Fatalf("Stdout without ExportDeadline = %q, want none", got)
+	}
+}
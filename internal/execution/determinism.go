@@ -0,0 +1,117 @@
+package execution
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables a run sets when asked to be reproducible. Snippets
+// read them through their own helper code; DeterminismHelperSource is one
+// they can paste.
+const (
+	// EnvSeed holds the run's random seed as a decimal int64.
+	EnvSeed = "GOPOKE_SEED"
+	// EnvFrozenTime holds the run's frozen start time in RFC 3339 format
+	// with nanoseconds, in UTC.
+	EnvFrozenTime = "GOPOKE_FROZEN_TIME"
+)
+
+// seedGODEBUG makes the top-level math/rand functions deterministic: the
+// global source starts from seed 1 instead of a random one, and rand.Seed
+// takes effect again on Go 1.24 and later.
+var seedGODEBUG = []string{"randautoseed=0", "randseednop=0"}
+
+// DeterminismHelperSource is helper code for snippets run with a seed or a
+// frozen start time. It needs the math/rand, os, strconv and time imports.
+// Without those options it falls back to a random seed and the wall clock.
+const DeterminismHelperSource = `// runSeed returns the seed gopoke set for the run, or a random one.
+func runSeed() int64 {
+	if value, err := strconv.ParseInt(os.Getenv("` + EnvSeed + `"), 10, 64); err == nil {
+		return value
+	}
+	return time.Now().UnixNano()
+}
+
+// runRand returns a generator seeded with runSeed.
+func runRand() *rand.Rand {
+	return rand.New(rand.NewSource(runSeed()))
+}
+
+// runStart is the frozen start time gopoke set for the run, or the wall
+// clock at startup.
+var runStart = func() time.Time {
+	if value, err := time.Parse(time.RFC3339Nano, os.Getenv("` + EnvFrozenTime + `")); err == nil {
+		return value
+	}
+	return time.Now()
+}()
+
+// runClockBase anchors runNow to the wall clock.
+var runClockBase = time.Now()
+
+// runNow advances from runStart at wall-clock speed.
+func runNow() time.Time {
+	return runStart.Add(time.Since(runClockBase))
+}
+`
+
+// ParseFrozenTime parses a frozen start time in RFC 3339 format. An empty
+// value returns the zero time.
+func ParseFrozenTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("frozen time must be RFC 3339, such as 2025-01-01T09:00:00Z: %w", err)
+	}
+	return parsed, nil
+}
+
+// determinismEnvironment returns environment with the seed and frozen start
+// time applied. hostGODEBUG is kept when environment does not set GODEBUG
+// itself. The input map is not modified.
+func determinismEnvironment(environment map[string]string, seed *int64, frozenTime time.Time, hostGODEBUG string) map[string]string {
+	if seed == nil && frozenTime.IsZero() {
+		return environment
+	}
+	merged := make(map[string]string, len(environment)+3)
+	for key, value := range environment {
+		merged[key] = value
+	}
+	if seed != nil {
+		merged[EnvSeed] = strconv.FormatInt(*seed, 10)
+		godebug, ok := merged["GODEBUG"]
+		if !ok {
+			godebug = hostGODEBUG
+		}
+		merged["GODEBUG"] = appendGODEBUG(godebug, seedGODEBUG...)
+	}
+	if !frozenTime.IsZero() {
+		merged[EnvFrozenTime] = frozenTime.UTC().Format(time.RFC3339Nano)
+	}
+	return merged
+}
+
+// appendGODEBUG adds settings to a comma-separated GODEBUG value, replacing
+// earlier values of the same keys.
+func appendGODEBUG(godebug string, settings ...string) string {
+	replaced := make(map[string]bool, len(settings))
+	for _, setting := range settings {
+		key, _, _ := strings.Cut(setting, "=")
+		replaced[key] = true
+	}
+	parts := make([]string, 0, len(settings)+1)
+	for _, part := range strings.Split(godebug, ",") {
+		part = strings.TrimSpace(part)
+		key, _, _ := strings.Cut(part, "=")
+		if part == "" || replaced[key] {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(append(parts, settings...), ",")
+}
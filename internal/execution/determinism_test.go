@@ -0,0 +1,93 @@
+package execution
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeterminismEnvironment(t *testing.T) {
+	t.Parallel()
+
+	base := map[string]string{"FOO": "bar"}
+	if got := determinismEnvironment(base, nil, time.Time{}, "http2debug=1"); len(got) != 1 {
+		t.Fatalf("determinismEnvironment() without options = %v", got)
+	}
+
+	seed := int64(-42)
+	frozen := time.Date(2025, time.March, 30, 1, 30, 0, 0, time.FixedZone("CET", 3600))
+	got := determinismEnvironment(base, &seed, frozen, "http2debug=1,randautoseed=1")
+	if got[EnvSeed] != "-42" {
+		t.Errorf("%s = %q, want -42", EnvSeed, got[EnvSeed])
+	}
+	if want := "2025-03-30T00:30:00Z"; got[EnvFrozenTime] != want {
+		t.Errorf("%s = %q, want %q", EnvFrozenTime, got[EnvFrozenTime], want)
+	}
+	if want := "http2debug=1,randautoseed=0,randseednop=0"; got["GODEBUG"] != want {
+		t.Errorf("GODEBUG = %q, want %q", got["GODEBUG"], want)
+	}
+	if len(base) != 1 {
+		t.Fatalf("input environment modified: %v", base)
+	}
+
+	got = determinismEnvironment(map[string]string{"GODEBUG": "x509sha1=1"}, &seed, time.Time{}, "http2debug=1")
+	if want := "x509sha1=1,randautoseed=0,randseednop=0"; got["GODEBUG"] != want {
+		t.Errorf("GODEBUG = %q, want %q", got["GODEBUG"], want)
+	}
+	if _, ok := got[EnvFrozenTime]; ok {
+		t.Errorf("%s set without a frozen time", EnvFrozenTime)
+	}
+}
+
+func TestParseFrozenTime(t *testing.T) {
+	t.Parallel()
+
+	if value, err := ParseFrozenTime(" "); err != nil || !value.IsZero() {
+		t.Fatalf("ParseFrozenTime(blank) = %v, %v; want zero time", value, err)
+	}
+	value, err := ParseFrozenTime("2025-01-01T09:00:00.5+05:45")
+	if err != nil {
+		t.Fatalf("ParseFrozenTime() error = %v", err)
+	}
+	if got, want := value.UTC().Format(time.RFC3339Nano), "2025-01-01T03:15:00.5Z"; got != want {
+		t.Fatalf("ParseFrozenTime() = %s, want %s", got, want)
+	}
+	if _, err := ParseFrozenTime("yesterday"); err == nil {
+		t.Fatal("ParseFrozenTime(yesterday) error = nil, want error")
+	}
+}
+
+func TestRunGoSnippetWithOptionsSeedIsReproducible(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	projectDir := t.TempDir()
+	snippet := "package main\n\nimport (\n\t\"fmt\"\n\t\"math/rand\"\n\t\"os\"\n\t\"strconv\"\n\t\"time\"\n)\n\n" +
+		DeterminismHelperSource +
+		"\nfunc main() {\n\tfmt.Println(runRand().Int63(), rand.Int63(), runNow().Year())\n}\n"
+	seed := int64(7)
+	options := RunOptions{Seed: &seed, FrozenTime: time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)}
+
+	outputs := make([]string, 0, 2)
+	for range 2 {
+		result, err := RunGoSnippetWithOptions(context.Background(), projectDir, snippet, options)
+		if err != nil {
+			t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+		}
+		if result.ExitCode != 0 {
+			t.Fatalf("ExitCode = %d, stderr = %s", result.ExitCode, result.Stderr)
+		}
+		outputs = append(outputs, result.Stdout)
+	}
+	if outputs[0] != outputs[1] {
+		t.Fatalf("seeded runs differ: %q and %q", outputs[0], outputs[1])
+	}
+	if !strings.HasSuffix(strings.TrimSpace(outputs[0]), " 2001") {
+		t.Fatalf("Stdout = %q, want frozen year 2001", outputs[0])
+	}
+}
@@ -0,0 +1,118 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"gopoke/internal/procmem"
+)
+
+// MessageDiskQuotaExceeded is the stderr note for a run stopped by its disk
+// write quota.
+const MessageDiskQuotaExceeded = "execution stopped: disk write quota exceeded"
+
+// diskQuotaInterval is how often a run's disk writes are sampled.
+const diskQuotaInterval = 500 * time.Millisecond
+
+// errDiskQuotaExceeded is the cancel cause of a run that wrote too much.
+var errDiskQuotaExceeded = errors.New("disk write quota exceeded")
+
+// diskWriteMeter reports how many bytes a run has written so far.
+type diskWriteMeter func() int64
+
+// newDiskWriteMeter measures the storage writes of the process tree rooted
+// at pid where the platform reports them, and growth of workingDirectory
+// elsewhere. The build that go run does first counts too.
+func newDiskWriteMeter(pid int, workingDirectory string) diskWriteMeter {
+	if _, ok := procmem.WriteBytes(pid); ok {
+		return processWriteMeter(pid)
+	}
+	baseline := directorySize(workingDirectory)
+	return func() int64 {
+		return max(directorySize(workingDirectory)-baseline, 0)
+	}
+}
+
+// processWriteMeter sums write_bytes over the tree rooted at pid, keeping
+// the last reading of processes that have exited.
+func processWriteMeter(pid int) diskWriteMeter {
+	written := make(map[int]int64)
+	return func() int64 {
+		pids := []int{pid}
+		if tree, err := procmem.ProcessTree(pid); err == nil {
+			pids = pids[:0]
+			for _, process := range tree {
+				pids = append(pids, process.PID)
+			}
+		}
+		for _, current := range pids {
+			if value, ok := procmem.WriteBytes(current); ok && value > written[current] {
+				written[current] = value
+			}
+		}
+		var total int64
+		for _, value := range written {
+			total += value
+		}
+		return total
+	}
+}
+
+// directorySize is the total size of the regular files under root.
+func directorySize(root string) int64 {
+	var total int64
+	_ = filepath.WalkDir(root, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// watchDiskQuota samples meter until ctx ends and cancels the run with
+// errDiskQuotaExceeded once it has written more than limit bytes.
+func watchDiskQuota(ctx context.Context, meter diskWriteMeter, limit int64, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(diskQuotaInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if meter() > limit {
+				cancel(errDiskQuotaExceeded)
+				return
+			}
+		}
+	}
+}
+
+// limitDiskWrites is the stage that stops runs writing more than
+// options.MaxDiskWriteBytes.
+func limitDiskWrites(next RunFunc) RunFunc {
+	return func(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error) {
+		limit := options.MaxDiskWriteBytes
+		if limit <= 0 {
+			return next(ctx, projectPath, snippet, options)
+		}
+		ctx, stopRun := context.WithCancelCause(ctx)
+		defer stopRun(nil)
+		absoluteProjectPath, _ := filepath.Abs(projectPath)
+		workingDirectory := runDirectory(absoluteProjectPath, options.WorkingDirectory)
+		options = WithStartHook(options, func(pid int) {
+			go watchDiskQuota(ctx, newDiskWriteMeter(pid, workingDirectory), limit, stopRun)
+		})
+		result, err := next(ctx, projectPath, snippet, options)
+		if err == nil && stoppedBy(ctx, &result, errDiskQuotaExceeded, MessageDiskQuotaExceeded) {
+			result.DiskQuotaExceeded = true
+		}
+		return result, err
+	}
+}
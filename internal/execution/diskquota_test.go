@@ -0,0 +1,64 @@
+package execution
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirectoryWriteMeterReportsGrowth(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "existing"), make([]byte, 4096), 0o600); err != nil {
+		t.Fatalf("write existing file: %v", err)
+	}
+	// A pid that cannot exist forces the directory fallback.
+	meter := newDiskWriteMeter(-1, dir)
+	if got := meter(); got != 0 {
+		t.Fatalf("meter() before writes = %d, want 0", got)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "nested"), 0o700); err != nil {
+		t.Fatalf("create nested dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "nested", "new"), make([]byte, 1000), 0o600); err != nil {
+		t.Fatalf("write new file: %v", err)
+	}
+	if got := meter(); got != 1000 {
+		t.Fatalf("meter() after writes = %d, want 1000", got)
+	}
+}
+
+func TestRunGoSnippetWithOptionsStopsAtDiskQuota(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	snippet := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\t\"time\"\n)\n\nfunc main() {\n\tfile, err := os.Create(\"fill.bin\")\n\tif err != nil {\n\t\tpanic(err)\n\t}\n\tdefer file.Close()\n\tfmt.Println(\"writing\")\n\tchunk := make([]byte, 1<<20)\n\tfor i := 0; i < 500; i++ {\n\t\tfile.Write(chunk)\n\t\tfile.Sync()\n\t\ttime.Sleep(10 * time.Millisecond)\n\t}\n}\n"
+	projectDir := t.TempDir()
+	result, err := RunGoSnippetWithOptions(context.Background(), projectDir, snippet, RunOptions{
+		Timeout:           time.Minute,
+		MaxDiskWriteBytes: 64 << 20,
+	})
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	if !result.DiskQuotaExceeded || result.ExitCode != -1 {
+		t.Fatalf("result = exit %d, quota exceeded %v; want the run stopped", result.ExitCode, result.DiskQuotaExceeded)
+	}
+	if result.Limits.MaxDiskWriteBytes != 64<<20 {
+		t.Fatalf("Limits.MaxDiskWriteBytes = %d", result.Limits.MaxDiskWriteBytes)
+	}
+	if info, err := os.Stat(filepath.Join(projectDir, "fill.bin")); err == nil && info.Size() >= 500<<20 {
+		t.Fatalf("snippet wrote %d bytes despite the quota", info.Size())
+	}
+	if !strings.Contains(result.Stdout, "writing") {
+		t.Fatalf("Stdout = %q", result.Stdout)
+	}
+}
@@ -0,0 +1,97 @@
+package execution
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// defaultOutputDrainTimeout is how long output is still read after the run
+// exits. Processes the run left behind can hold its output open; the bound
+// keeps them from holding the result back.
+const defaultOutputDrainTimeout = 2 * time.Second
+
+// outputPipe carries one output stream of a run into its capture. The
+// runner owns both ends instead of leaving the copying to os/exec, so after
+// the process exits it knows whether everything was read or the drain
+// bound cut it short.
+type outputPipe struct {
+	reader *os.File
+	writer *os.File
+	done   chan struct{}
+}
+
+func newOutputPipe() (*outputPipe, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("create output pipe: %w", err)
+	}
+	return &outputPipe{reader: reader, writer: writer, done: make(chan struct{})}, nil
+}
+
+// copyTo copies the stream into dst until every holder of the write end
+// closes it. The runner's own write end is closed first; the started
+// process has its copy.
+func (p *outputPipe) copyTo(dst io.Writer) {
+	_ = p.writer.Close()
+	go func() {
+		defer close(p.done)
+		_, _ = io.Copy(dst, p.reader)
+	}()
+}
+
+// Close releases both ends; copying, if started, stops at the next read.
+func (p *outputPipe) Close() {
+	_ = p.writer.Close()
+	_ = p.reader.Close()
+}
+
+// drainOutput waits up to timeout for the pipes to reach end of output and
+// reports whether they did. It then closes them and waits for their
+// copying to stop, so no output arrives after the run's result is built.
+func drainOutput(pipes []*outputPipe, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	complete := true
+	for _, pipe := range pipes {
+		if complete {
+			select {
+			case <-pipe.done:
+			case <-timer.C:
+				complete = false
+			}
+		}
+		pipe.Close()
+		<-pipe.done
+	}
+	return complete
+}
+
+func resolveOutputDrainTimeout(value time.Duration) time.Duration {
+	if value > 0 {
+		return value
+	}
+	return defaultOutputDrainTimeout
+}
+
+// markStop records how much had been captured when the run was asked to
+// stop.
+func (w *limitedCaptureWriter) markStop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopSize = w.size
+	w.stopped = true
+}
+
+// drainedBytes is how much was captured after markStop; zero if the run
+// was never asked to stop.
+func (w *limitedCaptureWriter) drainedBytes() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.stopped {
+		return 0
+	}
+	return int64(w.size - w.stopSize)
+}
@@ -0,0 +1,197 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Directives the fake backend reads from snippet lines, in order, when no
+// scripted response matches. Each must start a line, so a snippet can script
+// its own outcome while remaining valid Go:
+//
+//	//stdout: hello      writes "hello" to stdout
+//	//stderr: ./x.go:3:2: declared and not used: v
+//	//sleep: 1.5         waits one and a half seconds
+//	//exit: 1            exits with status 1 after all output
+const (
+	DirectiveStdout = "//stdout: "
+	DirectiveStderr = "//stderr: "
+	DirectiveSleep  = "//sleep: "
+	DirectiveExit   = "//exit: "
+)
+
+// Output streams for scripted fake output.
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// FakeOutput is one scripted write, made after Delay.
+type FakeOutput struct {
+	Stream string
+	Text   string
+	Delay  time.Duration
+}
+
+// FakeResponse scripts the outcome of snippets containing Match.
+type FakeResponse struct {
+	// Match selects snippets containing it; empty matches every snippet.
+	Match    string
+	Outputs  []FakeOutput
+	ExitCode int
+}
+
+// FakeBackend produces scripted results without invoking a toolchain, for
+// demos, screenshots and tests on machines without Go. It honors timeouts,
+// cancellation, output caps, chunk callbacks and tee like the Go backend.
+type FakeBackend struct {
+	// Responses are tried in order; snippets matching none are scripted by
+	// their directive comments.
+	Responses []FakeResponse
+}
+
+// Name implements Backend.
+func (b *FakeBackend) Name() string {
+	return BackendFake
+}
+
+// Run implements Backend.
+func (b *FakeBackend) Run(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, fmt.Errorf("run snippet context: %w", err)
+	}
+	if strings.TrimSpace(projectPath) == "" {
+		return Result{}, fmt.Errorf("project path is required")
+	}
+	if strings.TrimSpace(snippet) == "" {
+		return Result{}, fmt.Errorf("snippet is required")
+	}
+	response, err := b.respond(snippet)
+	if err != nil {
+		return Result{}, err
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdoutCapture := newLimitedCaptureWriter(resolveMaxBytes(options.MaxStdoutBytes), options.OutputEncoding, options.OnStdoutChunk)
+	stderrCapture := newLimitedCaptureWriter(resolveMaxBytes(options.MaxStderrBytes), options.OutputEncoding, options.OnStderrChunk)
+	var stdout, stderr io.Writer = stdoutCapture, stderrCapture
+	var tee *teeSink
+	if options.Tee != nil {
+		tee = &teeSink{writer: options.Tee}
+		stdout = io.MultiWriter(stdoutCapture, tee)
+		stderr = io.MultiWriter(stderrCapture, tee)
+	}
+
+	startedAt := time.Now()
+	var interrupted error
+	for _, output := range response.Outputs {
+		if output.Delay > 0 {
+			timer := time.NewTimer(output.Delay)
+			select {
+			case <-runCtx.Done():
+				timer.Stop()
+				interrupted = runCtx.Err()
+			case <-timer.C:
+			}
+			if interrupted != nil {
+				break
+			}
+		}
+		writer := stdout
+		if output.Stream == StreamStderr {
+			writer = stderr
+		}
+		_, _ = io.WriteString(writer, output.Text)
+	}
+
+	stdoutCapture.Flush()
+	stderrCapture.Flush()
+
+	result := Result{
+		ExitCode:        response.ExitCode,
+		DurationMS:      time.Since(startedAt).Milliseconds(),
+		StdoutTruncated: stdoutCapture.Truncated(),
+		StderrTruncated: stderrCapture.Truncated(),
+		Limits: RunLimits{
+			TimeoutMS:      timeout.Milliseconds(),
+			MaxOutputBytes: int64(resolveMaxBytes(options.MaxStdoutBytes)),
+		},
+	}
+	result.setOutput(stdoutCapture, stderrCapture)
+	if tee != nil {
+		if teeErr := tee.Err(); teeErr != nil {
+			result.TeeError = teeErr.Error()
+		}
+	}
+
+	switch {
+	case interrupted == nil:
+	case errors.Is(interrupted, context.DeadlineExceeded):
+		result.TimedOut = true
+		result.ExitCode = -1
+		if strings.TrimSpace(result.Stderr) == "" {
+			result.Stderr = MessageTimedOut
+		}
+	default:
+		result.Canceled = true
+		result.ExitCode = -1
+		if strings.TrimSpace(result.Stderr) == "" {
+			result.Stderr = MessageCanceled
+		}
+	}
+	return result, nil
+}
+
+func (b *FakeBackend) respond(snippet string) (FakeResponse, error) {
+	for _, response := range b.Responses {
+		if strings.Contains(snippet, response.Match) {
+			return response, nil
+		}
+	}
+	return parseFakeDirectives(snippet)
+}
+
+// parseFakeDirectives scripts a response from snippet directive comments.
+func parseFakeDirectives(snippet string) (FakeResponse, error) {
+	var response FakeResponse
+	var delay time.Duration
+	for _, line := range strings.Split(snippet, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case strings.HasPrefix(line, DirectiveStdout):
+			response.Outputs = append(response.Outputs, FakeOutput{Stream: StreamStdout, Text: strings.TrimPrefix(line, DirectiveStdout) + "\n", Delay: delay})
+			delay = 0
+		case strings.HasPrefix(line, DirectiveStderr):
+			response.Outputs = append(response.Outputs, FakeOutput{Stream: StreamStderr, Text: strings.TrimPrefix(line, DirectiveStderr) + "\n", Delay: delay})
+			delay = 0
+		case strings.HasPrefix(line, DirectiveSleep):
+			seconds, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(line, DirectiveSleep)), 64)
+			if err != nil || seconds < 0 {
+				return FakeResponse{}, fmt.Errorf("invalid sleep directive %q", line)
+			}
+			delay += time.Duration(seconds * float64(time.Second))
+		case strings.HasPrefix(line, DirectiveExit):
+			code, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, DirectiveExit)))
+			if err != nil {
+				return FakeResponse{}, fmt.Errorf("invalid exit directive %q", line)
+			}
+			response.ExitCode = code
+		}
+	}
+	if delay > 0 {
+		// A trailing sleep still delays the end of the run.
+		response.Outputs = append(response.Outputs, FakeOutput{Stream: StreamStdout, Delay: delay})
+	}
+	return response, nil
+}
@@ -0,0 +1,85 @@
+package execution
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeBackendFollowsDirectives(t *testing.T) {
+	t.Parallel()
+
+	var chunks []string
+	backend := &FakeBackend{}
+	result, err := backend.Run(context.Background(), t.TempDir(),
+		"package main\n\n//stdout: hello\n//stderr: ./main.go:5:2: declared and not used: x\n//exit: 1\nfunc main() {}\n",
+		RunOptions{OnStdoutChunk: func(chunk string) { chunks = append(chunks, chunk) }})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Stdout != "hello\n" || result.ExitCode != 1 {
+		t.Fatalf("result = %+v, want scripted stdout and exit 1", result)
+	}
+	if !strings.Contains(result.Stderr, "declared and not used") {
+		t.Fatalf("Stderr = %q, want scripted compile error", result.Stderr)
+	}
+	if len(chunks) != 1 || chunks[0] != "hello\n" {
+		t.Fatalf("stdout chunks = %q, want streamed output", chunks)
+	}
+
+	if _, err := backend.Run(context.Background(), t.TempDir(), "//exit: many\n", RunOptions{}); err == nil {
+		t.Fatal("Run(invalid exit directive) error = nil, want non-nil")
+	}
+}
+
+func TestFakeBackendScriptedResponsesAndTimeout(t *testing.T) {
+	t.Parallel()
+
+	backend := &FakeBackend{Responses: []FakeResponse{
+		{Match: "slow()", Outputs: []FakeOutput{
+			{Stream: StreamStdout, Text: "started\n"},
+			{Stream: StreamStdout, Text: "never\n", Delay: time.Hour},
+		}},
+		{Outputs: []FakeOutput{{Stream: StreamStdout, Text: "default\n"}}},
+	}}
+
+	result, err := backend.Run(context.Background(), t.TempDir(), "func main() { slow() }", RunOptions{Timeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.TimedOut || result.ExitCode != -1 || result.Stdout != "started\n" {
+		t.Fatalf("result = %+v, want timeout after partial output", result)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := backend.Run(ctx, t.TempDir(), "func main() {}", RunOptions{}); err == nil {
+		t.Fatal("Run(canceled context) error = nil, want non-nil")
+	}
+
+	result, err = backend.Run(context.Background(), t.TempDir(), "func main() {}", RunOptions{MaxStdoutBytes: 3})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Stdout != "def" || !result.StdoutTruncated {
+		t.Fatalf("result = %+v, want fallback response capped at 3 bytes", result)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]string{"": BackendGo, "go": BackendGo, " FAKE ": BackendFake} {
+		backend, err := NewBackend(name)
+		if err != nil {
+			t.Fatalf("NewBackend(%q) error = %v", name, err)
+		}
+		if backend.Name() != want {
+			t.Fatalf("NewBackend(%q).Name() = %q, want %q", name, backend.Name(), want)
+		}
+	}
+	if _, err := NewBackend("remote"); err == nil {
+		t.Fatal("NewBackend(remote) error = nil, want non-nil")
+	}
+}
@@ -0,0 +1,88 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopoke/internal/project"
+)
+
+// TestRun selects the tests of one package to run with go test.
+type TestRun struct {
+	// Package is an import path or a directory pattern such as "./store",
+	// relative to the working directory.
+	Package string `json:"package"`
+	// Name runs one test, benchmark, fuzz target or example by its exact
+	// name; empty runs every test of the package.
+	Name    string `json:"name,omitempty"`
+	Verbose bool   `json:"verbose,omitempty"`
+	// CacheMode is a project.TestCacheForce or project.TestCacheUse mode;
+	// empty forces a rerun.
+	CacheMode string `json:"cacheMode,omitempty"`
+}
+
+// testNamePattern matches the names go test accepts as test functions.
+var testNamePattern = regexp.MustCompile(`^(Test|Benchmark|Fuzz|Example)\w*$`)
+
+// RunGoTest runs the tests of a package with go test under the same
+// timeout, capture, cancellation and limits as a snippet run. A failing
+// test is a result with a non-zero exit code, not an error.
+func RunGoTest(ctx context.Context, projectPath string, test TestRun, options RunOptions) (Result, error) {
+	run := func(ctx context.Context, projectPath string, _ string, options RunOptions) (Result, error) {
+		return runGoTest(ctx, projectPath, test, options)
+	}
+	return Chain(run, limitDiskWrites, limitProcesses)(ctx, projectPath, "", options)
+}
+
+func runGoTest(ctx context.Context, projectPath string, test TestRun, options RunOptions) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, fmt.Errorf("run test context: %w", err)
+	}
+	if strings.TrimSpace(projectPath) == "" {
+		return Result{}, fmt.Errorf("project path is required")
+	}
+	args, err := goTestArgs(test)
+	if err != nil {
+		return Result{}, err
+	}
+	absoluteProjectPath, workingDirectory, err := resolveRunPaths(projectPath, options.WorkingDirectory)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result, err := runGoCommand(ctx, absoluteProjectPath, workingDirectory, args, options)
+	if err != nil {
+		return Result{}, err
+	}
+	result.TestCached = project.TestCacheHit(result.Stdout)
+	return result, nil
+}
+
+// goTestArgs builds the go test arguments for test. Benchmarks run alone,
+// with no tests beside them.
+func goTestArgs(test TestRun) ([]string, error) {
+	packagePath := strings.TrimSpace(test.Package)
+	if packagePath == "" {
+		return nil, fmt.Errorf("test package is required")
+	}
+	if strings.HasPrefix(packagePath, "-") {
+		return nil, fmt.Errorf("invalid test package %q", packagePath)
+	}
+	args := append([]string{"test"}, project.TestCacheFlags(test.CacheMode)...)
+	if test.Verbose {
+		args = append(args, "-v")
+	}
+	name := strings.TrimSpace(test.Name)
+	switch {
+	case name == "":
+	case !testNamePattern.MatchString(name):
+		return nil, fmt.Errorf("invalid test name %q", name)
+	case strings.HasPrefix(name, "Benchmark"):
+		args = append(args, "-run", "^$", "-bench", "^"+name+"$")
+	default:
+		args = append(args, "-run", "^"+name+"$")
+	}
+	return append(args, packagePath), nil
+}
@@ -0,0 +1,83 @@
+package execution
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoTestArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		test TestRun
+		want []string
+	}{
+		{TestRun{Package: "./store"}, []string{"test", "-count=1", "./store"}},
+		{TestRun{Package: ".", Name: "TestAdd", Verbose: true, CacheMode: "use"}, []string{"test", "-v", "-run", "^TestAdd$", "."}},
+		{TestRun{Package: ".", Name: "BenchmarkAdd"}, []string{"test", "-count=1", "-run", "^$", "-bench", "^BenchmarkAdd$", "."}},
+	}
+	for _, tt := range tests {
+		got, err := goTestArgs(tt.test)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("goTestArgs(%+v) = %v, %v; want %v", tt.test, got, err, tt.want)
+		}
+	}
+	for _, invalid := range []TestRun{{}, {Package: "-exec=sh"}, {Package: ".", Name: "TestA|TestB"}} {
+		if _, err := goTestArgs(invalid); err == nil {
+			t.Errorf("goTestArgs(%+v) error = nil", invalid)
+		}
+	}
+}
+
+func TestRunGoTestRunsOneTest(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	projectDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":  "module example.com/calc\n\ngo 1.25\n",
+		"calc.go": "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+		"calc_test.go": "package calc\n\nimport \"testing\"\n\n" +
+			"func TestAdd(t *testing.T) {\n\tt.Log(\"adding\")\n}\n\n" +
+			"func TestBroken(t *testing.T) {\n\tt.Fatal(\"broken\")\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	options := RunOptions{
+		Timeout:     time.Minute,
+		Environment: map[string]string{"GOFLAGS": "", "GOWORK": "off"},
+	}
+
+	var streamed strings.Builder
+	passing := options
+	passing.OnStdoutChunk = func(chunk string) { streamed.WriteString(chunk) }
+	result, err := RunGoTest(context.Background(), projectDir, TestRun{Package: ".", Name: "TestAdd", Verbose: true}, passing)
+	if err != nil {
+		t.Fatalf("RunGoTest(TestAdd) error = %v", err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Stdout, "--- PASS: TestAdd") || strings.Contains(result.Stdout, "TestBroken") {
+		t.Fatalf("RunGoTest(TestAdd) = exit %d, stdout %q", result.ExitCode, result.Stdout)
+	}
+	if !strings.Contains(streamed.String(), "adding") {
+		t.Fatalf("streamed stdout = %q, want the test log", streamed.String())
+	}
+
+	result, err = RunGoTest(context.Background(), projectDir, TestRun{Package: "."}, options)
+	if err != nil {
+		t.Fatalf("RunGoTest(package) error = %v", err)
+	}
+	if result.ExitCode == 0 || !strings.Contains(result.Stdout, "--- FAIL: TestBroken") {
+		t.Fatalf("RunGoTest(package) = exit %d, stdout %q", result.ExitCode, result.Stdout)
+	}
+}
@@ -0,0 +1,136 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RunFunc runs one snippet. Backend.Run and RunGoSnippetWithOptions have
+// this shape.
+type RunFunc func(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error)
+
+// Middleware wraps a RunFunc with one execution stage, such as a limit, a
+// sandbox check or logging. A stage may change the context and options it
+// passes to next and the result it returns, and may hook the run's process
+// through options.OnStart.
+type Middleware func(next RunFunc) RunFunc
+
+// Chain wraps run with middleware, the first outermost.
+func Chain(run RunFunc, middleware ...Middleware) RunFunc {
+	for index := len(middleware) - 1; index >= 0; index-- {
+		run = middleware[index](run)
+	}
+	return run
+}
+
+// pipelineStage is one registered Middleware.
+type pipelineStage struct {
+	name       string
+	middleware Middleware
+}
+
+// Pipeline is an ordered set of named execution stages. Stages run in
+// registration order, the first registered outermost. It is safe for
+// concurrent use; runs keep the stages registered when they started.
+type Pipeline struct {
+	mu     sync.RWMutex
+	stages []pipelineStage
+}
+
+// Use registers middleware under name after the existing stages. Names are
+// unique so a stage can be removed again.
+func (p *Pipeline) Use(name string, middleware Middleware) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("stage name is required")
+	}
+	if middleware == nil {
+		return fmt.Errorf("stage %q has no middleware", name)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if slices.ContainsFunc(p.stages, func(stage pipelineStage) bool { return stage.name == name }) {
+		return fmt.Errorf("stage %q is already registered", name)
+	}
+	p.stages = append(p.stages, pipelineStage{name: name, middleware: middleware})
+	return nil
+}
+
+// Remove unregisters the stage called name and reports whether it existed.
+func (p *Pipeline) Remove(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	before := len(p.stages)
+	p.stages = slices.DeleteFunc(p.stages, func(stage pipelineStage) bool { return stage.name == name })
+	return len(p.stages) != before
+}
+
+// Stages lists the registered stage names in run order.
+func (p *Pipeline) Stages() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.stages))
+	for _, stage := range p.stages {
+		names = append(names, stage.name)
+	}
+	return names
+}
+
+// Backend returns backend with the currently registered stages around its
+// Run. Later registrations do not affect the returned backend.
+func (p *Pipeline) Backend(backend Backend) Backend {
+	p.mu.RLock()
+	middleware := make([]Middleware, 0, len(p.stages))
+	for _, stage := range p.stages {
+		middleware = append(middleware, stage.middleware)
+	}
+	p.mu.RUnlock()
+	return pipelineBackend{backend: backend, run: Chain(backend.Run, middleware...)}
+}
+
+// pipelineBackend is a Backend whose runs pass through middleware.
+type pipelineBackend struct {
+	backend Backend
+	run     RunFunc
+}
+
+// Name implements Backend with the wrapped backend's name.
+func (b pipelineBackend) Name() string {
+	return b.backend.Name()
+}
+
+// Run implements Backend.
+func (b pipelineBackend) Run(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error) {
+	return b.run(ctx, projectPath, snippet, options)
+}
+
+// WithStartHook returns options whose OnStart calls hook after the existing
+// callback, for stages that watch the run's process.
+func WithStartHook(options RunOptions, hook func(pid int)) RunOptions {
+	previous := options.OnStart
+	options.OnStart = func(pid int) {
+		if previous != nil {
+			previous(pid)
+		}
+		hook(pid)
+	}
+	return options
+}
+
+// stoppedBy reports whether a run that ended canceled was stopped by a stage
+// canceling ctx with cause, and if so clears Canceled and swaps the
+// placeholder for message.
+func stoppedBy(ctx context.Context, result *Result, cause error, message string) bool {
+	if !result.Canceled || !errors.Is(context.Cause(ctx), cause) {
+		return false
+	}
+	result.Canceled = false
+	if result.Stderr == MessageCanceled {
+		result.Stderr = message
+	}
+	return true
+}
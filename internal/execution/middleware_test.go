@@ -0,0 +1,73 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPipelineRunsStagesInRegistrationOrder(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	stage := func(name string) Middleware {
+		return func(next RunFunc) RunFunc {
+			return func(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error) {
+				calls = append(calls, name+" before")
+				result, err := next(ctx, projectPath, snippet, options)
+				calls = append(calls, name+" after")
+				return result, err
+			}
+		}
+	}
+	var pipeline Pipeline
+	for _, name := range []string{"outer", "inner"} {
+		if err := pipeline.Use(name, stage(name)); err != nil {
+			t.Fatalf("Use(%s) error = %v", name, err)
+		}
+	}
+	if err := pipeline.Use("outer", stage("again")); err == nil {
+		t.Fatal("Use(duplicate) error = nil")
+	}
+	backend := pipeline.Backend(&FakeBackend{})
+	if !pipeline.Remove("inner") || pipeline.Remove("inner") {
+		t.Fatal("Remove(inner) should succeed once")
+	}
+	if got := pipeline.Stages(); !reflect.DeepEqual(got, []string{"outer"}) {
+		t.Fatalf("Stages() = %v", got)
+	}
+
+	result, err := backend.Run(context.Background(), t.TempDir(), "//stdout: hi\n", RunOptions{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Stdout != "hi\n" || backend.Name() != BackendFake {
+		t.Fatalf("Run() = %+v via %s", result, backend.Name())
+	}
+	// The backend was built before inner was removed, so it keeps it.
+	want := []string{"outer before", "inner before", "inner after", "outer after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestStoppedByReportsStageCause(t *testing.T) {
+	t.Parallel()
+
+	errStage := errors.New("stage limit")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errStage)
+
+	result := Result{Canceled: true, ExitCode: -1, Stderr: MessageCanceled}
+	if !stoppedBy(ctx, &result, errStage, "stopped by stage") {
+		t.Fatal("stoppedBy() = false, want true")
+	}
+	if result.Canceled || result.Stderr != "stopped by stage" {
+		t.Fatalf("result = %+v", result)
+	}
+	other := Result{Canceled: true}
+	if stoppedBy(ctx, &other, errDiskQuotaExceeded, MessageDiskQuotaExceeded) || !other.Canceled {
+		t.Fatalf("stoppedBy(other cause) changed %+v", other)
+	}
+}
@@ -0,0 +1,40 @@
+package execution
+
+import (
+	"maps"
+	"os"
+	"strings"
+)
+
+// ModuleOverlay reports a run that used private copies of the project's
+// go.mod and go.sum, so whatever go run changed in them, such as go.sum
+// entries for newly fetched dependencies, stayed out of the project.
+type ModuleOverlay struct {
+	// GoModChanged and GoSumChanged are set when the run changed its
+	// copies; the changes reach the project only when applied.
+	GoModChanged bool `json:"GoModChanged,omitempty"`
+	GoSumChanged bool `json:"GoSumChanged,omitempty"`
+	// Skipped says why the run used the project's module files directly.
+	Skipped string `json:"Skipped,omitempty"`
+}
+
+// modFileEnvironment points the go command at modFile through GOFLAGS,
+// keeping any flags the run or the host already set there. GOFLAGS cannot
+// quote a path with spaces, so such a path is returned as a go run flag
+// instead.
+func modFileEnvironment(environment map[string]string, modFile string) (map[string]string, []string) {
+	flag := "-modfile=" + modFile
+	if strings.ContainsAny(modFile, " \t\n") {
+		return environment, []string{flag}
+	}
+	goFlags, ok := environment["GOFLAGS"]
+	if !ok {
+		goFlags = os.Getenv("GOFLAGS")
+	}
+	overridden := maps.Clone(environment)
+	if overridden == nil {
+		overridden = make(map[string]string, 1)
+	}
+	overridden["GOFLAGS"] = strings.TrimSpace(goFlags + " " + flag)
+	return overridden, nil
+}
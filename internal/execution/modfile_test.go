@@ -0,0 +1,19 @@
+package execution
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestModFileEnvironment(t *testing.T) {
+	environment, flags := modFileEnvironment(map[string]string{"GOFLAGS": "-trimpath"}, "/tmp/overlay/go.mod")
+	if environment["GOFLAGS"] != "-trimpath -modfile=/tmp/overlay/go.mod" || len(flags) != 0 {
+		t.Fatalf("modFileEnvironment() = %v, %v; want -modfile appended to GOFLAGS", environment, flags)
+	}
+
+	original := map[string]string{"GOFLAGS": "-trimpath"}
+	environment, flags = modFileEnvironment(original, "/Application Support/overlay/go.mod")
+	if environment["GOFLAGS"] != "-trimpath" || !slices.Equal(flags, []string{"-modfile=/Application Support/overlay/go.mod"}) {
+		t.Fatalf("modFileEnvironment(spaces) = %v, %v; want a command line flag", environment, flags)
+	}
+}
@@ -0,0 +1,49 @@
+package execution
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRunGoSnippetWithOptionsLowPriority(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	if _, err := exec.LookPath("nice"); err != nil || runtime.GOOS != "linux" {
+		t.Skip("needs nice and /proc")
+	}
+
+	// Field 19 of /proc/self/stat is the niceness; the command name before
+	// it is parenthesized and may contain spaces.
+	snippet := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\t\"strings\"\n)\n\nfunc main() {\n\tstat, _ := os.ReadFile(\"/proc/self/stat\")\n\tfields := strings.Fields(string(stat[strings.LastIndex(string(stat), \")\")+2:]))\n\tfmt.Println(fields[16])\n}\n"
+	niceness := func(lowPriority bool) int {
+		t.Helper()
+		result, err := RunGoSnippetWithOptions(context.Background(), t.TempDir(), snippet, RunOptions{LowPriority: lowPriority})
+		if err != nil {
+			t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+		}
+		if result.ExitCode != 0 {
+			t.Fatalf("ExitCode = %d, stderr = %s", result.ExitCode, result.Stderr)
+		}
+		if result.LowPriority != lowPriority {
+			t.Fatalf("LowPriority = %v, want %v", result.LowPriority, lowPriority)
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(result.Stdout))
+		if err != nil {
+			t.Fatalf("Stdout = %q, want a niceness", result.Stdout)
+		}
+		return value
+	}
+
+	normal := niceness(false)
+	low := niceness(true)
+	if low <= normal && normal < 19 {
+		t.Fatalf("niceness = %d with LowPriority, %d without; want it raised", low, normal)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package execution
+
+import "os/exec"
+
+// lowPriorityNice is the niceness low-priority runs start with.
+const lowPriorityNice = "10"
+
+// lowerPriority makes command start under nice and, where available,
+// ionice's lowest best-effort class. Its children inherit both. It reports
+// whether any priority was lowered.
+func lowerPriority(command *exec.Cmd) bool {
+	if command.Err != nil {
+		return false
+	}
+	lowered := false
+	if nice, err := exec.LookPath("nice"); err == nil {
+		wrapCommand(command, nice, "-n", lowPriorityNice)
+		lowered = true
+	}
+	if ionice, err := exec.LookPath("ionice"); err == nil {
+		wrapCommand(command, ionice, "-c", "2", "-n", "7")
+		lowered = true
+	}
+	return lowered
+}
+
+// wrapCommand makes command run through program with leading args.
+func wrapCommand(command *exec.Cmd, program string, args ...string) {
+	wrapped := append([]string{program}, args...)
+	wrapped = append(wrapped, command.Path)
+	command.Args = append(wrapped, command.Args[1:]...)
+	command.Path = program
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package execution
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// belowNormalPriorityClass is the BELOW_NORMAL_PRIORITY_CLASS process
+// creation flag, which children inherit.
+const belowNormalPriorityClass = 0x00004000
+
+// lowerPriority starts command in the below-normal priority class. It must
+// run after configureCommandForLifecycle.
+func lowerPriority(command *exec.Cmd) bool {
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.CreationFlags |= belowNormalPriorityClass
+	return true
+}
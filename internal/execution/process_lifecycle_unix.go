@@ -4,6 +4,7 @@ package execution
 
 import (
 	"errors"
+	"fmt"
 	"os/exec"
 	"syscall"
 )
@@ -28,6 +29,20 @@ func signalInterrupt(command *exec.Cmd) error {
 	return nil
 }
 
+func signalTerminate(command *exec.Cmd) error {
+	if command == nil || command.Process == nil {
+		return nil
+	}
+	pid := command.Process.Pid
+	if pid <= 0 {
+		return nil
+	}
+	if err := syscall.Kill(-pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return nil
+}
+
 func forceKill(command *exec.Cmd) error {
 	if command == nil || command.Process == nil {
 		return nil
@@ -41,3 +56,27 @@ func forceKill(command *exec.Cmd) error {
 	}
 	return nil
 }
+
+// CanSuspendRuns reports whether SuspendRun works on this platform.
+const CanSuspendRuns = true
+
+// SuspendRun stops every process in the process group of a run whose root
+// is pid, as OnStart reports it, until ResumeRun.
+func SuspendRun(pid int) error {
+	return signalRunGroup(pid, syscall.SIGSTOP)
+}
+
+// ResumeRun continues a run stopped by SuspendRun.
+func ResumeRun(pid int) error {
+	return signalRunGroup(pid, syscall.SIGCONT)
+}
+
+func signalRunGroup(pid int, signal syscall.Signal) error {
+	if pid <= 0 {
+		return fmt.Errorf("invalid pid %d", pid)
+	}
+	if err := syscall.Kill(-pid, signal); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("signal run process group %d: %w", pid, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+//go:build !windows
+
+package execution
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuspendAndResumeRun(t *testing.T) {
+	t.Parallel()
+
+	command := exec.Command("sleep", "30")
+	configureCommandForLifecycle(command)
+	if err := command.Start(); err != nil {
+		t.Skipf("start sleep: %v", err)
+	}
+	defer func() {
+		_ = forceKill(command)
+		_ = command.Wait()
+	}()
+	pid := command.Process.Pid
+
+	if err := SuspendRun(pid); err != nil {
+		t.Fatalf("SuspendRun() error = %v", err)
+	}
+	if runtime.GOOS == "linux" {
+		waitForProcessState(t, pid, "T")
+	}
+	if err := ResumeRun(pid); err != nil {
+		t.Fatalf("ResumeRun() error = %v", err)
+	}
+	if runtime.GOOS == "linux" {
+		waitForProcessState(t, pid, "S")
+	}
+	if err := SuspendRun(0); err == nil {
+		t.Fatal("SuspendRun(0) error = nil")
+	}
+}
+
+// waitForProcessState polls /proc until pid is in state.
+func waitForProcessState(t *testing.T, pid int, state string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+		if err != nil {
+			t.Fatalf("read process stat: %v", err)
+		}
+		// The state follows the parenthesized command name.
+		fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+		if len(fields) > 0 && fields[0] == state {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("process %d state = %v, want %s", pid, fields, state)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
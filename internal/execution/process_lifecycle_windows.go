@@ -26,6 +26,11 @@ func signalInterrupt(command *exec.Cmd) error {
 	return nil
 }
 
+// signalTerminate is unsupported: Windows has no SIGTERM to deliver.
+func signalTerminate(command *exec.Cmd) error {
+	return fmt.Errorf("terminate signal is not supported on windows")
+}
+
 func forceKill(command *exec.Cmd) error {
 	if command == nil || command.Process == nil {
 		return nil
@@ -38,3 +43,16 @@ func forceKill(command *exec.Cmd) error {
 	}
 	return nil
 }
+
+// CanSuspendRuns reports whether SuspendRun works on this platform.
+const CanSuspendRuns = false
+
+// SuspendRun is unsupported: Windows has no job-wide stop signal.
+func SuspendRun(pid int) error {
+	return fmt.Errorf("suspending runs is not supported on windows")
+}
+
+// ResumeRun is unsupported on Windows; see SuspendRun.
+func ResumeRun(pid int) error {
+	return fmt.Errorf("resuming runs is not supported on windows")
+}
@@ -0,0 +1,73 @@
+package execution
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gopoke/internal/procmem"
+)
+
+// MessageProcessLimitExceeded is the stderr note for a run stopped by its
+// child process limit.
+const MessageProcessLimitExceeded = "execution stopped: child process limit exceeded"
+
+// processLimitInterval is how often a run's process tree is counted. Fork
+// bombs grow fast, so it is sampled more often than disk writes.
+const processLimitInterval = 100 * time.Millisecond
+
+// errProcessLimitExceeded is the cancel cause of a run with too many
+// children.
+var errProcessLimitExceeded = errors.New("child process limit exceeded")
+
+// watchProcessLimit counts the descendants of pid until ctx ends and
+// cancels the run with errProcessLimitExceeded once there are more than
+// limit. The go command and the compilers it starts count too. It gives up
+// where the platform cannot list processes.
+func watchProcessLimit(ctx context.Context, pid int, limit int, cancel context.CancelCauseFunc) {
+	ticker := time.NewTicker(processLimitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tree, err := procmem.ProcessTree(pid)
+			if err != nil {
+				return
+			}
+			if len(tree)-1 > limit {
+				cancel(errProcessLimitExceeded)
+				return
+			}
+		}
+	}
+}
+
+// limitProcesses is the stage that stops runs with more than
+// options.MaxProcesses descendant processes.
+func limitProcesses(next RunFunc) RunFunc {
+	return func(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error) {
+		limit := options.MaxProcesses
+		if limit <= 0 {
+			return next(ctx, projectPath, snippet, options)
+		}
+		ctx, stopRun := context.WithCancelCause(ctx)
+		defer stopRun(nil)
+		options = WithStartHook(options, func(pid int) {
+			go watchProcessLimit(ctx, pid, limit, stopRun)
+		})
+		result, err := next(ctx, projectPath, snippet, options)
+		if err == nil && stoppedBy(ctx, &result, errProcessLimitExceeded, MessageProcessLimitExceeded) {
+			result.ProcessLimitExceeded = true
+		}
+		return result, err
+	}
+}
+
+// openFileLimitHit reports whether output shows the run running out of file
+// descriptors.
+func openFileLimitHit(output string) bool {
+	return strings.Contains(output, "too many open files")
+}
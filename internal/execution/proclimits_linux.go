@@ -0,0 +1,24 @@
+//go:build linux
+
+package execution
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// limitOpenFiles makes command start under prlimit with both RLIMIT_NOFILE
+// limits set to limit; the Go runtime raises the soft limit to the hard one,
+// so both must be lowered. It reports false when prlimit is unavailable.
+func limitOpenFiles(command *exec.Cmd, limit int) bool {
+	if command.Err != nil {
+		return false
+	}
+	prlimit, err := exec.LookPath("prlimit")
+	if err != nil {
+		return false
+	}
+	value := strconv.Itoa(limit)
+	wrapCommand(command, prlimit, "--nofile="+value+":"+value, "--")
+	return true
+}
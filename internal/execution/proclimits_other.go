@@ -0,0 +1,10 @@
+//go:build !linux
+
+package execution
+
+import "os/exec"
+
+// limitOpenFiles is unsupported on this platform and always reports false.
+func limitOpenFiles(command *exec.Cmd, limit int) bool {
+	return false
+}
@@ -0,0 +1,65 @@
+package execution
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunGoSnippetWithOptionsStopsAtProcessLimit(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("process trees are not listed on windows")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	snippet := "package main\n\nimport (\n\t\"fmt\"\n\t\"os/exec\"\n\t\"time\"\n)\n\nfunc main() {\n\tfmt.Println(\"spawning\")\n\tfor i := 0; i < 200; i++ {\n\t\texec.Command(\"sleep\", \"30\").Start()\n\t\ttime.Sleep(5 * time.Millisecond)\n\t}\n\ttime.Sleep(30 * time.Second)\n}\n"
+	result, err := RunGoSnippetWithOptions(context.Background(), t.TempDir(), snippet, RunOptions{
+		Timeout:      time.Minute,
+		MaxProcesses: 64,
+	})
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	if !result.ProcessLimitExceeded || result.ExitCode != -1 {
+		t.Fatalf("result = exit %d, process limit exceeded %v; want the run stopped", result.ExitCode, result.ProcessLimitExceeded)
+	}
+	if result.Limits.MaxProcesses != 64 {
+		t.Fatalf("Limits.MaxProcesses = %d", result.Limits.MaxProcesses)
+	}
+	if !strings.Contains(result.Stdout, "spawning") {
+		t.Fatalf("Stdout = %q", result.Stdout)
+	}
+}
+
+func TestRunGoSnippetWithOptionsLimitsOpenFiles(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS != "linux" {
+		t.Skip("open file limits are applied on linux only")
+	}
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		t.Skip("prlimit not available")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	snippet := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() {\n\tvar files []*os.File\n\tfor i := 0; i < 2048; i++ {\n\t\tfile, err := os.Open(os.DevNull)\n\t\tif err != nil {\n\t\t\tfmt.Println(err)\n\t\t\tos.Exit(1)\n\t\t}\n\t\tfiles = append(files, file)\n\t}\n\tfmt.Println(\"opened\", len(files))\n}\n"
+	result, err := RunGoSnippetWithOptions(context.Background(), t.TempDir(), snippet, RunOptions{
+		Timeout:      time.Minute,
+		MaxOpenFiles: 256,
+	})
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	if !result.OpenFileLimitHit || result.ExitCode == 0 {
+		t.Fatalf("result = exit %d, open file limit hit %v, stdout %q, stderr %q", result.ExitCode, result.OpenFileLimitHit, result.Stdout, result.Stderr)
+	}
+}
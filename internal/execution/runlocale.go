@@ -0,0 +1,142 @@
+package execution
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TimeZoneOption is one entry of the run time zone picker.
+type TimeZoneOption struct {
+	Name string `json:"name"`
+	// Offset is the current UTC offset such as "+05:45", empty when the
+	// zone database is unavailable.
+	Offset string `json:"offset,omitempty"`
+}
+
+// LocaleOption is one entry of the run locale picker.
+type LocaleOption struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// pickerTimeZones mixes common zones with ones that catch offset bugs:
+// half- and quarter-hour offsets, the extremes of the date line and a
+// 30-minute daylight saving shift.
+var pickerTimeZones = []string{
+	"UTC",
+	"America/Los_Angeles",
+	"America/Denver",
+	"America/Chicago",
+	"America/New_York",
+	"America/Sao_Paulo",
+	"America/St_Johns",
+	"Europe/London",
+	"Europe/Berlin",
+	"Europe/Istanbul",
+	"Europe/Moscow",
+	"Asia/Dubai",
+	"Asia/Tehran",
+	"Asia/Kolkata",
+	"Asia/Kathmandu",
+	"Asia/Shanghai",
+	"Asia/Tokyo",
+	"Australia/Adelaide",
+	"Australia/Sydney",
+	"Australia/Lord_Howe",
+	"Pacific/Auckland",
+	"Pacific/Chatham",
+	"Pacific/Kiritimati",
+	"Pacific/Pago_Pago",
+}
+
+// pickerLocales mixes common locales with ones that catch formatting and
+// case-mapping bugs.
+var pickerLocales = []LocaleOption{
+	{Name: "C", Label: "C (POSIX)"},
+	{Name: "en_US.UTF-8", Label: "English (United States)"},
+	{Name: "en_GB.UTF-8", Label: "English (United Kingdom)"},
+	{Name: "de_DE.UTF-8", Label: "German (Germany)"},
+	{Name: "fr_FR.UTF-8", Label: "French (France)"},
+	{Name: "es_ES.UTF-8", Label: "Spanish (Spain)"},
+	{Name: "pt_BR.UTF-8", Label: "Portuguese (Brazil)"},
+	{Name: "tr_TR.UTF-8", Label: "Turkish (Turkey)"},
+	{Name: "ru_RU.UTF-8", Label: "Russian (Russia)"},
+	{Name: "ar_SA.UTF-8", Label: "Arabic (Saudi Arabia)"},
+	{Name: "hi_IN.UTF-8", Label: "Hindi (India)"},
+	{Name: "ja_JP.UTF-8", Label: "Japanese (Japan)"},
+	{Name: "zh_CN.UTF-8", Label: "Chinese (China)"},
+}
+
+var localePattern = regexp.MustCompile(`^(C|POSIX|[a-z]{2,3}(_[A-Z]{2}|_[0-9]{3})?)(\.[A-Za-z0-9-]+)?(@[A-Za-z0-9]+)?$`)
+
+// TimeZones lists the time zones offered for runs with their current
+// offsets.
+func TimeZones() []TimeZoneOption {
+	now := time.Now()
+	options := make([]TimeZoneOption, 0, len(pickerTimeZones))
+	for _, name := range pickerTimeZones {
+		option := TimeZoneOption{Name: name}
+		if location, err := time.LoadLocation(name); err == nil {
+			option.Offset = now.In(location).Format("-07:00")
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// Locales lists the locales offered for runs.
+func Locales() []LocaleOption {
+	return append([]LocaleOption(nil), pickerLocales...)
+}
+
+// ValidateTimeZone accepts an empty name or an IANA zone known to the zone
+// database. "Local" is rejected because it means the host's zone.
+func ValidateTimeZone(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	if name == "Local" {
+		return fmt.Errorf("time zone must name a zone, not Local")
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return fmt.Errorf("unknown time zone %q", name)
+	}
+	return nil
+}
+
+// ValidateLocale accepts an empty name, C, POSIX or a language_TERRITORY
+// locale with an optional codeset and modifier, such as de_DE.UTF-8.
+func ValidateLocale(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || localePattern.MatchString(name) {
+		return nil
+	}
+	return fmt.Errorf("invalid locale %q", name)
+}
+
+// localeEnvironment returns environment with the run's time zone and
+// locale applied. TZ is read by Go's time package; LANG and LC_ALL by
+// programs and C libraries the snippet calls. The input map is not
+// modified.
+func localeEnvironment(environment map[string]string, timeZone string, locale string) map[string]string {
+	timeZone = strings.TrimSpace(timeZone)
+	locale = strings.TrimSpace(locale)
+	if timeZone == "" && locale == "" {
+		return environment
+	}
+	merged := make(map[string]string, len(environment)+3)
+	for key, value := range environment {
+		merged[key] = value
+	}
+	if timeZone != "" {
+		merged["TZ"] = timeZone
+	}
+	if locale != "" {
+		merged["LANG"] = locale
+		merged["LC_ALL"] = locale
+	}
+	return merged
+}
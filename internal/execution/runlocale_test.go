@@ -0,0 +1,76 @@
+package execution
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTimeZone(t *testing.T) {
+	t.Parallel()
+
+	if _, err := time.LoadLocation("Asia/Kathmandu"); err != nil {
+		t.Skip("time zone database not available")
+	}
+	for _, name := range []string{"", "UTC", "Asia/Kathmandu", " Europe/Berlin "} {
+		if err := ValidateTimeZone(name); err != nil {
+			t.Errorf("ValidateTimeZone(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"Local", "Mars/Olympus_Mons", "../etc/passwd"} {
+		if err := ValidateTimeZone(name); err == nil {
+			t.Errorf("ValidateTimeZone(%q) error = nil, want error", name)
+		}
+	}
+}
+
+func TestValidateLocale(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", "C", "POSIX", "de_DE", "tr_TR.UTF-8", "sr_RS.UTF-8@latin", "es_419.UTF-8"} {
+		if err := ValidateLocale(name); err != nil {
+			t.Errorf("ValidateLocale(%q) error = %v", name, err)
+		}
+	}
+	for _, name := range []string{"english", "de-DE", "en_US.UTF-8; rm -rf /", "en_US UTF-8"} {
+		if err := ValidateLocale(name); err == nil {
+			t.Errorf("ValidateLocale(%q) error = nil, want error", name)
+		}
+	}
+}
+
+func TestPickerListsAreValid(t *testing.T) {
+	t.Parallel()
+
+	for _, option := range Locales() {
+		if err := ValidateLocale(option.Name); err != nil {
+			t.Errorf("Locales() entry %q: %v", option.Name, err)
+		}
+	}
+	zones := TimeZones()
+	if len(zones) == 0 || zones[0].Name != "UTC" {
+		t.Fatalf("TimeZones() = %v, want UTC first", zones)
+	}
+	if zones[0].Offset != "" && zones[0].Offset != "+00:00" {
+		t.Fatalf("UTC offset = %q, want +00:00", zones[0].Offset)
+	}
+}
+
+func TestLocaleEnvironment(t *testing.T) {
+	t.Parallel()
+
+	base := map[string]string{"TZ": "America/New_York", "FOO": "bar"}
+	if got := localeEnvironment(base, "", " "); len(got) != 2 || got["TZ"] != "America/New_York" {
+		t.Fatalf("localeEnvironment() without overrides = %v", got)
+	}
+
+	got := localeEnvironment(base, "Asia/Kathmandu", "tr_TR.UTF-8")
+	want := map[string]string{"TZ": "Asia/Kathmandu", "LANG": "tr_TR.UTF-8", "LC_ALL": "tr_TR.UTF-8", "FOO": "bar"}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+	if base["TZ"] != "America/New_York" {
+		t.Fatalf("input environment modified: %v", base)
+	}
+}
@@ -0,0 +1,119 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Shutdown signals ask a canceled or timed-out run to stop before it is
+// force killed.
+const (
+	// ShutdownInterrupt sends SIGINT to the run's process group. It is the
+	// default.
+	ShutdownInterrupt = "interrupt"
+	// ShutdownTerminate sends SIGTERM, which container runtimes and process
+	// managers use; Windows falls back to an interrupt.
+	ShutdownTerminate = "terminate"
+	// ShutdownHTTP posts to a loopback URL the snippet serves, falling back
+	// to an interrupt when the request fails.
+	ShutdownHTTP = "http"
+)
+
+// MaxShutdownGrace bounds how long a run may take to stop after its
+// shutdown signal before it is force killed.
+const MaxShutdownGrace = 30 * time.Second
+
+// Shutdown is how a run is asked to stop.
+type Shutdown struct {
+	Signal string
+	// URL is the endpoint ShutdownHTTP posts to.
+	URL string
+}
+
+// NormalizeShutdown validates a shutdown preference. An empty signal means
+// ShutdownInterrupt; ShutdownHTTP needs an http or https URL on a loopback
+// host, so a run cannot be made to call out to other machines.
+func NormalizeShutdown(signal string, rawURL string) (Shutdown, error) {
+	signal = strings.ToLower(strings.TrimSpace(signal))
+	rawURL = strings.TrimSpace(rawURL)
+	switch signal {
+	case "", ShutdownInterrupt:
+		return Shutdown{Signal: ShutdownInterrupt}, nil
+	case ShutdownTerminate:
+		return Shutdown{Signal: ShutdownTerminate}, nil
+	case ShutdownHTTP:
+	default:
+		return Shutdown{}, fmt.Errorf("unsupported shutdown signal %q", signal)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || rawURL == "" {
+		return Shutdown{}, fmt.Errorf("shutdown URL is required for the http shutdown signal")
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return Shutdown{}, fmt.Errorf("shutdown URL must use http or https")
+	}
+	if !isLoopbackHost(parsed.Hostname()) {
+		return Shutdown{}, fmt.Errorf("shutdown URL must point at localhost")
+	}
+	return Shutdown{Signal: ShutdownHTTP, URL: parsed.String()}, nil
+}
+
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requestShutdown asks command to stop as shutdown describes. The HTTP call
+// runs in the background so it cannot delay the forced kill; it falls back
+// to an interrupt when it fails.
+func requestShutdown(command *exec.Cmd, shutdown Shutdown, grace time.Duration) {
+	switch shutdown.Signal {
+	case ShutdownTerminate:
+		if err := signalTerminate(command); err != nil {
+			_ = signalInterrupt(command)
+		}
+	case ShutdownHTTP:
+		go func() {
+			if err := postShutdown(shutdown.URL, grace); err != nil {
+				_ = signalInterrupt(command)
+			}
+		}()
+	default:
+		_ = signalInterrupt(command)
+	}
+}
+
+// shutdownClient does not follow redirects, which could lead off the
+// loopback host.
+var shutdownClient = &http.Client{
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func postShutdown(endpoint string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("build shutdown request: %w", err)
+	}
+	response, err := shutdownClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("call shutdown URL: %w", err)
+	}
+	response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("shutdown URL returned %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package execution
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNormalizeShutdown(t *testing.T) {
+	t.Parallel()
+
+	valid := []struct {
+		signal string
+		url    string
+		want   Shutdown
+	}{
+		{"", "", Shutdown{Signal: ShutdownInterrupt}},
+		{" TERMINATE ", "", Shutdown{Signal: ShutdownTerminate}},
+		{"http", "http://localhost:8080/shutdown", Shutdown{Signal: ShutdownHTTP, URL: "http://localhost:8080/shutdown"}},
+		{"http", "https://[::1]:8443/quit", Shutdown{Signal: ShutdownHTTP, URL: "https://[::1]:8443/quit"}},
+	}
+	for _, tc := range valid {
+		got, err := NormalizeShutdown(tc.signal, tc.url)
+		if err != nil || got != tc.want {
+			t.Fatalf("NormalizeShutdown(%q, %q) = %+v, %v; want %+v", tc.signal, tc.url, got, err, tc.want)
+		}
+	}
+	for _, tc := range [][2]string{
+		{"kill", ""},
+		{"http", ""},
+		{"http", "ftp://localhost/quit"},
+		{"http", "http://example.com/shutdown"},
+		{"http", "http://10.0.0.1/shutdown"},
+	} {
+		if _, err := NormalizeShutdown(tc[0], tc[1]); err == nil {
+			t.Fatalf("NormalizeShutdown(%q, %q) error = nil, want error", tc[0], tc[1])
+		}
+	}
+}
+
+// runUntilReady runs snippet and cancels it once it prints "ready".
+func runUntilReady(t *testing.T, snippet string, options RunOptions) Result {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	var once sync.Once
+	options.Timeout = time.Minute
+	options.KillGracePeriod = 5 * time.Second
+	options.OnStdoutChunk = func(chunk string) {
+		if strings.Contains(chunk, "ready") {
+			once.Do(cancel)
+		}
+	}
+	result, err := RunGoSnippetWithOptions(ctx, t.TempDir(), snippet, options)
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	return result
+}
+
+func TestRunGoSnippetWithOptionsShutdownTerminate(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGTERM is not delivered on windows")
+	}
+
+	snippet := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\t\"os/signal\"\n\t\"syscall\"\n)\n\nfunc main() {\n\tsignals := make(chan os.Signal, 1)\n\tsignal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)\n\tfmt.Println(\"ready\")\n\tfmt.Println(\"got\", <-signals)\n}\n"
+	result := runUntilReady(t, snippet, RunOptions{Shutdown: Shutdown{Signal: ShutdownTerminate}})
+	if !strings.Contains(result.Stdout, "got terminated") {
+		t.Fatalf("Stdout = %q, want the snippet to see SIGTERM", result.Stdout)
+	}
+}
+
+func TestRunGoSnippetWithOptionsShutdownHTTP(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserve port: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	snippet := "package main\n\nimport (\n\t\"context\"\n\t\"fmt\"\n\t\"net\"\n\t\"net/http\"\n\t\"os\"\n)\n\nfunc main() {\n\tserver := &http.Server{}\n\thttp.HandleFunc(\"/shutdown\", func(w http.ResponseWriter, r *http.Request) {\n\t\tgo server.Shutdown(context.Background())\n\t})\n\tlistener, err := net.Listen(\"tcp\", os.Getenv(\"ADDR\"))\n\tif err != nil {\n\t\tpanic(err)\n\t}\n\tfmt.Println(\"ready\")\n\tfmt.Println(server.Serve(listener))\n}\n"
+	result := runUntilReady(t, snippet, RunOptions{
+		Environment: map[string]string{"ADDR": address},
+		Shutdown:    Shutdown{Signal: ShutdownHTTP, URL: "http://" + address + "/shutdown"},
+	})
+	if !strings.Contains(result.Stdout, "http: Server closed") {
+		t.Fatalf("Stdout = %q, want a graceful server shutdown", result.Stdout)
+	}
+}
@@ -8,17 +8,39 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"gopoke/internal/benchsnippet"
+	"gopoke/internal/faults"
+	"gopoke/internal/golden"
+	"gopoke/internal/highlight"
+	"gopoke/internal/outputfold"
+	"gopoke/internal/project"
+	"gopoke/internal/runguard"
+	"gopoke/internal/textenc"
 )
 
 // DefaultTimeout limits snippet run duration for MVP safety.
 const DefaultTimeout = 15 * time.Second
 
+// RunCacheDirName is the per-project directory holding generated snippet files.
+const RunCacheDirName = ".gopoke-run-cache"
+
+// runCacheDir returns cacheDir, or the project's RunCacheDirName when it is
+// empty.
+func runCacheDir(projectPath string, cacheDir string) string {
+	if cacheDir = strings.TrimSpace(cacheDir); cacheDir != "" {
+		return cacheDir
+	}
+	return filepath.Join(projectPath, RunCacheDirName)
+}
+
 const (
 	// DefaultMaxOutputBytes caps stdout and stderr captured for one run.
 	DefaultMaxOutputBytes = 128 * 1024
@@ -26,6 +48,13 @@ const (
 	defaultKillGracePeriod = 400 * time.Millisecond
 )
 
+const (
+	// MessageTimedOut is the stderr placeholder for a timed-out run with no output.
+	MessageTimedOut = "execution timed out"
+	// MessageCanceled is the stderr placeholder for a canceled run with no output.
+	MessageCanceled = "execution canceled"
+)
+
 // RunRequest captures user-provided input for one snippet execution.
 type RunRequest struct {
 	RunID       string `json:"runId"`
@@ -33,6 +62,66 @@ type RunRequest struct {
 	PackagePath string `json:"packagePath"`
 	Source      string `json:"source"`
 	TimeoutMS   int64  `json:"timeoutMs"`
+	// TeeToFile mirrors full, untruncated output to a file inside the project
+	// or its artifacts directory. Relative paths resolve under artifacts.
+	TeeToFile string `json:"teeToFile,omitempty"`
+	// Experiments overrides the project's GOEXPERIMENT selection when non-nil;
+	// an empty slice disables all experiments for this run.
+	Experiments []string `json:"experiments,omitempty"`
+	// ConfirmDestructive runs a snippet the pre-run scan flagged under the
+	// confirm policy.
+	ConfirmDestructive bool `json:"confirmDestructive,omitempty"`
+	// TimeZone and Locale override TZ and LANG/LC_ALL for this run only.
+	TimeZone string `json:"timeZone,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	// Seed and FrozenTime (RFC 3339) make time- and rand-dependent
+	// snippets reproducible; see EnvSeed and EnvFrozenTime.
+	Seed       *int64 `json:"seed,omitempty"`
+	FrozenTime string `json:"frozenTime,omitempty"`
+	// ExportDeadline exposes the run's timeout moment as EnvDeadline so
+	// the snippet can wind down before it is interrupted.
+	ExportDeadline bool `json:"exportDeadline,omitempty"`
+	// ShutdownSignal is how a canceled or timed-out run is asked to stop
+	// (see ShutdownInterrupt, ShutdownTerminate and ShutdownHTTP), and
+	// ShutdownGraceMS how long it gets before it is killed; zero keeps the
+	// default grace period.
+	ShutdownSignal  string `json:"shutdownSignal,omitempty"`
+	ShutdownURL     string `json:"shutdownUrl,omitempty"`
+	ShutdownGraceMS int64  `json:"shutdownGraceMs,omitempty"`
+	// CPUAffinity lists the CPU indexes the run may use; empty leaves
+	// scheduling alone.
+	CPUAffinity []int `json:"cpuAffinity,omitempty"`
+	// LowPriority runs the snippet at reduced CPU and I/O priority; nil
+	// follows the LowPriorityRuns setting.
+	LowPriority *bool `json:"lowPriority,omitempty"`
+	// MaxDiskWriteBytes overrides the global disk write quota for this run.
+	MaxDiskWriteBytes int64 `json:"maxDiskWriteBytes,omitempty"`
+	// MaxProcesses and MaxOpenFiles override the global child process and
+	// file descriptor limits for this run.
+	MaxProcesses int64 `json:"maxProcesses,omitempty"`
+	MaxOpenFiles int64 `json:"maxOpenFiles,omitempty"`
+	// Params are values for the parameters the snippet declares with
+	// //gopoke:param comments.
+	Params map[string]string `json:"params,omitempty"`
+	// Env sets environment variables for this run only, over the project's
+	// variables and those the snippet declares.
+	Env map[string]string `json:"env,omitempty"`
+	// SnippetID names the saved snippet being run, if any, in run
+	// notifications.
+	SnippetID string `json:"snippetId,omitempty"`
+	// Golden compares the output with the golden snapshot of snippet
+	// SnippetID, recording the snapshot if the snippet has none.
+	Golden bool `json:"golden,omitempty"`
+	// IgnoreModuleWarning runs a project snippet even when go.sum
+	// verification found problems.
+	IgnoreModuleWarning bool `json:"ignoreModuleWarning,omitempty"`
+	// RefreshCache runs a cacheable snippet even when a cached result
+	// exists, replacing it.
+	RefreshCache bool `json:"refreshCache,omitempty"`
+	// Files are project-relative Go files of one main package compiled
+	// with the snippet, which replaces the package's main; see
+	// project.RunTarget.Files.
+	Files []string `json:"files,omitempty"`
 }
 
 // StdoutChunkHandler receives incremental stdout chunks while a run is active.
@@ -52,15 +141,80 @@ type RunOptions struct {
 	MaxStdoutBytes   int
 	MaxStderrBytes   int
 	KillGracePeriod  time.Duration
+	// Shutdown is how the run is asked to stop before KillGracePeriod
+	// runs out; the zero value interrupts it.
+	Shutdown Shutdown
+	// OutputDrainTimeout bounds how long output is still read after the
+	// process exits, for processes it left holding stdout or stderr; zero
+	// uses a default of two seconds.
+	OutputDrainTimeout time.Duration
+	// Tee receives all stdout and stderr bytes, interleaved and uncapped.
+	// Write errors are reported in Result.TeeError and never fail the run.
+	Tee io.Writer
+	// OnStart receives the pid of the started process. Backends that do not
+	// start a process never call it.
+	OnStart func(pid int)
+	// OutputEncoding is the textenc encoding of program output; empty
+	// detects it.
+	OutputEncoding string
+	// TimeZone is an IANA zone set as TZ for the run; empty inherits the
+	// host's.
+	TimeZone string
+	// Locale is set as LANG and LC_ALL for the run; empty inherits the
+	// host's.
+	Locale string
+	// Seed is exposed as EnvSeed and makes the top-level math/rand
+	// functions deterministic; nil leaves randomness alone.
+	Seed *int64
+	// FrozenTime is exposed as EnvFrozenTime; the zero time sets nothing.
+	FrozenTime time.Time
+	// ExportDeadline sets EnvDeadline to the moment the run times out.
+	ExportDeadline bool
+	// CPUAffinity pins the run to these CPU indexes where the platform
+	// allows it, and sets GOMAXPROCS to match unless Environment sets it.
+	CPUAffinity []int
+	// LowPriority runs the process, and the build before it, at reduced
+	// CPU and I/O priority.
+	LowPriority bool
+	// MaxDiskWriteBytes stops the run once it has written more than this
+	// many bytes to disk; zero means no quota.
+	MaxDiskWriteBytes int64
+	// MaxProcesses stops the run once it has more descendant processes
+	// than this, and MaxOpenFiles caps each process's file descriptors
+	// where the platform allows it. Zero means no limit.
+	MaxProcesses int
+	MaxOpenFiles int
+	// Args are passed to the program after the snippet file.
+	Args []string
+	// Files are absolute paths of Go files in one directory built with the
+	// snippet. The snippet is then written next to them for the run, since
+	// go run needs its files in one directory.
+	Files []string
+	// ModFile is a copy of the project's go.mod the run uses in its place
+	// through -modfile; go.sum is read and written next to it.
+	ModFile string
+	// CacheDir holds the generated snippet file. Empty uses RunCacheDirName
+	// inside the project.
+	CacheDir string
 }
 
 // Diagnostic contains one parsed compiler/runtime mapping from run output.
 type Diagnostic struct {
-	Kind    string
-	File    string
-	Line    int
-	Column  int
-	Message string
+	Kind        string
+	File        string
+	Line        int
+	Column      int
+	Message     string
+	Explanation *DiagnosticExplanation
+}
+
+// DiagnosticExplanation is a curated, localized explanation of a compiler
+// error with a suggested fix.
+type DiagnosticExplanation struct {
+	ID     string
+	Title  string
+	Detail string
+	Fix    string
 }
 
 // RichBlock mirrors richoutput.RichBlock for JSON serialization to the frontend.
@@ -71,17 +225,117 @@ type RichBlock struct {
 
 // Result contains one snippet execution outcome.
 type Result struct {
-	Stdout          string       `json:"Stdout"`
-	Stderr          string       `json:"Stderr"`
-	ExitCode        int          `json:"ExitCode"`
-	DurationMS      int64        `json:"DurationMS"`
-	TimedOut        bool         `json:"TimedOut"`
-	Canceled        bool         `json:"Canceled"`
-	StdoutTruncated bool         `json:"StdoutTruncated"`
-	StderrTruncated bool         `json:"StderrTruncated"`
-	Diagnostics     []Diagnostic `json:"Diagnostics"`
-	CleanStdout     string       `json:"CleanStdout,omitempty"`
-	RichBlocks      []RichBlock  `json:"RichBlocks,omitempty"`
+	Stdout             string       `json:"Stdout"`
+	Stderr             string       `json:"Stderr"`
+	ExitCode           int          `json:"ExitCode"`
+	DurationMS         int64        `json:"DurationMS"`
+	TimedOut           bool         `json:"TimedOut"`
+	Canceled           bool         `json:"Canceled"`
+	StdoutTruncated    bool         `json:"StdoutTruncated"`
+	StderrTruncated    bool         `json:"StderrTruncated"`
+	Diagnostics        []Diagnostic `json:"Diagnostics"`
+	DiagnosticsSummary string       `json:"DiagnosticsSummary,omitempty"`
+	CleanStdout        string       `json:"CleanStdout,omitempty"`
+	RichBlocks         []RichBlock  `json:"RichBlocks,omitempty"`
+	// StdoutHighlights mark CleanStdout and StderrHighlights mark Stderr by
+	// the project's output highlight rules.
+	StdoutHighlights []highlight.Span `json:"StdoutHighlights,omitempty"`
+	StderrHighlights []highlight.Span `json:"StderrHighlights,omitempty"`
+	// StdoutFolds and StderrFolds are foldable repeated lines and framework
+	// stack frames in CleanStdout and Stderr.
+	StdoutFolds []outputfold.Fold `json:"StdoutFolds,omitempty"`
+	StderrFolds []outputfold.Fold `json:"StderrFolds,omitempty"`
+	// Benchmarks annotate the benchmark functions of a benchmark snippet
+	// with their results.
+	Benchmarks []benchsnippet.Annotation `json:"Benchmarks,omitempty"`
+	// Golden is how the output compared with the snippet's golden snapshot,
+	// for runs that asked for the comparison.
+	Golden *golden.Check `json:"Golden,omitempty"`
+	// PlainText marks results produced in accessible plain-text mode; the UI
+	// renders output verbatim without ANSI or rich-block processing.
+	PlainText bool `json:"PlainText,omitempty"`
+	// Limits are the timeout and output caps this run was held to.
+	Limits RunLimits `json:"Limits"`
+	// TeeFile is where full output was mirrored; TeeError is set if mirroring failed.
+	TeeFile  string `json:"TeeFile,omitempty"`
+	TeeError string `json:"TeeError,omitempty"`
+	// StdoutEncoding is the encoding stdout was decoded from, detected unless
+	// the run set one.
+	StdoutEncoding string `json:"StdoutEncoding,omitempty"`
+	// RawStdout holds the captured stdout bytes when they were decoded as
+	// binary, for rendering a hexdump.
+	RawStdout []byte `json:"-"`
+	// GuardFindings lists destructive-looking calls found by the pre-run
+	// scan.
+	GuardFindings []runguard.Finding `json:"GuardFindings,omitempty"`
+	// ModuleWarning lists go.sum inconsistencies found before the run.
+	ModuleWarning *project.SumWarning `json:"ModuleWarning,omitempty"`
+	// ConfirmationRequired is set when the run was held back until the user
+	// confirms GuardFindings or resolves ModuleWarning; nothing was executed.
+	ConfirmationRequired bool `json:"ConfirmationRequired,omitempty"`
+	// Cached is set when the result was replayed from the run cache instead
+	// of running the snippet again.
+	Cached bool `json:"Cached,omitempty"`
+	// TestCached is set when a test run reported a package result that go
+	// test replayed from its test cache.
+	TestCached bool `json:"TestCached,omitempty"`
+	// CPUAffinity is the CPU set the run was pinned to; empty when it was
+	// not pinned.
+	CPUAffinity []int `json:"CPUAffinity,omitempty"`
+	// LowPriority is set when the run's priority was lowered.
+	LowPriority bool `json:"LowPriority,omitempty"`
+	// DiskQuotaExceeded is set when the run was stopped for writing more
+	// than Limits.MaxDiskWriteBytes to disk.
+	DiskQuotaExceeded bool `json:"DiskQuotaExceeded,omitempty"`
+	// ProcessLimitExceeded is set when the run was stopped for starting
+	// more than Limits.MaxProcesses processes.
+	ProcessLimitExceeded bool `json:"ProcessLimitExceeded,omitempty"`
+	// OpenFileLimitHit is set when the run reported running out of file
+	// descriptors under Limits.MaxOpenFiles.
+	OpenFileLimitHit bool `json:"OpenFileLimitHit,omitempty"`
+	// DrainedBytes counts the output captured after the run was canceled or
+	// timed out, such as what a signal handler printed on its way out.
+	DrainedBytes int64 `json:"DrainedBytes,omitempty"`
+	// OutputIncomplete is set when processes the run left behind still held
+	// its output open once the drain bound ran out; output they wrote later
+	// is missing.
+	OutputIncomplete bool `json:"OutputIncomplete,omitempty"`
+	// NetworkDenied is set when the user denied the run's network access at
+	// a permission prompt, which stopped it.
+	NetworkDenied bool `json:"NetworkDenied,omitempty"`
+	// ModuleOverlay reports the run's private copy of the project's module
+	// files when it changed them or could not use one.
+	ModuleOverlay *ModuleOverlay `json:"ModuleOverlay,omitempty"`
+}
+
+// Run limit sources reported in RunLimits.
+const (
+	LimitSourceDefault = "default"
+	LimitSourceGlobal  = "global"
+	LimitSourceProject = "project"
+	// LimitSourceSnippet is a limit the snippet declares in its source.
+	LimitSourceSnippet = "snippet"
+	LimitSourceRequest = "request"
+)
+
+// RunLimits describes the effective limits of one run and where each came from.
+type RunLimits struct {
+	TimeoutMS      int64  `json:"TimeoutMS"`
+	TimeoutSource  string `json:"TimeoutSource,omitempty"`
+	MaxOutputBytes int64  `json:"MaxOutputBytes"`
+	OutputSource   string `json:"OutputSource,omitempty"`
+	// SlowFilesystem is set when a default or global timeout was extended
+	// because the project lives on a network or slow filesystem.
+	SlowFilesystem bool `json:"SlowFilesystem,omitempty"`
+	// MaxDiskWriteBytes is the run's disk write quota; zero means none.
+	MaxDiskWriteBytes int64  `json:"MaxDiskWriteBytes,omitempty"`
+	DiskWriteSource   string `json:"DiskWriteSource,omitempty"`
+	// MaxProcesses and MaxOpenFiles are the run's child process and file
+	// descriptor limits; zero means none.
+	MaxProcesses    int64  `json:"MaxProcesses,omitempty"`
+	ProcessSource   string `json:"ProcessSource,omitempty"`
+	MaxOpenFiles    int64  `json:"MaxOpenFiles,omitempty"`
+	OpenFilesSource string `json:"OpenFilesSource,omitempty"`
 }
 
 // RunGoSnippet executes a Go snippet with `go run` in the selected project context.
@@ -93,6 +347,11 @@ func RunGoSnippet(ctx context.Context, projectPath string, snippet string, timeo
 
 // RunGoSnippetWithOptions executes a Go snippet with explicit working directory and env values.
 func RunGoSnippetWithOptions(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error) {
+	return Chain(runGoSnippet, limitDiskWrites, limitProcesses)(ctx, projectPath, snippet, options)
+}
+
+// runGoSnippet is RunGoSnippetWithOptions without its limit stages.
+func runGoSnippet(ctx context.Context, projectPath string, snippet string, options RunOptions) (Result, error) {
 	if err := ctx.Err(); err != nil {
 		return Result{}, fmt.Errorf("run snippet context: %w", err)
 	}
@@ -103,55 +362,74 @@ func RunGoSnippetWithOptions(ctx context.Context, projectPath string, snippet st
 		return Result{}, fmt.Errorf("snippet is required")
 	}
 
+	absoluteProjectPath, workingDirectory, err := resolveRunPaths(projectPath, options.WorkingDirectory)
+	if err != nil {
+		return Result{}, err
+	}
+
+	cacheDir := runCacheDir(absoluteProjectPath, options.CacheDir)
+	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
+		return Result{}, fmt.Errorf("create run cache dir: %w", err)
+	}
+
+	filePath, err := stableSnippetFilePath(cacheDir, snippet)
+	if err != nil {
+		return Result{}, fmt.Errorf("resolve snippet cache path: %w", err)
+	}
+	if len(options.Files) > 0 {
+		filePath, err = packageSnippetFilePath(options.Files, snippet)
+		if err != nil {
+			return Result{}, err
+		}
+		defer os.Remove(filePath)
+	}
+	if err := os.WriteFile(filePath, []byte(snippet), 0o600); err != nil {
+		return Result{}, fmt.Errorf("write snippet file: %w", err)
+	}
+
+	runArgs := []string{"run"}
+	if options.ModFile != "" {
+		var flags []string
+		options.Environment, flags = modFileEnvironment(options.Environment, options.ModFile)
+		runArgs = append(runArgs, flags...)
+	}
+	runArgs = append(append(runArgs, filePath), options.Files...)
+	return runGoCommand(ctx, absoluteProjectPath, workingDirectory, append(runArgs, options.Args...), options)
+}
+
+// resolveRunPaths returns the absolute project path and the working
+// directory of a run, checking both are directories.
+func resolveRunPaths(projectPath string, workingDirectory string) (string, string, error) {
 	absoluteProjectPath, err := filepath.Abs(projectPath)
 	if err != nil {
-		return Result{}, fmt.Errorf("resolve project path: %w", err)
+		return "", "", fmt.Errorf("resolve project path: %w", err)
 	}
 	info, err := os.Stat(absoluteProjectPath)
 	if err != nil {
-		return Result{}, fmt.Errorf("inspect project path: %w", err)
+		return "", "", fmt.Errorf("inspect project path: %w", err)
 	}
 	if !info.IsDir() {
-		return Result{}, fmt.Errorf("project path must be a directory")
-	}
-
-	workingDirectory := strings.TrimSpace(options.WorkingDirectory)
-	if workingDirectory == "" {
-		workingDirectory = absoluteProjectPath
-	} else {
-		if !filepath.IsAbs(workingDirectory) {
-			workingDirectory = filepath.Join(absoluteProjectPath, workingDirectory)
-		}
-		workingDirectory = filepath.Clean(workingDirectory)
+		return "", "", fmt.Errorf("project path must be a directory")
 	}
 
+	workingDirectory = runDirectory(absoluteProjectPath, workingDirectory)
 	workingDirectoryInfo, err := os.Stat(workingDirectory)
 	if err != nil {
-		return Result{}, fmt.Errorf("inspect working directory: %w", err)
+		return "", "", fmt.Errorf("inspect working directory: %w", err)
 	}
 	if !workingDirectoryInfo.IsDir() {
-		return Result{}, fmt.Errorf("working directory must be a directory")
+		return "", "", fmt.Errorf("working directory must be a directory")
 	}
+	return absoluteProjectPath, workingDirectory, nil
+}
 
+// runGoCommand runs the go command with goArgs in workingDirectory under
+// the run's timeout, capture, shutdown and output drain options.
+func runGoCommand(ctx context.Context, absoluteProjectPath string, workingDirectory string, goArgs []string, options RunOptions) (Result, error) {
 	timeout := options.Timeout
 	if timeout <= 0 {
 		timeout = DefaultTimeout
 	}
-
-	cacheDir := filepath.Join(absoluteProjectPath, ".gopoke-run-cache")
-	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
-		return Result{}, fmt.Errorf("create run cache dir: %w", err)
-	}
-
-	filePath, err := stableSnippetFilePath(cacheDir, snippet)
-	if err != nil {
-		return Result{}, fmt.Errorf("resolve snippet cache path: %w", err)
-	}
-	cleanSnippetCache(cacheDir, filepath.Base(filePath))
-	if err := os.WriteFile(filePath, []byte(snippet), 0o600); err != nil {
-		return Result{}, fmt.Errorf("write snippet file: %w", err)
-	}
-
 	runCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -160,34 +438,99 @@ func RunGoSnippetWithOptions(ctx context.Context, projectPath string, snippet st
 		toolchain = "go"
 	}
 
-	command := exec.Command(toolchain, "run", filePath)
+	program, programArgs, pinned := pinnedCommand(toolchain, goArgs, options.CPUAffinity)
+	command := exec.Command(program, programArgs...)
 	command.Dir = workingDirectory
-	command.Env = mergeEnvironment(os.Environ(), options.Environment)
+	environment := localeEnvironment(options.Environment, options.TimeZone, options.Locale)
+	environment = affinityEnvironment(environment, options.CPUAffinity)
+	environment = determinismEnvironment(environment, options.Seed, options.FrozenTime, os.Getenv("GODEBUG"))
+	if deadline, ok := runCtx.Deadline(); ok && options.ExportDeadline {
+		environment = deadlineEnvironment(environment, deadline)
+	}
+	command.Env = mergeEnvironment(os.Environ(), environment)
 	configureCommandForLifecycle(command)
-
-	stdoutCapture := newLimitedCaptureWriter(resolveMaxBytes(options.MaxStdoutBytes), options.OnStdoutChunk)
-	stderrCapture := newLimitedCaptureWriter(resolveMaxBytes(options.MaxStderrBytes), options.OnStderrChunk)
-	command.Stdout = stdoutCapture
-	command.Stderr = stderrCapture
+	lowPriority := options.LowPriority && lowerPriority(command)
+	openFilesLimited := options.MaxOpenFiles > 0 && limitOpenFiles(command, options.MaxOpenFiles)
+
+	stdoutCapture := newLimitedCaptureWriter(resolveMaxBytes(options.MaxStdoutBytes), options.OutputEncoding, options.OnStdoutChunk)
+	stderrCapture := newLimitedCaptureWriter(resolveMaxBytes(options.MaxStderrBytes), options.OutputEncoding, options.OnStderrChunk)
+	var stdout, stderr io.Writer = stdoutCapture, stderrCapture
+	var tee *teeSink
+	if options.Tee != nil {
+		tee = &teeSink{writer: options.Tee}
+		stdout = io.MultiWriter(stdoutCapture, tee)
+		stderr = io.MultiWriter(stderrCapture, tee)
+	}
+	stdoutPipe, err := newOutputPipe()
+	if err != nil {
+		return Result{}, fmt.Errorf("start snippet command: %w", err)
+	}
+	defer stdoutPipe.Close()
+	stderrPipe, err := newOutputPipe()
+	if err != nil {
+		return Result{}, fmt.Errorf("start snippet command: %w", err)
+	}
+	defer stderrPipe.Close()
+	command.Stdout = stdoutPipe.writer
+	command.Stderr = stderrPipe.writer
 
 	startedAt := time.Now()
+	if err := faults.Inject(faults.ProcessSpawn, absoluteProjectPath); err != nil {
+		return Result{}, fmt.Errorf("start snippet command: %w", err)
+	}
 	if err := command.Start(); err != nil {
 		return Result{}, fmt.Errorf("start snippet command: %w", err)
 	}
+	stdoutPipe.copyTo(stdout)
+	stderrPipe.copyTo(stderr)
+	if options.OnStart != nil {
+		options.OnStart(command.Process.Pid)
+	}
+	stopMarked := make(chan struct{})
+	stopMark := context.AfterFunc(runCtx, func() {
+		stdoutCapture.markStop()
+		stderrCapture.markStop()
+		close(stopMarked)
+	})
 	waitCh := make(chan error, 1)
 	go func() {
 		waitCh <- command.Wait()
 	}()
-	err = waitForCommandExit(runCtx, command, waitCh, resolveKillGracePeriod(options.KillGracePeriod))
+	err = waitForCommandExit(runCtx, command, waitCh, options.Shutdown, resolveKillGracePeriod(options.KillGracePeriod))
+	outputComplete := drainOutput([]*outputPipe{stdoutPipe, stderrPipe}, resolveOutputDrainTimeout(options.OutputDrainTimeout))
 	duration := time.Since(startedAt)
+	if !stopMark() {
+		<-stopMarked
+	}
+
+	stdoutCapture.Flush()
+	stderrCapture.Flush()
 
 	result := Result{
-		Stdout:          stdoutCapture.String(),
-		Stderr:          stderrCapture.String(),
 		ExitCode:        0,
 		DurationMS:      duration.Milliseconds(),
 		StdoutTruncated: stdoutCapture.Truncated(),
 		StderrTruncated: stderrCapture.Truncated(),
+		Limits: RunLimits{
+			TimeoutMS:         timeout.Milliseconds(),
+			MaxOutputBytes:    int64(resolveMaxBytes(options.MaxStdoutBytes)),
+			MaxDiskWriteBytes: options.MaxDiskWriteBytes,
+			MaxProcesses:      int64(options.MaxProcesses),
+			MaxOpenFiles:      int64(options.MaxOpenFiles),
+		},
+	}
+	result.setOutput(stdoutCapture, stderrCapture)
+	result.DrainedBytes = stdoutCapture.drainedBytes() + stderrCapture.drainedBytes()
+	result.OutputIncomplete = !outputComplete
+	if pinned {
+		result.CPUAffinity = options.CPUAffinity
+	}
+	result.LowPriority = lowPriority
+	result.OpenFileLimitHit = openFilesLimited && (openFileLimitHit(result.Stderr) || openFileLimitHit(result.Stdout))
+	if tee != nil {
+		if teeErr := tee.Err(); teeErr != nil {
+			result.TeeError = teeErr.Error()
+		}
 	}
 
 	if err == nil {
@@ -198,7 +541,7 @@ func RunGoSnippetWithOptions(ctx context.Context, projectPath string, snippet st
 		result.TimedOut = true
 		result.ExitCode = -1
 		if strings.TrimSpace(result.Stderr) == "" {
-			result.Stderr = "execution timed out"
+			result.Stderr = MessageTimedOut
 		}
 		return result, nil
 	}
@@ -206,7 +549,7 @@ func RunGoSnippetWithOptions(ctx context.Context, projectPath string, snippet st
 		result.Canceled = true
 		result.ExitCode = -1
 		if strings.TrimSpace(result.Stderr) == "" {
-			result.Stderr = "execution canceled"
+			result.Stderr = MessageCanceled
 		}
 		return result, nil
 	}
@@ -220,6 +563,19 @@ func RunGoSnippetWithOptions(ctx context.Context, projectPath string, snippet st
 	return Result{}, fmt.Errorf("run snippet command: %w", err)
 }
 
+// runDirectory resolves a run's working directory against its absolute
+// project path; empty means the project itself.
+func runDirectory(absoluteProjectPath string, workingDirectory string) string {
+	workingDirectory = strings.TrimSpace(workingDirectory)
+	if workingDirectory == "" {
+		return absoluteProjectPath
+	}
+	if !filepath.IsAbs(workingDirectory) {
+		workingDirectory = filepath.Join(absoluteProjectPath, workingDirectory)
+	}
+	return filepath.Clean(workingDirectory)
+}
+
 func mergeEnvironment(base []string, overrides map[string]string) []string {
 	merged := make(map[string]string, len(base)+len(overrides))
 	for _, entry := range base {
@@ -259,6 +615,17 @@ func resolveKillGracePeriod(value time.Duration) time.Duration {
 	return defaultKillGracePeriod
 }
 
+// IsSnippetFile reports whether path names a generated snippet file, as it
+// appears in compiler and panic output of a run.
+func IsSnippetFile(path string) bool {
+	name := filepath.Base(filepath.FromSlash(path))
+	if !strings.HasPrefix(name, "snippet-") || !strings.HasSuffix(name, ".go") {
+		return false
+	}
+	_, err := hex.DecodeString(strings.TrimSuffix(strings.TrimPrefix(name, "snippet-"), ".go"))
+	return err == nil
+}
+
 func stableSnippetFilePath(cacheDir string, snippet string) (string, error) {
 	if strings.TrimSpace(cacheDir) == "" {
 		return "", fmt.Errorf("cache dir is required")
@@ -268,12 +635,26 @@ func stableSnippetFilePath(cacheDir string, snippet string) (string, error) {
 	return filepath.Join(cacheDir, fileName), nil
 }
 
-func waitForCommandExit(ctx context.Context, command *exec.Cmd, waitCh <-chan error, killGracePeriod time.Duration) error {
+// packageSnippetFilePath places the snippet beside files, which must share
+// a directory. The name has no leading dot or underscore, which the go
+// command would ignore.
+func packageSnippetFilePath(files []string, snippet string) (string, error) {
+	dir := filepath.Dir(files[0])
+	for _, file := range files[1:] {
+		if filepath.Dir(file) != dir {
+			return "", fmt.Errorf("run files must share one directory, got %s and %s", dir, filepath.Dir(file))
+		}
+	}
+	sum := sha256.Sum256([]byte(snippet))
+	return filepath.Join(dir, "gopoke_snippet_"+hex.EncodeToString(sum[:12])+".go"), nil
+}
+
+func waitForCommandExit(ctx context.Context, command *exec.Cmd, waitCh <-chan error, shutdown Shutdown, killGracePeriod time.Duration) error {
 	select {
 	case waitErr := <-waitCh:
 		return waitErr
 	case <-ctx.Done():
-		_ = signalInterrupt(command)
+		requestShutdown(command, shutdown, killGracePeriod)
 
 		timer := time.NewTimer(killGracePeriod)
 		defer timer.Stop()
@@ -294,14 +675,24 @@ type limitedCaptureWriter struct {
 	maxBytes  int
 	size      int
 	truncated bool
-	onChunk   func(string)
+	// stopSize is size when the run was asked to stop, if stopped.
+	stopSize int
+	stopped  bool
+	encoding string
+	decoder  *textenc.Decoder
+	onChunk  func(string)
 }
 
-func newLimitedCaptureWriter(maxBytes int, onChunk func(string)) *limitedCaptureWriter {
-	return &limitedCaptureWriter{
+func newLimitedCaptureWriter(maxBytes int, encoding string, onChunk func(string)) *limitedCaptureWriter {
+	w := &limitedCaptureWriter{
 		maxBytes: maxBytes,
+		encoding: encoding,
 		onChunk:  onChunk,
 	}
+	if onChunk != nil {
+		w.decoder = textenc.NewDecoder(encoding)
+	}
+	return w
 }
 
 func (w *limitedCaptureWriter) Write(p []byte) (int, error) {
@@ -330,8 +721,8 @@ func (w *limitedCaptureWriter) Write(p []byte) (int, error) {
 			return 0, err
 		}
 		w.size += len(accepted)
-		if w.onChunk != nil {
-			chunk = string(accepted)
+		if w.decoder != nil {
+			chunk = w.decoder.Decode(accepted, false)
 		}
 	}
 	if len(accepted) < len(p) {
@@ -345,29 +736,70 @@ func (w *limitedCaptureWriter) Write(p []byte) (int, error) {
 	return len(p), nil
 }
 
-func (w *limitedCaptureWriter) String() string {
+// teeSink serializes writes from both output streams and keeps the first
+// error, so a failing tee never disturbs the captured output.
+type teeSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+	err    error
+}
+
+func (t *teeSink) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err == nil {
+		_, t.err = t.writer.Write(p)
+	}
+	return len(p), nil
+}
+
+func (t *teeSink) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// Flush streams characters held back at a chunk boundary once output has
+// ended.
+func (w *limitedCaptureWriter) Flush() {
+	if w.decoder == nil {
+		return
+	}
+	w.mu.Lock()
+	chunk := w.decoder.Decode(nil, true)
+	w.mu.Unlock()
+	if chunk != "" {
+		w.onChunk(chunk)
+	}
+}
+
+// Decoded converts the captured bytes to text.
+func (w *limitedCaptureWriter) Decoded() textenc.Decoded {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return w.buffer.String()
+	return textenc.Decode(w.buffer.Bytes(), w.encoding)
 }
 
-func (w *limitedCaptureWriter) Truncated() bool {
+// Bytes returns a copy of the captured bytes.
+func (w *limitedCaptureWriter) Bytes() []byte {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return w.truncated
+	return bytes.Clone(w.buffer.Bytes())
 }
 
-func cleanSnippetCache(cacheDir string, keepFileName string) {
-	entries, err := os.ReadDir(cacheDir)
-	if err != nil {
-		return
-	}
-	for _, entry := range entries {
-		if entry.IsDir() || entry.Name() == keepFileName {
-			continue
-		}
-		if strings.HasPrefix(entry.Name(), "snippet-") && strings.HasSuffix(entry.Name(), ".go") {
-			os.Remove(filepath.Join(cacheDir, entry.Name()))
-		}
+// setOutput fills the result's output text from both captures.
+func (r *Result) setOutput(stdout *limitedCaptureWriter, stderr *limitedCaptureWriter) {
+	decoded := stdout.Decoded()
+	r.Stdout = decoded.Text
+	r.StdoutEncoding = decoded.Encoding
+	if decoded.Encoding == textenc.Binary {
+		r.RawStdout = stdout.Bytes()
 	}
+	r.Stderr = stderr.Decoded().Text
+}
+
+func (w *limitedCaptureWriter) Truncated() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.truncated
 }
@@ -1,6 +1,7 @@
 package execution
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
@@ -375,6 +376,54 @@ func TestRunGoSnippetWithOptionsOutputCap(t *testing.T) {
 	}
 }
 
+func TestRunGoSnippetWithOptionsTeeReceivesFullOutput(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	projectDir := t.TempDir()
+	snippet := strings.Join([]string{
+		"package main",
+		"",
+		"import (",
+		"\t\"fmt\"",
+		"\t\"os\"",
+		"\t\"strings\"",
+		")",
+		"",
+		"func main() {",
+		"\tfmt.Print(strings.Repeat(\"o\", 2048))",
+		"\tfmt.Fprint(os.Stderr, strings.Repeat(\"e\", 1024))",
+		"}",
+		"",
+	}, "\n")
+
+	var tee bytes.Buffer
+	result, err := RunGoSnippetWithOptions(context.Background(), projectDir, snippet, RunOptions{
+		MaxStdoutBytes: 128,
+		MaxStderrBytes: 128,
+		Timeout:        10 * time.Second,
+		Tee:            &tee,
+	})
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	if !result.StdoutTruncated || len(result.Stdout) != 128 {
+		t.Fatalf("stdout len = %d truncated = %v, want capped at 128", len(result.Stdout), result.StdoutTruncated)
+	}
+	if got, want := strings.Count(tee.String(), "o"), 2048; got != want {
+		t.Fatalf("tee stdout bytes = %d, want %d", got, want)
+	}
+	if got, want := strings.Count(tee.String(), "e"), 1024; got != want {
+		t.Fatalf("tee stderr bytes = %d, want %d", got, want)
+	}
+	if result.TeeError != "" {
+		t.Fatalf("TeeError = %q, want empty", result.TeeError)
+	}
+}
+
 func TestRunGoSnippetWithOptionsHardKillFallback(t *testing.T) {
 	t.Parallel()
 
@@ -453,6 +502,53 @@ func TestRunGoSnippetWithOptionsHardKillFallback(t *testing.T) {
 	}
 }
 
+func TestRunGoSnippetWithOptionsCancelKeepsFinalOutput(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	toolchainPath := filepath.Join(t.TempDir(), "fake-go.sh")
+	// The background sleep ignores the interrupt, inherits stdout and
+	// outlives the run.
+	script := "#!/usr/bin/env bash\n" +
+		"trap 'echo \"final line\"; exit 130' INT\n" +
+		"(trap '' INT; echo holding; exec sleep 3) &\n" +
+		"while true; do sleep 0.05; done\n"
+	if err := os.WriteFile(toolchainPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("WriteFile(fake toolchain) error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var once sync.Once
+	started := time.Now()
+	result, err := RunGoSnippetWithOptions(ctx, projectDir, "package main\nfunc main() {}\n", RunOptions{
+		Toolchain:          toolchainPath,
+		Timeout:            10 * time.Second,
+		KillGracePeriod:    2 * time.Second,
+		OutputDrainTimeout: 200 * time.Millisecond,
+		OnStdoutChunk: func(chunk string) {
+			if strings.Contains(chunk, "holding") {
+				once.Do(cancel)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunGoSnippetWithOptions() error = %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("run returned after %s, want the drain bound to cut it short", elapsed)
+	}
+	if !result.Canceled {
+		t.Fatalf("result.Canceled = false, want true: %+v", result)
+	}
+	if result.Stdout != "holding\nfinal line\n" {
+		t.Fatalf("result.Stdout = %q, want the line printed after cancellation", result.Stdout)
+	}
+	if result.DrainedBytes != int64(len("final line\n")) || !result.OutputIncomplete {
+		t.Fatalf("DrainedBytes = %d, OutputIncomplete = %v", result.DrainedBytes, result.OutputIncomplete)
+	}
+}
+
 func TestRunGoSnippetWithOptionsCancelStress(t *testing.T) {
 	t.Parallel()
 
@@ -587,6 +683,9 @@ func TestStableSnippetFilePath(t *testing.T) {
 	if got, want := filepath.Dir(pathOneA), cacheDir; got != want {
 		t.Fatalf("pathOneA dir = %q, want %q", got, want)
 	}
+	if !IsSnippetFile(pathOneA) || IsSnippetFile(filepath.Join(cacheDir, "snippet-notes.go")) {
+		t.Fatalf("IsSnippetFile() misclassified %q or a hand-written file", pathOneA)
+	}
 }
 
 func canonicalPath(t *testing.T, value string) string {
@@ -0,0 +1,79 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// SnippetCacheFile is a generated snippet file in a run cache directory.
+type SnippetCacheFile struct {
+	Path  string
+	Bytes int64
+	// UsedAt is the file's modification time. Runs and checks rewrite the
+	// file each time they use it.
+	UsedAt time.Time
+}
+
+// SnippetCacheFiles lists the generated snippet files in the run cache
+// directory dir and its check directory. A missing dir has none.
+func SnippetCacheFiles(dir string) ([]SnippetCacheFile, error) {
+	var files []SnippetCacheFile
+	for _, path := range []string{dir, filepath.Join(dir, CheckDirName)} {
+		entries, err := os.ReadDir(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("list run cache: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !IsSnippetFile(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("inspect run cache file: %w", err)
+			}
+			files = append(files, SnippetCacheFile{Path: filepath.Join(path, entry.Name()), Bytes: info.Size(), UsedAt: info.ModTime()})
+		}
+	}
+	return files, nil
+}
+
+// EvictSnippetCache removes the least recently used files until the rest
+// fit in maxBytes and returns the ones it removed. Files used after
+// keepAfter are never removed, since a run may still be compiling them, so
+// the rest can stay over budget.
+func EvictSnippetCache(files []SnippetCacheFile, maxBytes int64, keepAfter time.Time) []SnippetCacheFile {
+	var total int64
+	for _, file := range files {
+		total += file.Bytes
+	}
+	if total <= maxBytes {
+		return nil
+	}
+	ordered := slices.Clone(files)
+	slices.SortFunc(ordered, func(left, right SnippetCacheFile) int {
+		return left.UsedAt.Compare(right.UsedAt)
+	})
+	var removed []SnippetCacheFile
+	for _, file := range ordered {
+		if total <= maxBytes || file.UsedAt.After(keepAfter) {
+			break
+		}
+		if err := os.Remove(file.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		total -= file.Bytes
+		removed = append(removed, file)
+	}
+	return removed
+}
@@ -0,0 +1,56 @@
+package execution
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEvictSnippetCacheRemovesLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Now()
+	write := func(path string, size int, usedAt time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o600); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+		if err := os.Chtimes(path, usedAt, usedAt); err != nil {
+			t.Fatalf("set times: %v", err)
+		}
+	}
+	oldest := filepath.Join(dir, CheckDirName, "snippet-aa.go")
+	older := filepath.Join(dir, "snippet-bb.go")
+	inUse := filepath.Join(dir, "snippet-cc.go")
+	write(oldest, 100, now.Add(-3*time.Hour))
+	write(older, 100, now.Add(-2*time.Hour))
+	write(inUse, 100, now)
+	write(filepath.Join(dir, "notes.go"), 1000, now.Add(-4*time.Hour))
+
+	files, err := SnippetCacheFiles(dir)
+	if err != nil {
+		t.Fatalf("SnippetCacheFiles() error = %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("SnippetCacheFiles() = %+v, want the three snippet files", files)
+	}
+
+	removed := EvictSnippetCache(files, 50, now.Add(-time.Minute))
+	if len(removed) != 2 || removed[0].Path != oldest || removed[1].Path != older {
+		t.Fatalf("EvictSnippetCache() removed %+v, want the two oldest", removed)
+	}
+	if _, err := os.Stat(inUse); err != nil {
+		t.Fatalf("file in use was removed: %v", err)
+	}
+	if removed := EvictSnippetCache(files[2:], 150, now); removed != nil {
+		t.Fatalf("EvictSnippetCache(within budget) removed %+v", removed)
+	}
+	if files, err := SnippetCacheFiles(filepath.Join(dir, "missing")); err != nil || len(files) != 0 {
+		t.Fatalf("SnippetCacheFiles(missing) = %v, %v; want none", files, err)
+	}
+}
@@ -0,0 +1,182 @@
+// Package faults injects failures and delays into storage and process
+// layers so resilience paths can be exercised. Injection is off unless a
+// test enables a fault or the GOPOKE_FAULTS environment variable is set, for
+// example:
+//
+//	GOPOKE_FAULTS=storage.write:fail,process.spawn:fail:2,storage.read:delay:300ms
+//
+// Each entry is point:fail[:times] or point:delay:duration. A fault without
+// times applies to every hit.
+package faults
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EnvVar configures faults for the whole process at startup.
+const EnvVar = "GOPOKE_FAULTS"
+
+// Point names an instrumented operation.
+type Point string
+
+const (
+	// StorageRead runs before the state file is read.
+	StorageRead Point = "storage.read"
+	// StorageWrite runs after the temporary state file is written and before
+	// it replaces the state file.
+	StorageWrite Point = "storage.write"
+	// ProcessSpawn runs before worker and snippet processes start.
+	ProcessSpawn Point = "process.spawn"
+)
+
+var knownPoints = map[Point]bool{StorageRead: true, StorageWrite: true, ProcessSpawn: true}
+
+// ErrInjected is wrapped by every injected failure.
+var ErrInjected = errors.New("injected fault")
+
+// Fault describes what happens when an instrumented point is hit.
+type Fault struct {
+	// Fail makes the hit return an error wrapping ErrInjected.
+	Fail bool
+	// Delay is slept before the hit returns.
+	Delay time.Duration
+	// Times limits how many hits the fault applies to; zero means all.
+	Times int
+	// Target restricts the fault to hits whose target has this prefix, such
+	// as a storage directory or project path. Empty matches every target.
+	Target string
+}
+
+type rule struct {
+	point Point
+	fault Fault
+	hits  int
+}
+
+var (
+	enabled atomic.Bool
+	mu      sync.Mutex
+	rules   []*rule
+)
+
+func init() {
+	if spec := strings.TrimSpace(os.Getenv(EnvVar)); spec != "" {
+		if err := Configure(spec); err != nil {
+			fmt.Fprintf(os.Stderr, "gopoke: ignoring %s: %v\n", EnvVar, err)
+		}
+	}
+}
+
+// Inject applies the first active fault registered for point and target.
+// It returns nil immediately when no faults are enabled.
+func Inject(point Point, target string) error {
+	if !enabled.Load() {
+		return nil
+	}
+
+	mu.Lock()
+	var fault *Fault
+	for _, candidate := range rules {
+		if candidate.point != point || !strings.HasPrefix(target, candidate.fault.Target) {
+			continue
+		}
+		if candidate.fault.Times > 0 && candidate.hits >= candidate.fault.Times {
+			continue
+		}
+		candidate.hits++
+		matched := candidate.fault
+		fault = &matched
+		break
+	}
+	mu.Unlock()
+
+	if fault == nil {
+		return nil
+	}
+	if fault.Delay > 0 {
+		time.Sleep(fault.Delay)
+	}
+	if fault.Fail {
+		return fmt.Errorf("%w: %s", ErrInjected, point)
+	}
+	return nil
+}
+
+// Enable registers fault for point and returns a function that removes it.
+func Enable(point Point, fault Fault) (disable func()) {
+	added := &rule{point: point, fault: fault}
+	mu.Lock()
+	rules = append(rules, added)
+	enabled.Store(true)
+	mu.Unlock()
+
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for index, candidate := range rules {
+			if candidate == added {
+				rules = append(rules[:index], rules[index+1:]...)
+				break
+			}
+		}
+		enabled.Store(len(rules) > 0)
+	}
+}
+
+// Configure registers the faults described by spec, in the EnvVar format.
+// Nothing is registered if any entry is invalid.
+func Configure(spec string) error {
+	var parsed []*rule
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		fields := strings.Split(entry, ":")
+		if len(fields) < 2 || len(fields) > 3 {
+			return fmt.Errorf("invalid fault %q: want point:action[:argument]", entry)
+		}
+		point := Point(fields[0])
+		if !knownPoints[point] {
+			return fmt.Errorf("invalid fault %q: unknown point %q", entry, point)
+		}
+
+		var fault Fault
+		switch fields[1] {
+		case "fail":
+			fault.Fail = true
+			if len(fields) == 3 {
+				times, err := strconv.Atoi(fields[2])
+				if err != nil || times <= 0 {
+					return fmt.Errorf("invalid fault %q: times must be a positive integer", entry)
+				}
+				fault.Times = times
+			}
+		case "delay":
+			if len(fields) != 3 {
+				return fmt.Errorf("invalid fault %q: delay needs a duration", entry)
+			}
+			delay, err := time.ParseDuration(fields[2])
+			if err != nil || delay <= 0 {
+				return fmt.Errorf("invalid fault %q: delay must be a positive duration", entry)
+			}
+			fault.Delay = delay
+		default:
+			return fmt.Errorf("invalid fault %q: unknown action %q", entry, fields[1])
+		}
+		parsed = append(parsed, &rule{point: point, fault: fault})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	rules = append(rules, parsed...)
+	enabled.Store(len(rules) > 0)
+	return nil
+}
@@ -0,0 +1,57 @@
+package faults
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInjectHonorsTimesAndTarget(t *testing.T) {
+	target := t.TempDir()
+	disable := Enable(ProcessSpawn, Fault{Fail: true, Times: 2, Target: target})
+	defer disable()
+
+	if err := Inject(ProcessSpawn, "/elsewhere"); err != nil {
+		t.Fatalf("Inject(other target) error = %v, want nil", err)
+	}
+	for hit := 1; hit <= 2; hit++ {
+		if err := Inject(ProcessSpawn, target+"/project"); !errors.Is(err, ErrInjected) {
+			t.Fatalf("hit %d: Inject() error = %v, want ErrInjected", hit, err)
+		}
+	}
+	if err := Inject(ProcessSpawn, target); err != nil {
+		t.Fatalf("Inject() after times exhausted error = %v, want nil", err)
+	}
+
+	disable()
+	if err := Inject(ProcessSpawn, target); err != nil {
+		t.Fatalf("Inject() after disable error = %v, want nil", err)
+	}
+}
+
+func TestConfigureParsesSpec(t *testing.T) {
+	target := t.TempDir()
+	if err := Configure("storage.read:delay:20ms"); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	t.Cleanup(func() {
+		mu.Lock()
+		rules = nil
+		enabled.Store(false)
+		mu.Unlock()
+	})
+
+	started := time.Now()
+	if err := Inject(StorageRead, target); err != nil {
+		t.Fatalf("Inject() error = %v, want delay only", err)
+	}
+	if elapsed := time.Since(started); elapsed < 20*time.Millisecond {
+		t.Fatalf("Inject() returned after %v, want at least 20ms", elapsed)
+	}
+
+	for _, spec := range []string{"storage.write", "disk.melt:fail", "storage.write:fail:0", "process.spawn:delay", "storage.read:explode"} {
+		if err := Configure(spec); err == nil {
+			t.Fatalf("Configure(%q) error = nil, want non-nil", spec)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package formatting
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// Quick fix kinds, named after the diagnostics explainer IDs they resolve.
+const (
+	QuickFixUnusedImport   = "unusedImport"
+	QuickFixUnusedVariable = "unusedVariable"
+	QuickFixMissingReturn  = "missingReturn"
+)
+
+// ErrNoQuickFix reports that no deterministic fix applies to a diagnostic.
+var ErrNoQuickFix = errors.New("no quick fix available")
+
+// ApplyQuickFix edits source to resolve one diagnostic and returns the
+// gofmt-formatted result. line and column are 1-based; name is the import
+// path or variable the diagnostic refers to and is unused for missing returns.
+func ApplyQuickFix(source string, kind string, line int, column int, name string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "snippet.go", source, parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("parse source: %w", err)
+	}
+	if line <= 0 || line > fset.File(file.Pos()).LineCount() {
+		return "", fmt.Errorf("line %d is outside the source", line)
+	}
+
+	var edited []byte
+	switch kind {
+	case QuickFixUnusedImport:
+		edited, err = removeUnusedImport(fset, file, []byte(source), line, name)
+	case QuickFixUnusedVariable:
+		edited, err = resolveUnusedVariable(fset, file, []byte(source), line, column, name)
+	case QuickFixMissingReturn:
+		edited, err = insertMissingReturn(fset, file, []byte(source), line)
+	default:
+		return "", fmt.Errorf("%w: %s", ErrNoQuickFix, kind)
+	}
+	if err != nil {
+		return "", err
+	}
+	return GoSource(string(edited))
+}
+
+func removeUnusedImport(fset *token.FileSet, file *ast.File, source []byte, line int, path string) ([]byte, error) {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			importPath, err := strconv.Unquote(importSpec.Path.Value)
+			if err != nil || importPath != path || fset.Position(importSpec.Pos()).Line != line {
+				continue
+			}
+			if genDecl.Lparen.IsValid() && len(genDecl.Specs) > 1 {
+				return deleteLines(fset, source, importSpec.Pos(), importSpec.End()), nil
+			}
+			return deleteLines(fset, source, genDecl.Pos(), genDecl.End()), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: import %q not found on line %d", ErrNoQuickFix, path, line)
+}
+
+// resolveUnusedVariable deletes a side-effect-free declaration of the variable,
+// or else keeps the declaration and marks the variable used with "_ = name".
+func resolveUnusedVariable(fset *token.FileSet, file *ast.File, source []byte, line int, column int, name string) ([]byte, error) {
+	ident, statement := findDeclaringStatement(fset, file, line, column, name)
+	if ident == nil {
+		return nil, fmt.Errorf("%w: declaration of %s not found on line %d", ErrNoQuickFix, name, line)
+	}
+
+	switch stmt := statement.(type) {
+	case *ast.RangeStmt:
+		return fixUnusedRangeVariable(fset, source, stmt, ident), nil
+	case *ast.AssignStmt:
+		if len(stmt.Lhs) == 1 && len(stmt.Rhs) == 1 && !hasCall(stmt.Rhs[0]) {
+			return deleteLines(fset, source, stmt.Pos(), stmt.End()), nil
+		}
+	case *ast.DeclStmt:
+		specs := stmt.Decl.(*ast.GenDecl).Specs
+		if len(specs) == 1 {
+			valueSpec := specs[0].(*ast.ValueSpec)
+			if len(valueSpec.Names) == 1 && !slicesHaveCall(valueSpec.Values) {
+				return deleteLines(fset, source, stmt.Pos(), stmt.End()), nil
+			}
+		}
+	}
+
+	end := fset.File(statement.End()).Offset(statement.End())
+	lineStart := fset.File(statement.Pos()).LineStart(fset.Position(statement.Pos()).Line)
+	indent := leadingWhitespace(source[fset.File(lineStart).Offset(lineStart):])
+	insertion := "\n" + indent + "_ = " + ident.Name
+	return splice(source, end, end, insertion), nil
+}
+
+// findDeclaringStatement locates the identifier declared at line:column (or
+// named name on that line) and the statement that declares it.
+func findDeclaringStatement(fset *token.FileSet, file *ast.File, line int, column int, name string) (*ast.Ident, ast.Stmt) {
+	var foundIdent *ast.Ident
+	var foundStatement ast.Stmt
+	matches := func(ident *ast.Ident) bool {
+		position := fset.Position(ident.Pos())
+		if position.Line != line {
+			return false
+		}
+		if name != "" {
+			return ident.Name == name
+		}
+		return position.Column == column
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if foundIdent != nil {
+			return false
+		}
+		switch stmt := node.(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE {
+				return true
+			}
+			for _, lhs := range stmt.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && matches(ident) {
+					foundIdent, foundStatement = ident, stmt
+				}
+			}
+		case *ast.DeclStmt:
+			genDecl, ok := stmt.Decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				return true
+			}
+			for _, spec := range genDecl.Specs {
+				for _, ident := range spec.(*ast.ValueSpec).Names {
+					if matches(ident) {
+						foundIdent, foundStatement = ident, stmt
+					}
+				}
+			}
+		case *ast.RangeStmt:
+			if stmt.Tok != token.DEFINE {
+				return true
+			}
+			for _, expr := range []ast.Expr{stmt.Key, stmt.Value} {
+				if ident, ok := expr.(*ast.Ident); ok && matches(ident) {
+					foundIdent, foundStatement = ident, stmt
+				}
+			}
+		}
+		return true
+	})
+	return foundIdent, foundStatement
+}
+
+func fixUnusedRangeVariable(fset *token.FileSet, source []byte, stmt *ast.RangeStmt, ident *ast.Ident) []byte {
+	offset := func(pos token.Pos) int { return fset.File(pos).Offset(pos) }
+	switch {
+	case stmt.Value == ident:
+		// for k, v := range x -> for k := range x
+		return splice(source, offset(stmt.Key.End()), offset(stmt.Value.End()), "")
+	case stmt.Value != nil:
+		return splice(source, offset(ident.Pos()), offset(ident.End()), "_")
+	default:
+		// for k := range x -> for range x
+		return splice(source, offset(stmt.Key.Pos()), offset(stmt.X.Pos()), "range ")
+	}
+}
+
+func insertMissingReturn(fset *token.FileSet, file *ast.File, source []byte, line int) ([]byte, error) {
+	var funcType *ast.FuncType
+	var body *ast.BlockStmt
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch fn := node.(type) {
+		case *ast.FuncDecl:
+			if fn.Body != nil && fset.Position(fn.Body.Rbrace).Line == line {
+				funcType, body = fn.Type, fn.Body
+			}
+		case *ast.FuncLit:
+			if fset.Position(fn.Body.Rbrace).Line == line {
+				funcType, body = fn.Type, fn.Body
+			}
+		}
+		return true
+	})
+	if body == nil || funcType.Results == nil || len(funcType.Results.List) == 0 {
+		return nil, fmt.Errorf("%w: no function with results ends on line %d", ErrNoQuickFix, line)
+	}
+
+	statement := "return"
+	if len(funcType.Results.List[0].Names) == 0 {
+		values := make([]string, 0, len(funcType.Results.List))
+		for _, field := range funcType.Results.List {
+			values = append(values, zeroValue(fset, source, field.Type))
+		}
+		statement += " " + strings.Join(values, ", ")
+	}
+	rbrace := fset.File(body.Rbrace).Offset(body.Rbrace)
+	return splice(source, rbrace, rbrace, statement+"\n"), nil
+}
+
+// zeroValue renders the zero value of a result type. Unknown named types use
+// *new(T), which is valid for any type including type parameters.
+func zeroValue(fset *token.FileSet, source []byte, expr ast.Expr) string {
+	switch typ := expr.(type) {
+	case *ast.Ident:
+		switch typ.Name {
+		case "bool":
+			return "false"
+		case "string":
+			return `""`
+		case "error", "any":
+			return "nil"
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "complex64", "complex128", "byte", "rune":
+			return "0"
+		}
+	case *ast.StarExpr, *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		if array, ok := typ.(*ast.ArrayType); !ok || array.Len == nil {
+			return "nil"
+		}
+	}
+	start := fset.File(expr.Pos()).Offset(expr.Pos())
+	end := fset.File(expr.End()).Offset(expr.End())
+	return "*new(" + string(source[start:end]) + ")"
+}
+
+// deleteLines removes the source from start to end, widening to whole lines
+// when nothing else shares them.
+func deleteLines(fset *token.FileSet, source []byte, start token.Pos, end token.Pos) []byte {
+	from := fset.File(start).Offset(start)
+	to := fset.File(end).Offset(end)
+	lineStart := bytes.LastIndexByte(source[:from], '\n') + 1
+	if strings.TrimSpace(string(source[lineStart:from])) == "" {
+		from = lineStart
+	}
+	if newline := bytes.IndexByte(source[to:], '\n'); newline >= 0 && isBlankOrComment(source[to:to+newline]) {
+		to += newline + 1
+	}
+	return splice(source, from, to, "")
+}
+
+func isBlankOrComment(text []byte) bool {
+	trimmed := strings.TrimSpace(string(text))
+	return trimmed == "" || strings.HasPrefix(trimmed, "//")
+}
+
+func splice(source []byte, from int, to int, replacement string) []byte {
+	edited := make([]byte, 0, len(source)-(to-from)+len(replacement))
+	edited = append(edited, source[:from]...)
+	edited = append(edited, replacement...)
+	return append(edited, source[to:]...)
+}
+
+func leadingWhitespace(line []byte) string {
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// hasCall reports whether evaluating expr may have side effects worth
+// keeping: a call (including conversions) or a channel receive. Function
+// literal bodies are not evaluated by the declaration and are skipped.
+func hasCall(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			found = true
+		case *ast.UnaryExpr:
+			found = found || n.Op == token.ARROW
+		}
+		return !found
+	})
+	return found
+}
+
+func slicesHaveCall(exprs []ast.Expr) bool {
+	for _, expr := range exprs {
+		if hasCall(expr) {
+			return true
+		}
+	}
+	return false
+}
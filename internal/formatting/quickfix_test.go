@@ -0,0 +1,97 @@
+package formatting
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyQuickFix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		source string
+		kind   string
+		line   int
+		column int
+		target string
+		want   string
+	}{
+		{
+			name:   "unused import in group",
+			source: "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println() }\n",
+			kind:   QuickFixUnusedImport, line: 5, column: 2, target: "os",
+			want: "package main\n\nimport (\n\t\"fmt\"\n)\n\nfunc main() { fmt.Println() }\n",
+		},
+		{
+			name:   "unused single import",
+			source: "package main\n\nimport \"os\"\n\nfunc main() {}\n",
+			kind:   QuickFixUnusedImport, line: 3, column: 8, target: "os",
+			want: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name:   "unused pure variable is removed",
+			source: "package main\n\nfunc main() {\n\tx := 1 + 2\n\tprintln(\"ok\")\n}\n",
+			kind:   QuickFixUnusedVariable, line: 4, column: 2, target: "x",
+			want: "package main\n\nfunc main() {\n\tprintln(\"ok\")\n}\n",
+		},
+		{
+			name:   "unused call result is kept",
+			source: "package main\n\nimport \"os\"\n\nfunc main() {\n\twd, err := os.Getwd()\n\t_ = err\n}\n",
+			kind:   QuickFixUnusedVariable, line: 6, column: 2, target: "wd",
+			want: "package main\n\nimport \"os\"\n\nfunc main() {\n\twd, err := os.Getwd()\n\t_ = wd\n\t_ = err\n}\n",
+		},
+		{
+			name:   "unused range value",
+			source: "package main\n\nfunc main() {\n\tfor i, v := range []int{1} {\n\t\tprintln(i)\n\t}\n}\n",
+			kind:   QuickFixUnusedVariable, line: 4, column: 9, target: "v",
+			want: "package main\n\nfunc main() {\n\tfor i := range []int{1} {\n\t\tprintln(i)\n\t}\n}\n",
+		},
+		{
+			name:   "unused range key",
+			source: "package main\n\nfunc main() {\n\tfor i := range 3 {\n\t}\n}\n",
+			kind:   QuickFixUnusedVariable, line: 4, column: 6, target: "i",
+			want: "package main\n\nfunc main() {\n\tfor range 3 {\n\t}\n}\n",
+		},
+		{
+			name:   "missing return with zero values",
+			source: "package main\n\ntype point struct{ x int }\n\nfunc f(ok bool) (int, string, error, point, []byte) {\n\tif ok {\n\t\treturn 1, \"\", nil, point{}, nil\n\t}\n}\n\nfunc main() {}\n",
+			kind:   QuickFixMissingReturn, line: 9, column: 1,
+			want: "package main\n\ntype point struct{ x int }\n\nfunc f(ok bool) (int, string, error, point, []byte) {\n\tif ok {\n\t\treturn 1, \"\", nil, point{}, nil\n\t}\n\treturn 0, \"\", nil, *new(point), nil\n}\n\nfunc main() {}\n",
+		},
+		{
+			name:   "missing return with named results",
+			source: "package main\n\nfunc f() (n int) {\n\tn = 1\n}\n",
+			kind:   QuickFixMissingReturn, line: 5, column: 1,
+			want: "package main\n\nfunc f() (n int) {\n\tn = 1\n\treturn\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ApplyQuickFix(tt.source, tt.kind, tt.line, tt.column, tt.target)
+			if err != nil {
+				t.Fatalf("ApplyQuickFix() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ApplyQuickFix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyQuickFixRejectsUnfixableDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	source := "package main\n\nfunc main() {}\n"
+	if _, err := ApplyQuickFix(source, "undefinedName", 3, 1, "x"); !errors.Is(err, ErrNoQuickFix) {
+		t.Fatalf("ApplyQuickFix(unsupported kind) error = %v, want ErrNoQuickFix", err)
+	}
+	if _, err := ApplyQuickFix(source, QuickFixUnusedImport, 3, 1, "os"); !errors.Is(err, ErrNoQuickFix) {
+		t.Fatalf("ApplyQuickFix(missing import) error = %v, want ErrNoQuickFix", err)
+	}
+	if _, err := ApplyQuickFix(source, QuickFixMissingReturn, 99, 1, ""); err == nil {
+		t.Fatal("ApplyQuickFix(line out of range) error = nil, want error")
+	}
+}
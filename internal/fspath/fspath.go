@@ -0,0 +1,128 @@
+// Package fspath resolves project paths to one canonical spelling and
+// classifies the filesystems they live on, so projects reached through
+// symlinks, network shares or virtual filesystems compare equal and get
+// timeouts suited to their storage.
+package fspath
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Filesystem kinds reported by Inspect.
+const (
+	KindLocal   = "local"
+	KindNetwork = "network"
+	// KindVirtual covers FUSE, WSL shares and other filesystems backed by a
+	// user-space or foreign implementation.
+	KindVirtual = "virtual"
+)
+
+// SlowProbeThreshold is the directory listing latency above which a volume
+// is treated as slow regardless of its kind.
+const SlowProbeThreshold = 100 * time.Millisecond
+
+// Volume describes the filesystem holding a path.
+type Volume struct {
+	Kind string `json:"kind"`
+	// Slow is set for network volumes and volumes whose probe exceeded
+	// SlowProbeThreshold.
+	Slow    bool  `json:"slow"`
+	ProbeMS int64 `json:"probeMs"`
+}
+
+// Canonical returns the absolute, cleaned path with symlinks resolved. When
+// path does not exist yet only its parent is resolved; when neither can be
+// resolved the path is returned absolute and cleaned. UNC paths are not
+// resolved because Windows maps them inconsistently between drive letters
+// and shares.
+func Canonical(path string) string {
+	cleaned := absolute(path)
+	if IsUNC(cleaned) {
+		return cleaned
+	}
+	if resolved, err := filepath.EvalSymlinks(cleaned); err == nil {
+		return filepath.Clean(resolved)
+	}
+	return CanonicalParent(cleaned)
+}
+
+// CanonicalParent resolves symlinks in the directories above path but keeps
+// its final element, so a symlinked file keeps its own name and location.
+func CanonicalParent(path string) string {
+	cleaned := absolute(path)
+	parent := filepath.Dir(cleaned)
+	if IsUNC(cleaned) || parent == cleaned {
+		return cleaned
+	}
+	resolved, err := filepath.EvalSymlinks(parent)
+	if err != nil {
+		return cleaned
+	}
+	return filepath.Join(resolved, filepath.Base(cleaned))
+}
+
+func absolute(path string) string {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return absolutePath
+}
+
+// CaseInsensitive reports whether the platform's default filesystems
+// ignore case, as APFS, HFS+ and NTFS do.
+var CaseInsensitive = runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+
+// Key returns the identity of a cleaned path: the path itself, folded to
+// lower case where filesystems are case-insensitive. Paths with equal keys
+// name the same location.
+func Key(path string) string {
+	if CaseInsensitive {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// Equal reports whether two cleaned paths have the same Key.
+func Equal(a string, b string) bool {
+	return a == b || Key(a) == Key(b)
+}
+
+// IsUNC reports whether path is a Windows UNC path such as
+// \\server\share\dir.
+func IsUNC(path string) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	return strings.HasPrefix(path, `\\`) || strings.HasPrefix(path, "//")
+}
+
+// Inspect classifies the filesystem holding dir and times a listing of it.
+func Inspect(dir string) Volume {
+	volume := Volume{Kind: kindOf(dir)}
+	startedAt := time.Now()
+	_, _ = os.ReadDir(dir)
+	elapsed := time.Since(startedAt)
+	volume.ProbeMS = elapsed.Milliseconds()
+	volume.Slow = volume.Kind == KindNetwork || elapsed > SlowProbeThreshold
+	return volume
+}
+
+// classifyType maps a filesystem type name to a kind.
+func classifyType(fsType string) string {
+	fsType = strings.ToLower(fsType)
+	switch fsType {
+	case "nfs", "nfs4", "cifs", "smb3", "smbfs", "ncpfs", "afs", "afpfs", "ceph", "glusterfs",
+		"lustre", "gpfs", "davfs", "webdav", "9p", "fuse.sshfs", "fuse.rclone", "fuse.s3fs",
+		"fuse.gcsfuse", "fuse.glusterfs":
+		return KindNetwork
+	}
+	if fsType == "fuse" || strings.HasPrefix(fsType, "fuse.") || strings.Contains(fsType, "fuse") {
+		return KindVirtual
+	}
+	return KindLocal
+}
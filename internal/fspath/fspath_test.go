@@ -0,0 +1,123 @@
+package fspath
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonicalResolvesSymlinkedDirectory(t *testing.T) {
+	t.Parallel()
+
+	root := resolvedTempDir(t)
+	target := filepath.Join(root, "target")
+	if err := os.Mkdir(target, 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if got := Canonical(link); got != target {
+		t.Fatalf("Canonical(link) = %q, want %q", got, target)
+	}
+	if got := Canonical(filepath.Join(link, "missing.go")); got != filepath.Join(target, "missing.go") {
+		t.Fatalf("Canonical(missing) = %q, want file under target", got)
+	}
+	if got := Canonical(link + string(filepath.Separator) + "."); got != target {
+		t.Fatalf("Canonical(link/.) = %q, want %q", got, target)
+	}
+}
+
+func TestCanonicalParentKeepsSymlinkedFile(t *testing.T) {
+	t.Parallel()
+
+	root := resolvedTempDir(t)
+	shared := filepath.Join(root, "shared.go")
+	if err := os.WriteFile(shared, []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	dirLink := filepath.Join(root, "project")
+	if err := os.Symlink(root, dirLink); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	fileLink := filepath.Join(dirLink, "main.go")
+	if err := os.Symlink(shared, fileLink); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if got, want := CanonicalParent(fileLink), filepath.Join(root, "main.go"); got != want {
+		t.Fatalf("CanonicalParent() = %q, want %q", got, want)
+	}
+	if got := Canonical(fileLink); got != shared {
+		t.Fatalf("Canonical() = %q, want %q", got, shared)
+	}
+}
+
+func TestClassifyType(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"ext4":        KindLocal,
+		"apfs":        KindLocal,
+		"tmpfs":       KindLocal,
+		"nfs4":        KindNetwork,
+		"CIFS":        KindNetwork,
+		"smbfs":       KindNetwork,
+		"fuse.sshfs":  KindNetwork,
+		"9p":          KindNetwork,
+		"fuse.bindfs": KindVirtual,
+		"macfuse":     KindVirtual,
+	}
+	for fsType, want := range cases {
+		if got := classifyType(fsType); got != want {
+			t.Errorf("classifyType(%q) = %q, want %q", fsType, got, want)
+		}
+	}
+}
+
+func TestInspectTempDir(t *testing.T) {
+	t.Parallel()
+
+	volume := Inspect(t.TempDir())
+	if volume.Kind == "" {
+		t.Fatal("Inspect().Kind is empty")
+	}
+	if volume.Kind == KindNetwork && !volume.Slow {
+		t.Fatalf("network volume not marked slow: %+v", volume)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	if !Equal("/a/b", "/a/b") {
+		t.Fatal("Equal(same) = false")
+	}
+	if Equal("/a/b", "/a/c") {
+		t.Fatal("Equal(different) = true")
+	}
+}
+
+func resolvedTempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	return dir
+}
+
+func TestKeyFoldsCaseOnlyWhereFilesystemsIgnoreIt(t *testing.T) {
+	t.Parallel()
+
+	same := Key("/Users/me/Proj") == Key("/users/me/proj")
+	if same != CaseInsensitive {
+		t.Fatalf("Key equal = %t, want %t", same, CaseInsensitive)
+	}
+	if Equal("/Users/me/Proj", "/users/me/proj") != CaseInsensitive {
+		t.Fatal("Equal disagrees with Key")
+	}
+}
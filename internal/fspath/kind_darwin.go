@@ -0,0 +1,21 @@
+//go:build darwin
+
+package fspath
+
+import "syscall"
+
+// kindOf classifies the filesystem type statfs reports for path.
+func kindOf(path string) string {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return KindLocal
+	}
+	name := make([]byte, 0, len(stat.Fstypename))
+	for _, c := range stat.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return classifyType(string(name))
+}
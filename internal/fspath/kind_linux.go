@@ -0,0 +1,58 @@
+//go:build linux
+
+package fspath
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// kindOf finds the mount holding path in /proc/self/mountinfo and
+// classifies its filesystem type.
+func kindOf(path string) string {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return KindLocal
+	}
+	defer file.Close()
+
+	path = filepath.Clean(path)
+	best, bestType := "", ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Fields: id parent major:minor root mountpoint options [optional...] - fstype source superoptions
+		fields := strings.Fields(scanner.Text())
+		separator := -1
+		for index, field := range fields {
+			if field == "-" {
+				separator = index
+				break
+			}
+		}
+		if len(fields) < 5 || separator < 0 || separator+1 >= len(fields) {
+			continue
+		}
+		mountPoint := unescapeMountPath(fields[4])
+		if !within(path, mountPoint) || len(mountPoint) < len(best) {
+			continue
+		}
+		best, bestType = mountPoint, fields[separator+1]
+	}
+	return classifyType(bestType)
+}
+
+func within(path string, mountPoint string) bool {
+	if mountPoint == "/" || path == mountPoint {
+		return true
+	}
+	return strings.HasPrefix(path, mountPoint+"/")
+}
+
+// unescapeMountPath decodes the octal escapes mountinfo uses for spaces,
+// tabs, newlines and backslashes.
+func unescapeMountPath(value string) string {
+	replacer := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+	return replacer.Replace(value)
+}
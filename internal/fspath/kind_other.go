@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package fspath
+
+// kindOf cannot classify filesystems on this platform.
+func kindOf(path string) string {
+	_ = path
+	return KindLocal
+}
@@ -0,0 +1,40 @@
+//go:build windows
+
+package fspath
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// driveRemote is the GetDriveTypeW result for mapped network drives.
+const driveRemote = 4
+
+var getDriveType = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDriveTypeW")
+
+// kindOf treats WSL shares as virtual, other UNC paths as network, and asks
+// Windows about drive letters.
+func kindOf(path string) string {
+	if IsUNC(path) {
+		host := strings.ToLower(strings.TrimLeft(filepath.ToSlash(path), "/"))
+		if strings.HasPrefix(host, "wsl$/") || strings.HasPrefix(host, "wsl.localhost/") {
+			return KindVirtual
+		}
+		return KindNetwork
+	}
+	volume := filepath.VolumeName(path)
+	if volume == "" {
+		return KindLocal
+	}
+	root, err := syscall.UTF16PtrFromString(volume + `\`)
+	if err != nil {
+		return KindLocal
+	}
+	kind, _, _ := getDriveType.Call(uintptr(unsafe.Pointer(root)))
+	if kind == driveRemote {
+		return KindNetwork
+	}
+	return KindLocal
+}
@@ -0,0 +1,79 @@
+// Package fuzzy scores how well a short query matches a name or path, for
+// filtering long lists as the user types.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// substringBonus favors text containing the query as written.
+	substringBonus = 100
+	// boundaryBonus favors matches that start a word, path segment or
+	// camelCase hump.
+	boundaryBonus = 10
+	// consecutiveBonus favors runs of matched characters.
+	consecutiveBonus = 5
+	// gapPenalty is charged per skipped character between matches.
+	gapPenalty = 1
+)
+
+// Score reports whether every character of query appears in text in order,
+// ignoring case, and how good the match is; higher is better. An empty
+// query matches everything with score 0.
+func Score(query string, text string) (int, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return 0, true
+	}
+	lower := strings.ToLower(text)
+
+	score := 0
+	if strings.Contains(lower, query) {
+		score += substringBonus
+	}
+
+	pattern := []rune(query)
+	next := 0
+	previous := rune(0)
+	lastMatch := -1
+	position := 0
+	for index, r := range lower {
+		if next < len(pattern) && r == pattern[next] {
+			original, _ := utf8.DecodeRuneInString(text[index:])
+			if isBoundary(previous, original) {
+				score += boundaryBonus
+			}
+			if lastMatch >= 0 {
+				if gap := position - lastMatch - 1; gap == 0 {
+					score += consecutiveBonus
+				} else {
+					score -= gap * gapPenalty
+				}
+			}
+			lastMatch = position
+			next++
+		}
+		previous, _ = utf8.DecodeRuneInString(text[index:])
+		position++
+	}
+	if next < len(pattern) {
+		return 0, false
+	}
+	return max(score, 1), true
+}
+
+// isBoundary reports whether r starts a word after previous.
+func isBoundary(previous rune, r rune) bool {
+	switch {
+	case previous == 0:
+		return true
+	case !unicode.IsLetter(previous) && !unicode.IsDigit(previous):
+		return true
+	case unicode.IsLower(previous) && unicode.IsUpper(r):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package fuzzy
+
+import "testing"
+
+func TestScoreMatchesSubsequences(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		text  string
+		match bool
+	}{
+		{"", "anything", true},
+		{"http", "HTTP client", true},
+		{"hc", "httpClient", true},
+		{"cmdapi", "/src/cmd/api", true},
+		{"xyz", "httpClient", false},
+		{"ab", "ba", false},
+	}
+	for _, tt := range tests {
+		if _, match := Score(tt.query, tt.text); match != tt.match {
+			t.Errorf("Score(%q, %q) match = %v, want %v", tt.query, tt.text, match, tt.match)
+		}
+	}
+}
+
+func TestScoreRanksCloserMatchesHigher(t *testing.T) {
+	t.Parallel()
+
+	better := []struct{ query, high, low string }{
+		// Substring beats scattered characters.
+		{"parse", "parseConfig", "perhaps_a_response"},
+		// Word starts beat mid-word matches.
+		{"hc", "httpClient", "hatch"},
+		// Tighter matches beat wider ones.
+		{"run", "runner", "r_u_n"},
+	}
+	for _, tt := range better {
+		high, highOK := Score(tt.query, tt.high)
+		low, lowOK := Score(tt.query, tt.low)
+		if !highOK || !lowOK || high <= low {
+			t.Errorf("Score(%q): %q = %d (%v), %q = %d (%v); want the first higher", tt.query, tt.high, high, highOK, tt.low, low, lowOK)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package golden compares a run's output with an approved golden snapshot,
+// approval-testing style: the first output is recorded, later outputs are
+// diffed against it line by line, and a changed output waits for the user
+// to approve it as the new snapshot or reject it.
+package golden
+
+import "strings"
+
+// Check outcomes.
+const (
+	// StatusRecorded marks a run whose output became the golden snapshot.
+	StatusRecorded = "recorded"
+	// StatusMatched marks a run whose output matched the snapshot.
+	StatusMatched = "matched"
+	// StatusChanged marks a run whose output differed; it awaits approval.
+	StatusChanged = "changed"
+)
+
+// Diff line operations.
+const (
+	OpEqual   = "equal"
+	OpRemoved = "removed"
+	OpAdded   = "added"
+)
+
+// maxDiffCells bounds the line-matching table; larger changed regions are
+// shown as wholly removed and added.
+const maxDiffCells = 1 << 22
+
+// Check is how a run's output compared with its golden snapshot.
+type Check struct {
+	Status string `json:"status"`
+	// GoldenExitCode is the snapshot's exit code.
+	GoldenExitCode int `json:"goldenExitCode"`
+	// Diff turns the snapshot's stdout into the run's; it is set only for
+	// StatusChanged.
+	Diff []Line `json:"diff,omitempty"`
+}
+
+// Line is one line of a diff.
+type Line struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Compare checks stdout and exitCode against the snapshot's.
+func Compare(goldenStdout string, goldenExitCode int, stdout string, exitCode int) Check {
+	check := Check{Status: StatusMatched, GoldenExitCode: goldenExitCode}
+	if goldenStdout == stdout && goldenExitCode == exitCode {
+		return check
+	}
+	check.Status = StatusChanged
+	check.Diff = Diff(goldenStdout, stdout)
+	return check
+}
+
+// Diff returns a line diff from before to after, with every line of both.
+func Diff(before string, after string) []Line {
+	a, b := splitLines(before), splitLines(after)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	for _, text := range a[:prefix] {
+		lines = append(lines, Line{Op: OpEqual, Text: text})
+	}
+	lines = append(lines, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Op: OpEqual, Text: text})
+	}
+	return lines
+}
+
+// diffMiddle diffs the changed region by longest common subsequence.
+func diffMiddle(a []string, b []string) []Line {
+	lines := make([]Line, 0, len(a)+len(b))
+	if len(a)*len(b) > maxDiffCells || len(a) == 0 || len(b) == 0 {
+		for _, text := range a {
+			lines = append(lines, Line{Op: OpRemoved, Text: text})
+		}
+		for _, text := range b {
+			lines = append(lines, Line{Op: OpAdded, Text: text})
+		}
+		return lines
+	}
+
+	// common[i][j] is the LCS length of a[i:] and b[j:].
+	width := len(b) + 1
+	common := make([]int, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i*width+j] = common[(i+1)*width+j+1] + 1
+			} else {
+				common[i*width+j] = max(common[(i+1)*width+j], common[i*width+j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Op: OpEqual, Text: a[i]})
+			i++
+			j++
+		case common[(i+1)*width+j] >= common[i*width+j+1]:
+			lines = append(lines, Line{Op: OpRemoved, Text: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: OpAdded, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, Line{Op: OpRemoved, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, Line{Op: OpAdded, Text: b[j]})
+	}
+	return lines
+}
+
+// splitLines splits output into lines; a final line ending does not start
+// another line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
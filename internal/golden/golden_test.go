@@ -0,0 +1,42 @@
+package golden
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	if check := Compare("a\nb\n", 0, "a\nb\n", 0); check.Status != StatusMatched || check.Diff != nil {
+		t.Fatalf("Compare(same) = %+v, want matched", check)
+	}
+	if check := Compare("a\n", 0, "a\n", 1); check.Status != StatusChanged || check.GoldenExitCode != 0 {
+		t.Fatalf("Compare(exit code) = %+v, want changed", check)
+	}
+
+	check := Compare("total: 3\nalpha\nbeta\ngamma\ndone\n", 0, "total: 4\nalpha\ngamma\ndelta\ndone\n", 0)
+	want := []Line{
+		{Op: OpRemoved, Text: "total: 3"},
+		{Op: OpAdded, Text: "total: 4"},
+		{Op: OpEqual, Text: "alpha"},
+		{Op: OpRemoved, Text: "beta"},
+		{Op: OpEqual, Text: "gamma"},
+		{Op: OpAdded, Text: "delta"},
+		{Op: OpEqual, Text: "done"},
+	}
+	if check.Status != StatusChanged || !reflect.DeepEqual(check.Diff, want) {
+		t.Fatalf("Compare() = %+v, want diff %+v", check, want)
+	}
+}
+
+func TestDiffEmptySides(t *testing.T) {
+	t.Parallel()
+
+	if got, want := Diff("", "x\n"), []Line{{Op: OpAdded, Text: "x"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff(empty, x) = %+v, want %+v", got, want)
+	}
+	if got, want := Diff("x", ""), []Line{{Op: OpRemoved, Text: "x"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff(x, empty) = %+v, want %+v", got, want)
+	}
+}
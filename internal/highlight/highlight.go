@@ -0,0 +1,213 @@
+// Package highlight applies a project's output highlight rules to run
+// output. Rules are regular expressions matched line by line; each match
+// becomes a span with the rule's color and label, and collapse rules mark
+// the whole matching line so the UI can fold it. Spans carry byte offsets
+// into the whole stream, so output streamed in chunks is annotated as lines
+// complete.
+package highlight
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopoke/internal/storage"
+)
+
+// Palette lists the named colors a rule may use besides "#rrggbb" values.
+var Palette = []string{"red", "orange", "yellow", "green", "blue", "purple", "gray"}
+
+const (
+	// MaxRules bounds the rules of one project.
+	MaxRules = 50
+	// MaxPatternLength bounds a rule's pattern.
+	MaxPatternLength = 512
+	// MaxLabelLength bounds a rule's label, in characters.
+	MaxLabelLength = 40
+	// maxLineBytes is how much of one line is matched; the rest of a longer
+	// line is passed over.
+	maxLineBytes = 64 << 10
+	// maxSpansPerLine bounds the matches of one rule on one line.
+	maxSpansPerLine = 32
+)
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Span marks a range of output. Start and End are byte offsets into the
+// stream; a collapse span covers its whole line without the line ending.
+// Only the first 64 KiB of a line are matched.
+type Span struct {
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	RuleID   string `json:"ruleId"`
+	Color    string `json:"color,omitempty"`
+	Label    string `json:"label,omitempty"`
+	Collapse bool   `json:"collapse,omitempty"`
+}
+
+// Normalize validates rules, trims their fields and gives rules without an
+// ID one.
+func Normalize(rules []storage.HighlightRule) ([]storage.HighlightRule, error) {
+	if len(rules) > MaxRules {
+		return nil, fmt.Errorf("at most %d highlight rules are allowed", MaxRules)
+	}
+	normalized := make([]storage.HighlightRule, 0, len(rules))
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		rule.ID = strings.TrimSpace(rule.ID)
+		rule.Color = strings.ToLower(strings.TrimSpace(rule.Color))
+		rule.Label = strings.TrimSpace(rule.Label)
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("highlight pattern is required")
+		}
+		if len(rule.Pattern) > MaxPatternLength {
+			return nil, fmt.Errorf("highlight pattern is longer than %d bytes", MaxPatternLength)
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid highlight pattern %q: %w", rule.Pattern, err)
+		}
+		if rule.Color != "" && !slices.Contains(Palette, rule.Color) && !hexColor.MatchString(rule.Color) {
+			return nil, fmt.Errorf("unknown highlight color %q; use #rrggbb or one of %s", rule.Color, strings.Join(Palette, ", "))
+		}
+		if utf8.RuneCountInString(rule.Label) > MaxLabelLength {
+			return nil, fmt.Errorf("highlight label is longer than %d characters", MaxLabelLength)
+		}
+		if rule.ID == "" {
+			rule.ID = newID()
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate highlight rule ID %q", rule.ID)
+		}
+		seen[rule.ID] = true
+		normalized = append(normalized, rule)
+	}
+	return normalized, nil
+}
+
+func newID() string {
+	raw := make([]byte, 6)
+	rand.Read(raw)
+	return "hl_" + hex.EncodeToString(raw)
+}
+
+type compiledRule struct {
+	rule    storage.HighlightRule
+	pattern *regexp.Regexp
+}
+
+// Highlighter applies a set of rules. A nil Highlighter marks nothing.
+type Highlighter struct {
+	rules []compiledRule
+}
+
+// Compile prepares rules for matching. It returns nil when there are no
+// rules.
+func Compile(rules []storage.HighlightRule) (*Highlighter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compile highlight rule %s: %w", rule.ID, err)
+		}
+		compiled = append(compiled, compiledRule{rule: rule, pattern: pattern})
+	}
+	return &Highlighter{rules: compiled}, nil
+}
+
+// Spans returns the spans of a complete output text.
+func (h *Highlighter) Spans(text string) []Span {
+	stream := h.Stream()
+	return append(stream.Feed(text), stream.Flush()...)
+}
+
+// Stream starts annotating one output stream.
+func (h *Highlighter) Stream() *Stream {
+	return &Stream{highlighter: h}
+}
+
+// Stream annotates output that arrives in chunks. Lines are matched once
+// they end, so the spans of a line split across chunks come with the chunk
+// that completes it.
+type Stream struct {
+	highlighter *Highlighter
+	offset      int // stream offset of the start of pending
+	pending     []byte
+	dropped     int // bytes of the pending line past maxLineBytes
+}
+
+// Feed consumes the next chunk and returns the spans of the lines it
+// completes.
+func (s *Stream) Feed(chunk string) []Span {
+	if s.highlighter == nil {
+		return nil
+	}
+	var spans []Span
+	for chunk != "" {
+		line, rest, complete := strings.Cut(chunk, "\n")
+		if room := maxLineBytes - len(s.pending); room > 0 {
+			kept := min(room, len(line))
+			s.pending = append(s.pending, line[:kept]...)
+			s.dropped += len(line) - kept
+		} else {
+			s.dropped += len(line)
+		}
+		if !complete {
+			break
+		}
+		spans = append(spans, s.endLine()...)
+		s.offset++ // the newline
+		chunk = rest
+	}
+	return spans
+}
+
+// Flush returns the spans of a final line without a line ending.
+func (s *Stream) Flush() []Span {
+	if s.highlighter == nil || (len(s.pending) == 0 && s.dropped == 0) {
+		return nil
+	}
+	return s.endLine()
+}
+
+func (s *Stream) endLine() []Span {
+	line := string(s.pending)
+	start := s.offset
+	end := start + len(s.pending) + s.dropped
+	if s.dropped == 0 && strings.HasSuffix(line, "\r") {
+		line = line[:len(line)-1]
+		end--
+	}
+	s.offset += len(s.pending) + s.dropped
+	s.pending = s.pending[:0]
+	s.dropped = 0
+	return s.highlighter.lineSpans(line, start, end)
+}
+
+// lineSpans matches every rule against one line, in rule order. Start and
+// end are the stream offsets of the line.
+func (h *Highlighter) lineSpans(line string, start int, end int) []Span {
+	var spans []Span
+	for _, compiled := range h.rules {
+		rule := compiled.rule
+		if rule.Collapse {
+			if compiled.pattern.MatchString(line) {
+				spans = append(spans, Span{Start: start, End: end, RuleID: rule.ID, Color: rule.Color, Label: rule.Label, Collapse: true})
+			}
+			continue
+		}
+		for _, match := range compiled.pattern.FindAllStringIndex(line, maxSpansPerLine) {
+			if match[0] == match[1] {
+				continue
+			}
+			spans = append(spans, Span{Start: start + match[0], End: start + match[1], RuleID: rule.ID, Color: rule.Color, Label: rule.Label})
+		}
+	}
+	return spans
+}
@@ -0,0 +1,99 @@
+package highlight
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopoke/internal/storage"
+)
+
+func TestNormalizeValidatesRules(t *testing.T) {
+	rules, err := Normalize([]storage.HighlightRule{{Pattern: `ERROR`, Color: " Red ", Label: " error "}})
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if rules[0].ID == "" || rules[0].Color != "red" || rules[0].Label != "error" {
+		t.Fatalf("rules = %+v, want an ID and trimmed fields", rules)
+	}
+
+	for name, rule := range map[string]storage.HighlightRule{
+		"empty pattern":   {},
+		"invalid pattern": {Pattern: `(`},
+		"unknown color":   {Pattern: `x`, Color: "teal"},
+		"long label":      {Pattern: `x`, Label: strings.Repeat("x", MaxLabelLength+1)},
+	} {
+		if _, err := Normalize([]storage.HighlightRule{rule}); err == nil {
+			t.Errorf("Normalize(%s) error = nil, want an error", name)
+		}
+	}
+	if _, err := Normalize([]storage.HighlightRule{{ID: "a", Pattern: "x"}, {ID: "a", Pattern: "y"}}); err == nil {
+		t.Error("Normalize(duplicate IDs) error = nil, want an error")
+	}
+	if _, err := Normalize([]storage.HighlightRule{{Pattern: "x", Color: "#A0b1C2"}}); err != nil {
+		t.Errorf("Normalize(hex color) error = %v", err)
+	}
+}
+
+func TestSpansMarkMatchesAndCollapsedLines(t *testing.T) {
+	highlighter, err := Compile([]storage.HighlightRule{
+		{ID: "req", Pattern: `req-[0-9]+`, Color: "blue", Label: "request"},
+		{ID: "debug", Pattern: `^DEBUG`, Collapse: true},
+	})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	text := "start req-1 and req-22\r\nDEBUG noisy\nend"
+	got := highlighter.Spans(text)
+	want := []Span{
+		{Start: 6, End: 11, RuleID: "req", Color: "blue", Label: "request"},
+		{Start: 16, End: 22, RuleID: "req", Color: "blue", Label: "request"},
+		{Start: 24, End: 35, RuleID: "debug", Collapse: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Spans() = %+v, want %+v", got, want)
+	}
+	for _, span := range got {
+		if span.RuleID == "req" && !strings.HasPrefix(text[span.Start:span.End], "req-") {
+			t.Fatalf("span %+v covers %q", span, text[span.Start:span.End])
+		}
+	}
+	if text[24:35] != "DEBUG noisy" {
+		t.Fatalf("collapse span covers %q", text[24:35])
+	}
+}
+
+func TestStreamMatchesLinesSplitAcrossChunks(t *testing.T) {
+	highlighter, err := Compile([]storage.HighlightRule{{ID: "err", Pattern: `ERROR \w+`}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	text := "ok\nan ERROR here\nERROR tail"
+	stream := highlighter.Stream()
+	var got []Span
+	for _, chunk := range []string{"ok\nan ER", "ROR he", "re\nERR", "OR tail"} {
+		got = append(got, stream.Feed(chunk)...)
+	}
+	got = append(got, stream.Flush()...)
+	if want := highlighter.Spans(text); !reflect.DeepEqual(got, want) || len(want) != 2 {
+		t.Fatalf("streamed spans = %+v, want %+v", got, want)
+	}
+
+	var none *Highlighter
+	if spans := none.Spans(text); spans != nil {
+		t.Fatalf("nil highlighter spans = %+v, want none", spans)
+	}
+}
+
+func TestStreamKeepsOffsetsPastLongLines(t *testing.T) {
+	highlighter, err := Compile([]storage.HighlightRule{{ID: "mark", Pattern: `MARK`}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	long := strings.Repeat("x", maxLineBytes+10) + "MARK"
+	text := long + "\nMARK"
+	got := highlighter.Spans(text)
+	if len(got) != 1 || text[got[0].Start:got[0].End] != "MARK" || got[0].Start != len(long)+1 {
+		t.Fatalf("Spans() = %+v, want only the match after the long line", got)
+	}
+}
@@ -0,0 +1,163 @@
+// Package i18n localizes user-facing strings produced by the backend using
+// embedded per-locale message catalogs.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// DefaultLocale is used when no locale is configured or a key is missing.
+const DefaultLocale = "en"
+
+// Message keys shared by backend packages.
+const (
+	MsgRunCanceled           = "run.canceled"
+	MsgRunTimedOut           = "run.timedOut"
+	MsgRunDiskQuota          = "run.diskQuotaExceeded"
+	MsgRunProcessLimit       = "run.processLimitExceeded"
+	MsgRunNetworkDenied      = "run.networkDenied"
+	MsgProjectNotFound       = "project.notFound"
+	MsgProjectNotTrusted     = "project.notTrusted"
+	MsgDiagnosticsPanic      = "diagnostics.runtimePanic"
+	MsgDiagnosticsCompile    = "diagnostics.compile"
+	MsgDiagnosticsPanicCount = "diagnostics.panic"
+	MsgDiagnosticsVet        = "diagnostics.vet"
+	MsgDiagnosticsNone       = "diagnostics.none"
+	MsgToolNotFound          = "tools.notFound"
+	MsgToolOutdated          = "tools.outdated"
+	MsgToolGitManual         = "tools.gitManual"
+
+	MsgA11yStatusSuccess  = "a11y.status.success"
+	MsgA11yStatusFailed   = "a11y.status.failed"
+	MsgA11yStatusTimedOut = "a11y.status.timedOut"
+	MsgA11yStatusCanceled = "a11y.status.canceled"
+	MsgA11yProblems       = "a11y.problems"
+	MsgA11yProblem        = "a11y.problem"
+	MsgA11yKindCompile    = "a11y.kind.compile"
+	MsgA11yKindPanic      = "a11y.kind.panic"
+	MsgA11yStdout         = "a11y.stdout"
+	MsgA11yStdoutEmpty    = "a11y.stdout.empty"
+	MsgA11yStderr         = "a11y.stderr"
+	MsgA11yStderrEmpty    = "a11y.stderr.empty"
+	MsgA11yTruncated      = "a11y.truncated"
+	MsgA11yEnd            = "a11y.end"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var catalogs = mustLoadCatalogs()
+
+// Localizer renders messages for one locale, falling back to English.
+type Localizer struct {
+	locale   string
+	messages map[string]string
+}
+
+// New returns a localizer for locale. Region variants such as "es-MX" fall
+// back to their base language, and unknown locales to DefaultLocale.
+func New(locale string) *Localizer {
+	resolved := Resolve(locale)
+	return &Localizer{locale: resolved, messages: catalogs[resolved]}
+}
+
+// Resolve maps a requested locale onto a supported catalog.
+func Resolve(locale string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if _, ok := catalogs[normalized]; ok {
+		return normalized
+	}
+	if base, _, found := strings.Cut(normalized, "-"); found {
+		if _, ok := catalogs[base]; ok {
+			return base
+		}
+	}
+	return DefaultLocale
+}
+
+// IsSupported reports whether a catalog exists for locale exactly.
+func IsSupported(locale string) bool {
+	_, ok := catalogs[locale]
+	return ok
+}
+
+// Locales returns supported locale codes in sorted order.
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// Locale returns the resolved locale code.
+func (l *Localizer) Locale() string {
+	if l == nil {
+		return DefaultLocale
+	}
+	return l.locale
+}
+
+// T formats the message for key with fmt-style args. Missing keys fall back
+// to English, then to the key itself. A nil Localizer renders English.
+func (l *Localizer) T(key string, args ...any) string {
+	format := l.lookup(key)
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Plural formats key + ".one" when count is 1 and key + ".other" otherwise,
+// passing count as the first argument.
+func (l *Localizer) Plural(key string, count int, args ...any) string {
+	suffix := ".other"
+	if count == 1 {
+		suffix = ".one"
+	}
+	return l.T(key+suffix, append([]any{count}, args...)...)
+}
+
+// Error returns an error whose text is the localized message for key.
+func (l *Localizer) Error(key string, args ...any) error {
+	return errors.New(l.T(key, args...))
+}
+
+func (l *Localizer) lookup(key string) string {
+	if l != nil {
+		if message, ok := l.messages[key]; ok {
+			return message
+		}
+	}
+	if message, ok := catalogs[DefaultLocale][key]; ok {
+		return message
+	}
+	return key
+}
+
+func mustLoadCatalogs() map[string]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("read locale catalogs: %v", err))
+	}
+	loaded := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("read locale catalog %s: %v", entry.Name(), err))
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("decode locale catalog %s: %v", entry.Name(), err))
+		}
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+	return loaded
+}
@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveLocale(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "", want: "en"},
+		{input: "es", want: "es"},
+		{input: "es-MX", want: "es"},
+		{input: "de_AT", want: "de"},
+		{input: "ja", want: "en"},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.input); got != tt.want {
+			t.Fatalf("Resolve(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizerTranslatesAndFallsBack(t *testing.T) {
+	t.Parallel()
+
+	spanish := New("es")
+	if got, want := spanish.T(MsgRunCanceled), "ejecución cancelada"; got != want {
+		t.Fatalf("T(run.canceled) = %q, want %q", got, want)
+	}
+	if got, want := spanish.T(MsgToolNotFound, "gopls"), "gopls no encontrado"; got != want {
+		t.Fatalf("T(tools.notFound) = %q, want %q", got, want)
+	}
+	if got, want := spanish.T("missing.key"), "missing.key"; got != want {
+		t.Fatalf("T(missing) = %q, want %q", got, want)
+	}
+
+	var nilLocalizer *Localizer
+	if got, want := nilLocalizer.T(MsgRunTimedOut), "execution timed out"; got != want {
+		t.Fatalf("nil T() = %q, want %q", got, want)
+	}
+}
+
+func TestLocalizerPlural(t *testing.T) {
+	t.Parallel()
+
+	english := New("en")
+	if got, want := english.Plural(MsgDiagnosticsCompile, 1), "1 compile error"; got != want {
+		t.Fatalf("Plural(1) = %q, want %q", got, want)
+	}
+	if got, want := english.Plural(MsgDiagnosticsCompile, 3), "3 compile errors"; got != want {
+		t.Fatalf("Plural(3) = %q, want %q", got, want)
+	}
+}
+
+func TestCatalogsDefineEveryEnglishKey(t *testing.T) {
+	t.Parallel()
+
+	for _, locale := range Locales() {
+		for key := range catalogs[DefaultLocale] {
+			if _, ok := catalogs[locale][key]; !ok {
+				t.Errorf("locale %s is missing key %q", locale, key)
+			}
+		}
+	}
+	if !slices.Contains(Locales(), DefaultLocale) {
+		t.Fatalf("Locales() = %v, missing %s", Locales(), DefaultLocale)
+	}
+}
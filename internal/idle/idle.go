@@ -0,0 +1,243 @@
+// Package idle pauses background work while the user is away. A Monitor
+// notices when no interaction has been reported for a while and pauses the
+// registered subsystems, then resumes them on the next interaction before
+// it returns, so the interaction never sees a paused subsystem.
+package idle
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Pauser is a background subsystem that can stop its periodic work. Pause
+// and Resume must be quick; they run on the caller's goroutine.
+type Pauser interface {
+	Pause()
+	Resume()
+}
+
+// Status describes the monitor.
+type Status struct {
+	// Timeout is how long without interaction pauses subsystems; zero means
+	// never.
+	Timeout time.Duration `json:"timeout"`
+	Idle    bool          `json:"idle"`
+	// IdleSince is when subsystems were paused; zero while active.
+	IdleSince time.Time `json:"idleSince,omitempty"`
+	// Subsystems are the registered subsystem names.
+	Subsystems []string `json:"subsystems"`
+}
+
+type namedPauser struct {
+	name   string
+	pauser Pauser
+}
+
+// Monitor pauses subsystems after a period without interaction. It is safe
+// for concurrent use.
+type Monitor struct {
+	logger *slog.Logger
+
+	lastActive atomic.Int64 // unix nanoseconds
+	idle       atomic.Bool
+
+	mu        sync.Mutex
+	timeout   time.Duration
+	idleSince time.Time
+	pausers   []namedPauser
+	running   bool
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewMonitor returns a monitor that pauses nothing until SetTimeout.
+func NewMonitor(logger *slog.Logger) *Monitor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	m := &Monitor{logger: logger, wake: make(chan struct{}, 1)}
+	m.lastActive.Store(time.Now().UnixNano())
+	return m
+}
+
+// Register adds a subsystem. It is paused at once if the monitor is idle.
+func (m *Monitor) Register(name string, pauser Pauser) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pausers = append(m.pausers, namedPauser{name: name, pauser: pauser})
+	if m.idle.Load() {
+		pauser.Pause()
+	}
+}
+
+// SetTimeout sets how long without interaction pauses subsystems; zero or
+// less never pauses and resumes any paused ones.
+func (m *Monitor) SetTimeout(timeout time.Duration) {
+	m.mu.Lock()
+	m.timeout = max(timeout, 0)
+	if m.timeout == 0 {
+		m.resumeLocked()
+	}
+	m.mu.Unlock()
+	m.signal()
+}
+
+// Touch reports an interaction. Paused subsystems resume before it returns.
+func (m *Monitor) Touch() {
+	m.lastActive.Store(time.Now().UnixNano())
+	if !m.idle.Load() {
+		return
+	}
+	m.mu.Lock()
+	m.resumeLocked()
+	m.mu.Unlock()
+	m.signal()
+}
+
+// Status reports the monitor's state.
+func (m *Monitor) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := Status{Timeout: m.timeout, Idle: m.idle.Load(), IdleSince: m.idleSince, Subsystems: make([]string, 0, len(m.pausers))}
+	for _, registered := range m.pausers {
+		status.Subsystems = append(status.Subsystems, registered.name)
+	}
+	return status
+}
+
+// Start begins watching for inactivity, counted from now.
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running {
+		return
+	}
+	m.running = true
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	m.lastActive.Store(time.Now().UnixNano())
+	go m.loop(m.stop, m.done)
+}
+
+// Stop stops watching and resumes paused subsystems so they can shut down
+// normally.
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	stop, done := m.stop, m.done
+	m.resumeLocked()
+	m.mu.Unlock()
+	close(stop)
+	<-done
+}
+
+func (m *Monitor) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	for {
+		var timer *time.Timer
+		var fired <-chan time.Time
+		m.mu.Lock()
+		timeout := m.timeout
+		m.mu.Unlock()
+		if timeout > 0 && !m.idle.Load() {
+			wait := time.Until(time.Unix(0, m.lastActive.Load()).Add(timeout))
+			if wait <= 0 {
+				m.pause(timeout)
+				continue
+			}
+			timer = time.NewTimer(wait)
+			fired = timer.C
+		}
+		select {
+		case <-stop:
+		case <-m.wake:
+		case <-fired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-stop:
+			return
+		default:
+		}
+	}
+}
+
+// pause pauses every subsystem unless an interaction arrived meanwhile.
+func (m *Monitor) pause(timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lastActive := time.Unix(0, m.lastActive.Load())
+	if m.idle.Load() || m.timeout != timeout || time.Since(lastActive) < timeout {
+		return
+	}
+	m.idle.Store(true)
+	m.idleSince = time.Now().UTC()
+	for _, registered := range m.pausers {
+		registered.pauser.Pause()
+	}
+	m.logger.Info("paused background work while idle", "idleFor", time.Since(lastActive).Round(time.Second))
+}
+
+func (m *Monitor) resumeLocked() {
+	if !m.idle.Load() {
+		return
+	}
+	for _, registered := range m.pausers {
+		registered.pauser.Resume()
+	}
+	m.idle.Store(false)
+	m.idleSince = time.Time{}
+	m.logger.Info("resumed background work")
+}
+
+// signal wakes the loop to re-arm its timer.
+func (m *Monitor) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Gate lets a periodic loop wait out a pause. It implements Pauser; the
+// zero value is open.
+type Gate struct {
+	mu      sync.Mutex
+	resumed chan struct{}
+}
+
+// Pause closes the gate.
+func (g *Gate) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		g.resumed = make(chan struct{})
+	}
+}
+
+// Resume opens the gate, releasing loops waiting on Paused.
+func (g *Gate) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed != nil {
+		close(g.resumed)
+		g.resumed = nil
+	}
+}
+
+// Paused returns a channel closed when the gate opens, or nil when it is
+// open.
+func (g *Gate) Paused() <-chan struct{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resumed
+}
@@ -0,0 +1,104 @@
+package idle
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingPauser struct {
+	paused  atomic.Int32
+	resumed atomic.Int32
+}
+
+func (p *countingPauser) Pause()  { p.paused.Add(1) }
+func (p *countingPauser) Resume() { p.resumed.Add(1) }
+
+func TestMonitorPausesWhenIdleAndResumesOnTouch(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewMonitor(nil)
+	pauser := &countingPauser{}
+	monitor.Register("watchdog", pauser)
+	monitor.SetTimeout(20 * time.Millisecond)
+	monitor.Start()
+	defer monitor.Stop()
+
+	waitFor(t, func() bool { return monitor.Status().Idle })
+	if pauser.paused.Load() != 1 {
+		t.Fatalf("Pause calls = %d, want 1", pauser.paused.Load())
+	}
+	if status := monitor.Status(); status.IdleSince.IsZero() || len(status.Subsystems) != 1 {
+		t.Fatalf("Status() = %+v", status)
+	}
+
+	late := &countingPauser{}
+	monitor.Register("late", late)
+	if late.paused.Load() != 1 {
+		t.Fatal("subsystem registered while idle was not paused")
+	}
+
+	monitor.Touch()
+	if pauser.resumed.Load() != 1 || late.resumed.Load() != 1 || monitor.Status().Idle {
+		t.Fatalf("after Touch resumed = %d/%d, idle = %v", pauser.resumed.Load(), late.resumed.Load(), monitor.Status().Idle)
+	}
+
+	waitFor(t, func() bool { return pauser.paused.Load() == 2 })
+	monitor.SetTimeout(0)
+	if monitor.Status().Idle || pauser.resumed.Load() != 2 {
+		t.Fatal("disabling the timeout did not resume subsystems")
+	}
+}
+
+func TestMonitorKeepsActiveWhileTouched(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewMonitor(nil)
+	pauser := &countingPauser{}
+	monitor.Register("watchdog", pauser)
+	monitor.SetTimeout(60 * time.Millisecond)
+	monitor.Start()
+	defer monitor.Stop()
+
+	for range 10 {
+		monitor.Touch()
+		time.Sleep(15 * time.Millisecond)
+	}
+	if pauser.paused.Load() != 0 {
+		t.Fatalf("Pause calls = %d while active, want 0", pauser.paused.Load())
+	}
+}
+
+func TestGate(t *testing.T) {
+	t.Parallel()
+
+	var gate Gate
+	if gate.Paused() != nil {
+		t.Fatal("zero Gate is paused")
+	}
+	gate.Pause()
+	resumed := gate.Paused()
+	if resumed == nil {
+		t.Fatal("Paused() = nil after Pause")
+	}
+	gate.Resume()
+	select {
+	case <-resumed:
+	default:
+		t.Fatal("Resume did not release the paused channel")
+	}
+	if gate.Paused() != nil {
+		t.Fatal("Paused() != nil after Resume")
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 5s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
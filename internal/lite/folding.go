@@ -0,0 +1,134 @@
+package lite
+
+import (
+	"cmp"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Folding range kinds from the LSP specification. Code blocks have no kind.
+const (
+	FoldImports = "imports"
+	FoldRegion  = "region"
+)
+
+var (
+	regionStart = regexp.MustCompile(`^//\s*#?region\b\s*(.*)$`)
+	regionEnd   = regexp.MustCompile(`^//\s*#?endregion\b`)
+)
+
+// FoldingRange is an LSP folding range with an ID that stays the same while
+// the region moves, so the editor can keep a region folded across edits.
+// Lines are zero-based; EndLine is the last hidden line.
+type FoldingRange struct {
+	ID        string `json:"id"`
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Kind      string `json:"kind,omitempty"`
+}
+
+// FoldingRanges returns the foldable regions of src: the import block,
+// declaration blocks, functions, multi-line types, and regions marked by
+// //region and //endregion comments (or //#region and //#endregion).
+// Brace-delimited ranges stop before the closing line so it stays visible.
+// IDs are built from the declaration or region name and numbered when names
+// repeat.
+func FoldingRanges(filename string, src []byte) []FoldingRange {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	ranges := make([]FoldingRange, 0)
+	add := func(id string, start int, end int, kind string) {
+		if end > start {
+			ranges = append(ranges, FoldingRange{ID: id, StartLine: start, EndLine: end, Kind: kind})
+		}
+	}
+	line := func(pos token.Pos) int {
+		return fset.Position(pos).Line - 1
+	}
+
+	if file != nil {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Body == nil || !decl.Body.Rbrace.IsValid() {
+					continue
+				}
+				name := decl.Name.Name
+				if decl.Recv != nil && len(decl.Recv.List) > 0 {
+					name = "(" + receiverType(decl.Recv.List[0].Type) + ")." + name
+				}
+				add("func:"+name, line(decl.Pos()), line(decl.Body.Rbrace)-1, "")
+			case *ast.GenDecl:
+				if decl.Lparen.IsValid() && decl.Rparen.IsValid() {
+					kind := ""
+					if decl.Tok == token.IMPORT {
+						kind = FoldImports
+					}
+					add(decl.Tok.String(), line(decl.Pos()), line(decl.Rparen)-1, kind)
+					continue
+				}
+				for _, spec := range decl.Specs {
+					typeSpec, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					switch typ := typeSpec.Type.(type) {
+					case *ast.StructType:
+						add("type:"+typeSpec.Name.Name, line(decl.Pos()), line(typ.Fields.Closing)-1, "")
+					case *ast.InterfaceType:
+						add("type:"+typeSpec.Name.Name, line(decl.Pos()), line(typ.Methods.Closing)-1, "")
+					}
+				}
+			}
+		}
+	}
+
+	// Comments are scanned rather than taken from the syntax tree so region
+	// markers still fold while the code has parse errors.
+	var scan scanner.Scanner
+	scanFile := token.NewFileSet().AddFile(filename, -1, len(src))
+	scan.Init(scanFile, src, nil, scanner.ScanComments)
+	type openRegion struct {
+		name string
+		line int
+	}
+	open := make([]openRegion, 0)
+	for {
+		pos, tok, lit := scan.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.COMMENT {
+			continue
+		}
+		text := strings.TrimSpace(lit)
+		if regionEnd.MatchString(text) {
+			if len(open) > 0 {
+				region := open[len(open)-1]
+				open = open[:len(open)-1]
+				add("region:"+region.name, region.line, scanFile.Line(pos)-1, FoldRegion)
+			}
+			continue
+		}
+		if match := regionStart.FindStringSubmatch(text); match != nil {
+			open = append(open, openRegion{name: strings.TrimSpace(match[1]), line: scanFile.Line(pos) - 1})
+		}
+	}
+	slices.SortStableFunc(ranges, func(a FoldingRange, b FoldingRange) int {
+		return cmp.Compare(a.StartLine, b.StartLine)
+	})
+	seen := make(map[string]int, len(ranges))
+	for i := range ranges {
+		seen[ranges[i].ID]++
+		if n := seen[ranges[i].ID]; n > 1 {
+			ranges[i].ID += "#" + strconv.Itoa(n)
+		}
+	}
+	return ranges
+}
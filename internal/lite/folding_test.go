@@ -0,0 +1,59 @@
+package lite
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFoldingRanges(t *testing.T) {
+	t.Parallel()
+
+	src := strings.Join([]string{
+		"package main",   // 0
+		"",               // 1
+		"import (",       // 2
+		"\t\"fmt\"",      // 3
+		"\t\"os\"",       // 4
+		")",              // 5
+		"",               // 6
+		"//region setup", // 7
+		"type Config struct {",
+		"\tName string", // 9
+		"}",             // 10
+		"//endregion",   // 11
+		"",              // 12
+		"func main() {", // 13
+		"\tfmt.Println(os.Args)",
+		"}", // 15
+		"",
+		"func (c *Config) Load() {}", // 17
+	}, "\n")
+	got := make([]string, 0)
+	for _, r := range FoldingRanges("main.go", []byte(src)) {
+		got = append(got, fmt.Sprintf("%s %d-%d %s", r.ID, r.StartLine, r.EndLine, r.Kind))
+	}
+	want := []string{"import 2-4 imports", "region:setup 7-11 region", "type:Config 8-9 ", "func:main 13-14 "}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("FoldingRanges() = %q, want %q", got, want)
+	}
+}
+
+func TestFoldingRangeIDsStayStable(t *testing.T) {
+	t.Parallel()
+
+	src := "package main\n\nfunc a() {\n\t_ = 1\n}\n\n// #region helpers\n// #region helpers\nfunc b() {\n\t_ = 2\n}\n// #endregion\n// #endregion\n"
+	before := FoldingRanges("main.go", []byte(src))
+	after := FoldingRanges("main.go", []byte(strings.Replace(src, "package main\n", "package main\n\n// moved\n", 1)))
+	if len(before) != 4 || len(after) != len(before) {
+		t.Fatalf("ranges = %+v then %+v, want four each", before, after)
+	}
+	for i := range before {
+		if before[i].ID != after[i].ID || after[i].StartLine != before[i].StartLine+2 {
+			t.Fatalf("range %d = %+v then %+v, want the same ID two lines down", i, before[i], after[i])
+		}
+	}
+	if before[1].ID != "region:helpers" || before[2].ID != "region:helpers#2" {
+		t.Fatalf("region IDs = %q, %q; want numbered duplicates", before[1].ID, before[2].ID)
+	}
+}
@@ -0,0 +1,286 @@
+// Package lite is a syntax-only language server for Go documents, used in
+// place of gopls when gopls is not installed. It parses each open document
+// with go/parser and answers with parse-error diagnostics, a document
+// outline, folding ranges and gofmt formatting, so the editor keeps basic
+// feedback without type information.
+package lite
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"gopoke/internal/diagnostics"
+)
+
+// maxDiagnostics caps the parse errors reported per document; errors past
+// the first few are usually fallout from the first one.
+const maxDiagnostics = 10
+
+// Symbol kinds from the LSP specification.
+const (
+	SymbolClass     = 5
+	SymbolMethod    = 6
+	SymbolField     = 8
+	SymbolInterface = 11
+	SymbolFunction  = 12
+	SymbolVariable  = 13
+	SymbolConstant  = 14
+	SymbolStruct    = 23
+)
+
+// Position is an LSP position. Character counts UTF-16 code units.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is an LSP range.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Diagnostic is an LSP diagnostic for one parse error.
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// Symbol is an LSP DocumentSymbol.
+type Symbol struct {
+	Name           string   `json:"name"`
+	Detail         string   `json:"detail,omitempty"`
+	Kind           int      `json:"kind"`
+	Range          Range    `json:"range"`
+	SelectionRange Range    `json:"selectionRange"`
+	Children       []Symbol `json:"children,omitempty"`
+}
+
+// TextEdit is an LSP text edit.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// Diagnose returns the parse errors of src.
+func Diagnose(filename string, src []byte) []Diagnostic {
+	_, err := parser.ParseFile(token.NewFileSet(), filename, src, parser.AllErrors|parser.SkipObjectResolution)
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		if err == nil {
+			return []Diagnostic{}
+		}
+		return []Diagnostic{{Severity: diagnostics.SeverityError, Source: diagnostics.SourceSyntax, Message: err.Error()}}
+	}
+	list.RemoveMultiples()
+	lines := newLineIndex(src)
+	result := make([]Diagnostic, 0, min(len(list), maxDiagnostics))
+	for _, item := range list {
+		if len(result) == maxDiagnostics {
+			break
+		}
+		start := lines.position(item.Pos.Offset)
+		result = append(result, Diagnostic{
+			Range:    Range{Start: start, End: start},
+			Severity: diagnostics.SeverityError,
+			Source:   diagnostics.SourceSyntax,
+			Message:  item.Msg,
+		})
+	}
+	return result
+}
+
+// Symbols returns the outline of src: top-level functions, methods, types,
+// constants and variables, with struct fields and interface methods as
+// children. Detail holds a function's signature or a declared type. A file
+// with parse errors yields what the parser recovered.
+func Symbols(filename string, src []byte) []Symbol {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if file == nil {
+		return []Symbol{}
+	}
+	lines := newLineIndex(src)
+	rangeOf := func(node ast.Node) Range {
+		return Range{Start: lines.position(fset.Position(node.Pos()).Offset), End: lines.position(fset.Position(node.End()).Offset)}
+	}
+	symbol := func(name *ast.Ident, kind int, node ast.Node) Symbol {
+		return Symbol{Name: name.Name, Kind: kind, Range: rangeOf(node), SelectionRange: rangeOf(name)}
+	}
+	detail := func(expr ast.Expr) string {
+		if expr == nil {
+			return ""
+		}
+		var text strings.Builder
+		if err := printer.Fprint(&text, fset, expr); err != nil {
+			return ""
+		}
+		return text.String()
+	}
+
+	symbols := make([]Symbol, 0, len(file.Decls))
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			function := symbol(decl.Name, SymbolFunction, decl)
+			function.Detail = detail(decl.Type)
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				function.Kind = SymbolMethod
+				function.Name = "(" + receiverType(decl.Recv.List[0].Type) + ")." + decl.Name.Name
+			}
+			symbols = append(symbols, function)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					typeSymbol := symbol(spec.Name, SymbolClass, spec)
+					switch typ := spec.Type.(type) {
+					case *ast.StructType:
+						typeSymbol.Kind = SymbolStruct
+						typeSymbol.Detail = "struct"
+						typeSymbol.Children = fieldSymbols(typ.Fields, SymbolField, symbol, detail)
+					case *ast.InterfaceType:
+						typeSymbol.Kind = SymbolInterface
+						typeSymbol.Detail = "interface"
+						typeSymbol.Children = fieldSymbols(typ.Methods, SymbolMethod, symbol, detail)
+					default:
+						typeSymbol.Detail = detail(spec.Type)
+					}
+					symbols = append(symbols, typeSymbol)
+				case *ast.ValueSpec:
+					kind := SymbolVariable
+					if decl.Tok == token.CONST {
+						kind = SymbolConstant
+					}
+					for _, name := range spec.Names {
+						if name.Name != "_" {
+							value := symbol(name, kind, spec)
+							value.Detail = detail(spec.Type)
+							symbols = append(symbols, value)
+						}
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+func fieldSymbols(fields *ast.FieldList, kind int, symbol func(*ast.Ident, int, ast.Node) Symbol, detail func(ast.Expr) string) []Symbol {
+	if fields == nil {
+		return nil
+	}
+	children := make([]Symbol, 0, len(fields.List))
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			child := symbol(name, kind, field)
+			child.Detail = detail(field.Type)
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// SymbolPath returns the symbols enclosing position, outermost first, for
+// breadcrumbs. It is empty outside every symbol.
+func SymbolPath(symbols []Symbol, position Position) []Symbol {
+	path := make([]Symbol, 0, 2)
+	for len(symbols) > 0 {
+		found := false
+		for _, symbol := range symbols {
+			if contains(symbol.Range, position) {
+				path = append(path, symbol)
+				symbols = symbol.Children
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return path
+}
+
+func contains(r Range, position Position) bool {
+	return !before(position, r.Start) && !before(r.End, position)
+}
+
+func before(a Position, b Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
+
+// receiverType renders a method receiver type as gopls names it, such as
+// "*Buffer" or "List[T]".
+func receiverType(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverType(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	case *ast.IndexExpr:
+		return receiverType(expr.X) + "[" + receiverType(expr.Index) + "]"
+	case *ast.IndexListExpr:
+		params := ""
+		for i, index := range expr.Indices {
+			if i > 0 {
+				params += ", "
+			}
+			params += receiverType(index)
+		}
+		return receiverType(expr.X) + "[" + params + "]"
+	case *ast.ParenExpr:
+		return receiverType(expr.X)
+	default:
+		return "?"
+	}
+}
+
+// Format returns the edits that gofmt src. Formatting fails while src does
+// not parse.
+func Format(src []byte) ([]TextEdit, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, err
+	}
+	if string(formatted) == string(src) {
+		return []TextEdit{}, nil
+	}
+	end := newLineIndex(src).position(len(src))
+	return []TextEdit{{Range: Range{End: end}, NewText: string(formatted)}}, nil
+}
+
+// lineIndex converts byte offsets to LSP positions.
+type lineIndex struct {
+	src   []byte
+	start []int
+}
+
+func newLineIndex(src []byte) lineIndex {
+	start := []int{0}
+	for offset, b := range src {
+		if b == '\n' {
+			start = append(start, offset+1)
+		}
+	}
+	return lineIndex{src: src, start: start}
+}
+
+func (l lineIndex) position(offset int) Position {
+	offset = max(0, min(offset, len(l.src)))
+	line := sort.SearchInts(l.start, offset+1) - 1
+	character := 0
+	for _, r := range string(l.src[l.start[line]:offset]) {
+		character += utf16.RuneLen(r)
+	}
+	return Position{Line: line, Character: character}
+}
@@ -0,0 +1,128 @@
+package lite
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDiagnoseReportsParseErrors(t *testing.T) {
+	t.Parallel()
+
+	if got := Diagnose("main.go", []byte("package main\n\nfunc main() {}\n")); len(got) != 0 {
+		t.Fatalf("Diagnose(valid) = %+v, want none", got)
+	}
+	got := Diagnose("main.go", []byte("package main\n\nfunc main() {\n\ts := \"é\" +\n}\n"))
+	if len(got) != 1 {
+		t.Fatalf("Diagnose() = %+v, want one error", got)
+	}
+	if got[0].Range.Start != (Position{Line: 4, Character: 0}) || got[0].Severity != 1 || got[0].Source != "syntax" {
+		t.Fatalf("Diagnose()[0] = %+v, want an error at 4:0", got[0])
+	}
+}
+
+func TestSymbolsOutlineDeclarations(t *testing.T) {
+	t.Parallel()
+
+	src := "package main\n\nconst limit = 3\n\ntype Buffer struct {\n\tdata []byte\n}\n\ntype Reader interface {\n\tRead() int\n}\n\nfunc (b *Buffer) Len() int { return len(b.data) }\n\nfunc main() {}\n"
+	symbols := Symbols("main.go", []byte(src))
+	names := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		names = append(names, fmt.Sprintf("%s:%d", symbol.Name, symbol.Kind))
+	}
+	if got, want := strings.Join(names, " "), "limit:14 Buffer:23 Reader:11 (*Buffer).Len:6 main:12"; got != want {
+		t.Fatalf("Symbols() = %s, want %s", got, want)
+	}
+	buffer := symbols[1]
+	if len(buffer.Children) != 1 || buffer.Children[0].Name != "data" || buffer.Range.Start.Line != 4 || buffer.Range.End.Line != 6 {
+		t.Fatalf("Buffer symbol = %+v, want lines 4-6 with field data", buffer)
+	}
+	if buffer.SelectionRange.Start != (Position{Line: 4, Character: 5}) {
+		t.Fatalf("Buffer selection = %+v, want the name at 4:5", buffer.SelectionRange)
+	}
+}
+
+func TestFormatReplacesDocument(t *testing.T) {
+	t.Parallel()
+
+	edits, err := Format([]byte("package main\nfunc main(){\n}\n"))
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if len(edits) != 1 || edits[0].NewText != "package main\n\nfunc main() {\n}\n" || edits[0].Range.End != (Position{Line: 3}) {
+		t.Fatalf("Format() = %+v, want one whole-document edit", edits)
+	}
+	if edits, err := Format([]byte("package main\n")); err != nil || len(edits) != 0 {
+		t.Fatalf("Format(formatted) = %+v, %v; want no edits", edits, err)
+	}
+	if _, err := Format([]byte("package main\nfunc {")); err == nil {
+		t.Fatal("Format(invalid) error = nil, want error")
+	}
+}
+
+func TestPositionCountsUTF16Units(t *testing.T) {
+	t.Parallel()
+
+	lines := newLineIndex([]byte("a😀b\nxy"))
+	if got := lines.position(len("a😀")); got != (Position{Line: 0, Character: 3}) {
+		t.Fatalf("position(after emoji) = %+v, want 0:3", got)
+	}
+	if got := lines.position(len("a😀b\nx")); got != (Position{Line: 1, Character: 1}) {
+		t.Fatalf("position(second line) = %+v, want 1:1", got)
+	}
+}
+
+func TestServePublishesDiagnosticsAndAnswersRequests(t *testing.T) {
+	t.Parallel()
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- Serve(clientReader, serverWriter) }()
+	responses := bufio.NewReader(serverReader)
+
+	send := func(body string) {
+		t.Helper()
+		if _, err := fmt.Fprintf(clientWriter, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+			t.Fatalf("write message: %v", err)
+		}
+	}
+	receive := func() map[string]any {
+		t.Helper()
+		body, err := readMessage(responses)
+		if err != nil {
+			t.Fatalf("readMessage() error = %v", err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		return decoded
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	if result, _ := receive()["result"].(map[string]any); result["capabilities"] == nil {
+		t.Fatalf("initialize result = %v, want capabilities", result)
+	}
+	send(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///w/main.go","text":"package main\n\nfunc main() {\n"}}}`)
+	published := receive()
+	params, _ := published["params"].(map[string]any)
+	if published["method"] != "textDocument/publishDiagnostics" || len(params["diagnostics"].([]any)) != 1 {
+		t.Fatalf("didOpen notification = %v, want one diagnostic", published)
+	}
+	send(`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{}}`)
+	if response := receive(); response["error"] == nil {
+		t.Fatalf("hover response = %v, want method not found", response)
+	}
+	send(`{"jsonrpc":"2.0","id":3,"method":"shutdown"}`)
+	if response := receive(); response["id"] != float64(3) {
+		t.Fatalf("shutdown response = %v", response)
+	}
+	send(`{"jsonrpc":"2.0","method":"exit"}`)
+	if err := <-done; err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package lite
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ServerName is reported in the initialize result.
+const ServerName = "gopoke-lite"
+
+// JSON-RPC error codes.
+const (
+	codeMethodNotFound = -32601
+	codeRequestFailed  = -32803
+)
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type documentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// server holds the open documents of one session.
+type server struct {
+	out       io.Writer
+	documents map[string][]byte
+}
+
+// Serve answers Content-Length framed LSP messages from in on out until the
+// client sends exit or in ends. Documents sync in full; every open or change
+// publishes the document's parse errors.
+func Serve(in io.Reader, out io.Writer) error {
+	s := &server{out: out, documents: make(map[string][]byte)}
+	reader := bufio.NewReader(in)
+	for {
+		body, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) handle(msg message) error {
+	isRequest := len(msg.ID) > 0
+	var params documentParams
+	if len(msg.Params) > 0 {
+		_ = json.Unmarshal(msg.Params, &params)
+	}
+	uri := params.TextDocument.URI
+
+	switch msg.Method {
+	case "":
+		// A response to a request this server never sends.
+		return nil
+	case "initialize":
+		return s.reply(msg.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":           1,
+				"documentSymbolProvider":     true,
+				"documentFormattingProvider": true,
+				"foldingRangeProvider":       true,
+			},
+			"serverInfo": map[string]string{"name": ServerName},
+		})
+	case "shutdown":
+		return s.reply(msg.ID, nil)
+	case "textDocument/didOpen":
+		s.documents[uri] = []byte(params.TextDocument.Text)
+		return s.publish(uri)
+	case "textDocument/didChange":
+		if len(params.ContentChanges) == 0 {
+			return nil
+		}
+		s.documents[uri] = []byte(params.ContentChanges[len(params.ContentChanges)-1].Text)
+		return s.publish(uri)
+	case "textDocument/didClose":
+		delete(s.documents, uri)
+		return s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": []Diagnostic{}})
+	case "textDocument/documentSymbol":
+		src, ok := s.documents[uri]
+		if !ok {
+			return s.reply(msg.ID, []Symbol{})
+		}
+		return s.reply(msg.ID, Symbols(documentName(uri), src))
+	case "textDocument/foldingRange":
+		src, ok := s.documents[uri]
+		if !ok {
+			return s.reply(msg.ID, []FoldingRange{})
+		}
+		return s.reply(msg.ID, FoldingRanges(documentName(uri), src))
+	case "textDocument/formatting":
+		src, ok := s.documents[uri]
+		if !ok {
+			return s.reply(msg.ID, []TextEdit{})
+		}
+		edits, err := Format(src)
+		if err != nil {
+			return s.fail(msg.ID, codeRequestFailed, fmt.Sprintf("format: %v", err))
+		}
+		return s.reply(msg.ID, edits)
+	}
+	if isRequest {
+		return s.fail(msg.ID, codeMethodNotFound, "method not supported without gopls: "+msg.Method)
+	}
+	return nil
+}
+
+// publish sends the parse errors of a Go document.
+func (s *server) publish(uri string) error {
+	name := documentName(uri)
+	if !strings.HasSuffix(name, ".go") {
+		return nil
+	}
+	return s.notify("textDocument/publishDiagnostics", map[string]any{
+		"uri":         uri,
+		"diagnostics": Diagnose(name, s.documents[uri]),
+	})
+}
+
+func (s *server) reply(id json.RawMessage, result any) error {
+	if result == nil {
+		// A null result must still be present in the response.
+		return s.write(map[string]any{"jsonrpc": "2.0", "id": id, "result": nil})
+	}
+	return s.write(message{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *server) fail(id json.RawMessage, code int, text string) error {
+	return s.write(message{JSONRPC: "2.0", ID: id, Error: &responseError{Code: code, Message: text}})
+}
+
+func (s *server) notify(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", method, err)
+	}
+	return s.write(message{JSONRPC: "2.0", Method: method, Params: raw})
+}
+
+func (s *server) write(value any) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode message: %w", err)
+	}
+	if _, err := fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	return nil
+}
+
+// readMessage reads one Content-Length framed message body.
+func readMessage(reader *bufio.Reader) ([]byte, error) {
+	contentLength := -1
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	body := make([]byte, contentLength)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, fmt.Errorf("read message body: %w", err)
+	}
+	return body, nil
+}
+
+// documentName is the file name of a document URI, used to label parse
+// errors.
+func documentName(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Path == "" {
+		return uri
+	}
+	return path.Base(parsed.Path)
+}
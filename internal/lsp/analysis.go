@@ -0,0 +1,171 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AnalysisState summarizes whether gopls diagnostics are current.
+type AnalysisState string
+
+const (
+	// AnalysisClean means gopls is idle and reported no errors.
+	AnalysisClean AnalysisState = "clean"
+	// AnalysisAnalyzing means edits or gopls work are still in flight, so
+	// published diagnostics may be stale.
+	AnalysisAnalyzing AnalysisState = "analyzing"
+	// AnalysisHasErrors means gopls is idle and reported at least one error.
+	AnalysisHasErrors AnalysisState = "hasErrors"
+)
+
+// analysisSettleTimeout bounds how long an edit keeps the state analyzing
+// when gopls publishes nothing for it, such as when diagnostics are unchanged.
+const analysisSettleTimeout = 3 * time.Second
+
+// AnalysisEvent reports an analysis state change.
+type AnalysisEvent struct {
+	State  AnalysisState `json:"state"`
+	Errors int           `json:"errors"`
+}
+
+// AnalysisHandler receives analysis state changes.
+type AnalysisHandler func(event AnalysisEvent)
+
+// analysisTracker derives the analysis state from edits sent to gopls,
+// $/progress work-done notifications and published diagnostics.
+type analysisTracker struct {
+	mu          sync.Mutex
+	diagnostics *diagnosticStore
+	handler     AnalysisHandler
+	// tokens holds $/progress tokens between their begin and end reports.
+	tokens map[string]struct{}
+	// waiting maps edited document URIs to the edit generation whose
+	// diagnostics are still outstanding.
+	waiting    map[string]int
+	generation int
+	current    AnalysisEvent
+}
+
+func newAnalysisTracker(diagnostics *diagnosticStore) *analysisTracker {
+	return &analysisTracker{
+		diagnostics: diagnostics,
+		tokens:      make(map[string]struct{}),
+		waiting:     make(map[string]int),
+		current:     AnalysisEvent{State: AnalysisClean},
+	}
+}
+
+// edited marks a document as changed and awaiting fresh diagnostics.
+func (t *analysisTracker) edited(uri string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.generation++
+	generation := t.generation
+	t.waiting[uri] = generation
+	time.AfterFunc(analysisSettleTimeout, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.waiting[uri] == generation {
+			delete(t.waiting, uri)
+			t.updateLocked()
+		}
+	})
+	t.updateLocked()
+}
+
+// observe updates the state from a server message. The diagnostic store must
+// observe the message first so error counts are current.
+func (t *analysisTracker) observe(msg []byte) {
+	progress := bytes.Contains(msg, []byte(`"$/progress"`))
+	if !progress && !bytes.Contains(msg, []byte(`"textDocument/publishDiagnostics"`)) {
+		return
+	}
+	var notification struct {
+		Method string `json:"method"`
+		Params struct {
+			URI   string `json:"uri"`
+			Token any    `json:"token"`
+			Value struct {
+				Kind string `json:"kind"`
+			} `json:"value"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(msg, &notification); err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch notification.Method {
+	case "$/progress":
+		token := fmt.Sprint(notification.Params.Token)
+		switch notification.Params.Value.Kind {
+		case "begin":
+			t.tokens[token] = struct{}{}
+		case "end":
+			delete(t.tokens, token)
+		}
+	case "textDocument/publishDiagnostics":
+		delete(t.waiting, notification.Params.URI)
+	default:
+		return
+	}
+	t.updateLocked()
+}
+
+// state returns the latest analysis event.
+func (t *analysisTracker) state() AnalysisEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current
+}
+
+func (t *analysisTracker) setHandler(handler AnalysisHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handler = handler
+}
+
+// reset forgets in-flight work when the gopls session ends.
+func (t *analysisTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.tokens)
+	clear(t.waiting)
+	t.updateLocked()
+}
+
+// updateLocked recomputes the state and notifies the handler on change. The
+// handler runs under the tracker lock so events arrive in order; it must not
+// call back into the tracker.
+func (t *analysisTracker) updateLocked() {
+	next := AnalysisEvent{State: AnalysisAnalyzing}
+	if len(t.tokens) == 0 && len(t.waiting) == 0 {
+		next.Errors = t.diagnostics.errorCount()
+		next.State = AnalysisClean
+		if next.Errors > 0 {
+			next.State = AnalysisHasErrors
+		}
+	}
+	if next == t.current {
+		return
+	}
+	t.current = next
+	if t.handler != nil {
+		t.handler(next)
+	}
+}
+
+// SetAnalysisHandler streams analysis state changes to handler. A nil
+// handler disables streaming.
+func (m *Manager) SetAnalysisHandler(handler AnalysisHandler) {
+	m.analysis.setHandler(handler)
+}
+
+// AnalysisState returns the current analysis state.
+func (m *Manager) AnalysisState() AnalysisEvent {
+	return m.analysis.state()
+}
@@ -0,0 +1,47 @@
+package lsp
+
+import "testing"
+
+func TestAnalysisTrackerStates(t *testing.T) {
+	t.Parallel()
+
+	var store diagnosticStore
+	tracker := newAnalysisTracker(&store)
+	var events []AnalysisEvent
+	tracker.setHandler(func(event AnalysisEvent) { events = append(events, event) })
+
+	observe := func(msg string) {
+		store.observe([]byte(msg))
+		tracker.observe([]byte(msg))
+	}
+
+	tracker.edited("file:///w/main.go")
+	if got := tracker.state().State; got != AnalysisAnalyzing {
+		t.Fatalf("state after edit = %q, want %q", got, AnalysisAnalyzing)
+	}
+	observe(`{"method":"$/progress","params":{"token":7,"value":{"kind":"begin","title":"Loading packages"}}}`)
+	observe(`{"method":"textDocument/publishDiagnostics","params":{"uri":"file:///w/main.go","diagnostics":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":1}},"severity":1,"message":"undefined: x"}]}}`)
+	if got := tracker.state().State; got != AnalysisAnalyzing {
+		t.Fatalf("state during progress = %q, want %q", got, AnalysisAnalyzing)
+	}
+	observe(`{"method":"$/progress","params":{"token":7,"value":{"kind":"end"}}}`)
+	if got := tracker.state(); got != (AnalysisEvent{State: AnalysisHasErrors, Errors: 1}) {
+		t.Fatalf("state after progress end = %+v, want hasErrors with 1 error", got)
+	}
+
+	tracker.edited("file:///w/main.go")
+	observe(`{"method":"textDocument/publishDiagnostics","params":{"uri":"file:///w/main.go","diagnostics":[]}}`)
+	if got := tracker.state().State; got != AnalysisClean {
+		t.Fatalf("state after clean diagnostics = %q, want %q", got, AnalysisClean)
+	}
+
+	want := []AnalysisState{AnalysisAnalyzing, AnalysisHasErrors, AnalysisAnalyzing, AnalysisClean}
+	if len(events) != len(want) {
+		t.Fatalf("events = %+v, want states %v", events, want)
+	}
+	for index, state := range want {
+		if events[index].State != state {
+			t.Fatalf("events[%d] = %+v, want %q", index, events[index], state)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// didChangeDebounce is how long didChange notifications are held so a burst
+// of keystrokes reaches gopls as one change.
+const didChangeDebounce = 150 * time.Millisecond
+
+// changeDebouncer coalesces didChange notifications per document before they
+// are forwarded. Any other message flushes pending changes first, so requests
+// such as completion always see the latest text.
+type changeDebouncer struct {
+	mu      sync.Mutex
+	delay   time.Duration
+	forward func(msg []byte) error
+	// onChange is called for every didChange as it arrives.
+	onChange func(uri string)
+
+	order   []string
+	pending map[string]*pendingChange
+	timer   *time.Timer
+	closed  bool
+	// err is the first forwarding error from a timer flush.
+	err error
+}
+
+// pendingChange accumulates contentChanges for one document. Each change
+// applies to the result of the ones before it, so appending preserves the
+// meaning of the original sequence.
+type pendingChange struct {
+	envelope     map[string]json.RawMessage
+	textDocument json.RawMessage
+	changes      []json.RawMessage
+}
+
+func newChangeDebouncer(delay time.Duration, forward func(msg []byte) error, onChange func(uri string)) *changeDebouncer {
+	return &changeDebouncer{
+		delay:    delay,
+		forward:  forward,
+		onChange: onChange,
+		pending:  make(map[string]*pendingChange),
+	}
+}
+
+// submit forwards msg, holding didChange notifications for the debounce delay.
+func (d *changeDebouncer) submit(msg []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.err != nil {
+		return d.err
+	}
+
+	uri, envelope, params, ok := parseDidChange(msg)
+	if !ok {
+		if err := d.flushLocked(); err != nil {
+			return err
+		}
+		return d.forward(msg)
+	}
+
+	if d.onChange != nil {
+		d.onChange(uri)
+	}
+	pending := d.pending[uri]
+	if pending == nil {
+		pending = &pendingChange{}
+		d.pending[uri] = pending
+		d.order = append(d.order, uri)
+	}
+	pending.envelope = envelope
+	pending.textDocument = params.TextDocument
+	pending.changes = append(pending.changes, params.ContentChanges...)
+
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.delay, d.flushTimer)
+	} else {
+		d.timer.Reset(d.delay)
+	}
+	return nil
+}
+
+// close drops pending changes and stops the timer; nothing is forwarded after it returns.
+func (d *changeDebouncer) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.order = nil
+	clear(d.pending)
+}
+
+func (d *changeDebouncer) flushTimer() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed || d.err != nil {
+		return
+	}
+	d.err = d.flushLocked()
+}
+
+func (d *changeDebouncer) flushLocked() error {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	order := d.order
+	d.order = nil
+	for _, uri := range order {
+		pending := d.pending[uri]
+		delete(d.pending, uri)
+
+		rawParams, err := json.Marshal(map[string]any{
+			"textDocument":   pending.textDocument,
+			"contentChanges": pending.changes,
+		})
+		if err != nil {
+			return err
+		}
+		pending.envelope["params"] = rawParams
+		msg, err := json.Marshal(pending.envelope)
+		if err != nil {
+			return err
+		}
+		if err := d.forward(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type didChangeParams struct {
+	TextDocument   json.RawMessage   `json:"textDocument"`
+	ContentChanges []json.RawMessage `json:"contentChanges"`
+}
+
+func parseDidChange(msg []byte) (string, map[string]json.RawMessage, didChangeParams, bool) {
+	var params didChangeParams
+	if !bytes.Contains(msg, []byte(`"textDocument/didChange"`)) {
+		return "", nil, params, false
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return "", nil, params, false
+	}
+	var method string
+	if err := json.Unmarshal(envelope["method"], &method); err != nil || method != "textDocument/didChange" {
+		return "", nil, params, false
+	}
+	if err := json.Unmarshal(envelope["params"], &params); err != nil {
+		return "", nil, params, false
+	}
+	var document struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params.TextDocument, &document); err != nil || document.URI == "" {
+		return "", nil, params, false
+	}
+	return document.URI, envelope, params, true
+}
@@ -0,0 +1,83 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestChangeDebouncerCoalescesAndFlushesBeforeRequests(t *testing.T) {
+	t.Parallel()
+
+	var forwarded []string
+	var changed []string
+	debouncer := newChangeDebouncer(time.Hour, func(msg []byte) error {
+		forwarded = append(forwarded, string(msg))
+		return nil
+	}, func(uri string) { changed = append(changed, uri) })
+	defer debouncer.close()
+
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.go","version":2},"contentChanges":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":0}},"text":"x"}]}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.go","version":3},"contentChanges":[{"range":{"start":{"line":0,"character":1},"end":{"line":0,"character":1}},"text":"y"}]}}`,
+	} {
+		if err := debouncer.submit([]byte(msg)); err != nil {
+			t.Fatalf("submit() error = %v", err)
+		}
+	}
+	if len(forwarded) != 0 {
+		t.Fatalf("forwarded before flush = %v", forwarded)
+	}
+	if len(changed) != 2 {
+		t.Fatalf("onChange calls = %v, want 2", changed)
+	}
+
+	completion := `{"jsonrpc":"2.0","id":5,"method":"textDocument/completion","params":{}}`
+	if err := debouncer.submit([]byte(completion)); err != nil {
+		t.Fatalf("submit() error = %v", err)
+	}
+	if len(forwarded) != 2 || forwarded[1] != completion {
+		t.Fatalf("forwarded = %v, want merged change then completion", forwarded)
+	}
+
+	var merged struct {
+		Method string `json:"method"`
+		Params struct {
+			TextDocument struct {
+				Version int `json:"version"`
+			} `json:"textDocument"`
+			ContentChanges []contentChange `json:"contentChanges"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(forwarded[0]), &merged); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if merged.Method != "textDocument/didChange" || merged.Params.TextDocument.Version != 3 {
+		t.Fatalf("merged = %+v, want didChange at version 3", merged)
+	}
+	text, err := applyContentChanges("", merged.Params.ContentChanges)
+	if err != nil || text != "xy" {
+		t.Fatalf("merged changes produce %q (%v), want %q", text, err, "xy")
+	}
+}
+
+func TestChangeDebouncerFlushesAfterDelay(t *testing.T) {
+	t.Parallel()
+
+	forwarded := make(chan []byte, 1)
+	debouncer := newChangeDebouncer(10*time.Millisecond, func(msg []byte) error {
+		forwarded <- msg
+		return nil
+	}, nil)
+	defer debouncer.close()
+
+	msg := `{"method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.go","version":2},"contentChanges":[{"text":"package a\n"}]}}`
+	if err := debouncer.submit([]byte(msg)); err != nil {
+		t.Fatalf("submit() error = %v", err)
+	}
+	select {
+	case <-forwarded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("didChange was not flushed after the debounce delay")
+	}
+}
@@ -0,0 +1,121 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// LiveDiagnostic is one diagnostic gopls published for an open document.
+// Lines and columns are 1-based to match compiler output.
+type LiveDiagnostic struct {
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Severity  int    `json:"severity"`
+	Source    string `json:"source,omitempty"`
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message"`
+}
+
+// diagnosticStore keeps the latest publishDiagnostics payload per document URI.
+type diagnosticStore struct {
+	mu    sync.RWMutex
+	byURI map[string][]LiveDiagnostic
+}
+
+type publishDiagnosticsParams struct {
+	URI         string `json:"uri"`
+	Diagnostics []struct {
+		Range struct {
+			Start struct {
+				Line      int `json:"line"`
+				Character int `json:"character"`
+			} `json:"start"`
+			End struct {
+				Line      int `json:"line"`
+				Character int `json:"character"`
+			} `json:"end"`
+		} `json:"range"`
+		Severity int    `json:"severity"`
+		Code     any    `json:"code"`
+		Source   string `json:"source"`
+		Message  string `json:"message"`
+	} `json:"diagnostics"`
+}
+
+// observe records diagnostics from a server message. Other messages are ignored.
+func (s *diagnosticStore) observe(msg []byte) {
+	if !bytes.Contains(msg, []byte(`"textDocument/publishDiagnostics"`)) {
+		return
+	}
+	var notification struct {
+		Method string                   `json:"method"`
+		Params publishDiagnosticsParams `json:"params"`
+	}
+	if err := json.Unmarshal(msg, &notification); err != nil || notification.Method != "textDocument/publishDiagnostics" {
+		return
+	}
+
+	diagnostics := make([]LiveDiagnostic, 0, len(notification.Params.Diagnostics))
+	for _, item := range notification.Params.Diagnostics {
+		diagnostic := LiveDiagnostic{
+			Line:      item.Range.Start.Line + 1,
+			Column:    item.Range.Start.Character + 1,
+			EndLine:   item.Range.End.Line + 1,
+			EndColumn: item.Range.End.Character + 1,
+			Severity:  item.Severity,
+			Source:    item.Source,
+			Message:   item.Message,
+		}
+		if item.Code != nil {
+			diagnostic.Code = fmt.Sprint(item.Code)
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byURI == nil {
+		s.byURI = make(map[string][]LiveDiagnostic)
+	}
+	if len(diagnostics) == 0 {
+		delete(s.byURI, notification.Params.URI)
+		return
+	}
+	s.byURI[notification.Params.URI] = diagnostics
+}
+
+func (s *diagnosticStore) get(uri string) []LiveDiagnostic {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]LiveDiagnostic(nil), s.byURI[uri]...)
+}
+
+// errorCount returns the number of error-severity diagnostics across documents.
+func (s *diagnosticStore) errorCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count := 0
+	for _, diagnostics := range s.byURI {
+		for _, diagnostic := range diagnostics {
+			if diagnostic.Severity == 1 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func (s *diagnosticStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byURI = nil
+}
+
+// LiveDiagnostics returns the latest gopls diagnostics for a document URI.
+func (m *Manager) LiveDiagnostics(uri string) []LiveDiagnostic {
+	return m.diagnostics.get(uri)
+}
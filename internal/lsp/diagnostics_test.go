@@ -0,0 +1,25 @@
+package lsp
+
+import "testing"
+
+func TestDiagnosticStoreObservesPublishDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	var store diagnosticStore
+	store.observe([]byte(`{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"uri":"file:///w/main.go","diagnostics":[{"range":{"start":{"line":3,"character":1},"end":{"line":3,"character":2}},"severity":1,"code":"UnusedVar","source":"compiler","message":"declared and not used: x"}]}}`))
+	store.observe([]byte(`{"jsonrpc":"2.0","id":4,"result":{"contents":"textDocument/publishDiagnostics"}}`))
+
+	got := store.get("file:///w/main.go")
+	if len(got) != 1 {
+		t.Fatalf("len(diagnostics) = %d, want 1", len(got))
+	}
+	want := LiveDiagnostic{Line: 4, Column: 2, EndLine: 4, EndColumn: 3, Severity: 1, Source: "compiler", Code: "UnusedVar", Message: "declared and not used: x"}
+	if got[0] != want {
+		t.Fatalf("diagnostic = %+v, want %+v", got[0], want)
+	}
+
+	store.observe([]byte(`{"method":"textDocument/publishDiagnostics","params":{"uri":"file:///w/main.go","diagnostics":[]}}`))
+	if got := store.get("file:///w/main.go"); len(got) != 0 {
+		t.Fatalf("diagnostics after clear = %+v, want none", got)
+	}
+}
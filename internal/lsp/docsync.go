@@ -0,0 +1,318 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Text document sync kinds from the LSP specification.
+const (
+	syncKindFull        = 1
+	syncKindIncremental = 2
+)
+
+// position is an LSP position. Character counts UTF-16 code units, the
+// protocol's default position encoding.
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+// contentChange is one TextDocumentContentChangeEvent. A nil Range replaces
+// the whole document.
+type contentChange struct {
+	Range *textRange `json:"range,omitempty"`
+	Text  string     `json:"text"`
+}
+
+// documentSync mirrors open documents for one gopls session so didChange
+// traffic can be translated between the sync kinds the editor and gopls use.
+// The editor is always offered incremental sync; full-content changes it still
+// sends are reduced to the edited range before they reach gopls, and ranged
+// changes are expanded again if gopls only accepts full content.
+type documentSync struct {
+	mu           sync.Mutex
+	docs         map[string]string
+	initializeID string
+	// serverKind is the sync kind gopls announced in its initialize result.
+	serverKind int
+}
+
+func newDocumentSync() *documentSync {
+	return &documentSync{docs: make(map[string]string), serverKind: syncKindFull}
+}
+
+// rewriteClient tracks document lifecycle messages from the editor and
+// rewrites didChange content changes to the sync kind gopls negotiated.
+func (s *documentSync) rewriteClient(msg []byte) []byte {
+	if !bytes.Contains(msg, []byte(`"initialize"`)) && !bytes.Contains(msg, []byte(`"textDocument/did`)) {
+		return msg
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return msg
+	}
+	var method string
+	if err := json.Unmarshal(envelope["method"], &method); err != nil {
+		return msg
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch method {
+	case "initialize":
+		s.initializeID = string(envelope["id"])
+	case "textDocument/didOpen":
+		var params struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(envelope["params"], &params); err == nil {
+			s.docs[params.TextDocument.URI] = params.TextDocument.Text
+		}
+	case "textDocument/didClose":
+		var params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(envelope["params"], &params); err == nil {
+			delete(s.docs, params.TextDocument.URI)
+		}
+	case "textDocument/didChange":
+		return s.rewriteDidChangeLocked(msg, envelope)
+	}
+	return msg
+}
+
+func (s *documentSync) rewriteDidChangeLocked(msg []byte, envelope map[string]json.RawMessage) []byte {
+	var params struct {
+		TextDocument   json.RawMessage `json:"textDocument"`
+		ContentChanges []contentChange `json:"contentChanges"`
+	}
+	if err := json.Unmarshal(envelope["params"], &params); err != nil {
+		return msg
+	}
+	var document struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params.TextDocument, &document); err != nil {
+		return msg
+	}
+
+	previous, known := s.docs[document.URI]
+	text, err := applyContentChanges(previous, params.ContentChanges)
+	if err != nil || (!known && hasRangedChange(params.ContentChanges)) {
+		// The mirror cannot follow this document any more; forward the
+		// editor's changes untouched and stop translating them.
+		delete(s.docs, document.URI)
+		return msg
+	}
+	s.docs[document.URI] = text
+
+	var changes []contentChange
+	switch {
+	case s.serverKind == syncKindIncremental && known && !hasRangedChange(params.ContentChanges):
+		changes = []contentChange{diffChange(previous, text)}
+	case s.serverKind != syncKindIncremental && hasRangedChange(params.ContentChanges):
+		changes = []contentChange{{Text: text}}
+	default:
+		return msg
+	}
+
+	rawParams, err := json.Marshal(map[string]any{
+		"textDocument":   params.TextDocument,
+		"contentChanges": changes,
+	})
+	if err != nil {
+		return msg
+	}
+	envelope["params"] = rawParams
+	rewritten, err := json.Marshal(envelope)
+	if err != nil {
+		return msg
+	}
+	return rewritten
+}
+
+// rewriteServer records the sync kind gopls announces in its initialize
+// result and advertises incremental sync to the editor in its place.
+func (s *documentSync) rewriteServer(msg []byte) []byte {
+	if !bytes.Contains(msg, []byte(`"textDocumentSync"`)) {
+		return msg
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return msg
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initializeID == "" || string(envelope["id"]) != s.initializeID {
+		return msg
+	}
+	var result map[string]any
+	if err := json.Unmarshal(envelope["result"], &result); err != nil || result == nil {
+		return msg
+	}
+	capabilities, _ := result["capabilities"].(map[string]any)
+	if capabilities == nil {
+		return msg
+	}
+
+	switch value := capabilities["textDocumentSync"].(type) {
+	case float64:
+		s.serverKind = int(value)
+		capabilities["textDocumentSync"] = syncKindIncremental
+	case map[string]any:
+		if kind, ok := value["change"].(float64); ok {
+			s.serverKind = int(kind)
+		}
+		value["change"] = syncKindIncremental
+	default:
+		return msg
+	}
+
+	rawResult, err := json.Marshal(result)
+	if err != nil {
+		return msg
+	}
+	envelope["result"] = rawResult
+	rewritten, err := json.Marshal(envelope)
+	if err != nil {
+		return msg
+	}
+	return rewritten
+}
+
+func hasRangedChange(changes []contentChange) bool {
+	for _, change := range changes {
+		if change.Range != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyContentChanges applies changes in order. As the protocol requires,
+// each range refers to the document produced by the changes before it, which
+// is how editors report multi-cursor edits.
+func applyContentChanges(text string, changes []contentChange) (string, error) {
+	for index, change := range changes {
+		if change.Range == nil {
+			text = change.Text
+			continue
+		}
+		start, err := offsetAt(text, change.Range.Start)
+		if err != nil {
+			return "", fmt.Errorf("change %d start: %w", index, err)
+		}
+		end, err := offsetAt(text, change.Range.End)
+		if err != nil {
+			return "", fmt.Errorf("change %d end: %w", index, err)
+		}
+		if end < start {
+			return "", fmt.Errorf("change %d: range end precedes start", index)
+		}
+		text = text[:start] + change.Text + text[end:]
+	}
+	return text, nil
+}
+
+// offsetAt converts an LSP position into a byte offset in text. Characters
+// past the end of a line clamp to the line end, as the protocol specifies.
+func offsetAt(text string, pos position) (int, error) {
+	if pos.Line < 0 || pos.Character < 0 {
+		return 0, fmt.Errorf("invalid position %d:%d", pos.Line, pos.Character)
+	}
+	lineStart := 0
+	for line := 0; line < pos.Line; line++ {
+		newline := strings.IndexByte(text[lineStart:], '\n')
+		if newline < 0 {
+			if line == pos.Line-1 && pos.Character == 0 {
+				return len(text), nil
+			}
+			return 0, fmt.Errorf("line %d is past the end of the document", pos.Line)
+		}
+		lineStart += newline + 1
+	}
+
+	lineEnd := len(text)
+	if newline := strings.IndexByte(text[lineStart:], '\n'); newline >= 0 {
+		lineEnd = lineStart + newline
+	}
+	if lineEnd > lineStart && text[lineEnd-1] == '\r' {
+		lineEnd--
+	}
+
+	offset := lineStart
+	units := 0
+	for offset < lineEnd && units < pos.Character {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		units += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset, nil
+}
+
+// positionAt converts a byte offset in text into an LSP position.
+func positionAt(text string, offset int) position {
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+	units := 0
+	for _, r := range text[lineStart:offset] {
+		units += utf16.RuneLen(r)
+	}
+	return position{Line: strings.Count(text[:offset], "\n"), Character: units}
+}
+
+// diffChange returns a single ranged change that turns previous into next by
+// replacing the span between their common prefix and common suffix.
+func diffChange(previous, next string) contentChange {
+	limit := min(len(previous), len(next))
+	prefix := 0
+	for prefix < limit && previous[prefix] == next[prefix] {
+		prefix++
+	}
+	// Never split a rune or a CRLF pair.
+	for prefix > 0 && ((prefix < len(previous) && !utf8.RuneStart(previous[prefix])) ||
+		(prefix < len(next) && !utf8.RuneStart(next[prefix])) || previous[prefix-1] == '\r') {
+		prefix--
+	}
+
+	suffix := 0
+	for suffix < limit-prefix && previous[len(previous)-1-suffix] == next[len(next)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && (!utf8.RuneStart(previous[len(previous)-suffix]) ||
+		(previous[len(previous)-suffix] == '\n' && len(previous)-suffix > 0 && previous[len(previous)-suffix-1] == '\r')) {
+		suffix--
+	}
+
+	return contentChange{
+		Range: &textRange{
+			Start: positionAt(previous, prefix),
+			End:   positionAt(previous, len(previous)-suffix),
+		},
+		Text: next[prefix : len(next)-suffix],
+	}
+}
+
+// text returns the mirrored content of an open document.
+func (s *documentSync) text(uri string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	text, ok := s.docs[uri]
+	return text, ok
+}
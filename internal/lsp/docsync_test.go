@@ -0,0 +1,162 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyContentChangesMultiCursor(t *testing.T) {
+	t.Parallel()
+
+	text := "a := 1\nb := 2\n"
+	// Two cursors typing "x" at the start of each line; the second range is
+	// relative to the document after the first insertion.
+	changes := []contentChange{
+		{Range: &textRange{Start: position{0, 0}, End: position{0, 0}}, Text: "x"},
+		{Range: &textRange{Start: position{1, 0}, End: position{1, 0}}, Text: "x"},
+	}
+	got, err := applyContentChanges(text, changes)
+	if err != nil {
+		t.Fatalf("applyContentChanges() error = %v", err)
+	}
+	if want := "xa := 1\nxb := 2\n"; got != want {
+		t.Fatalf("applyContentChanges() = %q, want %q", got, want)
+	}
+
+	if _, err := applyContentChanges(text, []contentChange{{Range: &textRange{Start: position{5, 0}, End: position{5, 0}}}}); err == nil {
+		t.Fatal("applyContentChanges(out of range) error = nil, want non-nil")
+	}
+}
+
+func TestOffsetAtCountsUTF16Units(t *testing.T) {
+	t.Parallel()
+
+	text := "s := \"😀é\"\r\nx"
+	tests := []struct {
+		pos  position
+		want int
+	}{
+		{position{0, 6}, 6},
+		{position{0, 8}, 10},  // after the surrogate pair
+		{position{0, 9}, 12},  // after é
+		{position{0, 99}, 13}, // clamps before \r\n
+		{position{1, 1}, 16},
+	}
+	for _, tt := range tests {
+		got, err := offsetAt(text, tt.pos)
+		if err != nil {
+			t.Fatalf("offsetAt(%+v) error = %v", tt.pos, err)
+		}
+		if got != tt.want {
+			t.Fatalf("offsetAt(%+v) = %d, want %d", tt.pos, got, tt.want)
+		}
+		if tt.pos.Character < 99 && positionAt(text, got) != tt.pos {
+			t.Fatalf("positionAt(%d) = %+v, want %+v", got, positionAt(text, got), tt.pos)
+		}
+	}
+}
+
+func TestDiffChangeRoundTrips(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct{ previous, next string }{
+		{"func main() {\n}\n", "func main() {\n\tprintln()\n}\n"},
+		{"x := \"é\"\n", "x := \"è\"\n"},
+		{"a\r\nb\r\n", "a\r\nc\r\nb\r\n"},
+		{"same", "same"},
+		{"", "package main\n"},
+		{"remove me\n", ""},
+		{"\n", "a\n"},
+		{"\nabc", "x\nabc"},
+	}
+	for _, tt := range tests {
+		change := diffChange(tt.previous, tt.next)
+		got, err := applyContentChanges(tt.previous, []contentChange{change})
+		if err != nil {
+			t.Fatalf("apply diff of %q: %v", tt.previous, err)
+		}
+		if got != tt.next {
+			t.Fatalf("diffChange(%q, %q) produced %q via %+v", tt.previous, tt.next, got, change)
+		}
+	}
+
+	change := diffChange("func main() {\n}\n", "func main() {\n\tprintln()\n}\n")
+	if change.Text != "\tprintln()\n" || change.Range.Start != (position{1, 0}) {
+		t.Fatalf("diffChange() = %+v %q, want minimal insertion at 1:0", change.Range, change.Text)
+	}
+}
+
+func TestDocumentSyncNegotiatesIncrementalChanges(t *testing.T) {
+	t.Parallel()
+
+	sync := newDocumentSync()
+	sync.rewriteClient([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`))
+	response := sync.rewriteServer([]byte(`{"jsonrpc":"2.0","id":1,"result":{"capabilities":{"textDocumentSync":{"openClose":true,"change":2}}}}`))
+	var initialized struct {
+		Result struct {
+			Capabilities struct {
+				TextDocumentSync struct {
+					Change int `json:"change"`
+				} `json:"textDocumentSync"`
+			} `json:"capabilities"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(response, &initialized); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if initialized.Result.Capabilities.TextDocumentSync.Change != syncKindIncremental {
+		t.Fatalf("advertised sync = %s, want incremental", response)
+	}
+
+	sync.rewriteClient([]byte(`{"method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.go","version":1,"text":"package a\n"}}}`))
+	full := []byte(`{"method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.go","version":2},"contentChanges":[{"text":"package a\n\nvar x int\n"}]}}`)
+	var decoded struct {
+		Params struct {
+			TextDocument struct {
+				Version int `json:"version"`
+			} `json:"textDocument"`
+			ContentChanges []contentChange `json:"contentChanges"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(sync.rewriteClient(full), &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	changes := decoded.Params.ContentChanges
+	if len(changes) != 1 || changes[0].Range == nil || changes[0].Text != "\nvar x int\n" {
+		t.Fatalf("forwarded changes = %+v, want one ranged insertion", changes)
+	}
+	if decoded.Params.TextDocument.Version != 2 {
+		t.Fatalf("version = %d, want 2", decoded.Params.TextDocument.Version)
+	}
+
+	ranged := []byte(`{"method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.go","version":3},"contentChanges":[{"range":{"start":{"line":2,"character":4},"end":{"line":2,"character":5}},"text":"y"}]}}`)
+	if got := sync.rewriteClient(ranged); string(got) != string(ranged) {
+		t.Fatalf("ranged change rewritten to %s", got)
+	}
+	if got := sync.docs["file:///a.go"]; got != "package a\n\nvar y int\n" {
+		t.Fatalf("mirrored text = %q", got)
+	}
+}
+
+func TestDocumentSyncExpandsRangesForFullSyncServer(t *testing.T) {
+	t.Parallel()
+
+	sync := newDocumentSync()
+	sync.rewriteClient([]byte(`{"id":"init","method":"initialize","params":{}}`))
+	sync.rewriteServer([]byte(`{"id":"init","result":{"capabilities":{"textDocumentSync":1}}}`))
+	sync.rewriteClient([]byte(`{"method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///a.go","text":"ab"}}}`))
+
+	msg := []byte(`{"method":"textDocument/didChange","params":{"textDocument":{"uri":"file:///a.go"},"contentChanges":[{"range":{"start":{"line":0,"character":1},"end":{"line":0,"character":1}},"text":"X"}]}}`)
+	var decoded struct {
+		Params struct {
+			ContentChanges []contentChange `json:"contentChanges"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(sync.rewriteClient(msg), &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	changes := decoded.Params.ContentChanges
+	if len(changes) != 1 || changes[0].Range != nil || changes[0].Text != "aXb" {
+		t.Fatalf("forwarded changes = %+v, want full content", changes)
+	}
+}
@@ -15,15 +15,23 @@ type Manager struct {
 	workspace   *workspace
 	projectPath string
 	ready       bool
+	mode        string
 	lastError   string
 	logger      *slog.Logger
+	diagnostics diagnosticStore
+	analysis    *analysisTracker
+	memory      memoryWatch
+	requests    requestWatch
+	tokens      tokenWatch
 }
 
 // NewManager creates an LSP manager.
 func NewManager() *Manager {
-	return &Manager{
+	m := &Manager{
 		logger: slog.Default(),
 	}
+	m.analysis = newAnalysisTracker(&m.diagnostics)
+	return m
 }
 
 // StartForProject starts the LSP proxy for a project. Stops any existing session first.
@@ -35,10 +43,13 @@ func (m *Manager) StartForProject(ctx context.Context, projectPath string) error
 		m.stopLocked()
 	}
 
+	// Without gopls the proxy serves syntax-only feedback, so the editor
+	// still shows parse errors, an outline and formatting.
 	goplsPath := findGoplsBinary()
+	mode := ModeGopls
 	if goplsPath == "" {
-		m.lastError = "gopls not found in PATH"
-		return fmt.Errorf("gopls not found in PATH; install with: go install golang.org/x/tools/gopls@latest")
+		mode = ModeLite
+		m.logger.Info("gopls not found in PATH; using syntax-only language server", "install", "go install golang.org/x/tools/gopls@latest")
 	}
 
 	ws, err := createWorkspace(projectPath)
@@ -54,10 +65,18 @@ func (m *Manager) StartForProject(ctx context.Context, projectPath string) error
 		return fmt.Errorf("create proxy: %w", err)
 	}
 
+	proxy.projectDir = projectPath
+	proxy.env = ws.env
+	proxy.diagnostics = &m.diagnostics
+	proxy.analysis = m.analysis
+	proxy.memory = &m.memory
+	proxy.requests = &m.requests
+	proxy.tokens = &m.tokens
 	m.proxy = proxy
 	m.workspace = ws
 	m.projectPath = projectPath
 	m.ready = true
+	m.mode = mode
 	m.lastError = ""
 
 	go func() {
@@ -74,6 +93,21 @@ func (m *Manager) StartForProject(ctx context.Context, projectPath string) error
 	return nil
 }
 
+// RestartIfLite restarts a syntax-only session for the same project so it
+// picks up gopls once it is installed. It reports whether it restarted.
+func (m *Manager) RestartIfLite(ctx context.Context) (bool, error) {
+	m.mu.RLock()
+	projectPath, mode := m.projectPath, m.mode
+	m.mu.RUnlock()
+	if mode != ModeLite || projectPath == "" || findGoplsBinary() == "" {
+		return false, nil
+	}
+	if err := m.StartForProject(ctx, projectPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // Port returns the WebSocket proxy port, or 0 if not running.
 func (m *Manager) Port() int {
 	m.mu.RLock()
@@ -103,6 +137,7 @@ func (m *Manager) Status() StatusResult {
 	defer m.mu.RUnlock()
 	return StatusResult{
 		Ready: m.ready,
+		Mode:  m.mode,
 		Error: m.lastError,
 	}
 }
@@ -127,6 +162,9 @@ func (m *Manager) stopLocked() {
 		m.workspace = nil
 	}
 
+	m.diagnostics.reset()
+	m.analysis.reset()
 	m.ready = false
+	m.mode = ""
 	m.projectPath = ""
 }
@@ -0,0 +1,179 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gopoke/internal/idle"
+	"gopoke/internal/procmem"
+)
+
+// memoryPollInterval is how often the watchdog samples gopls resident memory.
+const memoryPollInterval = 5 * time.Second
+
+// memoryModeDegradeClosed asks gopls to drop state for closed files.
+const memoryModeDegradeClosed = "DegradeClosed"
+
+// MemoryPolicy configures the gopls memory watchdog.
+type MemoryPolicy struct {
+	// LimitBytes is the resident memory threshold. Zero disables the watchdog.
+	LimitBytes int64
+	// RestartDegraded restarts gopls with memoryMode=DegradeClosed once the
+	// limit is exceeded.
+	RestartDegraded bool
+}
+
+// MemoryWarning reports gopls exceeding the memory limit.
+type MemoryWarning struct {
+	PID           int   `json:"pid"`
+	ResidentBytes int64 `json:"residentBytes"`
+	LimitBytes    int64 `json:"limitBytes"`
+	// Restarted is set when the session was closed so the editor reconnects
+	// to a gopls running in degraded memory mode.
+	Restarted bool `json:"restarted"`
+}
+
+// MemoryHandler receives gopls memory warnings.
+type MemoryHandler func(warning MemoryWarning)
+
+// memoryWatch holds the watchdog policy and handler shared with the proxy.
+// The watchdog stops sampling while gate is paused.
+type memoryWatch struct {
+	mu      sync.Mutex
+	policy  MemoryPolicy
+	handler MemoryHandler
+	gate    idle.Gate
+}
+
+func (w *memoryWatch) snapshot() (MemoryPolicy, MemoryHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.policy, w.handler
+}
+
+// SetMemoryPolicy replaces the gopls memory watchdog policy. It applies to
+// running sessions from their next sample.
+func (m *Manager) SetMemoryPolicy(policy MemoryPolicy) {
+	m.memory.mu.Lock()
+	defer m.memory.mu.Unlock()
+	m.memory.policy = policy
+}
+
+// SetMemoryHandler streams gopls memory warnings to handler. A nil handler
+// disables streaming.
+func (m *Manager) SetMemoryHandler(handler MemoryHandler) {
+	m.memory.mu.Lock()
+	defer m.memory.mu.Unlock()
+	m.memory.handler = handler
+}
+
+// Pause stops the memory watchdog's sampling until Resume, for idle
+// periods.
+func (m *Manager) Pause() {
+	m.memory.gate.Pause()
+}
+
+// Resume restarts the memory watchdog's sampling.
+func (m *Manager) Resume() {
+	m.memory.gate.Resume()
+}
+
+// watchMemory samples the resident memory of pid until ctx ends. It warns
+// once each time usage crosses the limit and, when the policy asks for it,
+// switches the proxy to degraded mode and closes the session so the editor
+// reconnects to a fresh gopls.
+func (p *Proxy) watchMemory(ctx context.Context, pid int, interval time.Duration, closeSession func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	warned := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if resumed := p.memory.gate.Paused(); resumed != nil {
+			ticker.Stop()
+			select {
+			case <-ctx.Done():
+				return
+			case <-resumed:
+			}
+			ticker.Reset(interval)
+			continue
+		}
+
+		policy, handler := p.memory.snapshot()
+		if policy.LimitBytes <= 0 {
+			continue
+		}
+		resident, ok := procmem.ResidentBytes(pid)
+		if !ok {
+			continue
+		}
+		if resident <= policy.LimitBytes {
+			warned = false
+			continue
+		}
+		if warned {
+			continue
+		}
+		warned = true
+
+		warning := MemoryWarning{PID: pid, ResidentBytes: resident, LimitBytes: policy.LimitBytes}
+		// A gopls already running degraded is not restarted again.
+		if policy.RestartDegraded && p.degradeMemory.CompareAndSwap(false, true) {
+			warning.Restarted = true
+		}
+		p.logger.Warn("gopls memory limit exceeded",
+			"pid", pid, "residentBytes", resident, "limitBytes", policy.LimitBytes, "restart", warning.Restarted)
+		if handler != nil {
+			handler(warning)
+		}
+		if warning.Restarted {
+			closeSession()
+			return
+		}
+	}
+}
+
+// withMemoryMode sets the gopls memoryMode initialization option on an
+// initialize request. Other messages pass through untouched.
+func withMemoryMode(msg []byte, mode string) []byte {
+	if !bytes.Contains(msg, []byte(`"initialize"`)) {
+		return msg
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return msg
+	}
+	var method string
+	if err := json.Unmarshal(envelope["method"], &method); err != nil || method != "initialize" {
+		return msg
+	}
+	var params map[string]any
+	if err := json.Unmarshal(envelope["params"], &params); err != nil || params == nil {
+		return msg
+	}
+	options, _ := params["initializationOptions"].(map[string]any)
+	if options == nil {
+		options = make(map[string]any)
+	}
+	options["memoryMode"] = mode
+	params["initializationOptions"] = options
+
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return msg
+	}
+	envelope["params"] = rawParams
+	rewritten, err := json.Marshal(envelope)
+	if err != nil {
+		return msg
+	}
+	return rewritten
+}
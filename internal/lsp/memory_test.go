@@ -0,0 +1,75 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"gopoke/internal/procmem"
+)
+
+func TestWithMemoryModeSetsInitializationOption(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"initializationOptions":{"staticcheck":true}}}`)
+	var decoded struct {
+		Params struct {
+			InitializationOptions map[string]any `json:"initializationOptions"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(withMemoryMode(msg, memoryModeDegradeClosed), &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	options := decoded.Params.InitializationOptions
+	if options["memoryMode"] != memoryModeDegradeClosed || options["staticcheck"] != true {
+		t.Fatalf("initializationOptions = %v, want memoryMode added and staticcheck kept", options)
+	}
+
+	initialized := []byte(`{"jsonrpc":"2.0","method":"initialized","params":{}}`)
+	if got := withMemoryMode(initialized, memoryModeDegradeClosed); string(got) != string(initialized) {
+		t.Fatalf("initialized rewritten to %s", got)
+	}
+}
+
+func TestWatchMemoryWarnsAndRestartsDegraded(t *testing.T) {
+	t.Parallel()
+
+	pid := os.Getpid()
+	if _, ok := procmem.ResidentBytes(pid); !ok {
+		t.Skip("resident memory sampling unsupported on this platform")
+	}
+
+	warnings := make(chan MemoryWarning, 1)
+	proxy := &Proxy{
+		logger: slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})),
+		memory: &memoryWatch{
+			policy:  MemoryPolicy{LimitBytes: 1, RestartDegraded: true},
+			handler: func(warning MemoryWarning) { warnings <- warning },
+		},
+	}
+
+	closed := make(chan struct{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go proxy.watchMemory(ctx, pid, time.Millisecond, func() { close(closed) })
+
+	select {
+	case warning := <-warnings:
+		if warning.PID != pid || warning.ResidentBytes <= warning.LimitBytes || !warning.Restarted {
+			t.Fatalf("warning = %+v, want restart past the limit", warning)
+		}
+	case <-ctx.Done():
+		t.Fatal("no memory warning before timeout")
+	}
+	select {
+	case <-closed:
+	case <-ctx.Done():
+		t.Fatal("session not closed after restart warning")
+	}
+	if !proxy.degradeMemory.Load() {
+		t.Fatal("degradeMemory = false after restart, want true")
+	}
+}
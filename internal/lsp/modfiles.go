@@ -0,0 +1,143 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Language IDs gopls understands for module and workspace files.
+const (
+	LanguageIDGo     = "go"
+	LanguageIDGoMod  = "go.mod"
+	LanguageIDGoSum  = "go.sum"
+	LanguageIDGoWork = "go.work"
+)
+
+// languageIDAliases maps editor language IDs (Monaco registers "gomod") to
+// the IDs gopls uses to classify documents.
+var languageIDAliases = map[string]string{
+	"gomod":  LanguageIDGoMod,
+	"gosum":  LanguageIDGoSum,
+	"gowork": LanguageIDGoWork,
+}
+
+// ModDocument is a module or workspace file the editor can open through the
+// language server.
+type ModDocument struct {
+	Path       string `json:"path"`
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+}
+
+// ModDocuments lists go.mod, go.sum and go.work files in the active project.
+func (m *Manager) ModDocuments() []ModDocument {
+	m.mu.RLock()
+	projectPath := m.projectPath
+	m.mu.RUnlock()
+	if projectPath == "" {
+		return nil
+	}
+	return modDocuments(projectPath)
+}
+
+func modDocuments(projectPath string) []ModDocument {
+	documents := make([]ModDocument, 0, 3)
+	for _, candidate := range []struct {
+		name       string
+		languageID string
+	}{
+		{name: "go.mod", languageID: LanguageIDGoMod},
+		{name: "go.sum", languageID: LanguageIDGoSum},
+		{name: "go.work", languageID: LanguageIDGoWork},
+	} {
+		path := filepath.Join(projectPath, candidate.name)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		documents = append(documents, ModDocument{
+			Path:       path,
+			URI:        "file://" + path,
+			LanguageID: candidate.languageID,
+		})
+	}
+	return documents
+}
+
+// rewriteClientMessage adapts editor messages before they reach gopls:
+// initialize gains the project folder so its go.mod is in scope (and with it
+// mod diagnostics, hover and the tidy code action), and didOpen language ID
+// aliases are normalized. Other messages pass through untouched.
+func rewriteClientMessage(msg []byte, projectDir string) []byte {
+	if !bytes.Contains(msg, []byte(`"initialize"`)) && !bytes.Contains(msg, []byte(`"textDocument/didOpen"`)) {
+		return msg
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return msg
+	}
+	var method string
+	if err := json.Unmarshal(envelope["method"], &method); err != nil {
+		return msg
+	}
+
+	var params map[string]any
+	if err := json.Unmarshal(envelope["params"], &params); err != nil || params == nil {
+		return msg
+	}
+	changed := false
+	switch method {
+	case "initialize":
+		changed = addWorkspaceFolder(params, projectDir)
+	case "textDocument/didOpen":
+		document, _ := params["textDocument"].(map[string]any)
+		if languageID, ok := document["languageId"].(string); ok {
+			if normalized, alias := languageIDAliases[languageID]; alias {
+				document["languageId"] = normalized
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return msg
+	}
+
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return msg
+	}
+	envelope["params"] = rawParams
+	rewritten, err := json.Marshal(envelope)
+	if err != nil {
+		return msg
+	}
+	return rewritten
+}
+
+func addWorkspaceFolder(params map[string]any, projectDir string) bool {
+	if projectDir == "" {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "go.mod")); err != nil {
+		return false
+	}
+	uri := "file://" + projectDir
+	folders, _ := params["workspaceFolders"].([]any)
+	if len(folders) == 0 {
+		// gopls ignores rootUri once workspaceFolders is set, so keep it.
+		if rootURI, ok := params["rootUri"].(string); ok && rootURI != "" {
+			folders = append(folders, map[string]any{"uri": rootURI, "name": filepath.Base(rootURI)})
+		}
+	}
+	for _, folder := range folders {
+		if entry, ok := folder.(map[string]any); ok && entry["uri"] == uri {
+			return false
+		}
+	}
+	params["workspaceFolders"] = append(folders, map[string]any{
+		"uri":  uri,
+		"name": filepath.Base(projectDir),
+	})
+	return true
+}
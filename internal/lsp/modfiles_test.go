@@ -0,0 +1,82 @@
+package lsp
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRewriteClientMessageNormalizesModLanguageID(t *testing.T) {
+	t.Parallel()
+
+	msg := []byte(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///p/go.mod","languageId":"gomod","version":1,"text":"module x"}}}`)
+	var decoded struct {
+		Params struct {
+			TextDocument struct {
+				LanguageID string `json:"languageId"`
+				Text       string `json:"text"`
+			} `json:"textDocument"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(rewriteClientMessage(msg, ""), &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got, want := decoded.Params.TextDocument.LanguageID, LanguageIDGoMod; got != want {
+		t.Fatalf("languageId = %q, want %q", got, want)
+	}
+	if got, want := decoded.Params.TextDocument.Text, "module x"; got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+
+	goDoc := []byte(`{"method":"textDocument/didOpen","params":{"textDocument":{"languageId":"go"}}}`)
+	if got := rewriteClientMessage(goDoc, ""); string(got) != string(goDoc) {
+		t.Fatalf("go didOpen rewritten to %s", got)
+	}
+}
+
+func TestRewriteClientMessageAddsProjectFolder(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"rootUri":"file:///ws"}}`)
+	var decoded struct {
+		ID     int `json:"id"`
+		Params struct {
+			WorkspaceFolders []struct {
+				URI string `json:"uri"`
+			} `json:"workspaceFolders"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(rewriteClientMessage(msg, projectDir), &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded.ID != 1 {
+		t.Fatalf("id = %d, want 1", decoded.ID)
+	}
+	folders := decoded.Params.WorkspaceFolders
+	if len(folders) != 2 || folders[0].URI != "file:///ws" || folders[1].URI != "file://"+projectDir {
+		t.Fatalf("workspaceFolders = %+v, want root then project", folders)
+	}
+}
+
+func TestModDocumentsListsExistingFiles(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	for _, name := range []string{"go.mod", "go.work"} {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(""), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	documents := modDocuments(projectDir)
+	if len(documents) != 2 {
+		t.Fatalf("modDocuments() = %+v, want go.mod and go.work", documents)
+	}
+	if documents[0].LanguageID != LanguageIDGoMod || documents[1].LanguageID != LanguageIDGoWork {
+		t.Fatalf("language IDs = %q, %q", documents[0].LanguageID, documents[1].LanguageID)
+	}
+}
@@ -1,8 +1,17 @@
 package lsp
 
+// Language server modes reported in StatusResult.
+const (
+	// ModeGopls serves the editor from gopls.
+	ModeGopls = "gopls"
+	// ModeLite serves syntax-only feedback because gopls is not installed.
+	ModeLite = "lite"
+)
+
 // StatusResult is the LSP status for the frontend.
 type StatusResult struct {
 	Ready bool   `json:"ready"`
+	Mode  string `json:"mode,omitempty"`
 	Error string `json:"error"`
 }
 
@@ -15,9 +15,12 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"gopoke/internal/lite"
 )
 
 // Proxy bridges a WebSocket connection to a gopls stdio process.
@@ -31,6 +34,22 @@ type Proxy struct {
 	// goplsPath is resolved once when the proxy is created.
 	goplsPath    string
 	workspaceDir string
+	env          []string // extra gopls environment
+	// projectDir is offered to gopls as a workspace folder so module files
+	// of the open project are served alongside the snippet workspace.
+	projectDir string
+	// diagnostics records publishDiagnostics notifications for the unified feed.
+	diagnostics *diagnosticStore
+	// analysis tracks whether diagnostics are current for the analysis status.
+	analysis *analysisTracker
+	// memory holds the watchdog policy; degradeMemory is set once the
+	// watchdog restarts gopls, so later sessions start in degraded mode.
+	memory        *memoryWatch
+	degradeMemory atomic.Bool
+	// requests reports editor request latency.
+	requests *requestWatch
+	// tokens supplies gopoke hover and completion results.
+	tokens *tokenWatch
 }
 
 // wsUpgrader allows all origins because the WebSocket is only exposed on
@@ -88,46 +107,57 @@ func (p *Proxy) handleWS(w http.ResponseWriter, r *http.Request) {
 	}
 	defer conn.Close()
 
-	_, cancel := context.WithCancel(r.Context())
+	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
-	cmd := exec.Command(p.goplsPath, "serve")
-	cmd.Dir = p.workspaceDir
-
-	stdin, err := cmd.StdinPipe()
+	server, err := p.startServer(ctx)
 	if err != nil {
-		p.logger.Warn("create stdin pipe", "error", err)
-		return
-	}
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		p.logger.Warn("create stdout pipe", "error", err)
-		return
-	}
-	if err := cmd.Start(); err != nil {
-		p.logger.Warn("start gopls", "error", err)
+		p.logger.Warn("start language server", "error", err)
 		return
 	}
+	stdin, stdout := server.stdin, server.stdout
+
+	documents := newDocumentSync()
+	timer := newRequestTimer(p.requests)
+	tokens := newTokenProvider(p.tokens, p.projectDir, documents)
 
 	var wg sync.WaitGroup
 	wg.Add(2)
 
-	// WS → gopls stdin: read WebSocket messages, wrap with Content-Length, write to stdin
+	forward := func(msg []byte) error {
+		msg = rewriteClientMessage(msg, p.projectDir)
+		if p.degradeMemory.Load() {
+			msg = withMemoryMode(msg, memoryModeDegradeClosed)
+		}
+		msg = documents.rewriteClient(msg)
+		header := fmt.Sprintf("Content-Length: %d\r\n\r\n", len(msg))
+		if _, err := io.WriteString(stdin, header); err != nil {
+			return err
+		}
+		_, err := stdin.Write(msg)
+		return err
+	}
+	var onChange func(uri string)
+	if p.analysis != nil {
+		onChange = p.analysis.edited
+	}
+	changes := newChangeDebouncer(didChangeDebounce, forward, onChange)
+
+	// WS → gopls stdin: read WebSocket messages, coalesce didChange bursts,
+	// wrap with Content-Length, write to stdin
 	go func() {
 		defer wg.Done()
 		defer stdin.Close()
+		defer changes.close()
 		for {
 			_, msg, err := conn.ReadMessage()
 			if err != nil {
 				cancel()
 				return
 			}
-			header := fmt.Sprintf("Content-Length: %d\r\n\r\n", len(msg))
-			if _, err := io.WriteString(stdin, header); err != nil {
-				cancel()
-				return
-			}
-			if _, err := stdin.Write(msg); err != nil {
+			timer.client(msg)
+			tokens.client(msg)
+			if err := changes.submit(msg); err != nil {
 				cancel()
 				return
 			}
@@ -144,6 +174,15 @@ func (p *Proxy) handleWS(w http.ResponseWriter, r *http.Request) {
 
 		for scanner.Scan() {
 			data := scanner.Bytes()
+			if p.diagnostics != nil {
+				p.diagnostics.observe(data)
+			}
+			if p.analysis != nil {
+				p.analysis.observe(data)
+			}
+			timer.server(data)
+			data = tokens.server(data)
+			data = documents.rewriteServer(data)
 			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
 				return
 			}
@@ -153,8 +192,79 @@ func (p *Proxy) handleWS(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	if p.memory != nil && server.pid > 0 {
+		go p.watchMemory(ctx, server.pid, memoryPollInterval, func() { conn.Close() })
+	}
+
 	wg.Wait()
-	gracefulStopProcess(cmd, p.logger)
+	server.stop()
+}
+
+// languageServer is the server side of one editor session.
+type languageServer struct {
+	stdin  io.WriteCloser
+	stdout io.Reader
+	// pid is the gopls process, or 0 for the in-process syntax-only server.
+	pid  int
+	stop func()
+}
+
+// startServer starts gopls for a session, or the syntax-only server when
+// the proxy has no gopls.
+func (p *Proxy) startServer(ctx context.Context) (languageServer, error) {
+	if p.goplsPath == "" {
+		return startLiteServer(ctx), nil
+	}
+
+	cmd := exec.Command(p.goplsPath, "serve")
+	cmd.Dir = p.workspaceDir
+	if len(p.env) > 0 {
+		cmd.Env = append(os.Environ(), p.env...)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return languageServer{}, fmt.Errorf("create stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return languageServer{}, fmt.Errorf("create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return languageServer{}, fmt.Errorf("start gopls: %w", err)
+	}
+	return languageServer{
+		stdin:  stdin,
+		stdout: stdout,
+		pid:    cmd.Process.Pid,
+		stop:   func() { gracefulStopProcess(cmd, p.logger) },
+	}, nil
+}
+
+// startLiteServer runs the syntax-only server in process over pipes. The
+// pipes close when the session ends so neither side blocks on the other.
+func startLiteServer(ctx context.Context) languageServer {
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serverWriter.CloseWithError(lite.Serve(clientReader, serverWriter))
+	}()
+	closePipes := func() {
+		clientReader.CloseWithError(io.ErrClosedPipe)
+		serverReader.CloseWithError(io.ErrClosedPipe)
+	}
+	stopOnCancel := context.AfterFunc(ctx, closePipes)
+	return languageServer{
+		stdin:  clientWriter,
+		stdout: serverReader,
+		stop: func() {
+			stopOnCancel()
+			closePipes()
+			<-done
+		},
+	}
 }
 
 const goplsGracePeriod = 2 * time.Second
@@ -235,6 +345,12 @@ func splitContentLength(data []byte, atEOF bool) (advance int, token []byte, err
 	return totalLen, body, nil
 }
 
+// GoplsAvailable reports whether gopls is in PATH. Without it sessions run
+// the syntax-only language server.
+func GoplsAvailable() bool {
+	return findGoplsBinary() != ""
+}
+
 // findGoplsBinary locates gopls in PATH.
 func findGoplsBinary() string {
 	path, err := exec.LookPath("gopls")
@@ -0,0 +1,130 @@
+package lsp
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// maxPendingRequests bounds request timing state when gopls leaves
+// requests unanswered.
+const maxPendingRequests = 1024
+
+// RequestEvent reports an editor request gopls answered.
+type RequestEvent struct {
+	Method    string
+	StartedAt time.Time
+	Duration  time.Duration
+	// Error is the JSON-RPC error message, empty on success.
+	Error string
+}
+
+// RequestHandler receives answered editor requests.
+type RequestHandler func(event RequestEvent)
+
+// requestWatch holds the request and activity handlers shared with the
+// proxy.
+type requestWatch struct {
+	mu       sync.Mutex
+	handler  RequestHandler
+	activity func()
+}
+
+func (w *requestWatch) snapshot() RequestHandler {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.handler
+}
+
+func (w *requestWatch) activitySnapshot() func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.activity
+}
+
+// SetRequestHandler reports editor requests and their latency to handler.
+// A nil handler disables reporting.
+func (m *Manager) SetRequestHandler(handler RequestHandler) {
+	m.requests.mu.Lock()
+	defer m.requests.mu.Unlock()
+	m.requests.handler = handler
+}
+
+// SetActivityHandler calls handler for every message the editor sends, so
+// typing counts as interaction. A nil handler disables it.
+func (m *Manager) SetActivityHandler(handler func()) {
+	m.requests.mu.Lock()
+	defer m.requests.mu.Unlock()
+	m.requests.activity = handler
+}
+
+// requestTimer matches one session's client requests with gopls responses.
+type requestTimer struct {
+	watch   *requestWatch
+	mu      sync.Mutex
+	pending map[string]pendingRequest
+}
+
+type pendingRequest struct {
+	method    string
+	startedAt time.Time
+}
+
+type rpcEnvelope struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func newRequestTimer(watch *requestWatch) *requestTimer {
+	return &requestTimer{watch: watch, pending: make(map[string]pendingRequest)}
+}
+
+// client notes a request sent by the editor.
+func (t *requestTimer) client(msg []byte) {
+	if t.watch == nil {
+		return
+	}
+	if activity := t.watch.activitySnapshot(); activity != nil {
+		activity()
+	}
+	if t.watch.snapshot() == nil {
+		return
+	}
+	var envelope rpcEnvelope
+	if err := json.Unmarshal(msg, &envelope); err != nil || len(envelope.ID) == 0 || envelope.Method == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) >= maxPendingRequests {
+		return
+	}
+	t.pending[string(envelope.ID)] = pendingRequest{method: envelope.Method, startedAt: time.Now()}
+}
+
+// server reports the request a gopls response answers.
+func (t *requestTimer) server(msg []byte) {
+	if t.watch == nil {
+		return
+	}
+	var envelope rpcEnvelope
+	if err := json.Unmarshal(msg, &envelope); err != nil || len(envelope.ID) == 0 || envelope.Method != "" {
+		return
+	}
+	t.mu.Lock()
+	request, ok := t.pending[string(envelope.ID)]
+	delete(t.pending, string(envelope.ID))
+	t.mu.Unlock()
+	handler := t.watch.snapshot()
+	if !ok || handler == nil {
+		return
+	}
+	event := RequestEvent{Method: request.method, StartedAt: request.startedAt, Duration: time.Since(request.startedAt)}
+	if envelope.Error != nil {
+		event.Error = envelope.Error.Message
+	}
+	handler(event)
+}
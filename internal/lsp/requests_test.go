@@ -0,0 +1,32 @@
+package lsp
+
+import "testing"
+
+func TestRequestTimerMatchesResponses(t *testing.T) {
+	t.Parallel()
+
+	var events []RequestEvent
+	watch := &requestWatch{handler: func(event RequestEvent) { events = append(events, event) }}
+	timer := newRequestTimer(watch)
+
+	timer.client([]byte(`{"jsonrpc":"2.0","id":1,"method":"textDocument/hover","params":{}}`))
+	timer.client([]byte(`{"jsonrpc":"2.0","id":"a","method":"textDocument/completion","params":{}}`))
+	timer.client([]byte(`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{}}`))
+	// A server request and the editor's answer are not editor requests.
+	timer.server([]byte(`{"jsonrpc":"2.0","id":7,"method":"workspace/configuration","params":{}}`))
+	timer.client([]byte(`{"jsonrpc":"2.0","id":7,"result":[]}`))
+
+	timer.server([]byte(`{"jsonrpc":"2.0","id":"a","error":{"code":-32800,"message":"canceled"}}`))
+	timer.server([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+	timer.server([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want two", events)
+	}
+	if events[0].Method != "textDocument/completion" || events[0].Error != "canceled" {
+		t.Fatalf("events[0] = %+v, want the canceled completion", events[0])
+	}
+	if events[1].Method != "textDocument/hover" || events[1].Error != "" || events[1].Duration < 0 {
+		t.Fatalf("events[1] = %+v, want the hover", events[1])
+	}
+}
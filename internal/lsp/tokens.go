@@ -0,0 +1,406 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// LSP completion item kinds.
+const (
+	completionKindVariable = 6
+	completionKindKeyword  = 14
+)
+
+var (
+	envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	envPartialPattern   = regexp.MustCompile(`^[A-Za-z0-9_]*$`)
+)
+
+// Directive is a gopoke directive the editor explains and completes.
+type Directive struct {
+	// Name is the directive itself, such as "//gopoke:env".
+	Name string
+	// Usage shows its arguments, such as "//gopoke:env KEY=VALUE". It
+	// equals Name for directives without arguments.
+	Usage string
+	// Doc is a markdown description.
+	Doc string
+}
+
+// EnvVar is an environment variable a run of the open snippet sets.
+type EnvVar struct {
+	Name   string
+	Value  string
+	Masked bool
+	// Source says where the value comes from, such as "project
+	// environment".
+	Source string
+}
+
+// TokenSource supplies the gopoke tokens the editor explains on hover and
+// offers as completions next to gopls: directives and ${NAME} references to
+// run environment variables.
+type TokenSource struct {
+	Directives []Directive
+	// EnvVars returns the variables a run of source in projectPath sets,
+	// one entry per name with the value that wins. Nil offers none.
+	EnvVars func(projectPath string, source string) []EnvVar
+}
+
+// tokenWatch holds the token source shared with the proxy.
+type tokenWatch struct {
+	mu     sync.Mutex
+	source TokenSource
+}
+
+func (w *tokenWatch) snapshot() TokenSource {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.source
+}
+
+// SetTokenSource sets the gopoke tokens merged into gopls hover and
+// completion results. The zero TokenSource disables them.
+func (m *Manager) SetTokenSource(source TokenSource) {
+	m.tokens.mu.Lock()
+	defer m.tokens.mu.Unlock()
+	m.tokens.source = source
+}
+
+// tokenProvider merges gopoke hover and completion results into one
+// session's gopls responses.
+type tokenProvider struct {
+	watch      *tokenWatch
+	projectDir string
+	documents  *documentSync
+	mu         sync.Mutex
+	pending    map[string]tokenRequest
+}
+
+type tokenRequest struct {
+	method string
+	uri    string
+	pos    position
+}
+
+func newTokenProvider(watch *tokenWatch, projectDir string, documents *documentSync) *tokenProvider {
+	return &tokenProvider{watch: watch, projectDir: projectDir, documents: documents, pending: make(map[string]tokenRequest)}
+}
+
+// client notes hover and completion requests sent by the editor.
+func (p *tokenProvider) client(msg []byte) {
+	if p.watch == nil {
+		return
+	}
+	if !bytes.Contains(msg, []byte(`"textDocument/hover"`)) && !bytes.Contains(msg, []byte(`"textDocument/completion"`)) {
+		return
+	}
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			TextDocument struct {
+				URI string `json:"uri"`
+			} `json:"textDocument"`
+			Position position `json:"position"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(msg, &request); err != nil || len(request.ID) == 0 {
+		return
+	}
+	if request.Method != "textDocument/hover" && request.Method != "textDocument/completion" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.pending) >= maxPendingRequests {
+		return
+	}
+	p.pending[string(request.ID)] = tokenRequest{
+		method: request.Method,
+		uri:    request.Params.TextDocument.URI,
+		pos:    request.Params.Position,
+	}
+}
+
+// server adds gopoke results to the gopls response msg answers, if any.
+// Error responses pass through unchanged.
+func (p *tokenProvider) server(msg []byte) []byte {
+	p.mu.Lock()
+	waiting := len(p.pending) > 0
+	p.mu.Unlock()
+	if !waiting {
+		return msg
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return msg
+	}
+	if _, ok := envelope["method"]; ok {
+		return msg
+	}
+	p.mu.Lock()
+	request, ok := p.pending[string(envelope["id"])]
+	delete(p.pending, string(envelope["id"]))
+	p.mu.Unlock()
+	if _, failed := envelope["error"]; !ok || failed {
+		return msg
+	}
+	text, ok := p.documents.text(request.uri)
+	if !ok {
+		return msg
+	}
+
+	source := p.watch.snapshot()
+	var result json.RawMessage
+	var err error
+	switch request.method {
+	case "textDocument/hover":
+		value, span, found := tokenHover(source, p.projectDir, text, request.pos)
+		if !found {
+			return msg
+		}
+		result, err = mergeHover(envelope["result"], value, span)
+	case "textDocument/completion":
+		items := tokenCompletions(source, p.projectDir, text, request.pos)
+		if len(items) == 0 {
+			return msg
+		}
+		result, err = mergeCompletions(envelope["result"], items)
+	}
+	if err != nil || result == nil {
+		return msg
+	}
+	envelope["result"] = result
+	merged, err := json.Marshal(envelope)
+	if err != nil {
+		return msg
+	}
+	return merged
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type textEdit struct {
+	Range   textRange `json:"range"`
+	NewText string    `json:"newText"`
+}
+
+type completionItem struct {
+	Label         string         `json:"label"`
+	Kind          int            `json:"kind"`
+	Detail        string         `json:"detail,omitempty"`
+	Documentation *markupContent `json:"documentation,omitempty"`
+	FilterText    string         `json:"filterText,omitempty"`
+	TextEdit      textEdit       `json:"textEdit"`
+}
+
+// sourceLine locates the line of text holding pos: its start offset, its
+// content without the line ending, and pos as a byte column.
+func sourceLine(text string, pos position) (int, string, int, bool) {
+	offset, err := offsetAt(text, pos)
+	if err != nil {
+		return 0, "", 0, false
+	}
+	start := strings.LastIndexByte(text[:offset], '\n') + 1
+	end := len(text)
+	if newline := strings.IndexByte(text[offset:], '\n'); newline >= 0 {
+		end = offset + newline
+	}
+	line := strings.TrimSuffix(text[start:end], "\r")
+	return start, line, offset - start, true
+}
+
+// tokenHover returns markdown describing the directive or ${NAME} reference
+// at pos and the span it covers.
+func tokenHover(source TokenSource, projectDir string, text string, pos position) (string, textRange, bool) {
+	start, line, column, ok := sourceLine(text, pos)
+	if !ok {
+		return "", textRange{}, false
+	}
+	span := func(from, to int) textRange {
+		return textRange{Start: positionAt(text, start+from), End: positionAt(text, start+to)}
+	}
+
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := len(line) - len(trimmed)
+	for _, directive := range source.Directives {
+		rest, ok := strings.CutPrefix(trimmed, directive.Name)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		if column < indent || column > indent+len(directive.Name) {
+			break
+		}
+		return directiveMarkdown(directive), span(indent, indent+len(directive.Name)), true
+	}
+
+	for _, match := range envReferencePattern.FindAllStringSubmatchIndex(line, -1) {
+		if column < match[0] || column >= match[1] {
+			continue
+		}
+		name := line[match[2]:match[3]]
+		return envMarkdown(name, lookupEnvVar(source, projectDir, text, name)), span(match[0], match[1]), true
+	}
+	return "", textRange{}, false
+}
+
+// tokenCompletions returns directives when pos follows the start of one on
+// a line of its own, and run variables when it follows "${".
+func tokenCompletions(source TokenSource, projectDir string, text string, pos position) []completionItem {
+	start, line, column, ok := sourceLine(text, pos)
+	if !ok {
+		return nil
+	}
+	prefix := line[:column]
+	span := func(from int) textRange {
+		return textRange{Start: positionAt(text, start+from), End: positionAt(text, start+column)}
+	}
+
+	if open := strings.LastIndex(prefix, "${"); open >= 0 && envPartialPattern.MatchString(prefix[open+2:]) && source.EnvVars != nil {
+		closing := "}"
+		if strings.HasPrefix(line[column:], "}") {
+			closing = ""
+		}
+		var items []completionItem
+		for _, variable := range source.EnvVars(projectDir, text) {
+			items = append(items, completionItem{
+				Label:         variable.Name,
+				Kind:          completionKindVariable,
+				Detail:        variable.Source,
+				Documentation: &markupContent{Kind: "markdown", Value: envMarkdown(variable.Name, &variable)},
+				TextEdit:      textEdit{Range: span(open + 2), NewText: variable.Name + closing},
+			})
+		}
+		return items
+	}
+
+	trimmed := strings.TrimLeft(prefix, " \t")
+	if len(trimmed) < 3 || strings.ContainsAny(trimmed, " \t") {
+		return nil
+	}
+	indent := len(prefix) - len(trimmed)
+	var items []completionItem
+	for _, directive := range source.Directives {
+		if !strings.HasPrefix(directive.Name, trimmed) {
+			continue
+		}
+		newText := directive.Name
+		if directive.Usage != directive.Name {
+			newText += " "
+		}
+		items = append(items, completionItem{
+			Label:         directive.Name,
+			Kind:          completionKindKeyword,
+			Detail:        directive.Usage,
+			Documentation: &markupContent{Kind: "markdown", Value: directive.Doc},
+			FilterText:    directive.Name,
+			TextEdit:      textEdit{Range: span(indent), NewText: newText},
+		})
+	}
+	return items
+}
+
+// lookupEnvVar returns the variable a run sets for name, or nil.
+func lookupEnvVar(source TokenSource, projectDir string, text string, name string) *EnvVar {
+	if source.EnvVars == nil {
+		return nil
+	}
+	for _, variable := range source.EnvVars(projectDir, text) {
+		if variable.Name == name {
+			return &variable
+		}
+	}
+	return nil
+}
+
+func directiveMarkdown(directive Directive) string {
+	return fmt.Sprintf("```\n%s\n```\n\n%s", directive.Usage, directive.Doc)
+}
+
+// envMarkdown describes name and the value a run gives it. Masked values
+// are not shown.
+func envMarkdown(name string, variable *EnvVar) string {
+	if variable == nil {
+		return fmt.Sprintf("`%s` is not set by this snippet or the project. A run inherits it from the environment gopoke was started in, if it is set there.", name)
+	}
+	value := variable.Value
+	if variable.Masked {
+		value = "********"
+	}
+	return fmt.Sprintf("```\n%s=%s\n```\n\nFrom the %s.", name, value, variable.Source)
+}
+
+// mergeHover puts value ahead of the gopls hover result. Plain-text gopls
+// hovers are left alone rather than mixed with markdown.
+func mergeHover(result json.RawMessage, value string, span textRange) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(result)) == 0 || bytes.Equal(bytes.TrimSpace(result), []byte("null")) {
+		return json.Marshal(struct {
+			Contents markupContent `json:"contents"`
+			Range    textRange     `json:"range"`
+		}{markupContent{Kind: "markdown", Value: value}, span})
+	}
+	var hover map[string]json.RawMessage
+	if err := json.Unmarshal(result, &hover); err != nil {
+		return nil, err
+	}
+	var contents markupContent
+	if err := json.Unmarshal(hover["contents"], &contents); err != nil || contents.Kind != "markdown" {
+		return nil, nil
+	}
+	contents.Value = value + "\n\n---\n\n" + contents.Value
+	encoded, err := json.Marshal(contents)
+	if err != nil {
+		return nil, err
+	}
+	hover["contents"] = encoded
+	return json.Marshal(hover)
+}
+
+// mergeCompletions appends items to a gopls completion result, which may be
+// null, an item array or a CompletionList.
+func mergeCompletions(result json.RawMessage, items []completionItem) (json.RawMessage, error) {
+	encoded := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data)
+	}
+
+	trimmed := bytes.TrimSpace(result)
+	switch {
+	case len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")):
+		return json.Marshal(map[string]any{"isIncomplete": false, "items": encoded})
+	case trimmed[0] == '[':
+		var existing []json.RawMessage
+		if err := json.Unmarshal(trimmed, &existing); err != nil {
+			return nil, err
+		}
+		return json.Marshal(append(existing, encoded...))
+	}
+	var list map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &list); err != nil {
+		return nil, err
+	}
+	var existing []json.RawMessage
+	if raw, ok := list["items"]; ok && !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return nil, err
+		}
+	}
+	merged, err := json.Marshal(append(existing, encoded...))
+	if err != nil {
+		return nil, err
+	}
+	list["items"] = merged
+	return json.Marshal(list)
+}
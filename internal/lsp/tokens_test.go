@@ -0,0 +1,142 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newTestTokenProvider returns a provider for one open document holding
+// text.
+func newTestTokenProvider(t *testing.T, text string) *tokenProvider {
+	t.Helper()
+	documents := newDocumentSync()
+	open, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "textDocument/didOpen",
+		"params":  map[string]any{"textDocument": map[string]any{"uri": "file:///p/main.go", "text": text}},
+	})
+	if err != nil {
+		t.Fatalf("marshal didOpen: %v", err)
+	}
+	documents.rewriteClient(open)
+	watch := &tokenWatch{source: TokenSource{
+		Directives: []Directive{
+			{Name: "//gopoke:env", Usage: "//gopoke:env KEY=VALUE", Doc: "Sets a variable."},
+			{Name: "//gopoke:cacheable", Usage: "//gopoke:cacheable", Doc: "Caches the run."},
+		},
+		EnvVars: func(projectPath string, source string) []EnvVar {
+			if projectPath != "/p" {
+				t.Errorf("EnvVars(%q), want the session project", projectPath)
+			}
+			return []EnvVar{
+				{Name: "DSN", Value: "postgres://dev", Source: "project environment"},
+				{Name: "TOKEN", Value: "secret", Masked: true, Source: "project environment"},
+			}
+		},
+	}}
+	return newTokenProvider(watch, "/p", documents)
+}
+
+// exchange sends a request at line:character and returns the result of
+// the gopls response carrying result once the provider merged into it.
+func exchange(t *testing.T, provider *tokenProvider, method string, line, character int, result string) any {
+	t.Helper()
+	provider.client(fmt.Appendf(nil, `{"jsonrpc":"2.0","id":1,"method":%q,"params":{"textDocument":{"uri":"file:///p/main.go"},"position":{"line":%d,"character":%d}}}`,
+		method, line, character))
+	merged := provider.server(fmt.Appendf(nil, `{"jsonrpc":"2.0","id":1,"result":%s}`, result))
+	var response struct {
+		Result any `json:"result"`
+	}
+	if err := json.Unmarshal(merged, &response); err != nil {
+		t.Fatalf("unmarshal %s: %v", merged, err)
+	}
+	return response.Result
+}
+
+func TestTokenProviderHover(t *testing.T) {
+	t.Parallel()
+
+	text := "//gopoke:env MODE=dev\npackage main\n\nvar dsn = os.ExpandEnv(\"${DSN}\")\nvar token = \"${TOKEN}${HOME}\"\n"
+	provider := newTestTokenProvider(t, text)
+
+	hover := exchange(t, provider, "textDocument/hover", 0, 4, "null").(map[string]any)
+	contents := hover["contents"].(map[string]any)
+	if value := contents["value"].(string); !strings.Contains(value, "//gopoke:env KEY=VALUE") || !strings.Contains(value, "Sets a variable.") {
+		t.Fatalf("directive hover = %q", value)
+	}
+	if hover["range"] == nil {
+		t.Fatal("directive hover has no range")
+	}
+
+	// gopls' own markdown follows ours.
+	hover = exchange(t, provider, "textDocument/hover", 3, 26, `{"contents":{"kind":"markdown","value":"func os.ExpandEnv"}}`).(map[string]any)
+	value := hover["contents"].(map[string]any)["value"].(string)
+	if !strings.HasPrefix(value, "```\nDSN=postgres://dev\n```") || !strings.HasSuffix(value, "---\n\nfunc os.ExpandEnv") {
+		t.Fatalf("env hover = %q", value)
+	}
+
+	value = exchange(t, provider, "textDocument/hover", 4, 15, "null").(map[string]any)["contents"].(map[string]any)["value"].(string)
+	if strings.Contains(value, "secret") || !strings.Contains(value, "TOKEN=********") {
+		t.Fatalf("masked env hover = %q", value)
+	}
+	value = exchange(t, provider, "textDocument/hover", 4, 22, "null").(map[string]any)["contents"].(map[string]any)["value"].(string)
+	if !strings.Contains(value, "`HOME` is not set") {
+		t.Fatalf("unset env hover = %q", value)
+	}
+
+	if result := exchange(t, provider, "textDocument/hover", 1, 2, "null"); result != nil {
+		t.Fatalf("hover on plain code = %v, want gopls' null", result)
+	}
+	// Plain-text gopls hovers are not mixed with markdown.
+	result := exchange(t, provider, "textDocument/hover", 3, 26, `{"contents":{"kind":"plaintext","value":"plain"}}`).(map[string]any)
+	if result["contents"].(map[string]any)["value"] != "plain" {
+		t.Fatalf("plaintext hover = %v, want it unchanged", result)
+	}
+}
+
+func TestTokenProviderCompletion(t *testing.T) {
+	t.Parallel()
+
+	text := "//gopo\npackage main\n\nvar dsn = os.ExpandEnv(\"${D\")\n"
+	provider := newTestTokenProvider(t, text)
+
+	list := exchange(t, provider, "textDocument/completion", 0, 6, `{"isIncomplete":true,"items":[{"label":"gopls"}]}`).(map[string]any)
+	items := list["items"].([]any)
+	if len(items) != 3 || list["isIncomplete"] != true {
+		t.Fatalf("directive completion = %v, want gopls' item and both directives", list)
+	}
+	env := items[1].(map[string]any)
+	if env["label"] != "//gopoke:env" || env["textEdit"].(map[string]any)["newText"] != "//gopoke:env " {
+		t.Fatalf("directive item = %v", env)
+	}
+	if cacheable := items[2].(map[string]any); cacheable["textEdit"].(map[string]any)["newText"] != "//gopoke:cacheable" {
+		t.Fatalf("argument-less directive item = %v", cacheable)
+	}
+
+	list = exchange(t, provider, "textDocument/completion", 3, 27, "null").(map[string]any)
+	items = list["items"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("env completion = %v, want both variables", list)
+	}
+	dsn := items[0].(map[string]any)
+	edit := dsn["textEdit"].(map[string]any)
+	if dsn["label"] != "DSN" || edit["newText"] != "DSN}" {
+		t.Fatalf("env item = %v", dsn)
+	}
+	start := edit["range"].(map[string]any)["start"].(map[string]any)
+	if start["character"] != float64(26) {
+		t.Fatalf("env item replaces from %v, want the start of the partial name", start)
+	}
+
+	if result := exchange(t, provider, "textDocument/completion", 1, 3, `[{"label":"gopls"}]`).([]any); len(result) != 1 {
+		t.Fatalf("completion in plain code = %v, want gopls' items only", result)
+	}
+	// Errors pass through.
+	provider.client([]byte(`{"jsonrpc":"2.0","id":2,"method":"textDocument/completion","params":{"textDocument":{"uri":"file:///p/main.go"},"position":{"line":0,"character":6}}}`))
+	response := `{"jsonrpc":"2.0","id":2,"error":{"code":-32800,"message":"canceled"}}`
+	if got := provider.server([]byte(response)); string(got) != response {
+		t.Fatalf("error response = %s, want it unchanged", got)
+	}
+}
@@ -3,14 +3,21 @@ package lsp
 import (
 	"bufio"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"gopoke/internal/project"
+	"gopoke/internal/snippethelper"
 )
 
 type workspace struct {
 	dir         string
 	projectPath string
+	// env holds extra gopls environment, set for GOPATH-mode projects.
+	env []string
 }
 
 func createWorkspace(projectPath string) (*workspace, error) {
@@ -19,12 +26,32 @@ func createWorkspace(projectPath string) (*workspace, error) {
 		return nil, fmt.Errorf("create workspace dir: %w", err)
 	}
 
+	// A project without a go.mod is a GOPATH project. The workspace sits
+	// inside it, so gopls in GOPATH mode resolves the project's imports;
+	// a workspace go.mod would turn module mode back on.
+	if _, err := os.Stat(filepath.Join(projectPath, "go.mod")); os.IsNotExist(err) {
+		if err := os.Remove(filepath.Join(wsDir, "go.mod")); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("remove workspace go.mod: %w", err)
+		}
+		os.Remove(filepath.Join(wsDir, "go.sum"))
+		environment := project.DetectGOPATH(projectPath).Environment()
+		var env []string
+		for _, key := range slices.Sorted(maps.Keys(environment)) {
+			env = append(env, key+"="+environment[key])
+		}
+		return &workspace{dir: wsDir, projectPath: projectPath, env: env}, nil
+	}
+
 	goVersion := readGoVersionFromProject(projectPath)
 	if goVersion == "" {
 		goVersion = "1.22"
 	}
 
 	goModContent := fmt.Sprintf("module gopoke-snippet\n\ngo %s\n", goVersion)
+	if snippethelper.Installed(projectPath) {
+		// The workspace sits inside the project, next to the helper module.
+		goModContent += "\n" + snippethelper.GoModDirectives("../"+snippethelper.DirName)
+	}
 
 	if err := os.WriteFile(filepath.Join(wsDir, "go.mod"), []byte(goModContent), 0o644); err != nil {
 		return nil, fmt.Errorf("write workspace go.mod: %w", err)
@@ -0,0 +1,83 @@
+// Package netmon reports the network connections of running processes by
+// polling the platform's socket tables. Polling can miss connections that
+// open and close between samples, so results show what a process talks to
+// rather than an exhaustive audit.
+package netmon
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrUnsupported is returned where the platform cannot list sockets.
+var ErrUnsupported = errors.New("network monitoring is not supported on this platform")
+
+// Protocols reported in Connection.Protocol.
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// DefaultInterval is how often Watch samples connections.
+const DefaultInterval = 500 * time.Millisecond
+
+// Connection is one socket with a remote endpoint.
+type Connection struct {
+	PID           int    `json:"pid"`
+	Protocol      string `json:"protocol"`
+	LocalAddress  string `json:"localAddress"`
+	RemoteAddress string `json:"remoteAddress"`
+	// State is the TCP state, such as ESTABLISHED; empty for UDP.
+	State string `json:"state,omitempty"`
+}
+
+// Connections lists the sockets of pids that have a remote endpoint.
+// Listening and unconnected sockets are omitted.
+func Connections(pids []int) ([]Connection, error) {
+	if len(pids) == 0 {
+		return []Connection{}, nil
+	}
+	return connections(pids)
+}
+
+// Watch samples the connections of the processes returned by pids every
+// interval until ctx ends, calling onConnection once for each new protocol
+// and remote address. It returns ErrUnsupported immediately on platforms
+// without socket listing.
+func Watch(ctx context.Context, pids func() []int, interval time.Duration, onConnection func(Connection)) error {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	seen := make(map[string]bool)
+	sample := func() error {
+		found, err := Connections(pids())
+		if err != nil {
+			return err
+		}
+		for _, connection := range found {
+			key := connection.Protocol + " " + connection.RemoteAddress
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			onConnection(connection)
+		}
+		return nil
+	}
+	if err := sample(); errors.Is(err, ErrUnsupported) {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			// Processes exiting mid-sample make transient errors expected.
+			_ = sample()
+		}
+	}
+}
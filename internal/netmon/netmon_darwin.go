@@ -0,0 +1,70 @@
+//go:build darwin
+
+package netmon
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Supported reports whether Connections can list sockets here.
+const Supported = true
+
+// connections parses lsof's field output for the internet sockets of pids.
+func connections(pids []int) ([]Connection, error) {
+	list := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		list = append(list, strconv.Itoa(pid))
+	}
+	output, err := exec.Command("lsof", "-a", "-n", "-P", "-i", "-p", strings.Join(list, ","), "-F", "pPnT").Output()
+	if err != nil {
+		// lsof exits 1 when no process has a matching socket.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return []Connection{}, nil
+		}
+		return nil, fmt.Errorf("list sockets: %w", err)
+	}
+
+	found := make([]Connection, 0)
+	var pid int
+	var current *Connection
+	flush := func() {
+		if current != nil && current.RemoteAddress != "" {
+			found = append(found, *current)
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line == "" {
+			continue
+		}
+		value := line[1:]
+		switch line[0] {
+		case 'p':
+			flush()
+			pid, _ = strconv.Atoi(value)
+		case 'f':
+			flush()
+		case 'P':
+			current = &Connection{PID: pid, Protocol: strings.ToLower(value)}
+		case 'n':
+			if current != nil {
+				local, remote, _ := strings.Cut(value, "->")
+				current.LocalAddress = local
+				current.RemoteAddress = remote
+			}
+		case 'T':
+			if current != nil && current.Protocol == ProtocolTCP {
+				if state, ok := strings.CutPrefix(value, "ST="); ok {
+					current.State = state
+				}
+			}
+		}
+	}
+	flush()
+	return found, nil
+}
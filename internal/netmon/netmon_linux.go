@@ -0,0 +1,157 @@
+//go:build linux
+
+package netmon
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Supported reports whether Connections can list sockets here.
+const Supported = true
+
+// tcpStates names the hex states used in /proc/net/tcp.
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+}
+
+// connections matches socket inodes from /proc/<pid>/fd against the
+// kernel socket tables in /proc/<pid>/net.
+func connections(pids []int) ([]Connection, error) {
+	owners := make(map[string]int)
+	tablePID := 0
+	for _, pid := range pids {
+		inodes, err := socketInodes(pid)
+		if err != nil {
+			continue
+		}
+		for _, inode := range inodes {
+			owners[inode] = pid
+		}
+		if tablePID == 0 {
+			tablePID = pid
+		}
+	}
+	found := make([]Connection, 0)
+	if len(owners) == 0 {
+		return found, nil
+	}
+
+	for _, table := range []struct {
+		file     string
+		protocol string
+	}{
+		{"tcp", ProtocolTCP},
+		{"tcp6", ProtocolTCP},
+		{"udp", ProtocolUDP},
+		{"udp6", ProtocolUDP},
+	} {
+		entries, err := readSocketTable(fmt.Sprintf("/proc/%d/net/%s", tablePID, table.file), table.protocol)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			pid, ok := owners[entry.inode]
+			if !ok {
+				continue
+			}
+			entry.connection.PID = pid
+			found = append(found, entry.connection)
+		}
+	}
+	return found, nil
+}
+
+func socketInodes(pid int) ([]string, error) {
+	dir := "/proc/" + strconv.Itoa(pid) + "/fd"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	inodes := make([]string, 0)
+	for _, entry := range entries {
+		target, err := os.Readlink(dir + "/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+			inodes = append(inodes, strings.TrimSuffix(inode, "]"))
+		}
+	}
+	return inodes, nil
+}
+
+type socketEntry struct {
+	inode      string
+	connection Connection
+}
+
+// readSocketTable parses a /proc/net socket table, keeping sockets with a
+// remote port.
+func readSocketTable(path string, protocol string) ([]socketEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make([]socketEntry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		local, err := decodeAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		remote, err := decodeAddress(fields[2])
+		if err != nil || strings.HasSuffix(remote, ":0") {
+			continue
+		}
+		connection := Connection{Protocol: protocol, LocalAddress: local, RemoteAddress: remote}
+		if protocol == ProtocolTCP {
+			connection.State = tcpStates[fields[3]]
+		}
+		entries = append(entries, socketEntry{inode: fields[9], connection: connection})
+	}
+	return entries, scanner.Err()
+}
+
+// decodeAddress converts a kernel "IP:PORT" hex pair. IPv4 addresses are
+// one little-endian word and IPv6 addresses four.
+func decodeAddress(value string) (string, error) {
+	hexIP, hexPort, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", fmt.Errorf("invalid socket address %q", value)
+	}
+	raw, err := hex.DecodeString(hexIP)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", fmt.Errorf("invalid socket address %q", value)
+	}
+	for word := 0; word < len(raw); word += 4 {
+		raw[word], raw[word+1], raw[word+2], raw[word+3] = raw[word+3], raw[word+2], raw[word+1], raw[word]
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid socket port %q", value)
+	}
+	return net.JoinHostPort(net.IP(raw).String(), strconv.FormatUint(port, 10)), nil
+}
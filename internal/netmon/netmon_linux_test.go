@@ -0,0 +1,18 @@
+//go:build linux
+
+package netmon
+
+import "testing"
+
+func TestDecodeAddress(t *testing.T) {
+	tests := map[string]string{
+		"0100007F:1F90":                         "127.0.0.1:8080",
+		"00000000000000000000000001000000:0050": "[::1]:80",
+	}
+	for input, want := range tests {
+		got, err := decodeAddress(input)
+		if err != nil || got != want {
+			t.Fatalf("decodeAddress(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+}
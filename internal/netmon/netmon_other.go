@@ -0,0 +1,12 @@
+//go:build !linux && !darwin
+
+package netmon
+
+// Supported reports whether Connections can list sockets here.
+const Supported = false
+
+// connections is unsupported on this platform.
+func connections(pids []int) ([]Connection, error) {
+	_ = pids
+	return nil, ErrUnsupported
+}
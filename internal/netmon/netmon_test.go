@@ -0,0 +1,87 @@
+package netmon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConnectionsReportsRemoteEndpoint(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	found, err := Connections([]int{os.Getpid()})
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("Connections() error = %v", err)
+	}
+	for _, connection := range found {
+		if connection.RemoteAddress == listener.Addr().String() {
+			if connection.Protocol != ProtocolTCP || connection.PID != os.Getpid() {
+				t.Fatalf("connection = %+v, want tcp from this process", connection)
+			}
+			return
+		}
+	}
+	t.Fatalf("Connections() = %+v, want remote %s", found, listener.Addr())
+}
+
+func TestWatchReportsEachRemoteOnce(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reported := make(chan Connection, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, func() []int { return []int{os.Getpid()} }, 10*time.Millisecond, func(connection Connection) {
+			if connection.RemoteAddress == listener.Addr().String() {
+				reported <- connection
+			}
+		})
+	}()
+
+	for range 2 {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		defer conn.Close()
+	}
+
+	select {
+	case <-reported:
+	case err := <-done:
+		if errors.Is(err, ErrUnsupported) {
+			t.Skip(err)
+		}
+		t.Fatalf("Watch() returned early: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not reported")
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if extra := len(reported); extra != 0 {
+		t.Fatalf("remote reported %d extra times, want once", extra)
+	}
+}
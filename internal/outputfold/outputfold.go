@@ -0,0 +1,135 @@
+// Package outputfold finds regions of run output the UI can fold: runs of a
+// repeated line and groups of stack frames from the standard library or
+// dependencies in goroutine traces. Folds are byte ranges of the output, so
+// the text itself is shown unchanged.
+package outputfold
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Fold kinds.
+const (
+	// KindRepeat folds copies of the line before it.
+	KindRepeat = "repeat"
+	// KindFrames folds consecutive standard library or dependency frames.
+	KindFrames = "frames"
+)
+
+const (
+	// minRepeats is how many copies of a line must follow it to fold.
+	minRepeats = 2
+	// minFrames is how many noisy frames in a row fold.
+	minFrames = 2
+)
+
+// frameLocation is the file line of a goroutine trace frame.
+var frameLocation = regexp.MustCompile(`^\t((?:[A-Za-z]:)?[^:\n]+\.go):[0-9]+(?: \+0x[0-9a-fA-F]+)?$`)
+
+// Fold is a foldable range of output. Start and End are byte offsets; the
+// range covers whole lines without the final line ending. Lines is how many
+// lines it hides. Count is the number of repeated copies or folded frames.
+type Fold struct {
+	Kind  string `json:"kind"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Lines int    `json:"lines"`
+	Count int    `json:"count"`
+}
+
+type line struct {
+	text  string // without the line ending
+	start int
+	end   int
+}
+
+// Folds returns the foldable ranges of text in order. Frame groups are found
+// first; repeated lines inside them are not folded again.
+func Folds(text string) []Fold {
+	lines := splitLines(text)
+	folds := make([]Fold, 0)
+	for i := 0; i < len(lines); {
+		if frames := noisyFrames(lines[i:]); frames > 0 {
+			if frames >= minFrames {
+				last := lines[i+2*frames-1]
+				folds = append(folds, Fold{Kind: KindFrames, Start: lines[i].start, End: last.end, Lines: 2 * frames, Count: frames})
+			}
+			i += 2 * frames
+			continue
+		}
+		copies := 0
+		for i+copies+1 < len(lines) && lines[i+copies+1].text == lines[i].text {
+			copies++
+		}
+		if copies >= minRepeats {
+			folds = append(folds, Fold{Kind: KindRepeat, Start: lines[i+1].start, End: lines[i+copies].end, Lines: copies, Count: copies})
+		}
+		i += copies + 1
+	}
+	return folds
+}
+
+// noisyFrames counts the standard library and dependency frames at the start
+// of lines. Each frame is a function line and a tab-indented file line.
+func noisyFrames(lines []line) int {
+	count := 0
+	for len(lines) >= 2 {
+		match := frameLocation.FindStringSubmatch(lines[1].text)
+		if match == nil || strings.HasPrefix(lines[0].text, "\t") || !noisy(lines[0].text, match[1]) {
+			break
+		}
+		count++
+		lines = lines[2:]
+	}
+	return count
+}
+
+// noisy reports whether a frame belongs to the standard library or a
+// dependency: the file lives in the module cache or a vendor directory, or
+// in GOROOT, where its path ends with the package path of the function.
+func noisy(function string, file string) bool {
+	file = strings.ReplaceAll(file, `\`, "/")
+	if strings.Contains(file, "/pkg/mod/") || strings.Contains(file, "/vendor/") {
+		return true
+	}
+	function = strings.TrimPrefix(function, "created by ")
+	function, _, _ = strings.Cut(function, " in goroutine ")
+	pkg := packagePath(function)
+	if pkg == "" || pkg == "main" {
+		return false
+	}
+	first, _, _ := strings.Cut(pkg, "/")
+	if strings.Contains(first, ".") {
+		return false
+	}
+	return strings.HasSuffix(file[:max(strings.LastIndex(file, "/"), 0)], "/src/"+pkg)
+}
+
+// packagePath extracts the import path from a frame function such as
+// "net/http.(*conn).serve(0xc000)" or "runtime.gopark(...)".
+func packagePath(function string) string {
+	function, _, _ = strings.Cut(function, "(")
+	dir := ""
+	if slash := strings.LastIndex(function, "/"); slash >= 0 {
+		dir, function = function[:slash+1], function[slash+1:]
+	}
+	name, _, ok := strings.Cut(function, ".")
+	if !ok {
+		return ""
+	}
+	return dir + name
+}
+
+func splitLines(text string) []line {
+	lines := make([]line, 0, strings.Count(text, "\n")+1)
+	offset := 0
+	for text != "" {
+		content, rest, _ := strings.Cut(text, "\n")
+		trimmed := strings.TrimSuffix(content, "\r")
+		lines = append(lines, line{text: trimmed, start: offset, end: offset + len(trimmed)})
+		offset += len(content) + 1
+		text = rest
+	}
+	return lines
+}
@@ -0,0 +1,68 @@
+package outputfold
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFoldsRepeatedLines(t *testing.T) {
+	text := "start\ntick\ntick\ntick\ntick\nok\nok\nend"
+	got := Folds(text)
+	want := []Fold{{Kind: KindRepeat, Start: 11, End: 25, Lines: 3, Count: 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Folds() = %+v, want %+v", got, want)
+	}
+	if folded := text[got[0].Start:got[0].End]; folded != "tick\ntick\ntick" {
+		t.Fatalf("fold covers %q", folded)
+	}
+
+	crlf := Folds("a\r\na\r\na\r\n")
+	if len(crlf) != 1 || crlf[0].Start != 3 || crlf[0].End != 7 {
+		t.Fatalf("Folds(CRLF) = %+v, want the two copies without the last line ending", crlf)
+	}
+}
+
+func TestFoldsFrameworkStackFrames(t *testing.T) {
+	trace := strings.Join([]string{
+		"panic: boom",
+		"",
+		"goroutine 7 [running]:",
+		"main.handler({0x1, 0x2}, 0xc000)",
+		"\t/home/me/app/main.go:12 +0x25",
+		"net/http.HandlerFunc.ServeHTTP(0xc000?, {0x1, 0x2}, 0x3?)",
+		"\t/usr/local/go/src/net/http/server.go:2220 +0x29",
+		"net/http.serverHandler.ServeHTTP({0xc000?}, {0x1, 0x2}, 0xc000)",
+		"\t/usr/local/go/src/net/http/server.go:3210 +0x8e",
+		"github.com/acme/router.(*Mux).Serve(0xc000)",
+		"\t/home/me/go/pkg/mod/github.com/acme/router@v1.2.0/mux.go:88 +0x1f",
+		"created by net/http.(*Server).Serve in goroutine 1",
+		"\t/usr/local/go/src/net/http/server.go:3360 +0x485",
+		"exit status 2",
+	}, "\n")
+	got := Folds(trace)
+	if len(got) != 1 || got[0].Kind != KindFrames || got[0].Count != 4 || got[0].Lines != 8 {
+		t.Fatalf("Folds() = %+v, want one fold of four frames", got)
+	}
+	folded := trace[got[0].Start:got[0].End]
+	if !strings.HasPrefix(folded, "net/http.HandlerFunc.ServeHTTP") || !strings.HasSuffix(folded, "server.go:3360 +0x485") {
+		t.Fatalf("fold covers %q", folded)
+	}
+}
+
+func TestFoldsLeaveSingleFramesAndUserCode(t *testing.T) {
+	trace := strings.Join([]string{
+		"goroutine 1 [running]:",
+		"main.main()",
+		"\t/home/me/app/main.go:5 +0x1d",
+		"runtime.goexit()",
+		"\t/usr/local/go/src/runtime/asm_amd64.s:1700 +0x1",
+		"example.com/app/internal/store.Load()",
+		"\t/home/me/app/internal/store/store.go:40 +0x10",
+		"example.com/app/internal/store.Open()",
+		"\t/home/me/app/internal/store/open.go:9 +0x10",
+	}, "\n")
+	if got := Folds(trace); len(got) != 0 {
+		t.Fatalf("Folds() = %+v, want none", got)
+	}
+}
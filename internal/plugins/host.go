@@ -0,0 +1,433 @@
+package plugins
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Plugin states reported in Info.State.
+const (
+	StateRunning = "running"
+	StateFailed  = "failed"
+	StateStopped = "stopped"
+	// StatePendingApproval is a plugin that is not started because the user
+	// has not approved the permissions its manifest requests.
+	StatePendingApproval = "pendingApproval"
+)
+
+// GrantsFile is the file under the plugin root that records the
+// permissions the user approved for each plugin.
+const GrantsFile = "grants.json"
+
+// ErrNotApproved is returned when starting a plugin whose requested
+// permissions the user has not approved.
+var ErrNotApproved = errors.New("plugin permissions not approved")
+
+// Call timeouts. Transforms sit on every run's path, so they get the
+// least time.
+const (
+	initializeTimeout = 5 * time.Second
+	transformTimeout  = 2 * time.Second
+	commandTimeout    = 30 * time.Second
+	stopGrace         = 2 * time.Second
+)
+
+// Info describes one discovered plugin.
+type Info struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Dir         string    `json:"dir"`
+	Permissions []string  `json:"permissions"`
+	State       string    `json:"state"`
+	Error       string    `json:"error,omitempty"`
+	Commands    []Command `json:"commands"`
+}
+
+// Command is a plugin command the user can run.
+type Command struct {
+	Plugin string `json:"plugin"`
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+}
+
+// plugin is a discovered plugin and, while it runs, its process.
+type plugin struct {
+	manifest Manifest
+	process  *process
+	commands []Command
+	err      error
+}
+
+// Host discovers, starts and talks to the plugins under a root directory.
+// It is safe for concurrent use.
+type Host struct {
+	root        string
+	hostVersion string
+	logger      *slog.Logger
+
+	mu      sync.Mutex
+	plugins map[string]*plugin
+	invalid map[string]error    // directory name to manifest error
+	grants  map[string][]string // plugin name to approved permissions
+}
+
+// NewHost returns a host for the plugins under root. hostVersion is sent to
+// plugins when they initialize.
+func NewHost(root string, hostVersion string, logger *slog.Logger) *Host {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Host{
+		root:        root,
+		hostVersion: hostVersion,
+		logger:      logger,
+		plugins:     make(map[string]*plugin),
+		invalid:     make(map[string]error),
+		grants:      make(map[string][]string),
+	}
+}
+
+// Root is the directory plugins are discovered in.
+func (h *Host) Root() string {
+	return h.root
+}
+
+// Start discovers the plugins under the root and starts each whose
+// permissions the user approved. A plugin that fails to start is reported by
+// Plugins and does not stop the others.
+func (h *Host) Start(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("start plugins context: %w", err)
+	}
+	manifests, invalid, err := Discover(h.root)
+	if err != nil {
+		return err
+	}
+	grants, err := loadGrants(filepath.Join(h.root, GrantsFile))
+	if err != nil {
+		// Unreadable grants approve nothing; the user can approve again.
+		h.logger.Warn("load plugin grants failed", "error", err)
+		grants = make(map[string][]string)
+	}
+	h.Stop()
+
+	h.mu.Lock()
+	h.invalid = invalid
+	h.grants = grants
+	for _, manifest := range manifests {
+		h.plugins[manifest.Name] = &plugin{manifest: manifest}
+	}
+	h.mu.Unlock()
+	for _, manifest := range manifests {
+		if err := h.Restart(ctx, manifest.Name); err != nil && !errors.Is(err, ErrNotApproved) {
+			h.logger.Warn("start plugin failed", "plugin", manifest.Name, "error", err)
+		}
+	}
+	return nil
+}
+
+// Approve grants the named plugin the permissions its manifest requests,
+// remembers the decision and starts the plugin.
+func (h *Host) Approve(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("approve plugin context: %w", err)
+	}
+	h.mu.Lock()
+	entry, ok := h.plugins[name]
+	if !ok {
+		h.mu.Unlock()
+		return fmt.Errorf("plugin %q not found", name)
+	}
+	grants := maps.Clone(h.grants)
+	grants[name] = slices.Clone(entry.manifest.Permissions)
+	if err := saveGrants(filepath.Join(h.root, GrantsFile), grants); err != nil {
+		h.mu.Unlock()
+		return err
+	}
+	h.grants = grants
+	h.mu.Unlock()
+	return h.Restart(ctx, name)
+}
+
+// approvedLocked reports whether the user approved every permission the
+// plugin's manifest requests. h.mu must be held.
+func (h *Host) approvedLocked(manifest Manifest) bool {
+	granted := h.grants[manifest.Name]
+	for _, permission := range manifest.Permissions {
+		if !slices.Contains(granted, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// Restart stops the named plugin if it runs and starts it again.
+func (h *Host) Restart(ctx context.Context, name string) error {
+	h.mu.Lock()
+	entry, ok := h.plugins[name]
+	var previous *process
+	approved := false
+	if ok {
+		previous = entry.process
+		entry.process = nil
+		approved = h.approvedLocked(entry.manifest)
+		if !approved {
+			entry.commands = nil
+			entry.err = nil
+		}
+	}
+	h.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("plugin %q not found", name)
+	}
+	if previous != nil {
+		previous.stop(stopGrace)
+	}
+	if !approved {
+		return fmt.Errorf("plugin %q: %w", name, ErrNotApproved)
+	}
+
+	started, commands, err := h.launch(ctx, entry.manifest)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.plugins[name] != entry {
+		// The host stopped while the plugin started.
+		if started != nil {
+			go started.stop(stopGrace)
+		}
+		return fmt.Errorf("plugin %q was unloaded", name)
+	}
+	entry.process = started
+	entry.commands = commands
+	entry.err = err
+	return err
+}
+
+// launch starts a plugin and runs its initialize handshake.
+func (h *Host) launch(ctx context.Context, manifest Manifest) (*process, []Command, error) {
+	started, err := startProcess(manifest, h.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	var initialized InitializeResult
+	params := InitializeParams{HostVersion: h.hostVersion, Permissions: manifest.Permissions}
+	if err := started.call(ctx, MethodInitialize, params, &initialized, initializeTimeout); err != nil {
+		started.stop(stopGrace)
+		return nil, nil, fmt.Errorf("initialize plugin: %w", err)
+	}
+	if len(initialized.Commands) > 0 && !manifest.Allows(PermissionCommands) {
+		err := errors.New("plugin registered commands without the commands permission")
+		if notifyErr := started.notify(MethodRegistrationFailed, RegistrationError{Error: err.Error()}); notifyErr != nil {
+			h.logger.Warn("notify plugin of failed registration failed", "plugin", manifest.Name, "error", notifyErr)
+		}
+		started.stop(stopGrace)
+		return nil, nil, err
+	}
+	commands := make([]Command, 0, len(initialized.Commands))
+	for _, spec := range initialized.Commands {
+		if spec.ID == "" {
+			continue
+		}
+		title := spec.Title
+		if title == "" {
+			title = spec.ID
+		}
+		commands = append(commands, Command{Plugin: manifest.Name, ID: spec.ID, Title: title})
+	}
+	return started, commands, nil
+}
+
+// Stop shuts every running plugin down.
+func (h *Host) Stop() {
+	h.mu.Lock()
+	running := make([]*process, 0, len(h.plugins))
+	for _, entry := range h.plugins {
+		if entry.process != nil {
+			running = append(running, entry.process)
+			entry.process = nil
+		}
+	}
+	h.plugins = make(map[string]*plugin)
+	h.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, process := range running {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			process.stop(stopGrace)
+		}()
+	}
+	wg.Wait()
+}
+
+// Plugins describes the discovered plugins, sorted by name, followed by
+// directories whose manifests are invalid.
+func (h *Host) Plugins() []Info {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	infos := make([]Info, 0, len(h.plugins)+len(h.invalid))
+	for _, entry := range h.plugins {
+		info := Info{
+			Name:        entry.manifest.Name,
+			Version:     entry.manifest.Version,
+			Description: entry.manifest.Description,
+			Dir:         entry.manifest.Dir,
+			Permissions: append([]string{}, entry.manifest.Permissions...),
+			State:       StateStopped,
+			Commands:    append([]Command{}, entry.commands...),
+		}
+		err := entry.err
+		if entry.process != nil {
+			info.State = StateRunning
+			if exitErr := entry.process.err(); exitErr != nil {
+				err = exitErr
+			}
+		}
+		if err != nil {
+			info.State = StateFailed
+			info.Error = err.Error()
+			info.Commands = []Command{}
+		}
+		if entry.process == nil && !h.approvedLocked(entry.manifest) {
+			info.State = StatePendingApproval
+		}
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b Info) int { return cmp.Compare(a.Name, b.Name) })
+	invalid := make([]Info, 0, len(h.invalid))
+	for dir, err := range h.invalid {
+		invalid = append(invalid, Info{Name: dir, State: StateFailed, Error: err.Error(), Permissions: []string{}, Commands: []Command{}})
+	}
+	slices.SortFunc(invalid, func(a, b Info) int { return cmp.Compare(a.Name, b.Name) })
+	return append(infos, invalid...)
+}
+
+// Commands lists the commands of running plugins.
+func (h *Host) Commands() []Command {
+	commands := make([]Command, 0)
+	for _, info := range h.Plugins() {
+		commands = append(commands, info.Commands...)
+	}
+	return commands
+}
+
+// RunCommand runs a registered command and returns its output.
+func (h *Host) RunCommand(ctx context.Context, pluginName string, commandID string, args json.RawMessage) (string, error) {
+	h.mu.Lock()
+	entry, ok := h.plugins[pluginName]
+	var running *process
+	registered := false
+	if ok {
+		running = entry.process
+		registered = slices.ContainsFunc(entry.commands, func(command Command) bool { return command.ID == commandID })
+	}
+	h.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("plugin %q not found", pluginName)
+	}
+	if !registered {
+		return "", fmt.Errorf("plugin %q has no command %q", pluginName, commandID)
+	}
+	if running == nil {
+		return "", fmt.Errorf("plugin %q is not running", pluginName)
+	}
+	var result CommandResult
+	if err := running.call(ctx, MethodExecuteCommand, CommandParams{Command: commandID, Args: args}, &result, commandTimeout); err != nil {
+		return "", err
+	}
+	return result.Output, nil
+}
+
+// TransformOutput passes run stdout through every running plugin with the
+// transformOutput permission, in name order. A plugin that fails or times
+// out is skipped.
+func (h *Host) TransformOutput(ctx context.Context, runID string, stdout string) string {
+	for _, running := range h.runningWith(PermissionTransformOutput) {
+		var result TransformResult
+		if err := running.process.call(ctx, MethodTransformOutput, TransformParams{RunID: runID, Stdout: stdout}, &result, transformTimeout); err != nil {
+			h.logger.Warn("plugin output transform failed", "plugin", running.name, "error", err)
+			continue
+		}
+		stdout = result.Stdout
+	}
+	return stdout
+}
+
+// NotifyRunResult sends result to every running plugin with the runResults
+// permission without waiting for them to read it.
+func (h *Host) NotifyRunResult(result RunResult) {
+	for _, running := range h.runningWith(PermissionRunResults) {
+		go func() {
+			if err := running.process.notify(MethodRunResult, result); err != nil {
+				h.logger.Warn("notify plugin of run result failed", "plugin", running.name, "error", err)
+			}
+		}()
+	}
+}
+
+type namedProcess struct {
+	name    string
+	process *process
+}
+
+// runningWith returns the running plugins granted permission, by name.
+func (h *Host) runningWith(permission string) []namedProcess {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	running := make([]namedProcess, 0)
+	for name, entry := range h.plugins {
+		if entry.process != nil && entry.process.err() == nil && entry.manifest.Allows(permission) {
+			running = append(running, namedProcess{name: name, process: entry.process})
+		}
+	}
+	slices.SortFunc(running, func(a, b namedProcess) int { return cmp.Compare(a.name, b.name) })
+	return running
+}
+
+// loadGrants reads the approved permissions per plugin. A missing file
+// approves nothing.
+func loadGrants(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string][]string), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read plugin grants: %w", err)
+	}
+	grants := make(map[string][]string)
+	if err := json.Unmarshal(data, &grants); err != nil {
+		return nil, fmt.Errorf("parse plugin grants: %w", err)
+	}
+	return grants, nil
+}
+
+// saveGrants replaces the grants file through a temporary file.
+func saveGrants(path string, grants map[string][]string) error {
+	data, err := json.MarshalIndent(grants, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode plugin grants: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create plugins directory: %w", err)
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, data, 0o600); err != nil {
+		return fmt.Errorf("write plugin grants: %w", err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("replace plugin grants: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPluginHelperProcess is the plugin the host tests start: it runs only
+// when the test binary is invoked with the plugin-helper argument.
+func TestPluginHelperProcess(t *testing.T) {
+	if !slices.Contains(os.Args, "plugin-helper") {
+		return
+	}
+	lastRun := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var request message
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			fmt.Fprintln(os.Stderr, "bad request:", err)
+			continue
+		}
+		var result any
+		switch request.Method {
+		case MethodInitialize:
+			if slices.Contains(os.Args, "no-commands") {
+				result = InitializeResult{}
+				break
+			}
+			result = InitializeResult{Commands: []CommandSpec{{ID: "shout", Title: "Shout"}, {ID: "lastRun"}}}
+		case MethodRegistrationFailed:
+			var params RegistrationError
+			_ = json.Unmarshal(request.Params, &params)
+			_ = os.WriteFile("rejected", []byte(params.Error), 0o644)
+		case MethodTransformOutput:
+			var params TransformParams
+			_ = json.Unmarshal(request.Params, &params)
+			result = TransformResult{Stdout: strings.ToUpper(params.Stdout)}
+		case MethodExecuteCommand:
+			var params CommandParams
+			_ = json.Unmarshal(request.Params, &params)
+			output := "ran " + params.Command
+			if params.Command == "lastRun" {
+				output = lastRun
+			}
+			result = CommandResult{Output: output}
+		case MethodRunResult:
+			var params RunResult
+			_ = json.Unmarshal(request.Params, &params)
+			lastRun = params.RunID
+		}
+		if request.ID == 0 {
+			continue
+		}
+		encoded, _ := json.Marshal(result)
+		_ = encoder.Encode(message{ID: request.ID, Result: encoded})
+	}
+	os.Exit(0)
+}
+
+// writeHelperPlugin installs the helper plugin under root with permissions.
+func writeHelperPlugin(t *testing.T, root string, name string, permissions ...string) {
+	t.Helper()
+	writeHelperPluginArgs(t, root, name, nil, permissions...)
+}
+
+// writeHelperPluginArgs installs the helper plugin with extra helper
+// arguments, such as "no-commands".
+func writeHelperPluginArgs(t *testing.T, root string, name string, args []string, permissions ...string) {
+	t.Helper()
+	executable, err := filepath.Abs(os.Args[0])
+	if err != nil {
+		t.Fatalf("resolve test binary: %v", err)
+	}
+	writeManifest(t, root, name, Manifest{
+		Name:        name,
+		Version:     "1.0.0",
+		Command:     executable,
+		Args:        append([]string{"-test.run=^TestPluginHelperProcess$", "--", "plugin-helper"}, args...),
+		Permissions: permissions,
+	})
+}
+
+func writeManifest(t *testing.T, root string, dir string, manifest Manifest) {
+	t.Helper()
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("encode manifest: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+		t.Fatalf("create plugin dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, dir, ManifestFile), data, 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+}
+
+func TestDiscoverValidatesManifests(t *testing.T) {
+	t.Parallel()
+
+	manifests, invalid, err := Discover(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(manifests) != 0 || len(invalid) != 0 {
+		t.Fatalf("Discover(missing) = %v, %v, %v", manifests, invalid, err)
+	}
+
+	root := t.TempDir()
+	writeManifest(t, root, "good", Manifest{Name: "good", Command: "run.sh", Permissions: []string{PermissionRunResults}})
+	writeManifest(t, root, "greedy", Manifest{Name: "greedy", Command: "run.sh", Permissions: []string{"filesystem"}})
+	writeManifest(t, root, "unnamed", Manifest{Command: "run.sh"})
+	manifests, invalid, err = Discover(root)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Name != "good" || manifests[0].executable() != filepath.Join(root, "good", "run.sh") {
+		t.Fatalf("manifests = %+v", manifests)
+	}
+	if len(invalid) != 2 || invalid["greedy"] == nil || invalid["unnamed"] == nil {
+		t.Fatalf("invalid = %v", invalid)
+	}
+}
+
+func TestHostRunsPluginWithPermissions(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeHelperPlugin(t, root, "full", PermissionCommands, PermissionRunResults, PermissionTransformOutput)
+	host := NewHost(root, "test", nil)
+	ctx := context.Background()
+	if err := host.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer host.Stop()
+
+	infos := host.Plugins()
+	if len(infos) != 1 || infos[0].State != StatePendingApproval || len(infos[0].Commands) != 0 {
+		t.Fatalf("Plugins() before approval = %+v", infos)
+	}
+	if got := host.TransformOutput(ctx, "run-1", "hello\n"); got != "hello\n" {
+		t.Fatalf("TransformOutput() before approval = %q", got)
+	}
+	if err := host.Restart(ctx, "full"); !errors.Is(err, ErrNotApproved) {
+		t.Fatalf("Restart() before approval error = %v, want ErrNotApproved", err)
+	}
+	if err := host.Approve(ctx, "full"); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+
+	infos = host.Plugins()
+	if len(infos) != 1 || infos[0].State != StateRunning || len(infos[0].Commands) != 2 {
+		t.Fatalf("Plugins() = %+v", infos)
+	}
+	if got := host.TransformOutput(ctx, "run-1", "hello\n"); got != "HELLO\n" {
+		t.Fatalf("TransformOutput() = %q", got)
+	}
+	output, err := host.RunCommand(ctx, "full", "shout", nil)
+	if err != nil || output != "ran shout" {
+		t.Fatalf("RunCommand(shout) = %q, %v", output, err)
+	}
+	if _, err := host.RunCommand(ctx, "full", "unknown", nil); err == nil {
+		t.Fatal("RunCommand(unregistered) error = nil")
+	}
+
+	host.NotifyRunResult(RunResult{RunID: "run-7"})
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		output, err := host.RunCommand(ctx, "full", "lastRun", nil)
+		if err != nil {
+			t.Fatalf("RunCommand(lastRun) error = %v", err)
+		}
+		if output == "run-7" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("plugin last run = %q, want run-7", output)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := host.Restart(ctx, "full"); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+	if output, err := host.RunCommand(ctx, "full", "lastRun", nil); err != nil || output != "" {
+		t.Fatalf("RunCommand(lastRun) after restart = %q, %v", output, err)
+	}
+
+	reloaded := NewHost(root, "test", nil)
+	if err := reloaded.Start(ctx); err != nil {
+		t.Fatalf("Start(reloaded) error = %v", err)
+	}
+	defer reloaded.Stop()
+	if infos := reloaded.Plugins(); len(infos) != 1 || infos[0].State != StateRunning {
+		t.Fatalf("Plugins() after reload = %+v, want approval remembered", infos)
+	}
+}
+
+func TestHostEnforcesPermissions(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeHelperPlugin(t, root, "quiet")
+	writeHelperPluginArgs(t, root, "watcher", []string{"no-commands"}, PermissionRunResults)
+	writeManifest(t, root, "broken", Manifest{Name: "broken", Command: "does-not-exist"})
+	host := NewHost(root, "test", nil)
+	ctx := context.Background()
+	if err := host.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer host.Stop()
+	if err := host.Approve(ctx, "watcher"); err != nil {
+		t.Fatalf("Approve(watcher) error = %v", err)
+	}
+
+	infos := host.Plugins()
+	if len(infos) != 3 || infos[0].Name != "broken" || infos[0].State != StateFailed || infos[0].Error == "" {
+		t.Fatalf("Plugins() = %+v", infos)
+	}
+	if infos[1].Name != "quiet" || infos[1].State != StateFailed || !strings.Contains(infos[1].Error, "commands permission") {
+		t.Fatalf("quiet plugin = %+v, want registration failure", infos[1])
+	}
+	rejected, err := os.ReadFile(filepath.Join(root, "quiet", "rejected"))
+	if err != nil || !strings.Contains(string(rejected), "commands permission") {
+		t.Fatalf("quiet plugin saw rejection %q, %v", rejected, err)
+	}
+	if infos[2].Name != "watcher" || infos[2].State != StateRunning || len(infos[2].Commands) != 0 {
+		t.Fatalf("watcher plugin = %+v, want running with no commands", infos[2])
+	}
+	if got := host.TransformOutput(ctx, "run-1", "hello\n"); got != "hello\n" {
+		t.Fatalf("TransformOutput() without permission = %q", got)
+	}
+	if _, err := host.RunCommand(ctx, "quiet", "shout", nil); err == nil {
+		t.Fatal("RunCommand() without commands permission error = nil")
+	}
+}
@@ -0,0 +1,120 @@
+// Package plugins hosts user extensions: executables under the data root
+// that speak JSON lines over stdio. A plugin can register commands, receive
+// run results and transform run output, each only when its manifest asks
+// for the matching permission and the user approved it.
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// ManifestFile is the file in each plugin directory that describes it.
+const ManifestFile = "plugin.json"
+
+// Permissions a manifest can request.
+const (
+	// PermissionCommands lets a plugin register commands the user can run.
+	PermissionCommands = "commands"
+	// PermissionRunResults sends the plugin the outcome of every run,
+	// including its output.
+	PermissionRunResults = "runResults"
+	// PermissionTransformOutput lets a plugin rewrite run stdout before it
+	// is shown.
+	PermissionTransformOutput = "transformOutput"
+)
+
+var knownPermissions = []string{PermissionCommands, PermissionRunResults, PermissionTransformOutput}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// Manifest describes one plugin.
+type Manifest struct {
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Command is the executable, relative to the plugin directory or
+	// absolute, started with Args.
+	Command     string   `json:"command"`
+	Args        []string `json:"args,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// Dir is the plugin directory; it is not read from the file.
+	Dir string `json:"-"`
+}
+
+// Allows reports whether the manifest requests permission.
+func (m Manifest) Allows(permission string) bool {
+	return slices.Contains(m.Permissions, permission)
+}
+
+// LoadManifest reads and validates the manifest in dir.
+func LoadManifest(dir string) (Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("read plugin manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("parse plugin manifest: %w", err)
+	}
+	if !namePattern.MatchString(manifest.Name) {
+		return Manifest{}, fmt.Errorf("invalid plugin name %q", manifest.Name)
+	}
+	if manifest.Command == "" {
+		return Manifest{}, fmt.Errorf("plugin %q has no command", manifest.Name)
+	}
+	for _, permission := range manifest.Permissions {
+		if !slices.Contains(knownPermissions, permission) {
+			return Manifest{}, fmt.Errorf("plugin %q requests unknown permission %q", manifest.Name, permission)
+		}
+	}
+	manifest.Dir = dir
+	return manifest, nil
+}
+
+// Discover loads the manifests of the plugin directories directly under
+// root, sorted by name. A missing root means no plugins; directories with
+// invalid manifests are reported in the returned map and skipped.
+func Discover(root string) ([]Manifest, map[string]error, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Manifest{}, map[string]error{}, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read plugins directory: %w", err)
+	}
+	manifests := make([]Manifest, 0, len(entries))
+	invalid := make(map[string]error)
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifest, err := LoadManifest(filepath.Join(root, entry.Name()))
+		if err == nil && seen[manifest.Name] {
+			err = fmt.Errorf("plugin name %q is already used", manifest.Name)
+		}
+		if err != nil {
+			invalid[entry.Name()] = err
+			continue
+		}
+		seen[manifest.Name] = true
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Name < manifests[j].Name })
+	return manifests, invalid, nil
+}
+
+// executable resolves the manifest command.
+func (m Manifest) executable() string {
+	if filepath.IsAbs(m.Command) {
+		return m.Command
+	}
+	return filepath.Join(m.Dir, m.Command)
+}
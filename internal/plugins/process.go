@@ -0,0 +1,284 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Protocol methods. Requests carry an id and get a response with the same
+// id; notifications have none.
+const (
+	// MethodInitialize is the first request. Params are InitializeParams;
+	// the result is InitializeResult.
+	MethodInitialize = "initialize"
+	// MethodRunResult notifies a plugin of a finished run with RunResult.
+	MethodRunResult = "runResult"
+	// MethodTransformOutput asks for rewritten run stdout with
+	// TransformParams and gets TransformResult back.
+	MethodTransformOutput = "transformOutput"
+	// MethodExecuteCommand runs a registered command with CommandParams and
+	// gets CommandResult back.
+	MethodExecuteCommand = "executeCommand"
+	// MethodShutdown notifies a plugin that its stdin is about to close.
+	MethodShutdown = "shutdown"
+	// MethodRegistrationFailed notifies a plugin with RegistrationError that
+	// the host refused what its initialize result registered. The host stops
+	// the plugin right after.
+	MethodRegistrationFailed = "registrationFailed"
+)
+
+// message is one JSON line in either direction.
+type message struct {
+	ID     int64           `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// InitializeParams tell a plugin what the host grants it: the permissions
+// the user approved.
+type InitializeParams struct {
+	HostVersion string   `json:"hostVersion"`
+	Permissions []string `json:"permissions"`
+}
+
+// InitializeResult lists the commands a plugin registers.
+type InitializeResult struct {
+	Commands []CommandSpec `json:"commands,omitempty"`
+}
+
+// CommandSpec is a command as a plugin registers it.
+type CommandSpec struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// RegistrationError says why the host refused a plugin's registration.
+type RegistrationError struct {
+	Error string `json:"error"`
+}
+
+// RunResult is the outcome of a run sent to plugins.
+type RunResult struct {
+	RunID       string `json:"runId"`
+	ProjectPath string `json:"projectPath"`
+	ExitCode    int    `json:"exitCode"`
+	DurationMS  int64  `json:"durationMs"`
+	Stdout      string `json:"stdout"`
+	Stderr      string `json:"stderr"`
+	TimedOut    bool   `json:"timedOut,omitempty"`
+	Canceled    bool   `json:"canceled,omitempty"`
+}
+
+// TransformParams carry run stdout to transform.
+type TransformParams struct {
+	RunID  string `json:"runId"`
+	Stdout string `json:"stdout"`
+}
+
+// TransformResult is the rewritten stdout.
+type TransformResult struct {
+	Stdout string `json:"stdout"`
+}
+
+// CommandParams invoke a registered command.
+type CommandParams struct {
+	Command string          `json:"command"`
+	Args    json.RawMessage `json:"args,omitempty"`
+}
+
+// CommandResult is what a command returns for display.
+type CommandResult struct {
+	Output string `json:"output"`
+}
+
+// errProcessExited fails calls to a plugin that is no longer running.
+var errProcessExited = errors.New("plugin process exited")
+
+// process is one running plugin and its stdio connection.
+type process struct {
+	command *exec.Cmd
+	stdin   io.WriteCloser
+	logger  *slog.Logger
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan message
+	done    chan struct{}
+	exitErr error
+	// stderrDone closes once stderr is drained, which must happen before
+	// the command is waited for.
+	stderrDone chan struct{}
+}
+
+// startProcess starts the plugin executable of manifest.
+func startProcess(manifest Manifest, logger *slog.Logger) (*process, error) {
+	command := exec.Command(manifest.executable(), manifest.Args...)
+	command.Dir = manifest.Dir
+	stdin, err := command.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("open plugin stdin: %w", err)
+	}
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("open plugin stdout: %w", err)
+	}
+	stderr, err := command.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("open plugin stderr: %w", err)
+	}
+	if err := command.Start(); err != nil {
+		return nil, fmt.Errorf("start plugin: %w", err)
+	}
+	p := &process{
+		command:    command,
+		stdin:      stdin,
+		logger:     logger.With("plugin", manifest.Name),
+		pending:    make(map[int64]chan message),
+		done:       make(chan struct{}),
+		stderrDone: make(chan struct{}),
+	}
+	go p.logStderr(stderr)
+	go p.readLoop(stdout)
+	return p, nil
+}
+
+// readLoop delivers responses until stdout closes, then fails pending
+// calls and reaps the process.
+func (p *process) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var response message
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil || response.ID == 0 {
+			p.logger.Warn("ignored plugin message", "line", scanner.Text())
+			continue
+		}
+		p.mu.Lock()
+		reply, ok := p.pending[response.ID]
+		delete(p.pending, response.ID)
+		p.mu.Unlock()
+		if ok {
+			reply <- response
+		}
+	}
+	<-p.stderrDone
+	err := p.command.Wait()
+	p.mu.Lock()
+	p.exitErr = errProcessExited
+	if err != nil {
+		p.exitErr = fmt.Errorf("%w: %v", errProcessExited, err)
+	}
+	p.pending = nil
+	p.mu.Unlock()
+	close(p.done)
+}
+
+func (p *process) logStderr(stderr io.Reader) {
+	defer close(p.stderrDone)
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		p.logger.Info("plugin stderr", "line", scanner.Text())
+	}
+}
+
+// call sends a request and decodes its result into result, waiting at most
+// timeout.
+func (p *process) call(ctx context.Context, method string, params any, result any, timeout time.Duration) error {
+	p.mu.Lock()
+	if p.pending == nil {
+		err := p.exitErr
+		p.mu.Unlock()
+		return err
+	}
+	p.nextID++
+	id := p.nextID
+	reply := make(chan message, 1)
+	p.pending[id] = reply
+	p.mu.Unlock()
+	forget := func() {
+		p.mu.Lock()
+		if p.pending != nil {
+			delete(p.pending, id)
+		}
+		p.mu.Unlock()
+	}
+
+	if err := p.send(message{ID: id, Method: method}, params); err != nil {
+		forget()
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	select {
+	case response := <-reply:
+		if response.Error != "" {
+			return fmt.Errorf("plugin %s: %s", method, response.Error)
+		}
+		if result != nil {
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				return fmt.Errorf("decode plugin %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-p.done:
+		return p.err()
+	case <-ctx.Done():
+		forget()
+		return fmt.Errorf("plugin %s: %w", method, ctx.Err())
+	}
+}
+
+// notify sends a notification without waiting.
+func (p *process) notify(method string, params any) error {
+	return p.send(message{Method: method}, params)
+}
+
+func (p *process) send(envelope message, params any) error {
+	if params != nil {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("encode plugin %s params: %w", envelope.Method, err)
+		}
+		envelope.Params = encoded
+	}
+	line, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("encode plugin message: %w", err)
+	}
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write to plugin: %w", err)
+	}
+	return nil
+}
+
+// err is why the process stopped; nil while it runs.
+func (p *process) err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.exitErr
+}
+
+// stop asks the plugin to exit by closing stdin and kills it after grace.
+func (p *process) stop(grace time.Duration) {
+	_ = p.notify(MethodShutdown, nil)
+	_ = p.stdin.Close()
+	select {
+	case <-p.done:
+	case <-time.After(grace):
+		_ = p.command.Process.Kill()
+		<-p.done
+	}
+}
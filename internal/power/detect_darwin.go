@@ -0,0 +1,13 @@
+//go:build darwin
+
+package power
+
+import "os/exec"
+
+func detect() State {
+	output, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return State{Source: SourceUnknown, Percent: -1}
+	}
+	return parsePmset(string(output))
+}
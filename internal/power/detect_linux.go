@@ -0,0 +1,7 @@
+//go:build linux
+
+package power
+
+func detect() State {
+	return readSysfs("/sys/class/power_supply")
+}
@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package power
+
+func detect() State {
+	return State{Source: SourceUnknown, Percent: -1}
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package power
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var getSystemPowerStatus = syscall.NewLazyDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// systemPowerStatus mirrors SYSTEM_POWER_STATUS.
+type systemPowerStatus struct {
+	acLineStatus        byte
+	batteryFlag         byte
+	batteryLifePercent  byte
+	systemStatusFlag    byte
+	batteryLifeTime     uint32
+	batteryFullLifeTime uint32
+}
+
+const (
+	acLineOffline         = 0
+	batteryFlagNone       = 128
+	batteryPercentUnknown = 255
+)
+
+func detect() State {
+	var status systemPowerStatus
+	if ok, _, _ := getSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status))); ok == 0 {
+		return State{Source: SourceUnknown, Percent: -1}
+	}
+	state := State{Source: SourceAC, Percent: -1}
+	if status.batteryFlag == batteryFlagNone {
+		return state
+	}
+	if status.batteryLifePercent != batteryPercentUnknown {
+		state.Percent = int(status.batteryLifePercent)
+	}
+	if status.acLineStatus == acLineOffline {
+		state.Source = SourceBattery
+	}
+	return state
+}
@@ -0,0 +1,100 @@
+// Package power reports whether the machine is running on battery, so
+// expensive background work can be scaled down on laptops.
+package power
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Power sources.
+const (
+	SourceAC      = "ac"
+	SourceBattery = "battery"
+	// SourceUnknown is reported where the platform cannot be asked.
+	SourceUnknown = "unknown"
+)
+
+// State is the machine's power source. Machines without a battery are on
+// AC.
+type State struct {
+	Source string `json:"source"`
+	// Percent is the battery charge, or -1 when there is no battery or it
+	// is not known.
+	Percent int `json:"percent"`
+}
+
+// OnBattery reports whether the machine is running on battery.
+func (s State) OnBattery() bool {
+	return s.Source == SourceBattery
+}
+
+// Detect reads the current power state.
+func Detect() State {
+	return detect()
+}
+
+// readSysfs reads Linux power supplies under root, normally
+// /sys/class/power_supply. An online mains supply means AC; otherwise a
+// discharging battery means battery.
+func readSysfs(root string) State {
+	state := State{Source: SourceAC, Percent: -1}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return state
+	}
+	mainsOnline := false
+	discharging := false
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		switch readSysfsValue(dir, "type") {
+		case "Mains", "USB":
+			if readSysfsValue(dir, "online") == "1" {
+				mainsOnline = true
+			}
+		case "Battery":
+			if readSysfsValue(dir, "scope") == "Device" {
+				// Peripherals such as mice report batteries too.
+				continue
+			}
+			if readSysfsValue(dir, "status") == "Discharging" {
+				discharging = true
+			}
+			if percent, err := strconv.Atoi(readSysfsValue(dir, "capacity")); err == nil && state.Percent < 0 {
+				state.Percent = percent
+			}
+		}
+	}
+	if discharging && !mainsOnline {
+		state.Source = SourceBattery
+	}
+	return state
+}
+
+func readSysfsValue(dir string, name string) string {
+	raw, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(raw))
+}
+
+var pmsetPercent = regexp.MustCompile(`(\d+)%`)
+
+// parsePmset reads the output of macOS `pmset -g batt`.
+func parsePmset(output string) State {
+	state := State{Source: SourceUnknown, Percent: -1}
+	switch {
+	case strings.Contains(output, "'Battery Power'"):
+		state.Source = SourceBattery
+	case strings.Contains(output, "'AC Power'"), strings.Contains(output, "'UPS Power'"):
+		state.Source = SourceAC
+	}
+	if match := pmsetPercent.FindStringSubmatch(output); match != nil {
+		state.Percent, _ = strconv.Atoi(match[1])
+	}
+	return state
+}
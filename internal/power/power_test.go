@@ -0,0 +1,70 @@
+package power
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSysfs(t *testing.T) {
+	t.Parallel()
+
+	supply := func(t *testing.T, root, name string, values map[string]string) {
+		t.Helper()
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for file, value := range values {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(value+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	unplugged := t.TempDir()
+	supply(t, unplugged, "AC", map[string]string{"type": "Mains", "online": "0"})
+	supply(t, unplugged, "BAT0", map[string]string{"type": "Battery", "status": "Discharging", "capacity": "57"})
+	supply(t, unplugged, "hidpp_battery_0", map[string]string{"type": "Battery", "scope": "Device", "status": "Discharging", "capacity": "12"})
+	if got := readSysfs(unplugged); got != (State{Source: SourceBattery, Percent: 57}) {
+		t.Fatalf("readSysfs(unplugged) = %+v", got)
+	}
+
+	plugged := t.TempDir()
+	supply(t, plugged, "AC", map[string]string{"type": "Mains", "online": "1"})
+	supply(t, plugged, "BAT0", map[string]string{"type": "Battery", "status": "Charging", "capacity": "80"})
+	if got := readSysfs(plugged); got != (State{Source: SourceAC, Percent: 80}) {
+		t.Fatalf("readSysfs(plugged) = %+v", got)
+	}
+
+	if got := readSysfs(filepath.Join(t.TempDir(), "missing")); got != (State{Source: SourceAC, Percent: -1}) {
+		t.Fatalf("readSysfs(desktop) = %+v", got)
+	}
+}
+
+func TestParsePmset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		output string
+		want   State
+	}{
+		{
+			output: "Now drawing from 'Battery Power'\n -InternalBattery-0 (id=4653155)\t64%; discharging; 4:12 remaining present: true\n",
+			want:   State{Source: SourceBattery, Percent: 64},
+		},
+		{
+			output: "Now drawing from 'AC Power'\n -InternalBattery-0 (id=4653155)\t100%; charged; 0:00 remaining present: true\n",
+			want:   State{Source: SourceAC, Percent: 100},
+		},
+		{
+			output: "Now drawing from 'AC Power'\n",
+			want:   State{Source: SourceAC, Percent: -1},
+		},
+	}
+	for _, test := range tests {
+		if got := parsePmset(test.output); got != test.want {
+			t.Errorf("parsePmset(%q) = %+v, want %+v", test.output, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+//go:build linux
+
+package procmem
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OpenFiles counts this process's descriptors in /proc/self/fd.
+func OpenFiles() (int, bool) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	// The directory handle used for the listing is included and discounted.
+	return len(entries) - 1, true
+}
+
+// ChildProcesses counts processes whose parent is pid by scanning
+// /proc/<pid>/stat.
+func ChildProcesses(pid int) (int, bool) {
+	if pid <= 0 {
+		return 0, false
+	}
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, false
+	}
+	count := 0
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		raw, err := os.ReadFile("/proc/" + entry.Name() + "/stat")
+		if err != nil {
+			continue
+		}
+		// The command name may contain spaces; fields resume after its ')'.
+		closing := strings.LastIndexByte(string(raw), ')')
+		if closing < 0 {
+			continue
+		}
+		fields := strings.Fields(string(raw[closing+1:]))
+		if len(fields) < 2 {
+			continue
+		}
+		if parent, err := strconv.Atoi(fields[1]); err == nil && parent == pid {
+			count++
+		}
+	}
+	return count, true
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package procmem
+
+// OpenFiles is unsupported on this platform and always reports false.
+func OpenFiles() (int, bool) {
+	return 0, false
+}
+
+// ChildProcesses is unsupported on this platform and always reports false.
+func ChildProcesses(pid int) (int, bool) {
+	_ = pid
+	return 0, false
+}
@@ -0,0 +1,25 @@
+//go:build darwin
+
+package procmem
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ResidentBytes asks ps for resident set size, reported in KiB.
+func ResidentBytes(pid int) (int64, bool) {
+	if pid <= 0 {
+		return 0, false
+	}
+	output, err := exec.Command("ps", "-o", "rss=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return 0, false
+	}
+	residentKiB, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return residentKiB * 1024, true
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package procmem
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ResidentBytes reads resident set size from /proc/<pid>/statm.
+func ResidentBytes(pid int) (int64, bool) {
+	if pid <= 0 {
+		return 0, false
+	}
+	raw, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/statm")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(raw))
+	if len(fields) < 2 {
+		return 0, false
+	}
+	residentPages, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return residentPages * int64(os.Getpagesize()), true
+}
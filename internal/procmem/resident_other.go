@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package procmem
+
+// ResidentBytes is unsupported on this platform and always reports false.
+func ResidentBytes(pid int) (int64, bool) {
+	_ = pid
+	return 0, false
+}
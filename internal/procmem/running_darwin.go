@@ -0,0 +1,24 @@
+//go:build darwin
+
+package procmem
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Running probes pid with signal 0; a permission error still means the
+// process exists.
+func Running(pid int) (bool, bool) {
+	if pid <= 0 {
+		return false, false
+	}
+	err := syscall.Kill(pid, 0)
+	switch {
+	case err == nil, errors.Is(err, syscall.EPERM):
+		return true, true
+	case errors.Is(err, syscall.ESRCH):
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,23 @@
+//go:build linux
+
+package procmem
+
+import (
+	"os"
+	"strconv"
+)
+
+// Running reports whether pid has an entry in /proc.
+func Running(pid int) (bool, bool) {
+	if pid <= 0 {
+		return false, false
+	}
+	_, err := os.Stat("/proc/" + strconv.Itoa(pid))
+	if err == nil {
+		return true, true
+	}
+	if os.IsNotExist(err) {
+		return false, true
+	}
+	return false, false
+}
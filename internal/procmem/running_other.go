@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package procmem
+
+// Running is unsupported on this platform and always reports false.
+func Running(pid int) (bool, bool) {
+	_ = pid
+	return false, false
+}
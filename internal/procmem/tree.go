@@ -0,0 +1,53 @@
+package procmem
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupported is returned where the platform cannot list processes.
+var ErrUnsupported = errors.New("process listing is not supported on this platform")
+
+// Process is one entry of a process tree.
+type Process struct {
+	PID     int    `json:"pid"`
+	PPID    int    `json:"ppid"`
+	Command string `json:"command"`
+	// CPUPercent is the average CPU use since the process started, where 100
+	// is one fully used core.
+	CPUPercent    float64 `json:"cpuPercent"`
+	ResidentBytes int64   `json:"residentBytes"`
+}
+
+// ProcessTree returns root and all of its descendants, parents before
+// children. It returns an empty tree when root has exited.
+func ProcessTree(root int) ([]Process, error) {
+	if root <= 0 {
+		return nil, fmt.Errorf("invalid pid %d", root)
+	}
+	table, err := listProcesses()
+	if err != nil {
+		return nil, err
+	}
+	return descendants(table, root), nil
+}
+
+// descendants walks table breadth-first from root.
+func descendants(table []Process, root int) []Process {
+	children := make(map[int][]Process)
+	tree := make([]Process, 0)
+	for _, process := range table {
+		if process.PID == root {
+			tree = append(tree, process)
+			continue
+		}
+		children[process.PPID] = append(children[process.PPID], process)
+	}
+	if len(tree) == 0 {
+		return tree
+	}
+	for index := 0; index < len(tree); index++ {
+		tree = append(tree, children[tree[index].PID]...)
+	}
+	return tree
+}
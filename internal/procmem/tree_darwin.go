@@ -0,0 +1,40 @@
+//go:build darwin
+
+package procmem
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// listProcesses asks ps for every process. RSS is reported in KiB.
+func listProcesses() ([]Process, error) {
+	output, err := exec.Command("ps", "-A", "-o", "pid=,ppid=,%cpu=,rss=,command=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+	table := make([]Process, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		parent, _ := strconv.Atoi(fields[1])
+		cpu, _ := strconv.ParseFloat(fields[2], 64)
+		residentKiB, _ := strconv.ParseInt(fields[3], 10, 64)
+		table = append(table, Process{
+			PID:           pid,
+			PPID:          parent,
+			Command:       strings.Join(fields[4:], " "),
+			CPUPercent:    cpu,
+			ResidentBytes: residentKiB * 1024,
+		})
+	}
+	return table, nil
+}
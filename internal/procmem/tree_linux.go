@@ -0,0 +1,87 @@
+//go:build linux
+
+package procmem
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// clockTicks is USER_HZ, which Linux fixes at 100 for /proc accounting.
+const clockTicks = 100
+
+// listProcesses reads every process from /proc.
+func listProcesses() ([]Process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("list processes: %w", err)
+	}
+	uptime, _ := systemUptime()
+
+	table := make([]Process, 0, len(entries))
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		process, ok := readProcess(pid, uptime)
+		if ok {
+			table = append(table, process)
+		}
+	}
+	return table, nil
+}
+
+// readProcess reads pid's stat, statm and cmdline. Processes that exit
+// while being read are skipped.
+func readProcess(pid int, uptime float64) (Process, bool) {
+	raw, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return Process{}, false
+	}
+	opening := bytes.IndexByte(raw, '(')
+	closing := bytes.LastIndexByte(raw, ')')
+	if opening < 0 || closing < opening {
+		return Process{}, false
+	}
+	// Fields after the command name start at state (field 3 of stat).
+	fields := strings.Fields(string(raw[closing+1:]))
+	if len(fields) < 20 {
+		return Process{}, false
+	}
+	parent, _ := strconv.Atoi(fields[1])
+	process := Process{PID: pid, PPID: parent, Command: string(raw[opening+1 : closing])}
+
+	userTicks, _ := strconv.ParseFloat(fields[11], 64)
+	systemTicks, _ := strconv.ParseFloat(fields[12], 64)
+	startTicks, _ := strconv.ParseFloat(fields[19], 64)
+	if elapsed := uptime - startTicks/clockTicks; elapsed > 0 {
+		process.CPUPercent = (userTicks + systemTicks) / clockTicks / elapsed * 100
+	}
+	if resident, ok := ResidentBytes(pid); ok {
+		process.ResidentBytes = resident
+	}
+	if cmdline, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline"); err == nil && len(cmdline) > 0 {
+		process.Command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+	return process, true
+}
+
+func systemUptime() (float64, bool) {
+	raw, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(raw))
+	if len(fields) == 0 {
+		return 0, false
+	}
+	uptime, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return uptime, true
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package procmem
+
+// listProcesses is unsupported on this platform.
+func listProcesses() ([]Process, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,54 @@
+package procmem
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestDescendantsWalksParentsBeforeChildren(t *testing.T) {
+	table := []Process{
+		{PID: 1, PPID: 0},
+		{PID: 12, PPID: 11},
+		{PID: 10, PPID: 1},
+		{PID: 11, PPID: 10},
+		{PID: 13, PPID: 10},
+		{PID: 20, PPID: 1},
+	}
+
+	tree := descendants(table, 10)
+	got := make([]int, 0, len(tree))
+	for _, process := range tree {
+		got = append(got, process.PID)
+	}
+	want := []int{10, 11, 13, 12}
+	if len(got) != len(want) {
+		t.Fatalf("tree = %v, want %v", got, want)
+	}
+	for index := range want {
+		if got[index] != want[index] {
+			t.Fatalf("tree = %v, want %v", got, want)
+		}
+	}
+
+	if tree := descendants(table, 99); len(tree) != 0 {
+		t.Fatalf("tree of missing root = %+v, want empty", tree)
+	}
+}
+
+func TestProcessTreeIncludesCurrentProcess(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("process listing unsupported")
+	}
+
+	tree, err := ProcessTree(os.Getpid())
+	if err != nil {
+		t.Fatalf("ProcessTree() error = %v", err)
+	}
+	if len(tree) == 0 || tree[0].PID != os.Getpid() {
+		t.Fatalf("tree = %+v, want current process first", tree)
+	}
+	if tree[0].Command == "" || tree[0].ResidentBytes <= 0 {
+		t.Fatalf("root = %+v, want command and resident memory", tree[0])
+	}
+}
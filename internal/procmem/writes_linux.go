@@ -0,0 +1,33 @@
+//go:build linux
+
+package procmem
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WriteBytes reads how many bytes pid caused to be written to storage from
+// the write_bytes line of /proc/<pid>/io.
+func WriteBytes(pid int) (int64, bool) {
+	if pid <= 0 {
+		return 0, false
+	}
+	file, err := os.Open("/proc/" + strconv.Itoa(pid) + "/io")
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		value, found := strings.CutPrefix(scanner.Text(), "write_bytes:")
+		if !found {
+			continue
+		}
+		written, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		return written, err == nil
+	}
+	return 0, false
+}
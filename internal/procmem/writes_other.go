@@ -0,0 +1,9 @@
+//go:build !linux
+
+package procmem
+
+// WriteBytes is unsupported on this platform and always reports false.
+func WriteBytes(pid int) (int64, bool) {
+	_ = pid
+	return 0, false
+}
@@ -12,6 +12,9 @@ type ModuleInfo struct {
 	Path       string
 	ModuleFile string
 	HasModule  bool
+	// GOPATH places a project without a go.mod in GOPATH mode; it is the
+	// zero value for module projects.
+	GOPATH GOPATHInfo
 }
 
 // DetectModule checks for a go.mod file in the given path.
@@ -35,6 +38,7 @@ func DetectModule(ctx context.Context, path string) (ModuleInfo, error) {
 				Path:       absolutePath,
 				ModuleFile: moduleFile,
 				HasModule:  false,
+				GOPATH:     DetectGOPATH(absolutePath),
 			}, nil
 		}
 		return ModuleInfo{}, fmt.Errorf("inspect go.mod: %w", err)
@@ -0,0 +1,92 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Experiment describes one GOEXPERIMENT value gopoke offers as an option.
+type Experiment struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ExperimentSupport reports whether a toolchain accepts an experiment.
+type ExperimentSupport struct {
+	Experiment
+	Supported bool `json:"supported"`
+}
+
+// KnownExperiments is the validated set of GOEXPERIMENT values. Toolchains
+// accept only a subset; DetectExperiments reports which.
+var KnownExperiments = []Experiment{
+	{Name: "aliastypeparams", Description: "Generic type aliases"},
+	{Name: "arenas", Description: "Manual memory arenas (arena package)"},
+	{Name: "boringcrypto", Description: "BoringCrypto-backed crypto packages"},
+	{Name: "cgocheck2", Description: "Expensive cgo pointer checks"},
+	{Name: "greenteagc", Description: "Green Tea garbage collector"},
+	{Name: "jsonv2", Description: "encoding/json/v2 packages"},
+	{Name: "loopvar", Description: "Per-iteration loop variables"},
+	{Name: "newinliner", Description: "New inliner heuristics"},
+	{Name: "rangefunc", Description: "Range over function iterators"},
+	{Name: "spinbitmutex", Description: "Spin-bit runtime mutex"},
+	{Name: "swissmap", Description: "Swiss table map implementation"},
+	{Name: "synctest", Description: "testing/synctest package"},
+}
+
+// NormalizeExperiments validates names against KnownExperiments and returns
+// them lowercased, deduplicated and sorted.
+func NormalizeExperiments(names []string) ([]string, error) {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !isKnownExperiment(name) {
+			return nil, fmt.Errorf("unknown GOEXPERIMENT %q", name)
+		}
+		if !slices.Contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// DetectExperiments asks the toolchain to accept each known experiment in
+// turn; the go command rejects experiments it does not know.
+func DetectExperiments(ctx context.Context, toolchainPath string) ([]ExperimentSupport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("detect experiments context: %w", err)
+	}
+	if strings.TrimSpace(toolchainPath) == "" {
+		return nil, fmt.Errorf("toolchain is required")
+	}
+	support := make([]ExperimentSupport, 0, len(KnownExperiments))
+	for _, experiment := range KnownExperiments {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("detect experiments context: %w", err)
+		}
+		cmd := exec.CommandContext(ctx, toolchainPath, "env", "GOEXPERIMENT")
+		cmd.Env = append(os.Environ(), "GOEXPERIMENT="+experiment.Name, "GOTOOLCHAIN=local")
+		support = append(support, ExperimentSupport{
+			Experiment: experiment,
+			Supported:  cmd.Run() == nil,
+		})
+	}
+	return support, nil
+}
+
+func isKnownExperiment(name string) bool {
+	for _, experiment := range KnownExperiments {
+		if experiment.Name == name {
+			return true
+		}
+	}
+	return false
+}
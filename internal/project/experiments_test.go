@@ -0,0 +1,46 @@
+package project
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeExperiments(t *testing.T) {
+	t.Parallel()
+
+	got, err := NormalizeExperiments([]string{" Rangefunc", "arenas", "", "rangefunc"})
+	if err != nil {
+		t.Fatalf("NormalizeExperiments() error = %v", err)
+	}
+	if want := []string{"arenas", "rangefunc"}; !slices.Equal(got, want) {
+		t.Fatalf("NormalizeExperiments() = %v, want %v", got, want)
+	}
+	if _, err := NormalizeExperiments([]string{"arenas,rangefunc"}); err == nil {
+		t.Fatal("NormalizeExperiments(raw list) error = nil, want error")
+	}
+}
+
+func TestDetectExperiments(t *testing.T) {
+	t.Parallel()
+
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go binary not available")
+	}
+	support, err := DetectExperiments(context.Background(), goPath)
+	if err != nil {
+		t.Fatalf("DetectExperiments() error = %v", err)
+	}
+	if got, want := len(support), len(KnownExperiments); got != want {
+		t.Fatalf("len(support) = %d, want %d", got, want)
+	}
+	anySupported := false
+	for _, experiment := range support {
+		anySupported = anySupported || experiment.Supported
+	}
+	if !anySupported {
+		t.Fatal("no known experiment supported by the local toolchain")
+	}
+}
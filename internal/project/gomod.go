@@ -0,0 +1,218 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// GoModRequire is one require directive.
+type GoModRequire struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Indirect bool   `json:"indirect"`
+}
+
+// GoModReplace is one replace directive. NewVersion is empty for local
+// directory replacements.
+type GoModReplace struct {
+	OldPath    string `json:"oldPath"`
+	OldVersion string `json:"oldVersion,omitempty"`
+	NewPath    string `json:"newPath"`
+	NewVersion string `json:"newVersion,omitempty"`
+}
+
+// GoModExclude is one exclude directive.
+type GoModExclude struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// GoMod is the structured content of a project's go.mod.
+type GoMod struct {
+	ModuleFile string         `json:"moduleFile"`
+	ModulePath string         `json:"modulePath"`
+	GoVersion  string         `json:"goVersion"`
+	Toolchain  string         `json:"toolchain,omitempty"`
+	Requires   []GoModRequire `json:"requires"`
+	Replaces   []GoModReplace `json:"replaces"`
+	Excludes   []GoModExclude `json:"excludes"`
+}
+
+// ParseGoMod reads and parses go.mod in projectPath.
+func ParseGoMod(ctx context.Context, projectPath string) (GoMod, error) {
+	if err := ctx.Err(); err != nil {
+		return GoMod{}, fmt.Errorf("parse go.mod context: %w", err)
+	}
+	moduleFile := filepath.Join(projectPath, "go.mod")
+	data, err := os.ReadFile(moduleFile)
+	if err != nil {
+		return GoMod{}, fmt.Errorf("read go.mod: %w", err)
+	}
+	file, err := modfile.Parse(moduleFile, data, nil)
+	if err != nil {
+		return GoMod{}, fmt.Errorf("parse go.mod: %w", err)
+	}
+	return goModFromFile(moduleFile, file), nil
+}
+
+// AddReplace adds or updates a replace directive. A local directory target
+// is written with an empty newVersion; a module target needs a version.
+func AddReplace(ctx context.Context, projectPath string, oldPath string, oldVersion string, newPath string, newVersion string) (GoMod, error) {
+	oldPath = strings.TrimSpace(oldPath)
+	oldVersion = strings.TrimSpace(oldVersion)
+	newPath = strings.TrimSpace(newPath)
+	newVersion = strings.TrimSpace(newVersion)
+
+	if err := module.CheckImportPath(oldPath); err != nil {
+		return GoMod{}, fmt.Errorf("invalid replaced module: %w", err)
+	}
+	if oldVersion != "" {
+		if err := module.Check(oldPath, oldVersion); err != nil {
+			return GoMod{}, fmt.Errorf("invalid replaced version: %w", err)
+		}
+	}
+	if newVersion == "" {
+		if !modfile.IsDirectoryPath(newPath) {
+			return GoMod{}, fmt.Errorf("replacement %q must be a local directory (./, ../ or absolute) or include a version", newPath)
+		}
+	} else if err := module.Check(newPath, newVersion); err != nil {
+		return GoMod{}, fmt.Errorf("invalid replacement module: %w", err)
+	}
+
+	return editGoMod(ctx, projectPath, func(file *modfile.File) error {
+		return file.AddReplace(oldPath, oldVersion, newPath, newVersion)
+	})
+}
+
+// DropRequire removes a require directive. It fails if the module is not required.
+func DropRequire(ctx context.Context, projectPath string, modulePath string) (GoMod, error) {
+	modulePath = strings.TrimSpace(modulePath)
+	if modulePath == "" {
+		return GoMod{}, fmt.Errorf("module path is required")
+	}
+	return editGoMod(ctx, projectPath, func(file *modfile.File) error {
+		for _, require := range file.Require {
+			if require.Mod.Path == modulePath {
+				return file.DropRequire(modulePath)
+			}
+		}
+		return fmt.Errorf("module %s is not required", modulePath)
+	})
+}
+
+func editGoMod(ctx context.Context, projectPath string, edit func(*modfile.File) error) (GoMod, error) {
+	if err := ctx.Err(); err != nil {
+		return GoMod{}, fmt.Errorf("edit go.mod context: %w", err)
+	}
+	moduleFile := filepath.Join(projectPath, "go.mod")
+	original, err := os.ReadFile(moduleFile)
+	if err != nil {
+		return GoMod{}, fmt.Errorf("read go.mod: %w", err)
+	}
+	file, err := modfile.Parse(moduleFile, original, nil)
+	if err != nil {
+		return GoMod{}, fmt.Errorf("parse go.mod: %w", err)
+	}
+	if err := edit(file); err != nil {
+		return GoMod{}, fmt.Errorf("edit go.mod: %w", err)
+	}
+	file.Cleanup()
+	formatted, err := file.Format()
+	if err != nil {
+		return GoMod{}, fmt.Errorf("format go.mod: %w", err)
+	}
+	// Re-parse the output so a bad edit never reaches disk.
+	updated, err := modfile.Parse(moduleFile, formatted, nil)
+	if err != nil {
+		return GoMod{}, fmt.Errorf("validate edited go.mod: %w", err)
+	}
+	if err := replaceModFile(moduleFile, original, formatted); err != nil {
+		return GoMod{}, err
+	}
+	return goModFromFile(moduleFile, updated), nil
+}
+
+// replaceModFile atomically swaps path to data, refusing if the file changed
+// since original was read.
+func replaceModFile(path string, original []byte, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", filepath.Base(path), err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp %s: %w", filepath.Base(path), err)
+	}
+	tempPath := temp.Name()
+	defer os.Remove(tempPath)
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("write temp %s: %w", filepath.Base(path), err)
+	}
+	if err := temp.Chmod(info.Mode().Perm()); err != nil {
+		temp.Close()
+		return fmt.Errorf("chmod temp %s: %w", filepath.Base(path), err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("close temp %s: %w", filepath.Base(path), err)
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reread %s: %w", filepath.Base(path), err)
+	}
+	if !bytes.Equal(current, original) {
+		return fmt.Errorf("%s changed on disk during edit; reload and retry", filepath.Base(path))
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("replace %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func goModFromFile(moduleFile string, file *modfile.File) GoMod {
+	result := GoMod{
+		ModuleFile: moduleFile,
+		Requires:   make([]GoModRequire, 0, len(file.Require)),
+		Replaces:   make([]GoModReplace, 0, len(file.Replace)),
+		Excludes:   make([]GoModExclude, 0, len(file.Exclude)),
+	}
+	if file.Module != nil {
+		result.ModulePath = file.Module.Mod.Path
+	}
+	if file.Go != nil {
+		result.GoVersion = file.Go.Version
+	}
+	if file.Toolchain != nil {
+		result.Toolchain = file.Toolchain.Name
+	}
+	for _, require := range file.Require {
+		result.Requires = append(result.Requires, GoModRequire{
+			Path:     require.Mod.Path,
+			Version:  require.Mod.Version,
+			Indirect: require.Indirect,
+		})
+	}
+	for _, replace := range file.Replace {
+		result.Replaces = append(result.Replaces, GoModReplace{
+			OldPath:    replace.Old.Path,
+			OldVersion: replace.Old.Version,
+			NewPath:    replace.New.Path,
+			NewVersion: replace.New.Version,
+		})
+	}
+	for _, exclude := range file.Exclude {
+		result.Excludes = append(result.Excludes, GoModExclude{
+			Path:    exclude.Mod.Path,
+			Version: exclude.Mod.Version,
+		})
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testGoMod = `module example.com/app
+
+go 1.22
+
+require (
+	example.com/lib v1.2.0
+	golang.org/x/text v0.14.0 // indirect
+)
+
+exclude example.com/lib v1.1.0
+`
+
+func TestParseGoMod(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	writeGoMod(t, projectDir, testGoMod)
+
+	parsed, err := ParseGoMod(context.Background(), projectDir)
+	if err != nil {
+		t.Fatalf("ParseGoMod() error = %v", err)
+	}
+	if got, want := parsed.ModulePath, "example.com/app"; got != want {
+		t.Fatalf("ModulePath = %q, want %q", got, want)
+	}
+	if got, want := parsed.GoVersion, "1.22"; got != want {
+		t.Fatalf("GoVersion = %q, want %q", got, want)
+	}
+	if got, want := len(parsed.Requires), 2; got != want {
+		t.Fatalf("len(Requires) = %d, want %d", got, want)
+	}
+	if !parsed.Requires[1].Indirect {
+		t.Fatal("Requires[1].Indirect = false, want true")
+	}
+	if got, want := parsed.Excludes, []GoModExclude{{Path: "example.com/lib", Version: "v1.1.0"}}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("Excludes = %+v, want %+v", got, want)
+	}
+}
+
+func TestAddReplaceAndDropRequireRewriteGoMod(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	projectDir := t.TempDir()
+	writeGoMod(t, projectDir, testGoMod)
+
+	updated, err := AddReplace(ctx, projectDir, "example.com/lib", "", "../lib", "")
+	if err != nil {
+		t.Fatalf("AddReplace() error = %v", err)
+	}
+	if got, want := updated.Replaces, []GoModReplace{{OldPath: "example.com/lib", NewPath: "../lib"}}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("Replaces = %+v, want %+v", got, want)
+	}
+
+	updated, err = DropRequire(ctx, projectDir, "golang.org/x/text")
+	if err != nil {
+		t.Fatalf("DropRequire() error = %v", err)
+	}
+	if got, want := len(updated.Requires), 1; got != want {
+		t.Fatalf("len(Requires) = %d, want %d", got, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectDir, "go.mod"))
+	if err != nil {
+		t.Fatalf("read go.mod: %v", err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "replace example.com/lib => ../lib") || strings.Contains(content, "golang.org/x/text") {
+		t.Fatalf("go.mod after edits:\n%s", content)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(projectDir, ".go.mod.*.tmp")); len(matches) != 0 {
+		t.Fatalf("temp files left behind: %v", matches)
+	}
+}
+
+func TestGoModEditsRejectInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	projectDir := t.TempDir()
+	writeGoMod(t, projectDir, testGoMod)
+
+	if _, err := AddReplace(ctx, projectDir, "example.com/lib", "", "example.com/fork", ""); err == nil {
+		t.Fatal("AddReplace(module without version) error = nil, want error")
+	}
+	if _, err := AddReplace(ctx, projectDir, "example.com/lib", "", "example.com/fork", "latest"); err == nil {
+		t.Fatal("AddReplace(invalid version) error = nil, want error")
+	}
+	if _, err := DropRequire(ctx, projectDir, "example.com/missing"); err == nil {
+		t.Fatal("DropRequire(not required) error = nil, want error")
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectDir, "go.mod"))
+	if err != nil {
+		t.Fatalf("read go.mod: %v", err)
+	}
+	if string(data) != testGoMod {
+		t.Fatalf("go.mod changed after rejected edits:\n%s", data)
+	}
+}
+
+func writeGoMod(t *testing.T, dir string, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile(go.mod) error = %v", err)
+	}
+}
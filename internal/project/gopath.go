@@ -0,0 +1,79 @@
+package project
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GOPATHInfo places a project without a go.mod in its GOPATH workspace,
+// where the go command resolves its imports in GOPATH mode.
+type GOPATHInfo struct {
+	// Root is the GOPATH entry holding the project, the parent of the src
+	// directory above it; empty when the project is outside every GOPATH.
+	Root string
+	// ImportPath is the project's import path below Root/src.
+	ImportPath string
+	// Warning explains what does not work for the project, if anything.
+	Warning string
+}
+
+// DetectGOPATH locates projectPath in the configured GOPATH, or failing
+// that in the nearest ancestor directory named src, which is treated as a
+// GOPATH entry of its own.
+func DetectGOPATH(projectPath string) GOPATHInfo {
+	absolutePath, err := filepath.Abs(projectPath)
+	if err != nil {
+		absolutePath = filepath.Clean(projectPath)
+	}
+	for _, root := range filepath.SplitList(build.Default.GOPATH) {
+		if info, ok := gopathEntry(absolutePath, root); ok {
+			return info
+		}
+	}
+	for dir := filepath.Dir(absolutePath); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if filepath.Base(dir) != "src" {
+			continue
+		}
+		if info, ok := gopathEntry(absolutePath, filepath.Dir(dir)); ok {
+			return info
+		}
+	}
+	return GOPATHInfo{Warning: "the project has no go.mod and is not inside a GOPATH src directory; snippets can import only the standard library and GOPATH packages"}
+}
+
+// gopathEntry reports the import path of projectPath below root/src.
+func gopathEntry(projectPath string, root string) (GOPATHInfo, bool) {
+	if strings.TrimSpace(root) == "" {
+		return GOPATHInfo{}, false
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return GOPATHInfo{}, false
+	}
+	rel, err := filepath.Rel(filepath.Join(root, "src"), projectPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return GOPATHInfo{}, false
+	}
+	return GOPATHInfo{Root: root, ImportPath: filepath.ToSlash(rel)}, true
+}
+
+// Environment returns the variables that build the project in GOPATH mode:
+// GO111MODULE=auto, which picks GOPATH mode without a go.mod, GOWORK=off so
+// an inherited go.work cannot force module mode, and GOPATH with the
+// project's workspace first.
+func (info GOPATHInfo) Environment() map[string]string {
+	environment := map[string]string{"GO111MODULE": "auto", "GOWORK": "off"}
+	if info.Root == "" {
+		return environment
+	}
+	entries := []string{info.Root}
+	for _, entry := range filepath.SplitList(build.Default.GOPATH) {
+		if entry != "" && filepath.Clean(entry) != info.Root {
+			entries = append(entries, entry)
+		}
+	}
+	environment["GOPATH"] = strings.Join(entries, string(os.PathListSeparator))
+	return environment
+}
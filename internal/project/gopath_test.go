@@ -0,0 +1,39 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectModuleLocatesGOPATHProject(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	projectDir := filepath.Join(root, "src", "example.com", "legacy")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	module, err := DetectModule(context.Background(), projectDir)
+	if err != nil {
+		t.Fatalf("DetectModule() error = %v", err)
+	}
+	if module.HasModule || module.GOPATH.Root != root || module.GOPATH.ImportPath != "example.com/legacy" || module.GOPATH.Warning != "" {
+		t.Fatalf("DetectModule() = %+v", module)
+	}
+	environment := module.GOPATH.Environment()
+	if environment["GO111MODULE"] != "auto" || !strings.HasPrefix(environment["GOPATH"], root) {
+		t.Fatalf("Environment() = %v", environment)
+	}
+
+	outside := DetectGOPATH(t.TempDir())
+	if outside.Root != "" || outside.Warning == "" {
+		t.Fatalf("DetectGOPATH(outside) = %+v", outside)
+	}
+	if environment := outside.Environment(); environment["GO111MODULE"] != "auto" || environment["GOPATH"] != "" {
+		t.Fatalf("Environment(outside) = %v", environment)
+	}
+}
@@ -0,0 +1,155 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// go.sum problem kinds reported by VerifySums.
+const (
+	// SumMissingEntry means go.sum lacks a checksum a build needs.
+	SumMissingEntry = "missing_entry"
+	// SumChecksumMismatch means a module does not match its go.sum checksum.
+	SumChecksumMismatch = "checksum_mismatch"
+	// SumModified means a module in the download cache was changed on disk.
+	SumModified = "modified"
+)
+
+// SumRemediation is the command that brings go.sum back in line with go.mod.
+const SumRemediation = "go mod tidy"
+
+// SumProblem is one go.sum inconsistency.
+type SumProblem struct {
+	Kind string `json:"kind"`
+	// Module is the module path and version, when the go command named one.
+	Module string `json:"module,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// SumWarning describes go.sum inconsistencies found before a run.
+type SumWarning struct {
+	Problems    []SumProblem `json:"problems"`
+	Remediation string       `json:"remediation"`
+	// Output is what the go command printed.
+	Output string `json:"output"`
+}
+
+// VerifySums checks the module at dir for go.sum inconsistencies a build
+// would stop on: go mod verify for a modified download cache, then a
+// read-only package load for missing or mismatched checksums. It returns nil
+// when nothing is wrong. Failures with other causes, such as compile errors
+// or an unreachable proxy, are left for the run to report.
+func VerifySums(ctx context.Context, toolchain string, dir string, environment map[string]string) (*SumWarning, error) {
+	if strings.TrimSpace(toolchain) == "" {
+		toolchain = "go"
+	}
+	var output strings.Builder
+	problems := make([]SumProblem, 0)
+	for _, args := range [][]string{
+		{"mod", "verify"},
+		{"list", "-mod=readonly", "-deps", "./..."},
+	} {
+		command := exec.CommandContext(ctx, toolchain, args...)
+		command.Dir = dir
+		command.Env = commandEnvironment(environment)
+		var stderr bytes.Buffer
+		command.Stderr = &stderr
+		err := command.Run()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("verify go.sum: %w", ctxErr)
+		}
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("go %s: %w", args[0], err)
+		}
+		found := ParseSumProblems(stderr.String())
+		if len(found) > 0 {
+			problems = append(problems, found...)
+			output.WriteString(stderr.String())
+		}
+	}
+	if len(problems) == 0 {
+		return nil, nil
+	}
+	return &SumWarning{Problems: problems, Remediation: SumRemediation, Output: output.String()}, nil
+}
+
+// ParseSumProblems extracts go.sum problems from go command output.
+func ParseSumProblems(output string) []SumProblem {
+	problems := make([]SumProblem, 0)
+	seen := make(map[SumProblem]bool)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var problem SumProblem
+		switch {
+		case strings.Contains(line, "missing go.sum entry"):
+			problem = SumProblem{Kind: SumMissingEntry, Module: missingEntryModule(line)}
+		case strings.HasSuffix(line, ": checksum mismatch"):
+			module := strings.TrimSuffix(line, ": checksum mismatch")
+			problem = SumProblem{Kind: SumChecksumMismatch, Module: strings.TrimPrefix(strings.TrimPrefix(module, "go: "), "verifying ")}
+		case strings.Contains(line, ": dir has been modified") || strings.Contains(line, ": zip has been modified"):
+			module, _, _ := strings.Cut(line, ": ")
+			problem = SumProblem{Kind: SumModified, Module: module}
+		default:
+			continue
+		}
+		problem.Detail = line
+		if !seen[problem] {
+			seen[problem] = true
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// missingEntryModule names the module or package a missing go.sum entry is
+// for, in either of the go command's phrasings.
+func missingEntryModule(line string) string {
+	if _, rest, ok := strings.Cut(line, "missing go.sum entry for module providing package "); ok {
+		pkg, _, _ := strings.Cut(rest, " ")
+		return strings.TrimSuffix(pkg, ";")
+	}
+	prefix, _, _ := strings.Cut(line, ": missing go.sum entry")
+	prefix = strings.TrimPrefix(prefix, "go: ")
+	if strings.ContainsAny(prefix, "@ ") && !strings.Contains(prefix, ".go:") {
+		return prefix
+	}
+	return ""
+}
+
+// TidyModules runs go mod tidy in dir and returns its combined output.
+func TidyModules(ctx context.Context, toolchain string, dir string, environment map[string]string) (string, error) {
+	if strings.TrimSpace(toolchain) == "" {
+		toolchain = "go"
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("inspect module dir: %w", err)
+	}
+	command := exec.CommandContext(ctx, toolchain, "mod", "tidy")
+	command.Dir = dir
+	command.Env = commandEnvironment(environment)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("go mod tidy: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// commandEnvironment is the process environment with overrides appended;
+// os/exec keeps the last value of a repeated key.
+func commandEnvironment(overrides map[string]string) []string {
+	environment := os.Environ()
+	for key, value := range overrides {
+		if strings.TrimSpace(key) != "" {
+			environment = append(environment, key+"="+value)
+		}
+	}
+	return environment
+}
@@ -0,0 +1,71 @@
+package project
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSumProblems(t *testing.T) {
+	t.Parallel()
+
+	output := `main.go:2:8: missing go.sum entry for module providing package example.com/nope (imported by x); to add:
+	go get x
+go: example.com/dep@v1.2.0: missing go.sum entry for go.mod file; to add it:
+verifying golang.org/x/mod@v0.23.0: checksum mismatch
+	downloaded: h1:Zb7k=
+	go.sum:     h1:Zb7X=
+SECURITY ERROR
+golang.org/x/text v0.3.0: dir has been modified (/cache/golang.org/x/text@v0.3.0)
+go: example.com/other@v1.0.0: module lookup disabled by GOPROXY=off
+`
+	got := ParseSumProblems(output)
+	want := []SumProblem{
+		{Kind: SumMissingEntry, Module: "example.com/nope"},
+		{Kind: SumMissingEntry, Module: "example.com/dep@v1.2.0"},
+		{Kind: SumChecksumMismatch, Module: "golang.org/x/mod@v0.23.0"},
+		{Kind: SumModified, Module: "golang.org/x/text v0.3.0"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseSumProblems() = %+v, want %d problems", got, len(want))
+	}
+	for i := range want {
+		if got[i].Kind != want[i].Kind || got[i].Module != want[i].Module || got[i].Detail == "" {
+			t.Fatalf("problem %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestVerifySumsFindsMissingEntry(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	dir := t.TempDir()
+	write := func(name string, contents string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("go.mod", "module example.com/sums\n\ngo 1.22\n")
+	write("main.go", "package main\n\nfunc main() {}\n")
+	offline := map[string]string{"GOPROXY": "off", "GOFLAGS": "", "GOWORK": "off"}
+
+	warning, err := VerifySums(context.Background(), "", dir, offline)
+	if err != nil || warning != nil {
+		t.Fatalf("VerifySums(clean) = %+v, %v; want no warning", warning, err)
+	}
+
+	write("go.mod", "module example.com/sums\n\ngo 1.22\n\nrequire example.com/nope v1.0.0\n")
+	write("main.go", "package main\n\nimport _ \"example.com/nope\"\n\nfunc main() {}\n")
+	warning, err = VerifySums(context.Background(), "", dir, offline)
+	if err != nil {
+		t.Fatalf("VerifySums() error = %v", err)
+	}
+	if warning == nil || len(warning.Problems) != 1 || warning.Problems[0].Module != "example.com/nope" || warning.Remediation != SumRemediation {
+		t.Fatalf("VerifySums() = %+v, want a missing entry for example.com/nope", warning)
+	}
+}
@@ -0,0 +1,236 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// defaultWorkGoVersion is used when no module declares a go version.
+const defaultWorkGoVersion = "1.22"
+
+// GoWorkUse is one use directive and the module found in that directory.
+type GoWorkUse struct {
+	Path       string `json:"path"`
+	ModulePath string `json:"modulePath"`
+}
+
+// GoWork is the structured content of a go.work file.
+type GoWork struct {
+	WorkFile  string      `json:"workFile"`
+	GoVersion string      `json:"goVersion"`
+	Uses      []GoWorkUse `json:"uses"`
+}
+
+// ParseGoWork reads and parses go.work in projectPath.
+func ParseGoWork(ctx context.Context, projectPath string) (GoWork, error) {
+	if err := ctx.Err(); err != nil {
+		return GoWork{}, fmt.Errorf("parse go.work context: %w", err)
+	}
+	workFile := filepath.Join(projectPath, "go.work")
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return GoWork{}, fmt.Errorf("read go.work: %w", err)
+	}
+	file, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return GoWork{}, fmt.Errorf("parse go.work: %w", err)
+	}
+	return goWorkFromFile(workFile, file), nil
+}
+
+// CreateGoWork writes a new go.work in projectPath using the given module
+// directories. It refuses to overwrite an existing go.work.
+func CreateGoWork(ctx context.Context, projectPath string, moduleDirs []string) (GoWork, error) {
+	if err := ctx.Err(); err != nil {
+		return GoWork{}, fmt.Errorf("create go.work context: %w", err)
+	}
+	if len(moduleDirs) == 0 {
+		return GoWork{}, fmt.Errorf("at least one module directory is required")
+	}
+	workFile := filepath.Join(projectPath, "go.work")
+	file, err := modfile.ParseWork(workFile, nil, nil)
+	if err != nil {
+		return GoWork{}, fmt.Errorf("initialize go.work: %w", err)
+	}
+	if err := file.AddGoStmt(defaultWorkGoVersion); err != nil {
+		return GoWork{}, fmt.Errorf("initialize go.work: %w", err)
+	}
+	for _, moduleDir := range moduleDirs {
+		if err := addWorkUse(file, projectPath, moduleDir); err != nil {
+			return GoWork{}, err
+		}
+	}
+
+	formatted, err := formatWorkFile(workFile, file)
+	if err != nil {
+		return GoWork{}, err
+	}
+	handle, err := os.OpenFile(workFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return GoWork{}, fmt.Errorf("create go.work: %w", err)
+	}
+	if _, err := handle.Write(formatted); err != nil {
+		handle.Close()
+		os.Remove(workFile)
+		return GoWork{}, fmt.Errorf("write go.work: %w", err)
+	}
+	if err := handle.Close(); err != nil {
+		return GoWork{}, fmt.Errorf("close go.work: %w", err)
+	}
+	return goWorkFromFile(workFile, file), nil
+}
+
+// AddWorkModule adds a module directory to go.work after checking it holds a
+// valid go.mod whose module is not already used.
+func AddWorkModule(ctx context.Context, projectPath string, moduleDir string) (GoWork, error) {
+	return editGoWork(ctx, projectPath, func(file *modfile.WorkFile) error {
+		return addWorkUse(file, projectPath, moduleDir)
+	})
+}
+
+// DropWorkModule removes a module directory from go.work.
+func DropWorkModule(ctx context.Context, projectPath string, moduleDir string) (GoWork, error) {
+	return editGoWork(ctx, projectPath, func(file *modfile.WorkFile) error {
+		usePath, _, err := workUsePath(projectPath, moduleDir)
+		if err != nil {
+			return err
+		}
+		for _, use := range file.Use {
+			if filepath.Clean(use.Path) == filepath.Clean(usePath) {
+				return file.DropUse(use.Path)
+			}
+		}
+		return fmt.Errorf("module directory %s is not in go.work", usePath)
+	})
+}
+
+func editGoWork(ctx context.Context, projectPath string, edit func(*modfile.WorkFile) error) (GoWork, error) {
+	if err := ctx.Err(); err != nil {
+		return GoWork{}, fmt.Errorf("edit go.work context: %w", err)
+	}
+	workFile := filepath.Join(projectPath, "go.work")
+	original, err := os.ReadFile(workFile)
+	if err != nil {
+		return GoWork{}, fmt.Errorf("read go.work: %w", err)
+	}
+	file, err := modfile.ParseWork(workFile, original, nil)
+	if err != nil {
+		return GoWork{}, fmt.Errorf("parse go.work: %w", err)
+	}
+	if err := edit(file); err != nil {
+		return GoWork{}, fmt.Errorf("edit go.work: %w", err)
+	}
+	formatted, err := formatWorkFile(workFile, file)
+	if err != nil {
+		return GoWork{}, err
+	}
+	if err := replaceModFile(workFile, original, formatted); err != nil {
+		return GoWork{}, err
+	}
+	return goWorkFromFile(workFile, file), nil
+}
+
+// addWorkUse validates moduleDir and adds it, raising the go version when
+// the module requires a newer one.
+func addWorkUse(file *modfile.WorkFile, projectPath string, moduleDir string) error {
+	usePath, absoluteDir, err := workUsePath(projectPath, moduleDir)
+	if err != nil {
+		return err
+	}
+	modulePath, goVersion, err := readModuleHeader(absoluteDir)
+	if err != nil {
+		return err
+	}
+	for _, use := range file.Use {
+		if filepath.Clean(use.Path) == filepath.Clean(usePath) {
+			return fmt.Errorf("module directory %s is already in go.work", usePath)
+		}
+		existingDir := use.Path
+		if !filepath.IsAbs(existingDir) {
+			existingDir = filepath.Join(projectPath, existingDir)
+		}
+		if existingModule, _, err := readModuleHeader(existingDir); err == nil && existingModule == modulePath {
+			return fmt.Errorf("module %s is already provided by %s", modulePath, use.Path)
+		}
+	}
+	if err := file.AddUse(usePath, modulePath); err != nil {
+		return fmt.Errorf("add use %s: %w", usePath, err)
+	}
+	if goVersion != "" && (file.Go == nil || semver.Compare("v"+goVersion, "v"+file.Go.Version) > 0) {
+		if err := file.AddGoStmt(goVersion); err != nil {
+			return fmt.Errorf("raise go.work go version: %w", err)
+		}
+	}
+	return nil
+}
+
+// workUsePath returns the use path as go work writes it (./rel for
+// directories inside the workspace) and the absolute directory.
+func workUsePath(projectPath string, moduleDir string) (string, string, error) {
+	moduleDir = strings.TrimSpace(moduleDir)
+	if moduleDir == "" {
+		return "", "", fmt.Errorf("module directory is required")
+	}
+	absoluteDir := moduleDir
+	if !filepath.IsAbs(absoluteDir) {
+		absoluteDir = filepath.Join(projectPath, absoluteDir)
+	}
+	absoluteDir = filepath.Clean(absoluteDir)
+	rel, err := filepath.Rel(projectPath, absoluteDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return absoluteDir, absoluteDir, nil
+	}
+	if rel == "." {
+		return ".", absoluteDir, nil
+	}
+	return "./" + filepath.ToSlash(rel), absoluteDir, nil
+}
+
+func readModuleHeader(moduleDir string) (string, string, error) {
+	moduleFile := filepath.Join(moduleDir, "go.mod")
+	data, err := os.ReadFile(moduleFile)
+	if err != nil {
+		return "", "", fmt.Errorf("module directory %s has no readable go.mod: %w", moduleDir, err)
+	}
+	file, err := modfile.ParseLax(moduleFile, data, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("parse %s: %w", moduleFile, err)
+	}
+	if file.Module == nil || file.Module.Mod.Path == "" {
+		return "", "", fmt.Errorf("%s has no module directive", moduleFile)
+	}
+	goVersion := ""
+	if file.Go != nil {
+		goVersion = file.Go.Version
+	}
+	return file.Module.Mod.Path, goVersion, nil
+}
+
+func formatWorkFile(workFile string, file *modfile.WorkFile) ([]byte, error) {
+	file.Cleanup()
+	formatted := modfile.Format(file.Syntax)
+	if _, err := modfile.ParseWork(workFile, formatted, nil); err != nil {
+		return nil, fmt.Errorf("validate edited go.work: %w", err)
+	}
+	return formatted, nil
+}
+
+func goWorkFromFile(workFile string, file *modfile.WorkFile) GoWork {
+	result := GoWork{
+		WorkFile: workFile,
+		Uses:     make([]GoWorkUse, 0, len(file.Use)),
+	}
+	if file.Go != nil {
+		result.GoVersion = file.Go.Version
+	}
+	for _, use := range file.Use {
+		result.Uses = append(result.Uses, GoWorkUse{Path: use.Path, ModulePath: use.ModulePath})
+	}
+	return result
+}
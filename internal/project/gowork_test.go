@@ -0,0 +1,109 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateGoWorkAndManageModules(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workspace := t.TempDir()
+	writeWorkModule(t, workspace, "app", "example.com/app", "1.22")
+	writeWorkModule(t, workspace, "lib", "example.com/lib", "1.23")
+
+	created, err := CreateGoWork(ctx, workspace, []string{"app"})
+	if err != nil {
+		t.Fatalf("CreateGoWork() error = %v", err)
+	}
+	if got, want := created.Uses, []GoWorkUse{{Path: "./app", ModulePath: "example.com/app"}}; len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("Uses = %+v, want %+v", got, want)
+	}
+	if _, err := CreateGoWork(ctx, workspace, []string{"lib"}); err == nil {
+		t.Fatal("CreateGoWork(existing) error = nil, want error")
+	}
+
+	added, err := AddWorkModule(ctx, workspace, filepath.Join(workspace, "lib"))
+	if err != nil {
+		t.Fatalf("AddWorkModule() error = %v", err)
+	}
+	if got, want := len(added.Uses), 2; got != want {
+		t.Fatalf("len(Uses) = %d, want %d", got, want)
+	}
+	if got, want := added.GoVersion, "1.23"; got != want {
+		t.Fatalf("GoVersion = %q, want %q", got, want)
+	}
+
+	dropped, err := DropWorkModule(ctx, workspace, "./app")
+	if err != nil {
+		t.Fatalf("DropWorkModule() error = %v", err)
+	}
+	if got, want := len(dropped.Uses), 1; got != want {
+		t.Fatalf("len(Uses) = %d, want %d", got, want)
+	}
+
+	parsed, err := ParseGoWork(ctx, workspace)
+	if err != nil {
+		t.Fatalf("ParseGoWork() error = %v", err)
+	}
+	if got, want := parsed.Uses[0].Path, "./lib"; got != want {
+		t.Fatalf("Uses[0].Path = %q, want %q", got, want)
+	}
+}
+
+func TestGoWorkEditsRejectInvalidModules(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	workspace := t.TempDir()
+	writeWorkModule(t, workspace, "app", "example.com/app", "1.22")
+	writeWorkModule(t, workspace, "copy", "example.com/app", "1.22")
+	if err := os.MkdirAll(filepath.Join(workspace, "empty"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	if _, err := CreateGoWork(ctx, workspace, []string{"empty"}); err == nil {
+		t.Fatal("CreateGoWork(no go.mod) error = nil, want error")
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "go.work")); !os.IsNotExist(err) {
+		t.Fatalf("go.work exists after rejected create: %v", err)
+	}
+	if _, err := CreateGoWork(ctx, workspace, []string{"app"}); err != nil {
+		t.Fatalf("CreateGoWork() error = %v", err)
+	}
+	original, err := os.ReadFile(filepath.Join(workspace, "go.work"))
+	if err != nil {
+		t.Fatalf("read go.work: %v", err)
+	}
+
+	if _, err := AddWorkModule(ctx, workspace, "app"); err == nil {
+		t.Fatal("AddWorkModule(duplicate dir) error = nil, want error")
+	}
+	if _, err := AddWorkModule(ctx, workspace, "copy"); err == nil || !strings.Contains(err.Error(), "already provided") {
+		t.Fatalf("AddWorkModule(duplicate module) error = %v, want already provided", err)
+	}
+	if _, err := DropWorkModule(ctx, workspace, "missing"); err == nil {
+		t.Fatal("DropWorkModule(not used) error = nil, want error")
+	}
+
+	data, err := os.ReadFile(filepath.Join(workspace, "go.work"))
+	if err != nil {
+		t.Fatalf("read go.work: %v", err)
+	}
+	if string(data) != string(original) {
+		t.Fatalf("go.work changed after rejected edits:\n%s", data)
+	}
+}
+
+func writeWorkModule(t *testing.T, workspace string, dir string, modulePath string, goVersion string) {
+	t.Helper()
+	moduleDir := filepath.Join(workspace, dir)
+	if err := os.MkdirAll(moduleDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	writeGoMod(t, moduleDir, "module "+modulePath+"\n\ngo "+goVersion+"\n")
+}
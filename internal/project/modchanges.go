@@ -0,0 +1,171 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Requirement change kinds.
+const (
+	RequireAdded   = "added"
+	RequireChanged = "changed"
+	RequireRemoved = "removed"
+)
+
+// RequireChange is one require directive an edit added, moved to another
+// version or removed.
+type RequireChange struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Indirect bool   `json:"indirect"`
+}
+
+// ModuleChanges is what an edit did to a module's requirements and go.sum.
+type ModuleChanges struct {
+	Requires []RequireChange `json:"requires"`
+	// SumLines are the go.sum lines the edit added.
+	SumLines []string `json:"sumLines"`
+}
+
+// Empty reports whether there is nothing to apply.
+func (c ModuleChanges) Empty() bool {
+	return len(c.Requires) == 0 && len(c.SumLines) == 0
+}
+
+// DiffModuleFiles compares go.mod and go.sum contents before and after an
+// edit. Missing go.sum contents are passed as nil.
+func DiffModuleFiles(baseMod []byte, editedMod []byte, baseSum []byte, editedSum []byte) (ModuleChanges, error) {
+	base, err := modfile.Parse("go.mod", baseMod, nil)
+	if err != nil {
+		return ModuleChanges{}, fmt.Errorf("parse original go.mod: %w", err)
+	}
+	edited, err := modfile.Parse("go.mod", editedMod, nil)
+	if err != nil {
+		return ModuleChanges{}, fmt.Errorf("parse edited go.mod: %w", err)
+	}
+
+	changes := ModuleChanges{Requires: []RequireChange{}, SumLines: []string{}}
+	before := make(map[string]*modfile.Require, len(base.Require))
+	for _, require := range base.Require {
+		before[require.Mod.Path] = require
+	}
+	for _, require := range edited.Require {
+		previous, ok := before[require.Mod.Path]
+		delete(before, require.Mod.Path)
+		switch {
+		case !ok:
+			changes.Requires = append(changes.Requires, RequireChange{
+				Path: require.Mod.Path, Kind: RequireAdded, To: require.Mod.Version, Indirect: require.Indirect,
+			})
+		case previous.Mod.Version != require.Mod.Version:
+			changes.Requires = append(changes.Requires, RequireChange{
+				Path: require.Mod.Path, Kind: RequireChanged, From: previous.Mod.Version, To: require.Mod.Version, Indirect: require.Indirect,
+			})
+		}
+	}
+	for path, require := range before {
+		changes.Requires = append(changes.Requires, RequireChange{
+			Path: path, Kind: RequireRemoved, From: require.Mod.Version, Indirect: require.Indirect,
+		})
+	}
+	slices.SortFunc(changes.Requires, func(left, right RequireChange) int {
+		return strings.Compare(left.Path, right.Path)
+	})
+
+	known := make(map[string]bool)
+	for _, line := range sumLines(baseSum) {
+		known[line] = true
+	}
+	for _, line := range sumLines(editedSum) {
+		if !known[line] {
+			known[line] = true
+			changes.SumLines = append(changes.SumLines, line)
+		}
+	}
+	return changes, nil
+}
+
+// ApplyModuleChanges applies changes to the go.mod and go.sum in
+// projectPath, leaving the rest of both files as they are: requirements are
+// added, moved or dropped and the new go.sum lines merged in. go.sum is
+// written first, so a go.mod that fails to update never names modules
+// without checksums.
+func ApplyModuleChanges(ctx context.Context, projectPath string, changes ModuleChanges) (GoMod, error) {
+	if err := ctx.Err(); err != nil {
+		return GoMod{}, fmt.Errorf("apply module changes context: %w", err)
+	}
+	if len(changes.SumLines) > 0 {
+		if err := mergeSumLines(filepath.Join(projectPath, "go.sum"), changes.SumLines); err != nil {
+			return GoMod{}, err
+		}
+	}
+	if len(changes.Requires) == 0 {
+		return ParseGoMod(ctx, projectPath)
+	}
+	return editGoMod(ctx, projectPath, func(file *modfile.File) error {
+		for _, change := range changes.Requires {
+			var err error
+			switch change.Kind {
+			case RequireAdded, RequireChanged:
+				if slices.ContainsFunc(file.Require, func(require *modfile.Require) bool { return require.Mod.Path == change.Path }) {
+					err = file.AddRequire(change.Path, change.To)
+				} else {
+					file.AddNewRequire(change.Path, change.To, change.Indirect)
+				}
+			case RequireRemoved:
+				err = file.DropRequire(change.Path)
+			default:
+				err = fmt.Errorf("unknown requirement change %q", change.Kind)
+			}
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", change.Kind, change.Path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// mergeSumLines adds lines missing from the go.sum at path, creating it if
+// needed, and keeps the file sorted as the go command writes it.
+func mergeSumLines(path string, lines []string) error {
+	original, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read go.sum: %w", err)
+	}
+	merged := sumLines(original)
+	for _, line := range lines {
+		if !slices.Contains(merged, line) {
+			merged = append(merged, line)
+		}
+	}
+	slices.Sort(merged)
+	data := []byte(strings.Join(merged, "\n") + "\n")
+	if original == nil {
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("write go.sum: %w", err)
+		}
+		return nil
+	}
+	return replaceModFile(path, original, data)
+}
+
+// sumLines returns the non-empty lines of go.sum contents.
+func sumLines(data []byte) []string {
+	var lines []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
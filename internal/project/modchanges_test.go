@@ -0,0 +1,69 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDiffAndApplyModuleChanges(t *testing.T) {
+	t.Parallel()
+
+	edited := `module example.com/app
+
+go 1.22
+
+require (
+	example.com/lib v1.3.0
+	example.com/new v0.1.0
+)
+`
+	baseSum := "example.com/lib v1.2.0 h1:old=\n"
+	editedSum := baseSum + "example.com/new v0.1.0 h1:new=\nexample.com/new v0.1.0/go.mod h1:newmod=\n"
+	changes, err := DiffModuleFiles([]byte(testGoMod), []byte(edited), []byte(baseSum), []byte(editedSum))
+	if err != nil {
+		t.Fatalf("DiffModuleFiles() error = %v", err)
+	}
+	want := []RequireChange{
+		{Path: "example.com/lib", Kind: RequireChanged, From: "v1.2.0", To: "v1.3.0"},
+		{Path: "example.com/new", Kind: RequireAdded, To: "v0.1.0"},
+		{Path: "golang.org/x/text", Kind: RequireRemoved, From: "v0.14.0", Indirect: true},
+	}
+	if !slices.Equal(changes.Requires, want) {
+		t.Fatalf("Requires = %+v, want %+v", changes.Requires, want)
+	}
+	if len(changes.SumLines) != 2 {
+		t.Fatalf("SumLines = %q, want the two new lines", changes.SumLines)
+	}
+
+	// The project moved on since the edit: its go version changed and it
+	// gained a go.sum line of its own. Both must survive.
+	projectDir := t.TempDir()
+	writeGoMod(t, projectDir, testGoMod+"\nrequire example.com/other v1.0.0\n")
+	sumPath := filepath.Join(projectDir, "go.sum")
+	if err := os.WriteFile(sumPath, []byte("example.com/other v1.0.0 h1:other=\n"), 0o644); err != nil {
+		t.Fatalf("write go.sum: %v", err)
+	}
+	applied, err := ApplyModuleChanges(context.Background(), projectDir, changes)
+	if err != nil {
+		t.Fatalf("ApplyModuleChanges() error = %v", err)
+	}
+	versions := make(map[string]string)
+	for _, require := range applied.Requires {
+		versions[require.Path] = require.Version
+	}
+	if versions["example.com/lib"] != "v1.3.0" || versions["example.com/new"] != "v0.1.0" ||
+		versions["example.com/other"] != "v1.0.0" || versions["golang.org/x/text"] != "" {
+		t.Fatalf("requires after apply = %v", versions)
+	}
+	sum, err := os.ReadFile(sumPath)
+	if err != nil {
+		t.Fatalf("read go.sum: %v", err)
+	}
+	wantSum := "example.com/new v0.1.0 h1:new=\nexample.com/new v0.1.0/go.mod h1:newmod=\nexample.com/other v1.0.0 h1:other=\n"
+	if string(sum) != wantSum {
+		t.Fatalf("go.sum = %q, want %q", sum, wantSum)
+	}
+}
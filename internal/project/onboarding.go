@@ -0,0 +1,310 @@
+package project
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Onboarding is what a project's files suggest doing first: which package
+// to run, which environment variables to fill in and which tasks exist.
+type Onboarding struct {
+	Targets []SuggestedTarget `json:"targets"`
+	EnvKeys []SuggestedEnvKey `json:"envKeys"`
+	Tasks   []SuggestedTask   `json:"tasks"`
+}
+
+// SuggestedTarget is a run target ranked by how likely it is the project's
+// main entry point, most likely first.
+type SuggestedTarget struct {
+	Package string   `json:"package"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+	Reason  string   `json:"reason"`
+}
+
+// SuggestedEnvKey is an environment variable a template or compose file
+// expects that the project does not set yet.
+type SuggestedEnvKey struct {
+	Key string `json:"key"`
+	// Example is the template's value, often a placeholder.
+	Example string `json:"example,omitempty"`
+	Source  string `json:"source"`
+}
+
+// SuggestedTask is a Makefile target or compose service.
+type SuggestedTask struct {
+	Name        string `json:"name"`
+	Command     string `json:"command"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source"`
+}
+
+// Files the analyzer reads, relative to the project root.
+var (
+	makefileNames    = []string{"GNUmakefile", "makefile", "Makefile"}
+	envTemplateNames = []string{".env.example", ".env.sample", ".env.template", ".env.dist"}
+	composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+)
+
+var (
+	makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9_][A-Za-z0-9_./-]*)\s*:([^=]|$)`)
+	goRunPattern      = regexp.MustCompile(`\bgo\s+run\s+(.*)$`)
+)
+
+// AnalyzeOnboarding inspects the project at root for a first run. targets
+// are its discovered run targets; envKeys are the variables it already sets,
+// which are not suggested again. Files that are missing or unreadable are
+// skipped.
+func AnalyzeOnboarding(ctx context.Context, root string, targets []RunTarget, envKeys []string) (Onboarding, error) {
+	if err := ctx.Err(); err != nil {
+		return Onboarding{}, fmt.Errorf("analyze onboarding context: %w", err)
+	}
+	onboarding := Onboarding{
+		Targets: []SuggestedTarget{},
+		EnvKeys: []SuggestedEnvKey{},
+		Tasks:   []SuggestedTask{},
+	}
+
+	makeTasks, makeRuns := analyzeMakefile(root)
+	onboarding.Tasks = append(onboarding.Tasks, makeTasks...)
+	onboarding.Targets = rankTargets(root, targets, makeRuns)
+
+	known := make(map[string]bool, len(envKeys))
+	for _, key := range envKeys {
+		known[key] = true
+	}
+	for _, name := range envTemplateNames {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		values, _ := parseDotEnv(string(content))
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if known[key] {
+				continue
+			}
+			known[key] = true
+			onboarding.EnvKeys = append(onboarding.EnvKeys, SuggestedEnvKey{Key: key, Example: values[key], Source: name})
+		}
+	}
+
+	for _, name := range composeFileNames {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		services, envs := parseComposeFile(string(content))
+		for _, service := range services {
+			onboarding.Tasks = append(onboarding.Tasks, SuggestedTask{
+				Name:        service,
+				Command:     "docker compose up " + service,
+				Description: "Start the " + service + " service",
+				Source:      name,
+			})
+		}
+		for _, key := range envs {
+			if known[key] {
+				continue
+			}
+			known[key] = true
+			onboarding.EnvKeys = append(onboarding.EnvKeys, SuggestedEnvKey{Key: key, Source: name})
+		}
+		break
+	}
+	return onboarding, nil
+}
+
+// makeRun is a `go run` found in a Makefile recipe.
+type makeRun struct {
+	pkg     string
+	args    []string
+	command string
+}
+
+// analyzeMakefile lists the targets of the project's Makefile and the
+// `go run` invocations in their recipes.
+func analyzeMakefile(root string) ([]SuggestedTask, []makeRun) {
+	for _, name := range makefileNames {
+		file, err := os.Open(filepath.Join(root, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil
+		}
+		defer file.Close()
+
+		tasks := make([]SuggestedTask, 0)
+		runs := make([]makeRun, 0)
+		current := ""
+		comment := ""
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "\t") {
+				if current == "" {
+					continue
+				}
+				if match := goRunPattern.FindStringSubmatch(line); match != nil {
+					fields := strings.Fields(match[1])
+					for len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+						fields = fields[1:]
+					}
+					if len(fields) > 0 {
+						runs = append(runs, makeRun{pkg: fields[0], args: fields[1:], command: "make " + current})
+					}
+				}
+				continue
+			}
+			trimmed := strings.TrimSpace(line)
+			if text, ok := strings.CutPrefix(trimmed, "#"); ok {
+				comment = strings.TrimSpace(strings.TrimLeft(text, "#"))
+				continue
+			}
+			match := makeTargetPattern.FindStringSubmatch(line)
+			if match == nil {
+				current = ""
+				comment = ""
+				continue
+			}
+			current = match[1]
+			description := comment
+			comment = ""
+			if _, help, ok := strings.Cut(line, "##"); ok {
+				description = strings.TrimSpace(help)
+			}
+			if strings.HasPrefix(current, ".") {
+				current = ""
+				continue
+			}
+			tasks = append(tasks, SuggestedTask{Name: current, Command: "make " + current, Description: description, Source: name})
+		}
+		return tasks, runs
+	}
+	return nil, nil
+}
+
+// rankTargets orders targets from most to least likely entry point: those
+// a Makefile runs, the command named after the module, the root package and
+// the rest of cmd/.
+func rankTargets(root string, targets []RunTarget, runs []makeRun) []SuggestedTarget {
+	byPackage := make(map[string]RunTarget, len(targets))
+	for _, target := range targets {
+		byPackage[target.Package] = target
+	}
+	suggested := make([]SuggestedTarget, 0, len(targets))
+	seen := make(map[string]bool, len(targets))
+	add := func(target RunTarget, args []string, reason string) {
+		if seen[target.Package] {
+			return
+		}
+		seen[target.Package] = true
+		suggested = append(suggested, SuggestedTarget{Package: target.Package, Command: target.Command, Args: args, Reason: reason})
+	}
+
+	for _, run := range runs {
+		if target, ok := byPackage[normalizeRunPackage(run.pkg)]; ok {
+			add(target, run.args, "Run by "+run.command)
+		}
+	}
+	for _, name := range moduleNames(root) {
+		if target, ok := byPackage["./cmd/"+name]; ok {
+			add(target, nil, "Command named after the module")
+		}
+	}
+	if target, ok := byPackage["."]; ok {
+		add(target, nil, "Main package at the project root")
+	}
+	for _, target := range targets {
+		if strings.HasPrefix(target.Package, "./cmd/") {
+			add(target, nil, "Command under cmd/")
+		}
+	}
+	for _, target := range targets {
+		add(target, nil, "Main package")
+	}
+	return suggested
+}
+
+// normalizeRunPackage turns a `go run` argument into a run target package.
+func normalizeRunPackage(arg string) string {
+	arg = filepath.ToSlash(arg)
+	if strings.HasSuffix(arg, ".go") {
+		arg = path.Dir(arg)
+	}
+	arg = path.Clean(arg)
+	if arg == "." {
+		return "."
+	}
+	return "./" + arg
+}
+
+// moduleNames are the names a project's main command is likely to have:
+// the last element of its module path and its directory name.
+func moduleNames(root string) []string {
+	names := []string{filepath.Base(root)}
+	if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		if modulePath := modfile.ModulePath(data); modulePath != "" {
+			names = append([]string{path.Base(modulePath)}, names...)
+		}
+	}
+	return slices.Compact(names)
+}
+
+// parseComposeFile returns the service names of a compose file and the
+// environment variables it reads with ${VAR} interpolation. It understands
+// the block YAML compose files are written in, not YAML in general.
+func parseComposeFile(content string) ([]string, []string) {
+	services := make([]string, 0)
+	envs := make([]string, 0)
+	seen := make(map[string]bool)
+	inServices := false
+	serviceIndent := -1
+	for _, line := range strings.Split(content, "\n") {
+		for _, match := range composeVarPattern.FindAllStringSubmatch(line, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				envs = append(envs, match[1])
+			}
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == 0 {
+			inServices = strings.HasPrefix(trimmed, "services:")
+			serviceIndent = -1
+			continue
+		}
+		if !inServices {
+			continue
+		}
+		if serviceIndent == -1 {
+			serviceIndent = indent
+		}
+		if indent == serviceIndent && strings.HasSuffix(trimmed, ":") {
+			services = append(services, strings.Trim(strings.TrimSuffix(trimmed, ":"), `"'`))
+		}
+	}
+	sort.Strings(envs)
+	return services, envs
+}
+
+var composeVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)`)
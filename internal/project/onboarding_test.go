@@ -0,0 +1,68 @@
+package project
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeOnboarding(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	mainSource := "package main\n\nfunc main() {}\n"
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/shop\n")
+	writeFile(t, filepath.Join(root, "main.go"), mainSource)
+	writeFile(t, filepath.Join(root, "cmd", "migrate", "main.go"), mainSource)
+	writeFile(t, filepath.Join(root, "cmd", "seed", "main.go"), mainSource)
+	writeFile(t, filepath.Join(root, "cmd", "shop", "main.go"), mainSource)
+	writeFile(t, filepath.Join(root, "Makefile"), ".PHONY: build migrate\n"+
+		"VERSION := 1.0\n\n"+
+		"# Build every binary\n"+
+		"build:\n\tgo build ./...\n\n"+
+		"migrate: build ## Apply database migrations\n\tgo run ./cmd/migrate -dir migrations\n")
+	writeFile(t, filepath.Join(root, ".env.example"), "DATABASE_URL=postgres://localhost/shop\nPORT=8080\n")
+	writeFile(t, filepath.Join(root, "docker-compose.yml"), "services:\n"+
+		"  db:\n    image: postgres\n    environment:\n      POSTGRES_PASSWORD: ${DB_PASSWORD}\n"+
+		"  cache:\n    image: redis\n"+
+		"volumes:\n  data:\n")
+
+	targets, err := DiscoverRunTargets(context.Background(), root)
+	if err != nil {
+		t.Fatalf("DiscoverRunTargets() error = %v", err)
+	}
+	onboarding, err := AnalyzeOnboarding(context.Background(), root, targets, []string{"PORT"})
+	if err != nil {
+		t.Fatalf("AnalyzeOnboarding() error = %v", err)
+	}
+
+	packages := make([]string, 0, len(onboarding.Targets))
+	for _, target := range onboarding.Targets {
+		packages = append(packages, target.Package)
+	}
+	if want := []string{"./cmd/migrate", "./cmd/shop", ".", "./cmd/seed"}; !reflect.DeepEqual(packages, want) {
+		t.Fatalf("target order = %v, want %v", packages, want)
+	}
+	if got := onboarding.Targets[0]; !reflect.DeepEqual(got.Args, []string{"-dir", "migrations"}) || got.Reason != "Run by make migrate" {
+		t.Fatalf("Targets[0] = %+v", got)
+	}
+
+	wantEnv := []SuggestedEnvKey{
+		{Key: "DATABASE_URL", Example: "postgres://localhost/shop", Source: ".env.example"},
+		{Key: "DB_PASSWORD", Source: "docker-compose.yml"},
+	}
+	if !reflect.DeepEqual(onboarding.EnvKeys, wantEnv) {
+		t.Fatalf("EnvKeys = %+v, want %+v", onboarding.EnvKeys, wantEnv)
+	}
+
+	wantTasks := []SuggestedTask{
+		{Name: "build", Command: "make build", Description: "Build every binary", Source: "Makefile"},
+		{Name: "migrate", Command: "make migrate", Description: "Apply database migrations", Source: "Makefile"},
+		{Name: "db", Command: "docker compose up db", Description: "Start the db service", Source: "docker-compose.yml"},
+		{Name: "cache", Command: "docker compose up cache", Description: "Start the cache service", Source: "docker-compose.yml"},
+	}
+	if !reflect.DeepEqual(onboarding.Tasks, wantTasks) {
+		t.Fatalf("Tasks = %+v, want %+v", onboarding.Tasks, wantTasks)
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
-	"sort"
 
+	"gopoke/internal/fspath"
 	"gopoke/internal/storage"
 )
 
@@ -23,6 +23,11 @@ type OpenProjectResult struct {
 	Targets         []RunTarget
 	EnvVars         []storage.EnvVarRecord
 	EnvLoadWarnings []string
+	// Filesystem describes the volume holding the project.
+	Filesystem fspath.Volume
+	// Onboarding suggests a first run; it is set only the first time a
+	// project is opened.
+	Onboarding *Onboarding
 }
 
 // NewService constructs a project service.
@@ -43,6 +48,7 @@ func (s *Service) Open(ctx context.Context, path string) (OpenProjectResult, err
 	if err != nil {
 		return OpenProjectResult{}, fmt.Errorf("resolve project path: %w", err)
 	}
+	absolutePath = fspath.Canonical(absolutePath)
 	info, err := os.Stat(absolutePath)
 	if err != nil {
 		return OpenProjectResult{}, fmt.Errorf("inspect project path: %w", err)
@@ -77,33 +83,27 @@ func (s *Service) Open(ctx context.Context, path string) (OpenProjectResult, err
 		return OpenProjectResult{}, fmt.Errorf("persist recent project: %w", err)
 	}
 
-	envFromFile, envWarnings, err := loadDotEnvFile(absolutePath)
-	if err != nil {
-		return OpenProjectResult{}, fmt.Errorf("load .env: %w", err)
-	}
-	if len(envFromFile) > 0 {
-		currentEnv, envErr := s.store.ProjectEnvVars(ctx, record.ID)
-		if envErr != nil {
-			return OpenProjectResult{}, fmt.Errorf("load current env vars: %w", envErr)
+	var envWarnings []string
+	if record.Trusted() {
+		envFromFile, warnings, err := loadDotEnvFile(absolutePath)
+		if err != nil {
+			return OpenProjectResult{}, fmt.Errorf("load .env: %w", err)
 		}
-		existingByKey := make(map[string]storage.EnvVarRecord, len(currentEnv))
-		for _, variable := range currentEnv {
-			existingByKey[variable.Key] = variable
+		envWarnings = warnings
+		if err := s.store.SetProjectDotEnvVars(ctx, record.ID, envFromFile); err != nil {
+			return OpenProjectResult{}, fmt.Errorf("persist .env variables: %w", err)
 		}
-
-		keys := make([]string, 0, len(envFromFile))
-		for key := range envFromFile {
-			keys = append(keys, key)
+	} else {
+		// A restricted project keeps none of the variables it loaded from
+		// .env while it was trusted. The file is parsed only to recognise
+		// variables stored before they were marked as coming from it; an
+		// unreadable file leaves just the marked ones to drop.
+		legacy, _, _ := loadDotEnvFile(absolutePath)
+		if _, err := s.store.DropProjectDotEnvVars(ctx, record.ID, legacy); err != nil {
+			return OpenProjectResult{}, fmt.Errorf("drop .env variables: %w", err)
 		}
-		sort.Strings(keys)
-		for _, key := range keys {
-			masked := false
-			if existing, exists := existingByKey[key]; exists {
-				masked = existing.Masked
-			}
-			if _, envErr := s.store.UpdateProjectEnvVar(ctx, record.ID, key, envFromFile[key], masked); envErr != nil {
-				return OpenProjectResult{}, fmt.Errorf("persist .env variable %q: %w", key, envErr)
-			}
+		if _, statErr := os.Stat(filepath.Join(absolutePath, ".env")); statErr == nil {
+			envWarnings = append(envWarnings, ".env not loaded: trust the project to load it")
 		}
 	}
 
@@ -111,12 +111,26 @@ func (s *Service) Open(ctx context.Context, path string) (OpenProjectResult, err
 	if err != nil {
 		return OpenProjectResult{}, fmt.Errorf("load project env vars: %w", err)
 	}
+	var onboarding *Onboarding
+	if !found {
+		keys := make([]string, 0, len(envVars))
+		for _, variable := range envVars {
+			keys = append(keys, variable.Key)
+		}
+		analyzed, err := AnalyzeOnboarding(ctx, absolutePath, targets, keys)
+		if err != nil {
+			return OpenProjectResult{}, fmt.Errorf("analyze project: %w", err)
+		}
+		onboarding = &analyzed
+	}
 	return OpenProjectResult{
 		Project:         record,
 		Module:          moduleInfo,
 		Targets:         targets,
 		EnvVars:         envVars,
 		EnvLoadWarnings: envWarnings,
+		Filesystem:      fspath.Inspect(absolutePath),
+		Onboarding:      onboarding,
 	}, nil
 }
 
@@ -129,6 +143,32 @@ func (s *Service) Recent(ctx context.Context, limit int) ([]storage.ProjectRecor
 	return records, nil
 }
 
+// RecentPage returns one page of recently opened projects.
+func (s *Service) RecentPage(ctx context.Context, page storage.PageRequest) (storage.ProjectPage, error) {
+	records, err := s.store.RecentProjectsPage(ctx, page)
+	if err != nil {
+		return storage.ProjectPage{}, fmt.Errorf("load recent projects: %w", err)
+	}
+	return records, nil
+}
+
+// SetTrust records whether the user trusts a project. Restricted and
+// undecided projects do not load .env files or run workers, and drop the
+// .env variables they loaded while trusted the next time they are opened.
+func (s *Service) SetTrust(ctx context.Context, projectPath string, trust string) (storage.ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("set project trust context: %w", err)
+	}
+	if projectPath == "" {
+		return storage.ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+	record, err := s.store.UpdateProjectTrust(ctx, projectPath, trust)
+	if err != nil {
+		return storage.ProjectRecord{}, fmt.Errorf("persist project trust: %w", err)
+	}
+	return record, nil
+}
+
 // SetDefaultPackage stores a project's default run target package.
 func (s *Service) SetDefaultPackage(ctx context.Context, projectPath string, packagePath string) (storage.ProjectRecord, error) {
 	if err := ctx.Err(); err != nil {
@@ -155,6 +155,20 @@ func TestServiceOpenLoadsDotEnvAndReportsInvalidLines(t *testing.T) {
 		"DB_PORT=5432",
 	}, "\n"))
 
+	untrusted, err := service.Open(context.Background(), projectDir)
+	if err != nil {
+		t.Fatalf("Open(untrusted) error = %v", err)
+	}
+	if got := len(untrusted.EnvVars); got != 0 {
+		t.Fatalf("len(untrusted.EnvVars) = %d, want 0 before trust", got)
+	}
+	if got := len(untrusted.EnvLoadWarnings); got != 1 {
+		t.Fatalf("untrusted.EnvLoadWarnings = %v, want trust warning", untrusted.EnvLoadWarnings)
+	}
+	if _, err := service.SetTrust(context.Background(), projectDir, storage.TrustTrusted); err != nil {
+		t.Fatalf("SetTrust() error = %v", err)
+	}
+
 	result, err := service.Open(context.Background(), projectDir)
 	if err != nil {
 		t.Fatalf("Open() error = %v", err)
@@ -183,6 +197,12 @@ func TestServiceOpenPreservesMaskedValueForDotEnvOverrides(t *testing.T) {
 	writeFile(t, filepath.Join(projectDir, "go.mod"), "module example.com/test\n")
 	writeFile(t, filepath.Join(projectDir, "main.go"), "package main\n\nfunc main() {}\n")
 	writeFile(t, filepath.Join(projectDir, ".env"), "SECRET=from-file\n")
+	if _, err := store.RecordProjectOpen(context.Background(), projectDir, ""); err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	if _, err := service.SetTrust(context.Background(), projectDir, storage.TrustTrusted); err != nil {
+		t.Fatalf("SetTrust() error = %v", err)
+	}
 
 	firstOpen, err := service.Open(context.Background(), projectDir)
 	if err != nil {
@@ -207,6 +227,79 @@ func TestServiceOpenPreservesMaskedValueForDotEnvOverrides(t *testing.T) {
 	}
 }
 
+func TestServiceOpenDropsDotEnvVarsWhenRestricted(t *testing.T) {
+	t.Parallel()
+
+	store := storage.New(t.TempDir())
+	if err := store.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	service := NewService(store)
+
+	projectDir := t.TempDir()
+	writeFile(t, filepath.Join(projectDir, "go.mod"), "module example.com/test\n")
+	writeFile(t, filepath.Join(projectDir, "main.go"), "package main\n\nfunc main() {}\n")
+	writeFile(t, filepath.Join(projectDir, ".env"), "API_TOKEN=abc\nLEGACY=old\nEDITED=file\n")
+	project, err := store.RecordProjectOpen(context.Background(), projectDir, "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	if _, err := service.SetTrust(context.Background(), projectDir, storage.TrustTrusted); err != nil {
+		t.Fatalf("SetTrust(trusted) error = %v", err)
+	}
+	if _, err := service.Open(context.Background(), projectDir); err != nil {
+		t.Fatalf("Open(trusted) error = %v", err)
+	}
+	// LEGACY stands in for a variable loaded before .env variables were
+	// marked; EDITED was changed by the user after loading.
+	if _, err := store.UpdateProjectEnvVar(context.Background(), project.ID, "LEGACY", "old", false); err != nil {
+		t.Fatalf("UpdateProjectEnvVar(LEGACY) error = %v", err)
+	}
+	if _, err := store.UpdateProjectEnvVar(context.Background(), project.ID, "EDITED", "manual", false); err != nil {
+		t.Fatalf("UpdateProjectEnvVar(EDITED) error = %v", err)
+	}
+
+	if _, err := service.SetTrust(context.Background(), projectDir, storage.TrustRestricted); err != nil {
+		t.Fatalf("SetTrust(restricted) error = %v", err)
+	}
+	restricted, err := service.Open(context.Background(), projectDir)
+	if err != nil {
+		t.Fatalf("Open(restricted) error = %v", err)
+	}
+	if got := len(restricted.EnvVars); got != 1 || restricted.EnvVars[0].Key != "EDITED" {
+		t.Fatalf("restricted.EnvVars = %+v, want only the user-edited EDITED", restricted.EnvVars)
+	}
+	if got := len(restricted.EnvLoadWarnings); got != 1 {
+		t.Fatalf("restricted.EnvLoadWarnings = %v, want trust warning", restricted.EnvLoadWarnings)
+	}
+}
+
+func TestServiceOpenAnalyzesOnlyFirstOpen(t *testing.T) {
+	t.Parallel()
+
+	store := storage.New(t.TempDir())
+	if err := store.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	service := NewService(store)
+	root := t.TempDir()
+	writeProjectFiles(t, root, true)
+	first, err := service.Open(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if first.Onboarding == nil || len(first.Onboarding.Targets) != 1 {
+		t.Fatalf("first Open() onboarding = %+v, want one suggested target", first.Onboarding)
+	}
+	second, err := service.Open(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Open() again error = %v", err)
+	}
+	if second.Onboarding != nil {
+		t.Fatalf("second Open() onboarding = %+v, want nil", second.Onboarding)
+	}
+}
+
 func writeProjectFiles(t *testing.T, root string, withModule bool) {
 	t.Helper()
 	if withModule {
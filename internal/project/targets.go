@@ -27,6 +27,11 @@ type RunTarget struct {
 	Package string
 	Command string
 	Path    string
+	// Files lists, project-relative, the package's buildable files other
+	// than the one declaring main. A snippet that calls the package's
+	// helpers runs with them (see execution.RunRequest.Files); empty for
+	// single-file mains.
+	Files []string
 }
 
 // DiscoverRunTargets scans a project tree and returns runnable main packages.
@@ -73,7 +78,7 @@ func DiscoverRunTargets(ctx context.Context, root string) ([]RunTarget, error) {
 		if err := ctx.Err(); err != nil {
 			return nil, fmt.Errorf("discover targets context: %w", err)
 		}
-		runnable, err := isRunnableMainPackage(directory)
+		runnable, supportFiles, err := inspectMainPackage(directory)
 		if err != nil {
 			return nil, fmt.Errorf("inspect package %s: %w", directory, err)
 		}
@@ -93,10 +98,20 @@ func DiscoverRunTargets(ctx context.Context, root string) ([]RunTarget, error) {
 			command = "go run " + packagePath
 		}
 
+		var files []string
+		for _, file := range supportFiles {
+			relativeFile, err := filepath.Rel(absoluteRoot, file)
+			if err != nil {
+				return nil, fmt.Errorf("resolve relative path: %w", err)
+			}
+			files = append(files, filepath.ToSlash(relativeFile))
+		}
+
 		targets = append(targets, RunTarget{
 			Package: packagePath,
 			Command: command,
 			Path:    directory,
+			Files:   files,
 		})
 	}
 
@@ -113,10 +128,12 @@ func DiscoverRunTargets(ctx context.Context, root string) ([]RunTarget, error) {
 	return targets, nil
 }
 
-func isRunnableMainPackage(directory string) (bool, error) {
+// inspectMainPackage reports whether directory holds a main package with
+// func main, and the package's buildable files that do not declare main.
+func inspectMainPackage(directory string) (bool, []string, error) {
 	entries, err := os.ReadDir(directory)
 	if err != nil {
-		return false, fmt.Errorf("read directory: %w", err)
+		return false, nil, fmt.Errorf("read directory: %w", err)
 	}
 
 	goFiles := make([]string, 0)
@@ -134,35 +151,54 @@ func isRunnableMainPackage(directory string) (bool, error) {
 		goFiles = append(goFiles, filepath.Join(directory, name))
 	}
 	if len(goFiles) == 0 {
-		return false, nil
+		return false, nil, nil
 	}
 
 	fileSet := token.NewFileSet()
 	packageName := ""
 	hasMainFunc := false
+	var supportFiles []string
 
 	for _, filePath := range goFiles {
 		fileNode, err := parser.ParseFile(fileSet, filePath, nil, 0)
 		if err != nil {
-			return false, fmt.Errorf("parse go file: %w", err)
+			return false, nil, fmt.Errorf("parse go file: %w", err)
+		}
+		if !declaresMain(fileNode) {
+			supportFiles = append(supportFiles, filePath)
+		} else {
+			hasMainFunc = true
 		}
 
 		if packageName == "" {
 			packageName = fileNode.Name.Name
 		}
 
-		for _, declaration := range fileNode.Decls {
-			funcDecl, ok := declaration.(*ast.FuncDecl)
-			if !ok {
-				continue
-			}
-			if funcDecl.Name.Name == "main" && funcDecl.Recv == nil {
-				hasMainFunc = true
-			}
+	}
+
+	if packageName != "main" || !hasMainFunc {
+		return false, nil, nil
+	}
+	return true, supportFiles, nil
+}
+
+// declaresMain reports whether file declares func main.
+func declaresMain(file *ast.File) bool {
+	for _, declaration := range file.Decls {
+		if funcDecl, ok := declaration.(*ast.FuncDecl); ok && funcDecl.Name.Name == "main" && funcDecl.Recv == nil {
+			return true
 		}
 	}
+	return false
+}
 
-	return packageName == "main" && hasMainFunc, nil
+// DeclaresMain reports whether the Go file at path declares func main.
+func DeclaresMain(path string) (bool, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return false, fmt.Errorf("parse go file: %w", err)
+	}
+	return declaresMain(file), nil
 }
 
 func matchesBuildConstraints(filePath string) bool {
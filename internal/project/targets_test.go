@@ -35,6 +35,12 @@ func TestDiscoverRunTargets(t *testing.T) {
 	if got, want := targets[1].Package, "./cmd/api"; got != want {
 		t.Fatalf("targets[1].Package = %q, want %q", got, want)
 	}
+	if got := targets[0].Files; len(got) != 1 || got[0] != "helper.go" {
+		t.Fatalf("targets[0].Files = %v, want [helper.go]", got)
+	}
+	if got := targets[1].Files; len(got) != 0 {
+		t.Fatalf("targets[1].Files = %v, want none for a single-file main", got)
+	}
 }
 
 func TestDiscoverRunTargetsSkipsHiddenAndVendor(t *testing.T) {
@@ -0,0 +1,71 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Test cache modes for a project's test runs.
+const (
+	// TestCacheForce reruns every test with -count=1, so go test never
+	// reports a "(cached)" result. It is the default.
+	TestCacheForce = "force"
+	// TestCacheUse lets go test replay results for unchanged packages.
+	TestCacheUse = "use"
+)
+
+// cachedTestLine matches the summary go test prints for a package whose
+// result came from the test cache.
+var cachedTestLine = regexp.MustCompile(`(?m)^ok\s+\S+\s+\(cached\)`)
+
+// NormalizeTestCacheMode returns the canonical test cache mode. Empty means
+// TestCacheForce.
+func NormalizeTestCacheMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "":
+		return TestCacheForce, nil
+	case TestCacheForce, TestCacheUse:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported test cache mode %q", mode)
+	}
+}
+
+// TestCacheFlags returns the go test flags that apply mode. Unknown modes
+// force a rerun.
+func TestCacheFlags(mode string) []string {
+	if normalized, err := NormalizeTestCacheMode(mode); err == nil && normalized == TestCacheUse {
+		return nil
+	}
+	return []string{"-count=1"}
+}
+
+// TestCacheHit reports whether go test output includes a package result
+// replayed from the test cache.
+func TestCacheHit(output string) bool {
+	return cachedTestLine.MatchString(output)
+}
+
+// ClearTestCache expires cached test results with go clean -testcache in
+// dir and returns its combined output. The go command keeps one test cache
+// per GOCACHE, so projects sharing a build cache are cleared together.
+func ClearTestCache(ctx context.Context, toolchain string, dir string, environment map[string]string) (string, error) {
+	if strings.TrimSpace(toolchain) == "" {
+		toolchain = "go"
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("inspect module dir: %w", err)
+	}
+	command := exec.CommandContext(ctx, toolchain, "clean", "-testcache")
+	command.Dir = dir
+	command.Env = commandEnvironment(environment)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("go clean -testcache: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,54 @@
+package project
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestTestCacheModes(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]string{"": TestCacheForce, " USE ": TestCacheUse, "force": TestCacheForce} {
+		got, err := NormalizeTestCacheMode(input)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeTestCacheMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := NormalizeTestCacheMode("sometimes"); err == nil {
+		t.Fatal("NormalizeTestCacheMode(sometimes) error = nil")
+	}
+	if got := TestCacheFlags(""); !reflect.DeepEqual(got, []string{"-count=1"}) {
+		t.Fatalf("TestCacheFlags(default) = %v", got)
+	}
+	if got := TestCacheFlags(TestCacheUse); got != nil {
+		t.Fatalf("TestCacheFlags(use) = %v, want none", got)
+	}
+}
+
+func TestTestCacheHit(t *testing.T) {
+	t.Parallel()
+
+	if !TestCacheHit("=== RUN   TestA\nok  \texample.com/demo\t(cached)\n") {
+		t.Fatal("TestCacheHit(cached) = false")
+	}
+	if TestCacheHit("ok  \texample.com/demo\t0.012s\n") {
+		t.Fatal("TestCacheHit(fresh) = true")
+	}
+	if TestCacheHit("    t.Log: ok x (cached)\n") {
+		t.Fatal("TestCacheHit(test output mentioning cached) = true")
+	}
+}
+
+func TestClearTestCache(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	environment := map[string]string{"GOCACHE": t.TempDir(), "GOFLAGS": ""}
+	if _, err := ClearTestCache(context.Background(), "", t.TempDir(), environment); err != nil {
+		t.Fatalf("ClearTestCache() error = %v", err)
+	}
+}
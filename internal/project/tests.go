@@ -0,0 +1,95 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Kinds of test functions.
+const (
+	TestKindTest      = "test"
+	TestKindBenchmark = "benchmark"
+	TestKindFuzz      = "fuzz"
+	TestKindExample   = "example"
+)
+
+// TestFunc is one test function go test can run.
+type TestFunc struct {
+	// Package is the import path of the package the function is in.
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+}
+
+// listedTestName matches a name go test -list prints; the go command has
+// already applied its naming rules.
+var listedTestName = regexp.MustCompile(`^(Test|Benchmark|Fuzz|Example)\w*$`)
+
+// listedPackageLine matches the result line go test -list prints after the
+// names of each package.
+var listedPackageLine = regexp.MustCompile(`^(?:ok|\?)\s+(\S+)`)
+
+// ListTests lists the tests, benchmarks, fuzz targets and examples of the
+// packages matching packagePath, in dir, with go test -list. A package
+// that fails to build fails the listing with the go command's output.
+func ListTests(ctx context.Context, toolchain string, dir string, packagePath string, environment map[string]string) ([]TestFunc, error) {
+	if strings.TrimSpace(toolchain) == "" {
+		toolchain = "go"
+	}
+	packagePath = strings.TrimSpace(packagePath)
+	if packagePath == "" {
+		packagePath = "."
+	}
+	if strings.HasPrefix(packagePath, "-") {
+		return nil, fmt.Errorf("invalid test package %q", packagePath)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("inspect module dir: %w", err)
+	}
+	command := exec.CommandContext(ctx, toolchain, "test", "-list", ".", packagePath)
+	command.Dir = dir
+	command.Env = commandEnvironment(environment)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("go test -list: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return ParseTestList(string(output)), nil
+}
+
+// ParseTestList parses go test -list output. Names are printed before the
+// result line of their package, which names it.
+func ParseTestList(output string) []TestFunc {
+	tests := make([]TestFunc, 0)
+	pending := 0
+	for line := range strings.Lines(output) {
+		line = strings.TrimSpace(line)
+		if listedTestName.MatchString(line) {
+			tests = append(tests, TestFunc{Name: line, Kind: testKind(line)})
+			continue
+		}
+		if match := listedPackageLine.FindStringSubmatch(line); match != nil {
+			for index := pending; index < len(tests); index++ {
+				tests[index].Package = match[1]
+			}
+			pending = len(tests)
+		}
+	}
+	return tests
+}
+
+func testKind(name string) string {
+	switch {
+	case strings.HasPrefix(name, "Benchmark"):
+		return TestKindBenchmark
+	case strings.HasPrefix(name, "Fuzz"):
+		return TestKindFuzz
+	case strings.HasPrefix(name, "Example"):
+		return TestKindExample
+	default:
+		return TestKindTest
+	}
+}
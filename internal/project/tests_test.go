@@ -0,0 +1,62 @@
+package project
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTestList(t *testing.T) {
+	t.Parallel()
+
+	output := "TestAdd\nBenchmarkAdd\nExampleAdd\nok  \texample.com/calc\t0.003s\n" +
+		"?   \texample.com/calc/cmd\t[no test files]\n" +
+		"FuzzParse\nok  \texample.com/calc/parse\t0.002s\n"
+	want := []TestFunc{
+		{Package: "example.com/calc", Name: "TestAdd", Kind: TestKindTest},
+		{Package: "example.com/calc", Name: "BenchmarkAdd", Kind: TestKindBenchmark},
+		{Package: "example.com/calc", Name: "ExampleAdd", Kind: TestKindExample},
+		{Package: "example.com/calc/parse", Name: "FuzzParse", Kind: TestKindFuzz},
+	}
+	if got := ParseTestList(output); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseTestList() = %+v, want %+v", got, want)
+	}
+}
+
+func TestListTests(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":       "module example.com/calc\n\ngo 1.25\n",
+		"calc.go":      "package calc\n\nfunc Add(a, b int) int { return a + b }\n",
+		"calc_test.go": "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {}\n\nfunc BenchmarkAdd(b *testing.B) {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	tests, err := ListTests(context.Background(), "", dir, "", map[string]string{"GOFLAGS": "", "GOWORK": "off"})
+	if err != nil {
+		t.Fatalf("ListTests() error = %v", err)
+	}
+	want := []TestFunc{
+		{Package: "example.com/calc", Name: "TestAdd", Kind: TestKindTest},
+		{Package: "example.com/calc", Name: "BenchmarkAdd", Kind: TestKindBenchmark},
+	}
+	if !reflect.DeepEqual(tests, want) {
+		t.Fatalf("ListTests() = %+v, want %+v", tests, want)
+	}
+
+	if _, err := ListTests(context.Background(), "", dir, "-exec=sh", nil); err == nil {
+		t.Fatal("ListTests(flag as package) error = nil")
+	}
+}
@@ -11,17 +11,25 @@ import (
 	"strings"
 )
 
-var goToolchainPattern = regexp.MustCompile(`^go(?:\d+(?:\.\d+)*)?$`)
+// ToolchainTip selects the development toolchain installed as gotip.
+const ToolchainTip = "tip"
 
-// ToolchainInfo describes one available Go toolchain on PATH.
+const gotipBinary = "gotip"
+
+var goToolchainPattern = regexp.MustCompile(`^go(?:\d+(?:\.\d+)*|tip)?$`)
+
+// ToolchainInfo describes one available Go toolchain on PATH. DevVersion is
+// set for unreleased builds such as tip, whose version reads "devel ...".
 type ToolchainInfo struct {
-	Name    string `json:"name"`
-	Path    string `json:"path"`
-	Version string `json:"version"`
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Version    string `json:"version"`
+	DevVersion bool   `json:"devVersion"`
 }
 
-// DiscoverToolchains enumerates Go toolchain binaries available on PATH.
-func DiscoverToolchains(ctx context.Context) ([]ToolchainInfo, error) {
+// DiscoverToolchains enumerates Go toolchain binaries available on PATH and
+// in any extra directories, such as the managed tool bin directory.
+func DiscoverToolchains(ctx context.Context, extraDirs ...string) ([]ToolchainInfo, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("discover toolchains context: %w", err)
 	}
@@ -29,7 +37,8 @@ func DiscoverToolchains(ctx context.Context) ([]ToolchainInfo, error) {
 	candidateNames := map[string]struct{}{
 		"go": {},
 	}
-	for _, directory := range filepath.SplitList(os.Getenv("PATH")) {
+	binaryPaths := make(map[string]string)
+	for _, directory := range append(filepath.SplitList(os.Getenv("PATH")), extraDirs...) {
 		if err := ctx.Err(); err != nil {
 			return nil, fmt.Errorf("discover toolchains context: %w", err)
 		}
@@ -49,6 +58,9 @@ func DiscoverToolchains(ctx context.Context) ([]ToolchainInfo, error) {
 				continue
 			}
 			candidateNames[name] = struct{}{}
+			if _, exists := binaryPaths[name]; !exists && slices.Contains(extraDirs, directory) {
+				binaryPaths[name] = filepath.Join(directory, name)
+			}
 		}
 	}
 
@@ -69,16 +81,26 @@ func DiscoverToolchains(ctx context.Context) ([]ToolchainInfo, error) {
 		}
 		resolvedPath, err := ResolveToolchainBinary(name)
 		if err != nil {
-			continue
+			fallback, ok := binaryPaths[name]
+			if !ok {
+				continue
+			}
+			resolvedPath = fallback
 		}
 		if _, exists := seenPaths[resolvedPath]; exists {
 			continue
 		}
 		seenPaths[resolvedPath] = struct{}{}
+		version := ToolchainVersion(ctx, resolvedPath)
+		displayName := name
+		if name == gotipBinary {
+			displayName = ToolchainTip
+		}
 		toolchains = append(toolchains, ToolchainInfo{
-			Name:    name,
-			Path:    resolvedPath,
-			Version: toolchainVersion(ctx, resolvedPath),
+			Name:       displayName,
+			Path:       resolvedPath,
+			Version:    version,
+			DevVersion: name == gotipBinary || IsDevVersion(version),
 		})
 	}
 
@@ -88,12 +110,16 @@ func DiscoverToolchains(ctx context.Context) ([]ToolchainInfo, error) {
 	return toolchains, nil
 }
 
-// ResolveToolchainBinary resolves a toolchain name/path to an executable binary path.
+// ResolveToolchainBinary resolves a toolchain name/path to an executable
+// binary path. "tip" resolves to gotip.
 func ResolveToolchainBinary(value string) (string, error) {
 	candidate := strings.TrimSpace(value)
 	if candidate == "" {
 		return "", fmt.Errorf("toolchain is required")
 	}
+	if candidate == ToolchainTip {
+		candidate = gotipBinary
+	}
 	if filepath.IsAbs(candidate) {
 		info, err := os.Stat(candidate)
 		if err != nil {
@@ -114,7 +140,9 @@ func ResolveToolchainBinary(value string) (string, error) {
 	return resolvedPath, nil
 }
 
-func toolchainVersion(ctx context.Context, binaryPath string) string {
+// ToolchainVersion returns the go version output of a toolchain binary, or
+// "unknown" when it cannot be read.
+func ToolchainVersion(ctx context.Context, binaryPath string) string {
 	output, err := exec.CommandContext(ctx, binaryPath, "version").CombinedOutput()
 	if err != nil {
 		return "unknown"
@@ -126,6 +154,25 @@ func toolchainVersion(ctx context.Context, binaryPath string) string {
 	return text
 }
 
+// IsDevVersion reports whether go version output describes a development
+// build rather than a release.
+func IsDevVersion(version string) bool {
+	return strings.Contains(version, "devel")
+}
+
 func isExecutable(mode os.FileMode) bool {
 	return mode&0o111 != 0
 }
+
+// ToolchainGOROOT returns the GOROOT of a toolchain binary.
+func ToolchainGOROOT(ctx context.Context, binaryPath string) (string, error) {
+	output, err := exec.CommandContext(ctx, binaryPath, "env", "GOROOT").Output()
+	if err != nil {
+		return "", fmt.Errorf("read toolchain GOROOT: %w", err)
+	}
+	goroot := strings.TrimSpace(string(output))
+	if goroot == "" {
+		return "", fmt.Errorf("toolchain %s reports no GOROOT", binaryPath)
+	}
+	return goroot, nil
+}
@@ -2,7 +2,10 @@ package project
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -48,3 +51,43 @@ func TestResolveToolchainBinary(t *testing.T) {
 		t.Fatal("ResolveToolchainBinary(invalid) error = nil, want non-nil")
 	}
 }
+
+func TestDiscoverToolchainsLabelsTipAsDevVersion(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gotip script requires a POSIX shell")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+
+	binDir := t.TempDir()
+	script := "#!/bin/sh\necho 'go version devel go1.26-abcdef linux/amd64'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "gotip"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake gotip: %v", err)
+	}
+
+	toolchains, err := DiscoverToolchains(context.Background(), binDir)
+	if err != nil {
+		t.Fatalf("DiscoverToolchains() error = %v", err)
+	}
+	var tip *ToolchainInfo
+	for index := range toolchains {
+		if toolchains[index].Name == ToolchainTip {
+			tip = &toolchains[index]
+		}
+	}
+	if tip == nil {
+		t.Fatalf("toolchains = %+v, want a %q entry", toolchains, ToolchainTip)
+	}
+	if !tip.DevVersion {
+		t.Fatal("tip.DevVersion = false, want true")
+	}
+	if !strings.Contains(tip.Version, "devel") {
+		t.Fatalf("tip.Version = %q, want devel version", tip.Version)
+	}
+	if toolchains[0].Name != "go" || IsDevVersion("go version go1.22.0 linux/amd64") {
+		t.Fatalf("release toolchain labeled incorrectly: %+v", toolchains[0])
+	}
+}
@@ -0,0 +1,23 @@
+package richoutput
+
+import (
+	"encoding/json"
+
+	"gopoke/internal/textenc"
+)
+
+// maxHexdumpBytes bounds how much binary output a hexdump block shows.
+const maxHexdumpBytes = 4096
+
+type hexdumpData struct {
+	Bytes     int    `json:"bytes"`
+	Dump      string `json:"dump"`
+	Truncated bool   `json:"truncated"`
+}
+
+// Hexdump returns a block showing binary output as a hexdump.
+func Hexdump(data []byte) RichBlock {
+	dump, truncated := textenc.Hexdump(data, maxHexdumpBytes)
+	payload, _ := json.Marshal(hexdumpData{Bytes: len(data), Dump: dump, Truncated: truncated})
+	return RichBlock{Type: TypeHexdump, Data: payload}
+}
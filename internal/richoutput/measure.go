@@ -0,0 +1,54 @@
+package richoutput
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Measurement is one block measured by the gopoke.Measure helper.
+type Measurement struct {
+	// Label is the file and line of the Measure call.
+	Label  string `json:"label"`
+	Allocs uint64 `json:"allocs"`
+	Bytes  uint64 `json:"bytes"`
+	WallNS int64  `json:"wallNs"`
+	// BytesPerAlloc and Wall are derived for display.
+	BytesPerAlloc uint64 `json:"bytesPerAlloc"`
+	Wall          string `json:"wall"`
+}
+
+// ParseMeasure reads the JSON payload of a //gopoke:measure marker and
+// fills in the derived fields.
+func ParseMeasure(payload []byte) (Measurement, bool) {
+	var measurement Measurement
+	if err := json.Unmarshal(payload, &measurement); err != nil || measurement.WallNS < 0 {
+		return Measurement{}, false
+	}
+	measurement.BytesPerAlloc = 0
+	if measurement.Allocs > 0 {
+		measurement.BytesPerAlloc = measurement.Bytes / measurement.Allocs
+	}
+	measurement.Wall = time.Duration(measurement.WallNS).String()
+	return measurement, true
+}
+
+// MeasureHelper is Go source a snippet can paste in outside scratch mode,
+// where it cannot import the gopoke helper, to measure a block.
+const MeasureHelper = `// measure runs fn once and reports its allocations, bytes and wall time;
+// gopoke shows them as a measurement card.
+func measure(label string, fn func()) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	fn()
+	wall := time.Since(start)
+	runtime.ReadMemStats(&after)
+	fmt.Printf("//gopoke:measure {\"label\":%q,\"allocs\":%d,\"bytes\":%d,\"wallNs\":%d}\n",
+		label, after.Mallocs-before.Mallocs, after.TotalAlloc-before.TotalAlloc, wall.Nanoseconds())
+}
+`
+
+func measureBlock(measurement Measurement) RichBlock {
+	payload, _ := json.Marshal(measurement)
+	return RichBlock{Type: TypeMeasure, Data: payload}
+}
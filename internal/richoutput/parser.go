@@ -7,7 +7,9 @@ import (
 
 const markerPrefix = "//gopoke:"
 
-// Parse scans stdout line-by-line for //gopoke:<type> <json> markers.
+// Parse scans stdout line-by-line for //gopoke:<type> <json> markers and
+// //gopoke:progress lines, which are not JSON; each progress bar becomes one
+// block with its last state, and measure blocks gain derived display fields.
 // It returns clean stdout (markers stripped) and extracted rich blocks.
 // Malformed markers (bad JSON or missing payload) are kept in clean output.
 func Parse(stdout string) (cleanStdout string, blocks []RichBlock) {
@@ -17,6 +19,7 @@ func Parse(stdout string) (cleanStdout string, blocks []RichBlock) {
 
 	lines := strings.Split(stdout, "\n")
 	clean := make([]string, 0, len(lines))
+	progressBlocks := make(map[string]int)
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
@@ -25,6 +28,17 @@ func Parse(stdout string) (cleanStdout string, blocks []RichBlock) {
 			continue
 		}
 
+		if progress, ok := ParseProgress(trimmed); ok {
+			// Updates replace the bar where it first appeared.
+			if index, seen := progressBlocks[progress.Label]; seen {
+				blocks[index] = progressBlock(progress)
+			} else {
+				progressBlocks[progress.Label] = len(blocks)
+				blocks = append(blocks, progressBlock(progress))
+			}
+			continue
+		}
+
 		rest := trimmed[len(markerPrefix):]
 		spaceIdx := strings.IndexByte(rest, ' ')
 		if spaceIdx < 1 {
@@ -45,6 +59,16 @@ func Parse(stdout string) (cleanStdout string, blocks []RichBlock) {
 			continue
 		}
 
+		if blockType == TypeMeasure {
+			measurement, ok := ParseMeasure([]byte(payload))
+			if !ok {
+				clean = append(clean, line)
+				continue
+			}
+			blocks = append(blocks, measureBlock(measurement))
+			continue
+		}
+
 		blocks = append(blocks, RichBlock{
 			Type: blockType,
 			Data: json.RawMessage(payload),
@@ -112,3 +112,18 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMeasureBlock(t *testing.T) {
+	clean, blocks := Parse("before\n//gopoke:measure {\"label\":\"main.go:9\",\"allocs\":4,\"bytes\":4096,\"wallNs\":1500000}\n//gopoke:measure {\"wallNs\":-1}\nafter")
+	if clean != "before\n//gopoke:measure {\"wallNs\":-1}\nafter" || len(blocks) != 1 || blocks[0].Type != TypeMeasure {
+		t.Fatalf("Parse() = %q, %+v", clean, blocks)
+	}
+	var measurement Measurement
+	if err := json.Unmarshal(blocks[0].Data, &measurement); err != nil {
+		t.Fatalf("decode measure block: %v", err)
+	}
+	want := Measurement{Label: "main.go:9", Allocs: 4, Bytes: 4096, WallNS: 1500000, BytesPerAlloc: 1024, Wall: "1.5ms"}
+	if measurement != want {
+		t.Fatalf("measurement = %+v, want %+v", measurement, want)
+	}
+}
@@ -0,0 +1,112 @@
+package richoutput
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+const progressMarker = markerPrefix + TypeProgress + " "
+
+// maxProgressLine bounds the pending partial line of a progress stream;
+// longer lines cannot be progress markers.
+const maxProgressLine = 1024
+
+// Progress is the state of one progress bar. Lines with the same label
+// update the same bar; an unlabeled bar has an empty label.
+type Progress struct {
+	Label   string `json:"label"`
+	Current int64  `json:"current"`
+	Total   int64  `json:"total"`
+	Done    bool   `json:"done"`
+}
+
+// ParseProgress reads a progress marker line:
+//
+//	//gopoke:progress 42/100 "indexing"
+//
+// The label is an optional Go string literal. Current is clamped to Total.
+func ParseProgress(line string) (Progress, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, progressMarker) {
+		return Progress{}, false
+	}
+	counts, label, _ := strings.Cut(strings.TrimSpace(trimmed[len(progressMarker):]), " ")
+	current, total, ok := strings.Cut(counts, "/")
+	if !ok {
+		return Progress{}, false
+	}
+	progress := Progress{}
+	var err error
+	if progress.Current, err = strconv.ParseInt(current, 10, 64); err != nil || progress.Current < 0 {
+		return Progress{}, false
+	}
+	if progress.Total, err = strconv.ParseInt(total, 10, 64); err != nil || progress.Total <= 0 {
+		return Progress{}, false
+	}
+	if label = strings.TrimSpace(label); label != "" {
+		if progress.Label, err = strconv.Unquote(label); err != nil {
+			return Progress{}, false
+		}
+	}
+	progress.Current = min(progress.Current, progress.Total)
+	progress.Done = progress.Current == progress.Total
+	return progress, true
+}
+
+// ProgressHelper is Go source a snippet can paste in to report progress.
+const ProgressHelper = `// progress reports how far a long task has come; gopoke shows it as a bar.
+func progress(current, total int, label string) {
+	fmt.Printf("//gopoke:progress %d/%d %q\n", current, total, label)
+}
+`
+
+// ProgressStream reads progress markers from output that arrives in chunks,
+// for updating bars while a run is live.
+type ProgressStream struct {
+	pending  []byte
+	overlong bool // the pending line passed maxProgressLine
+}
+
+// Feed consumes the next chunk and returns the progress of the marker lines
+// it completes, keeping only the last update of each bar.
+func (s *ProgressStream) Feed(chunk string) []Progress {
+	var updates []Progress
+	for chunk != "" {
+		line, rest, complete := strings.Cut(chunk, "\n")
+		if !s.overlong {
+			if len(s.pending)+len(line) > maxProgressLine {
+				s.overlong = true
+				s.pending = s.pending[:0]
+			} else {
+				s.pending = append(s.pending, line...)
+			}
+		}
+		if !complete {
+			break
+		}
+		if progress, ok := ParseProgress(string(s.pending)); ok && !s.overlong {
+			updates = setProgress(updates, progress)
+		}
+		s.pending = s.pending[:0]
+		s.overlong = false
+		chunk = rest
+	}
+	return updates
+}
+
+// setProgress replaces the bar with the same label or adds a new one.
+func setProgress(bars []Progress, progress Progress) []Progress {
+	for i := range bars {
+		if bars[i].Label == progress.Label {
+			bars[i] = progress
+			return bars
+		}
+	}
+	return append(bars, progress)
+}
+
+func progressBlock(progress Progress) RichBlock {
+	payload, _ := json.Marshal(progress)
+	return RichBlock{Type: TypeProgress, Data: payload}
+}
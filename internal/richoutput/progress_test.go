@@ -0,0 +1,62 @@
+package richoutput
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseProgress(t *testing.T) {
+	for line, want := range map[string]Progress{
+		`//gopoke:progress 42/100 "indexing"`: {Label: "indexing", Current: 42, Total: 100},
+		`  //gopoke:progress 3/3`:             {Current: 3, Total: 3, Done: true},
+		`//gopoke:progress 9/5 "over"`:        {Label: "over", Current: 5, Total: 5, Done: true},
+	} {
+		got, ok := ParseProgress(line)
+		if !ok || got != want {
+			t.Errorf("ParseProgress(%q) = %+v, %v, want %+v", line, got, ok, want)
+		}
+	}
+	for _, line := range []string{
+		`//gopoke:progress 1/0`,
+		`//gopoke:progress -1/10`,
+		`//gopoke:progress 42`,
+		`//gopoke:progress 1/2 unquoted`,
+		`//gopoke:json {"a":1}`,
+	} {
+		if got, ok := ParseProgress(line); ok {
+			t.Errorf("ParseProgress(%q) = %+v, want no progress", line, got)
+		}
+	}
+}
+
+func TestParseMergesProgressUpdates(t *testing.T) {
+	stdout := "start\n//gopoke:progress 1/3 \"load\"\n//gopoke:json {\"a\":1}\n//gopoke:progress 3/3 \"load\"\n//gopoke:progress 1/2 \"save\"\nend"
+	clean, blocks := Parse(stdout)
+	if clean != "start\nend" {
+		t.Fatalf("clean = %q, want progress lines stripped", clean)
+	}
+	if len(blocks) != 3 || blocks[0].Type != TypeProgress || blocks[1].Type != TypeJSON || blocks[2].Type != TypeProgress {
+		t.Fatalf("blocks = %+v, want load bar, json, save bar", blocks)
+	}
+	var load Progress
+	if err := json.Unmarshal(blocks[0].Data, &load); err != nil {
+		t.Fatalf("unmarshal progress: %v", err)
+	}
+	if want := (Progress{Label: "load", Current: 3, Total: 3, Done: true}); load != want {
+		t.Fatalf("load bar = %+v, want %+v", load, want)
+	}
+}
+
+func TestProgressStreamKeepsLastUpdatePerChunk(t *testing.T) {
+	var stream ProgressStream
+	if got := stream.Feed("//gopoke:progress 1/4\n//gopoke:progress 2/4\n//gopoke:prog"); !reflect.DeepEqual(got, []Progress{{Current: 2, Total: 4}}) {
+		t.Fatalf("Feed() = %+v, want the last update", got)
+	}
+	if got := stream.Feed("ress 4/4\n"); !reflect.DeepEqual(got, []Progress{{Current: 4, Total: 4, Done: true}}) {
+		t.Fatalf("Feed() = %+v, want the split line", got)
+	}
+	if got := stream.Feed("plain output\n"); got != nil {
+		t.Fatalf("Feed() = %+v, want no updates", got)
+	}
+}
@@ -6,6 +6,16 @@ import "encoding/json"
 const (
 	TypeTable = "table"
 	TypeJSON  = "json"
+	// TypeDump renders a value tree printed by the gopoke.Dump helper.
+	TypeDump = "dump"
+	// TypeProgress renders a progress bar from //gopoke:progress lines.
+	TypeProgress = "progress"
+	// TypeMeasure renders the allocations and wall time of a block measured
+	// by the gopoke.Measure helper.
+	TypeMeasure = "measure"
+	// TypeHexdump renders binary stdout; it is produced by gopoke, not by
+	// markers in program output.
+	TypeHexdump = "hexdump"
 )
 
 // RichBlock holds one parsed rich output block extracted from program stdout.
@@ -0,0 +1,275 @@
+// Package runguard scans snippet source before a run for calls that look
+// destructive: removing directory trees, shelling out to rm and writing
+// outside the project. The scan is syntactic and only judges string
+// literals, so it flags likely mistakes rather than proving safety.
+package runguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Run confirmation policies, set per project.
+const (
+	// PolicyConfirm holds flagged runs until the user confirms them.
+	PolicyConfirm = "confirm"
+	// PolicyWarn runs flagged snippets and reports the findings.
+	PolicyWarn = "warn"
+	// PolicyOff skips the scan.
+	PolicyOff = "off"
+)
+
+// Rules reported in findings.
+const (
+	RuleRemoveAll           = "remove_all"
+	RuleShellRemove         = "shell_remove"
+	RuleWriteOutsideProject = "write_outside_project"
+)
+
+// Finding is one flagged call.
+type Finding struct {
+	Rule   string `json:"rule"`
+	Call   string `json:"call"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// NormalizePolicy returns the canonical policy name. Empty means
+// PolicyConfirm.
+func NormalizePolicy(policy string) (string, error) {
+	switch policy = strings.ToLower(strings.TrimSpace(policy)); policy {
+	case "":
+		return PolicyConfirm, nil
+	case PolicyConfirm, PolicyWarn, PolicyOff:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported run confirmation policy %q", policy)
+	}
+}
+
+// removeCommands are programs that delete files when run through exec.
+var removeCommands = []string{"rm", "rmdir", "del", "rd", "erase", "shred", "remove-item"}
+
+// shells run their -c argument as a command line.
+var shells = []string{"sh", "bash", "zsh", "dash", "cmd", "powershell", "pwsh"}
+
+// osWrites maps os functions that modify the filesystem to the indexes of
+// their path arguments.
+var osWrites = map[string][]int{
+	"WriteFile": {0},
+	"Create":    {0},
+	"Mkdir":     {0},
+	"MkdirAll":  {0},
+	"Remove":    {0},
+	"Rename":    {0, 1},
+	"Symlink":   {1},
+	"Link":      {1},
+	"Chmod":     {0},
+	"Chown":     {0},
+	"Truncate":  {0},
+}
+
+// Scan returns the destructive-looking calls in source, ordered by
+// position. Relative paths are judged against projectDir. Source that does
+// not parse yields no findings; the compiler reports it instead.
+func Scan(source string, projectDir string) []Finding {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, "main.go", source, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	osName := importName(file, "os")
+	execName := importName(file, "os/exec")
+
+	findings := make([]Finding, 0)
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		position := fileSet.Position(call.Pos())
+		finding := Finding{
+			Call:   pkg.Name + "." + selector.Sel.Name,
+			Line:   position.Line,
+			Column: position.Column,
+		}
+		switch {
+		case osName != "" && pkg.Name == osName:
+			if rule, detail, ok := checkOS(selector.Sel.Name, call.Args, projectDir); ok {
+				finding.Rule, finding.Detail = rule, detail
+				findings = append(findings, finding)
+			}
+		case execName != "" && pkg.Name == execName:
+			if detail, ok := checkExec(selector.Sel.Name, call.Args); ok {
+				finding.Rule, finding.Detail = RuleShellRemove, detail
+				findings = append(findings, finding)
+			}
+		}
+		return true
+	})
+	slices.SortFunc(findings, func(a, b Finding) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	return findings
+}
+
+// importName returns the name path is imported under, or "" when it is not
+// imported by name.
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || importPath != path {
+			continue
+		}
+		if spec.Name == nil {
+			return filepath.Base(path)
+		}
+		if spec.Name.Name == "_" || spec.Name.Name == "." {
+			return ""
+		}
+		return spec.Name.Name
+	}
+	return ""
+}
+
+func checkOS(function string, args []ast.Expr, projectDir string) (string, string, bool) {
+	if function == "RemoveAll" {
+		detail := "removes a directory tree"
+		if path, ok := stringLiteral(args, 0); ok {
+			detail = fmt.Sprintf("removes %q and everything below it", path)
+		}
+		return RuleRemoveAll, detail, true
+	}
+	indexes, ok := osWrites[function]
+	if function == "OpenFile" && len(args) > 1 && opensForWrite(args[1]) {
+		indexes, ok = []int{0}, true
+	}
+	if !ok {
+		return "", "", false
+	}
+	for _, index := range indexes {
+		path, ok := stringLiteral(args, index)
+		if !ok || !outsideProject(path, projectDir) {
+			continue
+		}
+		return RuleWriteOutsideProject, fmt.Sprintf("modifies %q outside the project", path), true
+	}
+	return "", "", false
+}
+
+func checkExec(function string, args []ast.Expr) (string, bool) {
+	first := 0
+	switch function {
+	case "Command":
+	case "CommandContext":
+		first = 1
+	default:
+		return "", false
+	}
+	name, ok := stringLiteral(args, first)
+	if !ok {
+		return "", false
+	}
+	program := commandName(name)
+	if slices.Contains(removeCommands, program) {
+		return fmt.Sprintf("runs %q", name), true
+	}
+	if !slices.Contains(shells, program) {
+		return "", false
+	}
+	for index := first + 1; index < len(args); index++ {
+		line, ok := stringLiteral(args, index)
+		if !ok {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(line, isCommandSeparator) {
+			if slices.Contains(removeCommands, commandName(word)) {
+				return fmt.Sprintf("runs %q through %s", line, program), true
+			}
+		}
+	}
+	return "", false
+}
+
+// commandName strips the directory and .exe suffix from a program name,
+// accepting either path separator.
+func commandName(name string) string {
+	base := strings.ToLower(name)
+	if index := strings.LastIndexAny(base, `/\`); index >= 0 {
+		base = base[index+1:]
+	}
+	return strings.TrimSuffix(base, ".exe")
+}
+
+func isCommandSeparator(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == ';' || r == '&' || r == '|'
+}
+
+// opensForWrite reports whether an os.OpenFile flag expression mentions a
+// flag that writes.
+func opensForWrite(flags ast.Expr) bool {
+	writes := false
+	ast.Inspect(flags, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			switch selector.Sel.Name {
+			case "O_WRONLY", "O_RDWR", "O_CREATE", "O_APPEND", "O_TRUNC":
+				writes = true
+			}
+		}
+		return !writes
+	})
+	return writes
+}
+
+func stringLiteral(args []ast.Expr, index int) (string, bool) {
+	if index >= len(args) {
+		return "", false
+	}
+	literal, ok := args[index].(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(literal.Value)
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// outsideProject reports whether path leaves projectDir. Home-relative and
+// absolute paths outside it count, as do relative paths climbing above it.
+func outsideProject(path string, projectDir string) bool {
+	if strings.HasPrefix(path, "~") {
+		return true
+	}
+	if !filepath.IsAbs(path) && !strings.HasPrefix(path, "/") {
+		cleaned := filepath.Clean(path)
+		return cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator))
+	}
+	if projectDir == "" {
+		return true
+	}
+	rel, err := filepath.Rel(projectDir, filepath.Clean(path))
+	if err != nil {
+		return true
+	}
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
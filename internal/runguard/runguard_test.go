@@ -0,0 +1,81 @@
+package runguard
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestScanFlagsDestructiveCalls(t *testing.T) {
+	t.Parallel()
+
+	projectDir := filepath.Join(t.TempDir(), "project")
+	source := `package main
+
+import (
+	"context"
+	"os"
+	run "os/exec"
+)
+
+func main() {
+	os.RemoveAll("build")
+	_ = run.Command("/bin/rm", "-rf", "tmp").Run()
+	_ = run.CommandContext(context.Background(), "sh", "-c", "cd out && rm -rf *").Run()
+	_ = os.WriteFile("../escape.txt", nil, 0o644)
+	_ = os.WriteFile("/etc/hosts", nil, 0o644)
+	_ = os.WriteFile(` + "`" + projectDir + "/ok.txt`" + `, nil, 0o644)
+	_ = os.WriteFile("notes.txt", nil, 0o644)
+	f, _ := os.OpenFile("~/.profile", os.O_APPEND|os.O_WRONLY, 0)
+	_, _ = os.OpenFile("/etc/passwd", os.O_RDONLY, 0)
+	_ = run.Command("ls", "-l").Run()
+	_ = f
+}
+`
+	findings := Scan(source, projectDir)
+	want := []struct {
+		rule string
+		call string
+		line int
+	}{
+		{RuleRemoveAll, "os.RemoveAll", 10},
+		{RuleShellRemove, "run.Command", 11},
+		{RuleShellRemove, "run.CommandContext", 12},
+		{RuleWriteOutsideProject, "os.WriteFile", 13},
+		{RuleWriteOutsideProject, "os.WriteFile", 14},
+		{RuleWriteOutsideProject, "os.OpenFile", 17},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("Scan() = %+v, want %d findings", findings, len(want))
+	}
+	for index, finding := range findings {
+		if finding.Rule != want[index].rule || finding.Call != want[index].call || finding.Line != want[index].line {
+			t.Errorf("finding[%d] = %+v, want %+v", index, finding, want[index])
+		}
+	}
+}
+
+func TestScanIgnoresUnparsableAndUnrelatedSource(t *testing.T) {
+	t.Parallel()
+
+	if findings := Scan("package main\nfunc main() {", "/p"); len(findings) != 0 {
+		t.Fatalf("Scan(unparsable) = %+v, want none", findings)
+	}
+	source := "package main\n\nimport \"fmt\"\n\nfunc main() { os := struct{}{}; _ = os; fmt.Println(\"rm\") }\n"
+	if findings := Scan(source, "/p"); len(findings) != 0 {
+		t.Fatalf("Scan(unrelated) = %+v, want none", findings)
+	}
+}
+
+func TestNormalizePolicy(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]string{"": PolicyConfirm, " Warn ": PolicyWarn, "off": PolicyOff} {
+		got, err := NormalizePolicy(input)
+		if err != nil || got != want {
+			t.Errorf("NormalizePolicy(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := NormalizePolicy("block"); err == nil {
+		t.Fatal("NormalizePolicy(block) error = nil, want error")
+	}
+}
@@ -0,0 +1,256 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"gopoke/internal/execution"
+)
+
+// defaultHandshakeTimeout bounds how long a new worker has to answer hello.
+const defaultHandshakeTimeout = 10 * time.Second
+
+// errWorkerExited is returned for calls still waiting when the worker's
+// protocol stream ends.
+var errWorkerExited = errors.New("worker exited")
+
+// errCancelUnanswered is returned when a worker does not report a canceled
+// run's result within the wait the manager allows it.
+var errCancelUnanswered = errors.New("worker did not finish the canceled run")
+
+// workerClient is the manager's side of the framed protocol with one worker
+// process. Calls may overlap; a read loop routes each response frame to the
+// call waiting on its ID.
+type workerClient struct {
+	in      io.WriteCloser
+	writeMu sync.Mutex
+
+	mu     sync.Mutex
+	nextID uint64
+	calls  map[uint64]*pendingCall
+
+	closed  chan struct{}
+	readErr error // set before closed is closed
+}
+
+// pendingCall receives the frames answering one request.
+type pendingCall struct {
+	frames chan workerResponse
+	done   chan struct{} // closed once the caller stops listening
+}
+
+// newWorkerClient speaks the protocol over a worker's stdin (in) and
+// stdout (out). It closes out once the worker stops writing frames.
+func newWorkerClient(in io.WriteCloser, out io.ReadCloser) *workerClient {
+	client := &workerClient{
+		in:     in,
+		calls:  make(map[uint64]*pendingCall),
+		closed: make(chan struct{}),
+	}
+	go client.readLoop(out)
+	return client
+}
+
+func (c *workerClient) readLoop(out io.ReadCloser) {
+	defer out.Close()
+	for {
+		var response workerResponse
+		if err := readFrame(out, &response); err != nil {
+			c.readErr = err
+			close(c.closed)
+			return
+		}
+		c.mu.Lock()
+		call, ok := c.calls[response.ID]
+		c.mu.Unlock()
+		if !ok {
+			continue
+		}
+		select {
+		case call.frames <- response:
+		case <-call.done:
+		}
+	}
+}
+
+// hello negotiates the protocol version and refuses a worker that settles
+// on a version outside the range this build speaks.
+func (c *workerClient) hello(ctx context.Context) (int, error) {
+	response, err := c.call(ctx, workerRequest{
+		Type:  frameTypeHello,
+		Hello: &helloRequest{MinVersion: MinWorkerProtocolVersion, MaxVersion: WorkerProtocolVersion},
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := expectFrame(response, frameTypeHello); err != nil {
+		return 0, err
+	}
+	if response.Version < MinWorkerProtocolVersion || response.Version > WorkerProtocolVersion {
+		return 0, fmt.Errorf(
+			"worker chose protocol %d (manager supports %d-%d)",
+			response.Version, MinWorkerProtocolVersion, WorkerProtocolVersion,
+		)
+	}
+	return response.Version, nil
+}
+
+// ping checks that the worker still answers, even while it runs a snippet.
+func (c *workerClient) ping(ctx context.Context) error {
+	response, err := c.call(ctx, workerRequest{Type: frameTypePing}, nil)
+	if err != nil {
+		return err
+	}
+	return expectFrame(response, frameTypePong)
+}
+
+// run executes one snippet, passing the started, stdout and stderr frames
+// that precede its result to onFrame. When ctx is canceled it sends a cancel
+// frame and keeps waiting for the result the worker reports, for at most
+// cancelWait.
+func (c *workerClient) run(ctx context.Context, request runRequest, cancelWait time.Duration, onFrame func(workerResponse)) (execution.Result, error) {
+	id, call := c.register()
+	defer c.unregister(id)
+	if err := c.write(workerRequest{ID: id, Type: frameTypeRun, Run: &request}); err != nil {
+		return execution.Result{}, err
+	}
+
+	canceled := ctx.Done()
+	var abandon <-chan time.Time
+	for {
+		select {
+		case <-canceled:
+			canceled = nil
+			if err := c.send(workerRequest{Type: frameTypeCancel, Cancel: id}); err != nil {
+				return execution.Result{}, fmt.Errorf("cancel worker run: %w", err)
+			}
+			timer := time.NewTimer(cancelWait)
+			defer timer.Stop()
+			abandon = timer.C
+		case <-abandon:
+			return execution.Result{}, errCancelUnanswered
+		case <-c.closed:
+			return execution.Result{}, c.exitError()
+		case response := <-call.frames:
+			if isProgressFrame(response) {
+				onFrame(response)
+				continue
+			}
+			if err := expectFrame(response, frameTypeResult); err != nil {
+				return execution.Result{}, err
+			}
+			if response.Result == nil {
+				return execution.Result{}, fmt.Errorf("worker result frame has no result")
+			}
+			return *response.Result, nil
+		}
+	}
+}
+
+// shutdown asks the worker to exit and closes its stdin. It does not wait
+// for the acknowledgement: a worker with runs queued answers only once they
+// finish.
+func (c *workerClient) shutdown() error {
+	err := c.send(workerRequest{Type: frameTypeShutdown})
+	if closeErr := c.in.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// send writes a request that gets no awaited reply under a fresh ID.
+func (c *workerClient) send(request workerRequest) error {
+	c.mu.Lock()
+	c.nextID++
+	request.ID = c.nextID
+	c.mu.Unlock()
+	return c.write(request)
+}
+
+// call sends request and waits for the frame that ends it, passing any
+// intermediate frames to onFrame.
+func (c *workerClient) call(ctx context.Context, request workerRequest, onFrame func(workerResponse)) (workerResponse, error) {
+	id, call := c.register()
+	defer c.unregister(id)
+	request.ID = id
+	if err := c.write(request); err != nil {
+		return workerResponse{}, err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return workerResponse{}, ctx.Err()
+		case <-c.closed:
+			return workerResponse{}, c.exitError()
+		case response := <-call.frames:
+			if isProgressFrame(response) {
+				if onFrame != nil {
+					onFrame(response)
+				}
+				continue
+			}
+			return response, nil
+		}
+	}
+}
+
+// exitError reports why the protocol stream ended; call only once closed
+// is closed.
+func (c *workerClient) exitError() error {
+	if errors.Is(c.readErr, io.EOF) {
+		return errWorkerExited
+	}
+	return fmt.Errorf("%w: %v", errWorkerExited, c.readErr)
+}
+
+// isProgressFrame reports whether response precedes the frame that ends a call.
+func isProgressFrame(response workerResponse) bool {
+	switch response.Type {
+	case frameTypeStarted, frameTypeStdout, frameTypeStderr:
+		return true
+	default:
+		return false
+	}
+}
+
+// register allocates a request ID and the call its frames are routed to.
+func (c *workerClient) register() (uint64, *pendingCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	call := &pendingCall{frames: make(chan workerResponse), done: make(chan struct{})}
+	c.calls[c.nextID] = call
+	return c.nextID, call
+}
+
+func (c *workerClient) unregister(id uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if call, ok := c.calls[id]; ok {
+		close(call.done)
+		delete(c.calls, id)
+	}
+}
+
+func (c *workerClient) write(request workerRequest) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return writeFrame(c.in, request)
+}
+
+// expectFrame turns an error frame into an error and rejects frames of a
+// type other than want.
+func expectFrame(response workerResponse, want string) error {
+	switch response.Type {
+	case want:
+		return nil
+	case frameTypeError:
+		return &workerError{message: response.Error, kind: response.ErrorKind}
+	default:
+		return fmt.Errorf("unexpected %q frame from worker, want %q", response.Type, want)
+	}
+}
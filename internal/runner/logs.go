@@ -0,0 +1,161 @@
+package runner
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// defaultLogCapacity bounds retained log lines per project worker.
+const defaultLogCapacity = 500
+
+// maxPendingLogBytes caps an unterminated line before it is flushed as-is.
+const maxPendingLogBytes = 16 * 1024
+
+const (
+	// LogStreamStdout marks a line written to worker stdout.
+	LogStreamStdout = "stdout"
+	// LogStreamStderr marks a line written to worker stderr.
+	LogStreamStderr = "stderr"
+)
+
+// LogLine is one captured line of worker output.
+type LogLine struct {
+	ProjectPath string    `json:"projectPath"`
+	Stream      string    `json:"stream"`
+	Line        string    `json:"line"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// LogHandler receives worker log lines as they are captured.
+type LogHandler func(line LogLine)
+
+// logRing keeps the most recent lines for one project worker.
+type logRing struct {
+	mu       sync.Mutex
+	lines    []LogLine
+	next     int
+	full     bool
+	capacity int
+}
+
+func newLogRing(capacity int) *logRing {
+	if capacity <= 0 {
+		capacity = defaultLogCapacity
+	}
+	return &logRing{
+		lines:    make([]LogLine, capacity),
+		capacity: capacity,
+	}
+}
+
+func (r *logRing) append(line LogLine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % r.capacity
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// tail returns up to limit most recent lines in chronological order.
+// A limit of zero or less returns everything retained.
+func (r *logRing) tail(limit int) []LogLine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	size := r.next
+	if r.full {
+		size = r.capacity
+	}
+	if limit <= 0 || limit > size {
+		limit = size
+	}
+
+	result := make([]LogLine, 0, limit)
+	start := r.next - limit
+	if start < 0 {
+		start += r.capacity
+	}
+	for i := 0; i < limit; i++ {
+		result = append(result, r.lines[(start+i)%r.capacity])
+	}
+	return result
+}
+
+// logWriter splits a worker output stream into lines for the ring buffer.
+type logWriter struct {
+	mu          sync.Mutex
+	projectPath string
+	stream      string
+	ring        *logRing
+	onLine      func(LogLine)
+	pending     bytes.Buffer
+}
+
+func newLogWriter(projectPath string, stream string, ring *logRing, onLine func(LogLine)) *logWriter {
+	return &logWriter{
+		projectPath: projectPath,
+		stream:      stream,
+		ring:        ring,
+		onLine:      onLine,
+	}
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	w.pending.Write(p)
+	lines := make([]LogLine, 0)
+	for {
+		data := w.pending.Bytes()
+		index := bytes.IndexByte(data, '\n')
+		if index < 0 {
+			if len(data) < maxPendingLogBytes {
+				break
+			}
+			index = len(data)
+		}
+		text := string(bytes.TrimRight(data[:index], "\r"))
+		w.pending.Next(min(index+1, len(data)))
+		lines = append(lines, w.record(text))
+	}
+	w.mu.Unlock()
+
+	w.emit(lines)
+	return len(p), nil
+}
+
+// Flush records any trailing partial line.
+func (w *logWriter) Flush() {
+	w.mu.Lock()
+	if w.pending.Len() == 0 {
+		w.mu.Unlock()
+		return
+	}
+	line := w.record(w.pending.String())
+	w.pending.Reset()
+	w.mu.Unlock()
+
+	w.emit([]LogLine{line})
+}
+
+func (w *logWriter) record(text string) LogLine {
+	line := LogLine{
+		ProjectPath: w.projectPath,
+		Stream:      w.stream,
+		Line:        text,
+		Timestamp:   time.Now().UTC(),
+	}
+	w.ring.append(line)
+	return line
+}
+
+func (w *logWriter) emit(lines []LogLine) {
+	if w.onLine == nil {
+		return
+	}
+	for _, line := range lines {
+		w.onLine(line)
+	}
+}
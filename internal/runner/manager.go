@@ -8,25 +8,46 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/faults"
+	"gopoke/internal/fspath"
+	"gopoke/internal/procmem"
 )
 
 const defaultStopTimeout = 2 * time.Second
 
+// canceledRunSlack is how long past its own grace period and drain timeout
+// a canceled run may take to report its result before the manager gives up
+// on the worker. It covers the executor's defaults for both when a run
+// leaves them unset.
+const canceledRunSlack = 5 * time.Second
+
 // Worker holds public lifecycle information for a project worker process.
 type Worker struct {
-	ProjectPath string
-	StartedAt   time.Time
-	PID         int
-	Running     bool
+	ProjectPath  string
+	StartedAt    time.Time
+	PID          int
+	Running      bool
+	UptimeMS     int64
+	RestartCount int
+	MemoryBytes  int64
 }
 
 type managedWorker struct {
-	info    Worker
-	command *exec.Cmd
-	done    chan struct{}
-	waitErr error
+	info     Worker
+	command  *exec.Cmd
+	client   *workerClient
+	stderr   *logWriter
+	lastUsed time.Time
+	// active counts runs in progress; a busy worker is never evicted.
+	active      int
+	fingerprint string
+	done        chan struct{}
+	waitErr     error
 }
 
 // CommandFactory creates a long-lived worker command for a project.
@@ -53,20 +74,66 @@ func WithStopTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithLogHandler streams captured worker log lines to handler.
+func WithLogHandler(handler LogHandler) Option {
+	return func(m *Manager) {
+		m.logHandler = handler
+	}
+}
+
+// WithLogCapacity sets how many log lines are retained per project worker.
+func WithLogCapacity(lines int) Option {
+	return func(m *Manager) {
+		if lines > 0 {
+			m.logCapacity = lines
+		}
+	}
+}
+
+// WithClock replaces the wall clock used for worker start times, idle
+// tracking and lifetime recycling.
+func WithClock(now func() time.Time) Option {
+	return func(m *Manager) {
+		if now != nil {
+			m.now = func() time.Time { return now().UTC() }
+		}
+	}
+}
+
 // Manager owns worker lifecycle per project.
 type Manager struct {
-	mu             sync.RWMutex
-	workers        map[string]*managedWorker
+	mu       sync.RWMutex
+	workers  map[string]*managedWorker
+	restarts map[string]int
+	// starting holds a channel per project whose new worker is still in
+	// its handshake; it is closed once the worker is published or refused.
+	starting    map[string]chan struct{}
+	logs        map[string]*logRing
+	logCapacity int
+	// handlerMu guards logHandler apart from mu, so publishing worker
+	// output never waits on worker lifecycle changes.
+	handlerMu      sync.RWMutex
+	logHandler     LogHandler
+	policy         Policy
 	commandFactory CommandFactory
 	stopTimeout    time.Duration
+	// handshakeTimeout bounds the protocol handshake with a new worker.
+	handshakeTimeout time.Duration
+	now              func() time.Time
 }
 
 // NewManager creates a process-based lifecycle manager.
 func NewManager(options ...Option) *Manager {
 	manager := &Manager{
-		workers:        make(map[string]*managedWorker),
-		commandFactory: defaultWorkerCommandFactory,
-		stopTimeout:    defaultStopTimeout,
+		workers:          make(map[string]*managedWorker),
+		restarts:         make(map[string]int),
+		starting:         make(map[string]chan struct{}),
+		logs:             make(map[string]*logRing),
+		logCapacity:      defaultLogCapacity,
+		commandFactory:   defaultWorkerCommandFactory,
+		stopTimeout:      defaultStopTimeout,
+		handshakeTimeout: defaultHandshakeTimeout,
+		now:              func() time.Time { return time.Now().UTC() },
 	}
 	for _, option := range options {
 		option(manager)
@@ -74,52 +141,192 @@ func NewManager(options ...Option) *Manager {
 	return manager
 }
 
-// StartWorker starts or reuses a long-lived worker process for a project.
+// StartWorker starts or reuses a long-lived worker process for a project. A
+// new worker must complete the protocol handshake, settling on a version
+// both sides speak, before it is used; one that does not is stopped. The
+// handshake runs without holding the manager lock; concurrent starts for the
+// same project wait for it instead of spawning a second worker.
 func (m *Manager) StartWorker(ctx context.Context, projectPath string) (Worker, error) {
+	info, _, err := m.startWorker(ctx, projectPath, false)
+	return info, err
+}
+
+// startWorker is StartWorker; with reserve set it also counts a run on the
+// returned worker before releasing the lock, so the worker cannot be evicted
+// before the run begins. The caller ends that run with finishRun.
+func (m *Manager) startWorker(ctx context.Context, projectPath string, reserve bool) (Worker, *managedWorker, error) {
 	if err := ctx.Err(); err != nil {
-		return Worker{}, fmt.Errorf("start worker context: %w", err)
+		return Worker{}, nil, fmt.Errorf("start worker context: %w", err)
 	}
 	normalizedProjectPath, err := normalizeProjectPath(projectPath)
 	if err != nil {
-		return Worker{}, err
+		return Worker{}, nil, err
 	}
 
-	m.mu.Lock()
+	if err := m.lockWhenNotStarting(ctx, normalizedProjectPath); err != nil {
+		return Worker{}, nil, err
+	}
 	existing, ok := m.workers[normalizedProjectPath]
 	if ok && existing.info.Running {
+		reason := m.recycleReasonLocked(existing, m.now())
+		if reason == "" {
+			return m.useWorkerLocked(existing, reserve)
+		}
 		m.mu.Unlock()
-		return existing.info, nil
+		if err := m.StopWorker(ctx, normalizedProjectPath); err != nil {
+			return Worker{}, nil, fmt.Errorf("recycle worker (%s): %w", reason, err)
+		}
+		if err := m.lockWhenNotStarting(ctx, normalizedProjectPath); err != nil {
+			return Worker{}, nil, err
+		}
+	}
+
+	if victims := m.evictIdleWorkersLocked(normalizedProjectPath); len(victims) > 0 {
+		m.mu.Unlock()
+		for _, victim := range victims {
+			if err := m.stopWorker(ctx, victim); err != nil {
+				return Worker{}, nil, fmt.Errorf("evict worker %s: %w", victim.info.ProjectPath, err)
+			}
+		}
+		if err := m.lockWhenNotStarting(ctx, normalizedProjectPath); err != nil {
+			return Worker{}, nil, err
+		}
+		if current, ok := m.workers[normalizedProjectPath]; ok && current.info.Running {
+			return m.useWorkerLocked(current, reserve)
+		}
 	}
 
 	command, err := m.commandFactory(normalizedProjectPath)
 	if err != nil {
 		m.mu.Unlock()
-		return Worker{}, fmt.Errorf("create worker command: %w", err)
+		return Worker{}, nil, fmt.Errorf("create worker command: %w", err)
+	}
+	if err := faults.Inject(faults.ProcessSpawn, normalizedProjectPath); err != nil {
+		m.mu.Unlock()
+		return Worker{}, nil, fmt.Errorf("start worker command: %w", err)
+	}
+	ring, ok := m.logs[normalizedProjectPath]
+	if !ok {
+		ring = newLogRing(m.logCapacity)
+		m.logs[normalizedProjectPath] = ring
 	}
-	command.Stdout = io.Discard
-	command.Stderr = io.Discard
+	stderr := newLogWriter(normalizedProjectPath, LogStreamStderr, ring, m.publishLogLine)
+	command.Stderr = stderr
+	// Protocol frames own the worker's stdin and stdout; only stderr is
+	// captured as log lines. Worker mode exits when stdin closes, so the
+	// pipe stays open for the worker's lifetime.
+	stdin, err := command.StdinPipe()
+	if err != nil {
+		m.mu.Unlock()
+		return Worker{}, nil, fmt.Errorf("open worker stdin: %w", err)
+	}
+	// An os.Pipe rather than StdoutPipe, which Wait closes while the
+	// client may still be reading the worker's last frames.
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		m.mu.Unlock()
+		return Worker{}, nil, fmt.Errorf("open worker stdout: %w", err)
+	}
+	command.Stdout = stdoutWriter
 
-	if err := command.Start(); err != nil {
+	err = command.Start()
+	stdoutWriter.Close()
+	if err != nil {
+		stdout.Close()
 		m.mu.Unlock()
-		return Worker{}, fmt.Errorf("start worker command: %w", err)
+		return Worker{}, nil, fmt.Errorf("start worker command: %w", err)
 	}
+	starting := make(chan struct{})
+	m.starting[normalizedProjectPath] = starting
+	m.mu.Unlock()
+
+	client := newWorkerClient(stdin, stdout)
+	handshakeCtx, cancelHandshake := context.WithTimeout(ctx, m.handshakeTimeout)
+	_, err = client.hello(handshakeCtx)
+	cancelHandshake()
 
+	m.mu.Lock()
+	delete(m.starting, normalizedProjectPath)
+	close(starting)
+	if err != nil {
+		m.mu.Unlock()
+		_ = command.Process.Kill()
+		_ = command.Wait()
+		return Worker{}, nil, fmt.Errorf("worker protocol handshake: %w", err)
+	}
+
+	restartCount, started := m.restarts[normalizedProjectPath]
+	if started {
+		restartCount++
+	}
+	m.restarts[normalizedProjectPath] = restartCount
+
+	startedAt := m.now()
 	worker := &managedWorker{
 		info: Worker{
-			ProjectPath: normalizedProjectPath,
-			StartedAt:   time.Now().UTC(),
-			PID:         command.Process.Pid,
-			Running:     true,
+			ProjectPath:  normalizedProjectPath,
+			StartedAt:    startedAt,
+			PID:          command.Process.Pid,
+			Running:      true,
+			RestartCount: restartCount,
 		},
-		command: command,
-		done:    make(chan struct{}),
+		command:     command,
+		client:      client,
+		stderr:      stderr,
+		lastUsed:    startedAt,
+		fingerprint: projectFingerprint(normalizedProjectPath),
+		done:        make(chan struct{}),
 	}
 
+	if reserve {
+		worker.active++
+	}
 	m.workers[normalizedProjectPath] = worker
+	info := worker.info
 	m.mu.Unlock()
 
 	go m.waitForWorkerExit(normalizedProjectPath, worker)
-	return worker.info, nil
+	return info, worker, nil
+}
+
+// useWorkerLocked marks a running worker as used, counting a run on it when
+// reserve is set, and releases the lock.
+func (m *Manager) useWorkerLocked(worker *managedWorker, reserve bool) (Worker, *managedWorker, error) {
+	worker.lastUsed = m.now()
+	if reserve {
+		worker.active++
+	}
+	info := worker.info
+	m.mu.Unlock()
+	return info, worker, nil
+}
+
+// finishRun ends a run counted by startWorker. The worker counts as used
+// when the run finishes, not only when it started.
+func (m *Manager) finishRun(worker *managedWorker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	worker.active--
+	worker.lastUsed = m.now()
+}
+
+// lockWhenNotStarting takes the manager lock once no worker for projectPath
+// is in its handshake. On error the lock is not held.
+func (m *Manager) lockWhenNotStarting(ctx context.Context, projectPath string) error {
+	m.mu.Lock()
+	for {
+		starting, ok := m.starting[projectPath]
+		if !ok {
+			return nil
+		}
+		m.mu.Unlock()
+		select {
+		case <-starting:
+		case <-ctx.Done():
+			return fmt.Errorf("start worker context: %w", ctx.Err())
+		}
+		m.mu.Lock()
+	}
 }
 
 // StopWorker stops a running worker for a project.
@@ -139,12 +346,20 @@ func (m *Manager) StopWorker(ctx context.Context, projectPath string) error {
 	if !running {
 		return nil
 	}
+	return m.stopWorker(ctx, worker)
+}
 
+// stopWorker stops worker and waits for it to exit.
+func (m *Manager) stopWorker(ctx context.Context, worker *managedWorker) error {
 	if worker.command.Process == nil {
 		return nil
 	}
 
-	if err := worker.command.Process.Signal(os.Interrupt); err != nil && !errors.Is(err, os.ErrProcessDone) {
+	// The shutdown request stops an idle worker; the interrupt cancels a
+	// run in progress, which would otherwise delay the request until it
+	// finishes.
+	shutdownErr := worker.client.shutdown()
+	if err := worker.command.Process.Signal(os.Interrupt); err != nil && !errors.Is(err, os.ErrProcessDone) && shutdownErr != nil {
 		return fmt.Errorf("signal worker process: %w", err)
 	}
 
@@ -175,9 +390,147 @@ func (m *Manager) StopAll(ctx context.Context) error {
 	return nil
 }
 
+// Run executes one snippet in the project's worker over the protocol,
+// starting the worker if needed. Canceling ctx sends the worker a cancel
+// frame for this run only and returns the result the worker reports, so
+// output drains and the snippet gets its grace period as it would outside a
+// worker. A worker that does not report the result in time is stopped.
+func (m *Manager) Run(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+	_, worker, err := m.startWorker(ctx, projectPath, true)
+	if err != nil {
+		return execution.Result{}, err
+	}
+	defer m.finishRun(worker)
+
+	var teeErr error
+	tee := func(chunk string) {
+		if options.Tee != nil && teeErr == nil {
+			_, teeErr = io.WriteString(options.Tee, chunk)
+		}
+	}
+	request := workerRunRequest(projectPath, snippet, options)
+	request.Stream = options.OnStdoutChunk != nil || options.OnStderrChunk != nil || options.Tee != nil
+	cancelWait := options.KillGracePeriod + options.OutputDrainTimeout + canceledRunSlack
+	result, err := worker.client.run(ctx, request, cancelWait, func(frame workerResponse) {
+		switch frame.Type {
+		case frameTypeStarted:
+			if options.OnStart != nil {
+				options.OnStart(frame.PID)
+			}
+		case frameTypeStdout:
+			tee(frame.Chunk)
+			if options.OnStdoutChunk != nil {
+				options.OnStdoutChunk(frame.Chunk)
+			}
+		case frameTypeStderr:
+			tee(frame.Chunk)
+			if options.OnStderrChunk != nil {
+				options.OnStderrChunk(frame.Chunk)
+			}
+		}
+	})
+	if errors.Is(err, errCancelUnanswered) {
+		ctxErr := ctx.Err()
+		if stopErr := m.StopWorker(context.WithoutCancel(ctx), projectPath); stopErr != nil {
+			return execution.Result{}, fmt.Errorf("stop worker after canceled run: %w", errors.Join(ctxErr, stopErr))
+		}
+		return execution.Result{}, ctxErr
+	}
+	if err != nil {
+		return execution.Result{}, fmt.Errorf("run in worker: %w", err)
+	}
+	if teeErr != nil {
+		result.TeeError = teeErr.Error()
+	}
+	return result, nil
+}
+
+// Ping checks that a project's running worker still answers the protocol,
+// including while it runs a snippet.
+func (m *Manager) Ping(ctx context.Context, projectPath string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("ping worker context: %w", err)
+	}
+	worker, err := m.runningWorker(projectPath)
+	if err != nil {
+		return err
+	}
+	if err := worker.client.ping(ctx); err != nil {
+		return fmt.Errorf("ping worker: %w", err)
+	}
+	return nil
+}
+
+// Backend returns an execution backend that runs snippets in project
+// workers through Run. Workers run the Go backend, whose name it reports.
+func (m *Manager) Backend() execution.Backend {
+	return workerBackend{manager: m}
+}
+
+type workerBackend struct {
+	manager *Manager
+}
+
+func (workerBackend) Name() string {
+	return execution.BackendGo
+}
+
+func (b workerBackend) Run(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+	return b.manager.Run(ctx, projectPath, snippet, options)
+}
+
+func (m *Manager) runningWorker(projectPath string) (*managedWorker, error) {
+	normalizedProjectPath, err := normalizeProjectPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	worker, ok := m.workers[normalizedProjectPath]
+	if !ok || !worker.info.Running {
+		return nil, fmt.Errorf("no worker running for %s", normalizedProjectPath)
+	}
+	return worker, nil
+}
+
+// workerRunRequest translates run options into a run frame. Tee, OnStart
+// and the chunk handlers stay with the manager, which feeds them from the
+// frames the worker streams back.
+func workerRunRequest(projectPath string, snippet string, options execution.RunOptions) runRequest {
+	return runRequest{
+		ProjectPath:      projectPath,
+		WorkingDirectory: options.WorkingDirectory,
+		Source:           snippet,
+		Environment:      options.Environment,
+		Toolchain:        options.Toolchain,
+		TimeZone:         options.TimeZone,
+		Locale:           options.Locale,
+		Seed:             options.Seed,
+		FrozenTime:       options.FrozenTime,
+		ExportDeadline:   options.ExportDeadline,
+		ShutdownSignal:   options.Shutdown.Signal,
+		ShutdownURL:      options.Shutdown.URL,
+		ShutdownGraceMS:  options.KillGracePeriod.Milliseconds(),
+		CPUAffinity:      options.CPUAffinity,
+		LowPriority:      options.LowPriority,
+		MaxDiskWrite:     options.MaxDiskWriteBytes,
+		MaxProcesses:     options.MaxProcesses,
+		MaxOpenFiles:     options.MaxOpenFiles,
+		Args:             options.Args,
+		Files:            options.Files,
+		ModFile:          options.ModFile,
+		CacheDir:         options.CacheDir,
+		OutputEncoding:   options.OutputEncoding,
+		TimeoutMS:        options.Timeout.Milliseconds(),
+		MaxStdoutBytes:   options.MaxStdoutBytes,
+		MaxStderrBytes:   options.MaxStderrBytes,
+		OutputDrainMS:    options.OutputDrainTimeout.Milliseconds(),
+	}
+}
+
 // IsRunning reports whether a project's worker is currently running.
 func (m *Manager) IsRunning(projectPath string) bool {
-	normalizedProjectPath, err := filepath.Abs(projectPath)
+	normalizedProjectPath, err := normalizeProjectPath(projectPath)
 	if err != nil {
 		return false
 	}
@@ -187,8 +540,77 @@ func (m *Manager) IsRunning(projectPath string) bool {
 	return ok && worker.info.Running
 }
 
+// List returns all running workers sorted by project path, with uptime and
+// resident memory sampled at call time.
+func (m *Manager) List() []Worker {
+	m.mu.RLock()
+	workers := make([]Worker, 0, len(m.workers))
+	for _, worker := range m.workers {
+		if worker.info.Running {
+			workers = append(workers, worker.info)
+		}
+	}
+	m.mu.RUnlock()
+
+	now := m.now()
+	for i := range workers {
+		workers[i].UptimeMS = now.Sub(workers[i].StartedAt).Milliseconds()
+		if memoryBytes, ok := procmem.ResidentBytes(workers[i].PID); ok {
+			workers[i].MemoryBytes = memoryBytes
+		}
+	}
+	slices.SortFunc(workers, func(a, b Worker) int {
+		switch {
+		case a.ProjectPath < b.ProjectPath:
+			return -1
+		case a.ProjectPath > b.ProjectPath:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return workers
+}
+
+// SetLogHandler replaces the live log handler. A nil handler disables streaming.
+func (m *Manager) SetLogHandler(handler LogHandler) {
+	m.handlerMu.Lock()
+	defer m.handlerMu.Unlock()
+	m.logHandler = handler
+}
+
+// Logs returns up to limit most recent log lines captured for a project's
+// worker, including output from previous worker processes. A limit of zero
+// returns every retained line.
+func (m *Manager) Logs(projectPath string, limit int) ([]LogLine, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("limit must be >= 0")
+	}
+	normalizedProjectPath, err := normalizeProjectPath(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	ring, ok := m.logs[normalizedProjectPath]
+	m.mu.RUnlock()
+	if !ok {
+		return []LogLine{}, nil
+	}
+	return ring.tail(limit), nil
+}
+
+func (m *Manager) publishLogLine(line LogLine) {
+	m.handlerMu.RLock()
+	handler := m.logHandler
+	m.handlerMu.RUnlock()
+	if handler != nil {
+		handler(line)
+	}
+}
+
 func (m *Manager) waitForWorkerExit(projectPath string, worker *managedWorker) {
 	waitErr := worker.command.Wait()
+	worker.stderr.Flush()
 
 	m.mu.Lock()
 	if current, ok := m.workers[projectPath]; ok && current == worker {
@@ -232,6 +654,7 @@ func normalizeProjectPath(projectPath string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("resolve project path: %w", err)
 	}
+	absoluteProjectPath = fspath.Canonical(absoluteProjectPath)
 	info, err := os.Stat(absoluteProjectPath)
 	if err != nil {
 		return "", fmt.Errorf("inspect project path: %w", err)
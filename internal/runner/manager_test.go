@@ -2,25 +2,76 @@ package runner
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
+
+	"gopoke/internal/execution"
+	"gopoke/internal/faults"
 )
 
 func TestHelperWorkerProcess(t *testing.T) {
-	if os.Getenv("GOPOKE_TEST_HELPER_WORKER") != "1" {
+	mode := os.Getenv("GOPOKE_TEST_HELPER_WORKER")
+	if mode == "" {
 		return
 	}
 
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
-	<-sig
+	fmt.Fprintln(os.Stderr, "helper worker warning")
+	if mode == "mismatch" {
+		// Answer hello with a version the manager never offered.
+		var request workerRequest
+		if err := readFrame(os.Stdin, &request); err == nil {
+			_ = writeFrame(os.Stdout, workerResponse{ID: request.ID, Type: frameTypeHello, Version: WorkerProtocolVersion + 1})
+		}
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		os.Exit(0)
+	}
+	if mode == "silent" {
+		// Never answer hello.
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		os.Exit(0)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := newWorkerServer("", helperExecute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := server.serve(ctx, os.Stdin, os.Stdout); err != nil {
+		os.Exit(1)
+	}
 	os.Exit(0)
 }
 
+// helperExecute echoes the snippet as output, or, when the snippet is
+// "block", waits for cancellation and reports a canceled result with the
+// output drained so far, as the Go executor does.
+func helperExecute(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+	if options.OnStart != nil {
+		options.OnStart(os.Getpid())
+	}
+	if snippet == "block" {
+		if options.OnStdoutChunk != nil {
+			options.OnStdoutChunk("partial\n")
+		}
+		<-ctx.Done()
+		return execution.Result{Stdout: "partial\n", Canceled: true, ExitCode: -1}, nil
+	}
+	if options.OnStdoutChunk != nil {
+		options.OnStdoutChunk(snippet)
+	}
+	return execution.Result{Stdout: snippet, ExitCode: 0}, nil
+}
+
 func TestManagerStartReuseAndStopWorker(t *testing.T) {
 	projectPath := t.TempDir()
 
@@ -59,6 +110,41 @@ func TestManagerStartReuseAndStopWorker(t *testing.T) {
 	}
 }
 
+func TestManagerFindsWorkerThroughSymlink(t *testing.T) {
+	projectPath := t.TempDir()
+	linkPath := filepath.Join(t.TempDir(), "project-link")
+	if err := os.Symlink(projectPath, linkPath); err != nil {
+		t.Skipf("create symlink: %v", err)
+	}
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+	)
+	defer manager.StopAll(context.Background())
+
+	if _, err := manager.StartWorker(context.Background(), linkPath); err != nil {
+		t.Fatalf("StartWorker(link) error = %v", err)
+	}
+	if !manager.IsRunning(linkPath) || !manager.IsRunning(projectPath) {
+		t.Fatal("IsRunning() = false through the link or its target, want true for both")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		lines, err := manager.Logs(linkPath, 0)
+		if err != nil {
+			t.Fatalf("Logs(link) error = %v", err)
+		}
+		if len(lines) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Logs(link) = no lines, want the worker's stderr")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 func TestManagerStopAll(t *testing.T) {
 	projectOne := t.TempDir()
 	projectTwo := t.TempDir()
@@ -86,9 +172,337 @@ func TestManagerStopAll(t *testing.T) {
 	}
 }
 
+func TestManagerListReportsMetrics(t *testing.T) {
+	projectOne := t.TempDir()
+	projectTwo := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+	)
+	defer manager.StopAll(context.Background())
+
+	if got := manager.List(); len(got) != 0 {
+		t.Fatalf("List() before start = %d workers, want 0", len(got))
+	}
+
+	if _, err := manager.StartWorker(context.Background(), projectOne); err != nil {
+		t.Fatalf("StartWorker(projectOne) error = %v", err)
+	}
+	if err := manager.StopWorker(context.Background(), projectOne); err != nil {
+		t.Fatalf("StopWorker(projectOne) error = %v", err)
+	}
+	restarted, err := manager.StartWorker(context.Background(), projectOne)
+	if err != nil {
+		t.Fatalf("StartWorker(projectOne restart) error = %v", err)
+	}
+	if got, want := restarted.RestartCount, 1; got != want {
+		t.Fatalf("restarted.RestartCount = %d, want %d", got, want)
+	}
+	if _, err := manager.StartWorker(context.Background(), projectTwo); err != nil {
+		t.Fatalf("StartWorker(projectTwo) error = %v", err)
+	}
+
+	workers := manager.List()
+	if got, want := len(workers), 2; got != want {
+		t.Fatalf("len(List()) = %d, want %d", got, want)
+	}
+	if workers[0].ProjectPath > workers[1].ProjectPath {
+		t.Fatalf("List() not sorted: %q before %q", workers[0].ProjectPath, workers[1].ProjectPath)
+	}
+	for _, worker := range workers {
+		if worker.PID <= 0 {
+			t.Fatalf("worker.PID = %d, want > 0", worker.PID)
+		}
+		if worker.UptimeMS < 0 {
+			t.Fatalf("worker.UptimeMS = %d, want >= 0", worker.UptimeMS)
+		}
+		if runtime.GOOS == "linux" && worker.MemoryBytes <= 0 {
+			t.Fatalf("worker.MemoryBytes = %d, want > 0 on linux", worker.MemoryBytes)
+		}
+	}
+}
+
+func TestManagerCapturesWorkerLogs(t *testing.T) {
+	projectPath := t.TempDir()
+
+	var mu sync.Mutex
+	streamed := make([]LogLine, 0)
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+		WithLogHandler(func(line LogLine) {
+			mu.Lock()
+			streamed = append(streamed, line)
+			mu.Unlock()
+		}),
+	)
+
+	if _, err := manager.StartWorker(context.Background(), projectPath); err != nil {
+		t.Fatalf("StartWorker() error = %v", err)
+	}
+
+	var lines []LogLine
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var err error
+		lines, err = manager.Logs(projectPath, 0)
+		if err != nil {
+			t.Fatalf("Logs() error = %v", err)
+		}
+		if len(lines) >= 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := manager.StopWorker(context.Background(), projectPath); err != nil {
+		t.Fatalf("StopWorker() error = %v", err)
+	}
+	streams := make(map[string]string)
+	for _, line := range lines {
+		if line.ProjectPath != projectPath {
+			t.Fatalf("line.ProjectPath = %q, want %q", line.ProjectPath, projectPath)
+		}
+		streams[line.Stream] = line.Line
+	}
+	if got, ok := streams[LogStreamStdout]; ok {
+		t.Fatalf("stdout line = %q, want protocol frames kept out of the log", got)
+	}
+	if got, want := streams[LogStreamStderr], "helper worker warning"; got != want {
+		t.Fatalf("stderr line = %q, want %q", got, want)
+	}
+
+	mu.Lock()
+	streamedCount := len(streamed)
+	mu.Unlock()
+	if streamedCount != len(lines) {
+		t.Fatalf("streamed %d lines, want %d", streamedCount, len(lines))
+	}
+
+	limited, err := manager.Logs(projectPath, 1)
+	if err != nil {
+		t.Fatalf("Logs(limit=1) error = %v", err)
+	}
+	if got, want := len(limited), 1; got != want {
+		t.Fatalf("len(Logs(limit=1)) = %d, want %d", got, want)
+	}
+	if limited[0] != lines[len(lines)-1] {
+		t.Fatalf("Logs(limit=1) = %+v, want most recent line %+v", limited[0], lines[len(lines)-1])
+	}
+}
+
+func TestLogRingKeepsMostRecentLines(t *testing.T) {
+	t.Parallel()
+
+	ring := newLogRing(3)
+	for _, text := range []string{"a", "b", "c", "d", "e"} {
+		ring.append(LogLine{Line: text})
+	}
+	tail := ring.tail(0)
+	got := make([]string, 0, len(tail))
+	for _, line := range tail {
+		got = append(got, line.Line)
+	}
+	if strings.Join(got, ",") != "c,d,e" {
+		t.Fatalf("tail(0) = %v, want [c d e]", got)
+	}
+	if last := ring.tail(2); last[0].Line != "d" || last[1].Line != "e" {
+		t.Fatalf("tail(2) = %+v, want d,e", last)
+	}
+}
+
 func testCommandFactory(projectPath string) (*exec.Cmd, error) {
 	_ = projectPath
+	return helperCommand("1"), nil
+}
+
+func helperCommand(mode string) *exec.Cmd {
 	command := exec.Command(os.Args[0], "-test.run=TestHelperWorkerProcess", "--")
-	command.Env = append(os.Environ(), "GOPOKE_TEST_HELPER_WORKER=1")
-	return command, nil
+	command.Env = append(os.Environ(), "GOPOKE_TEST_HELPER_WORKER="+mode)
+	return command
+}
+
+func TestManagerSpawnFailureIsRetryable(t *testing.T) {
+	projectPath := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+	)
+	defer manager.StopAll(context.Background())
+
+	disable := faults.Enable(faults.ProcessSpawn, faults.Fault{Fail: true, Times: 1, Target: projectPath})
+	defer disable()
+
+	if _, err := manager.StartWorker(context.Background(), projectPath); !errors.Is(err, faults.ErrInjected) {
+		t.Fatalf("StartWorker() error = %v, want injected fault", err)
+	}
+	if manager.IsRunning(projectPath) {
+		t.Fatal("IsRunning(projectPath) = true after failed spawn, want false")
+	}
+
+	worker, err := manager.StartWorker(context.Background(), projectPath)
+	if err != nil {
+		t.Fatalf("StartWorker(retry) error = %v", err)
+	}
+	if !worker.Running {
+		t.Fatal("worker.Running = false after retry, want true")
+	}
+}
+
+func TestManagerRunsSnippetsOverProtocol(t *testing.T) {
+	projectPath := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+	)
+	defer manager.StopAll(context.Background())
+
+	var started int
+	var streamed, teed strings.Builder
+	result, err := manager.Backend().Run(context.Background(), projectPath, "hello\n", execution.RunOptions{
+		Tee:           &teed,
+		OnStart:       func(pid int) { started = pid },
+		OnStdoutChunk: func(chunk string) { streamed.WriteString(chunk) },
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Stdout != "hello\n" || streamed.String() != "hello\n" || teed.String() != "hello\n" {
+		t.Fatalf("result stdout = %q, streamed %q, teed %q; want hello", result.Stdout, streamed.String(), teed.String())
+	}
+	workers := manager.List()
+	if len(workers) != 1 || started != workers[0].PID {
+		t.Fatalf("started pid = %d, workers = %+v; want the worker's pid", started, workers)
+	}
+	if err := manager.Ping(context.Background(), projectPath); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	lines, err := manager.Logs(projectPath, 0)
+	if err != nil {
+		t.Fatalf("Logs() error = %v", err)
+	}
+	for _, line := range lines {
+		if line.Stream == LogStreamStdout {
+			t.Fatalf("log line %+v, want protocol stdout kept out of the log", line)
+		}
+	}
+}
+
+func TestManagerCancelsOneRunWithoutStoppingWorker(t *testing.T) {
+	projectPath := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+	)
+	defer manager.StopAll(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	blockStarted := make(chan struct{})
+	type outcome struct {
+		result execution.Result
+		err    error
+	}
+	canceled := make(chan outcome, 1)
+	go func() {
+		result, err := manager.Run(ctx, projectPath, "block", execution.RunOptions{
+			OnStart: func(int) { close(blockStarted) },
+		})
+		canceled <- outcome{result, err}
+	}()
+	<-blockStarted
+
+	// A second run queues behind the blocked one and must survive its cancel.
+	queued := make(chan outcome, 1)
+	go func() {
+		result, err := manager.Run(context.Background(), projectPath, "queued\n", execution.RunOptions{})
+		queued <- outcome{result, err}
+	}()
+	if err := manager.Ping(context.Background(), projectPath); err != nil {
+		t.Fatalf("Ping() during a run error = %v", err)
+	}
+	cancel()
+
+	got := <-canceled
+	if got.err != nil {
+		t.Fatalf("Run(canceled) error = %v, want the worker's canceled result", got.err)
+	}
+	if !got.result.Canceled || got.result.Stdout != "partial\n" {
+		t.Fatalf("Run(canceled) result = %+v, want canceled with drained output", got.result)
+	}
+	next := <-queued
+	if next.err != nil || next.result.Stdout != "queued\n" {
+		t.Fatalf("Run(queued) = %+v, %v; want it to finish in the same worker", next.result, next.err)
+	}
+	if !manager.IsRunning(projectPath) {
+		t.Fatal("worker stopped after one of its runs was canceled")
+	}
+}
+
+func TestManagerRefusesProtocolVersionMismatch(t *testing.T) {
+	projectPath := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(func(string) (*exec.Cmd, error) { return helperCommand("mismatch"), nil }),
+		WithStopTimeout(500*time.Millisecond),
+	)
+	defer manager.StopAll(context.Background())
+
+	_, err := manager.StartWorker(context.Background(), projectPath)
+	if err == nil || !strings.Contains(err.Error(), "handshake") {
+		t.Fatalf("StartWorker() error = %v, want handshake refused", err)
+	}
+	if manager.IsRunning(projectPath) {
+		t.Fatal("worker with a mismatched protocol is running")
+	}
+}
+
+func TestManagerHandshakeDoesNotBlockOtherProjects(t *testing.T) {
+	silentProject := t.TempDir()
+	otherProject := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(func(projectPath string) (*exec.Cmd, error) {
+			if projectPath == silentProject {
+				return helperCommand("silent"), nil
+			}
+			return helperCommand("1"), nil
+		}),
+		WithStopTimeout(500*time.Millisecond),
+	)
+	manager.handshakeTimeout = 5 * time.Second
+	defer manager.StopAll(context.Background())
+
+	silentDone := make(chan error, 1)
+	go func() {
+		_, err := manager.StartWorker(context.Background(), silentProject)
+		silentDone <- err
+	}()
+	for {
+		manager.mu.RLock()
+		_, starting := manager.starting[silentProject]
+		manager.mu.RUnlock()
+		if starting {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := manager.StartWorker(context.Background(), otherProject); err != nil {
+		t.Fatalf("StartWorker(other) error = %v", err)
+	}
+	if workers := manager.List(); len(workers) != 1 || workers[0].ProjectPath != otherProject {
+		t.Fatalf("List() = %+v, want only the other project's worker", workers)
+	}
+	select {
+	case err := <-silentDone:
+		t.Fatalf("silent worker start finished early: %v", err)
+	default:
+	}
+	if err := <-silentDone; err == nil || !strings.Contains(err.Error(), "handshake") {
+		t.Fatalf("StartWorker(silent) error = %v, want handshake timeout", err)
+	}
 }
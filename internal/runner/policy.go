@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// projectFingerprintFiles are the module files whose changes trigger a worker
+// restart when RestartOnProjectChange is enabled.
+var projectFingerprintFiles = []string{"go.mod", "go.sum", "go.work", "go.work.sum"}
+
+// Policy bounds how many workers run concurrently and how long each one lives.
+type Policy struct {
+	// MaxWorkers caps concurrently running workers. Zero means unlimited.
+	// Workers with a run in progress are never evicted, so the cap is
+	// exceeded while every other worker is busy.
+	MaxWorkers int
+	// MaxLifetime recycles a worker on next use once it is older than this.
+	// Zero means unlimited.
+	MaxLifetime time.Duration
+	// RestartOnProjectChange recycles a worker on next use when the project's
+	// module files changed since it started.
+	RestartOnProjectChange bool
+}
+
+// WithMaxWorkers caps concurrently running workers, evicting the
+// least-recently-used idle worker when a new project needs one.
+func WithMaxWorkers(count int) Option {
+	return func(m *Manager) {
+		if count >= 0 {
+			m.policy.MaxWorkers = count
+		}
+	}
+}
+
+// WithMaxLifetime recycles workers older than lifetime on next use.
+func WithMaxLifetime(lifetime time.Duration) Option {
+	return func(m *Manager) {
+		if lifetime >= 0 {
+			m.policy.MaxLifetime = lifetime
+		}
+	}
+}
+
+// WithRestartOnProjectChange recycles workers whose project module files changed.
+func WithRestartOnProjectChange(enabled bool) Option {
+	return func(m *Manager) {
+		m.policy.RestartOnProjectChange = enabled
+	}
+}
+
+// SetPolicy replaces the worker policy. Running workers are re-evaluated the
+// next time they are started or reused.
+func (m *Manager) SetPolicy(policy Policy) {
+	if policy.MaxWorkers < 0 {
+		policy.MaxWorkers = 0
+	}
+	if policy.MaxLifetime < 0 {
+		policy.MaxLifetime = 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policy = policy
+}
+
+// Policy returns the active worker policy.
+func (m *Manager) Policy() Policy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.policy
+}
+
+// recycleReasonLocked reports why a running worker must be replaced before
+// reuse, or "" when it can be reused as-is.
+func (m *Manager) recycleReasonLocked(worker *managedWorker, now time.Time) string {
+	if m.policy.MaxLifetime > 0 && now.Sub(worker.info.StartedAt) >= m.policy.MaxLifetime {
+		return "max lifetime exceeded"
+	}
+	if m.policy.RestartOnProjectChange && projectFingerprint(worker.info.ProjectPath) != worker.fingerprint {
+		return "project changed"
+	}
+	return ""
+}
+
+// evictIdleWorkersLocked detaches least-recently-used idle workers so a
+// worker for projectPath fits under MaxWorkers, and returns them for the
+// caller to stop once the lock is released. Detached workers can no longer
+// be reused, so no run starts on one after it was chosen. Busy workers are
+// skipped; when too few are idle the new worker goes over the cap rather
+// than cancel a run.
+func (m *Manager) evictIdleWorkersLocked(projectPath string) []*managedWorker {
+	if m.policy.MaxWorkers <= 0 {
+		return nil
+	}
+	running := 0
+	idle := make([]*managedWorker, 0, len(m.workers))
+	for path, worker := range m.workers {
+		if path == projectPath || !worker.info.Running {
+			continue
+		}
+		running++
+		if worker.active == 0 {
+			idle = append(idle, worker)
+		}
+	}
+	excess := min(running-m.policy.MaxWorkers+1, len(idle))
+	if excess <= 0 {
+		return nil
+	}
+	slices.SortFunc(idle, func(a, b *managedWorker) int {
+		return a.lastUsed.Compare(b.lastUsed)
+	})
+	victims := idle[:excess]
+	for _, worker := range victims {
+		delete(m.workers, worker.info.ProjectPath)
+		worker.info.Running = false
+	}
+	return victims
+}
+
+// projectFingerprint summarizes module file sizes and modification times.
+func projectFingerprint(projectPath string) string {
+	var builder strings.Builder
+	for _, name := range projectFingerprintFiles {
+		info, err := os.Stat(filepath.Join(projectPath, name))
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(&builder, "%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+	}
+	return builder.String()
+}
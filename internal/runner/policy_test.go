@@ -0,0 +1,196 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+)
+
+func TestManagerEvictsLeastRecentlyUsedWorker(t *testing.T) {
+	projectOne := t.TempDir()
+	projectTwo := t.TempDir()
+	projectThree := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+		WithMaxWorkers(2),
+	)
+	t.Cleanup(func() {
+		_ = manager.StopAll(context.Background())
+	})
+
+	for _, projectPath := range []string{projectOne, projectTwo} {
+		if _, err := manager.StartWorker(context.Background(), projectPath); err != nil {
+			t.Fatalf("StartWorker(%s) error = %v", projectPath, err)
+		}
+	}
+	// Touch projectOne so projectTwo becomes least recently used.
+	if _, err := manager.StartWorker(context.Background(), projectOne); err != nil {
+		t.Fatalf("StartWorker(reuse) error = %v", err)
+	}
+	if _, err := manager.StartWorker(context.Background(), projectThree); err != nil {
+		t.Fatalf("StartWorker(projectThree) error = %v", err)
+	}
+
+	if !manager.IsRunning(projectOne) {
+		t.Fatal("recently used worker was evicted")
+	}
+	if manager.IsRunning(projectTwo) {
+		t.Fatal("least recently used worker still running")
+	}
+	if !manager.IsRunning(projectThree) {
+		t.Fatal("new worker not running")
+	}
+	if got, want := len(manager.List()), 2; got != want {
+		t.Fatalf("len(List()) = %d, want %d", got, want)
+	}
+}
+
+func TestManagerDoesNotEvictBusyWorker(t *testing.T) {
+	busyProject := t.TempDir()
+	idleProject := t.TempDir()
+	newProject := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+		WithMaxWorkers(1),
+	)
+	t.Cleanup(func() {
+		_ = manager.StopAll(context.Background())
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{})
+	finished := make(chan execution.Result, 1)
+	go func() {
+		result, _ := manager.Run(ctx, busyProject, "block", execution.RunOptions{
+			OnStart: func(int) { close(started) },
+		})
+		finished <- result
+	}()
+	<-started
+
+	// The only other worker is busy, so the new one goes over the limit.
+	if _, err := manager.StartWorker(context.Background(), idleProject); err != nil {
+		t.Fatalf("StartWorker(idle) error = %v", err)
+	}
+	if !manager.IsRunning(busyProject) || !manager.IsRunning(idleProject) {
+		t.Fatalf("workers = %+v, want the busy worker kept over the limit", manager.List())
+	}
+
+	// The busy worker is the least recently used, but its run is in flight.
+	manager.SetPolicy(Policy{MaxWorkers: 2})
+	if _, err := manager.StartWorker(context.Background(), newProject); err != nil {
+		t.Fatalf("StartWorker(new) error = %v", err)
+	}
+	if !manager.IsRunning(busyProject) {
+		t.Fatal("worker evicted while its run was in flight")
+	}
+	if manager.IsRunning(idleProject) {
+		t.Fatal("idle worker kept while over the limit")
+	}
+
+	select {
+	case result := <-finished:
+		t.Fatalf("run finished early with %+v", result)
+	default:
+	}
+	cancel()
+	if result := <-finished; !result.Canceled || result.Stdout != "partial\n" {
+		t.Fatalf("Run() = %+v, want the worker's canceled result", result)
+	}
+
+	// Finishing the run makes its worker more recently used than newProject's.
+	if _, err := manager.StartWorker(context.Background(), idleProject); err != nil {
+		t.Fatalf("StartWorker(idle again) error = %v", err)
+	}
+	if !manager.IsRunning(busyProject) || manager.IsRunning(newProject) {
+		t.Fatalf("workers = %+v, want the least recently used newProject evicted", manager.List())
+	}
+}
+
+func TestManagerRecyclesWorkerAfterMaxLifetime(t *testing.T) {
+	projectPath := t.TempDir()
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+		WithMaxLifetime(20*time.Millisecond),
+	)
+	t.Cleanup(func() {
+		_ = manager.StopAll(context.Background())
+	})
+
+	first, err := manager.StartWorker(context.Background(), projectPath)
+	if err != nil {
+		t.Fatalf("StartWorker() error = %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+
+	second, err := manager.StartWorker(context.Background(), projectPath)
+	if err != nil {
+		t.Fatalf("StartWorker(recycle) error = %v", err)
+	}
+	if second.PID == first.PID {
+		t.Fatalf("worker pid = %d, want recycled process", second.PID)
+	}
+	if got, want := second.RestartCount, 1; got != want {
+		t.Fatalf("RestartCount = %d, want %d", got, want)
+	}
+}
+
+func TestManagerRestartsWorkerOnProjectChange(t *testing.T) {
+	projectPath := t.TempDir()
+	goModPath := filepath.Join(projectPath, "go.mod")
+	if err := os.WriteFile(goModPath, []byte("module example.com/demo\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+
+	manager := NewManager(
+		WithCommandFactory(testCommandFactory),
+		WithStopTimeout(500*time.Millisecond),
+	)
+	t.Cleanup(func() {
+		_ = manager.StopAll(context.Background())
+	})
+
+	first, err := manager.StartWorker(context.Background(), projectPath)
+	if err != nil {
+		t.Fatalf("StartWorker() error = %v", err)
+	}
+	if err := os.WriteFile(goModPath, []byte("module example.com/demo\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatalf("rewrite go.mod: %v", err)
+	}
+
+	unchanged, err := manager.StartWorker(context.Background(), projectPath)
+	if err != nil {
+		t.Fatalf("StartWorker(policy disabled) error = %v", err)
+	}
+	if unchanged.PID != first.PID {
+		t.Fatalf("worker restarted with RestartOnProjectChange disabled")
+	}
+
+	manager.SetPolicy(Policy{RestartOnProjectChange: true})
+	restarted, err := manager.StartWorker(context.Background(), projectPath)
+	if err != nil {
+		t.Fatalf("StartWorker(policy enabled) error = %v", err)
+	}
+	if restarted.PID == first.PID {
+		t.Fatalf("worker pid = %d, want restarted process after go.mod change", restarted.PID)
+	}
+
+	reused, err := manager.StartWorker(context.Background(), projectPath)
+	if err != nil {
+		t.Fatalf("StartWorker(reuse) error = %v", err)
+	}
+	if reused.PID != restarted.PID {
+		t.Fatalf("worker restarted again without further project changes")
+	}
+}
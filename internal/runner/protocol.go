@@ -0,0 +1,209 @@
+package runner
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gopoke/internal/execution"
+)
+
+const (
+	// WorkerProtocolVersion is the newest manager/worker protocol this build
+	// speaks. Version 2 added cancel frames, separate stdout and stderr caps,
+	// the output drain timeout and error kinds.
+	WorkerProtocolVersion = 2
+	// MinWorkerProtocolVersion is the oldest protocol version this build accepts.
+	MinWorkerProtocolVersion = 2
+
+	// maxFrameBytes bounds one frame payload to protect against corrupt streams.
+	maxFrameBytes = 32 * 1024 * 1024
+)
+
+// Request frame types sent by the manager.
+const (
+	frameTypeHello    = "hello"
+	frameTypePing     = "ping"
+	frameTypeRun      = "run"
+	frameTypeCancel   = "cancel"
+	frameTypeShutdown = "shutdown"
+)
+
+// Response frame types sent by the worker.
+const (
+	frameTypePong    = "pong"
+	frameTypeStarted = "started"
+	frameTypeStdout  = "stdout"
+	frameTypeStderr  = "stderr"
+	frameTypeResult  = "result"
+	frameTypeError   = "error"
+)
+
+// Error kinds classify an error frame so the manager can rebuild errors
+// that callers match with errors.Is.
+const (
+	errorKindCanceled = "canceled"
+	errorKindDeadline = "deadline"
+)
+
+// workerRequest is one manager-to-worker frame. Unknown fields are ignored so
+// newer managers can add options without breaking older workers. A cancel
+// frame names the run it stops in Cancel and gets no reply of its own; the
+// run still ends with its result or error frame.
+type workerRequest struct {
+	ID     uint64        `json:"id"`
+	Type   string        `json:"type"`
+	Hello  *helloRequest `json:"hello,omitempty"`
+	Run    *runRequest   `json:"run,omitempty"`
+	Cancel uint64        `json:"cancel,omitempty"`
+}
+
+// helloRequest advertises the protocol range a manager supports.
+type helloRequest struct {
+	MinVersion int `json:"minVersion"`
+	MaxVersion int `json:"maxVersion"`
+}
+
+// runRequest asks the worker to compile and run one snippet.
+type runRequest struct {
+	ProjectPath      string            `json:"projectPath,omitempty"`
+	WorkingDirectory string            `json:"workingDirectory,omitempty"`
+	Source           string            `json:"source"`
+	Environment      map[string]string `json:"environment,omitempty"`
+	Toolchain        string            `json:"toolchain,omitempty"`
+	TimeZone         string            `json:"timeZone,omitempty"`
+	Locale           string            `json:"locale,omitempty"`
+	Seed             *int64            `json:"seed,omitempty"`
+	FrozenTime       time.Time         `json:"frozenTime,omitzero"`
+	ExportDeadline   bool              `json:"exportDeadline,omitempty"`
+	ShutdownSignal   string            `json:"shutdownSignal,omitempty"`
+	ShutdownURL      string            `json:"shutdownUrl,omitempty"`
+	ShutdownGraceMS  int64             `json:"shutdownGraceMs,omitempty"`
+	CPUAffinity      []int             `json:"cpuAffinity,omitempty"`
+	LowPriority      bool              `json:"lowPriority,omitempty"`
+	MaxDiskWrite     int64             `json:"maxDiskWrite,omitempty"`
+	MaxProcesses     int               `json:"maxProcesses,omitempty"`
+	MaxOpenFiles     int               `json:"maxOpenFiles,omitempty"`
+	Args             []string          `json:"args,omitempty"`
+	Files            []string          `json:"files,omitempty"`
+	ModFile          string            `json:"modFile,omitempty"`
+	CacheDir         string            `json:"cacheDir,omitempty"`
+	OutputEncoding   string            `json:"outputEncoding,omitempty"`
+	TimeoutMS        int64             `json:"timeoutMs,omitempty"`
+	MaxStdoutBytes   int               `json:"maxStdoutBytes,omitempty"`
+	MaxStderrBytes   int               `json:"maxStderrBytes,omitempty"`
+	OutputDrainMS    int64             `json:"outputDrainMs,omitempty"`
+	Stream           bool              `json:"stream,omitempty"`
+}
+
+// workerResponse is one worker-to-manager frame. A run produces a started
+// frame once its process is up and zero or more stdout/stderr frames,
+// followed by exactly one result or error frame with the same ID.
+type workerResponse struct {
+	ID        uint64            `json:"id"`
+	Type      string            `json:"type"`
+	Version   int               `json:"version,omitempty"`
+	PID       int               `json:"pid,omitempty"`
+	Chunk     string            `json:"chunk,omitempty"`
+	Result    *execution.Result `json:"result,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	ErrorKind string            `json:"errorKind,omitempty"`
+}
+
+// errorKindOf classifies err for an error frame, or returns "" when the
+// manager has nothing to match it against.
+func errorKindOf(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return errorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorKindDeadline
+	default:
+		return ""
+	}
+}
+
+// workerError is an error frame rebuilt on the manager side. It unwraps to
+// the context error its kind names.
+type workerError struct {
+	message string
+	kind    string
+}
+
+func (e *workerError) Error() string {
+	return "worker: " + e.message
+}
+
+func (e *workerError) Unwrap() error {
+	switch e.kind {
+	case errorKindCanceled:
+		return context.Canceled
+	case errorKindDeadline:
+		return context.DeadlineExceeded
+	default:
+		return nil
+	}
+}
+
+// negotiateProtocolVersion picks the highest version both sides support.
+func negotiateProtocolVersion(hello helloRequest) (int, error) {
+	if hello.MaxVersion < hello.MinVersion {
+		return 0, fmt.Errorf("invalid protocol range %d-%d", hello.MinVersion, hello.MaxVersion)
+	}
+	version := min(hello.MaxVersion, WorkerProtocolVersion)
+	if version < max(hello.MinVersion, MinWorkerProtocolVersion) {
+		return 0, fmt.Errorf(
+			"unsupported protocol range %d-%d (worker supports %d-%d)",
+			hello.MinVersion, hello.MaxVersion, MinWorkerProtocolVersion, WorkerProtocolVersion,
+		)
+	}
+	return version, nil
+}
+
+// writeFrame writes a 4-byte big-endian length prefix followed by JSON.
+func writeFrame(w io.Writer, value any) error {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode frame: %w", err)
+	}
+	if len(payload) > maxFrameBytes {
+		return fmt.Errorf("frame too large: %d bytes", len(payload))
+	}
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(payload)))
+	if _, err := w.Write(header[:]); err != nil {
+		return fmt.Errorf("write frame header: %w", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return fmt.Errorf("write frame payload: %w", err)
+	}
+	return nil
+}
+
+// readFrame reads one length-prefixed JSON frame into value. It returns
+// io.EOF unchanged when the stream ends cleanly between frames.
+func readFrame(r io.Reader, value any) error {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return fmt.Errorf("read frame header: %w", err)
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameBytes {
+		return fmt.Errorf("frame too large: %d bytes", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return fmt.Errorf("read frame payload: %w", err)
+	}
+	if err := json.Unmarshal(payload, value); err != nil {
+		return fmt.Errorf("decode frame: %w", err)
+	}
+	return nil
+}
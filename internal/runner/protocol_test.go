@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	sent := []workerRequest{
+		{ID: 1, Type: frameTypeHello, Hello: &helloRequest{MinVersion: 1, MaxVersion: 3}},
+		{ID: 2, Type: frameTypeRun, Run: &runRequest{Source: "package main\n", Stream: true}},
+	}
+	for _, request := range sent {
+		if err := writeFrame(&buffer, request); err != nil {
+			t.Fatalf("writeFrame() error = %v", err)
+		}
+	}
+
+	for _, want := range sent {
+		var got workerRequest
+		if err := readFrame(&buffer, &got); err != nil {
+			t.Fatalf("readFrame() error = %v", err)
+		}
+		if got.ID != want.ID || got.Type != want.Type {
+			t.Fatalf("frame = %+v, want %+v", got, want)
+		}
+	}
+
+	var extra workerRequest
+	if err := readFrame(&buffer, &extra); !errors.Is(err, io.EOF) {
+		t.Fatalf("readFrame(empty) error = %v, want io.EOF", err)
+	}
+}
+
+func TestReadFrameRejectsOversizedFrame(t *testing.T) {
+	t.Parallel()
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], maxFrameBytes+1)
+	var request workerRequest
+	err := readFrame(bytes.NewReader(header[:]), &request)
+	if err == nil || !strings.Contains(err.Error(), "frame too large") {
+		t.Fatalf("readFrame() error = %v, want frame too large", err)
+	}
+}
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		hello   helloRequest
+		want    int
+		wantErr bool
+	}{
+		{name: "exact", hello: helloRequest{MinVersion: WorkerProtocolVersion, MaxVersion: WorkerProtocolVersion}, want: WorkerProtocolVersion},
+		{name: "manager too old", hello: helloRequest{MinVersion: 1, MaxVersion: MinWorkerProtocolVersion - 1}, wantErr: true},
+		{name: "newer manager", hello: helloRequest{MinVersion: 1, MaxVersion: WorkerProtocolVersion + 5}, want: WorkerProtocolVersion},
+		{name: "manager too new", hello: helloRequest{MinVersion: WorkerProtocolVersion + 1, MaxVersion: WorkerProtocolVersion + 2}, wantErr: true},
+		{name: "inverted range", hello: helloRequest{MinVersion: 2, MaxVersion: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := negotiateProtocolVersion(tt.hello)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("negotiateProtocolVersion() = %d, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("negotiateProtocolVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("version = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,18 @@
 package runner
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
+
+	"gopoke/internal/execution"
 )
 
 const (
@@ -21,15 +30,255 @@ func WorkerProjectPath() string {
 	return os.Getenv(workerProjectEnv)
 }
 
-// RunWorkerModeIfEnabled blocks in worker mode until termination signal arrives.
+// RunWorkerModeIfEnabled serves the worker protocol on stdin/stdout until the
+// manager closes stdin, sends a shutdown frame, or a termination signal arrives.
 // It returns true if worker mode was active and handled.
 func RunWorkerModeIfEnabled() bool {
 	if !IsWorkerMode() {
 		return false
 	}
 
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
-	<-sig
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Protocol frames own stdout; diagnostics go to stderr.
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	server := newWorkerServer(WorkerProjectPath(), execution.RunGoSnippetWithOptions, logger)
+	if err := server.serve(ctx, os.Stdin, os.Stdout); err != nil {
+		logger.Error("worker protocol failed", "error", err)
+	}
 	return true
 }
+
+// snippetExecutor runs one snippet; it matches execution.RunGoSnippetWithOptions.
+type snippetExecutor func(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error)
+
+// workerServer executes framed requests for one project. Runs execute one
+// at a time in arrival order, off the read loop, so cancel and ping frames
+// are answered while a run is in progress.
+type workerServer struct {
+	projectPath string
+	execute     snippetExecutor
+	logger      *slog.Logger
+
+	writeMu sync.Mutex
+	version int
+
+	// lastRun is closed once the most recently queued run finishes; only
+	// the serve loop reads or replaces it.
+	lastRun chan struct{}
+	runs    sync.WaitGroup
+
+	mu      sync.Mutex
+	cancels map[uint64]context.CancelFunc
+}
+
+func newWorkerServer(projectPath string, execute snippetExecutor, logger *slog.Logger) *workerServer {
+	return &workerServer{
+		projectPath: projectPath,
+		execute:     execute,
+		logger:      logger,
+		cancels:     make(map[uint64]context.CancelFunc),
+	}
+}
+
+// serve reads requests until EOF, shutdown, or ctx cancellation, then waits
+// for queued runs to report their results. Requests other than hello and
+// ping are rejected until a version is negotiated.
+func (s *workerServer) serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	defer s.runs.Wait()
+
+	requests := make(chan workerRequest)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			var request workerRequest
+			if err := readFrame(in, &request); err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case requests <- request:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case request := <-requests:
+			done, err := s.handle(ctx, out, request)
+			if err != nil {
+				return err
+			}
+			if done {
+				return nil
+			}
+		}
+	}
+}
+
+// handle processes one request and reports whether the worker should exit.
+func (s *workerServer) handle(ctx context.Context, out io.Writer, request workerRequest) (bool, error) {
+	switch request.Type {
+	case frameTypeHello:
+		if request.Hello == nil {
+			return false, s.writeError(out, request.ID, fmt.Errorf("hello payload is required"))
+		}
+		version, err := negotiateProtocolVersion(*request.Hello)
+		if err != nil {
+			return false, s.writeError(out, request.ID, err)
+		}
+		s.version = version
+		s.logger.Info("worker protocol negotiated", "version", version, "project", s.projectPath)
+		return false, s.write(out, workerResponse{ID: request.ID, Type: frameTypeHello, Version: version})
+	case frameTypePing:
+		return false, s.write(out, workerResponse{ID: request.ID, Type: frameTypePong, Version: s.version})
+	}
+
+	if s.version == 0 {
+		return false, s.writeError(out, request.ID, fmt.Errorf("protocol handshake required before %q", request.Type))
+	}
+
+	switch request.Type {
+	case frameTypeRun:
+		if request.Run == nil {
+			return false, s.writeError(out, request.ID, fmt.Errorf("run payload is required"))
+		}
+		s.enqueueRun(ctx, out, request.ID, *request.Run)
+		return false, nil
+	case frameTypeCancel:
+		s.cancelRun(request.Cancel)
+		return false, nil
+	case frameTypeShutdown:
+		// Like a run, shutdown waits its turn behind the runs already queued.
+		s.runs.Wait()
+		return true, s.write(out, workerResponse{ID: request.ID, Type: frameTypeShutdown})
+	default:
+		return false, s.writeError(out, request.ID, fmt.Errorf("unsupported request type %q", request.Type))
+	}
+}
+
+// enqueueRun starts a run once the previous one finishes. A run canceled
+// while queued answers at once but keeps its place, so runs never overlap.
+func (s *workerServer) enqueueRun(ctx context.Context, out io.Writer, id uint64, request runRequest) {
+	runCtx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancels[id] = cancel
+	s.mu.Unlock()
+
+	previous := s.lastRun
+	done := make(chan struct{})
+	s.lastRun = done
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		defer close(done)
+		defer s.cancelRun(id)
+
+		if previous != nil {
+			select {
+			case <-previous:
+			case <-runCtx.Done():
+				s.reportRunError(s.writeError(out, id, fmt.Errorf("run canceled before it started: %w", runCtx.Err())))
+				<-previous
+				return
+			}
+		}
+		s.reportRunError(s.run(runCtx, out, id, request))
+	}()
+}
+
+// cancelRun cancels a queued or running run; unknown IDs are ignored, since
+// the run may already have finished.
+func (s *workerServer) cancelRun(id uint64) {
+	s.mu.Lock()
+	cancel, ok := s.cancels[id]
+	delete(s.cancels, id)
+	s.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (s *workerServer) reportRunError(err error) {
+	if err != nil {
+		s.logger.Warn("report worker run failed", "error", err)
+	}
+}
+
+func (s *workerServer) run(ctx context.Context, out io.Writer, id uint64, request runRequest) error {
+	projectPath := request.ProjectPath
+	if projectPath == "" {
+		projectPath = s.projectPath
+	}
+	options := execution.RunOptions{
+		WorkingDirectory:   request.WorkingDirectory,
+		Environment:        request.Environment,
+		Toolchain:          request.Toolchain,
+		TimeZone:           request.TimeZone,
+		Locale:             request.Locale,
+		Seed:               request.Seed,
+		FrozenTime:         request.FrozenTime,
+		ExportDeadline:     request.ExportDeadline,
+		Shutdown:           execution.Shutdown{Signal: request.ShutdownSignal, URL: request.ShutdownURL},
+		KillGracePeriod:    time.Duration(request.ShutdownGraceMS) * time.Millisecond,
+		CPUAffinity:        request.CPUAffinity,
+		LowPriority:        request.LowPriority,
+		MaxDiskWriteBytes:  request.MaxDiskWrite,
+		MaxProcesses:       request.MaxProcesses,
+		MaxOpenFiles:       request.MaxOpenFiles,
+		Args:               request.Args,
+		Files:              request.Files,
+		ModFile:            request.ModFile,
+		CacheDir:           request.CacheDir,
+		OutputEncoding:     request.OutputEncoding,
+		Timeout:            time.Duration(request.TimeoutMS) * time.Millisecond,
+		MaxStdoutBytes:     request.MaxStdoutBytes,
+		MaxStderrBytes:     request.MaxStderrBytes,
+		OutputDrainTimeout: time.Duration(request.OutputDrainMS) * time.Millisecond,
+		OnStart: func(pid int) {
+			if err := s.write(out, workerResponse{ID: id, Type: frameTypeStarted, PID: pid}); err != nil {
+				s.logger.Warn("report worker run start failed", "error", err)
+			}
+		},
+	}
+	if request.Stream {
+		options.OnStdoutChunk = func(chunk string) {
+			s.streamChunk(out, id, frameTypeStdout, chunk)
+		}
+		options.OnStderrChunk = func(chunk string) {
+			s.streamChunk(out, id, frameTypeStderr, chunk)
+		}
+	}
+
+	result, err := s.execute(ctx, projectPath, request.Source, options)
+	if err != nil {
+		return s.writeError(out, id, err)
+	}
+	return s.write(out, workerResponse{ID: id, Type: frameTypeResult, Result: &result})
+}
+
+func (s *workerServer) streamChunk(out io.Writer, id uint64, frameType string, chunk string) {
+	if err := s.write(out, workerResponse{ID: id, Type: frameType, Chunk: chunk}); err != nil {
+		s.logger.Warn("stream worker output failed", "error", err)
+	}
+}
+
+func (s *workerServer) writeError(out io.Writer, id uint64, err error) error {
+	return s.write(out, workerResponse{ID: id, Type: frameTypeError, Error: err.Error(), ErrorKind: errorKindOf(err)})
+}
+
+func (s *workerServer) write(out io.Writer, response workerResponse) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return writeFrame(out, response)
+}
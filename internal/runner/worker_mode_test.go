@@ -0,0 +1,195 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+)
+
+type workerHarness struct {
+	t        *testing.T
+	requests *io.PipeWriter
+	replies  *io.PipeReader
+	done     chan error
+}
+
+func startWorkerHarness(t *testing.T, execute snippetExecutor) *workerHarness {
+	t.Helper()
+
+	requestReader, requestWriter := io.Pipe()
+	replyReader, replyWriter := io.Pipe()
+	server := newWorkerServer("/tmp/project", execute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	done := make(chan error, 1)
+	go func() {
+		done <- server.serve(context.Background(), requestReader, replyWriter)
+		_ = replyWriter.Close()
+	}()
+	return &workerHarness{t: t, requests: requestWriter, replies: replyReader, done: done}
+}
+
+func (h *workerHarness) send(request workerRequest) {
+	h.t.Helper()
+	if err := writeFrame(h.requests, request); err != nil {
+		h.t.Fatalf("writeFrame() error = %v", err)
+	}
+}
+
+func (h *workerHarness) receive() workerResponse {
+	h.t.Helper()
+	var response workerResponse
+	if err := readFrame(h.replies, &response); err != nil {
+		h.t.Fatalf("readFrame() error = %v", err)
+	}
+	return response
+}
+
+func (h *workerHarness) wait() {
+	h.t.Helper()
+	select {
+	case err := <-h.done:
+		if err != nil {
+			h.t.Fatalf("serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		h.t.Fatal("worker server did not exit")
+	}
+}
+
+func TestWorkerServerRequiresHandshake(t *testing.T) {
+	t.Parallel()
+
+	harness := startWorkerHarness(t, func(context.Context, string, string, execution.RunOptions) (execution.Result, error) {
+		t.Fatal("executor called before handshake")
+		return execution.Result{}, nil
+	})
+
+	harness.send(workerRequest{ID: 1, Type: frameTypeRun, Run: &runRequest{Source: "package main"}})
+	if response := harness.receive(); response.Type != frameTypeError || response.ID != 1 {
+		t.Fatalf("response = %+v, want error for id 1", response)
+	}
+
+	harness.send(workerRequest{ID: 2, Type: frameTypeHello, Hello: &helloRequest{MinVersion: 99, MaxVersion: 100}})
+	if response := harness.receive(); response.Type != frameTypeError {
+		t.Fatalf("response = %+v, want negotiation error", response)
+	}
+
+	_ = harness.requests.Close()
+	harness.wait()
+}
+
+func TestWorkerServerRunsSnippetAndStreamsOutput(t *testing.T) {
+	t.Parallel()
+
+	var gotProjectPath string
+	var gotOptions execution.RunOptions
+	harness := startWorkerHarness(t, func(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+		gotProjectPath = projectPath
+		gotOptions = options
+		options.OnStdoutChunk("hello\n")
+		options.OnStderrChunk("warn\n")
+		return execution.Result{Stdout: "hello\n", Stderr: "warn\n", ExitCode: 0}, nil
+	})
+
+	harness.send(workerRequest{ID: 1, Type: frameTypeHello, Hello: &helloRequest{MinVersion: 1, MaxVersion: WorkerProtocolVersion + 1}})
+	hello := harness.receive()
+	if hello.Type != frameTypeHello || hello.Version != WorkerProtocolVersion {
+		t.Fatalf("hello response = %+v, want version %d", hello, WorkerProtocolVersion)
+	}
+
+	harness.send(workerRequest{ID: 2, Type: frameTypeRun, Run: &runRequest{
+		Source:         "package main",
+		TimeoutMS:      1500,
+		MaxStdoutBytes: 2048,
+		MaxStderrBytes: 512,
+		OutputDrainMS:  300,
+		Stream:         true,
+	}})
+	stdout := harness.receive()
+	stderr := harness.receive()
+	result := harness.receive()
+	if stdout.Type != frameTypeStdout || stdout.Chunk != "hello\n" {
+		t.Fatalf("stdout frame = %+v", stdout)
+	}
+	if stderr.Type != frameTypeStderr || stderr.Chunk != "warn\n" {
+		t.Fatalf("stderr frame = %+v", stderr)
+	}
+	if result.Type != frameTypeResult || result.ID != 2 || result.Result == nil {
+		t.Fatalf("result frame = %+v", result)
+	}
+	if got, want := result.Result.Stdout, "hello\n"; got != want {
+		t.Fatalf("result stdout = %q, want %q", got, want)
+	}
+	if got, want := gotProjectPath, "/tmp/project"; got != want {
+		t.Fatalf("project path = %q, want %q", got, want)
+	}
+	if got, want := gotOptions.Timeout, 1500*time.Millisecond; got != want {
+		t.Fatalf("timeout = %s, want %s", got, want)
+	}
+	if got, want := gotOptions.MaxStdoutBytes, 2048; got != want {
+		t.Fatalf("max stdout bytes = %d, want %d", got, want)
+	}
+	if got, want := gotOptions.MaxStderrBytes, 512; got != want {
+		t.Fatalf("max stderr bytes = %d, want %d", got, want)
+	}
+	if got, want := gotOptions.OutputDrainTimeout, 300*time.Millisecond; got != want {
+		t.Fatalf("output drain timeout = %s, want %s", got, want)
+	}
+
+	harness.send(workerRequest{ID: 3, Type: "compile-v2"})
+	if response := harness.receive(); response.Type != frameTypeError || response.ID != 3 {
+		t.Fatalf("response = %+v, want unsupported type error", response)
+	}
+
+	harness.send(workerRequest{ID: 4, Type: frameTypeShutdown})
+	if response := harness.receive(); response.Type != frameTypeShutdown {
+		t.Fatalf("response = %+v, want shutdown ack", response)
+	}
+	harness.wait()
+}
+
+func TestWorkerServerCancelsRunsByID(t *testing.T) {
+	t.Parallel()
+
+	harness := startWorkerHarness(t, func(ctx context.Context, projectPath string, snippet string, options execution.RunOptions) (execution.Result, error) {
+		options.OnStart(1)
+		<-ctx.Done()
+		return execution.Result{Canceled: true, ExitCode: -1}, nil
+	})
+
+	harness.send(workerRequest{ID: 1, Type: frameTypeHello, Hello: &helloRequest{MinVersion: MinWorkerProtocolVersion, MaxVersion: WorkerProtocolVersion}})
+	harness.receive()
+
+	harness.send(workerRequest{ID: 2, Type: frameTypeRun, Run: &runRequest{Source: "package main"}})
+	if started := harness.receive(); started.Type != frameTypeStarted || started.ID != 2 {
+		t.Fatalf("response = %+v, want started for id 2", started)
+	}
+	harness.send(workerRequest{ID: 3, Type: frameTypeRun, Run: &runRequest{Source: "package main"}})
+	harness.send(workerRequest{ID: 4, Type: frameTypePing})
+	if pong := harness.receive(); pong.Type != frameTypePong || pong.ID != 4 {
+		t.Fatalf("response = %+v, want pong while a run is in progress", pong)
+	}
+
+	// Canceling the queued run answers it at once with a classified error.
+	harness.send(workerRequest{ID: 5, Type: frameTypeCancel, Cancel: 3})
+	queued := harness.receive()
+	if queued.Type != frameTypeError || queued.ID != 3 || queued.ErrorKind != errorKindCanceled {
+		t.Fatalf("response = %+v, want canceled error for id 3", queued)
+	}
+	if err := expectFrame(queued, frameTypeResult); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expectFrame() error = %v, want context.Canceled", err)
+	}
+
+	harness.send(workerRequest{ID: 6, Type: frameTypeCancel, Cancel: 2})
+	running := harness.receive()
+	if running.Type != frameTypeResult || running.ID != 2 || running.Result == nil || !running.Result.Canceled {
+		t.Fatalf("response = %+v, want canceled result for id 2", running)
+	}
+
+	_ = harness.requests.Close()
+	harness.wait()
+}
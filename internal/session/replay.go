@@ -0,0 +1,86 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"gopoke/internal/execution"
+)
+
+// Step is one playback step with the editor state after the event applied.
+type Step struct {
+	Index         int               `json:"index"`
+	Event         Event             `json:"event"`
+	Description   string            `json:"description"`
+	ActiveProject string            `json:"activeProject"`
+	ActiveFile    string            `json:"activeFile"`
+	Source        string            `json:"source"`
+	Replayed      *execution.Result `json:"replayed,omitempty"`
+	Matches       *bool             `json:"matches,omitempty"`
+}
+
+// Replay turns recorded events into playback steps in sequence order.
+func Replay(events []Event) []Step {
+	steps := make([]Step, 0, len(events))
+	activeProject := ""
+	activeFile := ""
+	source := ""
+
+	for index, event := range events {
+		if event.ProjectPath != "" {
+			activeProject = event.ProjectPath
+		}
+		description := ""
+		switch event.Kind {
+		case KindOpenProject:
+			description = fmt.Sprintf("Opened project %s", event.ProjectPath)
+		case KindOpenFile:
+			activeFile = event.FilePath
+			source = event.Content
+			description = fmt.Sprintf("Opened %s", filepath.Base(event.FilePath))
+		case KindSaveFile:
+			activeFile = event.FilePath
+			source = event.Content
+			description = fmt.Sprintf("Saved %s (%d bytes)", filepath.Base(event.FilePath), len(event.Content))
+		case KindSaveSnippet:
+			source = event.Content
+			description = fmt.Sprintf("Saved snippet %q (%d bytes)", event.Name, len(event.Content))
+		case KindRun:
+			source = event.Content
+			description = describeRun(event.Result)
+		default:
+			description = fmt.Sprintf("Unknown action %q", event.Kind)
+		}
+
+		steps = append(steps, Step{
+			Index:         index + 1,
+			Event:         event,
+			Description:   description,
+			ActiveProject: activeProject,
+			ActiveFile:    activeFile,
+			Source:        source,
+		})
+	}
+	return steps
+}
+
+// SameOutcome reports whether a replayed run reproduced the recorded one.
+func SameOutcome(recorded execution.Result, replayed execution.Result) bool {
+	return recorded.ExitCode == replayed.ExitCode &&
+		recorded.TimedOut == replayed.TimedOut &&
+		recorded.Stdout == replayed.Stdout
+}
+
+func describeRun(result *execution.Result) string {
+	if result == nil {
+		return "Ran snippet"
+	}
+	switch {
+	case result.TimedOut:
+		return fmt.Sprintf("Ran snippet: timed out after %d ms", result.DurationMS)
+	case result.Canceled:
+		return fmt.Sprintf("Ran snippet: canceled after %d ms", result.DurationMS)
+	default:
+		return fmt.Sprintf("Ran snippet: exit code %d in %d ms", result.ExitCode, result.DurationMS)
+	}
+}
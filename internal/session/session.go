@@ -0,0 +1,199 @@
+// Package session records a work session's actions to a JSON Lines file and
+// rebuilds them into step-by-step playback.
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopoke/internal/execution"
+)
+
+// FormatVersion identifies the session file layout.
+const FormatVersion = 1
+
+// FileExtension is appended to recorded session files.
+const FileExtension = ".gopoke-session.jsonl"
+
+// maxLineBytes bounds one recorded line; run output is already capped upstream.
+const maxLineBytes = 16 * 1024 * 1024
+
+// Event kinds captured by the recorder.
+const (
+	KindOpenProject = "open_project"
+	KindOpenFile    = "open_file"
+	KindSaveFile    = "save_file"
+	KindSaveSnippet = "save_snippet"
+	KindRun         = "run"
+)
+
+// Header is the first line of a session file.
+type Header struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// Event is one recorded user action. Content holds the file or snippet
+// source at the save point, or the source that was run.
+type Event struct {
+	Seq         int               `json:"seq"`
+	Time        time.Time         `json:"time"`
+	Kind        string            `json:"kind"`
+	ProjectPath string            `json:"projectPath,omitempty"`
+	FilePath    string            `json:"filePath,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Content     string            `json:"content,omitempty"`
+	Result      *execution.Result `json:"result,omitempty"`
+}
+
+// Recorder appends events to a session file. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	path   string
+	seq    int
+	now    func() time.Time
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Start creates a new session file in dir and writes its header.
+func Start(dir string, name string) (*Recorder, error) {
+	if strings.TrimSpace(dir) == "" {
+		return nil, fmt.Errorf("session directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create session directory: %w", err)
+	}
+
+	startedAt := time.Now().UTC()
+	slug := strings.Trim(unsafeNameChars.ReplaceAllString(strings.TrimSpace(name), "-"), "-")
+	fileName := startedAt.Format("20060102-150405")
+	if slug != "" {
+		fileName += "-" + slug
+	}
+	path := filepath.Join(dir, fileName+FileExtension)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("create session file: %w", err)
+	}
+	recorder := &Recorder{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		path:   path,
+		now:    func() time.Time { return time.Now().UTC() },
+	}
+	if err := recorder.writeLine(Header{Version: FormatVersion, Name: name, StartedAt: startedAt}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// Path returns the session file path.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Record appends one event, assigning its sequence number and timestamp.
+func (r *Recorder) Record(event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return fmt.Errorf("session recorder is closed")
+	}
+	r.seq++
+	event.Seq = r.seq
+	if event.Time.IsZero() {
+		event.Time = r.now()
+	}
+	return r.writeLine(event)
+}
+
+// Close flushes and closes the session file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	flushErr := r.writer.Flush()
+	closeErr := r.file.Close()
+	r.file = nil
+	if flushErr != nil {
+		return fmt.Errorf("flush session file: %w", flushErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close session file: %w", closeErr)
+	}
+	return nil
+}
+
+// writeLine encodes value as one line and flushes so a crash loses at most
+// the event being written.
+func (r *Recorder) writeLine(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode session line: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := r.writer.Write(data); err != nil {
+		return fmt.Errorf("write session line: %w", err)
+	}
+	if err := r.writer.Flush(); err != nil {
+		return fmt.Errorf("flush session line: %w", err)
+	}
+	return nil
+}
+
+// Load reads a session file written by Recorder.
+func Load(path string) (Header, []Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Header{}, nil, fmt.Errorf("open session file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return Header{}, nil, fmt.Errorf("read session header: %w", err)
+		}
+		return Header{}, nil, errors.New("session file is empty")
+	}
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return Header{}, nil, fmt.Errorf("decode session header: %w", err)
+	}
+	if header.Version < 1 || header.Version > FormatVersion {
+		return Header{}, nil, fmt.Errorf("unsupported session version %d", header.Version)
+	}
+
+	events := make([]Event, 0)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return Header{}, nil, fmt.Errorf("decode session event %d: %w", len(events)+1, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return Header{}, nil, fmt.Errorf("read session file: %w", err)
+	}
+	return header, events, nil
+}
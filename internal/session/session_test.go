@@ -0,0 +1,99 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopoke/internal/execution"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	recorder, err := Start(dir, "bug repro: panic/nil")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !strings.HasSuffix(recorder.Path(), "-bug-repro-panic-nil"+FileExtension) {
+		t.Fatalf("Path() = %q, want sanitized name", recorder.Path())
+	}
+
+	events := []Event{
+		{Kind: KindOpenProject, ProjectPath: "/work/demo"},
+		{Kind: KindOpenFile, FilePath: "/work/demo/main.go", Content: "package main\n"},
+		{Kind: KindRun, ProjectPath: "/work/demo", Content: "package main\n", Result: &execution.Result{ExitCode: 2, Stdout: "boom"}},
+	}
+	for _, event := range events {
+		if err := recorder.Record(event); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := recorder.Record(Event{Kind: KindRun}); err == nil {
+		t.Fatal("Record() after Close() error = nil, want error")
+	}
+
+	header, loaded, err := Load(recorder.Path())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, want := header.Name, "bug repro: panic/nil"; got != want {
+		t.Fatalf("header.Name = %q, want %q", got, want)
+	}
+	if got, want := len(loaded), len(events); got != want {
+		t.Fatalf("len(events) = %d, want %d", got, want)
+	}
+	for i, event := range loaded {
+		if event.Seq != i+1 {
+			t.Fatalf("events[%d].Seq = %d, want %d", i, event.Seq, i+1)
+		}
+		if event.Time.IsZero() {
+			t.Fatalf("events[%d].Time is zero", i)
+		}
+	}
+	if got, want := loaded[2].Result.Stdout, "boom"; got != want {
+		t.Fatalf("run stdout = %q, want %q", got, want)
+	}
+}
+
+func TestLoadRejectsUnsupportedVersion(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "future"+FileExtension)
+	if err := os.WriteFile(path, []byte(`{"version":99}`+"\n"), 0o644); err != nil {
+		t.Fatalf("write session: %v", err)
+	}
+	if _, _, err := Load(path); err == nil || !strings.Contains(err.Error(), "unsupported session version") {
+		t.Fatalf("Load() error = %v, want unsupported version", err)
+	}
+}
+
+func TestReplayTracksEditorState(t *testing.T) {
+	t.Parallel()
+
+	steps := Replay([]Event{
+		{Seq: 1, Kind: KindOpenProject, ProjectPath: "/work/demo"},
+		{Seq: 2, Kind: KindOpenFile, FilePath: "/work/demo/main.go", Content: "v1"},
+		{Seq: 3, Kind: KindSaveFile, FilePath: "/work/demo/main.go", Content: "v2"},
+		{Seq: 4, Kind: KindRun, Content: "v2", Result: &execution.Result{ExitCode: 0, DurationMS: 12}},
+	})
+
+	if got, want := len(steps), 4; got != want {
+		t.Fatalf("len(steps) = %d, want %d", got, want)
+	}
+	if got, want := steps[1].Source, "v1"; got != want {
+		t.Fatalf("steps[1].Source = %q, want %q", got, want)
+	}
+	last := steps[3]
+	if last.ActiveProject != "/work/demo" || last.ActiveFile != "/work/demo/main.go" || last.Source != "v2" {
+		t.Fatalf("last step state = %+v", last)
+	}
+	if got, want := last.Description, "Ran snippet: exit code 0 in 12 ms"; got != want {
+		t.Fatalf("last.Description = %q, want %q", got, want)
+	}
+}
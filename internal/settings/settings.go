@@ -1,5 +1,13 @@
 package settings
 
+import (
+	"fmt"
+	"strings"
+
+	"gopoke/internal/i18n"
+	"gopoke/internal/textnorm"
+)
+
 // GlobalSettings stores app-wide configuration persisted across sessions.
 type GlobalSettings struct {
 	GoPath             string `json:"goPath"`          // Path to go binary (e.g. /usr/local/go/bin/go). Empty = auto-detect.
@@ -7,31 +15,139 @@ type GlobalSettings struct {
 	StaticcheckPath    string `json:"staticcheckPath"` // Path to staticcheck binary. Empty = auto-detect.
 	DefaultTimeoutMS   int64  `json:"defaultTimeoutMS"`
 	MaxOutputBytes     int64  `json:"maxOutputBytes"`
+	DefaultTimeoutSet  bool   `json:"defaultTimeoutSet"` // The user chose DefaultTimeoutMS; until then runs use the built-in timeout.
+	MaxOutputSet       bool   `json:"maxOutputSet"`      // The user chose MaxOutputBytes; until then runs use the built-in output cap.
 	GoPathOverride     string `json:"goPathOverride"`
 	GoModCacheOverride string `json:"goModCacheOverride"`
 	EditorTheme        string `json:"editorTheme"`
 	EditorFontFamily   string `json:"editorFontFamily"`
 	EditorFontSize     int    `json:"editorFontSize"`
 	EditorLineNumbers  bool   `json:"editorLineNumbers"`
+
+	WorkerMaxCount               int   `json:"workerMaxCount"`      // Maximum concurrent project workers; least-recently-used are evicted.
+	WorkerMaxLifetimeMS          int64 `json:"workerMaxLifetimeMS"` // Recycle workers older than this. 0 = unlimited.
+	WorkerRestartOnProjectChange bool  `json:"workerRestartOnProjectChange"`
+
+	UpdateChannel string `json:"updateChannel"` // "stable" or "beta".
+
+	Locale string `json:"locale"` // UI and backend message locale, e.g. "en" or "es".
+
+	PlainTextOutput bool `json:"plainTextOutput"` // Accessible mode: no rich blocks or ANSI, linear output.
+
+	GoplsMemoryLimitMB        int64 `json:"goplsMemoryLimitMB"`        // Warn when gopls resident memory exceeds this.
+	GoplsDegradeOnMemoryLimit bool  `json:"goplsDegradeOnMemoryLimit"` // Restart gopls with memoryMode=DegradeClosed past the limit.
+
+	ExecutionBackend string `json:"executionBackend"` // "go", or "fake" for scripted runs without a toolchain.
+
+	MonitorRunNetwork bool `json:"monitorRunNetwork"` // Report remote hosts and ports that running snippets connect to.
+	PromptRunNetwork  bool `json:"promptRunNetwork"`  // Pause runs once they connect to a host the project has not allowed and ask. Detected after the connection opens; Linux and macOS only.
+
+	LowPriorityRuns bool `json:"lowPriorityRuns"` // Run snippets at reduced CPU and I/O priority unless a run asks otherwise.
+
+	MaxDiskWriteBytes int64 `json:"maxDiskWriteBytes"` // Stop runs that write more than this to disk. 0 = unlimited.
+	MaxRunProcesses   int64 `json:"maxRunProcesses"`   // Stop runs with more descendant processes than this. 0 = unlimited.
+	MaxRunOpenFiles   int64 `json:"maxRunOpenFiles"`   // File descriptor limit for run processes (Linux). 0 = unlimited.
+
+	LineEndings        string `json:"lineEndings"`        // "preserve" keeps each file's convention; "lf" or "crlf" force one on save.
+	StripBOM           bool   `json:"stripBOM"`           // Drop UTF-8 byte order marks when saving.
+	EnsureFinalNewline bool   `json:"ensureFinalNewline"` // End saved files with a line break.
+
+	OTLPEndpoint string `json:"otlpEndpoint"` // OTLP/HTTP collector for run and LSP spans, e.g. http://localhost:4318. Empty = export off.
+
+	IdlePauseMinutes int `json:"idlePauseMinutes"` // Pause background work after this long without interaction. 0 = default, -1 = never.
+
+	PowerSaving       string `json:"powerSaving"`       // "auto" reduces background work on battery; "always" or "never" override detection.
+	BatteryMaxWorkers int    `json:"batteryMaxWorkers"` // Worker pool cap while power saving. 0 = default.
+
+	SnippetCacheLocation string `json:"snippetCacheLocation"` // "project" keeps generated snippet files in each project; "data" keeps them in the data directory, where snippets cannot import the project's internal packages.
+	SnippetCacheMaxMB    int64  `json:"snippetCacheMaxMB"`    // Evict the least recently used snippet files past this total across projects.
 }
 
 const (
-	DefaultTimeoutMS  = int64(30000)
-	DefaultMaxOutput  = int64(1_048_576)
+	// The run limit defaults match execution.DefaultTimeout and
+	// execution.DefaultMaxOutputBytes, so runs keep the built-in limits until
+	// the user changes them.
+	DefaultTimeoutMS = int64(15000)
+	DefaultMaxOutput = int64(131_072)
+	// LegacyTimeoutMS and LegacyMaxOutput are the defaults stored by
+	// versions whose runs ignored these settings.
+	LegacyTimeoutMS   = int64(30000)
+	LegacyMaxOutput   = int64(1_048_576)
 	DefaultFontFamily = "JetBrains Mono"
 	DefaultFontSize   = 14
 	DefaultTheme      = "Default Dark Modern"
+	DefaultMaxWorkers = 4
+	MaxWorkersLimit   = 32
+
+	UpdateChannelStable = "stable"
+	UpdateChannelBeta   = "beta"
+
+	ExecutionBackendGo   = "go"
+	ExecutionBackendFake = "fake"
+
+	DefaultLocale = i18n.DefaultLocale
+
+	DefaultGoplsMemoryMB = int64(2048)
+	MinGoplsMemoryMB     = int64(256)
+	MaxGoplsMemoryMB     = int64(65536)
+
+	// Run limit bounds shared by global settings and per-project overrides.
+	MinTimeoutMS      = int64(1000)
+	MaxTimeoutMS      = int64(300000)
+	MinOutputBytes    = int64(1024)
+	MaxOutputBytesCap = int64(10_485_760)
+	MinDiskWriteBytes = int64(67_108_864)
+	// The go command and its compilers run under the same limits, so
+	// lower values would stop builds.
+	MinRunProcesses = int64(32)
+	MinRunOpenFiles = int64(256)
+
+	DefaultIdlePauseMinutes = 10
+	MaxIdlePauseMinutes     = 240
+	IdlePauseNever          = -1
+
+	PowerSavingAuto   = "auto"
+	PowerSavingAlways = "always"
+	PowerSavingNever  = "never"
+
+	DefaultBatteryMaxWorkers = 1
+	// BatteryIdlePauseMinutes caps the idle pause while power saving.
+	BatteryIdlePauseMinutes = 2
+
+	// SnippetCacheProject keeps generated snippet files in each project's
+	// run cache directory; SnippetCacheData keeps them under the data
+	// directory, one subdirectory per project.
+	SnippetCacheProject = "project"
+	SnippetCacheData    = "data"
+
+	// Snippet cache size budget bounds, in megabytes, for generated snippet
+	// files across all projects.
+	DefaultSnippetCacheMB = int64(256)
+	MinSnippetCacheMB     = int64(1)
+	MaxSnippetCacheMB     = int64(65536)
 )
 
 // Defaults returns GlobalSettings with sensible defaults.
 func Defaults() GlobalSettings {
 	return GlobalSettings{
-		DefaultTimeoutMS:  DefaultTimeoutMS,
-		MaxOutputBytes:    DefaultMaxOutput,
-		EditorTheme:       DefaultTheme,
-		EditorFontFamily:  DefaultFontFamily,
-		EditorFontSize:    DefaultFontSize,
-		EditorLineNumbers: true,
+		DefaultTimeoutMS:   DefaultTimeoutMS,
+		MaxOutputBytes:     DefaultMaxOutput,
+		EditorTheme:        DefaultTheme,
+		EditorFontFamily:   DefaultFontFamily,
+		EditorFontSize:     DefaultFontSize,
+		EditorLineNumbers:  true,
+		WorkerMaxCount:     DefaultMaxWorkers,
+		UpdateChannel:      UpdateChannelStable,
+		Locale:             DefaultLocale,
+		GoplsMemoryLimitMB: DefaultGoplsMemoryMB,
+		ExecutionBackend:   ExecutionBackendGo,
+		LineEndings:        textnorm.LineEndingPreserve,
+		IdlePauseMinutes:   DefaultIdlePauseMinutes,
+		PowerSaving:        PowerSavingAuto,
+		BatteryMaxWorkers:  DefaultBatteryMaxWorkers,
+
+		SnippetCacheLocation: SnippetCacheProject,
+		SnippetCacheMaxMB:    DefaultSnippetCacheMB,
 	}
 }
 
@@ -53,25 +169,66 @@ func WithDefaults(s GlobalSettings) GlobalSettings {
 	if s.EditorFontSize <= 0 {
 		s.EditorFontSize = d.EditorFontSize
 	}
+	if s.WorkerMaxCount <= 0 {
+		s.WorkerMaxCount = d.WorkerMaxCount
+	}
+	if s.UpdateChannel == "" {
+		s.UpdateChannel = d.UpdateChannel
+	}
+	if s.Locale == "" {
+		s.Locale = d.Locale
+	}
+	if s.GoplsMemoryLimitMB <= 0 {
+		s.GoplsMemoryLimitMB = d.GoplsMemoryLimitMB
+	}
+	if s.ExecutionBackend == "" {
+		s.ExecutionBackend = d.ExecutionBackend
+	}
+	if s.LineEndings == "" {
+		s.LineEndings = d.LineEndings
+	}
+	if s.IdlePauseMinutes == 0 {
+		s.IdlePauseMinutes = d.IdlePauseMinutes
+	}
+	if s.PowerSaving == "" {
+		s.PowerSaving = d.PowerSaving
+	}
+	if s.BatteryMaxWorkers <= 0 {
+		s.BatteryMaxWorkers = d.BatteryMaxWorkers
+	}
+	if s.SnippetCacheLocation == "" {
+		s.SnippetCacheLocation = d.SnippetCacheLocation
+	}
+	if s.SnippetCacheMaxMB <= 0 {
+		s.SnippetCacheMaxMB = d.SnippetCacheMaxMB
+	}
 	// EditorLineNumbers: bool defaults to false, but our default is true.
 	// We can't distinguish "user set false" from "zero value" without a pointer.
 	// So we only apply default on fresh/empty settings (all fields zero).
 	return s
 }
 
+// MarkChangedRunLimits flags the run limits in next that differ from
+// previous as chosen by the user. A flag, once set, stays set.
+func MarkChangedRunLimits(previous GlobalSettings, next GlobalSettings) GlobalSettings {
+	next.DefaultTimeoutSet = next.DefaultTimeoutSet || previous.DefaultTimeoutSet || next.DefaultTimeoutMS != previous.DefaultTimeoutMS
+	next.MaxOutputSet = next.MaxOutputSet || previous.MaxOutputSet || next.MaxOutputBytes != previous.MaxOutputBytes
+	return next
+}
+
 // Validate checks settings constraints and clamps values.
 func Validate(s GlobalSettings) GlobalSettings {
-	if s.DefaultTimeoutMS < 1000 {
-		s.DefaultTimeoutMS = 1000
+	if s.DefaultTimeoutMS < MinTimeoutMS {
+		s.DefaultTimeoutMS = MinTimeoutMS
 	}
-	if s.DefaultTimeoutMS > 300000 {
-		s.DefaultTimeoutMS = 300000
+	if s.DefaultTimeoutMS > MaxTimeoutMS {
+		s.DefaultTimeoutMS = MaxTimeoutMS
 	}
-	if s.MaxOutputBytes < 1024 {
-		s.MaxOutputBytes = 1024
+	if s.MaxOutputBytes < MinOutputBytes {
+		s.MaxOutputBytes = MinOutputBytes
 	}
-	if s.MaxOutputBytes > 10_485_760 {
-		s.MaxOutputBytes = 10_485_760
+	if s.MaxOutputBytes > MaxOutputBytesCap {
+		s.MaxOutputBytes = MaxOutputBytesCap
 	}
 	if s.EditorFontSize < 10 {
 		s.EditorFontSize = 10
@@ -79,5 +236,108 @@ func Validate(s GlobalSettings) GlobalSettings {
 	if s.EditorFontSize > 24 {
 		s.EditorFontSize = 24
 	}
+	if s.WorkerMaxCount < 1 {
+		s.WorkerMaxCount = 1
+	}
+	if s.WorkerMaxCount > MaxWorkersLimit {
+		s.WorkerMaxCount = MaxWorkersLimit
+	}
+	if s.WorkerMaxLifetimeMS < 0 {
+		s.WorkerMaxLifetimeMS = 0
+	}
+	if s.MaxDiskWriteBytes < 0 {
+		s.MaxDiskWriteBytes = 0
+	}
+	if s.MaxDiskWriteBytes > 0 && s.MaxDiskWriteBytes < MinDiskWriteBytes {
+		s.MaxDiskWriteBytes = MinDiskWriteBytes
+	}
+	s.MaxRunProcesses = clampOptionalLimit(s.MaxRunProcesses, MinRunProcesses)
+	s.MaxRunOpenFiles = clampOptionalLimit(s.MaxRunOpenFiles, MinRunOpenFiles)
+	if s.UpdateChannel != UpdateChannelBeta {
+		s.UpdateChannel = UpdateChannelStable
+	}
+	if s.ExecutionBackend != ExecutionBackendFake {
+		s.ExecutionBackend = ExecutionBackendGo
+	}
+	if s.LineEndings != textnorm.LineEndingLF && s.LineEndings != textnorm.LineEndingCRLF {
+		s.LineEndings = textnorm.LineEndingPreserve
+	}
+	s.Locale = i18n.Resolve(s.Locale)
+	s.OTLPEndpoint = strings.TrimSpace(s.OTLPEndpoint)
+	if s.GoplsMemoryLimitMB < MinGoplsMemoryMB {
+		s.GoplsMemoryLimitMB = MinGoplsMemoryMB
+	}
+	if s.GoplsMemoryLimitMB > MaxGoplsMemoryMB {
+		s.GoplsMemoryLimitMB = MaxGoplsMemoryMB
+	}
+	if s.IdlePauseMinutes < 0 {
+		s.IdlePauseMinutes = IdlePauseNever
+	}
+	if s.IdlePauseMinutes > MaxIdlePauseMinutes {
+		s.IdlePauseMinutes = MaxIdlePauseMinutes
+	}
+	if s.PowerSaving != PowerSavingAlways && s.PowerSaving != PowerSavingNever {
+		s.PowerSaving = PowerSavingAuto
+	}
+	if s.BatteryMaxWorkers < 1 {
+		s.BatteryMaxWorkers = 1
+	}
+	if s.BatteryMaxWorkers > MaxWorkersLimit {
+		s.BatteryMaxWorkers = MaxWorkersLimit
+	}
+	if s.SnippetCacheLocation != SnippetCacheData {
+		s.SnippetCacheLocation = SnippetCacheProject
+	}
+	s.SnippetCacheMaxMB = min(max(s.SnippetCacheMaxMB, MinSnippetCacheMB), MaxSnippetCacheMB)
 	return s
 }
+
+// ValidateTimeoutMS rejects run timeouts outside the supported bounds.
+// Unlike Validate it does not clamp, so explicit overrides fail loudly.
+func ValidateTimeoutMS(timeoutMS int64) error {
+	if timeoutMS < MinTimeoutMS || timeoutMS > MaxTimeoutMS {
+		return fmt.Errorf("timeout must be between %d and %d ms, got %d", MinTimeoutMS, MaxTimeoutMS, timeoutMS)
+	}
+	return nil
+}
+
+// ValidateMaxOutputBytes rejects output caps outside the supported bounds.
+func ValidateMaxOutputBytes(maxOutputBytes int64) error {
+	if maxOutputBytes < MinOutputBytes || maxOutputBytes > MaxOutputBytesCap {
+		return fmt.Errorf("output cap must be between %d and %d bytes, got %d", MinOutputBytes, MaxOutputBytesCap, maxOutputBytes)
+	}
+	return nil
+}
+
+// ValidateMaxDiskWriteBytes rejects disk write quotas below the minimum.
+func ValidateMaxDiskWriteBytes(maxDiskWriteBytes int64) error {
+	if maxDiskWriteBytes < MinDiskWriteBytes {
+		return fmt.Errorf("disk write quota must be at least %d bytes, got %d", MinDiskWriteBytes, maxDiskWriteBytes)
+	}
+	return nil
+}
+
+// ValidateMaxRunProcesses rejects child process limits below the minimum.
+func ValidateMaxRunProcesses(maxProcesses int64) error {
+	if maxProcesses < MinRunProcesses {
+		return fmt.Errorf("process limit must be at least %d, got %d", MinRunProcesses, maxProcesses)
+	}
+	return nil
+}
+
+// ValidateMaxRunOpenFiles rejects file descriptor limits below the minimum.
+func ValidateMaxRunOpenFiles(maxOpenFiles int64) error {
+	if maxOpenFiles < MinRunOpenFiles {
+		return fmt.Errorf("open file limit must be at least %d, got %d", MinRunOpenFiles, maxOpenFiles)
+	}
+	return nil
+}
+
+// clampOptionalLimit keeps zero as "unlimited" and raises other values to
+// minimum.
+func clampOptionalLimit(value int64, minimum int64) int64 {
+	if value <= 0 {
+		return 0
+	}
+	return max(value, minimum)
+}
@@ -1,6 +1,10 @@
 package settings
 
-import "testing"
+import (
+	"testing"
+
+	"gopoke/internal/textnorm"
+)
 
 func TestDefaults(t *testing.T) {
 	t.Parallel()
@@ -34,6 +38,12 @@ func TestWithDefaultsFillsZeroValues(t *testing.T) {
 	if s.EditorTheme != "Default Dark Modern" {
 		t.Fatalf("theme = %q, want %q", s.EditorTheme, "Default Dark Modern")
 	}
+	if s.WorkerMaxCount != DefaultMaxWorkers {
+		t.Fatalf("workerMaxCount = %d, want %d", s.WorkerMaxCount, DefaultMaxWorkers)
+	}
+	if s.GoplsMemoryLimitMB != DefaultGoplsMemoryMB {
+		t.Fatalf("goplsMemoryLimitMB = %d, want %d", s.GoplsMemoryLimitMB, DefaultGoplsMemoryMB)
+	}
 }
 
 func TestWithDefaultsPreservesUserValues(t *testing.T) {
@@ -84,6 +94,15 @@ func TestValidateClampsValues(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "snippet cache out of range",
+			input: GlobalSettings{SnippetCacheLocation: "elsewhere", SnippetCacheMaxMB: MaxSnippetCacheMB + 1},
+			check: func(t *testing.T, s GlobalSettings) {
+				if s.SnippetCacheLocation != SnippetCacheProject || s.SnippetCacheMaxMB != MaxSnippetCacheMB {
+					t.Fatalf("snippet cache = %q, %d MB; want %q, %d MB", s.SnippetCacheLocation, s.SnippetCacheMaxMB, SnippetCacheProject, MaxSnippetCacheMB)
+				}
+			},
+		},
 		{
 			name:  "font size too small",
 			input: GlobalSettings{EditorFontSize: 5},
@@ -120,6 +139,69 @@ func TestValidateClampsValues(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:  "worker count too large",
+			input: GlobalSettings{WorkerMaxCount: 1000},
+			check: func(t *testing.T, s GlobalSettings) {
+				if s.WorkerMaxCount != MaxWorkersLimit {
+					t.Fatalf("workerMaxCount = %d, want %d", s.WorkerMaxCount, MaxWorkersLimit)
+				}
+			},
+		},
+		{
+			name:  "negative worker lifetime",
+			input: GlobalSettings{WorkerMaxCount: 2, WorkerMaxLifetimeMS: -5},
+			check: func(t *testing.T, s GlobalSettings) {
+				if s.WorkerMaxLifetimeMS != 0 {
+					t.Fatalf("workerMaxLifetimeMS = %d, want 0", s.WorkerMaxLifetimeMS)
+				}
+			},
+		},
+		{
+			name:  "unknown update channel",
+			input: GlobalSettings{WorkerMaxCount: 2, UpdateChannel: "nightly"},
+			check: func(t *testing.T, s GlobalSettings) {
+				if s.UpdateChannel != UpdateChannelStable {
+					t.Fatalf("updateChannel = %q, want %q", s.UpdateChannel, UpdateChannelStable)
+				}
+			},
+		},
+		{
+			name:  "regional locale resolves to catalog",
+			input: GlobalSettings{WorkerMaxCount: 2, Locale: "es-AR"},
+			check: func(t *testing.T, s GlobalSettings) {
+				if s.Locale != "es" {
+					t.Fatalf("locale = %q, want es", s.Locale)
+				}
+			},
+		},
+		{
+			name:  "unknown execution backend",
+			input: GlobalSettings{WorkerMaxCount: 2, ExecutionBackend: "remote"},
+			check: func(t *testing.T, s GlobalSettings) {
+				if s.ExecutionBackend != ExecutionBackendGo {
+					t.Fatalf("executionBackend = %q, want %q", s.ExecutionBackend, ExecutionBackendGo)
+				}
+			},
+		},
+		{
+			name:  "unknown line endings preserve",
+			input: GlobalSettings{WorkerMaxCount: 2, LineEndings: "cr"},
+			check: func(t *testing.T, s GlobalSettings) {
+				if s.LineEndings != textnorm.LineEndingPreserve {
+					t.Fatalf("lineEndings = %q, want %q", s.LineEndings, textnorm.LineEndingPreserve)
+				}
+			},
+		},
+		{
+			name:  "gopls memory limit too large",
+			input: GlobalSettings{WorkerMaxCount: 2, GoplsMemoryLimitMB: 1 << 20},
+			check: func(t *testing.T, s GlobalSettings) {
+				if s.GoplsMemoryLimitMB != MaxGoplsMemoryMB {
+					t.Fatalf("goplsMemoryLimitMB = %d, want %d", s.GoplsMemoryLimitMB, MaxGoplsMemoryMB)
+				}
+			},
+		},
 		{
 			name: "valid values unchanged",
 			input: GlobalSettings{
@@ -149,3 +231,62 @@ func TestValidateClampsValues(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRunLimitBounds(t *testing.T) {
+	t.Parallel()
+
+	for _, timeoutMS := range []int64{MinTimeoutMS, MaxTimeoutMS} {
+		if err := ValidateTimeoutMS(timeoutMS); err != nil {
+			t.Fatalf("ValidateTimeoutMS(%d) error = %v", timeoutMS, err)
+		}
+	}
+	for _, timeoutMS := range []int64{0, MinTimeoutMS - 1, MaxTimeoutMS + 1} {
+		if err := ValidateTimeoutMS(timeoutMS); err == nil {
+			t.Fatalf("ValidateTimeoutMS(%d) error = nil, want error", timeoutMS)
+		}
+	}
+	if err := ValidateMaxOutputBytes(MinOutputBytes - 1); err == nil {
+		t.Fatal("ValidateMaxOutputBytes(below min) error = nil, want error")
+	}
+	if err := ValidateMaxOutputBytes(MaxOutputBytesCap); err != nil {
+		t.Fatalf("ValidateMaxOutputBytes(max) error = %v", err)
+	}
+	if err := ValidateMaxDiskWriteBytes(MinDiskWriteBytes - 1); err == nil {
+		t.Fatal("ValidateMaxDiskWriteBytes(below min) error = nil, want error")
+	}
+	if got := Validate(GlobalSettings{MaxDiskWriteBytes: 1}).MaxDiskWriteBytes; got != MinDiskWriteBytes {
+		t.Fatalf("Validate() MaxDiskWriteBytes = %d, want %d", got, MinDiskWriteBytes)
+	}
+	if got := Validate(GlobalSettings{MaxDiskWriteBytes: -1}).MaxDiskWriteBytes; got != 0 {
+		t.Fatalf("Validate() MaxDiskWriteBytes = %d, want 0", got)
+	}
+	if err := ValidateMaxRunProcesses(MinRunProcesses - 1); err == nil {
+		t.Fatal("ValidateMaxRunProcesses(below min) error = nil, want error")
+	}
+	if err := ValidateMaxRunOpenFiles(MinRunOpenFiles - 1); err == nil {
+		t.Fatal("ValidateMaxRunOpenFiles(below min) error = nil, want error")
+	}
+	clamped := Validate(GlobalSettings{MaxRunProcesses: 1, MaxRunOpenFiles: -5})
+	if clamped.MaxRunProcesses != MinRunProcesses || clamped.MaxRunOpenFiles != 0 {
+		t.Fatalf("Validate() process limits = %d, %d; want %d, 0", clamped.MaxRunProcesses, clamped.MaxRunOpenFiles, MinRunProcesses)
+	}
+}
+
+func TestMarkChangedRunLimits(t *testing.T) {
+	t.Parallel()
+
+	previous := Defaults()
+	if got := MarkChangedRunLimits(previous, previous); got.DefaultTimeoutSet || got.MaxOutputSet {
+		t.Fatalf("MarkChangedRunLimits(unchanged) = %+v, want limits unflagged", got)
+	}
+	next := previous
+	next.DefaultTimeoutMS = 20000
+	got := MarkChangedRunLimits(previous, next)
+	if !got.DefaultTimeoutSet || got.MaxOutputSet {
+		t.Fatalf("MarkChangedRunLimits(timeout changed) = %+v, want only the timeout flagged", got)
+	}
+	// Going back to the default is still a choice.
+	if again := MarkChangedRunLimits(got, previous); !again.DefaultTimeoutSet {
+		t.Fatal("MarkChangedRunLimits() cleared a flag")
+	}
+}
@@ -0,0 +1,313 @@
+// Package share serves a read-only view of one project's snippets and
+// runs over HTTP so teammates on the LAN can follow an experiment from a
+// browser. Every request needs the share token; the server only answers
+// GET and HEAD and has no route that runs code or changes state. Project
+// environment variables are never exposed, but run output is shown as
+// captured.
+package share
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopoke/internal/storage"
+)
+
+// DefaultAddress listens on every interface with an OS-assigned port.
+const DefaultAddress = ":0"
+
+// tokenCookie carries the token after a browser opens the share link.
+const tokenCookie = "gopoke_share"
+
+// View is the shared state of a project.
+type View struct {
+	ProjectPath string                  `json:"projectPath"`
+	ProjectName string                  `json:"projectName"`
+	Snippets    []storage.SnippetRecord `json:"snippets"`
+	Runs        []storage.RunRecord     `json:"runs"`
+	GeneratedAt time.Time               `json:"generatedAt"`
+}
+
+// RunOutput is the captured output of a recent run.
+type RunOutput struct {
+	RunID      string `json:"runId"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	ExitCode   int    `json:"exitCode"`
+	DurationMS int64  `json:"durationMs"`
+}
+
+// Source supplies what the server shows.
+type Source interface {
+	View(ctx context.Context) (View, error)
+	// RunOutput reports false when the run's output is no longer kept.
+	RunOutput(ctx context.Context, runID string) (RunOutput, bool, error)
+}
+
+// Info describes a running share.
+type Info struct {
+	Active      bool   `json:"active"`
+	ProjectPath string `json:"projectPath,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Token       string `json:"token,omitempty"`
+	// URLs open the share in a browser, token included.
+	URLs      []string  `json:"urls,omitempty"`
+	StartedAt time.Time `json:"startedAt,omitzero"`
+}
+
+// Server is a running share.
+type Server struct {
+	listener    net.Listener
+	server      *http.Server
+	source      Source
+	token       string
+	projectPath string
+	startedAt   time.Time
+	logger      *slog.Logger
+}
+
+// Start listens on address and serves source in the background.
+func Start(address string, projectPath string, source Source, logger *slog.Logger) (*Server, error) {
+	if strings.TrimSpace(address) == "" {
+		address = DefaultAddress
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	s := &Server{
+		listener:    listener,
+		source:      source,
+		token:       token,
+		projectPath: projectPath,
+		startedAt:   time.Now().UTC(),
+		logger:      logger,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handlePage)
+	mux.HandleFunc("GET /runs/{id}", s.handleRunPage)
+	mux.HandleFunc("GET /api/view", s.handleView)
+	mux.HandleFunc("GET /api/runs/{id}", s.handleRunOutput)
+	s.server = &http.Server{Handler: s.guard(mux), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Warn("project share stopped", "error", err)
+		}
+	}()
+	return s, nil
+}
+
+// Info describes the share.
+func (s *Server) Info() Info {
+	return Info{
+		Active:      true,
+		ProjectPath: s.projectPath,
+		Address:     s.listener.Addr().String(),
+		Token:       s.token,
+		URLs:        s.urls(),
+		StartedAt:   s.startedAt,
+	}
+}
+
+// Shutdown stops the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// urls lists a link per reachable address. A wildcard listener is reached
+// through the machine's non-loopback addresses.
+func (s *Server) urls() []string {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	port := strconv.Itoa(addr.Port)
+	hosts := make([]string, 0)
+	if addr.IP.IsUnspecified() {
+		if interfaceAddrs, err := net.InterfaceAddrs(); err == nil {
+			for _, interfaceAddr := range interfaceAddrs {
+				network, ok := interfaceAddr.(*net.IPNet)
+				if ok && !network.IP.IsLoopback() && !network.IP.IsLinkLocalUnicast() && network.IP.To4() != nil {
+					hosts = append(hosts, network.IP.String())
+				}
+			}
+		}
+		if len(hosts) == 0 {
+			hosts = append(hosts, "127.0.0.1")
+		}
+	} else {
+		hosts = append(hosts, addr.IP.String())
+	}
+	urls := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		urls = append(urls, "http://"+net.JoinHostPort(host, port)+"/?token="+s.token)
+	}
+	return urls
+}
+
+// guard enforces read-only methods and the token. A token in the query
+// string is moved into a cookie so it does not linger in the address bar.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("Cache-Control", "no-store")
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Referrer-Policy", "no-referrer")
+		header.Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			header.Set("Allow", "GET, HEAD")
+			http.Error(w, "project share is read-only", http.StatusMethodNotAllowed)
+			return
+		}
+		if queryToken := r.URL.Query().Get("token"); queryToken != "" && s.validToken(queryToken) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     tokenCookie,
+				Value:    queryToken,
+				Path:     "/",
+				HttpOnly: true,
+				SameSite: http.SameSiteStrictMode,
+			})
+			clean := *r.URL
+			query := clean.Query()
+			query.Del("token")
+			clean.RawQuery = query.Encode()
+			http.Redirect(w, r, clean.RequestURI(), http.StatusSeeOther)
+			return
+		}
+		if !s.authorized(r) {
+			http.Error(w, "share token required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && s.validToken(bearer) {
+		return true
+	}
+	cookie, err := r.Cookie(tokenCookie)
+	return err == nil && s.validToken(cookie.Value)
+}
+
+func (s *Server) validToken(candidate string) bool {
+	return subtle.ConstantTimeCompare([]byte(candidate), []byte(s.token)) == 1
+}
+
+func (s *Server) handleView(w http.ResponseWriter, r *http.Request) {
+	view, err := s.source.View(r.Context())
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	writeJSON(w, view)
+}
+
+func (s *Server) handleRunOutput(w http.ResponseWriter, r *http.Request) {
+	output, ok, err := s.source.RunOutput(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	if !ok {
+		http.Error(w, "run output is no longer available", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, output)
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	view, err := s.source.View(r.Context())
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pageTemplate.Execute(w, view); err != nil {
+		s.logger.Warn("render project share page failed", "error", err)
+	}
+}
+
+func (s *Server) handleRunPage(w http.ResponseWriter, r *http.Request) {
+	output, ok, err := s.source.RunOutput(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.fail(w, err)
+		return
+	}
+	if !ok {
+		http.Error(w, "run output is no longer available", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := runTemplate.Execute(w, output); err != nil {
+		s.logger.Warn("render project share run page failed", "error", err)
+	}
+}
+
+func (s *Server) fail(w http.ResponseWriter, err error) {
+	s.logger.Warn("project share request failed", "error", err)
+	http.Error(w, "project state unavailable", http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
+
+func newToken() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate share token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+const pageStyle = `body{font-family:system-ui,sans-serif;margin:2rem;color:#1f2328}
+pre{background:#f6f8fa;padding:.75rem;overflow:auto}
+table{border-collapse:collapse}td,th{padding:.25rem .75rem;text-align:left;border-bottom:1px solid #d0d7de}`
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><meta http-equiv="refresh" content="5">
+<title>{{.ProjectName}} · gopoke</title><style>` + pageStyle + `</style></head>
+<body>
+<h1>{{.ProjectName}}</h1>
+<p>Read-only view of {{.ProjectPath}}, refreshed {{.GeneratedAt.Format "15:04:05"}}.</p>
+<h2>Runs</h2>
+{{if .Runs}}<table><tr><th>Started</th><th>Status</th><th>Exit</th><th>Duration</th></tr>
+{{range .Runs}}<tr><td><a href="/runs/{{.ID}}">{{.StartedAt.Format "2006-01-02 15:04:05"}}</a></td><td>{{.Status}}</td><td>{{.ExitCode}}</td><td>{{.DurationMS}} ms</td></tr>
+{{end}}</table>{{else}}<p>No runs yet.</p>{{end}}
+<h2>Snippets</h2>
+{{range .Snippets}}<h3>{{.Name}}</h3><pre>{{.Content}}</pre>
+{{else}}<p>No saved snippets.</p>{{end}}
+</body></html>
+`))
+
+var runTemplate = template.Must(template.New("run").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>Run {{.RunID}} · gopoke</title><style>` + pageStyle + `</style></head>
+<body>
+<p><a href="/">Back</a></p>
+<h1>Run {{.RunID}}</h1>
+<p>Exit code {{.ExitCode}} after {{.DurationMS}} ms.</p>
+<h2>Stdout</h2><pre>{{.Stdout}}</pre>
+<h2>Stderr</h2><pre>{{.Stderr}}</pre>
+</body></html>
+`))
@@ -0,0 +1,129 @@
+package share
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+
+	"gopoke/internal/storage"
+)
+
+type staticSource struct {
+	view    View
+	outputs map[string]RunOutput
+}
+
+func (s staticSource) View(ctx context.Context) (View, error) {
+	return s.view, nil
+}
+
+func (s staticSource) RunOutput(ctx context.Context, runID string) (RunOutput, bool, error) {
+	output, ok := s.outputs[runID]
+	return output, ok, nil
+}
+
+func startTestServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	source := staticSource{
+		view: View{
+			ProjectName: "demo",
+			Snippets:    []storage.SnippetRecord{{ID: "sn_1", Name: "probe", Content: "<script>alert(1)</script>"}},
+			Runs:        []storage.RunRecord{{ID: "run_1", Status: "success"}},
+		},
+		outputs: map[string]RunOutput{"run_1": {RunID: "run_1", Stdout: "hello"}},
+	}
+	server, err := Start("127.0.0.1:0", "/work/demo", source, nil)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { server.Shutdown(context.Background()) })
+	return server, "http://" + server.Info().Address
+}
+
+func TestServerRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	server, base := startTestServer(t)
+	response, err := http.Get(base + "/api/view")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status without token = %d, want 401", response.StatusCode)
+	}
+
+	request, _ := http.NewRequest(http.MethodGet, base+"/api/runs/run_1", nil)
+	request.Header.Set("Authorization", "Bearer "+server.Info().Token)
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer response.Body.Close()
+	var output RunOutput
+	if err := json.NewDecoder(response.Body).Decode(&output); err != nil || output.Stdout != "hello" {
+		t.Fatalf("run output = %+v, %v; want hello", output, err)
+	}
+}
+
+func TestServerIsReadOnly(t *testing.T) {
+	t.Parallel()
+
+	server, base := startTestServer(t)
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		request, _ := http.NewRequest(method, base+"/api/view", strings.NewReader("{}"))
+		request.Header.Set("Authorization", "Bearer "+server.Info().Token)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusMethodNotAllowed {
+			t.Fatalf("%s status = %d, want 405", method, response.StatusCode)
+		}
+	}
+}
+
+func TestServerShareLinkSetsCookieAndEscapesContent(t *testing.T) {
+	t.Parallel()
+
+	server, base := startTestServer(t)
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	response, err := client.Get(base + "/?token=" + server.Info().Token)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Request.URL.RawQuery != "" {
+		t.Fatalf("share link = %d at %s, want 200 without token in the URL", response.StatusCode, response.Request.URL)
+	}
+	page := string(body)
+	if !strings.Contains(page, "&lt;script&gt;") || strings.Contains(page, "<script>") {
+		t.Fatalf("page does not escape snippet content:\n%s", page)
+	}
+
+	response, err = client.Get(base + "/runs/run_1")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("run page status = %d, want 200 with cookie", response.StatusCode)
+	}
+}
+
+func TestServerInfoURLs(t *testing.T) {
+	t.Parallel()
+
+	server, _ := startTestServer(t)
+	info := server.Info()
+	if !info.Active || len(info.URLs) != 1 || !strings.HasPrefix(info.URLs[0], "http://127.0.0.1:") || !strings.HasSuffix(info.URLs[0], "/?token="+info.Token) {
+		t.Fatalf("Info() = %+v, want one loopback URL with the token", info)
+	}
+}
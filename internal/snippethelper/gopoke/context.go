@@ -0,0 +1,36 @@
+package gopoke
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+)
+
+// deadlineEnv is set by gopoke to the run's timeout moment, as Unix seconds
+// with a millisecond fraction, when the run exports its deadline.
+const deadlineEnv = "GOPOKE_DEADLINE_UNIX"
+
+// deadlineMargin is how long before the timeout Context ends, leaving the
+// snippet time to stop before gopoke interrupts it.
+const deadlineMargin = 250 * time.Millisecond
+
+// Deadline returns the moment gopoke times the run out, if the run exports
+// its deadline.
+func Deadline() (time.Time, bool) {
+	seconds, err := strconv.ParseFloat(os.Getenv(deadlineEnv), 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(int64(seconds * 1000)), true
+}
+
+// Context returns a context that ends shortly before the run's deadline.
+// Without an exported deadline it ends only when cancel is called.
+func Context() (ctx context.Context, cancel context.CancelFunc) {
+	deadline, ok := Deadline()
+	if !ok {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), deadline.Add(-deadlineMargin))
+}
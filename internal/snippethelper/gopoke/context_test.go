@@ -0,0 +1,30 @@
+package gopoke
+
+import (
+	"testing"
+	"time"
+
+	"gopoke/internal/execution"
+)
+
+func TestContextEndsBeforeExportedDeadline(t *testing.T) {
+	if deadlineEnv != execution.EnvDeadline {
+		t.Fatalf("deadlineEnv = %q, want %q", deadlineEnv, execution.EnvDeadline)
+	}
+	deadline := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	t.Setenv(deadlineEnv, execution.FormatDeadline(deadline))
+
+	ctx, cancel := Context()
+	defer cancel()
+	got, ok := ctx.Deadline()
+	if !ok || !got.Equal(deadline.Add(-deadlineMargin)) {
+		t.Fatalf("Context() deadline = %v, %v; want %v", got, ok, deadline.Add(-deadlineMargin))
+	}
+
+	t.Setenv(deadlineEnv, "")
+	ctx, cancel = Context()
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("Context() without an exported deadline has a deadline")
+	}
+}
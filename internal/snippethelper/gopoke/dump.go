@@ -0,0 +1,236 @@
+// Package gopoke holds helpers for snippets run in gopoke. Dump prints a
+// value as a //gopoke:dump marker, which gopoke shows as an expandable tree
+// instead of a wall of %+v output. Measure reports the allocations and wall
+// time of a block. Context derives a context from the deadline a run
+// exports.
+//
+// The package uses only the standard library: gopoke copies its source into
+// the scratch module, where snippets import it as "gopoke".
+package gopoke
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+const (
+	maxDepth     = 8
+	maxItems     = 100
+	maxValueSize = 1000
+)
+
+// Node is one value in a dump tree.
+type Node struct {
+	// Name is the field name, map key or index of the value in its parent.
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+	Kind string `json:"kind"`
+	// Value is the printed value of scalars, strings and values with a
+	// String or Error method.
+	Value    string `json:"value,omitempty"`
+	Len      int    `json:"len,omitempty"`
+	Children []Node `json:"children,omitempty"`
+	// Truncated is set when children or a long value were cut short.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Dump prints v as an expandable tree.
+func Dump(v any) {
+	if err := Fdump(os.Stdout, v); err != nil {
+		fmt.Fprintf(os.Stderr, "gopoke.Dump: %v\n", err)
+	}
+}
+
+// Fdump writes the //gopoke:dump marker of v to w.
+func Fdump(w io.Writer, v any) error {
+	var payload bytes.Buffer
+	encoder := json.NewEncoder(&payload)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(Tree(v)); err != nil {
+		return err
+	}
+	// Encode ends the payload with the marker's line ending.
+	_, err := fmt.Fprintf(w, "//gopoke:dump %s", payload.Bytes())
+	return err
+}
+
+// Tree returns the dump tree of v. Nesting stops after a few levels,
+// containers list their first hundred items and pointer cycles are marked
+// rather than followed.
+func Tree(v any) Node {
+	return newDumper().node(reflect.ValueOf(v), 0)
+}
+
+type dumper struct {
+	visiting map[uintptr]bool
+}
+
+func newDumper() *dumper {
+	return &dumper{visiting: make(map[uintptr]bool)}
+}
+
+func (d *dumper) node(v reflect.Value, depth int) Node {
+	if !v.IsValid() {
+		return Node{Type: "nil", Kind: "nil", Value: "nil"}
+	}
+	node := Node{Type: v.Type().String(), Kind: v.Kind().String()}
+	if text, ok := printed(v); ok {
+		node.Value, node.Truncated = clip(text)
+		return node
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			node.Value = "nil"
+			return node
+		}
+		if v.Kind() == reflect.Pointer {
+			address := v.Pointer()
+			if d.visiting[address] {
+				node.Value = "<cycle>"
+				return node
+			}
+			d.visiting[address] = true
+			defer delete(d.visiting, address)
+		}
+		elem := d.node(v.Elem(), depth)
+		if v.Kind() == reflect.Pointer {
+			elem.Type = node.Type
+		}
+		return elem
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Map || v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				node.Value = "nil"
+				return node
+			}
+		}
+		if v.Kind() != reflect.Struct {
+			node.Len = v.Len()
+		}
+		if depth >= maxDepth {
+			node.Truncated = true
+			return node
+		}
+		node.Children, node.Truncated = d.children(v, depth+1)
+		return node
+	case reflect.String:
+		node.Value, node.Truncated = clip(strconv.Quote(v.String()))
+	case reflect.Bool:
+		node.Value = strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		node.Value = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		node.Value = strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		node.Value = strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		node.Value = strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits())
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if v.IsNil() {
+			node.Value = "nil"
+		} else {
+			node.Value = fmt.Sprintf("0x%x", v.Pointer())
+		}
+	}
+	return node
+}
+
+func (d *dumper) children(v reflect.Value, depth int) ([]Node, bool) {
+	count := 0
+	if v.Kind() == reflect.Struct {
+		count = v.NumField()
+	} else {
+		count = v.Len()
+	}
+	children := make([]Node, 0, min(count, maxItems))
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField() && len(children) < maxItems; i++ {
+			child := d.node(v.Field(i), depth)
+			child.Name = v.Type().Field(i).Name
+			children = append(children, child)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = keyName(key)
+		}
+		order := make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return names[order[a]] < names[order[b]] })
+		for _, i := range order[:min(len(order), maxItems)] {
+			child := d.node(v.MapIndex(keys[i]), depth)
+			child.Name = names[i]
+			children = append(children, child)
+		}
+	default:
+		for i := 0; i < v.Len() && len(children) < maxItems; i++ {
+			child := d.node(v.Index(i), depth)
+			child.Name = "[" + strconv.Itoa(i) + "]"
+			children = append(children, child)
+		}
+	}
+	return children, count > len(children)
+}
+
+// printed returns the text of a value with an Error or String method, such
+// as time.Time, which says more than its fields.
+func printed(v reflect.Value) (text string, ok bool) {
+	if !v.CanInterface() {
+		return "", false
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "", false
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			text, ok = fmt.Sprintf("<%s method panicked: %v>", v.Type(), recovered), true
+		}
+	}()
+	switch value := v.Interface().(type) {
+	case error:
+		return value.Error(), true
+	case fmt.Stringer:
+		return value.String(), true
+	}
+	return "", false
+}
+
+func keyName(key reflect.Value) string {
+	if text, ok := printed(key); ok {
+		return text
+	}
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if key.CanInterface() {
+		return fmt.Sprint(key.Interface())
+	}
+	return key.Type().String()
+}
+
+func clip(text string) (string, bool) {
+	if len(text) <= maxValueSize {
+		return text, false
+	}
+	cut := maxValueSize
+	for cut > 0 && !utf8Start(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "…", true
+}
+
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
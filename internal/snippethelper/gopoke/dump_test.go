@@ -0,0 +1,91 @@
+package gopoke
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gopoke/internal/richoutput"
+)
+
+type account struct {
+	Name    string
+	Tags    []string
+	Limits  map[string]int
+	Created time.Time
+	Err     error
+	next    *account
+}
+
+func TestTreeExpandsContainers(t *testing.T) {
+	first := &account{
+		Name:    "ada",
+		Tags:    []string{"admin"},
+		Limits:  map[string]int{"b": 2, "a": 1},
+		Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Err:     errors.New("locked"),
+	}
+	first.next = first
+
+	tree := Tree(first)
+	if tree.Type != "*gopoke.account" || tree.Kind != "struct" || len(tree.Children) != 6 {
+		t.Fatalf("tree = %+v, want a struct with six fields", tree)
+	}
+	fields := make(map[string]Node)
+	for _, child := range tree.Children {
+		fields[child.Name] = child
+	}
+	if got := fields["Name"].Value; got != `"ada"` {
+		t.Errorf("Name = %q, want quoted string", got)
+	}
+	if tags := fields["Tags"]; tags.Len != 1 || tags.Children[0].Name != "[0]" {
+		t.Errorf("Tags = %+v, want one indexed child", tags)
+	}
+	if limits := fields["Limits"]; limits.Children[0].Name != "a" || limits.Children[1].Value != "2" {
+		t.Errorf("Limits = %+v, want children sorted by key", limits)
+	}
+	if got := fields["Created"].Value; got != "2024-01-02 03:04:05 +0000 UTC" {
+		t.Errorf("Created = %q, want its String form", got)
+	}
+	if got := fields["Err"].Value; got != "locked" {
+		t.Errorf("Err = %q, want its Error text", got)
+	}
+	if got := fields["next"].Value; got != "<cycle>" {
+		t.Errorf("next = %+v, want the cycle marked", fields["next"])
+	}
+}
+
+func TestTreeTruncatesLargeValues(t *testing.T) {
+	tree := Tree(make([]int, maxItems+5))
+	if tree.Len != maxItems+5 || len(tree.Children) != maxItems || !tree.Truncated {
+		t.Fatalf("tree = len %d, %d children, truncated %v; want the first %d items", tree.Len, len(tree.Children), tree.Truncated, maxItems)
+	}
+	long := Tree(strings.Repeat("é", maxValueSize))
+	if !long.Truncated || !strings.HasSuffix(long.Value, "…") {
+		t.Fatalf("long string = %d bytes, truncated %v", len(long.Value), long.Truncated)
+	}
+	if nilTree := Tree(nil); nilTree.Value != "nil" {
+		t.Fatalf("Tree(nil) = %+v", nilTree)
+	}
+}
+
+func TestFdumpWritesRichOutputMarker(t *testing.T) {
+	var out bytes.Buffer
+	if err := Fdump(&out, map[string]any{"html": "<b>"}); err != nil {
+		t.Fatalf("Fdump() error = %v", err)
+	}
+	clean, blocks := richoutput.Parse("before\n" + out.String() + "after")
+	if clean != "before\nafter" || len(blocks) != 1 || blocks[0].Type != richoutput.TypeDump {
+		t.Fatalf("Parse() = %q, %+v, want one dump block", clean, blocks)
+	}
+	var tree Node
+	if err := json.Unmarshal(blocks[0].Data, &tree); err != nil {
+		t.Fatalf("unmarshal dump: %v", err)
+	}
+	if tree.Kind != "map" || tree.Children[0].Value != `"<b>"` {
+		t.Fatalf("tree = %+v, want the map entry", tree)
+	}
+}
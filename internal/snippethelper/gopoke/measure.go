@@ -0,0 +1,62 @@
+package gopoke
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// Measurement is what Measure observed while running one block.
+type Measurement struct {
+	// Label is the file and line of the Measure call.
+	Label  string `json:"label"`
+	Allocs uint64 `json:"allocs"`
+	Bytes  uint64 `json:"bytes"`
+	WallNS int64  `json:"wallNs"`
+}
+
+// Measure runs fn once and prints its heap allocations, allocated bytes and
+// wall time as a //gopoke:measure marker. Allocations made by other
+// goroutines while fn runs are counted too.
+func Measure(fn func()) Measurement {
+	measurement, err := measure(os.Stdout, fn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gopoke.Measure: %v\n", err)
+	}
+	return measurement
+}
+
+// Fmeasure runs fn once and writes the //gopoke:measure marker to w.
+func Fmeasure(w io.Writer, fn func()) (Measurement, error) {
+	return measure(w, fn)
+}
+
+// measure is called by Measure and Fmeasure, so the snippet's call site is
+// two frames up.
+func measure(w io.Writer, fn func()) (Measurement, error) {
+	var measurement Measurement
+	if _, file, line, ok := runtime.Caller(2); ok {
+		measurement.Label = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	fn()
+	wall := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	measurement.Allocs = after.Mallocs - before.Mallocs
+	measurement.Bytes = after.TotalAlloc - before.TotalAlloc
+	measurement.WallNS = wall.Nanoseconds()
+	payload, err := json.Marshal(measurement)
+	if err != nil {
+		return measurement, err
+	}
+	_, err = fmt.Fprintf(w, "//gopoke:measure %s\n", payload)
+	return measurement, err
+}
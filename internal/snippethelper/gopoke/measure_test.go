@@ -0,0 +1,55 @@
+package gopoke
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopoke/internal/richoutput"
+)
+
+var measureSink []byte
+
+func TestFmeasureReportsAllocations(t *testing.T) {
+	var output bytes.Buffer
+	measurement, err := Fmeasure(&output, func() {
+		measureSink = make([]byte, 1<<20)
+	})
+	if err != nil {
+		t.Fatalf("Fmeasure() error = %v", err)
+	}
+	if measurement.Allocs < 1 || measurement.Bytes < 1<<20 || measurement.WallNS <= 0 {
+		t.Fatalf("measurement = %+v, want at least one 1 MiB allocation", measurement)
+	}
+	if !strings.HasPrefix(measurement.Label, "measure_test.go:") {
+		t.Fatalf("Label = %q, want the call site", measurement.Label)
+	}
+
+	clean, blocks := richoutput.Parse(output.String())
+	if strings.TrimSpace(clean) != "" || len(blocks) != 1 || blocks[0].Type != richoutput.TypeMeasure {
+		t.Fatalf("Parse() = %q, %+v", clean, blocks)
+	}
+	var parsed richoutput.Measurement
+	if err := json.Unmarshal(blocks[0].Data, &parsed); err != nil {
+		t.Fatalf("decode block: %v", err)
+	}
+	if parsed.Allocs != measurement.Allocs || parsed.Wall == "" || parsed.BytesPerAlloc == 0 {
+		t.Fatalf("parsed = %+v", parsed)
+	}
+}
+
+func TestFmeasureOfNonAllocatingBlock(t *testing.T) {
+	total := 0
+	measurement, err := Fmeasure(&bytes.Buffer{}, func() {
+		for i := range 1000 {
+			total += i
+		}
+	})
+	if err != nil {
+		t.Fatalf("Fmeasure() error = %v", err)
+	}
+	if measurement.Allocs != 0 || measurement.Bytes != 0 || total == 0 {
+		t.Fatalf("measurement = %+v, want no allocations", measurement)
+	}
+}
@@ -0,0 +1,62 @@
+// Package snippethelper installs the gopoke helper package into a module so
+// snippets can import "gopoke" and call gopoke.Dump, gopoke.Measure or
+// gopoke.Context. The helper lives in its own module next to the snippets
+// and is wired in with a replace directive, so it needs no network access
+// and no go.sum entries.
+package snippethelper
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ModulePath is the import path snippets use for the helper.
+const ModulePath = "gopoke"
+
+// DirName is the helper module directory inside the module it serves. The
+// leading dot keeps it out of ./... patterns.
+const DirName = ".gopoke-helper"
+
+//go:embed gopoke/dump.go gopoke/context.go gopoke/measure.go
+var helperSources embed.FS
+
+// Install writes the helper module into moduleDir. It overwrites an earlier
+// copy so the helper stays in step with the app.
+func Install(moduleDir string) error {
+	dir := filepath.Join(moduleDir, DirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create helper module dir: %w", err)
+	}
+	goMod := fmt.Sprintf("module %s\n\ngo 1.21\n", ModulePath)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		return fmt.Errorf("write helper go.mod: %w", err)
+	}
+	entries, err := helperSources.ReadDir("gopoke")
+	if err != nil {
+		return fmt.Errorf("read helper sources: %w", err)
+	}
+	for _, entry := range entries {
+		source, err := helperSources.ReadFile("gopoke/" + entry.Name())
+		if err != nil {
+			return fmt.Errorf("read helper source: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, entry.Name()), source, 0o644); err != nil {
+			return fmt.Errorf("write helper source: %w", err)
+		}
+	}
+	return nil
+}
+
+// Installed reports whether moduleDir has the helper module.
+func Installed(moduleDir string) bool {
+	_, err := os.Stat(filepath.Join(moduleDir, DirName, "go.mod"))
+	return err == nil
+}
+
+// GoModDirectives returns the go.mod lines that make the helper importable,
+// with helperDir the helper module directory relative to the go.mod.
+func GoModDirectives(helperDir string) string {
+	return fmt.Sprintf("require %s v0.0.0\n\nreplace %s => %s\n", ModulePath, ModulePath, filepath.ToSlash(helperDir))
+}
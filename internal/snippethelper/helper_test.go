@@ -0,0 +1,46 @@
+package snippethelper
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstalledHelperIsImportable(t *testing.T) {
+	goBinary, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+	moduleDir := t.TempDir()
+	if Installed(moduleDir) {
+		t.Fatal("Installed() = true before Install")
+	}
+	if err := Install(moduleDir); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if !Installed(moduleDir) {
+		t.Fatal("Installed() = false after Install")
+	}
+	goMod := "module example.com/scratch\n\ngo 1.22\n\n" + GoModDirectives("./"+DirName)
+	if err := os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte(goMod), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	snippet := "package main\n\nimport \"gopoke\"\n\nfunc main() { gopoke.Dump(struct{ N int }{N: 7}) }\n"
+	if err := os.WriteFile(filepath.Join(moduleDir, "main.go"), []byte(snippet), 0o644); err != nil {
+		t.Fatalf("write snippet: %v", err)
+	}
+
+	command := exec.Command(goBinary, "run", "main.go")
+	command.Dir = moduleDir
+	command.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=", "GOPROXY=off")
+	output, err := command.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run error = %v: %s", err, output)
+	}
+	want := `//gopoke:dump {"type":"struct { N int }","kind":"struct","children":[{"name":"N","type":"int","kind":"int","value":"7"}]}`
+	if got := strings.TrimSpace(string(output)); got != want {
+		t.Fatalf("output = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,117 @@
+// Package snippetmeta parses the run configuration a snippet carries in its
+// own source, so the configuration survives export, import and sharing.
+// Each setting is a line comment, usually in the snippet's header:
+//
+//	//gopoke:name Seed database
+//	//gopoke:timeout 30s
+//	//gopoke:env FOO=bar
+//	//gopoke:target ./cmd/api
+//
+// env may repeat; the other directives may appear once.
+package snippetmeta
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Directives.
+const (
+	DirectiveName    = "//gopoke:name"
+	DirectiveTimeout = "//gopoke:timeout"
+	DirectiveEnv     = "//gopoke:env"
+	DirectiveTarget  = "//gopoke:target"
+)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Meta is the configuration a snippet declares. Zero fields were not
+// declared.
+type Meta struct {
+	Name string `json:"name,omitempty"`
+	// TimeoutMS is the run timeout in milliseconds.
+	TimeoutMS int64 `json:"timeoutMs,omitempty"`
+	// Env holds variables set for the run.
+	Env map[string]string `json:"env,omitempty"`
+	// Target is the package to run, relative to the project, such as
+	// "./cmd/api".
+	Target string `json:"target,omitempty"`
+}
+
+// Parse returns the configuration declared in source.
+func Parse(source string) (Meta, error) {
+	var meta Meta
+	seen := make(map[string]int)
+	for index, line := range strings.Split(source, "\n") {
+		lineNumber := index + 1
+		line = strings.TrimSpace(line)
+		directive, value, ok := cutDirective(line)
+		if !ok {
+			continue
+		}
+		if directive != DirectiveEnv {
+			if previous, repeated := seen[directive]; repeated {
+				return Meta{}, fmt.Errorf("%s on line %d: already set on line %d", directive, lineNumber, previous)
+			}
+			seen[directive] = lineNumber
+		}
+		if value == "" {
+			return Meta{}, fmt.Errorf("%s on line %d: value is required", directive, lineNumber)
+		}
+		switch directive {
+		case DirectiveName:
+			meta.Name = value
+		case DirectiveTimeout:
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return Meta{}, fmt.Errorf("%s on line %d: %q is not a positive duration", directive, lineNumber, value)
+			}
+			meta.TimeoutMS = timeout.Milliseconds()
+		case DirectiveEnv:
+			key, envValue, found := strings.Cut(value, "=")
+			key = strings.TrimSpace(key)
+			if !found || !envKeyPattern.MatchString(key) {
+				return Meta{}, fmt.Errorf("%s on line %d: want KEY=VALUE", directive, lineNumber)
+			}
+			if meta.Env == nil {
+				meta.Env = make(map[string]string)
+			}
+			meta.Env[key] = envValue
+		case DirectiveTarget:
+			target, err := normalizeTarget(value)
+			if err != nil {
+				return Meta{}, fmt.Errorf("%s on line %d: %w", directive, lineNumber, err)
+			}
+			meta.Target = target
+		}
+	}
+	return meta, nil
+}
+
+// cutDirective splits a directive line into its directive and value.
+func cutDirective(line string) (string, string, bool) {
+	for _, directive := range []string{DirectiveName, DirectiveTimeout, DirectiveEnv, DirectiveTarget} {
+		rest, ok := strings.CutPrefix(line, directive)
+		if !ok || (rest != "" && !unicode.IsSpace(rune(rest[0]))) {
+			continue
+		}
+		return directive, strings.TrimSpace(rest), true
+	}
+	return "", "", false
+}
+
+// normalizeTarget cleans a package path and keeps it inside the project.
+func normalizeTarget(target string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(target, `\`, "/"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("target %q must be a package inside the project", target)
+	}
+	if cleaned == "." {
+		return ".", nil
+	}
+	return "./" + cleaned, nil
+}
@@ -0,0 +1,61 @@
+package snippetmeta
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	source := `//gopoke:name Seed database
+//gopoke:timeout 1m30s
+//gopoke:env DSN=postgres://localhost/dev?sslmode=disable
+//gopoke:env  VERBOSE=1
+//gopoke:target cmd/seed/
+//gopoke:names is not a directive
+package main
+
+func main() {}
+`
+	meta, err := Parse(source)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := Meta{
+		Name:      "Seed database",
+		TimeoutMS: 90000,
+		Env:       map[string]string{"DSN": "postgres://localhost/dev?sslmode=disable", "VERBOSE": "1"},
+		Target:    "./cmd/seed",
+	}
+	if !reflect.DeepEqual(meta, want) {
+		t.Fatalf("Parse() = %+v, want %+v", meta, want)
+	}
+
+	empty, err := Parse("package main\n\nfunc main() {}\n")
+	if err != nil || !reflect.DeepEqual(empty, Meta{}) {
+		t.Fatalf("Parse(no directives) = %+v, %v", empty, err)
+	}
+}
+
+func TestParseRejectsInvalidDirectives(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		source string
+		want   string
+	}{
+		"bad timeout":      {"//gopoke:timeout soon\n", "line 1"},
+		"negative timeout": {"//gopoke:timeout -5s\n", "positive duration"},
+		"repeated name":    {"//gopoke:name a\n//gopoke:name b\n", "already set on line 1"},
+		"env without =":    {"package main\n//gopoke:env FOO\n", "line 2: want KEY=VALUE"},
+		"bad env key":      {"//gopoke:env 1FOO=x\n", "KEY=VALUE"},
+		"escaping target":  {"//gopoke:target ../other\n", "inside the project"},
+		"empty value":      {"//gopoke:name\n", "value is required"},
+	} {
+		if _, err := Parse(tc.source); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Parse() error = %v, want it to mention %q", name, err, tc.want)
+		}
+	}
+}
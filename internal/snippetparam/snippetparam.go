@@ -0,0 +1,204 @@
+// Package snippetparam parses the parameters a snippet declares in its
+// source and turns supplied values into the environment variables and
+// flags a run passes to the program, so one saved snippet can serve many
+// inputs. A declaration is a line comment:
+//
+//	//gopoke:param NAME TYPE [default VALUE] [DESCRIPTION]
+//
+// VALUE may be a bare word or a Go string literal. A snippet reads a
+// parameter from the environment variable EnvPrefix+upper(NAME), or with
+// the flag package from -NAME.
+package snippetparam
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Directive starts a parameter declaration line.
+const Directive = "//gopoke:param"
+
+// EnvPrefix prefixes the environment variable of each parameter.
+const EnvPrefix = "GOPOKE_PARAM_"
+
+// Parameter types.
+const (
+	TypeString   = "string"
+	TypeInt      = "int"
+	TypeFloat    = "float"
+	TypeBool     = "bool"
+	TypeDuration = "duration"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Param is one declared parameter.
+type Param struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	HasDefault  bool   `json:"hasDefault"`
+	Description string `json:"description,omitempty"`
+	Line        int    `json:"line"`
+	// EnvVar is the environment variable the value is passed in.
+	EnvVar string `json:"envVar"`
+}
+
+// Parse returns the parameters declared in source, in declaration order.
+func Parse(source string) ([]Param, error) {
+	params := make([]Param, 0)
+	seen := make(map[string]int)
+	for index, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		rest, ok := strings.CutPrefix(line, Directive)
+		if !ok || (rest != "" && !unicode.IsSpace(rune(rest[0]))) {
+			continue
+		}
+		lineNumber := index + 1
+		param, err := parseDeclaration(rest)
+		if err != nil {
+			return nil, fmt.Errorf("parameter on line %d: %w", lineNumber, err)
+		}
+		// Names differing only in case share an environment variable.
+		if previous, ok := seen[param.EnvVar]; ok {
+			return nil, fmt.Errorf("parameter on line %d: %q already declared on line %d", lineNumber, param.Name, previous)
+		}
+		seen[param.EnvVar] = lineNumber
+		param.Line = lineNumber
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+func parseDeclaration(text string) (Param, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return Param{}, err
+	}
+	if len(tokens) < 2 {
+		return Param{}, fmt.Errorf("want %s NAME TYPE [default VALUE] [DESCRIPTION]", Directive)
+	}
+	param := Param{Name: tokens[0], Type: strings.ToLower(tokens[1])}
+	if !namePattern.MatchString(param.Name) {
+		return Param{}, fmt.Errorf("invalid name %q", param.Name)
+	}
+	if !slices.Contains([]string{TypeString, TypeInt, TypeFloat, TypeBool, TypeDuration}, param.Type) {
+		return Param{}, fmt.Errorf("unsupported type %q", tokens[1])
+	}
+	param.EnvVar = EnvPrefix + strings.ToUpper(param.Name)
+	rest := tokens[2:]
+	if len(rest) > 0 && rest[0] == "default" {
+		if len(rest) < 2 {
+			return Param{}, fmt.Errorf("default needs a value")
+		}
+		value, err := checkValue(param.Type, rest[1])
+		if err != nil {
+			return Param{}, fmt.Errorf("default: %w", err)
+		}
+		param.Default, param.HasDefault = value, true
+		rest = rest[2:]
+	}
+	param.Description = strings.Join(rest, " ")
+	return param, nil
+}
+
+// tokenize splits on whitespace, keeping Go string literals whole.
+func tokenize(text string) ([]string, error) {
+	tokens := make([]string, 0)
+	for {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if text == "" {
+			return tokens, nil
+		}
+		if text[0] == '"' || text[0] == '`' {
+			literal, err := strconv.QuotedPrefix(text)
+			if err != nil {
+				return nil, fmt.Errorf("unterminated string %s", text)
+			}
+			value, _ := strconv.Unquote(literal)
+			tokens = append(tokens, value)
+			text = text[len(literal):]
+			continue
+		}
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		}
+		tokens = append(tokens, text[:end])
+		text = text[end:]
+	}
+}
+
+// checkValue validates value for a parameter type and returns it trimmed,
+// except for strings, which are kept as given.
+func checkValue(paramType string, value string) (string, error) {
+	if paramType == TypeString {
+		return value, nil
+	}
+	value = strings.TrimSpace(value)
+	var err error
+	switch paramType {
+	case TypeInt:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case TypeFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case TypeBool:
+		_, err = strconv.ParseBool(value)
+	case TypeDuration:
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%q is not a valid %s", value, paramType)
+	}
+	return value, nil
+}
+
+// Bindings are the values of a run's parameters, ready to pass to the
+// program.
+type Bindings struct {
+	Environment map[string]string
+	Args        []string
+}
+
+// Resolve checks values against the parameters source declares, fills in
+// defaults and returns the bindings. Values for undeclared parameters and
+// missing values without a default are errors.
+func Resolve(source string, values map[string]string) (Bindings, error) {
+	params, err := Parse(source)
+	if err != nil {
+		return Bindings{}, err
+	}
+	declared := make(map[string]bool, len(params))
+	for _, param := range params {
+		declared[param.Name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if !declared[name] {
+			return Bindings{}, fmt.Errorf("snippet does not declare parameter %q", name)
+		}
+	}
+
+	bindings := Bindings{Environment: make(map[string]string, len(params)), Args: make([]string, 0, len(params))}
+	for _, param := range params {
+		value, ok := values[param.Name]
+		if !ok {
+			if !param.HasDefault {
+				return Bindings{}, fmt.Errorf("parameter %q is required", param.Name)
+			}
+			value = param.Default
+		}
+		value, err := checkValue(param.Type, value)
+		if err != nil {
+			return Bindings{}, fmt.Errorf("parameter %q: %w", param.Name, err)
+		}
+		bindings.Environment[param.EnvVar] = value
+		bindings.Args = append(bindings.Args, "-"+param.Name+"="+value)
+	}
+	return bindings, nil
+}
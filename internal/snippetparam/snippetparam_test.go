@@ -0,0 +1,100 @@
+package snippetparam
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const paramsSource = `package main
+
+//gopoke:param name string default "hello world" greeting target
+//gopoke:param count int default 3
+//gopoke:param verbose bool
+//gopoke:param wait duration default 1.5s
+//gopoke:paramless is not a directive
+func main() {}
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	params, err := Parse(paramsSource)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(params) != 4 {
+		t.Fatalf("len(params) = %d, want 4: %+v", len(params), params)
+	}
+	want := Param{
+		Name:        "name",
+		Type:        TypeString,
+		Default:     "hello world",
+		HasDefault:  true,
+		Description: "greeting target",
+		Line:        3,
+		EnvVar:      "GOPOKE_PARAM_NAME",
+	}
+	if params[0] != want {
+		t.Fatalf("params[0] = %+v, want %+v", params[0], want)
+	}
+	if params[2].HasDefault || params[2].Type != TypeBool {
+		t.Fatalf("params[2] = %+v, want bool without default", params[2])
+	}
+}
+
+func TestParseRejectsBadDeclarations(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"missing type":   "//gopoke:param name",
+		"bad name":       "//gopoke:param 9lives int",
+		"bad type":       "//gopoke:param name uuid",
+		"bad default":    "//gopoke:param count int default many",
+		"no default":     "//gopoke:param count int default",
+		"unterminated":   `//gopoke:param name string default "x`,
+		"duplicate case": "//gopoke:param n int\n//gopoke:param N int",
+	}
+	for name, source := range tests {
+		if _, err := Parse(source); err == nil {
+			t.Errorf("%s: Parse() error = nil, want error", name)
+		}
+	}
+	if _, err := Parse("\n\n//gopoke:param x uuid"); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("Parse() error = %v, want line 3", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	bindings, err := Resolve(paramsSource, map[string]string{"verbose": " true ", "name": " padded "})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	wantEnv := map[string]string{
+		"GOPOKE_PARAM_NAME":    " padded ",
+		"GOPOKE_PARAM_COUNT":   "3",
+		"GOPOKE_PARAM_VERBOSE": "true",
+		"GOPOKE_PARAM_WAIT":    "1.5s",
+	}
+	for key, value := range wantEnv {
+		if bindings.Environment[key] != value {
+			t.Errorf("%s = %q, want %q", key, bindings.Environment[key], value)
+		}
+	}
+	wantArgs := []string{"-name= padded ", "-count=3", "-verbose=true", "-wait=1.5s"}
+	if !slices.Equal(bindings.Args, wantArgs) {
+		t.Fatalf("Args = %q, want %q", bindings.Args, wantArgs)
+	}
+
+	if _, err := Resolve(paramsSource, nil); err == nil || !strings.Contains(err.Error(), `"verbose" is required`) {
+		t.Fatalf("Resolve(nil) error = %v, want verbose required", err)
+	}
+	if _, err := Resolve(paramsSource, map[string]string{"verbose": "yes"}); err == nil {
+		t.Fatal("Resolve(verbose=yes) error = nil, want error")
+	}
+	if _, err := Resolve(paramsSource, map[string]string{"verbose": "1", "other": "x"}); err == nil {
+		t.Fatal("Resolve(undeclared) error = nil, want error")
+	}
+}
@@ -0,0 +1,171 @@
+package snipsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitCommitMessage is the message of every library commit.
+const gitCommitMessage = "Sync gopoke snippets"
+
+// GitProvider keeps the library as a file on a branch of a git
+// repository. It works in a private bare clone with plumbing commands, so
+// it never touches a working tree, and relies on the user's git
+// credentials. A rejected non-fast-forward push is a conflict.
+type GitProvider struct {
+	URL    string
+	Branch string
+	Path   string
+	// CacheDir holds the bare clone.
+	CacheDir string
+}
+
+// Name implements Provider.
+func (p *GitProvider) Name() string {
+	return ProviderGit
+}
+
+// Pull implements Provider.
+func (p *GitProvider) Pull(ctx context.Context) (Library, string, error) {
+	if err := p.ensureRepository(ctx); err != nil {
+		return Library{}, "", err
+	}
+	heads, err := p.git(ctx, nil, nil, "ls-remote", "--heads", "origin", "refs/heads/"+p.Branch)
+	if err != nil {
+		return Library{}, "", err
+	}
+	if strings.TrimSpace(heads) == "" {
+		library, err := decodeLibrary(nil)
+		return library, "", err
+	}
+	if _, err := p.git(ctx, nil, nil, "fetch", "--quiet", "--no-tags", "origin", "+refs/heads/"+p.Branch+":"+p.remoteRef()); err != nil {
+		return Library{}, "", err
+	}
+	revision, err := p.git(ctx, nil, nil, "rev-parse", "--verify", p.remoteRef()+"^{commit}")
+	if err != nil {
+		return Library{}, "", err
+	}
+	revision = strings.TrimSpace(revision)
+	object := revision + ":" + p.Path
+	if _, err := p.git(ctx, nil, nil, "cat-file", "-e", object); err != nil {
+		library, err := decodeLibrary(nil)
+		return library, revision, err
+	}
+	raw, err := p.git(ctx, nil, nil, "cat-file", "blob", object)
+	if err != nil {
+		return Library{}, "", err
+	}
+	library, err := decodeLibrary([]byte(raw))
+	if err != nil {
+		return Library{}, "", err
+	}
+	return library, revision, nil
+}
+
+// Push implements Provider.
+func (p *GitProvider) Push(ctx context.Context, library Library, baseRevision string) (string, error) {
+	if err := p.ensureRepository(ctx); err != nil {
+		return "", err
+	}
+	raw, err := encodeLibrary(library)
+	if err != nil {
+		return "", err
+	}
+	blob, err := p.git(ctx, raw, nil, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return "", err
+	}
+
+	indexFile := filepath.Join(p.CacheDir, "gopoke-index")
+	defer os.Remove(indexFile)
+	os.Remove(indexFile)
+	indexEnv := []string{"GIT_INDEX_FILE=" + indexFile}
+	if baseRevision != "" {
+		if _, err := p.git(ctx, nil, indexEnv, "read-tree", baseRevision); err != nil {
+			return "", err
+		}
+	}
+	if _, err := p.git(ctx, nil, indexEnv, "update-index", "--add", "--cacheinfo", "100644,"+strings.TrimSpace(blob)+","+p.Path); err != nil {
+		return "", err
+	}
+	tree, err := p.git(ctx, nil, indexEnv, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	args := []string{"commit-tree", strings.TrimSpace(tree), "-m", gitCommitMessage}
+	if baseRevision != "" {
+		args = append(args, "-p", baseRevision)
+	}
+	commit, err := p.git(ctx, nil, p.identityEnv(ctx), args...)
+	if err != nil {
+		return "", err
+	}
+	commit = strings.TrimSpace(commit)
+
+	output, err := p.git(ctx, nil, nil, "push", "--porcelain", "origin", commit+":refs/heads/"+p.Branch)
+	if err != nil {
+		if strings.Contains(output+err.Error(), "[rejected]") {
+			return "", ErrConflict
+		}
+		return "", err
+	}
+	if _, err := p.git(ctx, nil, nil, "update-ref", p.remoteRef(), commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+func (p *GitProvider) remoteRef() string {
+	return "refs/remotes/origin/" + p.Branch
+}
+
+// ensureRepository creates the bare clone and points it at URL.
+func (p *GitProvider) ensureRepository(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(p.CacheDir, "HEAD")); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(p.CacheDir, 0o700); err != nil {
+			return fmt.Errorf("create git cache: %w", err)
+		}
+		if _, err := p.git(ctx, nil, nil, "init", "--bare", "--quiet"); err != nil {
+			return err
+		}
+	}
+	_, err := p.git(ctx, nil, nil, "config", "remote.origin.url", p.URL)
+	return err
+}
+
+// identityEnv supplies a committer identity when git has none configured.
+func (p *GitProvider) identityEnv(ctx context.Context) []string {
+	env := make([]string, 0, 4)
+	if name, err := p.git(ctx, nil, nil, "config", "user.name"); err != nil || strings.TrimSpace(name) == "" {
+		env = append(env, "GIT_AUTHOR_NAME=gopoke", "GIT_COMMITTER_NAME=gopoke")
+	}
+	if email, err := p.git(ctx, nil, nil, "config", "user.email"); err != nil || strings.TrimSpace(email) == "" {
+		env = append(env, "GIT_AUTHOR_EMAIL=gopoke@localhost", "GIT_COMMITTER_EMAIL=gopoke@localhost")
+	}
+	return env
+}
+
+// git runs a git command in the cache and returns its stdout. Prompts are
+// disabled so a missing credential fails instead of hanging.
+func (p *GitProvider) git(ctx context.Context, stdin []byte, env []string, args ...string) (string, error) {
+	command := exec.CommandContext(ctx, "git", args...)
+	command.Dir = p.CacheDir
+	command.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	command.Env = append(command.Env, env...)
+	if stdin != nil {
+		command.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
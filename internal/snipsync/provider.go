@@ -0,0 +1,132 @@
+package snipsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopoke/internal/storage"
+)
+
+// Provider names accepted in a sync config.
+const (
+	ProviderGit    = "git"
+	ProviderWebDAV = "webdav"
+	// ProviderRepo keeps snippets as files inside the project itself.
+	ProviderRepo = "repo"
+)
+
+// Defaults for git libraries.
+const (
+	DefaultBranch = "main"
+	DefaultPath   = "gopoke-snippets.json"
+	// DefaultRepoDir is where repo libraries keep snippet files, relative
+	// to the project.
+	DefaultRepoDir = ".gopoke/snippets"
+)
+
+// NormalizeConfig validates config and fills in defaults.
+func NormalizeConfig(config storage.SnippetSyncConfig) (storage.SnippetSyncConfig, error) {
+	config.Provider = strings.ToLower(strings.TrimSpace(config.Provider))
+	config.URL = strings.TrimSpace(config.URL)
+	config.Branch = strings.TrimSpace(config.Branch)
+	config.Path = strings.TrimSpace(config.Path)
+	config.Username = strings.TrimSpace(config.Username)
+	config.PasswordEnv = strings.TrimSpace(config.PasswordEnv)
+	if config.URL == "" && config.Provider != ProviderRepo {
+		return storage.SnippetSyncConfig{}, fmt.Errorf("library URL is required")
+	}
+
+	switch config.Provider {
+	case ProviderRepo:
+		if config.Path == "" {
+			config.Path = DefaultRepoDir
+		}
+		cleaned := path.Clean(filepath.ToSlash(config.Path))
+		if path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return storage.SnippetSyncConfig{}, fmt.Errorf("snippet directory must be inside the project")
+		}
+		if !ignoredByGoCommand(cleaned) {
+			return storage.SnippetSyncConfig{}, fmt.Errorf("snippet directory %q would be built as a package; put it under a directory starting with . or _, or under testdata", cleaned)
+		}
+		config.Path = cleaned
+		config.URL, config.Branch, config.Username, config.PasswordEnv = "", "", "", ""
+	case ProviderGit:
+		if config.Branch == "" {
+			config.Branch = DefaultBranch
+		}
+		if config.Path == "" {
+			config.Path = DefaultPath
+		}
+		cleaned := path.Clean(filepath.ToSlash(config.Path))
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return storage.SnippetSyncConfig{}, fmt.Errorf("library path must stay inside the repository")
+		}
+		config.Path = cleaned
+		if strings.HasPrefix(config.Branch, "-") || strings.ContainsAny(config.Branch, " ~^:?*[\\") {
+			return storage.SnippetSyncConfig{}, fmt.Errorf("invalid branch %q", config.Branch)
+		}
+		if strings.HasPrefix(config.URL, "-") {
+			return storage.SnippetSyncConfig{}, fmt.Errorf("invalid git URL %q", config.URL)
+		}
+		config.Username, config.PasswordEnv = "", ""
+	case ProviderWebDAV:
+		parsed, err := url.Parse(config.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return storage.SnippetSyncConfig{}, fmt.Errorf("WebDAV URL must be an http or https URL")
+		}
+		if parsed.User != nil {
+			return storage.SnippetSyncConfig{}, fmt.Errorf("put WebDAV credentials in the username and password variable, not the URL")
+		}
+		config.Branch, config.Path = "", ""
+	default:
+		return storage.SnippetSyncConfig{}, fmt.Errorf("unsupported sync provider %q", config.Provider)
+	}
+	return config, nil
+}
+
+// NewProvider builds the provider for config. Git providers keep a bare
+// clone under cacheRoot; repo providers keep files under projectPath.
+func NewProvider(config storage.SnippetSyncConfig, cacheRoot string, projectPath string) (Provider, error) {
+	config, err := NormalizeConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	switch config.Provider {
+	case ProviderRepo:
+		if projectPath == "" {
+			return nil, fmt.Errorf("project path is required for repo snippets")
+		}
+		return &RepoProvider{Dir: filepath.Join(projectPath, filepath.FromSlash(config.Path))}, nil
+	case ProviderGit:
+		sum := sha256.Sum256([]byte(config.URL))
+		return &GitProvider{
+			URL:      config.URL,
+			Branch:   config.Branch,
+			Path:     config.Path,
+			CacheDir: filepath.Join(cacheRoot, "git", hex.EncodeToString(sum[:8])),
+		}, nil
+	default:
+		password := ""
+		if config.PasswordEnv != "" {
+			password = os.Getenv(config.PasswordEnv)
+		}
+		return &WebDAVProvider{URL: config.URL, Username: config.Username, Password: password}, nil
+	}
+}
+
+// ignoredByGoCommand reports whether the go command skips the directory at
+// the slash-separated relative path dir when matching ./... patterns.
+func ignoredByGoCommand(dir string) bool {
+	for _, element := range strings.Split(dir, "/") {
+		if strings.HasPrefix(element, ".") || strings.HasPrefix(element, "_") || element == "testdata" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,222 @@
+package snipsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopoke/internal/storage"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := NormalizeConfig(storage.SnippetSyncConfig{Provider: " Git ", URL: "git@example.com:team/snippets.git", Username: "ignored"})
+	if err != nil {
+		t.Fatalf("NormalizeConfig(git) error = %v", err)
+	}
+	if config.Provider != ProviderGit || config.Branch != DefaultBranch || config.Path != DefaultPath || config.Username != "" {
+		t.Fatalf("NormalizeConfig(git) = %+v, want defaults", config)
+	}
+	config, err = NormalizeConfig(storage.SnippetSyncConfig{Provider: ProviderRepo, URL: "ignored"})
+	if err != nil || config.Path != DefaultRepoDir || config.URL != "" {
+		t.Fatalf("NormalizeConfig(repo) = %+v, %v; want the default directory", config, err)
+	}
+
+	for name, bad := range map[string]storage.SnippetSyncConfig{
+		"no url":          {Provider: ProviderGit},
+		"escaping path":   {Provider: ProviderGit, URL: "repo", Path: "../secrets.json"},
+		"option url":      {Provider: ProviderGit, URL: "--upload-pack=evil"},
+		"option branch":   {Provider: ProviderGit, URL: "repo", Branch: "-f"},
+		"webdav scheme":   {Provider: ProviderWebDAV, URL: "ftp://example.com/lib.json"},
+		"webdav userinfo": {Provider: ProviderWebDAV, URL: "https://user:pw@example.com/lib.json"},
+		"unknown":         {Provider: "s3", URL: "s3://bucket/lib.json"},
+		"repo escaping":   {Provider: ProviderRepo, Path: "../shared"},
+		"repo built":      {Provider: ProviderRepo, Path: "tools/snippets"},
+	} {
+		if _, err := NormalizeConfig(bad); err == nil {
+			t.Errorf("%s: NormalizeConfig() error = nil, want error", name)
+		}
+	}
+}
+
+// webdavServer is a one-file WebDAV store honoring If-Match and
+// If-None-Match.
+type webdavServer struct {
+	mu      sync.Mutex
+	body    []byte
+	version int
+}
+
+func (s *webdavServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if user, password, _ := r.BasicAuth(); user != "team" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	switch r.Method {
+	case http.MethodGet:
+		if s.body == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write(s.body)
+	case http.MethodPut:
+		if (r.Header.Get("If-None-Match") == "*" && s.body != nil) ||
+			(r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != etag) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		s.body, _ = io.ReadAll(r.Body)
+		s.version++
+		w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, s.version))
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func TestWebDAVProvider(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(&webdavServer{})
+	defer server.Close()
+	provider := &WebDAVProvider{URL: server.URL + "/lib.json", Username: "team", Password: "secret"}
+	ctx := context.Background()
+
+	library, revision, err := provider.Pull(ctx)
+	if err != nil || revision != "" || len(library.Snippets) != 0 {
+		t.Fatalf("Pull(empty) = %+v, %q, %v; want empty library", library, revision, err)
+	}
+	first := Library{Snippets: []Snippet{{ID: "sn_a", Name: "probe", Content: "package main"}}}
+	revision, err = provider.Push(ctx, first, "")
+	if err != nil || revision != `"v1"` {
+		t.Fatalf("Push() = %q, %v; want v1", revision, err)
+	}
+	if _, err := provider.Push(ctx, first, ""); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Push(stale) error = %v, want ErrConflict", err)
+	}
+	library, revision, err = provider.Pull(ctx)
+	if err != nil || revision != `"v1"` || len(library.Snippets) != 1 || library.Snippets[0].Name != "probe" {
+		t.Fatalf("Pull() = %+v, %q, %v; want the pushed library", library, revision, err)
+	}
+
+	unauthorized := &WebDAVProvider{URL: provider.URL}
+	if _, _, err := unauthorized.Pull(ctx); err == nil {
+		t.Fatal("Pull(without credentials) error = nil, want error")
+	}
+}
+
+func TestGitProvider(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if output, err := exec.Command("git", "init", "--bare", "--quiet", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, output)
+	}
+	newProvider := func() Provider {
+		provider, err := NewProvider(storage.SnippetSyncConfig{Provider: ProviderGit, URL: remote, Path: "team/snippets.json"}, t.TempDir(), "")
+		if err != nil {
+			t.Fatalf("NewProvider() error = %v", err)
+		}
+		return provider
+	}
+	ctx := context.Background()
+	alice, bob := newProvider(), newProvider()
+
+	library, revision, err := alice.Pull(ctx)
+	if err != nil || revision != "" || len(library.Snippets) != 0 {
+		t.Fatalf("Pull(empty) = %+v, %q, %v; want empty library", library, revision, err)
+	}
+	_, bobBase, err := bob.Pull(ctx)
+	if err != nil {
+		t.Fatalf("bob Pull() error = %v", err)
+	}
+	pushed, err := alice.Push(ctx, Library{Snippets: []Snippet{{ID: "sn_a", Name: "probe", Content: "package main"}}}, revision)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if _, err := bob.Push(ctx, Library{}, bobBase); !errors.Is(err, ErrConflict) {
+		t.Fatalf("bob Push(stale) error = %v, want ErrConflict", err)
+	}
+
+	library, revision, err = bob.Pull(ctx)
+	if err != nil || revision != pushed || len(library.Snippets) != 1 {
+		t.Fatalf("bob Pull() = %+v, %q, %v; want alice's library at %q", library, revision, err, pushed)
+	}
+	if _, err := bob.Push(ctx, Library{}, revision); err != nil {
+		t.Fatalf("bob Push() error = %v", err)
+	}
+	library, _, err = alice.Pull(ctx)
+	if err != nil || len(library.Snippets) != 0 {
+		t.Fatalf("alice Pull() = %+v, %v; want bob's empty library", library, err)
+	}
+}
+
+func TestRepoProvider(t *testing.T) {
+	t.Parallel()
+
+	project := t.TempDir()
+	provider, err := NewProvider(storage.SnippetSyncConfig{Provider: ProviderRepo}, t.TempDir(), project)
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	ctx := context.Background()
+	library, revision, err := provider.Pull(ctx)
+	if err != nil || revision != "" || len(library.Snippets) != 0 {
+		t.Fatalf("Pull(empty) = %+v, %q, %v; want empty library", library, revision, err)
+	}
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pushed, err := provider.Push(ctx, Library{Snippets: []Snippet{
+		{ID: "sn_a", Name: "Seed DB", Content: "package main\n", UpdatedAt: updated},
+	}}, revision)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	dir := filepath.Join(project, ".gopoke", "snippets")
+	raw, err := os.ReadFile(filepath.Join(dir, "seed_db.go"))
+	if err != nil || !strings.HasSuffix(string(raw), "\npackage main\n") {
+		t.Fatalf("snippet file = %q, %v", raw, err)
+	}
+
+	// A teammate's file arrives through git without a header.
+	if err := os.WriteFile(filepath.Join(dir, "ping.go"), []byte("package main // ping\n"), 0o644); err != nil {
+		t.Fatalf("write teammate file: %v", err)
+	}
+	if _, err := provider.Push(ctx, Library{}, pushed); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Push(stale) error = %v, want ErrConflict", err)
+	}
+	library, revision, err = provider.Pull(ctx)
+	if err != nil || len(library.Snippets) != 2 {
+		t.Fatalf("Pull() = %+v, %v; want two snippets", library, err)
+	}
+	ping, seed := library.Snippets[0], library.Snippets[1]
+	if ping.ID != "file:ping" || ping.Name != "ping" || ping.Content != "package main // ping\n" {
+		t.Fatalf("teammate snippet = %+v", ping)
+	}
+	if seed.ID != "sn_a" || seed.Name != "Seed DB" || seed.Content != "package main\n" || !seed.UpdatedAt.Equal(updated) {
+		t.Fatalf("pushed snippet = %+v", seed)
+	}
+
+	if _, err := provider.Push(ctx, Library{Snippets: []Snippet{ping}}, revision); err != nil {
+		t.Fatalf("Push(delete) error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "ping.go" {
+		t.Fatalf("snippet directory = %v, %v; want only ping.go", entries, err)
+	}
+}
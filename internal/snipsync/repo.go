@@ -0,0 +1,203 @@
+package snipsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopoke/internal/snippetmeta"
+)
+
+// repoHeaderPrefix starts the first line of each snippet file the repo
+// provider writes; the rest of the line is the snippet's JSON metadata.
+const repoHeaderPrefix = "// gopoke:snippet "
+
+// repoFileIDPrefix marks IDs of snippet files written without a header, such
+// as ones added by hand, which are identified by their file name.
+const repoFileIDPrefix = "file:"
+
+var repoFileNameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// RepoProvider keeps the library as one .go file per snippet in a directory
+// of the project, so the snippets can be committed and travel with the
+// code. The directory must be one the go command ignores, or the snippets'
+// main packages would break `go build ./...`. The revision is a hash of the
+// directory's snippet files, so edits made by a git pull or by hand show up
+// as remote changes.
+type RepoProvider struct {
+	// Dir is the absolute snippet directory.
+	Dir string
+}
+
+type repoHeader struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Name implements Provider.
+func (p *RepoProvider) Name() string {
+	return ProviderRepo
+}
+
+// Pull implements Provider.
+func (p *RepoProvider) Pull(ctx context.Context) (Library, string, error) {
+	if err := ctx.Err(); err != nil {
+		return Library{}, "", fmt.Errorf("read snippet directory context: %w", err)
+	}
+	files, revision, err := p.read()
+	if err != nil {
+		return Library{}, "", err
+	}
+	library := Library{Version: LibraryVersion, Snippets: make([]Snippet, 0, len(files))}
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		snippet := file.snippet
+		if seen[snippet.ID] {
+			return Library{}, "", fmt.Errorf("snippet ID %q appears in more than one file of %s", snippet.ID, p.Dir)
+		}
+		seen[snippet.ID] = true
+		library.Snippets = append(library.Snippets, snippet)
+	}
+	return library, revision, nil
+}
+
+// Push implements Provider.
+func (p *RepoProvider) Push(ctx context.Context, library Library, baseRevision string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("write snippet directory context: %w", err)
+	}
+	files, revision, err := p.read()
+	if err != nil {
+		return "", err
+	}
+	if revision != baseRevision {
+		return "", ErrConflict
+	}
+	if err := os.MkdirAll(p.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("create snippet directory: %w", err)
+	}
+
+	written := make(map[string]bool, len(library.Snippets))
+	for _, snippet := range library.Snippets {
+		name := repoFileName(snippet.Name, written)
+		written[name] = true
+		header, err := json.Marshal(repoHeader{ID: snippet.ID, Name: snippet.Name, UpdatedAt: snippet.UpdatedAt.UTC()})
+		if err != nil {
+			return "", fmt.Errorf("encode snippet header: %w", err)
+		}
+		content := repoHeaderPrefix + string(header) + "\n" + snippet.Content
+		if err := writeFileAtomic(filepath.Join(p.Dir, name), []byte(content)); err != nil {
+			return "", err
+		}
+	}
+	for _, file := range files {
+		if written[file.name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(p.Dir, file.name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("remove snippet file: %w", err)
+		}
+	}
+	_, revision, err = p.read()
+	return revision, err
+}
+
+type repoFile struct {
+	name    string
+	snippet Snippet
+}
+
+// read loads the snippet files sorted by name and hashes them. A missing
+// directory is empty and has the empty revision.
+func (p *RepoProvider) read() ([]repoFile, string, error) {
+	entries, err := os.ReadDir(p.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("read snippet directory: %w", err)
+	}
+	files := make([]repoFile, 0, len(entries))
+	hash := sha256.New()
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".go" {
+			continue
+		}
+		path := filepath.Join(p.Dir, entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("read snippet file: %w", err)
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, "", fmt.Errorf("inspect snippet file: %w", err)
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", entry.Name(), len(raw))
+		hash.Write(raw)
+		files = append(files, repoFile{name: entry.Name(), snippet: parseRepoFile(entry.Name(), string(raw), info.ModTime())})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	if len(files) == 0 {
+		return files, "", nil
+	}
+	return files, hex.EncodeToString(hash.Sum(nil)[:16]), nil
+}
+
+// parseRepoFile reads a snippet file. Files without a valid header are
+// named by their //gopoke:name directive or after the file, and dated by
+// their modification time.
+func parseRepoFile(fileName string, raw string, modTime time.Time) Snippet {
+	if first, rest, ok := strings.Cut(raw, "\n"); ok {
+		if metadata, found := strings.CutPrefix(first, repoHeaderPrefix); found {
+			var header repoHeader
+			if err := json.Unmarshal([]byte(metadata), &header); err == nil && header.ID != "" && header.Name != "" {
+				return Snippet{ID: header.ID, Name: header.Name, Content: rest, UpdatedAt: header.UpdatedAt}
+			}
+		}
+	}
+	base := strings.TrimSuffix(fileName, ".go")
+	name := base
+	if meta, err := snippetmeta.Parse(raw); err == nil && meta.Name != "" {
+		name = meta.Name
+	}
+	return Snippet{ID: repoFileIDPrefix + base, Name: name, Content: raw, UpdatedAt: modTime.UTC()}
+}
+
+// repoFileName derives a file name from a snippet name that is not in
+// taken.
+func repoFileName(snippetName string, taken map[string]bool) string {
+	base := strings.Trim(repoFileNameInvalid.ReplaceAllString(strings.ToLower(snippetName), "_"), "_")
+	if base == "" {
+		base = "snippet"
+	}
+	if len(base) > 64 {
+		base = base[:64]
+	}
+	name := base + ".go"
+	for suffix := 2; taken[name]; suffix++ {
+		name = fmt.Sprintf("%s_%d.go", base, suffix)
+	}
+	return name
+}
+
+// writeFileAtomic replaces path with data through a temporary file.
+func writeFileAtomic(path string, data []byte) error {
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, data, 0o644); err != nil {
+		return fmt.Errorf("write snippet file: %w", err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("replace snippet file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+// Package snipsync shares a project's snippets through a remote library
+// so a team can keep a common toolkit. A library is one JSON document held
+// by a provider (a git repository or a WebDAV server), or a directory of
+// snippet files inside the project itself. Sync merges it with
+// the local snippets against the state of the previous sync: one-sided
+// changes and deletions are carried over, and when both sides changed a
+// snippet the newer edit wins while the other is kept as a conflict copy.
+package snipsync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopoke/internal/storage"
+)
+
+// LibraryVersion is the library document format this build writes.
+const LibraryVersion = 1
+
+// maxSyncAttempts bounds retries when the library changes mid-sync.
+const maxSyncAttempts = 3
+
+// ErrConflict reports that the remote library moved past the revision a
+// push was based on.
+var ErrConflict = errors.New("remote library changed during sync")
+
+// Snippet is one snippet in a library. ID is the library's identity for
+// the snippet and may differ from the local snippet ID.
+type Snippet struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Library is the document a provider stores.
+type Library struct {
+	Version  int       `json:"version"`
+	Snippets []Snippet `json:"snippets"`
+}
+
+// Provider stores a library remotely.
+type Provider interface {
+	Name() string
+	// Pull returns the library and its revision; an empty revision means
+	// the library does not exist yet.
+	Pull(ctx context.Context) (Library, string, error)
+	// Push stores library if the remote is still at baseRevision and
+	// returns the new revision, or ErrConflict.
+	Push(ctx context.Context, library Library, baseRevision string) (string, error)
+}
+
+// Local reads and writes the project's snippets.
+type Local interface {
+	Snippets(ctx context.Context) ([]storage.SnippetRecord, error)
+	// Apply upserts and deletes snippets in one update and returns the
+	// upserted records, with IDs assigned, in order.
+	Apply(ctx context.Context, upserts []storage.SnippetRecord, deleteIDs []string) ([]storage.SnippetRecord, error)
+}
+
+// State is what a project saw at its last sync.
+type State struct {
+	Revision string `json:"revision"`
+	// Entries are keyed by library snippet ID.
+	Entries map[string]StateEntry `json:"entries"`
+}
+
+// StateEntry links a library snippet to its local copy.
+type StateEntry struct {
+	LocalID string `json:"localId"`
+	// Hash is the content hash both sides agreed on; empty when the
+	// snippet has not completed a sync yet.
+	Hash string `json:"hash,omitempty"`
+}
+
+// Conflict resolutions.
+const (
+	// ResolutionNewerWins keeps the newer edit and saves the other as a
+	// copy.
+	ResolutionNewerWins = "newer_wins"
+	// ResolutionRestored keeps a snippet one side deleted because the
+	// other side edited it.
+	ResolutionRestored = "restored"
+)
+
+// Conflict describes a snippet both sides touched.
+type Conflict struct {
+	Name       string `json:"name"`
+	Resolution string `json:"resolution"`
+	// Winner is "local" or "remote".
+	Winner string `json:"winner"`
+	// CopyName names the conflict copy holding the losing edit.
+	CopyName string `json:"copyName,omitempty"`
+}
+
+// Report summarizes a sync.
+type Report struct {
+	Provider string `json:"provider"`
+	Revision string `json:"revision"`
+	// Pulled and DeletedLocal count local snippets written or removed.
+	Pulled       int `json:"pulled"`
+	DeletedLocal int `json:"deletedLocal"`
+	// Pushed and DeletedRemote count library snippets written or removed.
+	Pushed        int        `json:"pushed"`
+	DeletedRemote int        `json:"deletedRemote"`
+	Conflicts     []Conflict `json:"conflicts"`
+}
+
+// Sync merges the library with the local snippets, writes the merge to
+// both sides and returns the state to keep for the next sync. Local
+// changes are applied before the push, so a failed push leaves local
+// snippets merged and the next sync only pushes.
+func Sync(ctx context.Context, provider Provider, local Local, state State) (Report, State, error) {
+	report := Report{Provider: provider.Name(), Conflicts: make([]Conflict, 0)}
+	if state.Entries == nil {
+		state.Entries = make(map[string]StateEntry)
+	}
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return Report{}, State{}, fmt.Errorf("snippet sync context: %w", err)
+		}
+		library, revision, err := provider.Pull(ctx)
+		if err != nil {
+			return Report{}, State{}, fmt.Errorf("pull library: %w", err)
+		}
+		snippets, err := local.Snippets(ctx)
+		if err != nil {
+			return Report{}, State{}, fmt.Errorf("load local snippets: %w", err)
+		}
+
+		plan := merge(snippets, library, state)
+		applied, err := local.Apply(ctx, plan.upserts, plan.deletes)
+		if err != nil {
+			return Report{}, State{}, fmt.Errorf("apply library to local snippets: %w", err)
+		}
+		report.Pulled += len(plan.upserts)
+		report.DeletedLocal += len(plan.deletes)
+		report.Conflicts = append(report.Conflicts, plan.conflicts...)
+
+		entries := make(map[string]StateEntry, len(plan.library.Snippets))
+		for index, snippet := range plan.library.Snippets {
+			localID := plan.localIDs[index]
+			if upsert := plan.upsertOf[index]; upsert >= 0 {
+				localID = applied[upsert].ID
+			}
+			entries[snippet.ID] = StateEntry{LocalID: localID, Hash: snippetHash(snippet.Name, snippet.Content)}
+		}
+
+		if !plan.push {
+			report.Revision = revision
+			return report, State{Revision: revision, Entries: entries}, nil
+		}
+		newRevision, err := provider.Push(ctx, plan.library, revision)
+		if errors.Is(err, ErrConflict) && attempt < maxSyncAttempts {
+			// Keep the old agreement but remember local IDs, so the retry
+			// sees the local merge result as local edits.
+			for id, entry := range entries {
+				previous := state.Entries[id]
+				previous.LocalID = entry.LocalID
+				state.Entries[id] = previous
+			}
+			continue
+		}
+		if err != nil {
+			return Report{}, State{}, fmt.Errorf("push library: %w", err)
+		}
+		report.Pushed = plan.pushed
+		report.DeletedRemote = plan.deletedRemote
+		report.Revision = newRevision
+		return report, State{Revision: newRevision, Entries: entries}, nil
+	}
+}
+
+// mergePlan is the outcome of merging one pull with the local snippets.
+type mergePlan struct {
+	library Library
+	// localIDs holds the local ID of each library snippet, empty for
+	// snippets to insert; upsertOf is the index of its upsert or -1.
+	localIDs      []string
+	upsertOf      []int
+	upserts       []storage.SnippetRecord
+	deletes       []string
+	conflicts     []Conflict
+	push          bool
+	pushed        int
+	deletedRemote int
+}
+
+type mergeEntry struct {
+	snippet Snippet
+	localID string
+}
+
+func merge(local []storage.SnippetRecord, remote Library, state State) mergePlan {
+	libraryIDs := make(map[string]string, len(state.Entries))
+	for id, entry := range state.Entries {
+		if entry.LocalID != "" {
+			libraryIDs[entry.LocalID] = id
+		}
+	}
+	localByID := make(map[string]storage.SnippetRecord, len(local))
+	for _, record := range local {
+		id := libraryIDs[record.ID]
+		if id == "" {
+			id = record.ID
+		}
+		localByID[id] = record
+	}
+	remoteByID := make(map[string]Snippet, len(remote.Snippets))
+	for _, snippet := range remote.Snippets {
+		remoteByID[snippet.ID] = snippet
+	}
+	ids := slices.Sorted(maps.Keys(localByID))
+	for id := range remoteByID {
+		if _, ok := localByID[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	var plan mergePlan
+	entries := make([]mergeEntry, 0, len(ids))
+	for _, id := range ids {
+		localRecord, hasLocal := localByID[id]
+		remoteSnippet, hasRemote := remoteByID[id]
+		base, hasBase := state.Entries[id]
+		hasBase = hasBase && base.Hash != ""
+		localSnippet := Snippet{ID: id, Name: localRecord.Name, Content: localRecord.Content, UpdatedAt: localRecord.UpdatedAt}
+		localHash := snippetHash(localRecord.Name, localRecord.Content)
+		remoteHash := snippetHash(remoteSnippet.Name, remoteSnippet.Content)
+
+		switch {
+		case hasLocal && hasRemote:
+			localChanged := !hasBase || localHash != base.Hash
+			remoteChanged := !hasBase || remoteHash != base.Hash
+			switch {
+			case localHash == remoteHash || (localChanged && !remoteChanged):
+				entries = append(entries, mergeEntry{snippet: localSnippet, localID: localRecord.ID})
+			case !localChanged:
+				entries = append(entries, mergeEntry{snippet: remoteSnippet, localID: localRecord.ID})
+			default:
+				winner, loser, winnerSide, loserSide := remoteSnippet, localSnippet, "remote", "local"
+				if localSnippet.UpdatedAt.After(remoteSnippet.UpdatedAt) {
+					winner, loser, winnerSide, loserSide = localSnippet, remoteSnippet, "local", "remote"
+				}
+				entries = append(entries, mergeEntry{snippet: winner, localID: localRecord.ID})
+				copySnippet := loser
+				copySnippet.ID = id + "-" + loserSide + "-" + strconv.FormatInt(loser.UpdatedAt.UnixNano(), 36)
+				copySnippet.Name = loser.Name + " (" + loserSide + " conflict)"
+				_, copiedLocally := localByID[copySnippet.ID]
+				_, copiedRemotely := remoteByID[copySnippet.ID]
+				if copiedLocally || copiedRemotely {
+					// A retried sync already saved this copy.
+					continue
+				}
+				entries = append(entries, mergeEntry{snippet: copySnippet})
+				plan.conflicts = append(plan.conflicts, Conflict{
+					Name: winner.Name, Resolution: ResolutionNewerWins, Winner: winnerSide, CopyName: copySnippet.Name,
+				})
+			}
+		case hasLocal:
+			switch {
+			case !hasBase:
+				entries = append(entries, mergeEntry{snippet: localSnippet, localID: localRecord.ID})
+			case localHash == base.Hash:
+				// Deleted from the library; dropping it here deletes it locally.
+			default:
+				entries = append(entries, mergeEntry{snippet: localSnippet, localID: localRecord.ID})
+				plan.conflicts = append(plan.conflicts, Conflict{Name: localSnippet.Name, Resolution: ResolutionRestored, Winner: "local"})
+			}
+		default:
+			switch {
+			case !hasBase:
+				entries = append(entries, mergeEntry{snippet: remoteSnippet})
+			case remoteHash == base.Hash:
+				// Deleted locally; dropping it here deletes it from the library.
+			default:
+				entries = append(entries, mergeEntry{snippet: remoteSnippet})
+				plan.conflicts = append(plan.conflicts, Conflict{Name: remoteSnippet.Name, Resolution: ResolutionRestored, Winner: "remote"})
+			}
+		}
+	}
+	dedupeNames(entries)
+
+	plan.library = Library{Version: LibraryVersion, Snippets: make([]Snippet, 0, len(entries))}
+	kept := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		plan.library.Snippets = append(plan.library.Snippets, entry.snippet)
+		plan.localIDs = append(plan.localIDs, entry.localID)
+		upsert := -1
+		current, exists := localByID[entry.snippet.ID]
+		if entry.localID != "" {
+			kept[entry.localID] = true
+		}
+		if entry.localID == "" || !exists || current.Name != entry.snippet.Name || current.Content != entry.snippet.Content {
+			upsert = len(plan.upserts)
+			plan.upserts = append(plan.upserts, storage.SnippetRecord{
+				ID:        entry.localID,
+				Name:      entry.snippet.Name,
+				Content:   entry.snippet.Content,
+				UpdatedAt: entry.snippet.UpdatedAt,
+			})
+		}
+		plan.upsertOf = append(plan.upsertOf, upsert)
+
+		previous, inRemote := remoteByID[entry.snippet.ID]
+		if !inRemote || previous.Name != entry.snippet.Name || previous.Content != entry.snippet.Content {
+			plan.pushed++
+		}
+	}
+	for _, record := range local {
+		if !kept[record.ID] {
+			plan.deletes = append(plan.deletes, record.ID)
+		}
+	}
+	libraryIDsKept := make(map[string]bool, len(plan.library.Snippets))
+	for _, snippet := range plan.library.Snippets {
+		libraryIDsKept[snippet.ID] = true
+	}
+	for id := range remoteByID {
+		if !libraryIDsKept[id] {
+			plan.deletedRemote++
+		}
+	}
+	plan.push = plan.pushed > 0 || plan.deletedRemote > 0 || remote.Version != LibraryVersion
+	return plan
+}
+
+// dedupeNames renames later entries whose names collide, ignoring case, as
+// local snippet names must be unique.
+func dedupeNames(entries []mergeEntry) {
+	taken := make(map[string]bool, len(entries))
+	for index := range entries {
+		name := entries[index].snippet.Name
+		candidate := name
+		for suffix := 2; taken[strings.ToLower(candidate)]; suffix++ {
+			candidate = fmt.Sprintf("%s (%d)", name, suffix)
+		}
+		taken[strings.ToLower(candidate)] = true
+		entries[index].snippet.Name = candidate
+	}
+}
+
+func snippetHash(name string, content string) string {
+	sum := sha256.Sum256([]byte(name + "\x00" + content))
+	return hex.EncodeToString(sum[:16])
+}
+
+// LoadState reads the state saved at path; a missing file is an empty
+// state.
+func LoadState(path string) (State, error) {
+	state := State{Entries: make(map[string]StateEntry)}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("read sync state: %w", err)
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return State{}, fmt.Errorf("decode sync state: %w", err)
+	}
+	if state.Entries == nil {
+		state.Entries = make(map[string]StateEntry)
+	}
+	return state, nil
+}
+
+// SaveState writes state to path atomically.
+func SaveState(path string, state State) error {
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode sync state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create sync state dir: %w", err)
+	}
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, raw, 0o600); err != nil {
+		return fmt.Errorf("write sync state: %w", err)
+	}
+	if err := os.Rename(temp, path); err != nil {
+		return fmt.Errorf("replace sync state: %w", err)
+	}
+	return nil
+}
+
+// decodeLibrary parses a library document; empty input is an empty
+// library.
+func decodeLibrary(raw []byte) (Library, error) {
+	library := Library{Version: LibraryVersion, Snippets: make([]Snippet, 0)}
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return library, nil
+	}
+	if err := json.Unmarshal(raw, &library); err != nil {
+		return Library{}, fmt.Errorf("decode library: %w", err)
+	}
+	if library.Version > LibraryVersion {
+		return Library{}, fmt.Errorf("library version %d is newer than supported version %d", library.Version, LibraryVersion)
+	}
+	seen := make(map[string]bool, len(library.Snippets))
+	for _, snippet := range library.Snippets {
+		if snippet.ID == "" || seen[snippet.ID] {
+			return Library{}, fmt.Errorf("library has a missing or duplicate snippet ID %q", snippet.ID)
+		}
+		seen[snippet.ID] = true
+	}
+	return library, nil
+}
+
+func encodeLibrary(library Library) ([]byte, error) {
+	library.Version = LibraryVersion
+	raw, err := json.MarshalIndent(library, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode library: %w", err)
+	}
+	return append(raw, '\n'), nil
+}
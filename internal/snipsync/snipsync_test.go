@@ -0,0 +1,279 @@
+package snipsync
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopoke/internal/storage"
+)
+
+// memoryProvider is a library held in memory with integer revisions.
+type memoryProvider struct {
+	library  Library
+	revision int
+	// beforePush runs once before the next push, to simulate a concurrent
+	// writer.
+	beforePush func(*memoryProvider)
+}
+
+func (p *memoryProvider) Name() string { return "memory" }
+
+func (p *memoryProvider) Pull(ctx context.Context) (Library, string, error) {
+	if p.revision == 0 {
+		return Library{Version: LibraryVersion}, "", nil
+	}
+	return Library{Version: p.library.Version, Snippets: slices.Clone(p.library.Snippets)}, fmt.Sprint(p.revision), nil
+}
+
+func (p *memoryProvider) Push(ctx context.Context, library Library, baseRevision string) (string, error) {
+	if hook := p.beforePush; hook != nil {
+		p.beforePush = nil
+		hook(p)
+	}
+	current := ""
+	if p.revision > 0 {
+		current = fmt.Sprint(p.revision)
+	}
+	if baseRevision != current {
+		return "", ErrConflict
+	}
+	p.library = library
+	p.revision++
+	return fmt.Sprint(p.revision), nil
+}
+
+// memoryLocal is one project's snippets.
+type memoryLocal struct {
+	snippets []storage.SnippetRecord
+	nextID   int
+}
+
+func (l *memoryLocal) Snippets(ctx context.Context) ([]storage.SnippetRecord, error) {
+	return slices.Clone(l.snippets), nil
+}
+
+func (l *memoryLocal) Apply(ctx context.Context, upserts []storage.SnippetRecord, deleteIDs []string) ([]storage.SnippetRecord, error) {
+	l.snippets = slices.DeleteFunc(l.snippets, func(record storage.SnippetRecord) bool {
+		return slices.Contains(deleteIDs, record.ID)
+	})
+	applied := make([]storage.SnippetRecord, 0, len(upserts))
+	for _, record := range upserts {
+		index := slices.IndexFunc(l.snippets, func(existing storage.SnippetRecord) bool { return existing.ID == record.ID })
+		if record.ID == "" || index < 0 {
+			l.nextID++
+			record.ID = fmt.Sprintf("local-%d", l.nextID)
+			l.snippets = append(l.snippets, record)
+		} else {
+			l.snippets[index] = record
+		}
+		applied = append(applied, record)
+	}
+	return applied, nil
+}
+
+func (l *memoryLocal) add(id string, name string, content string, updatedAt time.Time) {
+	l.snippets = append(l.snippets, storage.SnippetRecord{ID: id, Name: name, Content: content, UpdatedAt: updatedAt})
+}
+
+func (l *memoryLocal) edit(name string, content string, updatedAt time.Time) {
+	for index := range l.snippets {
+		if l.snippets[index].Name == name {
+			l.snippets[index].Content = content
+			l.snippets[index].UpdatedAt = updatedAt
+		}
+	}
+}
+
+func (l *memoryLocal) contents() map[string]string {
+	result := make(map[string]string, len(l.snippets))
+	for _, record := range l.snippets {
+		result[record.Name] = record.Content
+	}
+	return result
+}
+
+func runSync(t *testing.T, provider Provider, local *memoryLocal, state State) (Report, State) {
+	t.Helper()
+	report, next, err := Sync(context.Background(), provider, local, state)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	return report, next
+}
+
+var epoch = time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+func TestSyncSharesSnippetsBetweenProjects(t *testing.T) {
+	t.Parallel()
+
+	provider := &memoryProvider{}
+	alice, bob := &memoryLocal{}, &memoryLocal{}
+	alice.add("sn_a", "http probe", "package main // probe", epoch)
+	bob.add("sn_b", "json dump", "package main // dump", epoch)
+
+	report, aliceState := runSync(t, provider, alice, State{})
+	if report.Pushed != 1 || report.Pulled != 0 {
+		t.Fatalf("first sync report = %+v, want one pushed", report)
+	}
+	_, bobState := runSync(t, provider, bob, State{})
+	_, aliceState = runSync(t, provider, alice, aliceState)
+
+	want := map[string]string{"http probe": "package main // probe", "json dump": "package main // dump"}
+	for name, local := range map[string]*memoryLocal{"alice": alice, "bob": bob} {
+		if got := local.contents(); len(got) != 2 || got["http probe"] != want["http probe"] || got["json dump"] != want["json dump"] {
+			t.Fatalf("%s snippets = %v, want %v", name, got, want)
+		}
+	}
+
+	// A one-sided edit and a deletion travel to the other project.
+	bob.edit("http probe", "package main // probe v2", epoch.Add(time.Hour))
+	bob.snippets = slices.DeleteFunc(bob.snippets, func(record storage.SnippetRecord) bool { return record.Name == "json dump" })
+	report, _ = runSync(t, provider, bob, bobState)
+	if report.Pushed != 1 || report.DeletedRemote != 1 {
+		t.Fatalf("bob report = %+v, want one pushed and one deleted", report)
+	}
+	report, _ = runSync(t, provider, alice, aliceState)
+	if report.DeletedLocal != 1 || len(report.Conflicts) != 0 {
+		t.Fatalf("alice report = %+v, want one local deletion", report)
+	}
+	if got := alice.contents(); len(got) != 1 || got["http probe"] != "package main // probe v2" {
+		t.Fatalf("alice snippets = %v, want the edited probe only", got)
+	}
+}
+
+func TestSyncConflictKeepsNewerEditAndCopy(t *testing.T) {
+	t.Parallel()
+
+	provider := &memoryProvider{}
+	alice, bob := &memoryLocal{}, &memoryLocal{}
+	alice.add("sn_a", "probe", "v1", epoch)
+	_, aliceState := runSync(t, provider, alice, State{})
+	_, bobState := runSync(t, provider, bob, State{})
+
+	alice.edit("probe", "alice edit", epoch.Add(time.Minute))
+	bob.edit("probe", "bob edit", epoch.Add(2*time.Minute))
+	runSync(t, provider, bob, bobState)
+	report, _ := runSync(t, provider, alice, aliceState)
+
+	if len(report.Conflicts) != 1 {
+		t.Fatalf("Conflicts = %+v, want one", report.Conflicts)
+	}
+	conflict := report.Conflicts[0]
+	if conflict.Resolution != ResolutionNewerWins || conflict.Winner != "remote" || conflict.CopyName != "probe (local conflict)" {
+		t.Fatalf("conflict = %+v, want remote win with local copy", conflict)
+	}
+	got := alice.contents()
+	if got["probe"] != "bob edit" || got["probe (local conflict)"] != "alice edit" {
+		t.Fatalf("alice snippets = %v, want bob's edit and a copy of alice's", got)
+	}
+	if len(provider.library.Snippets) != 2 {
+		t.Fatalf("library = %+v, want both versions", provider.library.Snippets)
+	}
+}
+
+func TestSyncRestoresSnippetEditedWhileDeleted(t *testing.T) {
+	t.Parallel()
+
+	provider := &memoryProvider{}
+	alice, bob := &memoryLocal{}, &memoryLocal{}
+	alice.add("sn_a", "probe", "v1", epoch)
+	_, aliceState := runSync(t, provider, alice, State{})
+	_, bobState := runSync(t, provider, bob, State{})
+
+	bob.snippets = nil
+	runSync(t, provider, bob, bobState)
+	alice.edit("probe", "v2", epoch.Add(time.Minute))
+	report, _ := runSync(t, provider, alice, aliceState)
+
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Resolution != ResolutionRestored {
+		t.Fatalf("Conflicts = %+v, want one restore", report.Conflicts)
+	}
+	if len(provider.library.Snippets) != 1 || provider.library.Snippets[0].Content != "v2" {
+		t.Fatalf("library = %+v, want restored v2", provider.library.Snippets)
+	}
+}
+
+func TestSyncRenamesCollidingNames(t *testing.T) {
+	t.Parallel()
+
+	provider := &memoryProvider{}
+	alice, bob := &memoryLocal{}, &memoryLocal{}
+	alice.add("sn_a", "Probe", "alice", epoch)
+	bob.add("sn_b", "probe", "bob", epoch)
+	runSync(t, provider, alice, State{})
+	runSync(t, provider, bob, State{})
+
+	names := make([]string, 0, 2)
+	for _, record := range bob.snippets {
+		names = append(names, record.Name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"Probe", "probe (2)"}) {
+		t.Fatalf("bob names = %v, want Probe and probe (2)", names)
+	}
+}
+
+func TestSyncRetriesWhenLibraryMovesDuringPush(t *testing.T) {
+	t.Parallel()
+
+	provider := &memoryProvider{}
+	alice := &memoryLocal{}
+	alice.add("sn_a", "probe", "alice", epoch)
+	provider.beforePush = func(p *memoryProvider) {
+		p.library = Library{Version: LibraryVersion, Snippets: []Snippet{{ID: "sn_c", Name: "carol", Content: "carol", UpdatedAt: epoch}}}
+		p.revision++
+	}
+
+	report, state := runSync(t, provider, alice, State{})
+	if got := alice.contents(); len(got) != 2 || got["carol"] != "carol" {
+		t.Fatalf("alice snippets = %v, want carol's snippet pulled on retry", got)
+	}
+	if len(provider.library.Snippets) != 2 || report.Revision != "2" || state.Revision != "2" {
+		t.Fatalf("library = %+v revision %q, want both snippets at revision 2", provider.library.Snippets, report.Revision)
+	}
+
+	// The next sync finds nothing to do.
+	report, _ = runSync(t, provider, alice, state)
+	if report.Pushed != 0 || report.Pulled != 0 || report.DeletedLocal != 0 || report.DeletedRemote != 0 {
+		t.Fatalf("follow-up report = %+v, want no changes", report)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := t.TempDir() + "/state/project.json"
+	empty, err := LoadState(path)
+	if err != nil || len(empty.Entries) != 0 {
+		t.Fatalf("LoadState(missing) = %+v, %v; want empty state", empty, err)
+	}
+	state := State{Revision: "abc", Entries: map[string]StateEntry{"sn_a": {LocalID: "sn_1", Hash: "h"}}}
+	if err := SaveState(path, state); err != nil {
+		t.Fatalf("SaveState() error = %v", err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() error = %v", err)
+	}
+	if loaded.Revision != "abc" || loaded.Entries["sn_a"].LocalID != "sn_1" {
+		t.Fatalf("LoadState() = %+v, want saved state", loaded)
+	}
+}
+
+func TestDecodeLibraryRejectsBadDocuments(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{
+		`{"version": 99, "snippets": []}`,
+		`{"version": 1, "snippets": [{"id": "a"}, {"id": "a"}]}`,
+		`not json`,
+	} {
+		if _, err := decodeLibrary([]byte(raw)); err == nil {
+			t.Errorf("decodeLibrary(%s) error = nil, want error", strings.TrimSpace(raw))
+		}
+	}
+}
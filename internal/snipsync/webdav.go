@@ -0,0 +1,149 @@
+package snipsync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxLibraryBytes bounds a downloaded library document.
+const maxLibraryBytes = 16 << 20
+
+// contentRevisionPrefix marks revisions derived from the document when a
+// server sends no ETag.
+const contentRevisionPrefix = "sha256:"
+
+var webdavClient = &http.Client{Timeout: 30 * time.Second}
+
+// WebDAVProvider keeps the library as one file on a WebDAV server. ETags
+// guard pushes; servers without ETags are checked by re-reading the file
+// before writing, which narrows but does not close the race.
+type WebDAVProvider struct {
+	URL      string
+	Username string
+	Password string
+	// Client overrides the default HTTP client.
+	Client *http.Client
+}
+
+// Name implements Provider.
+func (p *WebDAVProvider) Name() string {
+	return ProviderWebDAV
+}
+
+// Pull implements Provider.
+func (p *WebDAVProvider) Pull(ctx context.Context) (Library, string, error) {
+	raw, revision, err := p.get(ctx)
+	if err != nil {
+		return Library{}, "", err
+	}
+	library, err := decodeLibrary(raw)
+	if err != nil {
+		return Library{}, "", err
+	}
+	return library, revision, nil
+}
+
+// Push implements Provider.
+func (p *WebDAVProvider) Push(ctx context.Context, library Library, baseRevision string) (string, error) {
+	raw, err := encodeLibrary(library)
+	if err != nil {
+		return "", err
+	}
+	request, err := p.newRequest(ctx, http.MethodPut, bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	switch {
+	case baseRevision == "":
+		request.Header.Set("If-None-Match", "*")
+	case strings.HasPrefix(baseRevision, contentRevisionPrefix):
+		_, current, err := p.get(ctx)
+		if err != nil {
+			return "", err
+		}
+		if current != baseRevision {
+			return "", ErrConflict
+		}
+	default:
+		request.Header.Set("If-Match", baseRevision)
+	}
+
+	response, err := p.client().Do(request)
+	if err != nil {
+		return "", fmt.Errorf("upload library: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxLibraryBytes))
+	switch {
+	case response.StatusCode == http.StatusPreconditionFailed:
+		return "", ErrConflict
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		return "", fmt.Errorf("upload library: HTTP %d", response.StatusCode)
+	}
+	if etag := response.Header.Get("ETag"); etag != "" {
+		return etag, nil
+	}
+	return contentRevision(raw), nil
+}
+
+// get downloads the library file; a missing file is empty with no
+// revision.
+func (p *WebDAVProvider) get(ctx context.Context) ([]byte, string, error) {
+	request, err := p.newRequest(ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	response, err := p.client().Do(request)
+	if err != nil {
+		return nil, "", fmt.Errorf("download library: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return nil, "", nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download library: HTTP %d", response.StatusCode)
+	}
+	raw, err := io.ReadAll(io.LimitReader(response.Body, maxLibraryBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read library: %w", err)
+	}
+	if len(raw) > maxLibraryBytes {
+		return nil, "", fmt.Errorf("library exceeds %d bytes", maxLibraryBytes)
+	}
+	if etag := response.Header.Get("ETag"); etag != "" {
+		return raw, etag, nil
+	}
+	return raw, contentRevision(raw), nil
+}
+
+func (p *WebDAVProvider) newRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, p.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if p.Username != "" || p.Password != "" {
+		request.SetBasicAuth(p.Username, p.Password)
+	}
+	return request, nil
+}
+
+func (p *WebDAVProvider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return webdavClient
+}
+
+func contentRevision(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return contentRevisionPrefix + hex.EncodeToString(sum[:])
+}
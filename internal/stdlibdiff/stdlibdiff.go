@@ -0,0 +1,383 @@
+// Package stdlibdiff compares the standard library of two Go releases for
+// one symbol: API added or removed, from the GOROOT/api/go1.N.txt files,
+// and behavior changes, from the GODEBUG history in GOROOT/doc/godebug.md.
+package stdlibdiff
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Feature is one exported API element as the api files list it.
+type Feature struct {
+	Package string `json:"package"`
+	// Text is the api line after the package, such as
+	// "func Cut(string, string) (string, string, bool)".
+	Text string `json:"text"`
+	// Since is the release that added it, such as "go1.18".
+	Since string `json:"since"`
+}
+
+// Note is a behavior change of one release, with its GODEBUG setting.
+type Note struct {
+	Version string `json:"version"`
+	Text    string `json:"text"`
+}
+
+// Release is the standard library API of one Go installation, including
+// every earlier release's additions.
+type Release struct {
+	// Version is the installation's version, such as "go1.22.3".
+	Version string `json:"version"`
+	// Minor is the release's minor version, 22 for go1.22.3.
+	Minor    int       `json:"minor"`
+	Features []Feature `json:"-"`
+	Notes    []Note    `json:"-"`
+}
+
+// Result is the difference of one symbol between two releases.
+type Result struct {
+	Symbol string `json:"symbol"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	// Added are features in To but not From; Removed the reverse.
+	Added   []Feature `json:"added"`
+	Removed []Feature `json:"removed"`
+	// Unchanged counts the symbol's features in both releases.
+	Unchanged int `json:"unchanged"`
+	// Notes are behavior changes mentioning the symbol in the releases
+	// after the older one, up to the newer one.
+	Notes []Note `json:"notes"`
+	// Changed reports whether the API or documented behavior differs, so
+	// a changed snippet result may come from the toolchain.
+	Changed bool `json:"changed"`
+}
+
+var (
+	apiFilePattern = regexp.MustCompile(`^go1(?:\.(\d+))?\.txt$`)
+	versionPattern = regexp.MustCompile(`^(?:go)?1(?:\.(\d+))?(?:\.\d+)?(?:rc\d+|beta\d+)?$`)
+	issueSuffix    = regexp.MustCompile(`\s+#\d+$`)
+	notesHeading   = regexp.MustCompile(`^### Go 1\.(\d+)\s*$`)
+	markdownLink   = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+)
+
+// ParseVersion returns the minor version of a Go version such as "1.22",
+// "go1.22" or "go1.22.3".
+func ParseVersion(version string) (int, error) {
+	match := versionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return 0, fmt.Errorf("invalid Go version %q", version)
+	}
+	if match[1] == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(match[1])
+}
+
+// Load reads the API files and GODEBUG history of the Go installation at
+// goroot.
+func Load(goroot string) (Release, error) {
+	apiDir := filepath.Join(goroot, "api")
+	entries, err := os.ReadDir(apiDir)
+	if err != nil {
+		return Release{}, fmt.Errorf("read api directory: %w", err)
+	}
+	release := Release{Minor: -1}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		match := apiFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		minor := 0
+		if match[1] != "" {
+			minor, _ = strconv.Atoi(match[1])
+		}
+		release.Minor = max(release.Minor, minor)
+		features, err := parseAPIFile(filepath.Join(apiDir, entry.Name()), releaseName(minor))
+		if err != nil {
+			return Release{}, err
+		}
+		for _, feature := range features {
+			key := feature.Package + "\x00" + feature.Text
+			if !seen[key] {
+				seen[key] = true
+				release.Features = append(release.Features, feature)
+			}
+		}
+	}
+	if release.Minor < 0 {
+		return Release{}, fmt.Errorf("no api files in %s", apiDir)
+	}
+	release.Version = releaseName(release.Minor)
+	if raw, err := os.ReadFile(filepath.Join(goroot, "VERSION")); err == nil {
+		first, _, _ := strings.Cut(string(raw), "\n")
+		if minor, err := ParseVersion(first); err == nil {
+			release.Version, release.Minor = strings.TrimSpace(first), minor
+		}
+	}
+	if raw, err := os.ReadFile(filepath.Join(goroot, "doc", "godebug.md")); err == nil {
+		release.Notes = ParseNotes(string(raw))
+	}
+	return release, nil
+}
+
+// AsOf returns the release as it was at an earlier minor version, derived
+// from this release's history.
+func (r Release) AsOf(minor int) Release {
+	earlier := Release{Version: releaseName(minor), Minor: minor}
+	for _, feature := range r.Features {
+		if since, _ := ParseVersion(feature.Since); since <= minor {
+			earlier.Features = append(earlier.Features, feature)
+		}
+	}
+	for _, note := range r.Notes {
+		if noted, _ := ParseVersion(note.Version); noted <= minor {
+			earlier.Notes = append(earlier.Notes, note)
+		}
+	}
+	return earlier
+}
+
+func releaseName(minor int) string {
+	if minor == 0 {
+		return "go1"
+	}
+	return "go1." + strconv.Itoa(minor)
+}
+
+// parseAPIFile reads lines such as
+// "pkg net/http, method (*Client) Do(*Request) (*Response, error) #123".
+// Platform-specific lines, "pkg syscall (linux-amd64), ...", count once.
+func parseAPIFile(path string, since string) ([]Feature, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open api file: %w", err)
+	}
+	defer file.Close()
+	var features []Feature
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "pkg ")
+		if !ok {
+			continue
+		}
+		pkg, text, ok := strings.Cut(line, ", ")
+		if !ok {
+			continue
+		}
+		if platform := strings.Index(pkg, " ("); platform >= 0 {
+			pkg = pkg[:platform]
+		}
+		features = append(features, Feature{Package: pkg, Text: issueSuffix.ReplaceAllString(text, ""), Since: since})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read api file: %w", err)
+	}
+	return features, nil
+}
+
+// ParseNotes reads the "### Go 1.N" sections of godebug.md, one note per
+// paragraph, with markdown links reduced to their text.
+func ParseNotes(markdown string) []Note {
+	var notes []Note
+	version := ""
+	var paragraph []string
+	flush := func() {
+		if version != "" && len(paragraph) > 0 {
+			notes = append(notes, Note{Version: version, Text: strings.Join(paragraph, " ")})
+		}
+		paragraph = nil
+	}
+	for line := range strings.Lines(markdown) {
+		line = strings.TrimSpace(line)
+		if match := notesHeading.FindStringSubmatch(line); match != nil {
+			flush()
+			minor, _ := strconv.Atoi(match[1])
+			version = releaseName(minor)
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			flush()
+			version = ""
+			continue
+		}
+		if line == "" {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	flush()
+	return notes
+}
+
+// Diff compares symbol between two releases. The symbol is a package, such
+// as "net/http" or "http", or a package member such as "strings.Cut",
+// "net/http.ServeMux" or "http.Client.Do"; a type includes its fields and
+// methods.
+func Diff(symbol string, from Release, to Release) (Result, error) {
+	symbol = strings.TrimSpace(symbol)
+	packages, name := resolveSymbol(symbol, append(slices.Clone(from.Features), to.Features...))
+	if len(packages) == 0 {
+		return Result{}, fmt.Errorf("no standard library package matches %q", symbol)
+	}
+	matches := func(feature Feature) bool {
+		return slices.Contains(packages, feature.Package) && (name == "" || identifierMatches(featureIdentifier(feature.Text), name))
+	}
+
+	result := Result{Symbol: symbol, From: from.Version, To: to.Version, Added: []Feature{}, Removed: []Feature{}, Notes: []Note{}}
+	inFrom := make(map[string]Feature)
+	for _, feature := range from.Features {
+		if matches(feature) {
+			inFrom[feature.Package+"\x00"+feature.Text] = feature
+		}
+	}
+	for _, feature := range to.Features {
+		if !matches(feature) {
+			continue
+		}
+		key := feature.Package + "\x00" + feature.Text
+		if _, ok := inFrom[key]; ok {
+			result.Unchanged++
+			delete(inFrom, key)
+			continue
+		}
+		result.Added = append(result.Added, feature)
+	}
+	for _, feature := range from.Features {
+		if _, ok := inFrom[feature.Package+"\x00"+feature.Text]; ok {
+			result.Removed = append(result.Removed, feature)
+		}
+	}
+
+	// Notes come from whichever release is newer, which records both.
+	lower, upper := min(from.Minor, to.Minor), max(from.Minor, to.Minor)
+	newer := to
+	if from.Minor > to.Minor {
+		newer = from
+	}
+	for _, note := range newer.Notes {
+		minor, _ := ParseVersion(note.Version)
+		if minor > lower && minor <= upper && noteMentions(note.Text, packages, name) {
+			result.Notes = append(result.Notes, Note{Version: note.Version, Text: markdownLink.ReplaceAllString(note.Text, "$1")})
+		}
+	}
+	result.Changed = len(result.Added) > 0 || len(result.Removed) > 0 || len(result.Notes) > 0
+	return result, nil
+}
+
+// resolveSymbol splits symbol into the packages it names, by full import
+// path or last path element, and the member name below them.
+func resolveSymbol(symbol string, features []Feature) ([]string, string) {
+	known := make(map[string]bool)
+	for _, feature := range features {
+		known[feature.Package] = true
+	}
+	// Try the longest package first: "net/http.Client.Do" is package
+	// "net/http", then "net/http.Client" and so on.
+	splits := []int{len(symbol)}
+	for i := len(symbol) - 1; i > 0; i-- {
+		if symbol[i] == '.' {
+			splits = append(splits, i)
+		}
+	}
+	for _, split := range splits {
+		pkg, name := symbol[:split], strings.TrimPrefix(symbol[split:], ".")
+		var packages []string
+		for path := range known {
+			if path == pkg || !strings.Contains(pkg, "/") && path[strings.LastIndex(path, "/")+1:] == pkg {
+				packages = append(packages, path)
+			}
+		}
+		if len(packages) > 0 {
+			slices.Sort(packages)
+			return packages, name
+		}
+	}
+	return nil, ""
+}
+
+// featureIdentifier names what an api line declares: "Cut" for a
+// function, "Client.Do" for a method, "Request.Pattern" for a field.
+func featureIdentifier(text string) string {
+	kind, rest, _ := strings.Cut(text, " ")
+	switch kind {
+	case "func", "const", "var":
+		return leadingIdentifier(rest)
+	case "method":
+		receiver, method, ok := strings.Cut(rest, ") ")
+		if !ok {
+			return ""
+		}
+		receiver = strings.TrimLeft(strings.TrimPrefix(receiver, "("), "*")
+		if bracket := strings.Index(receiver, "["); bracket >= 0 {
+			receiver = receiver[:bracket]
+		}
+		return receiver + "." + leadingIdentifier(method)
+	case "type":
+		typeName := leadingIdentifier(rest)
+		if _, member, ok := strings.Cut(rest, ", "); ok {
+			if embedded, ok := strings.CutPrefix(member, "embedded "); ok {
+				member = strings.TrimLeft(embedded[strings.LastIndex(embedded, ".")+1:], "*")
+			}
+			return typeName + "." + leadingIdentifier(member)
+		}
+		return typeName
+	}
+	return ""
+}
+
+func leadingIdentifier(text string) string {
+	end := strings.IndexFunc(text, func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127)
+	})
+	if end < 0 {
+		return text
+	}
+	return text[:end]
+}
+
+func identifierMatches(identifier string, name string) bool {
+	return identifier == name || strings.HasPrefix(identifier, name+".")
+}
+
+// noteMentions reports whether a behavior note concerns the symbol: it
+// names one of the packages or the symbol's outermost member.
+func noteMentions(text string, packages []string, name string) bool {
+	for _, pkg := range packages {
+		if strings.Contains(text, "/pkg/"+pkg+"/") || strings.Contains(text, "/pkg/"+pkg+"#") || containsWord(text, pkg) {
+			return true
+		}
+	}
+	if name == "" {
+		return false
+	}
+	member, _, _ := strings.Cut(name, ".")
+	return containsWord(text, member)
+}
+
+func containsWord(text string, word string) bool {
+	for start := 0; ; {
+		index := strings.Index(text[start:], word)
+		if index < 0 {
+			return false
+		}
+		index += start
+		end := index + len(word)
+		if (index == 0 || !isWordByte(text[index-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		start = index + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b == '/' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
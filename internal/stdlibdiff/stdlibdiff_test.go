@@ -0,0 +1,153 @@
+package stdlibdiff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testGodebug = `# Go, Backwards Compatibility, and GODEBUG
+
+## GODEBUG History
+
+### Go 1.22
+
+Go 1.22 changed [net/http.ServeMux](/pkg/net/http#ServeMux) patterns,
+controlled by the httpmuxgo121 setting.
+
+Go 1.22 made TLS stricter, controlled by the tlsrsakex setting.
+
+### Go 1.21
+
+Go 1.21 made ServeMux panic on nil handlers.
+`
+
+func writeGOROOT(t *testing.T, version string, files map[string]string, godebug string) string {
+	t.Helper()
+	goroot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(goroot, "api"), 0o755); err != nil {
+		t.Fatalf("create api dir: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(goroot, "api", name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(goroot, "VERSION"), []byte(version+"\ntime 2024-01-01\n"), 0o644); err != nil {
+		t.Fatalf("write VERSION: %v", err)
+	}
+	if godebug != "" {
+		if err := os.MkdirAll(filepath.Join(goroot, "doc"), 0o755); err != nil {
+			t.Fatalf("create doc dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(goroot, "doc", "godebug.md"), []byte(godebug), 0o644); err != nil {
+			t.Fatalf("write godebug.md: %v", err)
+		}
+	}
+	return goroot
+}
+
+func TestDiffReportsAPIAndBehaviorChanges(t *testing.T) {
+	t.Parallel()
+
+	base := map[string]string{
+		"go1.txt": "pkg net/http, type ServeMux struct\n" +
+			"pkg net/http, method (*ServeMux) Handle(string, Handler)\n" +
+			"pkg strings, func Index(string, string) int\n",
+		"go1.18.txt": "pkg strings, func Cut(string, string) (string, string, bool) #46336\n" +
+			"pkg syscall (linux-386), const AF_ALG = 38\n" +
+			"pkg syscall (linux-amd64), const AF_ALG = 38\n",
+	}
+	old, err := Load(writeGOROOT(t, "go1.21.5", base, ""))
+	if err != nil {
+		t.Fatalf("Load(old) error = %v", err)
+	}
+	newer := map[string]string{
+		"go1.22.txt": "pkg net/http, method (*Request) PathValue(string) string #61410\n" +
+			"pkg net/http, type Request struct, Pattern string #66405\n",
+	}
+	for name, content := range base {
+		newer[name] = content
+	}
+	current, err := Load(writeGOROOT(t, "go1.22.3", newer, testGodebug))
+	if err != nil {
+		t.Fatalf("Load(new) error = %v", err)
+	}
+	if current.Version != "go1.22.3" || current.Minor != 22 || len(current.Notes) != 3 {
+		t.Fatalf("Load(new) = %s minor %d with %d notes", current.Version, current.Minor, len(current.Notes))
+	}
+
+	result, err := Diff("http.Request", old, current)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(result.Added) != 2 || result.Added[0].Since != "go1.22" || len(result.Removed) != 0 || !result.Changed {
+		t.Fatalf("Diff(http.Request) = %+v", result)
+	}
+
+	result, err = Diff("net/http.ServeMux", old, current)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(result.Added) != 0 || result.Unchanged != 2 || len(result.Notes) != 1 || result.Notes[0].Version != "go1.22" {
+		t.Fatalf("Diff(net/http.ServeMux) = %+v", result)
+	}
+	if want := "Go 1.22 changed net/http.ServeMux patterns, controlled by the httpmuxgo121 setting."; result.Notes[0].Text != want {
+		t.Fatalf("note = %q, want %q", result.Notes[0].Text, want)
+	}
+
+	result, err = Diff("strings.Cut", current.AsOf(17), current)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if len(result.Added) != 1 || result.Added[0].Text != "func Cut(string, string) (string, string, bool)" {
+		t.Fatalf("Diff(strings.Cut) = %+v", result)
+	}
+
+	result, err = Diff("strings.Index", old, current)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if result.Changed || result.Unchanged != 1 {
+		t.Fatalf("Diff(strings.Index) = %+v", result)
+	}
+
+	if _, err := Diff("nosuchpkg.Func", old, current); err == nil {
+		t.Fatal("Diff(unknown package) error = nil")
+	}
+}
+
+func TestFeatureIdentifier(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"func Cut(string, string) (string, string, bool)":   "Cut",
+		"method (*Client) Do(*Request) (*Response, error)":  "Client.Do",
+		"method (Value[$0]) Load() $0":                      "Value.Load",
+		"type Request struct, Pattern string":               "Request.Pattern",
+		"type Reader interface, Read([]uint8) (int, error)": "Reader.Read",
+		"type Client struct":                                "Client",
+		"const AF_ALG = 38":                                 "AF_ALG",
+		"var ErrBodyNotAllowed error":                       "ErrBodyNotAllowed",
+		"type Server struct, embedded sync.Mutex":           "Server.Mutex",
+	}
+	for text, want := range tests {
+		if got := featureIdentifier(text); got != want {
+			t.Errorf("featureIdentifier(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{"1.22": 22, "go1.22": 22, "go1.22.3": 22, "go1": 0, "go1.23rc1": 23}
+	for version, want := range tests {
+		if got, err := ParseVersion(version); err != nil || got != want {
+			t.Errorf("ParseVersion(%q) = %d, %v; want %d", version, got, err, want)
+		}
+	}
+	if _, err := ParseVersion("latest"); err == nil {
+		t.Error("ParseVersion(latest) error = nil")
+	}
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ActivityRetention is how long daily activity counts are kept.
+const ActivityRetention = 90 * 24 * time.Hour
+
+// activityDayLayout formats ActivityRecord.Day.
+const activityDayLayout = "2006-01-02"
+
+// RecordActivity counts one run or edit of target in a project on the UTC
+// day of at, dropping counts older than ActivityRetention.
+func (s *Store) RecordActivity(ctx context.Context, projectID string, kind string, target string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("record activity context: %w", err)
+	}
+	if projectID == "" {
+		return fmt.Errorf("project ID is required")
+	}
+	if kind != ActivityRun && kind != ActivityEdit {
+		return fmt.Errorf("unknown activity kind %q", kind)
+	}
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("activity target is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+
+	day := at.UTC().Format(activityDayLayout)
+	oldest := at.UTC().Add(-ActivityRetention).Format(activityDayLayout)
+	activity := make([]ActivityRecord, 0, len(snapshot.Activity)+1)
+	counted := false
+	for _, record := range snapshot.Activity {
+		if record.Day < oldest {
+			continue
+		}
+		if record.ProjectID == projectID && record.Day == day && record.Kind == kind && record.Target == target {
+			record.Count++
+			counted = true
+		}
+		activity = append(activity, record)
+	}
+	if !counted {
+		activity = append(activity, ActivityRecord{ProjectID: projectID, Day: day, Kind: kind, Target: target, Count: 1})
+	}
+	snapshot.Activity = activity
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return fmt.Errorf("persist activity: %w", err)
+	}
+	return nil
+}
+
+// ProjectActivity returns a project's activity counts from the UTC day of
+// since onwards.
+func (s *Store) ProjectActivity(ctx context.Context, projectID string, since time.Time) ([]ActivityRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("project activity context: %w", err)
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("project ID is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return nil, fmt.Errorf("load state: %w", err)
+	}
+	first := since.UTC().Format(activityDayLayout)
+	result := make([]ActivityRecord, 0)
+	for _, record := range snapshot.Activity {
+		if record.ProjectID == projectID && record.Day >= first {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CreateExperiment saves a new experiment for a project. Names are unique
+// within a project, ignoring case.
+func (s *Store) CreateExperiment(ctx context.Context, record ExperimentRecord) (ExperimentRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ExperimentRecord{}, fmt.Errorf("create experiment context: %w", err)
+	}
+	if record.ProjectID == "" {
+		return ExperimentRecord{}, fmt.Errorf("project ID is required")
+	}
+	record.Name = strings.TrimSpace(record.Name)
+	if record.Name == "" {
+		return ExperimentRecord{}, fmt.Errorf("experiment name is required")
+	}
+	record.Description = strings.TrimSpace(record.Description)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ExperimentRecord{}, fmt.Errorf("load state: %w", err)
+	}
+	for _, existing := range snapshot.Experiments {
+		if existing.ProjectID == record.ProjectID && strings.EqualFold(existing.Name, record.Name) {
+			return ExperimentRecord{}, fmt.Errorf("experiment %q already exists", existing.Name)
+		}
+	}
+
+	now := time.Now().UTC()
+	record.ID = generateID("exp")
+	record.CreatedAt = now
+	record.LatestRunID = ""
+	record.LatestOutput = ""
+	snapshot.Experiments = append(snapshot.Experiments, record)
+	snapshot.Meta.UpdatedAt = now
+	if err := s.writeLocked(snapshot); err != nil {
+		return ExperimentRecord{}, fmt.Errorf("persist experiment: %w", err)
+	}
+	return record, nil
+}
+
+// ProjectExperiments returns a project's experiments in creation order.
+func (s *Store) ProjectExperiments(ctx context.Context, projectID string) ([]ExperimentRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("project experiments context: %w", err)
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("project ID is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return nil, fmt.Errorf("load state: %w", err)
+	}
+	result := make([]ExperimentRecord, 0)
+	for _, record := range snapshot.Experiments {
+		if record.ProjectID == projectID {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+// ExperimentByID returns one experiment.
+func (s *Store) ExperimentByID(ctx context.Context, experimentID string) (ExperimentRecord, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return ExperimentRecord{}, false, fmt.Errorf("experiment by id context: %w", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ExperimentRecord{}, false, fmt.Errorf("load state: %w", err)
+	}
+	for _, record := range snapshot.Experiments {
+		if record.ID == experimentID {
+			return record, true, nil
+		}
+	}
+	return ExperimentRecord{}, false, nil
+}
+
+// AssignRun attaches a run of the experiment's project to the experiment,
+// moving it from any other experiment. output is the tail of the run's
+// output, kept as the experiment's latest output when the run started
+// after its other runs.
+func (s *Store) AssignRun(ctx context.Context, experimentID string, runID string, output string) (RunRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return RunRecord{}, fmt.Errorf("assign run context: %w", err)
+	}
+	if experimentID == "" {
+		return RunRecord{}, fmt.Errorf("experiment ID is required")
+	}
+	if runID == "" {
+		return RunRecord{}, fmt.Errorf("run ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return RunRecord{}, fmt.Errorf("load state: %w", err)
+	}
+	experimentIndex := -1
+	for index, record := range snapshot.Experiments {
+		if record.ID == experimentID {
+			experimentIndex = index
+			break
+		}
+	}
+	if experimentIndex < 0 {
+		return RunRecord{}, fmt.Errorf("experiment not found")
+	}
+	experiment := snapshot.Experiments[experimentIndex]
+	runIndex := -1
+	for index, run := range snapshot.Runs {
+		if run.ID == runID {
+			runIndex = index
+			break
+		}
+	}
+	if runIndex < 0 {
+		return RunRecord{}, fmt.Errorf("run not found")
+	}
+	run := snapshot.Runs[runIndex]
+	if run.ProjectID != experiment.ProjectID {
+		return RunRecord{}, fmt.Errorf("run belongs to another project")
+	}
+
+	run.ExperimentID = experiment.ID
+	snapshot.Runs[runIndex] = run
+	latest := true
+	for _, other := range snapshot.Runs {
+		if other.ExperimentID == experiment.ID && other.ID != run.ID && other.StartedAt.After(run.StartedAt) {
+			latest = false
+			break
+		}
+	}
+	if latest {
+		experiment.LatestRunID = run.ID
+		experiment.LatestOutput = output
+		snapshot.Experiments[experimentIndex] = experiment
+	}
+	for index, record := range snapshot.Experiments {
+		if record.ID != experiment.ID && record.LatestRunID == run.ID {
+			snapshot.Experiments[index].LatestRunID = ""
+			snapshot.Experiments[index].LatestOutput = ""
+		}
+	}
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return RunRecord{}, fmt.Errorf("persist run assignment: %w", err)
+	}
+	return run, nil
+}
+
+// ExperimentRuns returns the runs attached to an experiment, latest start
+// first. Attached runs age out with the project's run history.
+func (s *Store) ExperimentRuns(ctx context.Context, experimentID string) ([]RunRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("experiment runs context: %w", err)
+	}
+	if experimentID == "" {
+		return nil, fmt.Errorf("experiment ID is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return nil, fmt.Errorf("load state: %w", err)
+	}
+	runs := make([]RunRecord, 0)
+	for _, run := range snapshot.Runs {
+		if run.ExperimentID == experimentID {
+			runs = append(runs, run)
+		}
+	}
+	slices.SortStableFunc(runs, func(a, b RunRecord) int {
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+	return runs, nil
+}
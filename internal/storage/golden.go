@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UpdateSnippetGolden replaces a snippet's golden and received outputs; nil
+// clears them.
+func (s *Store) UpdateSnippetGolden(ctx context.Context, snippetID string, golden *GoldenOutput, received *GoldenOutput) (SnippetRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return SnippetRecord{}, fmt.Errorf("update snippet golden context: %w", err)
+	}
+	if strings.TrimSpace(snippetID) == "" {
+		return SnippetRecord{}, fmt.Errorf("snippet ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return SnippetRecord{}, fmt.Errorf("load state: %w", err)
+	}
+	for i, existing := range snapshot.Snippets {
+		if existing.ID != snippetID {
+			continue
+		}
+		existing.Golden = golden
+		existing.Received = received
+		snapshot.Snippets[i] = existing
+		snapshot.Meta.UpdatedAt = time.Now().UTC()
+		if err := s.writeLocked(snapshot); err != nil {
+			return SnippetRecord{}, fmt.Errorf("persist snippet golden: %w", err)
+		}
+		return existing, nil
+	}
+	return SnippetRecord{}, fmt.Errorf("snippet not found")
+}
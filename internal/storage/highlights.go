@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UpdateProjectHighlights replaces the output highlight rules of a project.
+func (s *Store) UpdateProjectHighlights(ctx context.Context, path string, rules []HighlightRule) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project highlights context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.Highlights = rules
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project highlights: %w", err)
+	}
+	return existing, nil
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// runJournalFileName holds run records appended before the snapshot that
+// contains them is written. A record survives a failed or interrupted
+// snapshot write there and is replayed into the snapshot at the next
+// Bootstrap; the journal is cleared once a snapshot write succeeds.
+const runJournalFileName = "runs.journal"
+
+// JournalPath returns the run journal location.
+func (s *Store) JournalPath() string {
+	return filepath.Join(s.rootDir, runJournalFileName)
+}
+
+// appendRunJournalLocked durably appends one record, one JSON object per
+// line.
+func (s *Store) appendRunJournalLocked(record RunRecord) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode journal record: %w", err)
+	}
+	file, err := os.OpenFile(s.JournalPath(), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open run journal: %w", err)
+	}
+	if _, err := file.Write(append(encoded, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("append run journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("sync run journal: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close run journal: %w", err)
+	}
+	s.journalPending = true
+	return nil
+}
+
+// clearRunJournalLocked drops the journal once a snapshot holding its
+// records is on disk.
+func (s *Store) clearRunJournalLocked() error {
+	if err := os.Remove(s.JournalPath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("clear run journal: %w", err)
+	}
+	s.journalPending = false
+	return nil
+}
+
+// readRunJournal decodes the journal at path. A line that does not decode,
+// such as one torn by a crash mid-append, is skipped.
+func readRunJournal(path string) ([]RunRecord, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read run journal: %w", err)
+	}
+	var records []RunRecord
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(raw)+1)
+	for scanner.Scan() {
+		var record RunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.ID == "" || record.ProjectID == "" {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// replayRunJournal adds journaled records missing from snapshot and
+// reports how many it added.
+func replayRunJournal(snapshot *Snapshot, records []RunRecord) int {
+	added := 0
+	for _, record := range records {
+		if slices.ContainsFunc(snapshot.Runs, func(existing RunRecord) bool { return existing.ID == record.ID }) {
+			continue
+		}
+		snapshot.Runs = append(snapshot.Runs, record)
+		snapshot.Runs = pruneRunRecords(snapshot.Runs, record.ProjectID, maxRunsPerProject)
+		added++
+	}
+	return added
+}
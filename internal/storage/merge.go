@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopoke/internal/fspath"
+)
+
+// projectKey is the identity of a stored project path: its canonical
+// spelling, case-folded where filesystems ignore case.
+func projectKey(path string) string {
+	return fspath.Key(fspath.Canonical(path))
+}
+
+// mergeDuplicateProjects folds projects whose paths share a key into the
+// most recently opened one. Empty settings on the survivor are filled from
+// the duplicates, and their snippets, runs, experiments and env vars move
+// to it; an env var the survivor already defines wins, and a moved snippet
+// whose name is taken gets a numbered suffix. It returns the number of
+// records removed.
+func mergeDuplicateProjects(snapshot *Snapshot, key func(string) string) int {
+	groupByKey := make(map[string]int, len(snapshot.Projects))
+	groups := make([][]ProjectRecord, 0, len(snapshot.Projects))
+	for _, project := range snapshot.Projects {
+		identity := key(project.Path)
+		index, ok := groupByKey[identity]
+		if !ok {
+			groupByKey[identity] = len(groups)
+			groups = append(groups, []ProjectRecord{project})
+			continue
+		}
+		groups[index] = append(groups[index], project)
+	}
+	if len(groups) == len(snapshot.Projects) {
+		return 0
+	}
+
+	projects := make([]ProjectRecord, 0, len(groups))
+	renamed := make(map[string]string)
+	for _, group := range groups {
+		slices.SortStableFunc(group, func(a, b ProjectRecord) int {
+			return b.LastOpenedAt.Compare(a.LastOpenedAt)
+		})
+		survivor := group[0]
+		for _, duplicate := range group[1:] {
+			fillProjectSettings(&survivor, duplicate)
+			renamed[duplicate.ID] = survivor.ID
+		}
+		projects = append(projects, survivor)
+	}
+
+	// Survivor snippets keep their names; moved ones are renamed around them.
+	names := make(map[[2]string]bool, len(snapshot.Snippets))
+	for _, snippet := range snapshot.Snippets {
+		if _, ok := renamed[snippet.ProjectID]; !ok {
+			names[[2]string{snippet.ProjectID, snippetNameKey(snippet.Name)}] = true
+		}
+	}
+	snippets := make([]SnippetRecord, 0, len(snapshot.Snippets))
+	for _, snippet := range snapshot.Snippets {
+		if id, ok := renamed[snippet.ProjectID]; ok {
+			snippet.ProjectID = id
+			snippet.Name = unusedSnippetName(names, id, snippet.Name)
+		}
+		snippets = append(snippets, snippet)
+	}
+
+	runs := make([]RunRecord, 0, len(snapshot.Runs))
+	for _, run := range snapshot.Runs {
+		if id, ok := renamed[run.ProjectID]; ok {
+			run.ProjectID = id
+		}
+		runs = append(runs, run)
+	}
+	for _, project := range projects {
+		runs = pruneRunRecords(runs, project.ID, maxRunsPerProject)
+	}
+
+	// Survivor env vars come first so they win over moved duplicates.
+	envVars := make([]EnvVarRecord, 0, len(snapshot.EnvVars))
+	seen := make(map[[2]string]bool, len(snapshot.EnvVars))
+	for _, moved := range []bool{false, true} {
+		for _, variable := range snapshot.EnvVars {
+			id, ok := renamed[variable.ProjectID]
+			if ok != moved {
+				continue
+			}
+			if ok {
+				variable.ProjectID = id
+			}
+			if seen[[2]string{variable.ProjectID, variable.Key}] {
+				continue
+			}
+			seen[[2]string{variable.ProjectID, variable.Key}] = true
+			envVars = append(envVars, variable)
+		}
+	}
+
+	for index, record := range snapshot.Activity {
+		if id, ok := renamed[record.ProjectID]; ok {
+			snapshot.Activity[index].ProjectID = id
+		}
+	}
+	for index, record := range snapshot.Experiments {
+		if id, ok := renamed[record.ProjectID]; ok {
+			snapshot.Experiments[index].ProjectID = id
+		}
+	}
+
+	removed := len(snapshot.Projects) - len(projects)
+	snapshot.Projects = projects
+	snapshot.Snippets = snippets
+	snapshot.Runs = runs
+	snapshot.EnvVars = envVars
+	return removed
+}
+
+// unusedSnippetName returns name, or name with the first free " (n)"
+// suffix when projectID already has a snippet by that name, and marks the
+// result taken in names.
+func unusedSnippetName(names map[[2]string]bool, projectID string, name string) string {
+	candidate := name
+	for n := 2; names[[2]string{projectID, snippetNameKey(candidate)}]; n++ {
+		candidate = fmt.Sprintf("%s (%d)", strings.TrimSpace(name), n)
+	}
+	names[[2]string{projectID, snippetNameKey(candidate)}] = true
+	return candidate
+}
+
+func fillProjectSettings(survivor *ProjectRecord, duplicate ProjectRecord) {
+	if survivor.DefaultPkg == "" {
+		survivor.DefaultPkg = duplicate.DefaultPkg
+	}
+	if survivor.WorkingDir == "" {
+		survivor.WorkingDir = duplicate.WorkingDir
+	}
+	if survivor.Toolchain == "" {
+		survivor.Toolchain = duplicate.Toolchain
+	}
+	if survivor.TimeoutMS == 0 {
+		survivor.TimeoutMS = duplicate.TimeoutMS
+	}
+	if survivor.MaxOutputBytes == 0 {
+		survivor.MaxOutputBytes = duplicate.MaxOutputBytes
+	}
+	if len(survivor.Experiments) == 0 {
+		survivor.Experiments = duplicate.Experiments
+	}
+	if survivor.OutputEncoding == "" {
+		survivor.OutputEncoding = duplicate.OutputEncoding
+	}
+	if survivor.RunGuard == "" {
+		survivor.RunGuard = duplicate.RunGuard
+	}
+	if survivor.TestCache == "" {
+		survivor.TestCache = duplicate.TestCache
+	}
+	if len(survivor.NetworkAllow) == 0 {
+		survivor.NetworkAllow = duplicate.NetworkAllow
+	}
+	if survivor.Trust == TrustUnknown {
+		survivor.Trust = duplicate.Trust
+	}
+	if survivor.SnippetSync == nil {
+		survivor.SnippetSync = duplicate.SnippetSync
+	}
+	if len(survivor.Webhooks) == 0 {
+		survivor.Webhooks = duplicate.Webhooks
+	}
+	if len(survivor.Highlights) == 0 {
+		survivor.Highlights = duplicate.Highlights
+	}
+	survivor.Pinned = survivor.Pinned || duplicate.Pinned
+	survivor.ModuleOverlay = survivor.ModuleOverlay || duplicate.ModuleOverlay
+}
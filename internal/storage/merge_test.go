@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopoke/internal/settings"
+)
+
+func TestMergeDuplicateProjectsFoldsCase(t *testing.T) {
+	t.Parallel()
+
+	older := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	snapshot := newSnapshot()
+	snapshot.Projects = []ProjectRecord{
+		{ID: "prj_old", Path: "/Users/me/Proj", LastOpenedAt: older, Toolchain: "go1.24.0", TimeoutMS: 9000, Pinned: true},
+		{ID: "prj_other", Path: "/Users/me/other", LastOpenedAt: older},
+		{ID: "prj_new", Path: "/users/me/proj", LastOpenedAt: newer, DefaultPkg: "./cmd/api"},
+		{ID: "prj_mid", Path: "/USERS/me/proj", LastOpenedAt: older.Add(time.Minute), ModuleOverlay: true},
+	}
+	snapshot.Snippets = []SnippetRecord{{ID: "snp_1", ProjectID: "prj_old", Name: "a"}}
+	snapshot.Runs = []RunRecord{{ID: "run_1", ProjectID: "prj_old", Status: "success"}}
+	snapshot.EnvVars = []EnvVarRecord{
+		{ID: "env_1", ProjectID: "prj_old", Key: "TOKEN", Value: "old"},
+		{ID: "env_2", ProjectID: "prj_old", Key: "ONLY_OLD", Value: "kept"},
+		{ID: "env_3", ProjectID: "prj_new", Key: "TOKEN", Value: "new"},
+	}
+
+	if removed := mergeDuplicateProjects(&snapshot, strings.ToLower); removed != 2 {
+		t.Fatalf("mergeDuplicateProjects() removed %d, want 2", removed)
+	}
+	if len(snapshot.Projects) != 2 {
+		t.Fatalf("projects = %+v, want 2", snapshot.Projects)
+	}
+	survivor := snapshot.Projects[0]
+	if survivor.ID != "prj_new" || survivor.Path != "/users/me/proj" || survivor.DefaultPkg != "./cmd/api" {
+		t.Fatalf("survivor = %+v, want most recently opened record", survivor)
+	}
+	if survivor.Toolchain != "go1.24.0" || survivor.TimeoutMS != 9000 || !survivor.Pinned || !survivor.ModuleOverlay {
+		t.Fatalf("survivor = %+v, want settings filled from duplicate", survivor)
+	}
+	if snapshot.Snippets[0].ProjectID != "prj_new" || snapshot.Runs[0].ProjectID != "prj_new" {
+		t.Fatalf("snippets = %+v, runs = %+v, want moved to survivor", snapshot.Snippets, snapshot.Runs)
+	}
+	values := make(map[string]string)
+	for _, variable := range snapshot.EnvVars {
+		if variable.ProjectID != "prj_new" {
+			t.Fatalf("env var %+v not moved to survivor", variable)
+		}
+		values[variable.Key] = variable.Value
+	}
+	if len(values) != 2 || values["TOKEN"] != "new" || values["ONLY_OLD"] != "kept" {
+		t.Fatalf("env vars = %+v, want survivor TOKEN and moved ONLY_OLD", snapshot.EnvVars)
+	}
+
+	if removed := mergeDuplicateProjects(&snapshot, strings.ToLower); removed != 0 {
+		t.Fatalf("second merge removed %d, want 0", removed)
+	}
+}
+
+func TestMergeDuplicateProjectsRenamesCollidingSnippets(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	store := New(dataDir)
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	snapshot, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	now := time.Now().UTC()
+	snapshot.SchemaVersion = SchemaVersionV1
+	snapshot.Projects = []ProjectRecord{
+		{ID: "prj_a", Path: "/work/Proj", LastOpenedAt: now.Add(-time.Hour)},
+		{ID: "prj_b", Path: "/work/PROJ", LastOpenedAt: now.Add(-time.Minute)},
+		{ID: "prj_c", Path: "/work/proj", LastOpenedAt: now},
+	}
+	snapshot.Snippets = []SnippetRecord{
+		{ID: "snp_a", ProjectID: "prj_a", Name: "main", Content: "package main"},
+		{ID: "snp_b", ProjectID: "prj_b", Name: "Main", Content: "package main"},
+		{ID: "snp_c", ProjectID: "prj_c", Name: "other", Content: "package main"},
+	}
+	if removed := mergeDuplicateProjects(&snapshot, strings.ToLower); removed != 2 {
+		t.Fatalf("mergeDuplicateProjects() removed %d, want 2", removed)
+	}
+	names := make(map[string]string)
+	for _, snippet := range snapshot.Snippets {
+		if snippet.ProjectID != "prj_c" {
+			t.Fatalf("snippet %+v not moved to survivor", snippet)
+		}
+		names[snippet.ID] = snippet.Name
+	}
+	if want := map[string]string{"snp_a": "main", "snp_b": "Main (2)", "snp_c": "other"}; !maps.Equal(names, want) {
+		t.Fatalf("snippet names = %v, want %v", names, want)
+	}
+
+	// The merged state must still pass the store's name checks.
+	if err := store.writeLocked(snapshot); err != nil {
+		t.Fatalf("writeLocked() error = %v", err)
+	}
+	err = store.Update(ctx, func(tx *Tx) error {
+		_, err := tx.SaveSnippet(SnippetRecord{ID: "snp_a", ProjectID: "prj_c", Name: "main", Content: "package main\n"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Update() after merge error = %v", err)
+	}
+}
+
+func TestBootstrapMergesDuplicateProjects(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(root, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	dataDir := t.TempDir()
+	store := New(dataDir)
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	snapshot, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	now := time.Now().UTC()
+	snapshot.SchemaVersion = SchemaVersionV1
+	snapshot.Projects = append(snapshot.Projects,
+		ProjectRecord{ID: "prj_target", Path: root, LastOpenedAt: now},
+		ProjectRecord{ID: "prj_link", Path: link, LastOpenedAt: now.Add(-time.Minute)},
+	)
+	if err := store.writeLocked(snapshot); err != nil {
+		t.Fatalf("writeLocked() error = %v", err)
+	}
+
+	reopened := New(dataDir)
+	if err := reopened.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap(reopen) error = %v", err)
+	}
+	recent, err := reopened.RecentProjects(ctx, 10)
+	if err != nil {
+		t.Fatalf("RecentProjects() error = %v", err)
+	}
+	if len(recent) != 1 || recent[0].ID != "prj_target" {
+		t.Fatalf("RecentProjects() = %+v, want merged prj_target", recent)
+	}
+	migrated, err := reopened.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load(reopen) error = %v", err)
+	}
+	if migrated.SchemaVersion != SchemaVersionV2 {
+		t.Fatalf("SchemaVersion = %d, want %d after migration", migrated.SchemaVersion, SchemaVersionV2)
+	}
+}
+
+func TestBootstrapFlagsChosenLegacyRunLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	store := New(dataDir)
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	snapshot, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	snapshot.SchemaVersion = SchemaVersionV1
+	snapshot.GlobalSettings.DefaultTimeoutMS = settings.LegacyTimeoutMS
+	snapshot.GlobalSettings.MaxOutputBytes = 4096
+	if err := store.writeLocked(snapshot); err != nil {
+		t.Fatalf("writeLocked() error = %v", err)
+	}
+
+	reopened := New(dataDir)
+	if err := reopened.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap(reopen) error = %v", err)
+	}
+	gs, err := reopened.GetSettings(ctx)
+	if err != nil {
+		t.Fatalf("GetSettings() error = %v", err)
+	}
+	// The timeout at the V1 default is kept but not applied; the cap can
+	// only have been chosen.
+	if gs.DefaultTimeoutMS != settings.LegacyTimeoutMS || gs.DefaultTimeoutSet {
+		t.Fatalf("timeout = %d ms, set %t; want the V1 value kept unflagged", gs.DefaultTimeoutMS, gs.DefaultTimeoutSet)
+	}
+	if gs.MaxOutputBytes != 4096 || !gs.MaxOutputSet {
+		t.Fatalf("output cap = %d bytes, set %t; want the chosen cap kept and flagged", gs.MaxOutputBytes, gs.MaxOutputSet)
+	}
+
+	// Saving other settings leaves the timeout unflagged; entering it does not.
+	gs.Locale = "es"
+	if gs, err = reopened.UpdateSettings(ctx, gs); err != nil || gs.DefaultTimeoutSet {
+		t.Fatalf("UpdateSettings(unchanged timeout) = set %t, %v; want unflagged", gs.DefaultTimeoutSet, err)
+	}
+	gs.DefaultTimeoutSet = true
+	if gs, err = reopened.UpdateSettings(ctx, gs); err != nil || !gs.DefaultTimeoutSet || gs.DefaultTimeoutMS != settings.LegacyTimeoutMS {
+		t.Fatalf("UpdateSettings(explicit timeout) = %d ms, set %t, %v; want the chosen 30000 ms", gs.DefaultTimeoutMS, gs.DefaultTimeoutSet, err)
+	}
+}
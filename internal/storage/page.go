@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopoke/internal/fuzzy"
+)
+
+const (
+	// DefaultPageSize is the page size used when a request sets none.
+	DefaultPageSize = 50
+	// MaxPageSize caps the records returned in one page.
+	MaxPageSize = 500
+)
+
+// PageRequest selects one page of a list: up to Size records following the
+// record Cursor was issued for. An empty cursor starts at the first record;
+// a size of zero or less uses DefaultPageSize. The embedded filter narrows
+// the list first; a cursor is only valid with the filter it was issued for.
+type PageRequest struct {
+	Cursor string `json:"cursor,omitempty"`
+	Size   int    `json:"size,omitempty"`
+	ListFilter
+}
+
+// ListFilter narrows a list before it is paged.
+type ListFilter struct {
+	// Query is fuzzy-matched against each record's name: the project path,
+	// the snippet name, or a run's snippet name and ID. Matches are listed
+	// best first, then in the list's usual order.
+	Query string `json:"query,omitempty"`
+	// Status keeps runs with this status; it applies to runs only.
+	Status string `json:"status,omitempty"`
+	// From and To bound the time a list is ordered by, inclusive: when a
+	// project was opened, a snippet updated or a run started. A zero
+	// bound is open.
+	From time.Time `json:"from,omitzero"`
+	To   time.Time `json:"to,omitzero"`
+}
+
+// PageInfo describes where a page sits in its list. NextCursor requests
+// the following page and is empty on the last one. Total counts every
+// record matching the filter, not just the page.
+type PageInfo struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	Total      int    `json:"total"`
+}
+
+// ProjectPage is one page of projects, most recently opened first.
+type ProjectPage struct {
+	Items []ProjectRecord `json:"items"`
+	PageInfo
+}
+
+// SnippetPage is one page of a project's snippets, latest update first.
+type SnippetPage struct {
+	Items []SnippetRecord `json:"items"`
+	PageInfo
+}
+
+// RunPage is one page of a project's runs, latest start first.
+type RunPage struct {
+	Items []RunRecord `json:"items"`
+	PageInfo
+}
+
+// RecentProjectsPage returns one page of RecentProjects.
+func (s *Store) RecentProjectsPage(ctx context.Context, request PageRequest) (ProjectPage, error) {
+	if request.Status != "" {
+		return ProjectPage{}, fmt.Errorf("status filter applies to runs only")
+	}
+	projects, err := s.RecentProjects(ctx, 0)
+	if err != nil {
+		return ProjectPage{}, err
+	}
+	items, info, err := filterPage(projects, request, func(project ProjectRecord) (time.Time, string, []string) {
+		return project.LastOpenedAt, project.Path, []string{project.Path}
+	})
+	if err != nil {
+		return ProjectPage{}, err
+	}
+	return ProjectPage{Items: items, PageInfo: info}, nil
+}
+
+// ProjectSnippetsPage returns one page of ProjectSnippets.
+func (s *Store) ProjectSnippetsPage(ctx context.Context, projectID string, request PageRequest) (SnippetPage, error) {
+	if request.Status != "" {
+		return SnippetPage{}, fmt.Errorf("status filter applies to runs only")
+	}
+	snippets, err := s.ProjectSnippets(ctx, projectID)
+	if err != nil {
+		return SnippetPage{}, err
+	}
+	items, info, err := filterPage(snippets, request, func(snippet SnippetRecord) (time.Time, string, []string) {
+		return snippet.UpdatedAt, snippet.Name, []string{snippet.Name}
+	})
+	if err != nil {
+		return SnippetPage{}, err
+	}
+	return SnippetPage{Items: items, PageInfo: info}, nil
+}
+
+// ProjectRunsPage returns one page of ProjectRuns.
+func (s *Store) ProjectRunsPage(ctx context.Context, projectID string, request PageRequest) (RunPage, error) {
+	runs, err := s.ProjectRuns(ctx, projectID, 0)
+	if err != nil {
+		return RunPage{}, err
+	}
+	snippetNames := make(map[string]string)
+	if strings.TrimSpace(request.Query) != "" {
+		snippets, err := s.ProjectSnippets(ctx, projectID)
+		if err != nil {
+			return RunPage{}, err
+		}
+		for _, snippet := range snippets {
+			snippetNames[snippet.ID] = snippet.Name
+		}
+	}
+	if request.Status != "" {
+		runs = slices.DeleteFunc(runs, func(run RunRecord) bool { return run.Status != request.Status })
+	}
+	items, info, err := filterPage(runs, request, func(run RunRecord) (time.Time, string, []string) {
+		return run.StartedAt, run.ID, []string{snippetNames[run.SnippetID], run.ID}
+	})
+	if err != nil {
+		return RunPage{}, err
+	}
+	return RunPage{Items: items, PageInfo: info}, nil
+}
+
+// filterPage applies the request's date range and query to records in
+// their usual order and returns the requested page. describe returns a
+// record's ordering time, its unique key within that time and the names the
+// query is matched against.
+func filterPage[R any](records []R, request PageRequest, describe func(R) (time.Time, string, []string)) ([]R, PageInfo, error) {
+	type scored struct {
+		record   R
+		position pagePosition
+	}
+	matches := make([]scored, 0, len(records))
+	for _, record := range records {
+		at, key, names := describe(record)
+		if (!request.From.IsZero() && at.Before(request.From)) || (!request.To.IsZero() && at.After(request.To)) {
+			continue
+		}
+		best, matched := 0, false
+		for _, name := range names {
+			if score, ok := fuzzy.Score(request.Query, name); ok && (!matched || score > best) {
+				best, matched = score, true
+			}
+		}
+		if matched {
+			matches = append(matches, scored{record: record, position: pagePosition{score: best, at: at, key: key}})
+		}
+	}
+	// Stable, so equal scores keep the list's usual order.
+	slices.SortStableFunc(matches, func(a, b scored) int { return b.position.score - a.position.score })
+
+	start, end, info, err := paginate(matches, request, func(match scored) pagePosition { return match.position })
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	items := make([]R, 0, end-start)
+	for _, match := range matches[start:end] {
+		items = append(items, match.record)
+	}
+	return items, info, nil
+}
+
+// pagePosition is a record's place in a list ordered by query score, best
+// first, then by time, newest first, then by key. Cursors encode the
+// position of a page's last record, so records added or removed before it
+// do not shift the next page.
+type pagePosition struct {
+	score int
+	at    time.Time
+	key   string
+}
+
+func (p pagePosition) after(other pagePosition) bool {
+	if p.score != other.score {
+		return p.score < other.score
+	}
+	if !p.at.Equal(other.at) {
+		return p.at.Before(other.at)
+	}
+	return p.key > other.key
+}
+
+func (p pagePosition) cursor() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(p.score) + "|" + p.at.UTC().Format(time.RFC3339Nano) + "|" + p.key))
+}
+
+func parsePageCursor(cursor string) (pagePosition, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pagePosition{}, fmt.Errorf("invalid page cursor")
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return pagePosition{}, fmt.Errorf("invalid page cursor")
+	}
+	score, scoreErr := strconv.Atoi(parts[0])
+	at, atErr := time.Parse(time.RFC3339Nano, parts[1])
+	if scoreErr != nil || atErr != nil {
+		return pagePosition{}, fmt.Errorf("invalid page cursor")
+	}
+	return pagePosition{score: score, at: at, key: parts[2]}, nil
+}
+
+// paginate returns the bounds of the requested page of records sorted by
+// position.
+func paginate[R any](records []R, request PageRequest, position func(R) pagePosition) (int, int, PageInfo, error) {
+	size := request.Size
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	size = min(size, MaxPageSize)
+
+	start := 0
+	if request.Cursor != "" {
+		last, err := parsePageCursor(request.Cursor)
+		if err != nil {
+			return 0, 0, PageInfo{}, err
+		}
+		start = sort.Search(len(records), func(i int) bool {
+			return position(records[i]).after(last)
+		})
+	}
+	end := min(start+size, len(records))
+	info := PageInfo{Total: len(records)}
+	if end < len(records) {
+		info.NextCursor = position(records[end-1]).cursor()
+	}
+	return start, end, info, nil
+}
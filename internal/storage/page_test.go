@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProjectRunsPageWalksHistoryWithStableCursors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := New(t.TempDir())
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(ctx, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	base := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	record := func(id string, at time.Time) {
+		t.Helper()
+		if _, err := store.RecordRun(ctx, RunRecord{ID: id, ProjectID: project.ID, StartedAt: at, Status: "success"}); err != nil {
+			t.Fatalf("RecordRun(%s) error = %v", id, err)
+		}
+	}
+	for i, id := range []string{"run_a", "run_b", "run_c", "run_d"} {
+		record(id, base.Add(time.Duration(i)*time.Minute))
+	}
+	// Same start as run_d; ties are ordered by ID.
+	record("run_e", base.Add(3*time.Minute))
+
+	var seen []string
+	page, err := store.ProjectRunsPage(ctx, project.ID, PageRequest{Size: 2})
+	if err != nil {
+		t.Fatalf("ProjectRunsPage() error = %v", err)
+	}
+	for {
+		if page.Total < 5 {
+			t.Fatalf("page total = %d, want at least 5", page.Total)
+		}
+		for _, run := range page.Items {
+			seen = append(seen, run.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if len(seen) == 2 {
+			// A run recorded while paging must not shift later pages.
+			record("run_new", base.Add(time.Hour))
+		}
+		page, err = store.ProjectRunsPage(ctx, project.ID, PageRequest{Cursor: page.NextCursor, Size: 2})
+		if err != nil {
+			t.Fatalf("ProjectRunsPage(next) error = %v", err)
+		}
+	}
+	want := []string{"run_d", "run_e", "run_c", "run_b", "run_a"}
+	if len(seen) != len(want) {
+		t.Fatalf("paged runs = %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("paged runs = %v, want %v", seen, want)
+		}
+	}
+
+	if _, err := store.ProjectRunsPage(ctx, project.ID, PageRequest{Cursor: "not a cursor"}); err == nil {
+		t.Fatal("ProjectRunsPage(invalid cursor) error = nil")
+	}
+}
+
+func TestRecentProjectsPageDefaultsPageSize(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := New(t.TempDir())
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	for range 3 {
+		if _, err := store.RecordProjectOpen(ctx, t.TempDir(), ""); err != nil {
+			t.Fatalf("RecordProjectOpen() error = %v", err)
+		}
+	}
+	page, err := store.RecentProjectsPage(ctx, PageRequest{})
+	if err != nil {
+		t.Fatalf("RecentProjectsPage() error = %v", err)
+	}
+	if len(page.Items) != 3 || page.Total != 3 || page.NextCursor != "" {
+		t.Fatalf("RecentProjectsPage() = %+v, want all 3 projects on one page", page)
+	}
+}
+
+func TestPageFiltersByQueryStatusAndDate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := New(t.TempDir())
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(ctx, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	names := []string{"parseConfig", "http client", "perhaps a response"}
+	snippetIDs := make(map[string]string, len(names))
+	for _, name := range names {
+		snippet, err := store.SaveSnippet(ctx, SnippetRecord{ProjectID: project.ID, Name: name, Content: "package main"})
+		if err != nil {
+			t.Fatalf("SaveSnippet(%s) error = %v", name, err)
+		}
+		snippetIDs[name] = snippet.ID
+	}
+
+	snippets, err := store.ProjectSnippetsPage(ctx, project.ID, PageRequest{ListFilter: ListFilter{Query: "parse"}})
+	if err != nil {
+		t.Fatalf("ProjectSnippetsPage(query) error = %v", err)
+	}
+	if snippets.Total != 2 || snippets.Items[0].Name != "parseConfig" || snippets.Items[1].Name != "perhaps a response" {
+		t.Fatalf("ProjectSnippetsPage(parse) = %+v, want the substring match first", snippets)
+	}
+	if _, err := store.ProjectSnippetsPage(ctx, project.ID, PageRequest{ListFilter: ListFilter{Status: "failed"}}); err == nil {
+		t.Fatal("ProjectSnippetsPage(status) error = nil")
+	}
+
+	base := time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC)
+	runs := []RunRecord{
+		{ID: "run_1", SnippetID: snippetIDs["parseConfig"], StartedAt: base, Status: "failed"},
+		{ID: "run_2", SnippetID: snippetIDs["parseConfig"], StartedAt: base.Add(time.Hour), Status: "success"},
+		{ID: "run_3", SnippetID: snippetIDs["http client"], StartedAt: base.Add(2 * time.Hour), Status: "failed"},
+		{ID: "run_4", SnippetID: snippetIDs["parseConfig"], StartedAt: base.Add(3 * time.Hour), Status: "failed"},
+	}
+	for _, run := range runs {
+		run.ProjectID = project.ID
+		if _, err := store.RecordRun(ctx, run); err != nil {
+			t.Fatalf("RecordRun(%s) error = %v", run.ID, err)
+		}
+	}
+	page, err := store.ProjectRunsPage(ctx, project.ID, PageRequest{ListFilter: ListFilter{
+		Query:  "config",
+		Status: "failed",
+		To:     base.Add(2 * time.Hour),
+	}})
+	if err != nil {
+		t.Fatalf("ProjectRunsPage(filter) error = %v", err)
+	}
+	if page.Total != 1 || page.Items[0].ID != "run_1" {
+		t.Fatalf("ProjectRunsPage(filter) = %+v, want run_1 only", page)
+	}
+	page, err = store.ProjectRunsPage(ctx, project.ID, PageRequest{ListFilter: ListFilter{From: base.Add(time.Hour)}})
+	if err != nil {
+		t.Fatalf("ProjectRunsPage(from) error = %v", err)
+	}
+	if page.Total != 3 || page.Items[0].ID != "run_4" {
+		t.Fatalf("ProjectRunsPage(from) = %+v, want the 3 latest runs", page)
+	}
+}
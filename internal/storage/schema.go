@@ -8,8 +8,15 @@ import (
 	"gopoke/internal/settings"
 )
 
-// SchemaVersionV1 is the initial on-disk schema version.
-const SchemaVersionV1 = 1
+const (
+	// SchemaVersionV1 is the initial on-disk schema version.
+	SchemaVersionV1 = 1
+	// SchemaVersionV2 stores at most one project per canonical path, and
+	// runs apply the global run limit settings the user chose. V1 state is
+	// migrated by merging duplicate projects and flagging run limits that
+	// differ from the V1 defaults as chosen.
+	SchemaVersionV2 = 2
+)
 
 // Snapshot is persisted as one atomic state file for MVP.
 type Snapshot struct {
@@ -18,6 +25,8 @@ type Snapshot struct {
 	Snippets       []SnippetRecord         `json:"snippets"`
 	Runs           []RunRecord             `json:"runs"`
 	EnvVars        []EnvVarRecord          `json:"envVars"`
+	Activity       []ActivityRecord        `json:"activity,omitempty"`
+	Experiments    []ExperimentRecord      `json:"experiments,omitempty"`
 	GlobalSettings settings.GlobalSettings `json:"globalSettings"`
 	Meta           SnapshotMetadata        `json:"meta"`
 }
@@ -36,6 +45,95 @@ type ProjectRecord struct {
 	DefaultPkg   string    `json:"defaultPackage"`
 	WorkingDir   string    `json:"workingDirectory"`
 	Toolchain    string    `json:"toolchain"`
+	// Run limit overrides; zero inherits the global settings.
+	TimeoutMS      int64 `json:"timeoutMs,omitempty"`
+	MaxOutputBytes int64 `json:"maxOutputBytes,omitempty"`
+	// Experiments are GOEXPERIMENT values enabled for every run.
+	Experiments []string `json:"experiments,omitempty"`
+	// OutputEncoding overrides detection of run output encoding.
+	OutputEncoding string `json:"outputEncoding,omitempty"`
+	// RunGuard is the run confirmation policy for destructive-looking
+	// snippets; empty means confirm.
+	RunGuard string `json:"runGuard,omitempty"`
+	// TestCache is the go test cache mode for the project's test runs;
+	// empty means force a rerun.
+	TestCache string `json:"testCache,omitempty"`
+	// NetworkAllow lists remote hosts the project's runs may connect to
+	// without a permission prompt.
+	NetworkAllow []string `json:"networkAllow,omitempty"`
+	// Trust is the user's trust decision for the project. Empty marks a
+	// record saved before trust was tracked.
+	Trust string `json:"trust,omitempty"`
+	// Pinned keeps the project on the home screen regardless of when it
+	// was last opened.
+	Pinned bool `json:"pinned,omitempty"`
+	// ModuleOverlay runs the project's snippets against private copies of
+	// go.mod and go.sum, so they change the tracked files only when the
+	// user applies a run's changes.
+	ModuleOverlay bool `json:"moduleOverlay,omitempty"`
+	// SnippetSync is the remote library the project's snippets sync with;
+	// nil disables sync.
+	SnippetSync *SnippetSyncConfig `json:"snippetSync,omitempty"`
+	// Webhooks receive the results of the project's runs.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	// Highlights mark matching lines of the project's run output.
+	Highlights []HighlightRule `json:"highlights,omitempty"`
+}
+
+// HighlightRule marks run output matching a regular expression.
+type HighlightRule struct {
+	ID      string `json:"id"`
+	Pattern string `json:"pattern"`
+	// Color is a palette name such as "red" or a "#rrggbb" value.
+	Color string `json:"color,omitempty"`
+	// Label is shown next to matching lines.
+	Label string `json:"label,omitempty"`
+	// Collapse folds matching lines instead of highlighting the match.
+	Collapse bool `json:"collapse,omitempty"`
+}
+
+// Webhook posts run results to a URL.
+type Webhook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Statuses limits the hook to runs ending in these statuses; empty
+	// means every run.
+	Statuses []string `json:"statuses,omitempty"`
+	// SecretEnv names the environment variable holding the signing secret,
+	// so the secret is never stored. Empty sends unsigned payloads.
+	SecretEnv string `json:"secretEnv,omitempty"`
+}
+
+// SnippetSyncConfig describes a remote snippet library.
+type SnippetSyncConfig struct {
+	// Provider is "git", "webdav" or "repo".
+	Provider string `json:"provider"`
+	// URL is a git remote or the WebDAV URL of the library file; repo
+	// libraries have none.
+	URL string `json:"url"`
+	// Branch and Path locate the library file in a git repository. For
+	// repo libraries, Path is the snippet directory in the project.
+	Branch string `json:"branch,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// Username and PasswordEnv authenticate WebDAV requests. The password
+	// is read from the named environment variable so it is never stored.
+	Username    string `json:"username,omitempty"`
+	PasswordEnv string `json:"passwordEnv,omitempty"`
+}
+
+// Project trust states.
+const (
+	// TrustUnknown marks a project the user has not decided on yet; it is
+	// restricted until trusted.
+	TrustUnknown    = "unknown"
+	TrustTrusted    = "trusted"
+	TrustRestricted = "restricted"
+)
+
+// Trusted reports whether the project may load .env files and run workers.
+// Records saved before trust was tracked keep the access they had.
+func (p ProjectRecord) Trusted() bool {
+	return p.Trust == TrustTrusted || p.Trust == ""
 }
 
 // SnippetRecord captures persisted snippet data.
@@ -46,6 +144,18 @@ type SnippetRecord struct {
 	Content   string    `json:"content"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	// Golden is the approved output later golden runs are compared with;
+	// Received is the latest output that differed from it, awaiting
+	// approval.
+	Golden   *GoldenOutput `json:"golden,omitempty"`
+	Received *GoldenOutput `json:"received,omitempty"`
+}
+
+// GoldenOutput is a recorded snippet output.
+type GoldenOutput struct {
+	Stdout     string    `json:"stdout"`
+	ExitCode   int       `json:"exitCode"`
+	RecordedAt time.Time `json:"recordedAt"`
 }
 
 // RunRecord captures metadata for a run.
@@ -57,6 +167,66 @@ type RunRecord struct {
 	DurationMS int64     `json:"durationMs"`
 	ExitCode   int       `json:"exitCode"`
 	Status     string    `json:"status"`
+	// Environment is what the run saw; runs recorded before snapshots were
+	// kept have none.
+	Environment *RunEnvironment `json:"environment,omitempty"`
+	// ExperimentID is the experiment the run is attached to, if any.
+	ExperimentID string `json:"experimentId,omitempty"`
+}
+
+// ExperimentRecord groups a project's runs of one investigation.
+type ExperimentRecord struct {
+	ID          string    `json:"id"`
+	ProjectID   string    `json:"projectId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// LatestRunID is the attached run that started last, and LatestOutput
+	// the tail of its output as it was when the run was attached; run
+	// records keep no output.
+	LatestRunID  string `json:"latestRunId,omitempty"`
+	LatestOutput string `json:"latestOutput,omitempty"`
+}
+
+// RunEnvironment is a redacted snapshot of the environment a run used.
+type RunEnvironment struct {
+	ToolchainVersion string `json:"toolchainVersion"`
+	GOOS             string `json:"goos"`
+	GOARCH           string `json:"goarch"`
+	WorkingDirectory string `json:"workingDirectory"`
+	// Variables are sorted by key.
+	Variables []RunEnvVar `json:"variables"`
+}
+
+// RunEnvVar is one environment variable in a run snapshot. Value is kept
+// only for build settings such as GOFLAGS; other values are reduced to a
+// fingerprint so a change shows without the value, and masked variables keep
+// neither.
+type RunEnvVar struct {
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Masked      bool   `json:"masked,omitempty"`
+}
+
+// Activity kinds.
+const (
+	// ActivityRun counts runs; the target is the package run.
+	ActivityRun = "run"
+	// ActivityEdit counts saves; the target is the file saved.
+	ActivityEdit = "edit"
+)
+
+// ActivityRecord counts a project's runs or edits of one target on one day.
+type ActivityRecord struct {
+	ProjectID string `json:"projectId"`
+	// Day is the UTC date, as 2006-01-02.
+	Day  string `json:"day"`
+	Kind string `json:"kind"`
+	// Target is a package such as "./cmd/api" or a file such as
+	// "internal/store/store.go", relative to the project.
+	Target string `json:"target"`
+	Count  int    `json:"count"`
 }
 
 // EnvVarRecord captures a project-level environment variable.
@@ -66,6 +236,10 @@ type EnvVarRecord struct {
 	Key       string `json:"key"`
 	Value     string `json:"value"`
 	Masked    bool   `json:"masked"`
+	// FromDotEnv marks a variable last set from the project's .env file
+	// rather than by the user, so it can be dropped when the project loses
+	// trust.
+	FromDotEnv bool `json:"fromDotEnv,omitempty"`
 }
 
 func generateID(prefix string) string {
@@ -79,7 +253,7 @@ func generateID(prefix string) string {
 func newSnapshot() Snapshot {
 	now := time.Now().UTC()
 	return Snapshot{
-		SchemaVersion:  SchemaVersionV1,
+		SchemaVersion:  SchemaVersionV2,
 		Projects:       make([]ProjectRecord, 0),
 		Snippets:       make([]SnippetRecord, 0),
 		Runs:           make([]RunRecord, 0),
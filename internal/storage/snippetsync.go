@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UpdateProjectSnippetSync stores the remote library a project's snippets
+// sync with. A nil config disables sync.
+func (s *Store) UpdateProjectSnippetSync(ctx context.Context, path string, config *SnippetSyncConfig) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project snippet sync context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.SnippetSync = config
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project snippet sync: %w", err)
+	}
+	return existing, nil
+}
+
+// ApplySnippetSync writes the local side of a snippet sync in one update.
+// Upserts with an ID replace that snippet's name, content and update time;
+// upserts without one are inserted under a new ID. The returned records
+// follow the order of upserts. Names must stay unique once every change is
+// applied.
+func (s *Store) ApplySnippetSync(ctx context.Context, projectID string, upserts []SnippetRecord, deleteIDs []string) ([]SnippetRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("apply snippet sync context: %w", err)
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("project ID is required")
+	}
+
+	var applied []SnippetRecord
+	err := s.Update(ctx, func(tx *Tx) error {
+		if !projectExists(tx.snapshot.Projects, projectID) {
+			return fmt.Errorf("project not found")
+		}
+		for _, id := range deleteIDs {
+			if snippet, found := tx.SnippetByID(id); found && snippet.ProjectID == projectID {
+				tx.DeleteSnippet(id)
+			}
+		}
+		applied = make([]SnippetRecord, 0, len(upserts))
+		for _, record := range upserts {
+			if strings.TrimSpace(record.Name) == "" || strings.TrimSpace(record.Content) == "" {
+				return fmt.Errorf("synced snippet needs a name and content")
+			}
+			record.ProjectID = projectID
+			saved, err := tx.SaveSnippet(record)
+			if err != nil {
+				return err
+			}
+			applied = append(applied, saved)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("apply snippet sync: %w", err)
+	}
+	return applied, nil
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestApplySnippetSync(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := New(t.TempDir())
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(ctx, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	kept, err := store.SaveSnippet(ctx, SnippetRecord{ProjectID: project.ID, Name: "kept", Content: "v1"})
+	if err != nil {
+		t.Fatalf("SaveSnippet() error = %v", err)
+	}
+	dropped, err := store.SaveSnippet(ctx, SnippetRecord{ProjectID: project.ID, Name: "dropped", Content: "v1"})
+	if err != nil {
+		t.Fatalf("SaveSnippet() error = %v", err)
+	}
+
+	remoteTime := time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+	applied, err := store.ApplySnippetSync(ctx, project.ID, []SnippetRecord{
+		{ID: kept.ID, Name: "kept", Content: "v2", UpdatedAt: remoteTime},
+		// Reuses the name of the snippet deleted in the same update.
+		{Name: "dropped", Content: "pulled", UpdatedAt: remoteTime},
+	}, []string{dropped.ID})
+	if err != nil {
+		t.Fatalf("ApplySnippetSync() error = %v", err)
+	}
+	if len(applied) != 2 || applied[0].ID != kept.ID || applied[1].ID == "" || applied[1].ID == dropped.ID {
+		t.Fatalf("applied = %+v, want the kept ID and a new ID", applied)
+	}
+	if !applied[0].UpdatedAt.Equal(remoteTime) || !applied[0].CreatedAt.Equal(kept.CreatedAt) {
+		t.Fatalf("applied[0] = %+v, want remote update time and original creation time", applied[0])
+	}
+
+	snippets, err := store.ProjectSnippets(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("ProjectSnippets() error = %v", err)
+	}
+	if len(snippets) != 2 {
+		t.Fatalf("snippets = %+v, want 2", snippets)
+	}
+
+	if _, err := store.ApplySnippetSync(ctx, project.ID, []SnippetRecord{{Name: "KEPT", Content: "clash"}}, nil); err == nil {
+		t.Fatal("ApplySnippetSync(name clash) error = nil, want error")
+	}
+	if after, _ := store.ProjectSnippets(ctx, project.ID); len(after) != 2 {
+		t.Fatalf("snippets after failed apply = %+v, want unchanged", after)
+	}
+}
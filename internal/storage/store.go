@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -12,6 +13,8 @@ import (
 	"sync"
 	"time"
 
+	"gopoke/internal/faults"
+	"gopoke/internal/fspath"
 	"gopoke/internal/settings"
 )
 
@@ -27,12 +30,25 @@ type HealthReport struct {
 	SchemaVersion int
 }
 
+// RecordUsage summarizes persisted records owned by one project.
+type RecordUsage struct {
+	Runs       int
+	RunBytes   int64
+	Snippets   int
+	EnvVars    int
+	SavedBytes int64
+}
+
 // Store owns local on-disk state operations.
 type Store struct {
 	mu      sync.RWMutex
 	rootDir string
 	path    string
 	cached  *Snapshot
+
+	// journalPending is set while the run journal may hold records the
+	// state file lacks.
+	journalPending bool
 }
 
 // New creates a store rooted at the provided directory.
@@ -61,21 +77,59 @@ func (s *Store) Bootstrap(ctx context.Context) error {
 		return fmt.Errorf("create storage directory: %w", err)
 	}
 
-	_, err := os.Stat(s.path)
+	journaled, err := readRunJournal(s.JournalPath())
+	if err != nil {
+		return err
+	}
+	s.journalPending = len(journaled) > 0
+
+	_, err = os.Stat(s.path)
 	switch {
 	case err == nil:
-		_, loadErr := s.loadLocked()
+		snapshot, loadErr := s.loadLocked()
 		if loadErr != nil {
 			return fmt.Errorf("load existing state: %w", loadErr)
 		}
+		migrated := snapshot.SchemaVersion < SchemaVersionV2
+		if migrated {
+			mergeDuplicateProjects(&snapshot, projectKey)
+			markLegacyRunLimits(&snapshot.GlobalSettings)
+			snapshot.SchemaVersion = SchemaVersionV2
+		}
+		replayed := replayRunJournal(&snapshot, journaled)
+		if migrated || replayed > 0 {
+			snapshot.Meta.UpdatedAt = time.Now().UTC()
+			if err := s.writeLocked(snapshot); err != nil {
+				return fmt.Errorf("persist migrated state and journaled runs: %w", err)
+			}
+		}
+		if s.journalPending {
+			return s.clearRunJournalLocked()
+		}
 		return nil
 	case errors.Is(err, os.ErrNotExist):
-		return s.writeLocked(newSnapshot())
+		snapshot := newSnapshot()
+		replayRunJournal(&snapshot, journaled)
+		return s.writeLocked(snapshot)
 	default:
 		return fmt.Errorf("inspect state file: %w", err)
 	}
 }
 
+// markLegacyRunLimits flags V1 run limits that differ from the V1
+// defaults, which only the user can have chosen, as set. A limit still at
+// its V1 default may be a choice or the default; it is kept but left
+// unflagged, so runs keep the built-in limit they used under V1 until the
+// user changes it.
+func markLegacyRunLimits(gs *settings.GlobalSettings) {
+	if gs.DefaultTimeoutMS > 0 && gs.DefaultTimeoutMS != settings.LegacyTimeoutMS {
+		gs.DefaultTimeoutSet = true
+	}
+	if gs.MaxOutputBytes > 0 && gs.MaxOutputBytes != settings.LegacyMaxOutput {
+		gs.MaxOutputSet = true
+	}
+}
+
 // Health verifies state readability and reports schema information.
 func (s *Store) Health(ctx context.Context) (HealthReport, error) {
 	if err := ctx.Err(); err != nil {
@@ -145,6 +199,7 @@ func (s *Store) UpdateSettings(ctx context.Context, gs settings.GlobalSettings)
 		return settings.GlobalSettings{}, fmt.Errorf("load state: %w", err)
 	}
 
+	validated = settings.MarkChangedRunLimits(settings.WithDefaults(snapshot.GlobalSettings), validated)
 	snapshot.GlobalSettings = validated
 	snapshot.Meta.UpdatedAt = time.Now().UTC()
 	if err := s.writeLocked(snapshot); err != nil {
@@ -171,29 +226,29 @@ func (s *Store) RecordProjectOpen(ctx context.Context, path string, defaultPacka
 	}
 
 	now := time.Now().UTC()
-	normalizedPath := filepath.Clean(path)
+	normalizedPath := fspath.Canonical(path)
 	var record ProjectRecord
-	found := false
 
-	for i, existing := range snapshot.Projects {
-		if existing.Path == normalizedPath {
-			record = existing
-			record.LastOpenedAt = now
-			if strings.TrimSpace(defaultPackage) != "" {
-				record.DefaultPkg = defaultPackage
-			}
-			snapshot.Projects[i] = record
-			found = true
-			break
+	if index := projectIndex(snapshot.Projects, normalizedPath); index >= 0 {
+		// Records saved before paths were canonical move to the canonical
+		// spelling the first time they are reopened. A path differing only
+		// in case keeps the recorded spelling.
+		record = snapshot.Projects[index]
+		if fspath.Key(record.Path) != fspath.Key(normalizedPath) {
+			record.Path = normalizedPath
 		}
-	}
-
-	if !found {
+		record.LastOpenedAt = now
+		if strings.TrimSpace(defaultPackage) != "" {
+			record.DefaultPkg = defaultPackage
+		}
+		snapshot.Projects[index] = record
+	} else {
 		record = ProjectRecord{
 			ID:           generateID("prj"),
 			Path:         normalizedPath,
 			LastOpenedAt: now,
 			DefaultPkg:   defaultPackage,
+			Trust:        TrustUnknown,
 		}
 		snapshot.Projects = append(snapshot.Projects, record)
 	}
@@ -205,6 +260,25 @@ func (s *Store) RecordProjectOpen(ctx context.Context, path string, defaultPacka
 	return record, nil
 }
 
+// projectIndex finds the record for path. It matches the cleaned and the
+// canonical spelling first, then resolves stored paths so records saved
+// through a symlink are still found. It returns -1 when there is none.
+func projectIndex(projects []ProjectRecord, path string) int {
+	cleaned := filepath.Clean(path)
+	canonical := fspath.Canonical(path)
+	for index, project := range projects {
+		if fspath.Equal(project.Path, canonical) || fspath.Equal(project.Path, cleaned) {
+			return index
+		}
+	}
+	for index, project := range projects {
+		if fspath.Equal(fspath.Canonical(project.Path), canonical) {
+			return index
+		}
+	}
+	return -1
+}
+
 // ProjectByPath returns a project record for a given path.
 func (s *Store) ProjectByPath(ctx context.Context, path string) (ProjectRecord, bool, error) {
 	if err := ctx.Err(); err != nil {
@@ -222,9 +296,30 @@ func (s *Store) ProjectByPath(ctx context.Context, path string) (ProjectRecord,
 		return ProjectRecord{}, false, fmt.Errorf("load state: %w", err)
 	}
 
-	normalizedPath := filepath.Clean(path)
+	if index := projectIndex(snapshot.Projects, path); index >= 0 {
+		return snapshot.Projects[index], true, nil
+	}
+	return ProjectRecord{}, false, nil
+}
+
+// ProjectByID returns one project by ID.
+func (s *Store) ProjectByID(ctx context.Context, projectID string) (ProjectRecord, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, false, fmt.Errorf("project by id context: %w", err)
+	}
+	if strings.TrimSpace(projectID) == "" {
+		return ProjectRecord{}, false, fmt.Errorf("project ID is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, false, fmt.Errorf("load state: %w", err)
+	}
 	for _, project := range snapshot.Projects {
-		if project.Path == normalizedPath {
+		if project.ID == projectID {
 			return project, true, nil
 		}
 	}
@@ -251,20 +346,18 @@ func (s *Store) UpdateProjectDefaultPackage(ctx context.Context, path string, de
 		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
 	}
 
-	normalizedPath := filepath.Clean(path)
-	for i, existing := range snapshot.Projects {
-		if existing.Path != normalizedPath {
-			continue
-		}
-		existing.DefaultPkg = defaultPackage
-		snapshot.Projects[i] = existing
-		snapshot.Meta.UpdatedAt = time.Now().UTC()
-		if err := s.writeLocked(snapshot); err != nil {
-			return ProjectRecord{}, fmt.Errorf("persist project default package: %w", err)
-		}
-		return existing, nil
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
 	}
-	return ProjectRecord{}, fmt.Errorf("project not found")
+	existing := snapshot.Projects[index]
+	existing.DefaultPkg = defaultPackage
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project default package: %w", err)
+	}
+	return existing, nil
 }
 
 // UpdateProjectWorkingDirectory updates the saved working directory for a project without changing recency.
@@ -287,20 +380,18 @@ func (s *Store) UpdateProjectWorkingDirectory(ctx context.Context, path string,
 		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
 	}
 
-	normalizedPath := filepath.Clean(path)
-	for i, existing := range snapshot.Projects {
-		if existing.Path != normalizedPath {
-			continue
-		}
-		existing.WorkingDir = filepath.Clean(workingDirectory)
-		snapshot.Projects[i] = existing
-		snapshot.Meta.UpdatedAt = time.Now().UTC()
-		if err := s.writeLocked(snapshot); err != nil {
-			return ProjectRecord{}, fmt.Errorf("persist project working directory: %w", err)
-		}
-		return existing, nil
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
 	}
-	return ProjectRecord{}, fmt.Errorf("project not found")
+	existing := snapshot.Projects[index]
+	existing.WorkingDir = filepath.Clean(workingDirectory)
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project working directory: %w", err)
+	}
+	return existing, nil
 }
 
 // UpdateProjectToolchain updates the selected Go toolchain for a project without changing recency.
@@ -323,20 +414,312 @@ func (s *Store) UpdateProjectToolchain(ctx context.Context, path string, toolcha
 		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
 	}
 
-	normalizedPath := filepath.Clean(path)
-	for i, existing := range snapshot.Projects {
-		if existing.Path != normalizedPath {
-			continue
-		}
-		existing.Toolchain = strings.TrimSpace(toolchain)
-		snapshot.Projects[i] = existing
-		snapshot.Meta.UpdatedAt = time.Now().UTC()
-		if err := s.writeLocked(snapshot); err != nil {
-			return ProjectRecord{}, fmt.Errorf("persist project toolchain: %w", err)
-		}
-		return existing, nil
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.Toolchain = strings.TrimSpace(toolchain)
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project toolchain: %w", err)
+	}
+	return existing, nil
+}
+
+// UpdateProjectRunLimits stores per-project timeout and output cap overrides.
+// Zero clears an override.
+func (s *Store) UpdateProjectRunLimits(ctx context.Context, path string, timeoutMS int64, maxOutputBytes int64) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project run limits context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+	if timeoutMS < 0 || maxOutputBytes < 0 {
+		return ProjectRecord{}, fmt.Errorf("run limits must be >= 0")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.TimeoutMS = timeoutMS
+	existing.MaxOutputBytes = maxOutputBytes
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project run limits: %w", err)
 	}
-	return ProjectRecord{}, fmt.Errorf("project not found")
+	return existing, nil
+}
+
+// UpdateProjectExperiments stores the GOEXPERIMENT values enabled for a project.
+func (s *Store) UpdateProjectExperiments(ctx context.Context, path string, experiments []string) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project experiments context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.Experiments = append([]string(nil), experiments...)
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project experiments: %w", err)
+	}
+	return existing, nil
+}
+
+// UpdateProjectNetworkAllow replaces the hosts a project's runs may connect
+// to without a permission prompt.
+func (s *Store) UpdateProjectNetworkAllow(ctx context.Context, path string, hosts []string) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project network allow list context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.NetworkAllow = append([]string(nil), hosts...)
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project network allow list: %w", err)
+	}
+	return existing, nil
+}
+
+// UpdateProjectOutputEncoding stores the encoding a project's run output is
+// decoded from. Empty restores detection.
+func (s *Store) UpdateProjectOutputEncoding(ctx context.Context, path string, encoding string) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project output encoding context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.OutputEncoding = strings.TrimSpace(encoding)
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project output encoding: %w", err)
+	}
+	return existing, nil
+}
+
+// UpdateProjectRunGuard stores a project's run confirmation policy. Empty
+// restores the default.
+func (s *Store) UpdateProjectRunGuard(ctx context.Context, path string, policy string) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project run guard context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.RunGuard = strings.TrimSpace(policy)
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project run guard: %w", err)
+	}
+	return existing, nil
+}
+
+// UpdateProjectPinned pins or unpins a project on the home screen.
+func (s *Store) UpdateProjectPinned(ctx context.Context, path string, pinned bool) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project pinned context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.Pinned = pinned
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project pinned: %w", err)
+	}
+	return existing, nil
+}
+
+// UpdateProjectModuleOverlay turns a project's module overlay mode on or
+// off.
+func (s *Store) UpdateProjectModuleOverlay(ctx context.Context, path string, enabled bool) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project module overlay context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.ModuleOverlay = enabled
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project module overlay: %w", err)
+	}
+	return existing, nil
+}
+
+// UpdateProjectTestCache stores a project's test cache mode. Empty restores
+// the default.
+func (s *Store) UpdateProjectTestCache(ctx context.Context, path string, mode string) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project test cache context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.TestCache = strings.TrimSpace(mode)
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project test cache: %w", err)
+	}
+	return existing, nil
+}
+
+// UpdateProjectTrust records the user's trust decision for a project.
+func (s *Store) UpdateProjectTrust(ctx context.Context, path string, trust string) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project trust context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+	switch trust {
+	case TrustUnknown, TrustTrusted, TrustRestricted:
+	default:
+		return ProjectRecord{}, fmt.Errorf("unsupported project trust %q", trust)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.Trust = trust
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project trust: %w", err)
+	}
+	return existing, nil
 }
 
 // RecentProjects returns projects sorted by most recently opened first.
@@ -410,6 +793,7 @@ func (s *Store) UpdateProjectEnvVar(ctx context.Context, projectID string, key s
 			record = existing
 			record.Value = value
 			record.Masked = masked
+			record.FromDotEnv = false
 			snapshot.EnvVars[i] = record
 			found = true
 			break
@@ -434,6 +818,96 @@ func (s *Store) UpdateProjectEnvVar(ctx context.Context, projectID string, key s
 	return record, nil
 }
 
+// SetProjectDotEnvVars records the variables loaded from a project's .env
+// file. Variables that already exist keep their masked flag.
+func (s *Store) SetProjectDotEnvVars(ctx context.Context, projectID string, values map[string]string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("set .env vars context: %w", err)
+	}
+	if projectID == "" {
+		return fmt.Errorf("project ID is required")
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+
+	pending := maps.Clone(values)
+	for i, existing := range snapshot.EnvVars {
+		value, ok := pending[existing.Key]
+		if existing.ProjectID != projectID || !ok {
+			continue
+		}
+		snapshot.EnvVars[i].Value = value
+		snapshot.EnvVars[i].FromDotEnv = true
+		delete(pending, existing.Key)
+	}
+	for _, key := range slices.Sorted(maps.Keys(pending)) {
+		snapshot.EnvVars = append(snapshot.EnvVars, EnvVarRecord{
+			ID:         generateID("env"),
+			ProjectID:  projectID,
+			Key:        key,
+			Value:      pending[key],
+			FromDotEnv: true,
+		})
+	}
+
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return fmt.Errorf("persist env vars: %w", err)
+	}
+	return nil
+}
+
+// DropProjectDotEnvVars removes the variables a project loaded from its .env
+// file and reports how many were removed. Variables stored before the .env
+// marker existed are matched by key and value against legacy, the file's
+// current contents.
+func (s *Store) DropProjectDotEnvVars(ctx context.Context, projectID string, legacy map[string]string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("drop .env vars context: %w", err)
+	}
+	if projectID == "" {
+		return 0, fmt.Errorf("project ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return 0, fmt.Errorf("load state: %w", err)
+	}
+
+	filtered := make([]EnvVarRecord, 0, len(snapshot.EnvVars))
+	for _, envVar := range snapshot.EnvVars {
+		if envVar.ProjectID == projectID {
+			if value, ok := legacy[envVar.Key]; envVar.FromDotEnv || (ok && value == envVar.Value) {
+				continue
+			}
+		}
+		filtered = append(filtered, envVar)
+	}
+	removed := len(snapshot.EnvVars) - len(filtered)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	snapshot.EnvVars = filtered
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return 0, fmt.Errorf("persist env vars: %w", err)
+	}
+	return removed, nil
+}
+
 // DeleteProjectEnvVar removes one environment variable for a project.
 func (s *Store) DeleteProjectEnvVar(ctx context.Context, projectID string, key string) error {
 	if err := ctx.Err(); err != nil {
@@ -730,15 +1204,48 @@ func (s *Store) RecordRun(ctx context.Context, record RunRecord) (RunRecord, err
 		record.DurationMS = 0
 	}
 
+	// Journal the record first so a crash during the snapshot write does not
+	// lose it; writeLocked clears the journal once the snapshot holds it.
+	journalErr := s.appendRunJournalLocked(record)
 	snapshot.Runs = append(snapshot.Runs, record)
 	snapshot.Runs = pruneRunRecords(snapshot.Runs, record.ProjectID, maxRunsPerProject)
 	snapshot.Meta.UpdatedAt = now
 	if err := s.writeLocked(snapshot); err != nil {
-		return RunRecord{}, fmt.Errorf("persist run record: %w", err)
+		if journalErr != nil {
+			return RunRecord{}, fmt.Errorf("persist run record: %w", errors.Join(err, journalErr))
+		}
+		// The journal holds the record: keep it in memory so the next
+		// successful write, or the next Bootstrap, persists it.
+		s.cached = &snapshot
+		return record, nil
 	}
 	return record, nil
 }
 
+// RunByID returns one recorded run by ID.
+func (s *Store) RunByID(ctx context.Context, runID string) (RunRecord, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return RunRecord{}, false, fmt.Errorf("run by id context: %w", err)
+	}
+	if strings.TrimSpace(runID) == "" {
+		return RunRecord{}, false, fmt.Errorf("run ID is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return RunRecord{}, false, fmt.Errorf("load state: %w", err)
+	}
+	for _, run := range snapshot.Runs {
+		if run.ID == runID {
+			return run, true, nil
+		}
+	}
+	return RunRecord{}, false, nil
+}
+
 // ProjectRuns returns runs for one project sorted by latest start time first.
 func (s *Store) ProjectRuns(ctx context.Context, projectID string, limit int) ([]RunRecord, error) {
 	if err := ctx.Err(); err != nil {
@@ -789,11 +1296,91 @@ func (s *Store) ProjectRuns(ctx context.Context, projectID string, limit int) ([
 	return runs[:limit], nil
 }
 
+// ProjectRecordUsage counts one project's persisted records and their
+// approximate encoded size in the state file.
+func (s *Store) ProjectRecordUsage(ctx context.Context, projectID string) (RecordUsage, error) {
+	if err := ctx.Err(); err != nil {
+		return RecordUsage{}, fmt.Errorf("project record usage context: %w", err)
+	}
+	if projectID == "" {
+		return RecordUsage{}, fmt.Errorf("project ID is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return RecordUsage{}, fmt.Errorf("load state: %w", err)
+	}
+
+	usage := RecordUsage{}
+	for _, run := range snapshot.Runs {
+		if run.ProjectID == projectID {
+			usage.Runs++
+			usage.RunBytes += encodedSize(run)
+		}
+	}
+	for _, snippet := range snapshot.Snippets {
+		if snippet.ProjectID == projectID {
+			usage.Snippets++
+			usage.SavedBytes += encodedSize(snippet)
+		}
+	}
+	for _, envVar := range snapshot.EnvVars {
+		if envVar.ProjectID == projectID {
+			usage.EnvVars++
+			usage.SavedBytes += encodedSize(envVar)
+		}
+	}
+	return usage, nil
+}
+
+// DeleteProjectRuns removes all run history for one project and returns the
+// number of records removed.
+func (s *Store) DeleteProjectRuns(ctx context.Context, projectID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, fmt.Errorf("delete project runs context: %w", err)
+	}
+	if projectID == "" {
+		return 0, fmt.Errorf("project ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return 0, fmt.Errorf("load state: %w", err)
+	}
+
+	filtered := make([]RunRecord, 0, len(snapshot.Runs))
+	for _, run := range snapshot.Runs {
+		if run.ProjectID != projectID {
+			filtered = append(filtered, run)
+		}
+	}
+	removed := len(snapshot.Runs) - len(filtered)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	snapshot.Runs = filtered
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return 0, fmt.Errorf("persist runs: %w", err)
+	}
+	return removed, nil
+}
+
 func (s *Store) loadLocked() (Snapshot, error) {
 	if s.cached != nil {
 		return *s.cached, nil
 	}
 
+	if err := faults.Inject(faults.StorageRead, s.path); err != nil {
+		return Snapshot{}, err
+	}
 	raw, err := os.ReadFile(s.path)
 	if err != nil {
 		return Snapshot{}, err
@@ -803,7 +1390,7 @@ func (s *Store) loadLocked() (Snapshot, error) {
 	if err := json.Unmarshal(raw, &snapshot); err != nil {
 		return Snapshot{}, fmt.Errorf("decode state json: %w", err)
 	}
-	if snapshot.SchemaVersion != SchemaVersionV1 {
+	if snapshot.SchemaVersion != SchemaVersionV1 && snapshot.SchemaVersion != SchemaVersionV2 {
 		return Snapshot{}, fmt.Errorf("unsupported schema version: %d", snapshot.SchemaVersion)
 	}
 	s.cached = &snapshot
@@ -827,6 +1414,11 @@ func (s *Store) writeLocked(snapshot Snapshot) error {
 		os.Remove(tempPath)
 		return fmt.Errorf("write temp state: %w", err)
 	}
+	if err := faults.Inject(faults.StorageWrite, s.path); err != nil {
+		tempFile.Close()
+		os.Remove(tempPath)
+		return fmt.Errorf("write temp state: %w", err)
+	}
 	if err := tempFile.Sync(); err != nil {
 		tempFile.Close()
 		os.Remove(tempPath)
@@ -841,9 +1433,22 @@ func (s *Store) writeLocked(snapshot Snapshot) error {
 		return fmt.Errorf("replace state file: %w", err)
 	}
 	s.cached = &snapshot
+	if s.journalPending {
+		// The snapshot now holds every journaled record; a stale journal
+		// is harmless since replay skips known IDs.
+		_ = s.clearRunJournalLocked()
+	}
 	return nil
 }
 
+func encodedSize(value any) int64 {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return int64(len(raw))
+}
+
 func projectExists(projects []ProjectRecord, projectID string) bool {
 	return slices.ContainsFunc(projects, func(project ProjectRecord) bool {
 		return project.ID == projectID
@@ -896,7 +1501,7 @@ func pruneRunRecords(runs []RunRecord, projectID string, maxKeep int) []RunRecor
 }
 
 func snippetNameExists(snippets []SnippetRecord, projectID string, excludeID string, name string) bool {
-	normalizedName := strings.ToLower(strings.TrimSpace(name))
+	normalizedName := snippetNameKey(name)
 	return slices.ContainsFunc(snippets, func(snippet SnippetRecord) bool {
 		if snippet.ProjectID != projectID {
 			return false
@@ -904,6 +1509,12 @@ func snippetNameExists(snippets []SnippetRecord, projectID string, excludeID str
 		if excludeID != "" && snippet.ID == excludeID {
 			return false
 		}
-		return strings.ToLower(strings.TrimSpace(snippet.Name)) == normalizedName
+		return snippetNameKey(snippet.Name) == normalizedName
 	})
 }
+
+// snippetNameKey is the form snippet names are compared in: names differing
+// only in case or surrounding space clash.
+func snippetNameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
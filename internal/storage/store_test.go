@@ -2,14 +2,18 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	"gopoke/internal/faults"
+	"gopoke/internal/fspath"
 )
 
-func TestBootstrapCreatesSchemaV1Snapshot(t *testing.T) {
+func TestBootstrapCreatesSchemaV2Snapshot(t *testing.T) {
 	t.Parallel()
 
 	rootDir := t.TempDir()
@@ -24,7 +28,7 @@ func TestBootstrapCreatesSchemaV1Snapshot(t *testing.T) {
 		t.Fatalf("Load() error = %v", err)
 	}
 
-	if got, want := snapshot.SchemaVersion, SchemaVersionV1; got != want {
+	if got, want := snapshot.SchemaVersion, SchemaVersionV2; got != want {
 		t.Fatalf("schema version = %d, want %d", got, want)
 	}
 
@@ -61,7 +65,7 @@ func TestHealthReadyAfterBootstrap(t *testing.T) {
 	if !report.Ready {
 		t.Fatal("ready = false, want true")
 	}
-	if got, want := report.SchemaVersion, SchemaVersionV1; got != want {
+	if got, want := report.SchemaVersion, SchemaVersionV2; got != want {
 		t.Fatalf("schema version = %d, want %d", got, want)
 	}
 }
@@ -127,7 +131,7 @@ func TestRecentProjectsSortedByLastOpened(t *testing.T) {
 	if got, want := len(recent), 1; got != want {
 		t.Fatalf("len(recent) = %d, want %d", got, want)
 	}
-	if got, want := recent[0].Path, filepath.Clean("/tmp/b"); got != want {
+	if got, want := recent[0].Path, fspath.Canonical("/tmp/b"); got != want {
 		t.Fatalf("recent[0].Path = %q, want %q", got, want)
 	}
 }
@@ -156,6 +160,60 @@ func TestProjectByPath(t *testing.T) {
 	}
 }
 
+func TestProjectLookupThroughSymlink(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	target := filepath.Join(root, "project")
+	if err := os.Mkdir(target, 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	store := New(t.TempDir())
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	// A record saved before paths were canonical still holds the link.
+	snapshot, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	snapshot.Projects = append(snapshot.Projects, ProjectRecord{ID: "prj_legacy", Path: link, DefaultPkg: "."})
+	if err := store.writeLocked(snapshot); err != nil {
+		t.Fatalf("writeLocked() error = %v", err)
+	}
+
+	found, ok, err := store.ProjectByPath(ctx, target)
+	if err != nil || !ok || found.ID != "prj_legacy" {
+		t.Fatalf("ProjectByPath(target) = %+v, %t, %v; want legacy record", found, ok, err)
+	}
+	reopened, err := store.RecordProjectOpen(ctx, link, "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	if reopened.ID != "prj_legacy" || reopened.Path != target {
+		t.Fatalf("RecordProjectOpen() = %+v, want legacy record moved to %q", reopened, target)
+	}
+	if _, err := store.UpdateProjectToolchain(ctx, link, "go1.25.1"); err != nil {
+		t.Fatalf("UpdateProjectToolchain(link) error = %v", err)
+	}
+	recent, err := store.RecentProjects(ctx, 10)
+	if err != nil {
+		t.Fatalf("RecentProjects() error = %v", err)
+	}
+	if len(recent) != 1 || recent[0].Toolchain != "go1.25.1" {
+		t.Fatalf("RecentProjects() = %+v, want one updated record", recent)
+	}
+}
+
 func TestUpdateProjectDefaultPackage(t *testing.T) {
 	t.Parallel()
 
@@ -253,6 +311,62 @@ func TestRecordRunAndProjectRuns(t *testing.T) {
 	}
 }
 
+func TestProjectRecordUsageAndDeleteProjectRuns(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := New(t.TempDir())
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(ctx, "/tmp/project-usage", ".")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	other, err := store.RecordProjectOpen(ctx, "/tmp/project-other", ".")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen(other) error = %v", err)
+	}
+	for _, projectID := range []string{project.ID, project.ID, other.ID} {
+		if _, err := store.RecordRun(ctx, RunRecord{ProjectID: projectID, Status: "success"}); err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+	}
+	if _, err := store.SaveSnippet(ctx, SnippetRecord{ProjectID: project.ID, Name: "keep", Content: "package main"}); err != nil {
+		t.Fatalf("SaveSnippet() error = %v", err)
+	}
+
+	usage, err := store.ProjectRecordUsage(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("ProjectRecordUsage() error = %v", err)
+	}
+	if usage.Runs != 2 || usage.Snippets != 1 || usage.RunBytes <= 0 || usage.SavedBytes <= 0 {
+		t.Fatalf("ProjectRecordUsage() = %+v, want 2 runs and 1 snippet with sizes", usage)
+	}
+
+	removed, err := store.DeleteProjectRuns(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("DeleteProjectRuns() error = %v", err)
+	}
+	if got, want := removed, 2; got != want {
+		t.Fatalf("DeleteProjectRuns() = %d, want %d", got, want)
+	}
+	otherRuns, err := store.ProjectRuns(ctx, other.ID, 0)
+	if err != nil {
+		t.Fatalf("ProjectRuns(other) error = %v", err)
+	}
+	if got, want := len(otherRuns), 1; got != want {
+		t.Fatalf("len(other runs) = %d, want %d", got, want)
+	}
+	usage, err = store.ProjectRecordUsage(ctx, project.ID)
+	if err != nil {
+		t.Fatalf("ProjectRecordUsage() after delete error = %v", err)
+	}
+	if usage.Runs != 0 || usage.Snippets != 1 {
+		t.Fatalf("ProjectRecordUsage() after delete = %+v, want snippets kept", usage)
+	}
+}
+
 func TestUpdateProjectWorkingDirectoryAndToolchain(t *testing.T) {
 	t.Parallel()
 
@@ -295,6 +409,17 @@ func TestUpdateProjectWorkingDirectoryAndToolchain(t *testing.T) {
 	if got, want := found.Toolchain, "go1.25.1"; got != want {
 		t.Fatalf("found.Toolchain = %q, want %q", got, want)
 	}
+
+	if _, err := store.UpdateProjectExperiments(context.Background(), record.Path, []string{"arenas"}); err != nil {
+		t.Fatalf("UpdateProjectExperiments() error = %v", err)
+	}
+	found, _, err = store.ProjectByPath(context.Background(), record.Path)
+	if err != nil {
+		t.Fatalf("ProjectByPath() error = %v", err)
+	}
+	if got := found.Experiments; len(got) != 1 || got[0] != "arenas" {
+		t.Fatalf("found.Experiments = %v, want [arenas]", got)
+	}
 }
 
 func TestProjectEnvVarCRUD(t *testing.T) {
@@ -587,3 +712,199 @@ func TestBootstrapDetectsUnsupportedSchemaVersion(t *testing.T) {
 		t.Fatalf("Bootstrap() error = %q, want unsupported schema version error", err)
 	}
 }
+
+func TestWriteFailureLeavesStateIntactAndRetrySucceeds(t *testing.T) {
+	t.Parallel()
+
+	rootDir := t.TempDir()
+	store := New(rootDir)
+	if err := store.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	before, err := os.ReadFile(store.Path())
+	if err != nil {
+		t.Fatalf("read state: %v", err)
+	}
+
+	disable := faults.Enable(faults.StorageWrite, faults.Fault{Fail: true, Times: 1, Target: rootDir})
+	defer disable()
+
+	if _, err := store.RecordProjectOpen(context.Background(), "/tmp/project", ""); !errors.Is(err, faults.ErrInjected) {
+		t.Fatalf("RecordProjectOpen() error = %v, want injected fault", err)
+	}
+	after, err := os.ReadFile(store.Path())
+	if err != nil {
+		t.Fatalf("read state: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Fatal("state file changed after failed write")
+	}
+	leftovers, err := filepath.Glob(filepath.Join(rootDir, "state-*.json"))
+	if err != nil {
+		t.Fatalf("glob temp files: %v", err)
+	}
+	if len(leftovers) != 0 {
+		t.Fatalf("temp files left behind: %v", leftovers)
+	}
+	projects, err := store.RecentProjects(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("RecentProjects() error = %v", err)
+	}
+	if len(projects) != 0 {
+		t.Fatalf("projects after failed write = %d, want 0", len(projects))
+	}
+
+	if _, err := store.RecordProjectOpen(context.Background(), "/tmp/project", ""); err != nil {
+		t.Fatalf("RecordProjectOpen(retry) error = %v", err)
+	}
+	reloaded, err := New(rootDir).RecentProjects(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("RecentProjects(reloaded) error = %v", err)
+	}
+	if len(reloaded) != 1 {
+		t.Fatalf("persisted projects = %d, want 1", len(reloaded))
+	}
+}
+
+func TestSlowReadDelaysLoad(t *testing.T) {
+	t.Parallel()
+
+	rootDir := t.TempDir()
+	if err := New(rootDir).Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+
+	const delay = 50 * time.Millisecond
+	disable := faults.Enable(faults.StorageRead, faults.Fault{Delay: delay, Target: rootDir})
+	defer disable()
+
+	startedAt := time.Now()
+	if _, err := New(rootDir).Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if elapsed := time.Since(startedAt); elapsed < delay {
+		t.Fatalf("Load() took %v, want at least %v", elapsed, delay)
+	}
+}
+
+func TestRecordRunClearsJournalAfterSuccessfulWrite(t *testing.T) {
+	t.Parallel()
+
+	store := New(t.TempDir())
+	if err := store.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(context.Background(), "/tmp/project-no-journal", ".")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	if _, err := store.RecordRun(context.Background(), RunRecord{ProjectID: project.ID, Status: "success"}); err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	if _, err := os.Stat(store.JournalPath()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("journal after successful write: stat error = %v, want not exist", err)
+	}
+}
+
+func TestRecordRunJournalsBeforeSnapshotWrite(t *testing.T) {
+	t.Parallel()
+
+	rootDir := t.TempDir()
+	store := New(rootDir)
+	if err := store.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(context.Background(), "/tmp/project-journal-first", ".")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+
+	// Hold the snapshot write open: a crash now must still find the record.
+	disable := faults.Enable(faults.StorageWrite, faults.Fault{Delay: 500 * time.Millisecond, Times: 1, Target: rootDir})
+	defer disable()
+	done := make(chan error, 1)
+	go func() {
+		_, err := store.RecordRun(context.Background(), RunRecord{ID: "run_in_flight", ProjectID: project.ID, Status: "success"})
+		done <- err
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		journaled, err := readRunJournal(store.JournalPath())
+		if err != nil {
+			t.Fatalf("readRunJournal() error = %v", err)
+		}
+		if len(journaled) == 1 && journaled[0].ID == "run_in_flight" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("run not journaled while its snapshot write was in flight")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+	if _, err := os.Stat(store.JournalPath()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("journal after successful write: stat error = %v, want not exist", err)
+	}
+}
+
+func TestRunJournalReplaysRecordsAfterFailedWrite(t *testing.T) {
+	t.Parallel()
+
+	rootDir := t.TempDir()
+	store := New(rootDir)
+	if err := store.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(context.Background(), "/tmp/project-journal", ".")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+
+	disable := faults.Enable(faults.StorageWrite, faults.Fault{Fail: true, Times: 1, Target: rootDir})
+	recorded, err := store.RecordRun(context.Background(), RunRecord{ProjectID: project.ID, Status: "success"})
+	disable()
+	if err != nil {
+		t.Fatalf("RecordRun() with failing state write error = %v, want journaled", err)
+	}
+	if _, err := os.Stat(store.JournalPath()); err != nil {
+		t.Fatalf("journal after failed write: %v", err)
+	}
+	if runs, err := store.ProjectRuns(context.Background(), project.ID, 10); err != nil || len(runs) != 1 {
+		t.Fatalf("ProjectRuns() before restart = %d runs, error = %v", len(runs), err)
+	}
+
+	// Simulate a crash mid-append after the journaled record.
+	journal, err := os.OpenFile(store.JournalPath(), os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	if _, err := journal.WriteString(`{"id":"run_torn","projectId":`); err != nil {
+		t.Fatalf("write torn line: %v", err)
+	}
+	journal.Close()
+
+	restarted := New(rootDir)
+	if err := restarted.Bootstrap(context.Background()); err != nil {
+		t.Fatalf("Bootstrap() after crash error = %v", err)
+	}
+	runs, err := restarted.ProjectRuns(context.Background(), project.ID, 10)
+	if err != nil {
+		t.Fatalf("ProjectRuns() error = %v", err)
+	}
+	if len(runs) != 1 || runs[0].ID != recorded.ID {
+		t.Fatalf("runs after replay = %+v, want %s", runs, recorded.ID)
+	}
+	if _, err := os.Stat(restarted.JournalPath()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("journal after replay: stat error = %v, want not exist", err)
+	}
+
+	if err := New(rootDir).Bootstrap(context.Background()); err != nil {
+		t.Fatalf("second Bootstrap() error = %v", err)
+	}
+	if runs, _ := New(rootDir).ProjectRuns(context.Background(), project.ID, 10); len(runs) != 1 {
+		t.Fatalf("runs after second restart = %d, want 1", len(runs))
+	}
+}
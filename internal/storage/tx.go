@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Tx is a set of changes to the stored state made inside Store.Update. The
+// changes are written together when the update succeeds and dropped
+// otherwise; Tx methods never touch the state other callers see.
+type Tx struct {
+	snapshot Snapshot
+	now      time.Time
+	changed  bool
+	// savedSnippets are checked before the state is written.
+	savedSnippets []string
+}
+
+// ForgottenProject counts the records removed with a project.
+type ForgottenProject struct {
+	Project     ProjectRecord `json:"project"`
+	Snippets    int           `json:"snippets"`
+	Runs        int           `json:"runs"`
+	EnvVars     int           `json:"envVars"`
+	Activity    int           `json:"activity"`
+	Experiments int           `json:"experiments"`
+}
+
+// Update applies fn's changes as one all-or-nothing write. fn works on a
+// private view of the state under the store's write lock; when it returns
+// an error, or the changed state fails validation or cannot be written,
+// nothing is applied. An fn that changes nothing writes nothing.
+func (s *Store) Update(ctx context.Context, fn func(tx *Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("update context: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return fmt.Errorf("load state: %w", err)
+	}
+	tx := &Tx{snapshot: snapshot, now: time.Now().UTC()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !tx.changed {
+		return nil
+	}
+	if err := tx.validate(); err != nil {
+		return fmt.Errorf("validate update: %w", err)
+	}
+	tx.snapshot.Meta.UpdatedAt = tx.now
+	if err := s.writeLocked(tx.snapshot); err != nil {
+		return fmt.Errorf("persist update: %w", err)
+	}
+	return nil
+}
+
+// ProjectByPath returns the project stored for path.
+func (tx *Tx) ProjectByPath(path string) (ProjectRecord, bool) {
+	if index := projectIndex(tx.snapshot.Projects, path); index >= 0 {
+		return tx.snapshot.Projects[index], true
+	}
+	return ProjectRecord{}, false
+}
+
+// SnippetByID returns one snippet by ID.
+func (tx *Tx) SnippetByID(snippetID string) (SnippetRecord, bool) {
+	for _, snippet := range tx.snapshot.Snippets {
+		if snippet.ID == snippetID {
+			return snippet, true
+		}
+	}
+	return SnippetRecord{}, false
+}
+
+// ProjectSnippets returns a project's snippets in stored order.
+func (tx *Tx) ProjectSnippets(projectID string) []SnippetRecord {
+	var snippets []SnippetRecord
+	for _, snippet := range tx.snapshot.Snippets {
+		if snippet.ProjectID == projectID {
+			snippets = append(snippets, snippet)
+		}
+	}
+	return snippets
+}
+
+// SaveSnippet inserts record under a new ID when it has none and otherwise
+// replaces the snippet with its ID, keeping its creation time. A zero
+// update time is set to now. The name must be unique within the project
+// once the update is applied.
+func (tx *Tx) SaveSnippet(record SnippetRecord) (SnippetRecord, error) {
+	record.Name = strings.TrimSpace(record.Name)
+	if record.ProjectID == "" {
+		return SnippetRecord{}, fmt.Errorf("project ID is required")
+	}
+	if record.Name == "" || strings.TrimSpace(record.Content) == "" {
+		return SnippetRecord{}, fmt.Errorf("snippet needs a name and content")
+	}
+	if record.UpdatedAt.IsZero() {
+		record.UpdatedAt = tx.now
+	}
+
+	index := -1
+	if record.ID != "" {
+		index = slices.IndexFunc(tx.snapshot.Snippets, func(snippet SnippetRecord) bool { return snippet.ID == record.ID })
+		if index < 0 {
+			return SnippetRecord{}, fmt.Errorf("snippet %s not found", record.ID)
+		}
+		if tx.snapshot.Snippets[index].ProjectID != record.ProjectID {
+			return SnippetRecord{}, fmt.Errorf("snippet project mismatch")
+		}
+	}
+	// Slices are cloned before changing so the loaded state stays intact.
+	snippets := slices.Clone(tx.snapshot.Snippets)
+	if index < 0 {
+		record.ID = generateID("sn")
+		record.CreatedAt = tx.now
+		snippets = append(snippets, record)
+	} else {
+		record.CreatedAt = snippets[index].CreatedAt
+		snippets[index] = record
+	}
+	tx.snapshot.Snippets = snippets
+	tx.savedSnippets = append(tx.savedSnippets, record.ID)
+	tx.changed = true
+	return record, nil
+}
+
+// DeleteSnippet removes one snippet by ID and reports whether it existed.
+func (tx *Tx) DeleteSnippet(snippetID string) bool {
+	before := len(tx.snapshot.Snippets)
+	tx.snapshot.Snippets = slices.DeleteFunc(slices.Clone(tx.snapshot.Snippets), func(snippet SnippetRecord) bool {
+		return snippet.ID == snippetID
+	})
+	if len(tx.snapshot.Snippets) == before {
+		return false
+	}
+	tx.changed = true
+	return true
+}
+
+// ForgetProject removes a project with its snippets, runs, env vars,
+// activity and experiments.
+func (tx *Tx) ForgetProject(projectID string) (ForgottenProject, error) {
+	index := slices.IndexFunc(tx.snapshot.Projects, func(project ProjectRecord) bool { return project.ID == projectID })
+	if index < 0 {
+		return ForgottenProject{}, fmt.Errorf("project not found")
+	}
+	forgotten := ForgottenProject{Project: tx.snapshot.Projects[index]}
+	tx.snapshot.Projects = slices.Delete(slices.Clone(tx.snapshot.Projects), index, index+1)
+	tx.snapshot.Snippets, forgotten.Snippets = withoutProject(tx.snapshot.Snippets, projectID, func(r SnippetRecord) string { return r.ProjectID })
+	tx.snapshot.Runs, forgotten.Runs = withoutProject(tx.snapshot.Runs, projectID, func(r RunRecord) string { return r.ProjectID })
+	tx.snapshot.EnvVars, forgotten.EnvVars = withoutProject(tx.snapshot.EnvVars, projectID, func(r EnvVarRecord) string { return r.ProjectID })
+	tx.snapshot.Activity, forgotten.Activity = withoutProject(tx.snapshot.Activity, projectID, func(r ActivityRecord) string { return r.ProjectID })
+	tx.snapshot.Experiments, forgotten.Experiments = withoutProject(tx.snapshot.Experiments, projectID, func(r ExperimentRecord) string { return r.ProjectID })
+	tx.changed = true
+	return forgotten, nil
+}
+
+// withoutProject returns a copy of records without those owned by
+// projectID, and how many were dropped.
+func withoutProject[R any](records []R, projectID string, owner func(R) string) ([]R, int) {
+	kept := make([]R, 0, len(records))
+	for _, record := range records {
+		if owner(record) != projectID {
+			kept = append(kept, record)
+		}
+	}
+	return kept, len(records) - len(kept)
+}
+
+// validate checks the records the transaction saved: each snippet belongs
+// to an existing project and keeps a unique name.
+func (tx *Tx) validate() error {
+	for _, snippetID := range tx.savedSnippets {
+		snippet, found := tx.SnippetByID(snippetID)
+		if !found {
+			// Deleted later in the same transaction.
+			continue
+		}
+		if !projectExists(tx.snapshot.Projects, snippet.ProjectID) {
+			return fmt.Errorf("snippet %q belongs to no project", snippet.Name)
+		}
+		if snippetNameExists(tx.snapshot.Snippets, snippet.ProjectID, snippet.ID, snippet.Name) {
+			return fmt.Errorf("snippet name %q already exists", snippet.Name)
+		}
+	}
+	return nil
+}
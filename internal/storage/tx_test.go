@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gopoke/internal/faults"
+)
+
+func TestUpdateForgetProjectRemovesOwnedRecords(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := New(t.TempDir())
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(ctx, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	other, err := store.RecordProjectOpen(ctx, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen(other) error = %v", err)
+	}
+	for _, projectID := range []string{project.ID, other.ID} {
+		if _, err := store.SaveSnippet(ctx, SnippetRecord{ProjectID: projectID, Name: "main", Content: "package main"}); err != nil {
+			t.Fatalf("SaveSnippet() error = %v", err)
+		}
+		if _, err := store.RecordRun(ctx, RunRecord{ProjectID: projectID, Status: "success"}); err != nil {
+			t.Fatalf("RecordRun() error = %v", err)
+		}
+		if _, err := store.UpdateProjectEnvVar(ctx, projectID, "KEY", "value", false); err != nil {
+			t.Fatalf("UpdateProjectEnvVar() error = %v", err)
+		}
+		if err := store.RecordActivity(ctx, projectID, ActivityRun, ".", time.Now()); err != nil {
+			t.Fatalf("RecordActivity() error = %v", err)
+		}
+		if _, err := store.CreateExperiment(ctx, ExperimentRecord{ProjectID: projectID, Name: "baseline"}); err != nil {
+			t.Fatalf("CreateExperiment() error = %v", err)
+		}
+	}
+
+	var forgotten ForgottenProject
+	err = store.Update(ctx, func(tx *Tx) error {
+		forgotten, err = tx.ForgetProject(project.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Update(ForgetProject) error = %v", err)
+	}
+	if forgotten.Project.ID != project.ID || forgotten.Snippets != 1 || forgotten.Runs != 1 ||
+		forgotten.EnvVars != 1 || forgotten.Activity != 1 || forgotten.Experiments != 1 {
+		t.Fatalf("forgotten = %+v, want one of each record", forgotten)
+	}
+
+	snapshot, err := New(store.rootDir).Load(ctx)
+	if err != nil {
+		t.Fatalf("Load(reloaded) error = %v", err)
+	}
+	if len(snapshot.Projects) != 1 || len(snapshot.Snippets) != 1 || len(snapshot.Runs) != 1 ||
+		len(snapshot.EnvVars) != 1 || len(snapshot.Activity) != 1 || len(snapshot.Experiments) != 1 {
+		t.Fatalf("reloaded snapshot = %+v, want only the other project's records", snapshot)
+	}
+	if snapshot.Projects[0].ID != other.ID || snapshot.Snippets[0].ProjectID != other.ID {
+		t.Fatalf("reloaded snapshot kept %+v, want project %s", snapshot.Projects, other.ID)
+	}
+}
+
+func TestUpdateAppliesNothingOnFailure(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	rootDir := t.TempDir()
+	store := New(rootDir)
+	if err := store.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap() error = %v", err)
+	}
+	project, err := store.RecordProjectOpen(ctx, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RecordProjectOpen() error = %v", err)
+	}
+	existing, err := store.SaveSnippet(ctx, SnippetRecord{ProjectID: project.ID, Name: "existing", Content: "v1"})
+	if err != nil {
+		t.Fatalf("SaveSnippet() error = %v", err)
+	}
+
+	unchanged := func(step string) {
+		t.Helper()
+		snippets, err := store.ProjectSnippets(ctx, project.ID)
+		if err != nil {
+			t.Fatalf("%s: ProjectSnippets() error = %v", step, err)
+		}
+		if len(snippets) != 1 || snippets[0].ID != existing.ID || snippets[0].Content != "v1" {
+			t.Fatalf("%s: snippets = %+v, want only the existing snippet unchanged", step, snippets)
+		}
+		if _, found, _ := store.ProjectByPath(ctx, project.Path); !found {
+			t.Fatalf("%s: project was removed", step)
+		}
+	}
+
+	errStop := errors.New("stop")
+	err = store.Update(ctx, func(tx *Tx) error {
+		existing.Content = "v2"
+		if _, err := tx.SaveSnippet(existing); err != nil {
+			return err
+		}
+		if _, err := tx.ForgetProject(project.ID); err != nil {
+			return err
+		}
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("Update(fn error) error = %v, want %v", err, errStop)
+	}
+	unchanged("fn error")
+
+	err = store.Update(ctx, func(tx *Tx) error {
+		if _, err := tx.SaveSnippet(SnippetRecord{ProjectID: project.ID, Name: "new", Content: "v1"}); err != nil {
+			return err
+		}
+		_, err := tx.SaveSnippet(SnippetRecord{ProjectID: project.ID, Name: "EXISTING", Content: "clash"})
+		return err
+	})
+	if err == nil {
+		t.Fatal("Update(name clash) error = nil, want validation error")
+	}
+	unchanged("validation")
+
+	disable := faults.Enable(faults.StorageWrite, faults.Fault{Fail: true, Times: 1, Target: rootDir})
+	defer disable()
+	err = store.Update(ctx, func(tx *Tx) error {
+		tx.DeleteSnippet(existing.ID)
+		return nil
+	})
+	if !errors.Is(err, faults.ErrInjected) {
+		t.Fatalf("Update(write fault) error = %v, want injected fault", err)
+	}
+	unchanged("write fault")
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UpdateProjectWebhooks replaces the webhooks of a project.
+func (s *Store) UpdateProjectWebhooks(ctx context.Context, path string, hooks []Webhook) (ProjectRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return ProjectRecord{}, fmt.Errorf("update project webhooks context: %w", err)
+	}
+	if path == "" {
+		return ProjectRecord{}, fmt.Errorf("project path is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, err := s.loadLocked()
+	if err != nil {
+		return ProjectRecord{}, fmt.Errorf("load state: %w", err)
+	}
+
+	index := projectIndex(snapshot.Projects, path)
+	if index < 0 {
+		return ProjectRecord{}, fmt.Errorf("project not found")
+	}
+	existing := snapshot.Projects[index]
+	existing.Webhooks = hooks
+	snapshot.Projects[index] = existing
+	snapshot.Meta.UpdatedAt = time.Now().UTC()
+	if err := s.writeLocked(snapshot); err != nil {
+		return ProjectRecord{}, fmt.Errorf("persist project webhooks: %w", err)
+	}
+	return existing, nil
+}
@@ -0,0 +1,13 @@
+//go:build stress
+
+package stress
+
+import (
+	"testing"
+
+	"gopoke/internal/testutil"
+)
+
+func TestMain(m *testing.M) {
+	testutil.Main(m)
+}
@@ -0,0 +1,314 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopoke/internal/idle"
+)
+
+// ServiceName identifies gopoke in exported spans.
+const ServiceName = "gopoke"
+
+const (
+	// tracesPath is the OTLP/HTTP traces route appended to bare endpoints.
+	tracesPath = "/v1/traces"
+	// exportInterval is how often queued spans are sent.
+	exportInterval = 5 * time.Second
+	// exportBatch sends early once this many spans are queued.
+	exportBatch = 128
+	// maxQueuedSpans bounds memory while the collector is unreachable;
+	// the oldest spans are dropped first.
+	maxQueuedSpans = 2048
+)
+
+// SpanData is a finished span.
+type SpanData struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]string
+	// Error marks a failed operation.
+	Error string
+}
+
+// NormalizeOTLPEndpoint checks an OTLP/HTTP endpoint and returns its traces
+// URL. A bare collector address such as http://localhost:4318 gets the
+// /v1/traces route.
+func NormalizeOTLPEndpoint(endpoint string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(endpoint))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("OTLP endpoint must be an http or https URL: %q", endpoint)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = tracesPath
+	}
+	return parsed.String(), nil
+}
+
+// Exporter sends spans to an OTLP/HTTP collector as JSON in batches.
+type Exporter struct {
+	endpoint string
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu      sync.Mutex
+	queue   []SpanData
+	dropped int
+
+	// gate holds the export loop while the app is idle.
+	gate idle.Gate
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewExporter starts an exporter posting to the traces URL of endpoint.
+func NewExporter(endpoint string, logger *slog.Logger) (*Exporter, error) {
+	tracesURL, err := NormalizeOTLPEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	e := &Exporter{
+		endpoint: tracesURL,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		logger:   logger,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.loop()
+	return e, nil
+}
+
+// Endpoint returns the traces URL spans are posted to.
+func (e *Exporter) Endpoint() string {
+	return e.endpoint
+}
+
+// Export queues a span.
+func (e *Exporter) Export(span SpanData) {
+	e.mu.Lock()
+	if len(e.queue) >= maxQueuedSpans {
+		e.queue = e.queue[1:]
+		e.dropped++
+	}
+	e.queue = append(e.queue, span)
+	full := len(e.queue) >= exportBatch
+	e.mu.Unlock()
+	if full {
+		select {
+		case e.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush sends every queued span. Spans stay queued when the collector
+// rejects them with a retryable status or cannot be reached.
+func (e *Exporter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	batch := e.queue
+	e.queue = nil
+	dropped := e.dropped
+	e.dropped = 0
+	e.mu.Unlock()
+	if dropped > 0 {
+		e.logger.Warn("telemetry spans dropped", "count", dropped)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	retry, err := e.post(ctx, batch)
+	if err != nil && retry {
+		e.mu.Lock()
+		e.queue = append(batch, e.queue...)
+		if overflow := len(e.queue) - maxQueuedSpans; overflow > 0 {
+			e.queue = e.queue[overflow:]
+		}
+		e.mu.Unlock()
+	}
+	return err
+}
+
+// Shutdown stops the export loop and sends what is queued.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	select {
+	case <-e.stop:
+	default:
+		close(e.stop)
+	}
+	<-e.done
+	return e.Flush(ctx)
+}
+
+func (e *Exporter) loop() {
+	defer close(e.done)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		case <-e.wake:
+		}
+		if resumed := e.gate.Paused(); resumed != nil {
+			ticker.Stop()
+			select {
+			case <-e.stop:
+				return
+			case <-resumed:
+			case <-e.wake:
+			}
+			ticker.Reset(exportInterval)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), exportInterval)
+		if err := e.Flush(ctx); err != nil {
+			e.logger.Debug("export telemetry spans failed", "error", err)
+		}
+		cancel()
+	}
+}
+
+func (e *Exporter) post(ctx context.Context, batch []SpanData) (bool, error) {
+	body, err := json.Marshal(encodeOTLP(batch))
+	if err != nil {
+		return false, fmt.Errorf("encode spans: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create export request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := e.client.Do(request)
+	if err != nil {
+		return true, fmt.Errorf("export spans: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return false, nil
+	}
+	retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+	return retry, fmt.Errorf("export spans: HTTP %d", response.StatusCode)
+}
+
+// OTLP JSON encoding; see opentelemetry-proto's trace service.
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusOK         = 1
+	otlpStatusError      = 2
+)
+
+func encodeOTLP(batch []SpanData) otlpRequest {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, span := range batch {
+		encoded := otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			ParentSpanID:      span.ParentSpanID,
+			Name:              span.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        encodeAttributes(span.Attributes),
+			Status:            otlpStatus{Code: otlpStatusOK},
+		}
+		if span.Error != "" {
+			encoded.Status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+		spans = append(spans, encoded)
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: encodeAttributes(map[string]string{"service.name": ServiceName})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: ServiceName}, Spans: spans}},
+	}}}
+}
+
+func encodeAttributes(attributes map[string]string) []otlpAttribute {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	encoded := make([]otlpAttribute, 0, len(keys))
+	for _, key := range keys {
+		encoded = append(encoded, otlpAttribute{Key: key, Value: otlpValue{StringValue: attributes[key]}})
+	}
+	return encoded
+}
+
+func newID(size int) string {
+	raw := make([]byte, size)
+	rand.Read(raw)
+	return hex.EncodeToString(raw)
+}
@@ -0,0 +1,142 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is an OTLP/HTTP receiver keeping the spans it is sent.
+type collector struct {
+	mu    sync.Mutex
+	paths []string
+	spans []otlpSpan
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request otlpRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths = append(c.paths, r.URL.Path)
+	for _, resource := range request.ResourceSpans {
+		for _, scope := range resource.ScopeSpans {
+			c.spans = append(c.spans, scope.Spans...)
+		}
+	}
+}
+
+func TestRecorderExportsSpanTree(t *testing.T) {
+	t.Parallel()
+
+	receiver := &collector{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	recorder := NewRecorder()
+	if err := recorder.ConfigureExport(server.URL, nil); err != nil {
+		t.Fatalf("ConfigureExport() error = %v", err)
+	}
+	ctx, root := recorder.StartSpan(context.Background(), "run")
+	root.SetAttribute("gopoke.run_id", "run-1")
+	_, child := recorder.StartSpan(ctx, "run.execute")
+	child.End(errors.New("exit status 1"))
+	root.End(nil)
+	root.End(errors.New("ignored"))
+	recorder.RecordSpan("lsp textDocument/hover", time.Now().Add(-time.Millisecond), time.Now(), map[string]string{"rpc.method": "textDocument/hover"}, "")
+	if err := recorder.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if len(receiver.spans) != 3 || receiver.paths[0] != tracesPath {
+		t.Fatalf("collector got %d spans on %v, want 3 on %s", len(receiver.spans), receiver.paths, tracesPath)
+	}
+	execute, run, hover := receiver.spans[0], receiver.spans[1], receiver.spans[2]
+	if execute.TraceID != run.TraceID || execute.ParentSpanID != run.SpanID || run.ParentSpanID != "" {
+		t.Fatalf("execute = %+v, run = %+v; want execute as a child of run", execute, run)
+	}
+	if execute.Status.Code != otlpStatusError || execute.Status.Message != "exit status 1" || run.Status.Code != otlpStatusOK {
+		t.Fatalf("statuses = %+v / %+v, want error then ok", execute.Status, run.Status)
+	}
+	if len(run.Attributes) != 1 || run.Attributes[0].Value.StringValue != "run-1" {
+		t.Fatalf("run attributes = %+v", run.Attributes)
+	}
+	if hover.TraceID == run.TraceID || len(hover.TraceID) != 32 || len(hover.SpanID) != 16 {
+		t.Fatalf("hover = %+v, want its own trace", hover)
+	}
+}
+
+func TestRecorderWithoutExportIsNoop(t *testing.T) {
+	t.Parallel()
+
+	recorder := NewRecorder()
+	ctx, span := recorder.StartSpan(context.Background(), "run")
+	if span != nil || ctx != context.Background() {
+		t.Fatalf("StartSpan() = %v, want nil span while export is off", span)
+	}
+	span.SetAttribute("key", "value")
+	span.End(nil)
+	recorder.RecordSpan("lsp", time.Now(), time.Now(), nil, "")
+	if err := recorder.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestExporterKeepsSpansWhenCollectorFails(t *testing.T) {
+	t.Parallel()
+
+	failing := true
+	receiver := &collector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		receiver.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	exporter, err := NewExporter(server.URL+"/custom/traces", nil)
+	if err != nil {
+		t.Fatalf("NewExporter() error = %v", err)
+	}
+	exporter.Export(SpanData{TraceID: newID(16), SpanID: newID(8), Name: "run", Start: time.Now(), End: time.Now()})
+	if err := exporter.Flush(context.Background()); err == nil {
+		t.Fatal("Flush() error = nil, want collector failure")
+	}
+	failing = false
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if len(receiver.spans) != 1 || receiver.paths[0] != "/custom/traces" {
+		t.Fatalf("collector got %d spans on %v, want the retried span on the custom path", len(receiver.spans), receiver.paths)
+	}
+}
+
+func TestNormalizeOTLPEndpoint(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]string{
+		"http://localhost:4318":             "http://localhost:4318/v1/traces",
+		" https://otel.example.com/ ":       "https://otel.example.com/v1/traces",
+		"http://localhost:4318/otlp/traces": "http://localhost:4318/otlp/traces",
+	} {
+		got, err := NormalizeOTLPEndpoint(input)
+		if err != nil || got != want {
+			t.Errorf("NormalizeOTLPEndpoint(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, bad := range []string{"localhost:4318", "grpc://localhost:4317", "http://"} {
+		if _, err := NormalizeOTLPEndpoint(bad); err == nil {
+			t.Errorf("NormalizeOTLPEndpoint(%q) error = nil, want error", bad)
+		}
+	}
+}
@@ -6,11 +6,15 @@ import (
 	"time"
 )
 
-// StartupEvent captures startup timing.
+// StartupEvent captures startup timing and cleanup.
 type StartupEvent struct {
 	StartedAt   time.Time
 	CompletedAt time.Time
 	Duration    time.Duration
+	// SweptDirs counts orphaned scratch and run cache directories removed
+	// at startup; ReclaimedBytes is the size of their files.
+	SweptDirs      int
+	ReclaimedBytes int64
 }
 
 // RunEvent captures run trigger and first-output timings.
@@ -27,10 +31,15 @@ type runState struct {
 	closed        bool
 }
 
-// Recorder tracks startup and run latency events in memory.
+// Recorder tracks startup and run latency events in memory and, when
+// export is configured, sends spans to an OTLP collector.
 type Recorder struct {
 	mu   sync.Mutex
 	runs map[string]runState
+
+	exportMu sync.RWMutex
+	exporter *Exporter // nil while export is off
+	paused   bool      // export loops wait while set
 }
 
 // NewRecorder creates a telemetry recorder.
@@ -0,0 +1,170 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+)
+
+// exportShutdownTimeout bounds the final flush of a replaced exporter.
+const exportShutdownTimeout = 5 * time.Second
+
+type spanContextKey struct{}
+
+// Span is an operation being timed. Spans are nil while export is off;
+// their methods are then no-ops, so callers need no checks.
+type Span struct {
+	exporter *Exporter
+	mu       sync.Mutex
+	data     SpanData
+	ended    bool
+}
+
+// ConfigureExport sends spans to the OTLP/HTTP collector at endpoint,
+// replacing any running exporter. An empty endpoint turns export off.
+func (r *Recorder) ConfigureExport(endpoint string, logger *slog.Logger) error {
+	var exporter *Exporter
+	if endpoint != "" {
+		tracesURL, err := NormalizeOTLPEndpoint(endpoint)
+		if err != nil {
+			return err
+		}
+		r.exportMu.Lock()
+		current := r.exporter
+		r.exportMu.Unlock()
+		if current != nil && current.Endpoint() == tracesURL {
+			return nil
+		}
+		exporter, err = NewExporter(tracesURL, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.exportMu.Lock()
+	previous := r.exporter
+	r.exporter = exporter
+	if exporter != nil && r.paused {
+		exporter.gate.Pause()
+	}
+	r.exportMu.Unlock()
+	if previous != nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), exportShutdownTimeout)
+			defer cancel()
+			previous.Shutdown(ctx)
+		}()
+	}
+	return nil
+}
+
+// Pause holds span export until Resume, for idle periods. Spans recorded
+// meanwhile stay queued, and a full batch is still sent.
+func (r *Recorder) Pause() {
+	r.exportMu.Lock()
+	defer r.exportMu.Unlock()
+	r.paused = true
+	if r.exporter != nil {
+		r.exporter.gate.Pause()
+	}
+}
+
+// Resume restarts periodic span export.
+func (r *Recorder) Resume() {
+	r.exportMu.Lock()
+	defer r.exportMu.Unlock()
+	r.paused = false
+	if r.exporter != nil {
+		r.exporter.gate.Resume()
+	}
+}
+
+// Shutdown turns export off after sending queued spans.
+func (r *Recorder) Shutdown(ctx context.Context) error {
+	r.exportMu.Lock()
+	exporter := r.exporter
+	r.exporter = nil
+	r.exportMu.Unlock()
+	if exporter == nil {
+		return nil
+	}
+	return exporter.Shutdown(ctx)
+}
+
+func (r *Recorder) currentExporter() *Exporter {
+	if r == nil {
+		return nil
+	}
+	r.exportMu.RLock()
+	defer r.exportMu.RUnlock()
+	return r.exporter
+}
+
+// StartSpan starts a span, as a child of the span in ctx if any, and
+// returns a context carrying it.
+func (r *Recorder) StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	exporter := r.currentExporter()
+	if exporter == nil {
+		return ctx, nil
+	}
+	data := SpanData{SpanID: newID(8), Name: name, Start: time.Now(), Attributes: make(map[string]string)}
+	if parent, ok := ctx.Value(spanContextKey{}).(*Span); ok && parent != nil {
+		data.TraceID = parent.data.TraceID
+		data.ParentSpanID = parent.data.SpanID
+	} else {
+		data.TraceID = newID(16)
+	}
+	span := &Span{exporter: exporter, data: data}
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// RecordSpan exports an operation timed elsewhere as a root span.
+func (r *Recorder) RecordSpan(name string, start time.Time, end time.Time, attributes map[string]string, errorMessage string) {
+	exporter := r.currentExporter()
+	if exporter == nil {
+		return
+	}
+	exporter.Export(SpanData{
+		TraceID:    newID(16),
+		SpanID:     newID(8),
+		Name:       name,
+		Start:      start,
+		End:        end,
+		Attributes: maps.Clone(attributes),
+		Error:      errorMessage,
+	})
+}
+
+// SetAttribute records a string attribute.
+func (s *Span) SetAttribute(key string, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+// End finishes the span, marking it failed when err is not nil. Only the
+// first call counts.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	if err != nil {
+		s.data.Error = err.Error()
+	}
+	data := s.data
+	data.Attributes = maps.Clone(s.data.Attributes)
+	s.mu.Unlock()
+	s.exporter.Export(data)
+}
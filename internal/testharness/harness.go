@@ -0,0 +1,168 @@
+// Package testharness drives a headless Application end to end without a
+// real Go toolchain or gopls. A Harness starts the application on a
+// temporary data root with a fake clock, puts a synthetic go command (and
+// optionally a scripted gopls) first on PATH, and stops everything when the
+// test ends.
+//
+// Packages using the harness must route TestMain through Main so the test
+// binary can also serve as the project worker and the scripted gopls:
+//
+//	func TestMain(m *testing.M) { testharness.Main(m) }
+package testharness
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopoke/internal/app"
+	"gopoke/internal/execution"
+	"gopoke/internal/runner"
+	"gopoke/internal/storage"
+)
+
+// Epoch is where a harness clock starts.
+var Epoch = time.Date(2025, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+// Main runs the tests of a package that uses the harness. Processes the
+// harness spawns from the test binary are served instead of running tests.
+func Main(m *testing.M) {
+	if runner.RunWorkerModeIfEnabled() {
+		os.Exit(0)
+	}
+	if path := os.Getenv(lspScriptEnv); path != "" {
+		if err := serveScriptFile(path); err != nil {
+			os.Stderr.WriteString("scripted lsp: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// Option customizes a Harness.
+type Option func(*config)
+
+type config struct {
+	lspScript *LSPScript
+}
+
+// WithScriptedLSP installs a gopls that answers from script.
+func WithScriptedLSP(script LSPScript) Option {
+	return func(c *config) {
+		c.lspScript = &script
+	}
+}
+
+// Harness is a started Application with deterministic dependencies.
+type Harness struct {
+	t        testing.TB
+	app      *app.Application
+	clock    *FakeClock
+	binDir   string
+	dataRoot string
+}
+
+// New starts an Application for the test. It modifies PATH, so tests using
+// it cannot run in parallel.
+func New(t testing.TB, options ...Option) *Harness {
+	t.Helper()
+
+	var cfg config
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	binDir := t.TempDir()
+	if err := WriteSyntheticToolchain(binDir); err != nil {
+		t.Skipf("synthetic toolchain unavailable: %v", err)
+	}
+	if cfg.lspScript != nil {
+		if err := WriteScriptedLSP(binDir, *cfg.lspScript); err != nil {
+			t.Fatalf("WriteScriptedLSP() error = %v", err)
+		}
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	h := &Harness{
+		t:        t,
+		clock:    NewFakeClock(Epoch),
+		binDir:   binDir,
+		dataRoot: t.TempDir(),
+	}
+	h.app = app.NewWithDataRoot(h.dataRoot)
+	h.app.SetClock(h.clock.Now)
+	if err := h.app.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), app.DefaultShutdownTimeout)
+		defer cancel()
+		if err := h.app.Stop(ctx); err != nil {
+			t.Errorf("Stop() error = %v", err)
+		}
+	})
+	return h
+}
+
+// App returns the running application.
+func (h *Harness) App() *app.Application {
+	return h.app
+}
+
+// Clock returns the application's clock.
+func (h *Harness) Clock() *FakeClock {
+	return h.clock
+}
+
+// BinDir returns the directory holding the synthetic tools.
+func (h *Harness) BinDir() string {
+	return h.binDir
+}
+
+// OpenProject writes files into a new project directory, adds a go.mod when
+// files has none, and opens and trusts the project.
+func (h *Harness) OpenProject(files map[string]string) string {
+	h.t.Helper()
+
+	dir := h.t.TempDir()
+	if _, ok := files["go.mod"]; !ok {
+		files = maps.Clone(files)
+		if files == nil {
+			files = make(map[string]string)
+		}
+		files["go.mod"] = "module example.com/harness\n\ngo 1.22\n"
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			h.t.Fatalf("create %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			h.t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if _, err := h.app.OpenProject(context.Background(), dir); err != nil {
+		h.t.Fatalf("OpenProject() error = %v", err)
+	}
+	if _, err := h.app.SetProjectTrust(context.Background(), dir, storage.TrustTrusted); err != nil {
+		h.t.Fatalf("SetProjectTrust() error = %v", err)
+	}
+	return dir
+}
+
+// Run runs source in projectPath and fails the test on error.
+func (h *Harness) Run(projectPath string, source string) execution.Result {
+	h.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	result, err := h.app.RunSnippet(ctx, execution.RunRequest{ProjectPath: projectPath, Source: source}, nil, nil)
+	if err != nil {
+		h.t.Fatalf("RunSnippet() error = %v", err)
+	}
+	return result
+}
@@ -0,0 +1,171 @@
+package testharness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"gopoke/internal/lsp"
+)
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+func TestHarnessRunsScriptedSnippet(t *testing.T) {
+	h := New(t)
+	projectPath := h.OpenProject(map[string]string{"main.go": "package main\n\nfunc main() {}\n"})
+
+	result := h.Run(projectPath, "package main\n\n//stdout: hello\n//stdout: world\nfunc main() {}\n")
+	if result.ExitCode != 0 || result.Stdout != "hello\nworld\n" {
+		t.Fatalf("result = %+v, want scripted stdout and exit 0", result)
+	}
+
+	failing := h.Run(projectPath, "package main\n\n//stderr: ./main.go:4:2: declared and not used: x\n//exit: 1\nfunc main() {}\n")
+	if failing.ExitCode != 1 {
+		t.Fatalf("ExitCode = %d, want 1", failing.ExitCode)
+	}
+	if len(failing.Diagnostics) != 1 || failing.Diagnostics[0].Line != 4 {
+		t.Fatalf("Diagnostics = %+v, want the scripted compile error", failing.Diagnostics)
+	}
+}
+
+func TestHarnessClockDrivesWorkerRecycling(t *testing.T) {
+	h := New(t)
+	projectPath := h.OpenProject(nil)
+	ctx := context.Background()
+
+	gs, err := h.App().GetGlobalSettings(ctx)
+	if err != nil {
+		t.Fatalf("GetGlobalSettings() error = %v", err)
+	}
+	gs.WorkerMaxLifetimeMS = time.Minute.Milliseconds()
+	if _, err := h.App().UpdateGlobalSettings(ctx, gs); err != nil {
+		t.Fatalf("UpdateGlobalSettings() error = %v", err)
+	}
+
+	first, err := h.App().StartProjectWorker(ctx, projectPath)
+	if err != nil {
+		t.Fatalf("StartProjectWorker() error = %v", err)
+	}
+	if !first.StartedAt.Equal(Epoch) {
+		t.Fatalf("StartedAt = %v, want harness epoch %v", first.StartedAt, Epoch)
+	}
+	reused, err := h.App().StartProjectWorker(ctx, projectPath)
+	if err != nil {
+		t.Fatalf("StartProjectWorker() error = %v", err)
+	}
+	if reused.PID != first.PID {
+		t.Fatalf("worker restarted before its lifetime: pid %d -> %d", first.PID, reused.PID)
+	}
+
+	h.Clock().Advance(2 * time.Minute)
+	recycled, err := h.App().StartProjectWorker(ctx, projectPath)
+	if err != nil {
+		t.Fatalf("StartProjectWorker() error = %v", err)
+	}
+	if recycled.PID == first.PID {
+		t.Fatal("worker not recycled after its lifetime elapsed")
+	}
+}
+
+func TestHarnessScriptedLSPPublishesDiagnostics(t *testing.T) {
+	const documentURI = "file:///harness/main.go"
+	h := New(t, WithScriptedLSP(LSPScript{
+		Diagnostics: map[string]json.RawMessage{
+			documentURI: json.RawMessage(`[{"range":{"start":{"line":2,"character":1},"end":{"line":2,"character":2}},"severity":1,"source":"compiler","message":"undefined: y"}]`),
+		},
+	}))
+	projectPath := h.OpenProject(nil)
+	ctx := context.Background()
+	if err := h.App().StartLSP(ctx, projectPath); err != nil {
+		t.Fatalf("StartLSP() error = %v", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://127.0.0.1:%d/lsp", h.App().LSPWebSocketPort(ctx)), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	send := func(message string) {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+			t.Fatalf("WriteMessage() error = %v", err)
+		}
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	send(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"` + documentURI + `","languageId":"go","version":1,"text":"package main\n\nx := y\n"}}}`)
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage() error = %v", err)
+		}
+		if strings.Contains(string(message), "publishDiagnostics") {
+			break
+		}
+	}
+
+	entries, err := h.App().DocumentDiagnostics(ctx, documentURI, "")
+	if err != nil {
+		t.Fatalf("DocumentDiagnostics() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Line != 3 || entries[0].Message != "undefined: y" {
+		t.Fatalf("entries = %+v, want the scripted gopls diagnostic", entries)
+	}
+	if state := h.App().LSPAnalysisState(ctx); state.State != lsp.AnalysisHasErrors {
+		t.Fatalf("LSPAnalysisState() = %+v, want %q", state, lsp.AnalysisHasErrors)
+	}
+}
+
+func TestHarnessFallsBackToSyntaxOnlyLSP(t *testing.T) {
+	const documentURI = "file:///harness/main.go"
+	h := New(t)
+	// Only the synthetic tools are on PATH, so gopls is missing.
+	t.Setenv("PATH", h.BinDir())
+	projectPath := h.OpenProject(nil)
+	ctx := context.Background()
+	if err := h.App().StartLSP(ctx, projectPath); err != nil {
+		t.Fatalf("StartLSP() error = %v", err)
+	}
+	if status := h.App().LSPStatus(ctx); !status.Ready || status.Mode != lsp.ModeLite {
+		t.Fatalf("LSPStatus() = %+v, want a ready lite server", status)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(fmt.Sprintf("ws://127.0.0.1:%d/lsp", h.App().LSPWebSocketPort(ctx)), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	send := func(message string) {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(message)); err != nil {
+			t.Fatalf("WriteMessage() error = %v", err)
+		}
+	}
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	send(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"` + documentURI + `","languageId":"go","version":1,"text":"package main\n\nfunc main() {\n"}}}`)
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage() error = %v", err)
+		}
+		if strings.Contains(string(message), "publishDiagnostics") {
+			break
+		}
+	}
+
+	entries, err := h.App().DocumentDiagnostics(ctx, documentURI, "")
+	if err != nil {
+		t.Fatalf("DocumentDiagnostics() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Line != 4 || entries[0].Sources[0] != "syntax" {
+		t.Fatalf("entries = %+v, want one syntax error on line 4", entries)
+	}
+}
@@ -0,0 +1,165 @@
+package testharness
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lspScriptEnv points a test binary started as gopls at its script file.
+const lspScriptEnv = "GOPOKE_HARNESS_LSP_SCRIPT"
+
+// LSPScript scripts the language server that stands in for gopls.
+type LSPScript struct {
+	// Results maps request methods to their JSON result. Requests without an
+	// entry get a null result; initialize defaults to incremental sync.
+	Results map[string]json.RawMessage `json:"results,omitempty"`
+	// Diagnostics maps document URIs to the JSON diagnostics array published
+	// after every didOpen and didChange of that document.
+	Diagnostics map[string]json.RawMessage `json:"diagnostics,omitempty"`
+}
+
+var defaultInitializeResult = json.RawMessage(`{"capabilities":{"textDocumentSync":{"openClose":true,"change":2}},"serverInfo":{"name":"gopoke-harness"}}`)
+
+// WriteScriptedLSP writes script and a gopls command into dir that runs the
+// current test binary as the scripted server. The test binary must call Main
+// from TestMain.
+func WriteScriptedLSP(dir string, script LSPScript) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolve test binary: %w", err)
+	}
+	raw, err := json.Marshal(script)
+	if err != nil {
+		return fmt.Errorf("encode lsp script: %w", err)
+	}
+	scriptPath := filepath.Join(dir, "lsp-script.json")
+	if err := os.WriteFile(scriptPath, raw, 0o644); err != nil {
+		return fmt.Errorf("write lsp script: %w", err)
+	}
+	wrapper := fmt.Sprintf("#!/bin/sh\n%s=%s exec %s \"$@\"\n", lspScriptEnv, shellQuote(scriptPath), shellQuote(executable))
+	if err := os.WriteFile(filepath.Join(dir, "gopls"), []byte(wrapper), 0o755); err != nil {
+		return fmt.Errorf("write scripted gopls: %w", err)
+	}
+	return nil
+}
+
+// ServeLSP answers LSP messages framed with Content-Length headers on r
+// according to script until exit or end of input.
+func ServeLSP(r io.Reader, w io.Writer, script LSPScript) error {
+	reader := bufio.NewReader(r)
+	for {
+		body, err := readLSPMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var message struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				TextDocument struct {
+					URI string `json:"uri"`
+				} `json:"textDocument"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(body, &message); err != nil {
+			return fmt.Errorf("decode lsp message: %w", err)
+		}
+
+		switch message.Method {
+		case "exit":
+			return nil
+		case "textDocument/didOpen", "textDocument/didChange":
+			uri := message.Params.TextDocument.URI
+			diagnostics, ok := script.Diagnostics[uri]
+			if !ok {
+				diagnostics = json.RawMessage(`[]`)
+			}
+			if err := writeLSPMessage(w, map[string]any{
+				"jsonrpc": "2.0",
+				"method":  "textDocument/publishDiagnostics",
+				"params":  map[string]any{"uri": uri, "diagnostics": diagnostics},
+			}); err != nil {
+				return err
+			}
+		}
+		if len(message.ID) == 0 {
+			continue
+		}
+		result, ok := script.Results[message.Method]
+		if !ok {
+			result = json.RawMessage(`null`)
+			if message.Method == "initialize" {
+				result = defaultInitializeResult
+			}
+		}
+		if err := writeLSPMessage(w, map[string]any{"jsonrpc": "2.0", "id": message.ID, "result": result}); err != nil {
+			return err
+		}
+	}
+}
+
+func serveScriptFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read lsp script: %w", err)
+	}
+	var script LSPScript
+	if err := json.Unmarshal(raw, &script); err != nil {
+		return fmt.Errorf("decode lsp script: %w", err)
+	}
+	return ServeLSP(os.Stdin, os.Stdout, script)
+}
+
+func readLSPMessage(reader *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, fmt.Errorf("read lsp body: %w", err)
+	}
+	return body, nil
+}
+
+func writeLSPMessage(w io.Writer, message any) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("encode lsp message: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return fmt.Errorf("write lsp message: %w", err)
+	}
+	return nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,60 @@
+package testharness
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"gopoke/internal/execution"
+)
+
+// SyntheticGoVersion is the version the synthetic go command reports.
+const SyntheticGoVersion = "go1.99.0"
+
+const syntheticGoScript = `#!/bin/sh
+# Synthetic go command written by gopoke/internal/testharness.
+case "$1" in
+version)
+	echo "go version %[1]s %[2]s/%[3]s"
+	;;
+env)
+	shift
+	for name in "$@"; do
+		printenv "$name" || echo ""
+	done
+	;;
+run)
+	code=0
+	while IFS= read -r line || [ -n "$line" ]; do
+		case "$line" in
+		"%[4]s"*) printf '%%s\n' "${line#%[4]s}" ;;
+		"%[5]s"*) printf '%%s\n' "${line#%[5]s}" >&2 ;;
+		"%[6]s"*) sleep "${line#%[6]s}" ;;
+		"%[7]s"*) code="${line#%[7]s}" ;;
+		esac
+	done < "$2"
+	exit "$code"
+	;;
+*)
+	echo "synthetic go: unsupported command: $*" >&2
+	exit 2
+	;;
+esac
+`
+
+// WriteSyntheticToolchain writes a scripted go command into dir. It answers
+// go version, go env and go run without compiling anything, so runs are
+// fast and deterministic on machines without a Go toolchain. go run follows
+// the same directive comments as execution.FakeBackend.
+func WriteSyntheticToolchain(dir string) error {
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("synthetic toolchain requires a POSIX shell")
+	}
+	script := fmt.Sprintf(syntheticGoScript, SyntheticGoVersion, runtime.GOOS, runtime.GOARCH,
+		execution.DirectiveStdout, execution.DirectiveStderr, execution.DirectiveSleep, execution.DirectiveExit)
+	if err := os.WriteFile(filepath.Join(dir, "go"), []byte(script), 0o755); err != nil {
+		return fmt.Errorf("write synthetic go: %w", err)
+	}
+	return nil
+}
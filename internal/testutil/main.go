@@ -0,0 +1,18 @@
+package testutil
+
+import (
+	"os"
+	"testing"
+
+	"gopoke/internal/runner"
+)
+
+// Main runs the tests of a package that starts the application. Project
+// workers the application spawns from the test binary serve the worker
+// protocol instead of running the tests again.
+func Main(m *testing.M) {
+	if runner.RunWorkerModeIfEnabled() {
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,78 @@
+package textenc
+
+import (
+	"unicode/utf8"
+)
+
+// Decoder converts output chunk by chunk, holding back sequences split
+// across chunk boundaries. With Auto, each chunk that is not valid UTF-8 is
+// detected on its own; Decode on the complete output stays authoritative.
+type Decoder struct {
+	encoding string
+	pending  []byte
+}
+
+// NewDecoder returns a streaming decoder for encoding. Unknown names decode
+// as Auto.
+func NewDecoder(encoding string) *Decoder {
+	canonical, err := Normalize(encoding)
+	if err != nil {
+		canonical = Auto
+	}
+	return &Decoder{encoding: canonical}
+}
+
+// Decode converts chunk. Trailing bytes that may start an incomplete
+// character are kept for the next call unless final is set.
+func (d *Decoder) Decode(chunk []byte, final bool) string {
+	data := append(d.pending, chunk...)
+	d.pending = nil
+	if !final {
+		keep := d.incompleteTail(data)
+		if keep > 0 {
+			d.pending = append([]byte(nil), data[len(data)-keep:]...)
+			data = data[:len(data)-keep]
+		}
+	}
+	if len(data) == 0 {
+		return ""
+	}
+	return Decode(data, d.encoding).Text
+}
+
+// incompleteTail returns how many trailing bytes of data could be the start
+// of a character completed by the next chunk.
+func (d *Decoder) incompleteTail(data []byte) int {
+	switch d.encoding {
+	case UTF16LE, UTF16BE:
+		keep := len(data) % 2
+		// Hold a trailing high surrogate so its pair decodes together.
+		if len(data)-keep >= 2 {
+			last := data[len(data)-keep-2 : len(data)-keep]
+			unit := uint16(last[1])<<8 | uint16(last[0])
+			if d.encoding == UTF16BE {
+				unit = uint16(last[0])<<8 | uint16(last[1])
+			}
+			if unit >= 0xd800 && unit < 0xdc00 {
+				keep += 2
+			}
+		}
+		return keep
+	case UTF8, Auto:
+		for keep := 1; keep < utf8.UTFMax && keep <= len(data); keep++ {
+			b := data[len(data)-keep]
+			if b < utf8.RuneSelf {
+				return 0
+			}
+			if utf8.RuneStart(b) {
+				if utf8.FullRune(data[len(data)-keep:]) {
+					return 0
+				}
+				return keep
+			}
+		}
+		return 0
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,257 @@
+// Package textenc turns program output bytes into UTF-8 text. It detects
+// UTF-16 and binary output, converts common legacy code pages, and renders
+// binary data as a hexdump.
+package textenc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding names accepted by Normalize and reported by Decode.
+const (
+	Auto        = "auto"
+	UTF8        = "utf-8"
+	UTF16LE     = "utf-16le"
+	UTF16BE     = "utf-16be"
+	Latin1      = "iso-8859-1"
+	Windows1252 = "windows-1252"
+	CP437       = "cp437"
+	// Binary treats output as bytes; text shows printable ASCII only.
+	Binary = "binary"
+)
+
+// binaryControlRatio is the share of control bytes above which auto
+// detection treats output as binary.
+const binaryControlRatio = 0.1
+
+var aliases = map[string]string{
+	"":          Auto,
+	Auto:        Auto,
+	UTF8:        UTF8,
+	"utf8":      UTF8,
+	UTF16LE:     UTF16LE,
+	"utf16le":   UTF16LE,
+	UTF16BE:     UTF16BE,
+	"utf16be":   UTF16BE,
+	Latin1:      Latin1,
+	"latin1":    Latin1,
+	"latin-1":   Latin1,
+	Windows1252: Windows1252,
+	"cp1252":    Windows1252,
+	CP437:       CP437,
+	"ibm437":    CP437,
+	"oem":       CP437,
+	Binary:      Binary,
+	"hex":       Binary,
+}
+
+// Names lists the canonical encodings in display order.
+func Names() []string {
+	return []string{Auto, UTF8, UTF16LE, UTF16BE, Windows1252, Latin1, CP437, Binary}
+}
+
+// Normalize returns the canonical name for an encoding or alias. An empty
+// name means Auto.
+func Normalize(name string) (string, error) {
+	canonical, ok := aliases[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unsupported output encoding %q", name)
+	}
+	return canonical, nil
+}
+
+// Decoded is output converted to UTF-8.
+type Decoded struct {
+	Text string
+	// Encoding is the encoding applied, detected when Auto was requested.
+	Encoding string
+}
+
+// Decode converts data from encoding, detecting it when encoding is Auto
+// or unknown. Invalid sequences become U+FFFD.
+func Decode(data []byte, encoding string) Decoded {
+	encoding, err := Normalize(encoding)
+	if err != nil || encoding == Auto {
+		encoding = Detect(data)
+	}
+	return Decoded{Text: decodeAs(data, encoding), Encoding: encoding}
+}
+
+// Detect guesses the encoding of data: valid UTF-8 first, then UTF-16 by
+// byte order mark or NUL pattern, then binary by control byte density,
+// falling back to Windows-1252.
+func Detect(data []byte) string {
+	if utf8.Valid(data) && !mostlyControl(data) {
+		return UTF8
+	}
+	if encoding, ok := detectUTF16(data); ok {
+		return encoding
+	}
+	if mostlyControl(data) {
+		return Binary
+	}
+	return Windows1252
+}
+
+func decodeAs(data []byte, encoding string) string {
+	switch encoding {
+	case UTF16LE, UTF16BE:
+		return decodeUTF16(data, encoding == UTF16BE)
+	case Latin1:
+		return decodeTable(data, nil)
+	case Windows1252:
+		return decodeTable(data, &windows1252High)
+	case CP437:
+		return decodeTable(data, &cp437High)
+	case Binary:
+		return printableASCII(data)
+	default:
+		return strings.ToValidUTF8(string(data), "\uFFFD")
+	}
+}
+
+// mostlyControl reports whether NUL and other non-whitespace control bytes
+// make up more than binaryControlRatio of data.
+func mostlyControl(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	control := 0
+	for _, b := range data {
+		if isControl(b) {
+			control++
+		}
+	}
+	return float64(control)/float64(len(data)) > binaryControlRatio
+}
+
+func isControl(b byte) bool {
+	switch b {
+	case '\t', '\n', '\r', '\f', '\v', '\b', 0x1b:
+		return false
+	}
+	return b < 0x20 || b == 0x7f
+}
+
+// detectUTF16 checks for a byte order mark, then for ASCII-range text
+// whose high bytes are NUL.
+func detectUTF16(data []byte) (string, bool) {
+	if bytes.HasPrefix(data, []byte{0xff, 0xfe}) {
+		return UTF16LE, true
+	}
+	if bytes.HasPrefix(data, []byte{0xfe, 0xff}) {
+		return UTF16BE, true
+	}
+	if len(data) < 4 || len(data)%2 != 0 {
+		return "", false
+	}
+	evenNUL, oddNUL := 0, 0
+	for index, b := range data {
+		if b != 0 {
+			continue
+		}
+		if index%2 == 0 {
+			evenNUL++
+		} else {
+			oddNUL++
+		}
+	}
+	pairs := len(data) / 2
+	switch {
+	case oddNUL*10 >= pairs*4 && evenNUL == 0:
+		return UTF16LE, true
+	case evenNUL*10 >= pairs*4 && oddNUL == 0:
+		return UTF16BE, true
+	}
+	return "", false
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	if bigEndian {
+		data = bytes.TrimPrefix(data, []byte{0xfe, 0xff})
+	} else {
+		data = bytes.TrimPrefix(data, []byte{0xff, 0xfe})
+	}
+	units := make([]uint16, 0, len(data)/2)
+	for index := 0; index+1 < len(data); index += 2 {
+		if bigEndian {
+			units = append(units, uint16(data[index])<<8|uint16(data[index+1]))
+		} else {
+			units = append(units, uint16(data[index+1])<<8|uint16(data[index]))
+		}
+	}
+	text := string(utf16.Decode(units))
+	if len(data)%2 != 0 {
+		text += "\uFFFD"
+	}
+	return text
+}
+
+// decodeTable maps bytes below 0x80 to ASCII and the rest through high;
+// a nil table is Latin-1, where every byte is its own code point.
+func decodeTable(data []byte, high *[128]rune) string {
+	var builder strings.Builder
+	builder.Grow(len(data))
+	for _, b := range data {
+		switch {
+		case b < 0x80:
+			builder.WriteByte(b)
+		case high == nil:
+			builder.WriteRune(rune(b))
+		default:
+			builder.WriteRune(high[b-0x80])
+		}
+	}
+	return builder.String()
+}
+
+func printableASCII(data []byte) string {
+	var builder strings.Builder
+	builder.Grow(len(data))
+	for _, b := range data {
+		if b == '\n' || b == '\t' || (b >= 0x20 && b < 0x7f) {
+			builder.WriteByte(b)
+		} else {
+			builder.WriteByte('.')
+		}
+	}
+	return builder.String()
+}
+
+// Hexdump renders up to maxBytes of data as offset, hex and ASCII columns,
+// 16 bytes per line. truncated reports whether data was longer.
+func Hexdump(data []byte, maxBytes int) (dump string, truncated bool) {
+	if maxBytes > 0 && len(data) > maxBytes {
+		data = data[:maxBytes]
+		truncated = true
+	}
+	var builder strings.Builder
+	for offset := 0; offset < len(data); offset += 16 {
+		line := data[offset:min(offset+16, len(data))]
+		fmt.Fprintf(&builder, "%08x ", offset)
+		for index := range 16 {
+			if index == 8 {
+				builder.WriteByte(' ')
+			}
+			if index < len(line) {
+				fmt.Fprintf(&builder, " %02x", line[index])
+			} else {
+				builder.WriteString("   ")
+			}
+		}
+		builder.WriteString("  |")
+		for _, b := range line {
+			if b >= 0x20 && b < 0x7f {
+				builder.WriteByte(b)
+			} else {
+				builder.WriteByte('.')
+			}
+		}
+		builder.WriteString("|\n")
+	}
+	return builder.String(), truncated
+}
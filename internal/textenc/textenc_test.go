@@ -0,0 +1,81 @@
+package textenc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeDetectsEncodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		wantText string
+		wantEnc  string
+	}{
+		{name: "utf8", input: []byte("héllo ✓\n"), wantText: "héllo ✓\n", wantEnc: UTF8},
+		{name: "utf16le bom", input: []byte{0xff, 0xfe, 'h', 0, 'i', 0}, wantText: "hi", wantEnc: UTF16LE},
+		{name: "utf16le pattern", input: []byte{'o', 0, 'k', 0, '\n', 0}, wantText: "ok\n", wantEnc: UTF16LE},
+		{name: "utf16be pattern", input: []byte{0, 'o', 0, 'k'}, wantText: "ok", wantEnc: UTF16BE},
+		{name: "windows-1252", input: []byte("caf\xe9 \x80 \x93q\x94"), wantText: "café € “q”", wantEnc: Windows1252},
+		{name: "binary", input: []byte{0x7f, 'E', 'L', 'F', 2, 1, 1, 0, 0, 0}, wantText: ".ELF......", wantEnc: Binary},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Decode(tt.input, Auto)
+			if got.Text != tt.wantText || got.Encoding != tt.wantEnc {
+				t.Fatalf("Decode() = %+v, want %q as %s", got, tt.wantText, tt.wantEnc)
+			}
+		})
+	}
+}
+
+func TestDecodeHonorsExplicitEncoding(t *testing.T) {
+	if got := Decode([]byte{0x82, 0xb3, 0xdb}, CP437).Text; got != "é│█" {
+		t.Fatalf("Decode(cp437) = %q, want %q", got, "é│█")
+	}
+	if got := Decode([]byte{0x80}, Latin1).Text; got != "\u0080" {
+		t.Fatalf("Decode(latin1) = %q, want U+0080", got)
+	}
+	if got := Decode([]byte("ok\xff"), UTF8).Text; got != "ok\uFFFD" {
+		t.Fatalf("Decode(utf-8) = %q, want replacement character", got)
+	}
+	if _, err := Normalize("ebcdic"); err == nil {
+		t.Fatal("Normalize(ebcdic) error = nil, want error")
+	}
+	if got, _ := Normalize(" CP1252 "); got != Windows1252 {
+		t.Fatalf("Normalize(CP1252) = %q, want %q", got, Windows1252)
+	}
+}
+
+func TestDecoderJoinsSplitCharacters(t *testing.T) {
+	decoder := NewDecoder(Auto)
+	encoded := []byte("✓ done")
+	var text strings.Builder
+	for _, b := range encoded {
+		text.WriteString(decoder.Decode([]byte{b}, false))
+	}
+	text.WriteString(decoder.Decode(nil, true))
+	if got := text.String(); got != "✓ done" {
+		t.Fatalf("streamed text = %q, want %q", got, "✓ done")
+	}
+
+	utf16 := NewDecoder(UTF16LE)
+	// U+1F600 is a surrogate pair: 3D D8 00 DE.
+	first := utf16.Decode([]byte{'a', 0, 0x3d, 0xd8, 0x00}, false)
+	second := utf16.Decode([]byte{0xde}, true)
+	if got := first + second; got != "a😀" {
+		t.Fatalf("streamed utf-16 = %q, want %q", got, "a😀")
+	}
+}
+
+func TestHexdump(t *testing.T) {
+	dump, truncated := Hexdump([]byte("ABCDEFGHIJKLMNOPQ\x00"), 0)
+	want := "00000000  41 42 43 44 45 46 47 48  49 4a 4b 4c 4d 4e 4f 50  |ABCDEFGHIJKLMNOP|\n" +
+		"00000010  51 00                                             |Q.|\n"
+	if dump != want || truncated {
+		t.Fatalf("Hexdump() = %q, %t, want %q", dump, truncated, want)
+	}
+	if _, truncated := Hexdump(make([]byte, 40), 32); !truncated {
+		t.Fatal("Hexdump() truncated = false, want true")
+	}
+}
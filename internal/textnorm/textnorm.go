@@ -0,0 +1,82 @@
+// Package textnorm normalizes line endings, byte order marks and final
+// newlines of saved text so files keep the convention they were written in.
+package textnorm
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Line ending conventions.
+const (
+	// LineEndingPreserve keeps the convention detected in the original text.
+	LineEndingPreserve = "preserve"
+	LineEndingLF       = "lf"
+	LineEndingCRLF     = "crlf"
+)
+
+const bom = "\uFEFF"
+
+// Convention describes how a text is laid out on disk.
+type Convention struct {
+	// LineEnding is LineEndingLF or LineEndingCRLF, whichever is more
+	// common; empty when the text has no line breaks.
+	LineEnding string `json:"lineEnding"`
+	// BOM is set when the text starts with a UTF-8 byte order mark.
+	BOM bool `json:"bom"`
+	// FinalNewline is set when the text ends with a line break.
+	FinalNewline bool `json:"finalNewline"`
+}
+
+// Options selects how saved text is normalized.
+type Options struct {
+	// LineEnding is LineEndingPreserve, LineEndingLF or LineEndingCRLF.
+	LineEnding string
+	// StripBOM drops a byte order mark the original had.
+	StripBOM bool
+	// EnsureFinalNewline appends a line break to non-empty text lacking one.
+	EnsureFinalNewline bool
+}
+
+// Detect reports the convention of content.
+func Detect(content []byte) Convention {
+	convention := Convention{BOM: bytes.HasPrefix(content, []byte(bom))}
+	crlf := bytes.Count(content, []byte("\r\n"))
+	lf := bytes.Count(content, []byte("\n")) - crlf
+	switch {
+	case crlf > lf:
+		convention.LineEnding = LineEndingCRLF
+	case lf > 0 || crlf > 0:
+		convention.LineEnding = LineEndingLF
+	}
+	convention.FinalNewline = bytes.HasSuffix(content, []byte("\n"))
+	return convention
+}
+
+// Apply rewrites content for saving over text with the original
+// convention. Line endings follow options, falling back to the original's
+// and then to the content's own when preserving; a byte order mark is kept
+// only if the original had one and options do not strip it.
+func Apply(content string, original Convention, options Options) string {
+	content = strings.TrimPrefix(content, bom)
+
+	lineEnding := options.LineEnding
+	if lineEnding != LineEndingLF && lineEnding != LineEndingCRLF {
+		lineEnding = original.LineEnding
+		if lineEnding == "" {
+			lineEnding = Detect([]byte(content)).LineEnding
+		}
+	}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if options.EnsureFinalNewline && content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if lineEnding == LineEndingCRLF {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+
+	if original.BOM && !options.StripBOM {
+		content = bom + content
+	}
+	return content
+}
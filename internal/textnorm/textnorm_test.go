@@ -0,0 +1,77 @@
+package textnorm
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Convention
+	}{
+		{name: "lf", input: "a\nb\n", want: Convention{LineEnding: LineEndingLF, FinalNewline: true}},
+		{name: "crlf with bom", input: "\uFEFFa\r\nb\r\nc", want: Convention{LineEnding: LineEndingCRLF, BOM: true}},
+		{name: "mixed favors majority", input: "a\r\nb\nc\r\n", want: Convention{LineEnding: LineEndingCRLF, FinalNewline: true}},
+		{name: "single line", input: "package main", want: Convention{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect([]byte(tt.input)); got != tt.want {
+				t.Fatalf("Detect(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	crlfWithBOM := Convention{LineEnding: LineEndingCRLF, BOM: true, FinalNewline: true}
+	tests := []struct {
+		name     string
+		content  string
+		original Convention
+		options  Options
+		want     string
+	}{
+		{
+			name:     "preserves crlf and bom",
+			content:  "a\nb\n",
+			original: crlfWithBOM,
+			options:  Options{LineEnding: LineEndingPreserve},
+			want:     "\uFEFFa\r\nb\r\n",
+		},
+		{
+			name:     "forces lf and strips bom",
+			content:  "\uFEFFa\r\nb\r\n",
+			original: crlfWithBOM,
+			options:  Options{LineEnding: LineEndingLF, StripBOM: true},
+			want:     "a\nb\n",
+		},
+		{
+			name:     "adds final newline",
+			content:  "a\nb",
+			original: Convention{LineEnding: LineEndingLF},
+			options:  Options{EnsureFinalNewline: true},
+			want:     "a\nb\n",
+		},
+		{
+			name:     "new text keeps its own endings",
+			content:  "a\r\nb",
+			original: Convention{},
+			options:  Options{LineEnding: LineEndingPreserve},
+			want:     "a\r\nb",
+		},
+		{
+			name:     "empty stays empty",
+			content:  "",
+			original: Convention{},
+			options:  Options{EnsureFinalNewline: true},
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Apply(tt.content, tt.original, tt.options); got != tt.want {
+				t.Fatalf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
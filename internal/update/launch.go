@@ -0,0 +1,26 @@
+package update
+
+import "fmt"
+
+// Launch is the launcher step that installs a staged update: called first
+// thing in main, before the application opens any state, it applies a
+// pending update and restarts the process on the new build with the same
+// arguments. It returns only when nothing was installed, or when installing
+// failed and the current build should keep running.
+func (u *Updater) Launch() error {
+	applied, err := u.ApplyPending()
+	if err != nil {
+		return fmt.Errorf("install staged update: %w", err)
+	}
+	if !applied {
+		return nil
+	}
+	executablePath, err := u.resolveExecutablePath()
+	if err != nil {
+		return err
+	}
+	if err := u.restart(executablePath); err != nil {
+		return fmt.Errorf("restart on updated build: %w", err)
+	}
+	return nil
+}
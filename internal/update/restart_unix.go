@@ -0,0 +1,14 @@
+//go:build !windows
+
+package update
+
+import (
+	"os"
+	"syscall"
+)
+
+// restart replaces the process image with executablePath, keeping the
+// arguments and environment. It returns only on failure.
+func restart(executablePath string) error {
+	return syscall.Exec(executablePath, os.Args, os.Environ())
+}
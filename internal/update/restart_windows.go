@@ -0,0 +1,31 @@
+//go:build windows
+
+package update
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// restart runs executablePath with the same arguments, environment and
+// standard streams, and exits with its status once it finishes. Windows has
+// no exec, so the old build waits for the new one. It returns only when the
+// new build cannot start.
+func restart(executablePath string) error {
+	cmd := exec.Command(executablePath, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+	return nil
+}
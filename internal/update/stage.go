@@ -0,0 +1,379 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopoke/internal/download"
+)
+
+const (
+	pendingManifestName = "pending.json"
+	progressTool        = "gopoke"
+	// maxSignatureBytes bounds the detached signature download.
+	maxSignatureBytes = 4 << 10
+)
+
+// StagedUpdate is a verified build waiting to replace the executable.
+type StagedUpdate struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	// Signature is the base64 detached signature the build was verified
+	// with; ApplyPending checks it again before installing.
+	Signature string    `json:"signature"`
+	StagedAt  time.Time `json:"stagedAt"`
+}
+
+// Stage downloads the asset of an available update and its detached
+// signature, verifies the signature against the pinned release key, and
+// records the build as pending so Launch installs it when gopoke next
+// starts. Progress is reported with the same events used for toolchain
+// downloads.
+func (u *Updater) Stage(ctx context.Context, check CheckResult, onProgress download.OnProgress) (StagedUpdate, error) {
+	if err := ctx.Err(); err != nil {
+		return StagedUpdate{}, fmt.Errorf("stage update context: %w", err)
+	}
+	if !check.Available || check.Release == nil || check.Asset == nil {
+		return StagedUpdate{}, fmt.Errorf("no update available")
+	}
+	if !ValidVersion(check.Release.Version) {
+		return StagedUpdate{}, fmt.Errorf("release version %q is not a semantic version", check.Release.Version)
+	}
+	if !isNewer(check.Release.Version, u.currentVersion) {
+		return StagedUpdate{}, fmt.Errorf("release %s is not newer than %s", check.Release.Version, u.currentVersion)
+	}
+	if len(u.publicKey) == 0 {
+		return StagedUpdate{}, fmt.Errorf("no release signing key is pinned in this build")
+	}
+	if strings.TrimSpace(check.Asset.SignatureURL) == "" {
+		return StagedUpdate{}, fmt.Errorf("release %s has no signature", check.Release.Version)
+	}
+	if err := os.MkdirAll(u.dir, 0o755); err != nil {
+		return StagedUpdate{}, fmt.Errorf("create update dir: %w", err)
+	}
+
+	message := fmt.Sprintf("Downloading gopoke %s...", check.Release.Version)
+	report(onProgress, download.Progress{Stage: download.StageDownloading, Message: message})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, check.Asset.URL, nil)
+	if err != nil {
+		return StagedUpdate{}, fmt.Errorf("create download request: %w", err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return StagedUpdate{}, fmt.Errorf("download update: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return StagedUpdate{}, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	tmpFile, err := os.CreateTemp(u.dir, "gopoke-*.partial")
+	if err != nil {
+		return StagedUpdate{}, fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	totalBytes := resp.ContentLength
+	if totalBytes <= 0 {
+		totalBytes = check.Asset.Size
+	}
+	writer := &progressWriter{
+		onWrite: func(received int64) {
+			report(onProgress, download.Progress{
+				Stage:         download.StageDownloading,
+				BytesReceived: received,
+				BytesTotal:    totalBytes,
+				Percent:       percent(received, totalBytes),
+				Message:       message,
+			})
+		},
+	}
+	if _, err := io.Copy(io.MultiWriter(tmpFile, writer), contextReader{ctx: ctx, reader: resp.Body}); err != nil {
+		tmpFile.Close()
+		return StagedUpdate{}, fmt.Errorf("write update: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return StagedUpdate{}, fmt.Errorf("close update: %w", err)
+	}
+
+	report(onProgress, download.Progress{Stage: download.StageVerifying, Percent: 100, Message: "Verifying update..."})
+	signature, err := u.fetchSignature(ctx, check.Asset.SignatureURL)
+	if err != nil {
+		return StagedUpdate{}, err
+	}
+	if err := verifyFile(u.publicKey, check.Release.Version, tmpPath, signature); err != nil {
+		return StagedUpdate{}, err
+	}
+
+	stagedPath := filepath.Join(u.dir, stagedBinaryName(check.Release.Version, u.goos))
+	if err := os.Rename(tmpPath, stagedPath); err != nil {
+		return StagedUpdate{}, fmt.Errorf("stage update: %w", err)
+	}
+	if err := os.Chmod(stagedPath, 0o755); err != nil {
+		return StagedUpdate{}, fmt.Errorf("mark update executable: %w", err)
+	}
+
+	staged := StagedUpdate{
+		Version:   check.Release.Version,
+		Path:      stagedPath,
+		Signature: base64.StdEncoding.EncodeToString(signature),
+		StagedAt:  time.Now().UTC(),
+	}
+	if err := u.writePending(staged); err != nil {
+		return StagedUpdate{}, err
+	}
+
+	report(onProgress, download.Progress{
+		Stage:   download.StageComplete,
+		Percent: 100,
+		Message: fmt.Sprintf("gopoke %s will be installed on next launch", staged.Version),
+	})
+	return staged, nil
+}
+
+// Pending returns the staged update, if any.
+func (u *Updater) Pending() (StagedUpdate, bool, error) {
+	data, err := os.ReadFile(filepath.Join(u.dir, pendingManifestName))
+	if errors.Is(err, os.ErrNotExist) {
+		return StagedUpdate{}, false, nil
+	}
+	if err != nil {
+		return StagedUpdate{}, false, fmt.Errorf("read pending update: %w", err)
+	}
+	var staged StagedUpdate
+	if err := json.Unmarshal(data, &staged); err != nil {
+		return StagedUpdate{}, false, fmt.Errorf("decode pending update: %w", err)
+	}
+	return staged, true, nil
+}
+
+// DiscardPending removes any staged update.
+func (u *Updater) DiscardPending() error {
+	staged, ok, err := u.Pending()
+	if err != nil || !ok {
+		return err
+	}
+	if err := os.Remove(staged.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove staged update: %w", err)
+	}
+	if err := os.Remove(filepath.Join(u.dir, pendingManifestName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove pending manifest: %w", err)
+	}
+	return nil
+}
+
+// ApplyPending replaces the executable with the staged build after
+// verifying its signature against the pinned release key again and
+// checking that it is newer than the running build. The previous
+// executable is kept alongside with an ".old" suffix. It reports whether an
+// update was installed. Only Launch calls it, before the application
+// starts.
+func (u *Updater) ApplyPending() (bool, error) {
+	staged, ok, err := u.Pending()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if !isNewer(staged.Version, u.currentVersion) {
+		_ = u.DiscardPending()
+		return false, fmt.Errorf("staged update %s is not newer than %s; discarded", staged.Version, u.currentVersion)
+	}
+	signature, err := base64.StdEncoding.DecodeString(staged.Signature)
+	if err == nil {
+		err = verifyFile(u.publicKey, staged.Version, staged.Path, signature)
+	}
+	if err != nil {
+		_ = u.DiscardPending()
+		return false, fmt.Errorf("verify staged update: %w; discarded", err)
+	}
+
+	executablePath, err := u.resolveExecutablePath()
+	if err != nil {
+		return false, err
+	}
+	backupPath := executablePath + ".old"
+	_ = os.Remove(backupPath)
+	if err := os.Rename(executablePath, backupPath); err != nil {
+		return false, fmt.Errorf("back up executable: %w", err)
+	}
+	if err := moveFile(staged.Path, executablePath); err != nil {
+		_ = os.Rename(backupPath, executablePath)
+		return false, fmt.Errorf("install update: %w", err)
+	}
+	if err := os.Chmod(executablePath, 0o755); err != nil {
+		return false, fmt.Errorf("mark executable: %w", err)
+	}
+	if err := os.Remove(filepath.Join(u.dir, pendingManifestName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return true, fmt.Errorf("remove pending manifest: %w", err)
+	}
+	return true, nil
+}
+
+func (u *Updater) resolveExecutablePath() (string, error) {
+	if u.executablePath != "" {
+		return u.executablePath, nil
+	}
+	executablePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("resolve executable path: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(executablePath)
+	if err != nil {
+		return "", fmt.Errorf("resolve executable symlinks: %w", err)
+	}
+	return resolved, nil
+}
+
+func (u *Updater) writePending(staged StagedUpdate) error {
+	data, err := json.MarshalIndent(staged, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode pending update: %w", err)
+	}
+	manifestPath := filepath.Join(u.dir, pendingManifestName)
+	tmpPath := manifestPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("write pending update: %w", err)
+	}
+	if err := os.Rename(tmpPath, manifestPath); err != nil {
+		return fmt.Errorf("commit pending update: %w", err)
+	}
+	return nil
+}
+
+// stagedBinaryName names the staged file; version must be valid, which
+// keeps the name inside the update directory.
+func stagedBinaryName(version string, goos string) string {
+	name := "gopoke-" + strings.TrimPrefix(version, "v")
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// moveFile renames src to dst, copying when they are on different volumes.
+func moveFile(src string, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// fetchSignature downloads the detached signature at url: base64 text
+// encoding an Ed25519 signature.
+func (u *Updater) fetchSignature(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create signature request: %w", err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download signature: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("signature download returned status %d", resp.StatusCode)
+	}
+	text, err := io.ReadAll(io.LimitReader(resp.Body, maxSignatureBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(text)))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("malformed signature")
+	}
+	return signature, nil
+}
+
+// SignedMessage returns what a release signature covers: the version,
+// so a signed build cannot be served under another version to roll users
+// back, followed by the executable.
+func SignedMessage(version string, binary []byte) []byte {
+	message := make([]byte, 0, len(version)+len(binary)+16)
+	message = append(message, "gopoke "...)
+	message = append(message, canonicalVersion(version)...)
+	message = append(message, '\n')
+	return append(message, binary...)
+}
+
+// verifyFile checks that signature is key's signature of version and the
+// file at path.
+func verifyFile(key ed25519.PublicKey, version string, path string, signature []byte) error {
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("no release signing key is pinned in this build")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read update: %w", err)
+	}
+	if !ed25519.Verify(key, SignedMessage(version, data), signature) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func report(onProgress download.OnProgress, progress download.Progress) {
+	if onProgress == nil {
+		return
+	}
+	progress.Tool = progressTool
+	onProgress(progress)
+}
+
+func percent(received int64, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return min(float64(received)/float64(total)*100, 100)
+}
+
+type progressWriter struct {
+	received int64
+	onWrite  func(received int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.received += int64(len(p))
+	w.onWrite(w.received)
+	return len(p), nil
+}
+
+// contextReader stops a copy once ctx is canceled.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
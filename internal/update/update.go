@@ -0,0 +1,265 @@
+// Package update checks the gopoke release feed, downloads new builds and
+// verifies their signatures against a pinned key, and stages them so Launch
+// installs them when gopoke next starts.
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Release channels selectable in settings.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// DefaultFeedURL is the release feed consulted when none is configured.
+const DefaultFeedURL = "https://raw.githubusercontent.com/ShahramMebashar/gopoke/main/releases.json"
+
+// CurrentVersion is the running gopoke version, set at build time with
+// -ldflags "-X gopoke/internal/update.CurrentVersion=v1.2.3".
+var CurrentVersion = "dev"
+
+// ReleaseKey is the base64 Ed25519 public key release builds are signed
+// with, pinned at build time with
+// -ldflags "-X gopoke/internal/update.ReleaseKey=...". Builds without one
+// refuse to stage updates.
+var ReleaseKey = ""
+
+// Feed is the release feed document.
+type Feed struct {
+	Releases []Release `json:"releases"`
+}
+
+// Release describes one published gopoke build.
+type Release struct {
+	Version string `json:"version"`
+	// Channel is ChannelStable or ChannelBeta, matched exactly; releases on
+	// any other channel are never offered.
+	Channel     string    `json:"channel"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Notes       string    `json:"notes"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Asset is one platform-specific executable within a release.
+// SignatureURL serves the detached signature of the executable: the base64
+// Ed25519 signature, by the key pinned in ReleaseKey, of SignedMessage for
+// the release version and the executable.
+type Asset struct {
+	OS           string `json:"os"`
+	Arch         string `json:"arch"`
+	URL          string `json:"url"`
+	SignatureURL string `json:"signatureUrl"`
+	Size         int64  `json:"size"`
+}
+
+// CheckResult reports whether a newer release is available for this platform.
+type CheckResult struct {
+	CurrentVersion string   `json:"currentVersion"`
+	Channel        string   `json:"channel"`
+	Available      bool     `json:"available"`
+	Release        *Release `json:"release,omitempty"`
+	Asset          *Asset   `json:"asset,omitempty"`
+}
+
+// Option customizes an Updater.
+type Option func(*Updater)
+
+// WithFeedURL overrides the release feed URL.
+func WithFeedURL(url string) Option {
+	return func(u *Updater) {
+		if strings.TrimSpace(url) != "" {
+			u.feedURL = url
+		}
+	}
+}
+
+// WithHTTPClient overrides the HTTP client used for the feed and downloads.
+func WithHTTPClient(client *http.Client) Option {
+	return func(u *Updater) {
+		if client != nil {
+			u.client = client
+		}
+	}
+}
+
+// WithCurrentVersion overrides the version compared against the feed.
+func WithCurrentVersion(version string) Option {
+	return func(u *Updater) {
+		if strings.TrimSpace(version) != "" {
+			u.currentVersion = version
+		}
+	}
+}
+
+// WithPublicKey overrides the pinned key release signatures are verified
+// against.
+func WithPublicKey(key ed25519.PublicKey) Option {
+	return func(u *Updater) {
+		if len(key) == ed25519.PublicKeySize {
+			u.publicKey = key
+		}
+	}
+}
+
+// WithExecutablePath overrides the executable replaced by ApplyPending.
+func WithExecutablePath(path string) Option {
+	return func(u *Updater) {
+		if strings.TrimSpace(path) != "" {
+			u.executablePath = path
+		}
+	}
+}
+
+// Updater checks for, stages, and applies gopoke updates.
+type Updater struct {
+	dir            string
+	feedURL        string
+	client         *http.Client
+	currentVersion string
+	executablePath string
+	publicKey      ed25519.PublicKey
+	restart        func(executablePath string) error
+	goos           string
+	goarch         string
+}
+
+// NewUpdater creates an updater that stages downloads under dir.
+func NewUpdater(dir string, options ...Option) *Updater {
+	updater := &Updater{
+		dir:            dir,
+		feedURL:        DefaultFeedURL,
+		client:         http.DefaultClient,
+		currentVersion: CurrentVersion,
+		publicKey:      pinnedKey(),
+		restart:        restart,
+		goos:           runtime.GOOS,
+		goarch:         runtime.GOARCH,
+	}
+	for _, option := range options {
+		option(updater)
+	}
+	return updater
+}
+
+// pinnedKey decodes ReleaseKey, returning nil when it is unset or invalid.
+func pinnedKey() ed25519.PublicKey {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(ReleaseKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil
+	}
+	return key
+}
+
+// NormalizeChannel maps unknown channel names to ChannelStable.
+func NormalizeChannel(channel string) string {
+	if strings.EqualFold(strings.TrimSpace(channel), ChannelBeta) {
+		return ChannelBeta
+	}
+	return ChannelStable
+}
+
+// Check fetches the feed and returns the newest release on channel that is
+// newer than the running version and has an asset for this platform. The
+// beta channel also considers stable releases.
+func (u *Updater) Check(ctx context.Context, channel string) (CheckResult, error) {
+	if err := ctx.Err(); err != nil {
+		return CheckResult{}, fmt.Errorf("check update context: %w", err)
+	}
+	channel = NormalizeChannel(channel)
+	result := CheckResult{CurrentVersion: u.currentVersion, Channel: channel}
+
+	feed, err := u.fetchFeed(ctx)
+	if err != nil {
+		return CheckResult{}, err
+	}
+
+	var best *Release
+	var bestAsset *Asset
+	for i := range feed.Releases {
+		release := &feed.Releases[i]
+		if !ValidVersion(release.Version) || !channelIncludes(channel, release.Channel) {
+			continue
+		}
+		asset, ok := release.assetFor(u.goos, u.goarch)
+		if !ok {
+			continue
+		}
+		if best != nil && CompareVersions(release.Version, best.Version) <= 0 {
+			continue
+		}
+		best, bestAsset = release, asset
+	}
+	if best == nil || !isNewer(best.Version, u.currentVersion) {
+		return result, nil
+	}
+
+	result.Available = true
+	result.Release = best
+	result.Asset = bestAsset
+	return result, nil
+}
+
+func (u *Updater) fetchFeed(ctx context.Context) (Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.feedURL, nil)
+	if err != nil {
+		return Feed{}, fmt.Errorf("create feed request: %w", err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return Feed{}, fmt.Errorf("fetch release feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Feed{}, fmt.Errorf("release feed returned status %d", resp.StatusCode)
+	}
+
+	var feed Feed
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return Feed{}, fmt.Errorf("decode release feed: %w", err)
+	}
+	return feed, nil
+}
+
+func (r *Release) assetFor(goos string, goarch string) (*Asset, bool) {
+	for i := range r.Assets {
+		if r.Assets[i].OS == goos && r.Assets[i].Arch == goarch {
+			return &r.Assets[i], true
+		}
+	}
+	return nil, false
+}
+
+// channelIncludes reports whether a release published on releaseChannel is
+// offered on the selected channel. The feed's channel is not normalized, so
+// an empty, misspelled or unknown channel is skipped rather than treated as
+// stable.
+func channelIncludes(selected string, releaseChannel string) bool {
+	switch releaseChannel {
+	case ChannelStable:
+		return true
+	case ChannelBeta:
+		return selected == ChannelBeta
+	default:
+		return false
+	}
+}
+
+// isNewer reports whether candidate should replace current. Development
+// builds, whose version is not a release version, never auto-update.
+func isNewer(candidate string, current string) bool {
+	if !ValidVersion(current) || !ValidVersion(candidate) {
+		return false
+	}
+	return CompareVersions(candidate, current) > 0
+}
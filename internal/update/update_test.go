@@ -0,0 +1,342 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopoke/internal/download"
+)
+
+// newFeedServer serves a feed whose assets serve binary, with sign's result
+// for each release version as their detached signature.
+func newFeedServer(t *testing.T, binary []byte, sign func(version string) []byte) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.json":
+			asset := func(name string) []Asset {
+				return []Asset{{
+					OS:           runtime.GOOS,
+					Arch:         runtime.GOARCH,
+					URL:          server.URL + "/" + name,
+					SignatureURL: server.URL + "/" + name + ".sig",
+					Size:         int64(len(binary)),
+				}}
+			}
+			feed := Feed{Releases: []Release{
+				{Version: "v1.1.0", Channel: ChannelStable, Assets: asset("stable")},
+				{Version: "v1.2.0-beta.1", Channel: ChannelBeta, Assets: asset("beta")},
+				{Version: "v9.0.0", Channel: ChannelStable, Assets: []Asset{{OS: "plan9", Arch: "mips"}}},
+				// Releases off the known channels are never offered.
+				{Version: "v5.0.0", Channel: "nightly", Assets: asset("stable")},
+				{Version: "v6.0.0", Assets: asset("stable")},
+				{Version: "v7.0.0", Channel: "Stable", Assets: asset("stable")},
+			}}
+			_ = json.NewEncoder(w).Encode(feed)
+		case "/stable", "/beta":
+			_, _ = w.Write(binary)
+		case "/stable.sig":
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(sign("v1.1.0")) + "\n"))
+		case "/beta.sig":
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(sign("v1.2.0-beta.1")) + "\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newReleaseKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return public, private
+}
+
+// signedBy signs binary as the given release version with private.
+func signedBy(private ed25519.PrivateKey, binary []byte) func(version string) []byte {
+	return func(version string) []byte {
+		return ed25519.Sign(private, SignedMessage(version, binary))
+	}
+}
+
+func TestCheckSelectsNewestReleaseForChannel(t *testing.T) {
+	t.Parallel()
+
+	server := newFeedServer(t, []byte("binary"), func(string) []byte { return nil })
+
+	tests := []struct {
+		name        string
+		current     string
+		channel     string
+		wantVersion string
+	}{
+		{name: "stable", current: "v1.0.0", channel: ChannelStable, wantVersion: "v1.1.0"},
+		{name: "beta includes prereleases", current: "v1.0.0", channel: ChannelBeta, wantVersion: "v1.2.0-beta.1"},
+		{name: "unknown channel falls back to stable", current: "v1.0.0", channel: "nightly", wantVersion: "v1.1.0"},
+		{name: "stable skips releases off known channels", current: "v1.1.0", channel: ChannelStable},
+		{name: "beta skips releases off known channels", current: "v1.2.0-beta.1", channel: ChannelBeta},
+		{name: "up to date", current: "v1.1.0", channel: ChannelStable},
+		{name: "dev build", current: "dev", channel: ChannelBeta},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			updater := NewUpdater(t.TempDir(), WithFeedURL(server.URL+"/feed.json"), WithCurrentVersion(tt.current))
+			result, err := updater.Check(context.Background(), tt.channel)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if tt.wantVersion == "" {
+				if result.Available {
+					t.Fatalf("Check() available = %s, want none", result.Release.Version)
+				}
+				return
+			}
+			if !result.Available || result.Release.Version != tt.wantVersion {
+				t.Fatalf("Check() = %+v, want %s", result, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestStageAndLaunchInstallsSignedUpdate(t *testing.T) {
+	t.Parallel()
+
+	public, private := newReleaseKey(t)
+	binary := []byte("#!/bin/sh\necho new\n")
+	server := newFeedServer(t, binary, signedBy(private, binary))
+
+	executablePath := filepath.Join(t.TempDir(), "gopoke")
+	if err := os.WriteFile(executablePath, []byte("old"), 0o755); err != nil {
+		t.Fatalf("write executable: %v", err)
+	}
+	updater := NewUpdater(
+		t.TempDir(),
+		WithFeedURL(server.URL+"/feed.json"),
+		WithCurrentVersion("v1.0.0"),
+		WithExecutablePath(executablePath),
+		WithPublicKey(public),
+	)
+	var restarted []string
+	updater.restart = func(path string) error {
+		restarted = append(restarted, path)
+		return nil
+	}
+
+	check, err := updater.Check(context.Background(), ChannelStable)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	stages := make([]string, 0)
+	staged, err := updater.Stage(context.Background(), check, func(p download.Progress) {
+		if p.Tool != "gopoke" {
+			t.Errorf("progress tool = %q, want gopoke", p.Tool)
+		}
+		stages = append(stages, p.Stage)
+	})
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if got, want := staged.Version, "v1.1.0"; got != want {
+		t.Fatalf("staged version = %q, want %q", got, want)
+	}
+	if got := stages[len(stages)-1]; got != "complete" {
+		t.Fatalf("last progress stage = %q, want complete", got)
+	}
+	if installed, _ := os.ReadFile(executablePath); string(installed) != "old" {
+		t.Fatalf("executable = %q after Stage, want it untouched until launch", installed)
+	}
+	if _, ok, err := updater.Pending(); err != nil || !ok {
+		t.Fatalf("Pending() = %v, %v, want staged update", ok, err)
+	}
+
+	if err := updater.Launch(); err != nil {
+		t.Fatalf("Launch() error = %v", err)
+	}
+	if len(restarted) != 1 || restarted[0] != executablePath {
+		t.Fatalf("restarted = %v, want %s", restarted, executablePath)
+	}
+	installed, err := os.ReadFile(executablePath)
+	if err != nil {
+		t.Fatalf("read executable: %v", err)
+	}
+	if string(installed) != string(binary) {
+		t.Fatalf("executable = %q, want staged binary", installed)
+	}
+	if backup, err := os.ReadFile(executablePath + ".old"); err != nil || string(backup) != "old" {
+		t.Fatalf("backup = %q, %v, want old executable", backup, err)
+	}
+	if _, ok, _ := updater.Pending(); ok {
+		t.Fatal("pending update remains after launch")
+	}
+
+	if err := updater.Launch(); err != nil || len(restarted) != 1 {
+		t.Fatalf("Launch() without a pending update = %v, restarted %v", err, restarted)
+	}
+}
+
+func TestStageRejectsUntrustedSignature(t *testing.T) {
+	t.Parallel()
+
+	public, _ := newReleaseKey(t)
+	_, otherPrivate := newReleaseKey(t)
+	binary := []byte("tampered")
+
+	tests := map[string][]Option{
+		"signed by another key": {WithPublicKey(public)},
+		"no pinned key":         nil,
+	}
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			server := newFeedServer(t, binary, signedBy(otherPrivate, binary))
+			options = append([]Option{WithFeedURL(server.URL + "/feed.json"), WithCurrentVersion("v1.0.0")}, options...)
+			updater := NewUpdater(t.TempDir(), options...)
+
+			check, err := updater.Check(context.Background(), ChannelStable)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if _, err := updater.Stage(context.Background(), check, nil); err == nil {
+				t.Fatal("Stage() error = nil, want signature rejected")
+			}
+			if _, ok, _ := updater.Pending(); ok {
+				t.Fatal("pending update recorded for an untrusted build")
+			}
+		})
+	}
+}
+
+func TestLaunchDiscardsTamperedStagedUpdate(t *testing.T) {
+	t.Parallel()
+
+	public, private := newReleaseKey(t)
+	binary := []byte("signed")
+	server := newFeedServer(t, binary, signedBy(private, binary))
+	executablePath := filepath.Join(t.TempDir(), "gopoke")
+	if err := os.WriteFile(executablePath, []byte("old"), 0o755); err != nil {
+		t.Fatalf("write executable: %v", err)
+	}
+	updater := NewUpdater(
+		t.TempDir(),
+		WithFeedURL(server.URL+"/feed.json"),
+		WithCurrentVersion("v1.0.0"),
+		WithExecutablePath(executablePath),
+		WithPublicKey(public),
+	)
+	updater.restart = func(string) error {
+		t.Error("restarted on a tampered update")
+		return nil
+	}
+
+	check, err := updater.Check(context.Background(), ChannelStable)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	staged, err := updater.Stage(context.Background(), check, nil)
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	if err := os.WriteFile(staged.Path, []byte("swapped"), 0o755); err != nil {
+		t.Fatalf("tamper with staged update: %v", err)
+	}
+
+	if err := updater.Launch(); err == nil || !strings.Contains(err.Error(), "signature mismatch") {
+		t.Fatalf("Launch() error = %v, want signature mismatch", err)
+	}
+	if installed, _ := os.ReadFile(executablePath); string(installed) != "old" {
+		t.Fatalf("executable = %q, want the old build kept", installed)
+	}
+	if _, ok, _ := updater.Pending(); ok {
+		t.Fatal("tampered update still pending")
+	}
+}
+
+func TestStageRejectsSignatureForAnotherVersion(t *testing.T) {
+	t.Parallel()
+
+	public, private := newReleaseKey(t)
+	binary := []byte("old signed build")
+	// An older signed build relabeled as v1.1.0 must not verify.
+	server := newFeedServer(t, binary, func(string) []byte {
+		return ed25519.Sign(private, SignedMessage("v0.9.0", binary))
+	})
+	updater := NewUpdater(t.TempDir(), WithFeedURL(server.URL+"/feed.json"), WithCurrentVersion("v1.0.0"), WithPublicKey(public))
+
+	check, err := updater.Check(context.Background(), ChannelStable)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if _, err := updater.Stage(context.Background(), check, nil); err == nil || !strings.Contains(err.Error(), "signature mismatch") {
+		t.Fatalf("Stage() error = %v, want signature mismatch", err)
+	}
+}
+
+func TestStageRefusesInvalidVersion(t *testing.T) {
+	t.Parallel()
+
+	public, _ := newReleaseKey(t)
+	updater := NewUpdater(t.TempDir(), WithCurrentVersion("v1.0.0"), WithPublicKey(public))
+	check := CheckResult{
+		Available: true,
+		Release:   &Release{Version: "../../escape"},
+		Asset:     &Asset{URL: "http://127.0.0.1:0/binary", SignatureURL: "http://127.0.0.1:0/binary.sig"},
+	}
+	if _, err := updater.Stage(context.Background(), check, nil); err == nil || !strings.Contains(err.Error(), "semantic version") {
+		t.Fatalf("Stage() error = %v, want invalid version refused", err)
+	}
+}
+
+func TestLaunchDiscardsStagedUpdateNotNewer(t *testing.T) {
+	t.Parallel()
+
+	public, private := newReleaseKey(t)
+	binary := []byte("signed")
+	server := newFeedServer(t, binary, signedBy(private, binary))
+	executablePath := filepath.Join(t.TempDir(), "gopoke")
+	if err := os.WriteFile(executablePath, []byte("current"), 0o755); err != nil {
+		t.Fatalf("write executable: %v", err)
+	}
+	updateDir := t.TempDir()
+	stager := NewUpdater(updateDir, WithFeedURL(server.URL+"/feed.json"), WithCurrentVersion("v1.0.0"), WithPublicKey(public))
+	check, err := stager.Check(context.Background(), ChannelStable)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if _, err := stager.Stage(context.Background(), check, nil); err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+
+	// The running build has since moved past the staged one.
+	launcher := NewUpdater(updateDir, WithCurrentVersion("v1.5.0"), WithExecutablePath(executablePath), WithPublicKey(public))
+	launcher.restart = func(string) error {
+		t.Error("restarted into an older build")
+		return nil
+	}
+	if err := launcher.Launch(); err == nil || !strings.Contains(err.Error(), "not newer") {
+		t.Fatalf("Launch() error = %v, want staged update refused as not newer", err)
+	}
+	if installed, _ := os.ReadFile(executablePath); string(installed) != "current" {
+		t.Fatalf("executable = %q, want the running build kept", installed)
+	}
+	if _, ok, _ := launcher.Pending(); ok {
+		t.Fatal("older staged update still pending")
+	}
+}
@@ -0,0 +1,104 @@
+package update
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// semverPattern matches a semantic version with an optional "v" prefix.
+var semverPattern = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+// ValidVersion reports whether version is a semantic version such as
+// "v1.4.0" or "1.5.0-beta.2". Feed versions name staged files and are
+// signed, so anything else is refused.
+func ValidVersion(version string) bool {
+	return semverPattern.MatchString(version)
+}
+
+// canonicalVersion returns a valid version with its "v" prefix, the form
+// release signatures cover.
+func canonicalVersion(version string) string {
+	return "v" + strings.TrimPrefix(version, "v")
+}
+
+// CompareVersions compares semantic versions such as "v1.4.0" and
+// "1.5.0-beta.2". A release sorts after any prerelease of the same version.
+func CompareVersions(left string, right string) int {
+	leftCore, leftPre := splitVersion(left)
+	rightCore, rightPre := splitVersion(right)
+
+	for i := 0; i < 3; i++ {
+		if leftCore[i] != rightCore[i] {
+			if leftCore[i] < rightCore[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case leftPre == rightPre:
+		return 0
+	case leftPre == "":
+		return 1
+	case rightPre == "":
+		return -1
+	}
+	return comparePrerelease(leftPre, rightPre)
+}
+
+func splitVersion(version string) ([3]int, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if index := strings.IndexByte(version, '+'); index >= 0 {
+		version = version[:index]
+	}
+	prerelease := ""
+	if index := strings.IndexByte(version, '-'); index >= 0 {
+		version, prerelease = version[:index], version[index+1:]
+	}
+
+	var core [3]int
+	for i, part := range strings.SplitN(version, ".", 3) {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		core[i] = value
+	}
+	return core, prerelease
+}
+
+func comparePrerelease(left string, right string) int {
+	leftParts := strings.Split(left, ".")
+	rightParts := strings.Split(right, ".")
+	for i := 0; i < len(leftParts) && i < len(rightParts); i++ {
+		if leftParts[i] == rightParts[i] {
+			continue
+		}
+		leftNumber, leftErr := strconv.Atoi(leftParts[i])
+		rightNumber, rightErr := strconv.Atoi(rightParts[i])
+		switch {
+		case leftErr == nil && rightErr == nil:
+			if leftNumber < rightNumber {
+				return -1
+			}
+			return 1
+		case leftErr == nil:
+			return -1
+		case rightErr == nil:
+			return 1
+		case leftParts[i] < rightParts[i]:
+			return -1
+		default:
+			return 1
+		}
+	}
+	switch {
+	case len(leftParts) < len(rightParts):
+		return -1
+	case len(leftParts) > len(rightParts):
+		return 1
+	}
+	return 0
+}
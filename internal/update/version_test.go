@@ -0,0 +1,42 @@
+package update
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		left  string
+		right string
+		want  int
+	}{
+		{left: "v1.2.3", right: "1.2.3", want: 0},
+		{left: "v1.2.3", right: "v1.10.0", want: -1},
+		{left: "v2.0.0", right: "v1.99.99", want: 1},
+		{left: "v1.2.0-beta.1", right: "v1.2.0", want: -1},
+		{left: "v1.2.0-beta.2", right: "v1.2.0-beta.10", want: -1},
+		{left: "v1.2.0-rc.1", right: "v1.2.0-beta.3", want: 1},
+		{left: "v1.2.0+build.5", right: "v1.2.0", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.left, tt.right); got != tt.want {
+			t.Fatalf("CompareVersions(%q, %q) = %d, want %d", tt.left, tt.right, got, tt.want)
+		}
+	}
+}
+
+func TestValidVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []string{"v1.2.3", "1.2.3", "v1.2.0-beta.1", "v1.2.0+build.5"} {
+		if !ValidVersion(version) {
+			t.Fatalf("ValidVersion(%q) = false, want true", version)
+		}
+	}
+	for _, version := range []string{"", "dev", "../", "v1.2", "v1.2.3/../../x", "v01.2.3", "v1.2.3-"} {
+		if ValidVersion(version) {
+			t.Fatalf("ValidVersion(%q) = true, want false", version)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Package webhook posts run results to user-configured URLs. Payloads are
+// JSON with a Slack-compatible text summary, optionally signed with an
+// HMAC of the body, and delivered in the background with exponential
+// backoff on network errors, 429 and 5xx responses.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gopoke/internal/storage"
+)
+
+// EventRunCompleted is the event of run result payloads.
+const EventRunCompleted = "run.completed"
+
+// MaxOutputBytes bounds each output stream in a payload; longer output
+// keeps its tail, where failures usually show.
+const MaxOutputBytes = 4096
+
+// SignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body
+// when a hook has a secret.
+const SignatureHeader = "X-Gopoke-Signature"
+
+// Statuses a hook can filter on. They match the run statuses in run
+// history.
+var Statuses = []string{"success", "failed", "canceled", "timed_out"}
+
+const (
+	defaultAttempts = 4
+	defaultBackoff  = time.Second
+)
+
+// Payload describes a finished run.
+type Payload struct {
+	Event           string    `json:"event"`
+	Text            string    `json:"text"`
+	RunID           string    `json:"runId"`
+	ProjectPath     string    `json:"projectPath"`
+	ProjectName     string    `json:"projectName"`
+	SnippetID       string    `json:"snippetId,omitempty"`
+	SnippetName     string    `json:"snippetName,omitempty"`
+	Status          string    `json:"status"`
+	ExitCode        int       `json:"exitCode"`
+	DurationMS      int64     `json:"durationMs"`
+	Stdout          string    `json:"stdout"`
+	Stderr          string    `json:"stderr"`
+	StdoutTruncated bool      `json:"stdoutTruncated"`
+	StderrTruncated bool      `json:"stderrTruncated"`
+	FinishedAt      time.Time `json:"finishedAt"`
+}
+
+// NewPayload builds a run payload, truncating output and writing the text
+// summary.
+func NewPayload(runID string, projectPath string, projectName string, snippetID string, snippetName string,
+	status string, exitCode int, durationMS int64, stdout string, stderr string, finishedAt time.Time) Payload {
+	payload := Payload{
+		Event:       EventRunCompleted,
+		RunID:       runID,
+		ProjectPath: projectPath,
+		ProjectName: projectName,
+		SnippetID:   snippetID,
+		SnippetName: snippetName,
+		Status:      status,
+		ExitCode:    exitCode,
+		DurationMS:  durationMS,
+		FinishedAt:  finishedAt,
+	}
+	payload.Stdout, payload.StdoutTruncated = tail(stdout, MaxOutputBytes)
+	payload.Stderr, payload.StderrTruncated = tail(stderr, MaxOutputBytes)
+	subject := projectName
+	if snippetName != "" {
+		subject = snippetName + " in " + projectName
+	}
+	payload.Text = fmt.Sprintf("gopoke run %s: %s (exit %d, %d ms)", strings.ReplaceAll(status, "_", " "), subject, exitCode, durationMS)
+	return payload
+}
+
+// tail keeps the last max bytes of text on a rune boundary.
+func tail(text string, max int) (string, bool) {
+	if len(text) <= max {
+		return text, false
+	}
+	start := len(text) - max
+	for start < len(text) && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	return text[start:], true
+}
+
+// Matches reports whether hook wants runs with status. A hook without
+// statuses wants every run.
+func Matches(hook storage.Webhook, status string) bool {
+	return len(hook.Statuses) == 0 || slices.Contains(hook.Statuses, status)
+}
+
+// Normalize validates hooks, trims fields and assigns missing IDs.
+func Normalize(hooks []storage.Webhook) ([]storage.Webhook, error) {
+	normalized := make([]storage.Webhook, 0, len(hooks))
+	seen := make(map[string]bool, len(hooks))
+	for _, hook := range hooks {
+		hook.ID = strings.TrimSpace(hook.ID)
+		hook.URL = strings.TrimSpace(hook.URL)
+		hook.SecretEnv = strings.TrimSpace(hook.SecretEnv)
+		parsed, err := url.Parse(hook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("webhook URL must be an http or https URL: %q", hook.URL)
+		}
+		statuses := make([]string, 0, len(hook.Statuses))
+		for _, status := range hook.Statuses {
+			status = strings.ToLower(strings.TrimSpace(status))
+			if !slices.Contains(Statuses, status) {
+				return nil, fmt.Errorf("unknown run status %q; use one of %s", status, strings.Join(Statuses, ", "))
+			}
+			if !slices.Contains(statuses, status) {
+				statuses = append(statuses, status)
+			}
+		}
+		hook.Statuses = statuses
+		if hook.ID == "" {
+			hook.ID = newID()
+		}
+		if seen[hook.ID] {
+			return nil, fmt.Errorf("duplicate webhook ID %q", hook.ID)
+		}
+		seen[hook.ID] = true
+		normalized = append(normalized, hook)
+	}
+	return normalized, nil
+}
+
+func newID() string {
+	raw := make([]byte, 6)
+	rand.Read(raw)
+	return "wh_" + hex.EncodeToString(raw)
+}
+
+// Target is where a payload goes.
+type Target struct {
+	URL string
+	// Secret signs the body; empty sends it unsigned.
+	Secret string
+}
+
+// Dispatcher delivers payloads in the background.
+type Dispatcher struct {
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+	logger   *slog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Option customizes a Dispatcher.
+type Option func(*Dispatcher)
+
+// WithRetry sets the number of attempts and the first backoff, which
+// doubles after each failure.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.attempts = max(attempts, 1)
+		d.backoff = backoff
+	}
+}
+
+// NewDispatcher creates a dispatcher.
+func NewDispatcher(logger *slog.Logger, options ...Option) *Dispatcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		attempts: defaultAttempts,
+		backoff:  defaultBackoff,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Send delivers payload to target in the background; failures are logged.
+func (d *Dispatcher) Send(target Target, payload Payload) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		if err := d.Deliver(d.ctx, target, payload); err != nil {
+			d.logger.Warn("webhook delivery failed", "runId", payload.RunID, "error", err)
+		}
+	}()
+}
+
+// Deliver posts payload to target, retrying transient failures.
+func (d *Dispatcher) Deliver(ctx context.Context, target Target, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+	backoff := d.backoff
+	var lastErr error
+	for attempt := 1; attempt <= d.attempts; attempt++ {
+		retry, err := d.post(ctx, target, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == d.attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook delivery: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return lastErr
+}
+
+// post makes one attempt and reports whether a failure is worth retrying.
+func (d *Dispatcher) post(ctx context.Context, target Target, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create webhook request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "gopoke-webhook")
+	if target.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(target.Secret))
+		mac.Write(body)
+		request.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	response, err := d.client.Do(request)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("post webhook: %w", err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return false, nil
+	}
+	retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+	return retry, fmt.Errorf("post webhook: HTTP %d", response.StatusCode)
+}
+
+// Close waits for pending deliveries until ctx ends, then abandons them.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return fmt.Errorf("webhook deliveries abandoned: %w", ctx.Err())
+	}
+}
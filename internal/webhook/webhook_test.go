@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gopoke/internal/storage"
+)
+
+// recorder is a webhook receiver that answers with scripted statuses and
+// then 200.
+type recorder struct {
+	mu        sync.Mutex
+	statuses  []int
+	bodies    [][]byte
+	signature string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.signature = request.Header.Get(SignatureHeader)
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		w.WriteHeader(status)
+	}
+}
+
+func (r *recorder) attempts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func TestDeliverRetriesTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	receiver := &recorder{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	dispatcher := NewDispatcher(nil, WithRetry(4, time.Millisecond))
+	payload := NewPayload("run-1", "/work/demo", "demo", "sn_1", "probe", "failed", 2, 120, "out", "boom", time.Now())
+	if err := dispatcher.Deliver(context.Background(), Target{URL: server.URL, Secret: "s3cret"}, payload); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if got := receiver.attempts(); got != 3 {
+		t.Fatalf("attempts = %d, want 3", got)
+	}
+
+	body := receiver.bodies[2]
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); receiver.signature != want {
+		t.Fatalf("signature = %q, want %q", receiver.signature, want)
+	}
+	var decoded Payload
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if decoded.Event != EventRunCompleted || decoded.SnippetName != "probe" || decoded.Status != "failed" || decoded.DurationMS != 120 {
+		t.Fatalf("payload = %+v, want the failed probe run", decoded)
+	}
+	if decoded.Text != "gopoke run failed: probe in demo (exit 2, 120 ms)" {
+		t.Fatalf("Text = %q", decoded.Text)
+	}
+}
+
+func TestDeliverDoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	receiver := &recorder{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	dispatcher := NewDispatcher(nil, WithRetry(4, time.Millisecond))
+	err := dispatcher.Deliver(context.Background(), Target{URL: server.URL}, Payload{RunID: "run-1"})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 400") {
+		t.Fatalf("Deliver() error = %v, want HTTP 400", err)
+	}
+	if got := receiver.attempts(); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
+	}
+	if receiver.signature != "" {
+		t.Fatalf("signature = %q, want none without a secret", receiver.signature)
+	}
+}
+
+func TestSendDeliversBeforeClose(t *testing.T) {
+	t.Parallel()
+
+	receiver := &recorder{statuses: []int{http.StatusBadGateway}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	dispatcher := NewDispatcher(nil, WithRetry(2, time.Millisecond))
+	dispatcher.Send(Target{URL: server.URL}, Payload{RunID: "run-1"})
+	if err := dispatcher.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got := receiver.attempts(); got != 2 {
+		t.Fatalf("attempts = %d, want 2", got)
+	}
+}
+
+func TestNewPayloadKeepsOutputTail(t *testing.T) {
+	t.Parallel()
+
+	stdout := strings.Repeat("a", MaxOutputBytes) + "é" + "end"
+	payload := NewPayload("run-1", "/work/demo", "demo", "", "", "timed_out", -1, 5000, stdout, "short", time.Now())
+	if !payload.StdoutTruncated || payload.StderrTruncated {
+		t.Fatalf("truncated = %t/%t, want stdout only", payload.StdoutTruncated, payload.StderrTruncated)
+	}
+	if len(payload.Stdout) > MaxOutputBytes || !strings.HasSuffix(payload.Stdout, "éend") {
+		t.Fatalf("Stdout ends %q (%d bytes), want the tail within the limit", payload.Stdout[len(payload.Stdout)-8:], len(payload.Stdout))
+	}
+	if payload.Text != "gopoke run timed out: demo (exit -1, 5000 ms)" {
+		t.Fatalf("Text = %q", payload.Text)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	hooks, err := Normalize([]storage.Webhook{{URL: " https://hooks.example.com/x ", Statuses: []string{"Failed", "failed", "timed_out"}}})
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	if hooks[0].ID == "" || hooks[0].URL != "https://hooks.example.com/x" || len(hooks[0].Statuses) != 2 {
+		t.Fatalf("Normalize() = %+v, want an ID, a trimmed URL and two statuses", hooks[0])
+	}
+	if !Matches(hooks[0], "failed") || Matches(hooks[0], "success") || !Matches(storage.Webhook{}, "success") {
+		t.Fatal("Matches() disagrees with the status filter")
+	}
+
+	for _, bad := range [][]storage.Webhook{
+		{{URL: "ftp://example.com"}},
+		{{URL: "https://"}},
+		{{URL: "https://example.com", Statuses: []string{"exploded"}}},
+		{{ID: "a", URL: "https://example.com"}, {ID: "a", URL: "https://example.org"}},
+	} {
+		if _, err := Normalize(bad); err == nil {
+			t.Errorf("Normalize(%+v) error = nil, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package engine embeds gopoke's snippet runner in other Go tools, such as
+// editor plugins and bots. It runs a snippet with `go run` inside a
+// project's module, so the snippet can import the project's packages, and
+// parses compile errors and panics from its output.
+//
+// The package follows semantic versioning, reported by Version: within a
+// major version, exported identifiers keep their meaning and new fields and
+// methods may be added. It wraps gopoke's internal packages, whose APIs
+// change freely, and exposes none of their types.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopoke/internal/diagnostics"
+	"gopoke/internal/execution"
+	"gopoke/internal/project"
+)
+
+// Version is the semantic version of this package's API.
+const Version = "1.0.0"
+
+// Diagnostic kinds reported in Diagnostic.Kind.
+const (
+	KindCompile = diagnostics.KindCompile
+	KindPanic   = diagnostics.KindPanic
+)
+
+// Runner runs snippets in project context. Engine implements it; embedders
+// can substitute their own in tests.
+type Runner interface {
+	Run(ctx context.Context, project Project, request RunRequest) (RunResult, error)
+}
+
+// Options configure an Engine.
+type Options struct {
+	// Toolchain is the go binary or a name on PATH; empty means "go".
+	Toolchain string
+	// Scripted makes runs follow the directive comments gopoke's fake
+	// backend understands instead of invoking a toolchain, for tests of
+	// embedding code on machines without Go.
+	Scripted bool
+}
+
+// Engine runs snippets in project context. It is safe for concurrent use.
+type Engine struct {
+	backend   execution.Backend
+	toolchain string
+}
+
+// New returns an engine configured by options. It fails when the toolchain
+// cannot be found.
+func New(options Options) (*Engine, error) {
+	if options.Scripted {
+		return &Engine{backend: &execution.FakeBackend{}}, nil
+	}
+	name := strings.TrimSpace(options.Toolchain)
+	if name == "" {
+		name = "go"
+	}
+	toolchain, err := project.ResolveToolchainBinary(name)
+	if err != nil {
+		return nil, fmt.Errorf("resolve toolchain: %w", err)
+	}
+	return &Engine{backend: execution.GoBackend{}, toolchain: toolchain}, nil
+}
+
+// Project is a directory snippets run in.
+type Project struct {
+	// Path is the absolute project directory.
+	Path string
+	// HasModule reports whether Path holds a go.mod, without which snippets
+	// can only import the standard library.
+	HasModule bool
+	// Packages are the project's runnable main packages, relative to Path.
+	Packages []string
+}
+
+// OpenProject inspects the directory at path.
+func (e *Engine) OpenProject(ctx context.Context, path string) (Project, error) {
+	module, err := project.DetectModule(ctx, path)
+	if err != nil {
+		return Project{}, fmt.Errorf("detect module: %w", err)
+	}
+	targets, err := project.DiscoverRunTargets(ctx, module.Path)
+	if err != nil {
+		return Project{}, fmt.Errorf("discover packages: %w", err)
+	}
+	packages := make([]string, 0, len(targets))
+	for _, target := range targets {
+		packages = append(packages, target.Package)
+	}
+	return Project{Path: module.Path, HasModule: module.HasModule, Packages: packages}, nil
+}
+
+// RunRequest is one snippet to run.
+type RunRequest struct {
+	// Source is a complete main package.
+	Source string
+	// WorkingDirectory is where the snippet runs, relative to the project
+	// or absolute; empty means the project directory.
+	WorkingDirectory string
+	// Env adds or overrides environment variables of the run.
+	Env map[string]string
+	// Args are passed to the snippet's main.
+	Args []string
+	// Timeout stops the run; zero means 15 seconds.
+	Timeout time.Duration
+	// MaxOutputBytes caps each of stdout and stderr; zero means 128 KiB.
+	MaxOutputBytes int
+	// OnStdout and OnStderr receive output while the snippet runs.
+	OnStdout func(chunk string)
+	OnStderr func(chunk string)
+}
+
+// RunResult is the outcome of a run. A snippet that fails to compile or
+// exits non-zero is a result, not an error.
+type RunResult struct {
+	Stdout          string
+	Stderr          string
+	ExitCode        int
+	Duration        time.Duration
+	TimedOut        bool
+	Canceled        bool
+	StdoutTruncated bool
+	StderrTruncated bool
+	// Diagnostics are the compile errors and panics found in Stderr.
+	Diagnostics []Diagnostic
+}
+
+// Diagnostic is one compile error or panic location.
+type Diagnostic struct {
+	Kind    string
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// Run runs request.Source in proj. Canceling ctx stops the run and
+// returns a result with Canceled set.
+func (e *Engine) Run(ctx context.Context, proj Project, request RunRequest) (RunResult, error) {
+	if strings.TrimSpace(proj.Path) == "" {
+		return RunResult{}, fmt.Errorf("project path is required")
+	}
+	if !filepath.IsAbs(proj.Path) {
+		return RunResult{}, fmt.Errorf("project path must be absolute; use OpenProject")
+	}
+	options := execution.RunOptions{
+		WorkingDirectory: request.WorkingDirectory,
+		Environment:      request.Env,
+		Toolchain:        e.toolchain,
+		Timeout:          request.Timeout,
+		MaxStdoutBytes:   request.MaxOutputBytes,
+		MaxStderrBytes:   request.MaxOutputBytes,
+		Args:             request.Args,
+		OnStdoutChunk:    request.OnStdout,
+		OnStderrChunk:    request.OnStderr,
+	}
+	result, err := e.backend.Run(ctx, proj.Path, request.Source, options)
+	if err != nil {
+		return RunResult{}, fmt.Errorf("run snippet: %w", err)
+	}
+	return RunResult{
+		Stdout:          result.Stdout,
+		Stderr:          result.Stderr,
+		ExitCode:        result.ExitCode,
+		Duration:        time.Duration(result.DurationMS) * time.Millisecond,
+		TimedOut:        result.TimedOut,
+		Canceled:        result.Canceled,
+		StdoutTruncated: result.StdoutTruncated,
+		StderrTruncated: result.StderrTruncated,
+		Diagnostics:     ParseDiagnostics(result.Stderr),
+	}, nil
+}
+
+// ParseDiagnostics extracts compile errors and panic locations from go run
+// output.
+func ParseDiagnostics(output string) []Diagnostic {
+	parsed := diagnostics.ParseAll(output)
+	found := make([]Diagnostic, 0, len(parsed))
+	for _, item := range parsed {
+		found = append(found, Diagnostic{
+			Kind:    item.Kind,
+			File:    item.File,
+			Line:    item.Line,
+			Column:  item.Column,
+			Message: item.Message,
+		})
+	}
+	return found
+}
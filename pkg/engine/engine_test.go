@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEngineRunsSnippetInProjectContext(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go binary not available")
+	}
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/embed\n\ngo 1.25\n")
+	writeFile(t, filepath.Join(root, "greet", "greet.go"), "package greet\n\nfunc Hello() string { return \"hello from the project\" }\n")
+	writeFile(t, filepath.Join(root, "cmd", "tool", "main.go"), "package main\n\nfunc main() {}\n")
+
+	var runner Runner
+	engine, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	runner = engine
+	ctx := context.Background()
+	proj, err := engine.OpenProject(ctx, root)
+	if err != nil {
+		t.Fatalf("OpenProject() error = %v", err)
+	}
+	if !proj.HasModule || len(proj.Packages) != 1 || proj.Packages[0] != "./cmd/tool" {
+		t.Fatalf("OpenProject() = %+v", proj)
+	}
+
+	offline := map[string]string{"GOPROXY": "off", "GOFLAGS": "", "GOWORK": "off"}
+	result, err := runner.Run(ctx, proj, RunRequest{
+		Source:  "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/embed/greet\"\n)\n\nfunc main() { fmt.Println(greet.Hello()) }\n",
+		Env:     offline,
+		Timeout: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.ExitCode != 0 || strings.TrimSpace(result.Stdout) != "hello from the project" {
+		t.Fatalf("Run() = %+v", result)
+	}
+
+	broken, err := runner.Run(ctx, proj, RunRequest{Source: "package main\n\nfunc main() {\n\tx := 1\n}\n", Env: offline, Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("Run(broken) error = %v", err)
+	}
+	if broken.ExitCode == 0 || len(broken.Diagnostics) == 0 || broken.Diagnostics[0].Kind != KindCompile || broken.Diagnostics[0].Line != 4 {
+		t.Fatalf("Run(broken) = %+v", broken)
+	}
+}
+
+func TestScriptedEngine(t *testing.T) {
+	t.Parallel()
+
+	engine, err := New(Options{Scripted: true})
+	if err != nil {
+		t.Fatalf("New(scripted) error = %v", err)
+	}
+	var streamed strings.Builder
+	result, err := engine.Run(context.Background(), Project{Path: t.TempDir()}, RunRequest{
+		Source:   "package main\n\nfunc main() {}\n//stdout: scripted\n//exit: 3\n",
+		OnStdout: func(chunk string) { streamed.WriteString(chunk) },
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Stdout != "scripted\n" || result.ExitCode != 3 || streamed.String() != "scripted\n" {
+		t.Fatalf("Run() = %+v, streamed %q", result, streamed.String())
+	}
+	if _, err := engine.Run(context.Background(), Project{Path: "relative"}, RunRequest{Source: "package main"}); err == nil {
+		t.Fatal("Run(relative project) error = nil")
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	found := ParseDiagnostics("./main.go:3:2: declared and not used: v\n")
+	if len(found) != 1 || found[0].File != "./main.go" || found[0].Column != 2 {
+		t.Fatalf("ParseDiagnostics() = %+v", found)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
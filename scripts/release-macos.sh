@@ -10,6 +10,9 @@ app_name="${APP_NAME:-GoPoke}"
 platform="${TARGET_PLATFORM:-darwin/arm64}"
 build_tags="${BUILD_TAGS:-wails,desktop,production}"
 wails_cli="${WAILS_CLI:-go run github.com/wailsapp/wails/v2/cmd/wails@v2.11.0}"
+version="${VERSION:-dev}"
+release_key="${RELEASE_KEY:-}"
+ldflags="-X gopoke/internal/update.CurrentVersion=$version -X gopoke/internal/update.ReleaseKey=$release_key"
 
 sign_identity="${MACOS_SIGN_IDENTITY:-}"
 notary_profile="${MACOS_NOTARY_PROFILE:-}"
@@ -29,7 +32,7 @@ if [[ ! -d "$frontend_dist" ]]; then
   (cd "$app_root/frontend" && npm install && npm run build)
 fi
 
-$wails_cli build -clean -platform "$platform" -tags "$build_tags"
+$wails_cli build -clean -platform "$platform" -tags "$build_tags" -ldflags "$ldflags"
 
 app_bundle="$app_root/build/bin/$app_name.app"
 if [[ ! -d "$app_bundle" ]]; then
@@ -65,6 +68,7 @@ cat > "$report_file" <<EOF_REPORT
 - App Root: \`$app_root\`
 - Platform: \`$platform\`
 - Build Tags: \`$build_tags\`
+- Version: \`$version\`
 - Wails CLI: \`$wails_cli\`
 
 | Step | Status |